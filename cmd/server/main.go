@@ -3,18 +3,56 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/serial"
 	"homework/internal/usecase"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	httpGateway "homework/internal/gateways/http"
+	actuatorRepository "homework/internal/repository/actuator/postgres"
+	alertRepository "homework/internal/repository/alert/postgres"
+	auditRepository "homework/internal/repository/audit/postgres"
+	automationRepository "homework/internal/repository/automation/postgres"
 	eventRepository "homework/internal/repository/event/postgres"
+	groupRepository "homework/internal/repository/group/postgres"
+	homeRepository "homework/internal/repository/home/postgres"
+	outboxRepository "homework/internal/repository/outbox/postgres"
+	rateLimitRepository "homework/internal/repository/ratelimit/inmemory"
+	ruleRepository "homework/internal/repository/rule/postgres"
 	sensorRepository "homework/internal/repository/sensor/postgres"
+	transactionRepository "homework/internal/repository/transaction/postgres"
 	userRepository "homework/internal/repository/user/postgres"
+	webhookRepository "homework/internal/repository/webhook/postgres"
+)
+
+const (
+	// ruleEvaluationInterval - период вычисления правил оповещений без новых событий
+	ruleEvaluationInterval = 15 * time.Second
+	// webhookDeliveryInterval - период отправки уведомлений подпискам
+	webhookDeliveryInterval = 5 * time.Second
+	// outboxDispatchInterval - период доставки записей outbox
+	outboxDispatchInterval = time.Second
+	// commandExpiryInterval - период перевода невыполненных команд устройствам в expired
+	commandExpiryInterval = 10 * time.Second
+	// scheduleRunInterval - период запуска расписаний, время которых наступило
+	scheduleRunInterval = 15 * time.Second
+	// anomalyCheckpointInterval - период сохранения статистики обнаружения аномалий
+	anomalyCheckpointInterval = time.Minute
+	// debounceFlushInterval - период приема отложенных смен состояния датчиков сухого контакта
+	debounceFlushInterval = 50 * time.Millisecond
+	// nonceCleanupInterval - период удаления nonce подписанных событий с истекшим сроком
+	nonceCleanupInterval = time.Minute
+	// defaultSerialSchemes - схемы серийных номеров производителей, если SERIAL_SCHEMES не задана:
+	// алфанумерические номера "AX" с контрольным символом Luhn mod 36
+	defaultSerialSchemes = "AX=luhn36:10"
 )
 
 func main() {
@@ -34,17 +72,238 @@ func main() {
 
 	er := eventRepository.NewEventRepository(pool)
 	sr := sensorRepository.NewSensorRepository(pool)
+	fr := sensorRepository.NewFirmwareHistoryRepository(pool)
 	ur := userRepository.NewUserRepository(pool)
+	tr := userRepository.NewAPITokenRepository(pool)
 	sor := userRepository.NewSensorOwnerRepository(pool)
+	hr := homeRepository.NewHomeRepository(pool)
+	rr := homeRepository.NewRoomRepository(pool)
+	hor := homeRepository.NewHomeOwnerRepository(pool)
+	gr := groupRepository.NewGroupRepository(pool)
+	policy := usecase.NewAccessPolicy(sor, hor, rr, usecase.WithGroupAccess(gr))
+	transactor := transactionRepository.NewTransactor(pool)
+	audit := usecase.NewAudit(auditRepository.NewAuditRepository(pool), usecase.WithAuditAccessPolicy(policy))
+	webhooks := usecase.NewWebhook(webhookRepository.NewWebhookRepository(pool), sr, ur,
+		usecase.WithWebhookAccessPolicy(policy),
+		usecase.WithWebhookAudit(audit),
+	)
+	outbox := usecase.NewOutbox(outboxRepository.NewOutboxRepository(pool), usecase.WithOutboxPublisher(webhooks))
+	alerts := usecase.NewAlert(alertRepository.NewAlertRepository(pool),
+		usecase.WithAlertAccessPolicy(policy),
+		usecase.WithAlertTransactor(transactor),
+		usecase.WithAlertAudit(audit),
+		usecase.WithAlertWebhooks(webhooks),
+		usecase.WithAlertFlappingSuppression(sr),
+	)
+	debounce := usecase.NewDebounce()
+	vsr := sensorRepository.NewVirtualSensorRepository(pool)
+	anomalies := usecase.NewAnomaly(sensorRepository.NewAnomalyStateRepository(pool), alerts)
+	rules := usecase.NewRule(ruleRepository.NewRuleRepository(pool), sr, alerts,
+		usecase.WithRuleAccessPolicy(policy),
+		usecase.WithRuleTransactor(transactor),
+		usecase.WithRuleAudit(audit),
+	)
+	ar := actuatorRepository.NewActuatorRepository(pool)
+	actuators := usecase.NewActuator(ar, ar, sr,
+		usecase.WithActuatorAccessPolicy(policy),
+		usecase.WithActuatorTransactor(transactor),
+		usecase.WithActuatorAudit(audit),
+	)
+	atr := automationRepository.NewAutomationRepository(pool)
+	automations := usecase.NewAutomation(atr, sr, ur,
+		usecase.WithAutomationAccessPolicy(policy),
+		usecase.WithAutomationHomes(hr),
+		usecase.WithAutomationActuators(actuators),
+		usecase.WithAutomationWebhooks(webhooks),
+		usecase.WithAutomationAlerts(alerts),
+		usecase.WithAutomationAudit(audit),
+	)
+	scheduler := usecase.NewScheduler(atr, hr, automations,
+		usecase.WithSchedulerAccessPolicy(policy),
+		usecase.WithSchedulerAudit(audit),
+	)
+
+	serials, err := newSerialRegistry()
+	if err != nil {
+		log.Fatalf("can't configure serial number schemes: %v", err)
+	}
+
+	deviceAuth := usecase.NewDeviceAuth(
+		sensorRepository.NewSensorCredentialRepository(pool),
+		sensorRepository.NewEventNonceRepository(pool),
+		sr,
+		usecase.WithDeviceAccessPolicy(policy),
+		usecase.WithDeviceAudit(audit),
+	)
 
 	useCases := httpGateway.UseCases{
-		Event:  usecase.NewEvent(er, sr),
-		Sensor: usecase.NewSensor(sr),
-		User:   usecase.NewUser(ur, sor, sr),
+		Event: usecase.NewEvent(er, sr,
+			usecase.WithEventFirmwareHistory(fr),
+			usecase.WithEventAccessPolicy(policy),
+			usecase.WithEventDebounce(debounce),
+			usecase.WithEventVirtualSensors(vsr),
+			usecase.WithEventAnomalies(anomalies),
+			usecase.WithEventRules(rules),
+			usecase.WithEventTransactor(transactor),
+			usecase.WithEventOutbox(outbox),
+			usecase.WithEventAutomations(automations),
+		),
+		Sensor: usecase.NewSensor(sr,
+			usecase.WithSensorSerialValidator(serials),
+			usecase.WithSensorOwners(ur, sor),
+			usecase.WithSensorTransactor(transactor),
+			usecase.WithFirmwareHistory(fr),
+			usecase.WithSensorAccessPolicy(policy),
+			usecase.WithSensorAudit(audit),
+			usecase.WithSensorAnomalies(anomalies),
+			usecase.WithSensorDebounce(debounce),
+			usecase.WithVirtualSensors(vsr),
+			usecase.WithSensorDeviceAuth(deviceAuth),
+		),
+		User: usecase.NewUser(ur, sor, sr,
+			usecase.WithHomeAccess(hor, rr),
+			usecase.WithUserGroups(gr),
+			usecase.WithUserTokens(tr),
+			usecase.WithUserAccessPolicy(policy),
+			usecase.WithUserTransactor(transactor),
+			usecase.WithUserAudit(audit),
+		),
+		Home: usecase.NewHome(hr, rr, hor, sr, ur,
+			usecase.WithHomeAccessPolicy(policy),
+			usecase.WithHomeAudit(audit),
+			usecase.WithHomeTransactor(transactor),
+		),
+		Group: usecase.NewGroup(gr, ur, sr,
+			usecase.WithGroupAccessPolicy(policy),
+			usecase.WithGroupAudit(audit),
+		),
+		Invitation: usecase.NewInvitation(sensorRepository.NewSensorInvitationRepository(pool), sor, sr,
+			usecase.WithInvitationAccessPolicy(policy),
+			usecase.WithInvitationTransactor(transactor),
+			usecase.WithInvitationAudit(audit),
+		),
+		Rule:       rules,
+		Alert:      alerts,
+		Webhook:    webhooks,
+		Actuator:   actuators,
+		Automation: automations,
+		Scheduler:  scheduler,
+		Audit:      audit,
+		Auth:       usecase.NewAuth(tr, ur, usecase.WithAuthAudit(audit)),
+		DeviceAuth: deviceAuth,
 	}
 
+	useCases.RateLimiter, err = newRateLimiter(sr)
+	if err != nil {
+		log.Fatalf("can't configure rate limits: %v", err)
+	}
+
+	go runPeriodically(ctx, ruleEvaluationInterval, "rule evaluation", rules.EvaluateRules)
+	go runPeriodically(ctx, outboxDispatchInterval, "outbox dispatch", outbox.Dispatch)
+	go runPeriodically(ctx, webhookDeliveryInterval, "webhook delivery", webhooks.DeliverWebhooks)
+	go runPeriodically(ctx, commandExpiryInterval, "command expiry", actuators.ExpireCommands)
+	go runPeriodically(ctx, scheduleRunInterval, "schedule run", scheduler.RunDueSchedules)
+	go runPeriodically(ctx, anomalyCheckpointInterval, "anomaly checkpoint", anomalies.Checkpoint)
+	go runPeriodically(ctx, debounceFlushInterval, "debounce flush", useCases.Event.FlushDebouncedEvents)
+	go runPeriodically(ctx, nonceCleanupInterval, "nonce cleanup", deviceAuth.DeleteExpiredNonces)
+
 	r := httpGateway.NewServer(useCases)
 	if err := r.Run(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Printf("error during server shutdown: %v", err)
 	}
+
+	// статистика, накопленная после последнего сохранения, не теряется при штатной остановке
+	if err := anomalies.Checkpoint(context.Background()); err != nil {
+		log.Printf("anomaly checkpoint error: %v", err)
+	}
+}
+
+// newSerialRegistry дополняет схему по умолчанию схемами производителей из SERIAL_SCHEMES
+func newSerialRegistry() (*serial.Registry, error) {
+	config, ok := os.LookupEnv("SERIAL_SCHEMES")
+	if !ok {
+		config = defaultSerialSchemes
+	}
+	schemes, err := serial.ParseSchemes(config)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := serial.Default()
+	for _, scheme := range schemes {
+		if err := registry.Register(scheme); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// newRateLimiter настраивает ограничение частоты запросов по переменным окружения в формате "key=rate:burst,...":
+// RATE_LIMIT_SENSORS - события по типу датчика, RATE_LIMIT_USERS - чтение по id пользователя, ключ "*" - для остальных;
+// RATE_LIMIT_CLIENTS - события с одного адреса до проверки подписи, только ключ "*".
+// Если все переменные пусты, частота не ограничивается.
+func newRateLimiter(sr usecase.SensorRepository) (*usecase.RateLimiter, error) {
+	sensorLimits, err := domain.ParseRateLimits(os.Getenv("RATE_LIMIT_SENSORS"))
+	if err != nil {
+		return nil, err
+	}
+	userLimits, err := domain.ParseRateLimits(os.Getenv("RATE_LIMIT_USERS"))
+	if err != nil {
+		return nil, err
+	}
+	clientLimits, err := domain.ParseRateLimits(os.Getenv("RATE_LIMIT_CLIENTS"))
+	if err != nil {
+		return nil, err
+	}
+	if len(sensorLimits) == 0 && len(userLimits) == 0 && len(clientLimits) == 0 {
+		return nil, nil
+	}
+
+	var options []func(*usecase.RateLimiter)
+	for key, limit := range sensorLimits {
+		switch sensorType := domain.SensorType(key); {
+		case key == "*":
+			options = append(options, usecase.WithSensorRateLimit(limit))
+		case sensorType == domain.SensorTypeADC || sensorType == domain.SensorTypeContactClosure:
+			options = append(options, usecase.WithSensorTypeRateLimit(sensorType, limit))
+		default:
+			return nil, fmt.Errorf("unknown sensor type %q", key)
+		}
+	}
+	for key, limit := range userLimits {
+		if key == "*" {
+			options = append(options, usecase.WithUserRateLimit(limit))
+			continue
+		}
+		userID, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user id %q", key)
+		}
+		options = append(options, usecase.WithUserIDRateLimit(userID, limit))
+	}
+	for key, limit := range clientLimits {
+		if key != "*" {
+			return nil, fmt.Errorf("invalid client key %q, only \"*\" is supported", key)
+		}
+		options = append(options, usecase.WithClientRateLimit(limit))
+	}
+
+	return usecase.NewRateLimiter(rateLimitRepository.NewRateLimitStore(), sr, options...), nil
+}
+
+// runPeriodically вызывает fn каждые interval, пока не отменен ctx, ошибки пишет в лог с префиксом name
+func runPeriodically(ctx context.Context, interval time.Duration, name string, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				log.Printf("%s error: %v", name, err)
+			}
+		}
+	}
 }
@@ -0,0 +1,148 @@
+package domain
+
+import "time"
+
+// ActuatorType - тип исполнительного устройства
+type ActuatorType string
+
+const (
+	// ActuatorTypeRelay - реле, состояние 0 - выключено, 1 - включено
+	ActuatorTypeRelay ActuatorType = "relay"
+	// ActuatorTypeValve - клапан, состояние - степень открытия в процентах от 0 до 100
+	ActuatorTypeValve ActuatorType = "valve"
+)
+
+// IsValid сообщает, известен ли тип
+func (t ActuatorType) IsValid() bool {
+	return t == ActuatorTypeRelay || t == ActuatorTypeValve
+}
+
+// IsValidState сообщает, может ли устройство этого типа находиться в состоянии state
+func (t ActuatorType) IsValidState(state int64) bool {
+	switch t {
+	case ActuatorTypeRelay:
+		return state == 0 || state == 1
+	case ActuatorTypeValve:
+		return state >= 0 && state <= 100
+	}
+	return false
+}
+
+// Actuator - исполнительное устройство, например реле или клапан. Команды устройству забирает и выполняет
+// контроллер, который отправляет события датчика SensorID, доступ к устройству определяется доступом к датчику.
+type Actuator struct {
+	// ID - id устройства
+	ID int64
+	// SensorID - id датчика-контроллера, через который устройство получает команды
+	SensorID int64
+	// Name - название устройства
+	Name string
+	// Type - тип устройства
+	Type ActuatorType
+	// State - последнее подтвержденное контроллером состояние
+	State int64
+	// CreatedBy - id пользователя, добавившего устройство, 0 - добавлено без аутентификации
+	CreatedBy int64
+	// CreatedAt - время добавления
+	CreatedAt time.Time
+}
+
+// CommandKind - вид команды исполнительному устройству
+type CommandKind string
+
+const (
+	// CommandSetState - перевести устройство в состояние State
+	CommandSetState CommandKind = "set_state"
+	// CommandPulse - включить устройство на Duration и вернуть в исходное состояние
+	CommandPulse CommandKind = "pulse"
+)
+
+// IsValid сообщает, известен ли вид команды
+func (k CommandKind) IsValid() bool {
+	return k == CommandSetState || k == CommandPulse
+}
+
+// CommandStatus - состояние команды
+type CommandStatus string
+
+const (
+	// CommandPending - команда ждет, пока ее заберет контроллер
+	CommandPending CommandStatus = "pending"
+	// CommandDelivered - контроллер забрал команду, но еще не сообщил о выполнении
+	CommandDelivered CommandStatus = "delivered"
+	// CommandAcked - контроллер выполнил команду
+	CommandAcked CommandStatus = "acked"
+	// CommandFailed - контроллер не смог выполнить команду
+	CommandFailed CommandStatus = "failed"
+	// CommandExpired - контроллер не выполнил команду до ExpiresAt
+	CommandExpired CommandStatus = "expired"
+)
+
+// IsValid сообщает, известно ли состояние
+func (s CommandStatus) IsValid() bool {
+	switch s {
+	case CommandPending, CommandDelivered, CommandAcked, CommandFailed, CommandExpired:
+		return true
+	}
+	return false
+}
+
+// IsOpen сообщает, ждет ли команда выполнения
+func (s CommandStatus) IsOpen() bool {
+	return s == CommandPending || s == CommandDelivered
+}
+
+// Command - команда исполнительному устройству
+type Command struct {
+	// ID - id команды
+	ID int64
+	// ActuatorID - id устройства
+	ActuatorID int64
+	// SensorID - id датчика-контроллера устройства на момент отправки команды
+	SensorID int64
+	// Kind - вид команды
+	Kind CommandKind
+	// State - целевое состояние команды CommandSetState
+	State int64
+	// Duration - длительность команды CommandPulse
+	Duration time.Duration
+	// Status - состояние команды
+	Status CommandStatus
+	// Error - причина, по которой контроллер не выполнил команду
+	Error string
+	// CreatedBy - id отправившего команду пользователя, 0 - отправлена без аутентификации
+	CreatedBy int64
+	// CreatedAt - время отправки
+	CreatedAt time.Time
+	// ExpiresAt - время, до которого контроллер должен выполнить команду
+	ExpiresAt time.Time
+	// DeliveredAt - время, когда контроллер забрал команду
+	DeliveredAt *time.Time
+	// CompletedAt - время выполнения, неудачи или истечения команды
+	CompletedAt *time.Time
+}
+
+// Expire отмечает открытую команду истекшей, если время ее выполнения прошло к now
+func (c *Command) Expire(now time.Time) bool {
+	if !c.Status.IsOpen() || now.Before(c.ExpiresAt) {
+		return false
+	}
+	expiresAt := c.ExpiresAt
+	c.Status = CommandExpired
+	c.CompletedAt = &expiresAt
+	return true
+}
+
+// CommandFilter - условия выборки истории команд, нулевые поля не ограничивают выборку
+type CommandFilter struct {
+	ActuatorID int64
+	Status     CommandStatus
+	Limit      int
+	Offset     int
+}
+
+// Matches сообщает, подходит ли команда под условия фильтра без учета пагинации
+func (f CommandFilter) Matches(command *Command) bool {
+	return (f.ActuatorID == 0 || f.ActuatorID == command.ActuatorID) &&
+		(f.Status == "" || f.Status == command.Status)
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActuatorType_IsValidState(t *testing.T) {
+	assert.True(t, ActuatorTypeRelay.IsValidState(1))
+	assert.False(t, ActuatorTypeRelay.IsValidState(2))
+	assert.True(t, ActuatorTypeValve.IsValidState(100))
+	assert.False(t, ActuatorTypeValve.IsValidState(-1))
+	assert.False(t, ActuatorType("pump").IsValidState(0))
+}
+
+func TestCommand_Expire(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	command := Command{Status: CommandDelivered, ExpiresAt: now}
+	assert.False(t, command.Expire(now.Add(-time.Second)))
+	assert.Equal(t, CommandDelivered, command.Status)
+
+	assert.True(t, command.Expire(now))
+	assert.Equal(t, CommandExpired, command.Status)
+	assert.Equal(t, now, *command.CompletedAt)
+
+	acked := Command{Status: CommandAcked, ExpiresAt: now}
+	assert.False(t, acked.Expire(now.Add(time.Hour)))
+	assert.Equal(t, CommandAcked, acked.Status)
+}
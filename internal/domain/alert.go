@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// AlertStatus - состояние оповещения
+type AlertStatus string
+
+const (
+	// AlertStatusOpen - правило сработало, оповещение еще никто не подтвердил
+	AlertStatusOpen AlertStatus = "open"
+	// AlertStatusAcknowledged - пользователь подтвердил, что видит оповещение
+	AlertStatusAcknowledged AlertStatus = "acknowledged"
+	// AlertStatusResolved - оповещение закрыто пользователем или автоматически, когда условие правила перестало выполняться
+	AlertStatusResolved AlertStatus = "resolved"
+)
+
+// IsValid сообщает, известно ли состояние
+func (s AlertStatus) IsValid() bool {
+	switch s {
+	case AlertStatusOpen, AlertStatusAcknowledged, AlertStatusResolved:
+		return true
+	}
+	return false
+}
+
+// Alert - оповещение о срабатывании правила, автоматизации или об аномальных значениях датчика.
+// Пока оповещение не закрыто, повторные срабатывания не создают новых оповещений, а увеличивают Occurrences.
+type Alert struct {
+	// ID - id оповещения
+	ID int64
+	// RuleID - id сработавшего правила, 0 - оповещение создано автоматизацией
+	RuleID int64
+	// AutomationID - id создавшей оповещение автоматизации, 0 - оповещение создано правилом
+	AutomationID int64
+	// Anomaly - оповещение об аномальных значениях датчика, RuleID и AutomationID у него равны 0
+	Anomaly bool
+	// SensorID - id датчика правила или триггера автоматизации
+	SensorID int64
+	// Status - состояние оповещения
+	Status AlertStatus
+	// Value - значение датчика при последнем срабатывании
+	Value float64
+	// Occurrences - число срабатываний правила, учтенных в оповещении
+	Occurrences int
+	// CreatedAt - время первого срабатывания
+	CreatedAt time.Time
+	// LastOccurredAt - время последнего срабатывания
+	LastOccurredAt time.Time
+	// AcknowledgedAt - время подтверждения, nil - не подтверждено
+	AcknowledgedAt *time.Time
+	// AcknowledgedBy - id подтвердившего пользователя, 0 - подтверждено без аутентификации
+	AcknowledgedBy int64
+	// ResolvedAt - время закрытия, nil - не закрыто
+	ResolvedAt *time.Time
+	// ResolvedBy - id закрывшего пользователя, 0 - закрыто автоматически или без аутентификации
+	ResolvedBy int64
+}
+
+// IsActive сообщает, открыто ли оповещение: новые срабатывания правила учитываются в нем
+func (a *Alert) IsActive() bool {
+	return a.Status != AlertStatusResolved
+}
+
+// AlertFilter - условия выборки оповещений, нулевые поля не ограничивают выборку
+type AlertFilter struct {
+	RuleID   int64
+	SensorID int64
+	Status   AlertStatus
+	// SensorIDs - датчики, оповещения которых можно выбирать, nil - любые
+	SensorIDs []int64
+	Limit     int
+	Offset    int
+}
+
+// Matches сообщает, подходит ли оповещение под условия фильтра без учета пагинации
+func (f AlertFilter) Matches(alert *Alert) bool {
+	return (f.RuleID == 0 || f.RuleID == alert.RuleID) &&
+		(f.SensorID == 0 || f.SensorID == alert.SensorID) &&
+		(f.Status == "" || f.Status == alert.Status) &&
+		(f.SensorIDs == nil || slices.Contains(f.SensorIDs, alert.SensorID))
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// DefaultAnomalyAlpha - вес нового значения в статистике, если он не задан
+	DefaultAnomalyAlpha = 0.05
+	// DefaultAnomalySigma - порог оценки аномальности, если он не задан
+	DefaultAnomalySigma = 3
+	// DefaultAnomalyWarmUp - сколько значений накапливается до первой оценки, если не задано
+	DefaultAnomalyWarmUp = 30
+	// MaxAnomalyScore - оценка значения, отличного от всех предыдущих, если они были одинаковыми
+	MaxAnomalyScore = 100
+)
+
+// AnomalyDetection - настройки обнаружения аномалий датчика АЦП. Значение сравнивается с экспоненциально
+// взвешенными скользящими средним и дисперсией предыдущих значений, поэтому нормальный диапазон может дрейфовать.
+type AnomalyDetection struct {
+	// Alpha - вес нового значения в статистике, от 0 до 1: чем больше, тем быстрее статистика следует за дрейфом
+	Alpha float64
+	// Sigma - порог: значение, отклоняющееся от среднего на Sigma стандартных отклонений и больше, аномально
+	Sigma float64
+	// WarmUp - сколько значений накапливается, прежде чем события начинают оцениваться
+	WarmUp int
+}
+
+// AnomalyState - накопленная статистика значений датчика
+type AnomalyState struct {
+	// SensorID - id датчика
+	SensorID int64
+	// Mean - скользящее среднее
+	Mean float64
+	// Variance - скользящая дисперсия
+	Variance float64
+	// Count - сколько значений учтено
+	Count int64
+	// UpdatedAt - время последнего учтенного значения
+	UpdatedAt time.Time
+}
+
+// Detect оценивает значение по статистике state и учитывает его в ней. Оценка - отклонение от среднего
+// в стандартных отклонениях, scored равно false, пока статистика накапливается.
+func (d *AnomalyDetection) Detect(state *AnomalyState, value float64) (score float64, scored bool) {
+	if state.Count >= int64(d.WarmUp) {
+		score, scored = state.score(value), true
+	}
+
+	if state.Count == 0 {
+		state.Mean = value
+	} else {
+		diff := value - state.Mean
+		increment := d.Alpha * diff
+		state.Mean += increment
+		state.Variance = (1 - d.Alpha) * (state.Variance + diff*increment)
+	}
+	state.Count++
+
+	return score, scored
+}
+
+// IsAnomalous сообщает, превышает ли оценка порог
+func (d *AnomalyDetection) IsAnomalous(score float64) bool {
+	return score >= d.Sigma
+}
+
+func (s *AnomalyState) score(value float64) float64 {
+	deviation := math.Abs(value - s.Mean)
+	if deviation == 0 {
+		return 0
+	}
+	deviation /= math.Sqrt(s.Variance)
+	if math.IsInf(deviation, 0) || math.IsNaN(deviation) {
+		return MaxAnomalyScore
+	}
+	return math.Min(deviation, MaxAnomalyScore)
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnomalyDetection_Detect(t *testing.T) {
+	d := &AnomalyDetection{Alpha: 0.1, Sigma: 3, WarmUp: 10}
+
+	t.Run("warm up", func(t *testing.T) {
+		state := &AnomalyState{}
+		for i := 0; i < 10; i++ {
+			_, scored := d.Detect(state, 100)
+			assert.False(t, scored, i)
+		}
+		assert.Equal(t, int64(10), state.Count)
+		assert.Equal(t, 100.0, state.Mean)
+		assert.Zero(t, state.Variance)
+
+		score, scored := d.Detect(state, 100)
+		require.True(t, scored)
+		assert.Zero(t, score)
+	})
+
+	t.Run("constant values, then a change", func(t *testing.T) {
+		state := &AnomalyState{Count: 10, Mean: 100}
+		score, scored := d.Detect(state, 101)
+		require.True(t, scored)
+		assert.Equal(t, float64(MaxAnomalyScore), score)
+		assert.True(t, d.IsAnomalous(score))
+	})
+
+	t.Run("noise is normal, spike is anomalous", func(t *testing.T) {
+		state := &AnomalyState{}
+		for i := 0; i < 200; i++ {
+			d.Detect(state, float64(100+i%2*2))
+		}
+		assert.InDelta(t, 101, state.Mean, 0.2)
+
+		score, _ := d.Detect(state, 102)
+		assert.False(t, d.IsAnomalous(score), score)
+		score, _ = d.Detect(state, 120)
+		assert.True(t, d.IsAnomalous(score), score)
+	})
+
+	t.Run("statistics follow drift", func(t *testing.T) {
+		state := &AnomalyState{}
+		value := 100.0
+		for i := 0; i < 300; i++ {
+			value += 0.5 + float64(i%3)
+			score, scored := d.Detect(state, value)
+			if scored {
+				assert.False(t, d.IsAnomalous(score), "value %v, score %v", value, score)
+			}
+		}
+	})
+}
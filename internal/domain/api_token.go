@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// APIToken - персональный токен доступа пользователя к API.
+// Сам токен не хранится, только его хэш.
+type APIToken struct {
+	// ID - id токена
+	ID int64
+	// UserID - id владельца токена
+	UserID int64
+	// Name - название токена, которое задал пользователь
+	Name string
+	// Hash - хэш токена в hex, в JSON, в том числе в журнал аудита, не попадает
+	Hash string `json:"-"`
+	// CreatedAt - время создания
+	CreatedAt time.Time
+	// ExpiresAt - время, после которого токен недействителен, nil - бессрочный
+	ExpiresAt *time.Time
+	// RevokedAt - время отзыва, nil - токен не отозван
+	RevokedAt *time.Time
+}
+
+// IsActive сообщает, можно ли аутентифицироваться токеном в момент now
+func (t *APIToken) IsActive(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// AuditAction - вид изменения в журнале аудита
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+	// AuditActionAttach - привязка, например датчика к пользователю или пользователя к группе
+	AuditActionAttach AuditAction = "attach"
+	// AuditActionDetach - отвязка
+	AuditActionDetach AuditAction = "detach"
+	AuditActionRevoke AuditAction = "revoke"
+	AuditActionAccept AuditAction = "accept"
+	AuditActionRotate AuditAction = "rotate"
+	// AuditActionAcknowledge - подтверждение оповещения
+	AuditActionAcknowledge AuditAction = "acknowledge"
+	// AuditActionResolve - закрытие оповещения пользователем
+	AuditActionResolve AuditAction = "resolve"
+)
+
+// AuditEntityType - тип сущности в журнале аудита
+type AuditEntityType string
+
+const (
+	AuditEntitySensor           AuditEntityType = "sensor"
+	AuditEntityUser             AuditEntityType = "user"
+	AuditEntityHome             AuditEntityType = "home"
+	AuditEntityRoom             AuditEntityType = "room"
+	AuditEntityGroup            AuditEntityType = "group"
+	AuditEntityAPIToken         AuditEntityType = "api_token"
+	AuditEntitySensorCredential AuditEntityType = "sensor_credential"
+	AuditEntitySensorInvitation AuditEntityType = "sensor_invitation"
+	AuditEntityRule             AuditEntityType = "rule"
+	AuditEntityAlert            AuditEntityType = "alert"
+	AuditEntityWebhook          AuditEntityType = "webhook"
+	AuditEntityActuator         AuditEntityType = "actuator"
+	AuditEntityCommand          AuditEntityType = "command"
+	AuditEntityAutomation       AuditEntityType = "automation"
+	AuditEntityScene            AuditEntityType = "scene"
+	AuditEntitySchedule         AuditEntityType = "schedule"
+)
+
+// AuditEntry - запись журнала аудита об одном изменении. Записи только добавляются.
+type AuditEntry struct {
+	// ID - id записи
+	ID int64
+	// ActorID - id пользователя, выполнившего изменение, 0 - запрос без аутентификации
+	ActorID int64
+	// Action - вид изменения
+	Action AuditAction
+	// EntityType - тип измененной сущности
+	EntityType AuditEntityType
+	// EntityID - id измененной сущности. Изменения привязок записываются на сущность, к которой привязывают.
+	EntityID int64
+	// Changes - измененные поля: имя поля -> значение до и после
+	Changes map[string]AuditChange
+	// RequestID - id запроса, в котором сделано изменение
+	RequestID string
+	// SourceIP - адрес, с которого пришел запрос
+	SourceIP string
+	// CreatedAt - время изменения
+	CreatedAt time.Time
+}
+
+// AuditChange - значение поля до и после изменения, nil - поля не было
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditFilter - условия выборки журнала аудита, нулевые поля не ограничивают выборку
+type AuditFilter struct {
+	EntityType AuditEntityType
+	EntityID   int64
+	ActorID    int64
+	Limit      int
+	Offset     int
+}
+
+// Matches сообщает, подходит ли запись под условия фильтра без учета пагинации
+func (f AuditFilter) Matches(entry *AuditEntry) bool {
+	return (f.EntityType == "" || f.EntityType == entry.EntityType) &&
+		(f.EntityID == 0 || f.EntityID == entry.EntityID) &&
+		(f.ActorID == 0 || f.ActorID == entry.ActorID)
+}
+
+// AuditDiff сравнивает снимки сущности до и после изменения и возвращает различающиеся поля.
+// Снимки сравниваются в JSON-представлении, nil означает отсутствие сущности.
+func AuditDiff(before, after any) (map[string]AuditChange, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]AuditChange)
+	for name, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[name]) {
+			changes[name] = AuditChange{Before: value, After: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok && value != nil {
+			changes[name] = AuditChange{After: value}
+		}
+	}
+	return changes, nil
+}
+
+func auditFields(snapshot any) (map[string]any, error) {
+	fields := make(map[string]any)
+	if snapshot == nil || reflect.ValueOf(snapshot).Kind() == reflect.Pointer && reflect.ValueOf(snapshot).IsNil() {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditDiff(t *testing.T) {
+	t.Run("create, fields appear as added", func(t *testing.T) {
+		changes, err := AuditDiff(nil, &Home{ID: 1, Name: "home"})
+		require.NoError(t, err)
+		assert.Equal(t, AuditChange{After: "home"}, changes["Name"])
+		assert.Equal(t, AuditChange{After: float64(1)}, changes["ID"])
+	})
+
+	t.Run("update, unchanged fields are skipped", func(t *testing.T) {
+		changes, err := AuditDiff(&Home{ID: 1, Name: "old"}, &Home{ID: 1, Name: "new"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]AuditChange{"Name": {Before: "old", After: "new"}}, changes)
+	})
+
+	t.Run("delete, nil pointer is an empty snapshot", func(t *testing.T) {
+		var deleted *Home
+		changes, err := AuditDiff(&Home{ID: 1, Name: "home"}, deleted)
+		require.NoError(t, err)
+		assert.Equal(t, AuditChange{Before: "home"}, changes["Name"])
+	})
+
+	t.Run("secrets are not exported", func(t *testing.T) {
+		changes, err := AuditDiff(nil, &APIToken{ID: 1, Hash: "secret"})
+		require.NoError(t, err)
+		assert.NotContains(t, changes, "Hash")
+	})
+}
@@ -0,0 +1,260 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// AutomationTrigger - событие, запускающее автоматизацию: состояние датчика SensorID стало удовлетворять
+// условию Operator Value. Сравнивается CurrentState датчика без калибровки.
+type AutomationTrigger struct {
+	// SensorID - id датчика
+	SensorID int64
+	// Operator - сравнение состояния датчика с Value
+	Operator RuleOperator
+	// Value - значение, с которым сравнивается состояние
+	Value float64
+}
+
+// Fires сообщает, срабатывает ли триггер при переходе состояния датчика из previous в current.
+// Триггер срабатывает только при переходе, поэтому повторные события с тем же состоянием его не запускают.
+func (t *AutomationTrigger) Fires(previous, current int64) bool {
+	return t.Operator.Compare(float64(current), t.Value) && !t.Operator.Compare(float64(previous), t.Value)
+}
+
+// ConditionType - вид условия автоматизации
+type ConditionType string
+
+const (
+	// ConditionSensorState - состояние датчика SensorID удовлетворяет условию Operator Value
+	ConditionSensorState ConditionType = "sensor_state"
+	// ConditionTimeWindow - текущее время попадает в окно Window
+	ConditionTimeWindow ConditionType = "time_window"
+	// ConditionSun - текущее время попадает в интервал Sun относительно восхода и заката в доме HomeID
+	ConditionSun ConditionType = "sun"
+)
+
+// IsValid сообщает, известен ли вид условия
+func (t ConditionType) IsValid() bool {
+	return t == ConditionSensorState || t == ConditionTimeWindow || t == ConditionSun
+}
+
+// sunConditionDays - на сколько дней вокруг текущего ищутся границы интервала SunCondition
+const sunConditionDays = 2
+
+// SunCondition - интервал между восходом и закатом со смещениями, например "после заката" (After "sunset")
+// или "с sunrise+1h до sunset-30m". Незаданная граница - противоположное событие без смещения,
+// поэтому "после заката" длится до восхода. Интервал может переходить через полночь.
+type SunCondition struct {
+	// After - начало интервала в формате расписаний, например "sunset+30m"
+	After string
+	// Before - конец интервала в том же формате
+	Before string
+}
+
+// IsValid сообщает, задана ли хотя бы одна граница и верны ли обе
+func (c *SunCondition) IsValid() bool {
+	_, _, err := c.bounds()
+	return err == nil
+}
+
+// Contains сообщает, попадает ли момент now в интервал по календарю часового пояса loc и координатам дома.
+// В полярный день и полярную ночь, когда границ нет, условие не выполняется.
+func (c *SunCondition) Contains(now time.Time, loc *time.Location, coordinates Coordinates) bool {
+	after, before, err := c.bounds()
+	if err != nil {
+		return false
+	}
+
+	local := now.In(loc)
+	day := func(i int) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+i, 12, 0, 0, 0, loc)
+	}
+	// начало - последний момент After не позже now, конец - первый момент Before после начала
+	for i := 1; i >= -sunConditionDays; i-- {
+		start, ok := after.On(day(i), coordinates)
+		if !ok || start.After(now) {
+			continue
+		}
+		for j := i - 1; j <= i+sunConditionDays; j++ {
+			if end, ok := before.On(day(j), coordinates); ok && end.After(start) {
+				return now.Before(end)
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// bounds возвращает границы интервала, подставляя противоположное событие вместо незаданной
+func (c *SunCondition) bounds() (after, before SunTime, err error) {
+	if c.After == "" && c.Before == "" {
+		return SunTime{}, SunTime{}, errors.New("sun condition without bounds")
+	}
+	if c.After != "" {
+		if after, err = ParseSunTime(c.After); err != nil {
+			return SunTime{}, SunTime{}, err
+		}
+	}
+	if c.Before != "" {
+		if before, err = ParseSunTime(c.Before); err != nil {
+			return SunTime{}, SunTime{}, err
+		}
+	}
+
+	if c.After == "" {
+		after = SunTime{Event: before.Event.opposite()}
+	}
+	if c.Before == "" {
+		before = SunTime{Event: after.Event.opposite()}
+	}
+	return after, before, nil
+}
+
+// AutomationCondition - условие, которое проверяется после срабатывания триггера.
+// Заполняются только поля, относящиеся к виду условия.
+type AutomationCondition struct {
+	// Type - вид условия
+	Type ConditionType
+	// SensorID, Operator и Value - условие на CurrentState датчика для ConditionSensorState
+	SensorID int64
+	Operator RuleOperator
+	Value    float64
+	// Window - время суток для ConditionTimeWindow
+	Window *RuleWindow
+	// HomeID и Sun - дом, по координатам и часовому поясу которого считается интервал, для ConditionSun
+	HomeID int64
+	Sun    *SunCondition
+}
+
+// ActionType - вид действия автоматизации или сцены
+type ActionType string
+
+const (
+	// ActionCommand - отправить команду исполнительному устройству ActuatorID
+	ActionCommand ActionType = "command"
+	// ActionWebhook - отправить уведомление подписке WebhookID
+	ActionWebhook ActionType = "webhook"
+	// ActionAlert - создать оповещение по датчику триггера, только для автоматизаций
+	ActionAlert ActionType = "alert"
+	// ActionScene - запустить сцену SceneID, только для автоматизаций
+	ActionScene ActionType = "scene"
+)
+
+// IsValid сообщает, известен ли вид действия
+func (t ActionType) IsValid() bool {
+	switch t {
+	case ActionCommand, ActionWebhook, ActionAlert, ActionScene:
+		return true
+	}
+	return false
+}
+
+// AutomationAction - действие автоматизации или сцены. Заполняются только поля, относящиеся к виду действия.
+type AutomationAction struct {
+	// Type - вид действия
+	Type ActionType
+	// ActuatorID, Command, State и Duration - команда устройству для ActionCommand
+	ActuatorID int64
+	Command    CommandKind
+	State      int64
+	Duration   time.Duration
+	// WebhookID - подписка для ActionWebhook
+	WebhookID int64
+	// SceneID - сцена для ActionScene
+	SceneID int64
+}
+
+// Automation - автоматизация: когда срабатывает Trigger и выполняются все Conditions, выполняются Actions.
+// Автоматизация выполняется с правами своего автора.
+type Automation struct {
+	// ID - id автоматизации
+	ID int64
+	// Name - название
+	Name string
+	// Enabled - включена ли автоматизация
+	Enabled bool
+	// Trigger - запускающее событие
+	Trigger AutomationTrigger
+	// Conditions - условия, которые должны выполняться все
+	Conditions []AutomationCondition
+	// Actions - действия, выполняются по порядку
+	Actions []AutomationAction
+	// CreatedBy - id пользователя, создавшего автоматизацию, 0 - создана без аутентификации
+	CreatedBy int64
+	// CreatedAt - время создания
+	CreatedAt time.Time
+}
+
+// Scene - сцена: набор действий, который запускается по запросу
+type Scene struct {
+	// ID - id сцены
+	ID int64
+	// Name - название
+	Name string
+	// Actions - действия, выполняются по порядку
+	Actions []AutomationAction
+	// CreatedBy - id пользователя, создавшего сцену, 0 - создана без аутентификации
+	CreatedBy int64
+	// CreatedAt - время создания
+	CreatedAt time.Time
+}
+
+// AutomationRunStatus - результат запуска автоматизации или сцены
+type AutomationRunStatus string
+
+const (
+	// AutomationRunSucceeded - все действия выполнены
+	AutomationRunSucceeded AutomationRunStatus = "succeeded"
+	// AutomationRunFailed - хотя бы одно действие не выполнено
+	AutomationRunFailed AutomationRunStatus = "failed"
+	// AutomationRunSkipped - триггер сработал, но условия не выполнились, или запуск по расписанию пропущен
+	AutomationRunSkipped AutomationRunStatus = "skipped"
+)
+
+// IsValid сообщает, известен ли результат
+func (s AutomationRunStatus) IsValid() bool {
+	switch s {
+	case AutomationRunSucceeded, AutomationRunFailed, AutomationRunSkipped:
+		return true
+	}
+	return false
+}
+
+// AutomationRun - запись журнала запусков автоматизации, сцены или расписания
+type AutomationRun struct {
+	// ID - id запуска
+	ID int64
+	// AutomationID - id запущенной автоматизации, 0 - запущена сцена или расписание
+	AutomationID int64
+	// SceneID - id запущенной вручную сцены
+	SceneID int64
+	// ScheduleID - id сработавшего расписания
+	ScheduleID int64
+	// SensorID - id датчика, событие которого запустило автоматизацию
+	SensorID int64
+	// Status - результат запуска
+	Status AutomationRunStatus
+	// Error - причины, по которым действия не выполнены
+	Error string
+	// CreatedAt - время запуска
+	CreatedAt time.Time
+}
+
+// AutomationRunFilter - условия выборки журнала запусков, нулевые поля не ограничивают выборку
+type AutomationRunFilter struct {
+	AutomationID int64
+	SceneID      int64
+	ScheduleID   int64
+	Status       AutomationRunStatus
+	Limit        int
+	Offset       int
+}
+
+// Matches сообщает, подходит ли запуск под условия фильтра без учета пагинации
+func (f AutomationRunFilter) Matches(run *AutomationRun) bool {
+	return (f.AutomationID == 0 || f.AutomationID == run.AutomationID) &&
+		(f.SceneID == 0 || f.SceneID == run.SceneID) &&
+		(f.ScheduleID == 0 || f.ScheduleID == run.ScheduleID) &&
+		(f.Status == "" || f.Status == run.Status)
+}
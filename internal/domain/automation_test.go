@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutomationTrigger_Fires(t *testing.T) {
+	opens := AutomationTrigger{SensorID: 5, Operator: RuleOperatorEqual, Value: 1}
+	assert.True(t, opens.Fires(0, 1))
+	assert.False(t, opens.Fires(1, 1))
+	assert.False(t, opens.Fires(1, 0))
+
+	hot := AutomationTrigger{SensorID: 1, Operator: RuleOperatorGreater, Value: 800}
+	assert.True(t, hot.Fires(700, 900))
+	assert.False(t, hot.Fires(850, 900))
+}
+
+func TestAutomationRunFilter_Matches(t *testing.T) {
+	run := &AutomationRun{AutomationID: 1, Status: AutomationRunFailed}
+	assert.True(t, AutomationRunFilter{AutomationID: 1}.Matches(run))
+	assert.False(t, AutomationRunFilter{SceneID: 1}.Matches(run))
+	assert.False(t, AutomationRunFilter{AutomationID: 1, Status: AutomationRunSucceeded}.Matches(run))
+}
+
+func TestSunCondition_Contains(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	city := Coordinates{Latitude: 55.7558, Longitude: 37.6173}
+	arctic := Coordinates{Latitude: 68.97, Longitude: 33.07}
+	// 21 декабря 2024 в Москве восход около 8:58, закат около 15:58
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 12, 21, hour, minute, 0, 0, moscow)
+	}
+
+	assert.False(t, (&SunCondition{}).IsValid())
+	assert.False(t, (&SunCondition{After: "noon"}).IsValid())
+	assert.False(t, (&SunCondition{Before: "sunset+13h"}).IsValid())
+
+	afterSunset := &SunCondition{After: "sunset"}
+	require.True(t, afterSunset.IsValid())
+	assert.True(t, afterSunset.Contains(at(18, 0), moscow, city))
+	assert.True(t, afterSunset.Contains(at(3, 0), moscow, city), "night lasts until sunrise")
+	assert.False(t, afterSunset.Contains(at(12, 0), moscow, city))
+
+	daytime := &SunCondition{After: "sunrise+1h", Before: "sunset-30m"}
+	assert.False(t, daytime.Contains(at(9, 30), moscow, city))
+	assert.True(t, daytime.Contains(at(12, 0), moscow, city))
+	assert.False(t, daytime.Contains(at(15, 45), moscow, city))
+
+	beforeSunrise := &SunCondition{Before: "sunrise"}
+	assert.True(t, beforeSunrise.Contains(at(8, 0), moscow, city))
+	assert.False(t, beforeSunrise.Contains(at(10, 0), moscow, city))
+
+	assert.False(t, afterSunset.Contains(at(18, 0), moscow, arctic), "polar night")
+}
@@ -0,0 +1,83 @@
+package domain
+
+import "sort"
+
+// Calibration - профиль калибровки АЦП датчика
+// Значение в инженерных единицах вычисляется как Scale*f(raw) + Offset, где f - таблица,
+// полином или тождественное преобразование, если ни таблица, ни полином не заданы.
+type Calibration struct {
+	// Offset - смещение
+	Offset float64
+	// Scale - масштаб
+	Scale float64
+	// Polynomial - коэффициенты полинома a0 + a1*x + a2*x^2 + ...
+	Polynomial []float64
+	// Table - таблица соответствия сырых значений инженерным, между точками используется линейная интерполяция
+	Table []CalibrationPoint
+	// Unit - единица измерения
+	Unit string
+}
+
+// CalibrationPoint - точка таблицы калибровки
+type CalibrationPoint struct {
+	// Raw - сырое значение АЦП
+	Raw int64
+	// Value - значение в инженерных единицах
+	Value float64
+}
+
+// Convert переводит сырое значение АЦП в инженерные единицы
+func (c *Calibration) Convert(raw int64) float64 {
+	x := float64(raw)
+
+	switch {
+	case len(c.Table) > 0:
+		x = c.interpolate(raw)
+	case len(c.Polynomial) > 0:
+		result := 0.0
+		for i := len(c.Polynomial) - 1; i >= 0; i-- {
+			result = result*x + c.Polynomial[i]
+		}
+		x = result
+	}
+
+	return c.Scale*x + c.Offset
+}
+
+// interpolate - кусочно-линейная интерполяция по таблице, за ее пределами продолжаются крайние отрезки
+func (c *Calibration) interpolate(raw int64) float64 {
+	if len(c.Table) == 1 {
+		return c.Table[0].Value
+	}
+
+	i := sort.Search(len(c.Table), func(i int) bool { return c.Table[i].Raw >= raw })
+	switch {
+	case i == 0:
+		i = 1
+	case i == len(c.Table):
+		i = len(c.Table) - 1
+	}
+
+	left, right := c.Table[i-1], c.Table[i]
+	k := float64(raw-left.Raw) / float64(right.Raw-left.Raw)
+	return left.Value + k*(right.Value-left.Value)
+}
+
+// ApplyCalibration заполняет у события значение в инженерных единицах, если у датчика задана калибровка
+// или датчик вычисляемый. Сырое значение события не изменяется.
+func (s *Sensor) ApplyCalibration(event *Event) {
+	if event == nil {
+		return
+	}
+
+	switch {
+	case s.Calibration != nil:
+		value := s.Calibration.Convert(event.Payload)
+		event.Value = &value
+		event.Unit = s.Calibration.Unit
+	case s.Virtual != nil:
+		value := s.Virtual.Convert(event.Payload)
+		event.Value = &value
+		event.Unit = s.Virtual.Unit
+	}
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalibration_Convert(t *testing.T) {
+	tests := []struct {
+		name        string
+		calibration Calibration
+		raw         int64
+		want        float64
+	}{
+		{
+			name:        "scale and offset",
+			calibration: Calibration{Scale: 0.5, Offset: -10},
+			raw:         100,
+			want:        40,
+		},
+		{
+			name:        "polynomial",
+			calibration: Calibration{Scale: 1, Polynomial: []float64{1, 2, 3}},
+			raw:         2,
+			want:        17,
+		},
+		{
+			name: "table, between points",
+			calibration: Calibration{Scale: 1, Table: []CalibrationPoint{
+				{Raw: 0, Value: 0},
+				{Raw: 100, Value: 10},
+				{Raw: 200, Value: 50},
+			}},
+			raw:  150,
+			want: 30,
+		},
+		{
+			name: "table, exact point",
+			calibration: Calibration{Scale: 1, Table: []CalibrationPoint{
+				{Raw: 0, Value: 0},
+				{Raw: 100, Value: 10},
+			}},
+			raw:  100,
+			want: 10,
+		},
+		{
+			name: "table, extrapolation below",
+			calibration: Calibration{Scale: 1, Table: []CalibrationPoint{
+				{Raw: 0, Value: 0},
+				{Raw: 100, Value: 10},
+			}},
+			raw:  -100,
+			want: -10,
+		},
+		{
+			name: "table with scale and offset",
+			calibration: Calibration{Scale: 2, Offset: 1, Table: []CalibrationPoint{
+				{Raw: 0, Value: 0},
+				{Raw: 100, Value: 10},
+			}},
+			raw:  300,
+			want: 61,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, tt.calibration.Convert(tt.raw), 1e-9)
+		})
+	}
+}
+
+func TestSensor_ApplyCalibration(t *testing.T) {
+	t.Run("no calibration", func(t *testing.T) {
+		event := &Event{Payload: 10}
+		(&Sensor{}).ApplyCalibration(event)
+		assert.Nil(t, event.Value)
+		assert.Empty(t, event.Unit)
+	})
+
+	t.Run("raw payload is kept", func(t *testing.T) {
+		event := &Event{Payload: 10}
+		(&Sensor{Calibration: &Calibration{Scale: 0.1, Unit: "°C"}}).ApplyCalibration(event)
+		assert.Equal(t, int64(10), event.Payload)
+		assert.InDelta(t, 1.0, *event.Value, 1e-9)
+		assert.Equal(t, "°C", event.Unit)
+	})
+}
@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchLimit - насколько далеко вперед ищется следующее срабатывание cron-выражения
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// CronExpression - разобранное cron-выражение из пяти полей: минута, час, день месяца, месяц, день недели
+type CronExpression struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+	// anyDayOfMonth и anyDayOfWeek - поле начинается с "*", иначе дни месяца и недели объединяются по "или"
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+// cronField - допустимый диапазон значений поля и имена значений
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronMinute     = cronField{name: "minute", min: 0, max: 59}
+	cronHour       = cronField{name: "hour", min: 0, max: 23}
+	cronDayOfMonth = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth      = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// воскресенье можно задать и как 0, и как 7
+	cronDayOfWeek = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron разбирает cron-выражение вида "*/15 7-9 * * mon-fri": списки через запятую, диапазоны,
+// шаги, имена месяцев и дней недели, а также сокращения @hourly, @daily, @weekly, @monthly и @yearly
+func ParseCron(expr string) (*CronExpression, error) {
+	expr = strings.ToLower(strings.TrimSpace(expr))
+	if macro, ok := cronMacros[expr]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields, got %d", len(fields))
+	}
+
+	var (
+		c   CronExpression
+		err error
+	)
+	for i, f := range []struct {
+		dst   *uint64
+		field cronField
+	}{
+		{&c.minute, cronMinute},
+		{&c.hour, cronHour},
+		{&c.dayOfMonth, cronDayOfMonth},
+		{&c.month, cronMonth},
+		{&c.dayOfWeek, cronDayOfWeek},
+	} {
+		if *f.dst, err = f.field.parse(fields[i]); err != nil {
+			return nil, err
+		}
+	}
+	if c.dayOfWeek&(1<<7) != 0 {
+		c.dayOfWeek = c.dayOfWeek&^(1<<7) | 1
+	}
+	c.anyDayOfMonth = strings.HasPrefix(fields[2], "*")
+	c.anyDayOfWeek = strings.HasPrefix(fields[4], "*")
+
+	return &c, nil
+}
+
+// parse возвращает битовую маску значений поля
+func (f cronField) parse(s string) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(s, ",") {
+		rangePart, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			var err error
+			rangePart = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid %s step %q", f.name, part)
+			}
+		}
+
+		from, to := f.min, f.max
+		switch i := strings.IndexByte(rangePart, '-'); {
+		case rangePart == "*":
+		case i >= 0:
+			var err error
+			if from, err = f.value(rangePart[:i]); err != nil {
+				return 0, err
+			}
+			if to, err = f.value(rangePart[i+1:]); err != nil {
+				return 0, err
+			}
+			if from > to {
+				return 0, fmt.Errorf("invalid %s range %q", f.name, rangePart)
+			}
+		default:
+			var err error
+			if from, err = f.value(rangePart); err != nil {
+				return 0, err
+			}
+			// "5/10" означает "с 5 до конца диапазона с шагом 10"
+			to = from
+			if rangePart != part {
+				to = f.max
+			}
+		}
+
+		for v := from; v <= to; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	return mask, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[s]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s %q", f.name, s)
+	}
+	return v, nil
+}
+
+// Next возвращает ближайший момент срабатывания строго после after в часовом поясе after.
+// Если срабатываний в ближайшие пять лет нет (например, "0 0 30 2 *"), возвращает нулевое время.
+func (c *CronExpression) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		var next time.Time
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			next = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.matchesDay(t):
+			next = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			next = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case c.minute&(1<<uint(t.Minute())) == 0 || repeatsWallClock(t):
+			next = t.Add(time.Minute)
+		default:
+			return t
+		}
+		// при переводе часов назад time.Date может вернуть уже пройденный момент
+		if !next.After(t) {
+			next = t.Add(time.Minute)
+		}
+		t = next
+	}
+	return time.Time{}
+}
+
+func (c *CronExpression) matchesDay(t time.Time) bool {
+	dayOfMonth := c.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := c.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	if c.anyDayOfMonth || c.anyDayOfWeek {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+// repeatsWallClock сообщает, что при переводе часов назад показания часов в момент t уже были,
+// чтобы такое время срабатывало один раз
+func repeatsWallClock(t time.Time) bool {
+	_, offset := t.Zone()
+	_, earlierOffset := t.Add(-3 * time.Hour).Zone()
+	if earlierOffset <= offset {
+		return false
+	}
+	earlier := t.Add(-time.Duration(earlierOffset-offset) * time.Second)
+	return earlier.Hour() == t.Hour() && earlier.Minute() == t.Minute()
+}
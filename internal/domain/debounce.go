@@ -0,0 +1,81 @@
+package domain
+
+import "time"
+
+// Debounce - настройки подавления дребезга датчика сухого контакта
+type Debounce struct {
+	// Window - смена состояния, продержавшаяся меньше Window, подавляется, 0 - дребезг не подавляется
+	Window time.Duration
+	// FlapThreshold - датчик, переключившийся больше FlapThreshold раз за FlapWindow, нестабилен, 0 - не отслеживается
+	FlapThreshold int
+	// FlapWindow - окно подсчета переключений
+	FlapWindow time.Duration
+}
+
+// DebounceState - состояние подавления дребезга датчика
+type DebounceState struct {
+	// Accepted - последнее принятое состояние
+	Accepted int64
+	// Reported - последнее полученное от датчика состояние
+	Reported int64
+	// Pending - смена состояния, которая еще не продержалась окно, nil - нет
+	Pending *Event
+	// PendingSince - когда получена отложенная смена состояния
+	PendingSince time.Time
+	// Transitions - моменты переключений датчика в пределах окна FlapWindow
+	Transitions []time.Time
+}
+
+// Receive учитывает событие, полученное в момент now, и возвращает события, которые нужно принять:
+// отложенную смену состояния, продержавшуюся окно, и само событие, если оно не подавлено и не отложено
+func (d *Debounce) Receive(state *DebounceState, event *Event, now time.Time) []*Event {
+	var accepted []*Event
+	if due := d.Due(state, now); due != nil {
+		accepted = append(accepted, due)
+	}
+
+	if event.Payload != state.Reported {
+		state.Transitions = append(state.Transitions, now)
+		state.Reported = event.Payload
+	}
+
+	switch {
+	case d.Window == 0 || event.Payload == state.Accepted && state.Pending == nil:
+		state.Accepted = event.Payload
+		return append(accepted, event)
+	case event.Payload == state.Accepted:
+		// возврат в принятое состояние до окончания окна - дребезг, подавляется вместе с отложенной сменой
+		state.Pending = nil
+	case state.Pending == nil || state.Pending.Payload != event.Payload:
+		state.Pending, state.PendingSince = event, now
+	}
+	return accepted
+}
+
+// Due возвращает отложенную смену состояния, продержавшуюся окно к моменту now, и принимает ее
+func (d *Debounce) Due(state *DebounceState, now time.Time) *Event {
+	if state.Pending == nil || now.Sub(state.PendingSince) < d.Window {
+		return nil
+	}
+	event := state.Pending
+	state.Pending = nil
+	state.Accepted = event.Payload
+	return event
+}
+
+// IsFlapping сообщает, переключался ли датчик больше FlapThreshold раз за FlapWindow до момента now.
+// Переключения, вышедшие за окно, забываются.
+func (d *Debounce) IsFlapping(state *DebounceState, now time.Time) bool {
+	if d.FlapThreshold == 0 {
+		state.Transitions = nil
+		return false
+	}
+
+	expired := 0
+	for expired < len(state.Transitions) && now.Sub(state.Transitions[expired]) >= d.FlapWindow {
+		expired++
+	}
+	state.Transitions = state.Transitions[expired:]
+
+	return len(state.Transitions) > d.FlapThreshold
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebounce_Receive(t *testing.T) {
+	d := &Debounce{Window: 50 * time.Millisecond}
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+	event := func(payload int64) *Event { return &Event{Payload: payload} }
+
+	t.Run("same state is accepted", func(t *testing.T) {
+		state := &DebounceState{}
+		e := event(0)
+		assert.Equal(t, []*Event{e}, d.Receive(state, e, at(0)))
+	})
+
+	t.Run("bounce is suppressed", func(t *testing.T) {
+		state := &DebounceState{}
+		assert.Empty(t, d.Receive(state, event(1), at(0)))
+		assert.Empty(t, d.Receive(state, event(0), at(5)))
+		assert.Empty(t, d.Receive(state, event(1), at(10)))
+		assert.Empty(t, d.Receive(state, event(0), at(15)))
+		assert.Nil(t, d.Due(state, at(100)))
+		assert.Equal(t, int64(0), state.Accepted)
+	})
+
+	t.Run("stable change is accepted after window", func(t *testing.T) {
+		state := &DebounceState{}
+		first := event(1)
+		assert.Empty(t, d.Receive(state, first, at(0)))
+		assert.Empty(t, d.Receive(state, event(1), at(20)), "repeat keeps the first change")
+		assert.Nil(t, d.Due(state, at(49)))
+		assert.Same(t, first, d.Due(state, at(50)))
+		assert.Equal(t, int64(1), state.Accepted)
+		assert.Nil(t, d.Due(state, at(100)))
+	})
+
+	t.Run("overdue change is accepted before the next event", func(t *testing.T) {
+		state := &DebounceState{}
+		first := event(1)
+		assert.Empty(t, d.Receive(state, first, at(0)))
+		accepted := d.Receive(state, event(0), at(60))
+		assert.Equal(t, []*Event{first}, accepted)
+		require.NotNil(t, state.Pending, "change back is debounced too")
+		assert.Equal(t, int64(0), state.Pending.Payload)
+	})
+
+	t.Run("no window", func(t *testing.T) {
+		state := &DebounceState{}
+		e := event(1)
+		assert.Equal(t, []*Event{e}, (&Debounce{}).Receive(state, e, at(0)))
+		assert.Equal(t, int64(1), state.Accepted)
+	})
+}
+
+func TestDebounce_IsFlapping(t *testing.T) {
+	d := &Debounce{FlapThreshold: 2, FlapWindow: time.Second}
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	state := &DebounceState{}
+
+	for i := 0; i < 3; i++ {
+		d.Receive(state, &Event{Payload: int64((i + 1) % 2)}, start.Add(time.Duration(i)*100*time.Millisecond))
+	}
+	assert.True(t, d.IsFlapping(state, start.Add(300*time.Millisecond)))
+	assert.False(t, d.IsFlapping(state, start.Add(time.Second)), "first transition left the window")
+	assert.Len(t, state.Transitions, 2)
+
+	d.Receive(state, &Event{Payload: 1}, start.Add(1100*time.Millisecond))
+	assert.Len(t, state.Transitions, 2, "same state is not a transition")
+
+	assert.False(t, (&Debounce{}).IsFlapping(state, start))
+	assert.Empty(t, state.Transitions)
+}
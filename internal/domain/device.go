@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// DeviceInfo - сведения об оборудовании и прошивке, которые сообщает устройство
+type DeviceInfo struct {
+	// Manufacturer - производитель
+	Manufacturer string
+	// Model - модель устройства
+	Model string
+	// HardwareRevision - ревизия аппаратной части
+	HardwareRevision string
+	// FirmwareVersion - версия прошивки
+	FirmwareVersion string
+}
+
+// FirmwareChange - запись истории смены прошивки датчика
+type FirmwareChange struct {
+	// SensorID - id датчика
+	SensorID int64
+	// PreviousVersion - версия прошивки до смены, пустая при первом сообщении
+	PreviousVersion string
+	// Version - новая версия прошивки
+	Version string
+	// ChangedAt - время, когда устройство сообщило новую версию
+	ChangedAt time.Time
+}
+
+// InventoryGroup - группа датчиков с одинаковыми моделью и прошивкой
+type InventoryGroup struct {
+	Manufacturer    string
+	Model           string
+	FirmwareVersion string
+	// SensorIDs - id датчиков группы по возрастанию
+	SensorIDs []int64
+}
+
+// ApplyDeviceInfo обновляет сведения об устройстве. Пустые поля info не затирают известные значения.
+// Возвращает true, если изменилась версия прошивки.
+func (s *Sensor) ApplyDeviceInfo(info DeviceInfo) bool {
+	if info.Manufacturer != "" {
+		s.Manufacturer = info.Manufacturer
+	}
+	if info.Model != "" {
+		s.Model = info.Model
+	}
+	if info.HardwareRevision != "" {
+		s.HardwareRevision = info.HardwareRevision
+	}
+	if info.FirmwareVersion == "" || info.FirmwareVersion == s.FirmwareVersion {
+		return false
+	}
+	s.FirmwareVersion = info.FirmwareVersion
+	return true
+}
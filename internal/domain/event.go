@@ -12,4 +12,15 @@ type Event struct {
 	SensorID int64
 	// Payload - данные события
 	Payload int64
+	// Value - значение в инженерных единицах, вычисляется по калибровке датчика при чтении и не хранится
+	Value *float64 `json:",omitempty"`
+	// Unit - единица измерения значения Value
+	Unit string `json:",omitempty"`
+	// AnomalyScore - отклонение значения от скользящего среднего датчика в стандартных отклонениях,
+	// nil - у датчика не включено обнаружение аномалий или статистика еще накапливается
+	AnomalyScore *float64 `json:",omitempty"`
+	// Anomalous - оценка аномальности превысила порог датчика
+	Anomalous bool `json:",omitempty"`
+	// Device - сведения об устройстве, переданные вместе с событием, в событии не хранятся
+	Device *DeviceInfo `json:"-"`
 }
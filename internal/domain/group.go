@@ -0,0 +1,29 @@
+package domain
+
+// Group - структура для хранения группы пользователей, например семьи
+// Датчики группы доступны всем ее участникам.
+type Group struct {
+	// ID - id группы
+	ID int64
+	// Name - название группы
+	Name string
+}
+
+// GroupMember - структура для связи пользователя и группы
+type GroupMember struct {
+	// GroupID - id группы
+	GroupID int64
+	// UserID - id пользователя
+	UserID int64
+}
+
+// GroupSensor - структура для связи датчика и группы
+// Участники группы получают доступ к датчику с ролью Role.
+type GroupSensor struct {
+	// GroupID - id группы
+	GroupID int64
+	// SensorID - id датчика
+	SensorID int64
+	// Role - роль участников группы для датчика
+	Role SensorRole
+}
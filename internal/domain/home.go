@@ -0,0 +1,93 @@
+package domain
+
+import "time"
+
+// Home - структура для хранения дома
+type Home struct {
+	// ID - id дома
+	ID int64
+	// Name - название дома
+	Name string
+	// Address - адрес дома
+	Address string
+	// TimeZone - часовой пояс дома в формате IANA, пустая строка означает UTC
+	TimeZone string
+	// Coordinates - координаты дома для расчета восхода и заката, nil если не заданы
+	Coordinates *Coordinates
+}
+
+// Coordinates - географические координаты в градусах
+type Coordinates struct {
+	// Latitude - широта, положительная к северу от экватора
+	Latitude float64
+	// Longitude - долгота, положительная к востоку от Гринвича
+	Longitude float64
+}
+
+// IsValid проверяет, что координаты лежат в допустимых диапазонах
+func (c Coordinates) IsValid() bool {
+	return c.Latitude >= -90 && c.Latitude <= 90 && c.Longitude >= -180 && c.Longitude <= 180
+}
+
+// Location возвращает часовой пояс дома, для пустого или неизвестного пояса - UTC
+func (h Home) Location() *time.Location {
+	if h.TimeZone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(h.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// Room - структура для хранения комнаты
+// Комната всегда принадлежит ровно одному дому.
+type Room struct {
+	// ID - id комнаты
+	ID int64
+	// HomeID - id дома, в котором находится комната
+	HomeID int64
+	// Name - название комнаты
+	Name string
+}
+
+// SensorRoom - структура для связи датчика и комнаты
+// Связь многие-к-одному: датчик может находиться только в одной комнате, в комнате может быть несколько датчиков.
+type SensorRoom struct {
+	// SensorID - id датчика
+	SensorID int64
+	// RoomID - id комнаты
+	RoomID int64
+}
+
+// HomeOwner - структура для связи пользователя и дома
+// Доступ к дому дает доступ ко всем датчикам, размещенным в его комнатах.
+type HomeOwner struct {
+	// UserID - id пользователя
+	UserID int64
+	// HomeID - id дома
+	HomeID int64
+	// Role - уровень доступа пользователя к дому
+	Role HomeRole
+}
+
+// HomeRole - уровень доступа к дому. Владелец имеет все права участника.
+type HomeRole string
+
+const (
+	// HomeRoleOwner - владелец: может менять и удалять дом и давать доступ к нему
+	HomeRoleOwner HomeRole = "owner"
+	// HomeRoleMember - участник: видит дом и его датчики, управляет комнатами
+	HomeRoleMember HomeRole = "member"
+)
+
+// IsValid сообщает, является ли r известной ролью
+func (r HomeRole) IsValid() bool {
+	return r == HomeRoleOwner || r == HomeRoleMember
+}
+
+// Includes сообщает, дает ли роль r права роли other
+func (r HomeRole) Includes(other HomeRole) bool {
+	return r == HomeRoleOwner || (r == HomeRoleMember && other == HomeRoleMember)
+}
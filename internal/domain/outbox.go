@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// OutboxKind - вид записи outbox
+type OutboxKind string
+
+const (
+	// OutboxSensorEvent - датчик прислал событие, Payload - Event в JSON
+	OutboxSensorEvent OutboxKind = "sensor.event"
+)
+
+// OutboxEntry - запись outbox: изменение, о котором нужно сообщить внешним получателям.
+// Сохраняется в одной транзакции с самим изменением и удаляется после доставки всем получателям.
+type OutboxEntry struct {
+	// ID - id записи, задает порядок доставки записей датчика
+	ID int64
+	// SensorID - id датчика, к которому относится запись
+	SensorID int64
+	// Kind - вид записи
+	Kind OutboxKind
+	// Payload - содержимое записи в JSON
+	Payload []byte
+	// Attempts - число неудачных попыток доставки
+	Attempts int
+	// NextAttemptAt - время следующей попытки
+	NextAttemptAt time.Time
+	// LastError - причина неудачи последней попытки
+	LastError string
+	// CreatedAt - время создания
+	CreatedAt time.Time
+}
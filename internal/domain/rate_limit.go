@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RateLimit - параметры корзины токенов: Rate токенов в секунду, не больше Burst токенов подряд.
+// Нулевое значение означает отсутствие ограничения.
+type RateLimit struct {
+	// Rate - скорость пополнения корзины, токенов в секунду
+	Rate float64
+	// Burst - емкость корзины
+	Burst int
+}
+
+// IsZero сообщает, что ограничение не задано
+func (l RateLimit) IsZero() bool {
+	return l == RateLimit{}
+}
+
+// ParseRateLimit разбирает ограничение в виде "rate:burst", например "10:20".
+// Без burst емкость корзины равна округленной вверх скорости.
+func ParseRateLimit(s string) (RateLimit, error) {
+	rateStr, burstStr, hasBurst := strings.Cut(strings.TrimSpace(s), ":")
+
+	rate, err := strconv.ParseFloat(rateStr, 64)
+	if err != nil || rate <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q", s)
+	}
+
+	burst := int(rate)
+	if float64(burst) < rate {
+		burst++
+	}
+	if hasBurst {
+		if burst, err = strconv.Atoi(burstStr); err != nil || burst < 1 {
+			return RateLimit{}, fmt.Errorf("invalid rate limit %q", s)
+		}
+	}
+
+	return RateLimit{Rate: rate, Burst: burst}, nil
+}
+
+// ParseRateLimits разбирает список ограничений "key=rate:burst,key=rate:burst", например "*=10:20,cc=1:5"
+func ParseRateLimits(s string) (map[string]RateLimit, error) {
+	limits := make(map[string]RateLimit)
+	for _, item := range strings.Split(s, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, errors.New("rate limit must be in key=rate:burst form")
+		}
+
+		limit, err := ParseRateLimit(value)
+		if err != nil {
+			return nil, err
+		}
+		limits[key] = limit
+	}
+	return limits, nil
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimits(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		limits, err := ParseRateLimits(" *=10:20, cc=0.5 ,")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]RateLimit{
+			"*":  {Rate: 10, Burst: 20},
+			"cc": {Rate: 0.5, Burst: 1},
+		}, limits)
+	})
+
+	t.Run("ok, empty", func(t *testing.T) {
+		limits, err := ParseRateLimits("")
+		require.NoError(t, err)
+		assert.Empty(t, limits)
+	})
+
+	for _, spec := range []string{"10:20", "adc=", "adc=0", "adc=-1:5", "adc=10:0", "adc=10:x", "=10"} {
+		t.Run("fail, "+spec, func(t *testing.T) {
+			_, err := ParseRateLimits(spec)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package domain
+
+import "time"
+
+// RuleOperator - сравнение значения датчика с порогом правила
+type RuleOperator string
+
+const (
+	RuleOperatorGreater        RuleOperator = ">"
+	RuleOperatorGreaterOrEqual RuleOperator = ">="
+	RuleOperatorLess           RuleOperator = "<"
+	RuleOperatorLessOrEqual    RuleOperator = "<="
+	RuleOperatorEqual          RuleOperator = "=="
+	RuleOperatorNotEqual       RuleOperator = "!="
+)
+
+// IsValid сообщает, известен ли оператор
+func (o RuleOperator) IsValid() bool {
+	switch o {
+	case RuleOperatorGreater, RuleOperatorGreaterOrEqual, RuleOperatorLess, RuleOperatorLessOrEqual,
+		RuleOperatorEqual, RuleOperatorNotEqual:
+		return true
+	}
+	return false
+}
+
+// Compare сравнивает значение с порогом
+func (o RuleOperator) Compare(value, threshold float64) bool {
+	switch o {
+	case RuleOperatorGreater:
+		return value > threshold
+	case RuleOperatorGreaterOrEqual:
+		return value >= threshold
+	case RuleOperatorLess:
+		return value < threshold
+	case RuleOperatorLessOrEqual:
+		return value <= threshold
+	case RuleOperatorEqual:
+		return value == threshold
+	case RuleOperatorNotEqual:
+		return value != threshold
+	}
+	return false
+}
+
+// MinutesPerDay - число минут в сутках, границы окна правила задаются в минутах от полуночи
+const MinutesPerDay = 24 * 60
+
+// RuleWindow - время суток, в которое правило действует, например с 23:00 до 06:00
+type RuleWindow struct {
+	// From - начало окна в минутах от полуночи
+	From int
+	// To - конец окна в минутах от полуночи, не входит в окно. Если To меньше From, окно переходит через полночь.
+	To int
+	// TimeZone - часовой пояс IANA, в котором заданы границы, пустой - UTC
+	TimeZone string
+}
+
+// Location возвращает часовой пояс окна
+func (w *RuleWindow) Location() (*time.Location, error) {
+	return time.LoadLocation(w.TimeZone)
+}
+
+// Contains сообщает, попадает ли момент t в окно. nil-окно содержит любой момент.
+func (w *RuleWindow) Contains(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+
+	if location, err := w.Location(); err == nil {
+		t = t.In(location)
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if w.From <= w.To {
+		return w.From <= minute && minute < w.To
+	}
+	return minute >= w.From || minute < w.To
+}
+
+// Rule - правило оповещения: значение датчика SensorID удовлетворяет условию Operator Threshold
+// не меньше Duration подряд, и, если задано окно, в пределах окна. Значение - в инженерных единицах,
+// если у датчика есть калибровка, иначе сырое.
+type Rule struct {
+	// ID - id правила
+	ID int64
+	// SensorID - id датчика
+	SensorID int64
+	// Name - название правила
+	Name string
+	// Operator - сравнение значения с порогом
+	Operator RuleOperator
+	// Threshold - порог
+	Threshold float64
+	// Duration - сколько условие должно выполняться, чтобы правило сработало, 0 - сразу
+	Duration time.Duration
+	// Window - время суток, в которое правило действует, nil - круглосуточно
+	Window *RuleWindow
+	// Enabled - включено ли правило
+	Enabled bool
+	// CreatedBy - id пользователя, создавшего правило, 0 - создано без аутентификации
+	CreatedBy int64
+	// CreatedAt - время создания
+	CreatedAt time.Time
+}
+
+// RuleState - состояние вычисления правила, сохраняется, чтобы ожидание Duration переживало перезапуск
+type RuleState struct {
+	// RuleID - id правила
+	RuleID int64
+	// Value - последнее значение датчика
+	Value float64
+	// PendingSince - с какого момента выполняется условие, nil - не выполняется
+	PendingSince *time.Time
+	// Firing - правило сработало, и условие с тех пор не снималось
+	Firing bool
+	// UpdatedAt - время последнего вычисления
+	UpdatedAt time.Time
+}
+
+// RuleResult - результат вычисления правила
+type RuleResult int
+
+const (
+	// RuleIdle - условие не выполняется или выполняется меньше Duration
+	RuleIdle RuleResult = iota
+	// RuleFired - правило сработало: условие выполняется Duration подряд, а до этого правило не срабатывало
+	RuleFired
+	// RuleFiring - правило уже сработало, и условие продолжает выполняться
+	RuleFiring
+	// RuleCleared - правило срабатывало, но условие перестало выполняться
+	RuleCleared
+)
+
+// Evaluate вычисляет правило по последнему значению из state на момент now и обновляет state
+func (r *Rule) Evaluate(state *RuleState, now time.Time) RuleResult {
+	state.UpdatedAt = now
+	if !r.Enabled || !r.Window.Contains(now) || !r.Operator.Compare(state.Value, r.Threshold) {
+		wasFiring := state.Firing
+		state.PendingSince = nil
+		state.Firing = false
+		if wasFiring {
+			return RuleCleared
+		}
+		return RuleIdle
+	}
+
+	if state.PendingSince == nil {
+		state.PendingSince = &now
+	}
+	if state.Firing {
+		return RuleFiring
+	}
+	if now.Sub(*state.PendingSince) < r.Duration {
+		return RuleIdle
+	}
+
+	state.Firing = true
+	return RuleFired
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleWindow_Contains(t *testing.T) {
+	night := &RuleWindow{From: 23 * 60, To: 6 * 60}
+	day := &RuleWindow{From: 9 * 60, To: 18 * 60, TimeZone: "Europe/Moscow"}
+
+	tests := []struct {
+		name   string
+		window *RuleWindow
+		t      time.Time
+		want   bool
+	}{
+		{"nil window", nil, time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), true},
+		{"over midnight, before midnight", night, time.Date(2025, 1, 1, 23, 30, 0, 0, time.UTC), true},
+		{"over midnight, after midnight", night, time.Date(2025, 1, 1, 5, 59, 0, 0, time.UTC), true},
+		{"over midnight, end excluded", night, time.Date(2025, 1, 1, 6, 0, 0, 0, time.UTC), false},
+		{"over midnight, outside", night, time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), false},
+		{"time zone, inside", day, time.Date(2025, 1, 1, 6, 0, 0, 0, time.UTC), true},
+		{"time zone, outside", day, time.Date(2025, 1, 1, 15, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.window.Contains(tt.t))
+		})
+	}
+}
+
+func TestRule_Evaluate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("fires immediately without duration", func(t *testing.T) {
+		rule := &Rule{Operator: RuleOperatorEqual, Threshold: 1, Enabled: true}
+		state := &RuleState{Value: 1}
+
+		assert.Equal(t, RuleFired, rule.Evaluate(state, now))
+		assert.Equal(t, RuleFiring, rule.Evaluate(state, now.Add(time.Second)), "fires once while condition holds")
+
+		state.Value = 0
+		assert.Equal(t, RuleCleared, rule.Evaluate(state, now.Add(2*time.Second)))
+		assert.False(t, state.Firing)
+		assert.Nil(t, state.PendingSince)
+
+		assert.Equal(t, RuleIdle, rule.Evaluate(state, now.Add(2*time.Second)), "cleared only once")
+
+		state.Value = 1
+		assert.Equal(t, RuleFired, rule.Evaluate(state, now.Add(3*time.Second)), "fires again after condition cleared")
+	})
+
+	t.Run("fires after duration", func(t *testing.T) {
+		rule := &Rule{Operator: RuleOperatorGreater, Threshold: 800, Duration: 5 * time.Minute, Enabled: true}
+		state := &RuleState{Value: 801}
+
+		assert.Equal(t, RuleIdle, rule.Evaluate(state, now))
+		assert.Equal(t, now, *state.PendingSince)
+		assert.Equal(t, RuleIdle, rule.Evaluate(state, now.Add(4*time.Minute)))
+		assert.Equal(t, RuleFired, rule.Evaluate(state, now.Add(5*time.Minute)))
+	})
+
+	t.Run("pending resets when condition clears", func(t *testing.T) {
+		rule := &Rule{Operator: RuleOperatorGreater, Threshold: 800, Duration: 5 * time.Minute, Enabled: true}
+		state := &RuleState{Value: 801}
+
+		assert.Equal(t, RuleIdle, rule.Evaluate(state, now))
+		state.Value = 700
+		assert.Equal(t, RuleIdle, rule.Evaluate(state, now.Add(time.Minute)), "pending rule is not cleared")
+		state.Value = 900
+		assert.Equal(t, RuleIdle, rule.Evaluate(state, now.Add(5*time.Minute)))
+		assert.Equal(t, RuleFired, rule.Evaluate(state, now.Add(10*time.Minute)))
+	})
+
+	t.Run("disabled or outside window never fires", func(t *testing.T) {
+		rule := &Rule{Operator: RuleOperatorEqual, Threshold: 1}
+		assert.Equal(t, RuleIdle, rule.Evaluate(&RuleState{Value: 1}, now))
+
+		rule = &Rule{Operator: RuleOperatorEqual, Threshold: 1, Enabled: true, Window: &RuleWindow{From: 23 * 60, To: 6 * 60}}
+		assert.Equal(t, RuleIdle, rule.Evaluate(&RuleState{Value: 1}, now))
+		state := &RuleState{Value: 1}
+		assert.Equal(t, RuleFired, rule.Evaluate(state, now.Add(12*time.Hour)))
+		assert.Equal(t, RuleCleared, rule.Evaluate(state, now.Add(20*time.Hour)), "window end clears rule")
+	})
+}
@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxSunOffset - максимальное смещение от восхода или заката
+const maxSunOffset = 12 * time.Hour
+
+// sunSearchDays - на сколько дней вперед ищется восход или закат, с запасом на полярную ночь
+const sunSearchDays = 366
+
+// SunEvent - астрономическое событие, к которому привязано расписание
+type SunEvent string
+
+const (
+	SunEventSunrise SunEvent = "sunrise"
+	SunEventSunset  SunEvent = "sunset"
+)
+
+// opposite возвращает закат для восхода и восход для заката
+func (e SunEvent) opposite() SunEvent {
+	if e == SunEventSunset {
+		return SunEventSunrise
+	}
+	return SunEventSunset
+}
+
+// ScheduleExpression - разобранное выражение расписания: cron-выражение
+// или восход/закат со смещением, например "sunset+30m" или "sunrise-1h15m"
+type ScheduleExpression struct {
+	cron   *CronExpression
+	sun    SunEvent
+	offset time.Duration
+}
+
+// ParseScheduleExpression разбирает выражение расписания
+func ParseScheduleExpression(expr string) (*ScheduleExpression, error) {
+	expr = strings.ToLower(strings.TrimSpace(expr))
+	if sun, err := ParseSunTime(expr); err == nil {
+		return &ScheduleExpression{sun: sun.Event, offset: sun.Offset}, nil
+	} else if !errors.Is(err, errNotSunTime) {
+		return nil, err
+	}
+
+	cron, err := ParseCron(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &ScheduleExpression{cron: cron}, nil
+}
+
+// errNotSunTime - выражение не начинается с восхода или заката
+var errNotSunTime = errors.New("expression is not sunrise or sunset")
+
+// SunTime - момент относительно восхода или заката
+type SunTime struct {
+	Event  SunEvent
+	Offset time.Duration
+}
+
+// ParseSunTime разбирает восход или закат со смещением, например "sunset+30m" или "sunrise-1h15m"
+func ParseSunTime(expr string) (SunTime, error) {
+	expr = strings.ToLower(strings.TrimSpace(expr))
+	for _, event := range []SunEvent{SunEventSunrise, SunEventSunset} {
+		rest, ok := strings.CutPrefix(expr, string(event))
+		if !ok {
+			continue
+		}
+
+		rest = strings.ReplaceAll(rest, " ", "")
+		if rest == "" {
+			return SunTime{Event: event}, nil
+		}
+		if rest[0] != '+' && rest[0] != '-' {
+			return SunTime{}, fmt.Errorf("invalid %s offset %q", event, rest)
+		}
+		offset, err := time.ParseDuration(rest)
+		if err != nil || offset < -maxSunOffset || offset > maxSunOffset {
+			return SunTime{}, fmt.Errorf("invalid %s offset %q", event, rest)
+		}
+		return SunTime{Event: event, Offset: offset}, nil
+	}
+	return SunTime{}, errNotSunTime
+}
+
+// On возвращает момент в день day по календарю часового пояса day, ok равно false в полярный день и ночь
+func (t SunTime) On(day time.Time, coordinates Coordinates) (time.Time, bool) {
+	sunrise, sunset, ok := coordinates.SunTimes(day)
+	if !ok {
+		return time.Time{}, false
+	}
+	if t.Event == SunEventSunset {
+		return sunset.Add(t.Offset), true
+	}
+	return sunrise.Add(t.Offset), true
+}
+
+// IsSolar сообщает, привязано ли выражение к восходу или закату и требует ли координат
+func (e *ScheduleExpression) IsSolar() bool {
+	return e.sun != ""
+}
+
+// Next возвращает ближайший момент срабатывания строго после after. Время cron-выражения отсчитывается
+// в часовом поясе loc, восход и закат рассчитываются по координатам coordinates.
+// Нулевое время означает, что срабатываний в обозримом будущем нет.
+func (e *ScheduleExpression) Next(after time.Time, loc *time.Location, coordinates *Coordinates) time.Time {
+	if e.cron != nil {
+		return e.cron.Next(after.In(loc))
+	}
+	if coordinates == nil {
+		return time.Time{}
+	}
+
+	local := after.In(loc)
+	sun := SunTime{Event: e.sun, Offset: e.offset}
+	// смещение может перенести событие предыдущего дня на текущий
+	for i := -1; i <= sunSearchDays; i++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+i, 12, 0, 0, 0, loc)
+		if t, ok := sun.On(day, *coordinates); ok && t.After(after) {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// MissedRunPolicy - что делать с запусками, пропущенными, пока сервис не работал
+type MissedRunPolicy string
+
+const (
+	// MissedRunSkip - пропущенный запуск не выполняется, а только записывается в журнал
+	MissedRunSkip MissedRunPolicy = "skip"
+	// MissedRunOnce - после простоя выполняется один запуск вместо всех пропущенных
+	MissedRunOnce MissedRunPolicy = "run_once"
+)
+
+// IsValid сообщает, известна ли политика
+func (p MissedRunPolicy) IsValid() bool {
+	switch p {
+	case MissedRunSkip, MissedRunOnce:
+		return true
+	}
+	return false
+}
+
+// Schedule - расписание: действие, которое выполняется в моменты, заданные выражением,
+// по часовому поясу и координатам дома
+type Schedule struct {
+	// ID - id расписания
+	ID int64
+	// HomeID - id дома, по часовому поясу и координатам которого считается время
+	HomeID int64
+	// Name - название
+	Name string
+	// Expression - cron-выражение или восход/закат со смещением
+	Expression string
+	// Action - выполняемое действие: команда устройству или запуск сцены
+	Action AutomationAction
+	// MissedRunPolicy - что делать с запуском, пропущенным во время простоя
+	MissedRunPolicy MissedRunPolicy
+	// Enabled - включено ли расписание
+	Enabled bool
+	// NextRunAt - время следующего запуска, нулевое у выключенного расписания и если запусков больше не будет
+	NextRunAt time.Time
+	// LastRunAt - время последнего срабатывания, нулевое, если срабатываний не было
+	LastRunAt time.Time
+	// CreatedBy - id пользователя, создавшего расписание, 0 - создано без аутентификации
+	CreatedBy int64
+	// CreatedAt - время создания
+	CreatedAt time.Time
+}
@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	for _, expr := range []string{
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+		"@every 5m",
+	} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestCronExpression_Next(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// 2025-01-01 - среда
+	wednesday := time.Date(2025, 1, 1, 12, 7, 30, 0, moscow)
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"every 15 minutes", "*/15 * * * *", wednesday, time.Date(2025, 1, 1, 12, 15, 0, 0, moscow)},
+		{"strictly after", "15 12 * * *", time.Date(2025, 1, 1, 12, 15, 0, 0, moscow), time.Date(2025, 1, 2, 12, 15, 0, 0, moscow)},
+		{"weekdays 07:00 from friday", "0 7 * * mon-fri", time.Date(2025, 1, 3, 8, 0, 0, 0, moscow), time.Date(2025, 1, 6, 7, 0, 0, 0, moscow)},
+		{"sunday as 7", "0 9 * * 7", wednesday, time.Date(2025, 1, 5, 9, 0, 0, 0, moscow)},
+		{"list and step from value", "0 5/10,3 * * *", wednesday, time.Date(2025, 1, 1, 15, 0, 0, 0, moscow)},
+		{"day of month or day of week", "0 0 10 * sat", wednesday, time.Date(2025, 1, 4, 0, 0, 0, 0, moscow)},
+		{"macro", "@monthly", wednesday, time.Date(2025, 2, 1, 0, 0, 0, 0, moscow)},
+		{"leap day", "0 0 29 feb *", wednesday, time.Date(2028, 2, 29, 0, 0, 0, 0, moscow)},
+		{"never", "0 0 30 2 *", wednesday, time.Time{}},
+		{"spring forward skips missing hour", "30 2 * * *", time.Date(2025, 3, 29, 12, 0, 0, 0, berlin), time.Date(2025, 3, 31, 2, 30, 0, 0, berlin)},
+		{"fall back runs at first occurrence", "30 2 * * *", time.Date(2025, 10, 26, 0, 0, 0, 0, time.UTC).In(berlin), time.Date(2025, 10, 26, 0, 30, 0, 0, time.UTC)},
+		{"fall back runs once", "30 2 * * *", time.Date(2025, 10, 26, 0, 30, 0, 0, time.UTC).In(berlin), time.Date(2025, 10, 27, 2, 30, 0, 0, berlin)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			require.NoError(t, err)
+			got := c.Next(tt.after)
+			assert.True(t, tt.want.Equal(got), "want %v, got %v", tt.want, got)
+		})
+	}
+}
+
+func TestCoordinates_SunTimes(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	city := Coordinates{Latitude: 55.7558, Longitude: 37.6173}
+	arctic := Coordinates{Latitude: 68.97, Longitude: 33.07}
+
+	near := func(t *testing.T, want, got time.Time) {
+		assert.InDelta(t, 0, got.Sub(want).Minutes(), 3, "want %v, got %v", want, got)
+	}
+
+	sunrise, sunset, ok := city.SunTimes(time.Date(2024, 6, 21, 0, 0, 0, 0, moscow))
+	require.True(t, ok)
+	near(t, time.Date(2024, 6, 21, 3, 44, 0, 0, moscow), sunrise)
+	near(t, time.Date(2024, 6, 21, 21, 18, 0, 0, moscow), sunset)
+	assert.Equal(t, moscow, sunrise.Location())
+
+	sunrise, sunset, ok = city.SunTimes(time.Date(2024, 12, 21, 0, 0, 0, 0, moscow))
+	require.True(t, ok)
+	near(t, time.Date(2024, 12, 21, 8, 58, 0, 0, moscow), sunrise)
+	near(t, time.Date(2024, 12, 21, 15, 58, 0, 0, moscow), sunset)
+
+	_, _, ok = arctic.SunTimes(time.Date(2024, 12, 21, 0, 0, 0, 0, moscow))
+	assert.False(t, ok, "polar night")
+	_, _, ok = arctic.SunTimes(time.Date(2024, 6, 21, 0, 0, 0, 0, moscow))
+	assert.False(t, ok, "polar day")
+}
+
+func TestScheduleExpression_Next(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	city := &Coordinates{Latitude: 55.7558, Longitude: 37.6173}
+	arctic := &Coordinates{Latitude: 68.97, Longitude: 33.07}
+
+	for _, expr := range []string{"sunset30m", "sunrise+13h", "sunset+abc", "noon"} {
+		_, err := ParseScheduleExpression(expr)
+		assert.Error(t, err, expr)
+	}
+
+	t.Run("cron in home time zone", func(t *testing.T) {
+		e, err := ParseScheduleExpression("0 7 * * *")
+		require.NoError(t, err)
+		assert.False(t, e.IsSolar())
+		got := e.Next(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), moscow, nil)
+		assert.True(t, time.Date(2025, 1, 1, 7, 0, 0, 0, moscow).Equal(got), got)
+	})
+
+	t.Run("sunset with offset", func(t *testing.T) {
+		e, err := ParseScheduleExpression("Sunset + 30m")
+		require.NoError(t, err)
+		assert.True(t, e.IsSolar())
+
+		after := time.Date(2024, 6, 21, 12, 0, 0, 0, moscow)
+		_, sunset, _ := city.SunTimes(after)
+		assert.Equal(t, sunset.Add(30*time.Minute), e.Next(after, moscow, city))
+
+		// закат этого дня уже прошел - следующий запуск завтра
+		after = sunset.Add(time.Hour)
+		_, tomorrow, _ := city.SunTimes(after.AddDate(0, 0, 1))
+		assert.Equal(t, tomorrow.Add(30*time.Minute), e.Next(after, moscow, city))
+	})
+
+	t.Run("negative offset crosses midnight", func(t *testing.T) {
+		e, err := ParseScheduleExpression("sunrise-4h")
+		require.NoError(t, err)
+		after := time.Date(2024, 6, 20, 23, 0, 0, 0, moscow)
+		sunrise, _, _ := city.SunTimes(time.Date(2024, 6, 21, 0, 0, 0, 0, moscow))
+		assert.Equal(t, sunrise.Add(-4*time.Hour), e.Next(after, moscow, city))
+	})
+
+	t.Run("polar night waits for sunrise", func(t *testing.T) {
+		e, err := ParseScheduleExpression("sunrise")
+		require.NoError(t, err)
+		got := e.Next(time.Date(2024, 12, 21, 12, 0, 0, 0, moscow), moscow, arctic)
+		require.False(t, got.IsZero())
+		assert.Equal(t, 2025, got.Year())
+		assert.Equal(t, time.January, got.Month())
+	})
+
+	t.Run("no coordinates", func(t *testing.T) {
+		e, err := ParseScheduleExpression("sunrise")
+		require.NoError(t, err)
+		assert.True(t, e.Next(time.Now(), moscow, nil).IsZero())
+	})
+}
@@ -1,14 +1,36 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MaxSensorLabels - максимальное число меток датчика
+	MaxSensorLabels = 16
+	// maxSensorLabelLength - максимальная длина метки датчика
+	maxSensorLabelLength = 64
+)
 
 type SensorType string
 
 const (
 	SensorTypeContactClosure SensorType = "cc"
 	SensorTypeADC            SensorType = "adc"
+	// SensorTypeVirtual - вычисляемый датчик, значение которого задается выражением над другими датчиками
+	SensorTypeVirtual SensorType = "virtual"
 )
 
+// IsValid сообщает, известен ли тип датчика
+func (t SensorType) IsValid() bool {
+	switch t {
+	case SensorTypeContactClosure, SensorTypeADC, SensorTypeVirtual:
+		return true
+	}
+	return false
+}
+
 // Sensor - структура для хранения данных датчика
 type Sensor struct {
 	// ID - id датчика
@@ -27,4 +49,29 @@ type Sensor struct {
 	RegisteredAt time.Time
 	// LastActivity - дата последнего изменения состояния датчика
 	LastActivity time.Time
+	// Calibration - профиль калибровки, только для датчиков АЦП
+	Calibration *Calibration
+	// AnomalyDetection - настройки обнаружения аномалий, только для датчиков АЦП, nil - не обнаруживаются
+	AnomalyDetection *AnomalyDetection
+	// Debounce - подавление дребезга и обнаружение частых переключений, только для датчиков сухого контакта
+	Debounce *Debounce
+	// Flapping - датчик переключается слишком часто, оповещения по нему не создаются
+	Flapping bool
+	// Virtual - выражение вычисляемого датчика, только для виртуальных датчиков, nil - не вычисляется
+	Virtual *VirtualSensor
+	// Manufacturer - производитель устройства
+	Manufacturer string
+	// Model - модель устройства
+	Model string
+	// HardwareRevision - ревизия аппаратной части
+	HardwareRevision string
+	// FirmwareVersion - текущая версия прошивки
+	FirmwareVersion string
+	// Labels - метки датчика, например "kitchen" или "critical", по ним фильтруются подписки на уведомления
+	Labels []string
+}
+
+// IsValidLabel сообщает, можно ли использовать метку: непустая, без пробелов по краям и не длиннее 64 символов
+func IsValidLabel(label string) bool {
+	return label != "" && label == strings.TrimSpace(label) && utf8.RuneCountInString(label) <= maxSensorLabelLength
 }
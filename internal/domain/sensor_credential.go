@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// SensorCredential - секрет, которым датчик подписывает отправляемые события.
+// После ротации предыдущий секрет действует до PreviousExpiresAt, чтобы устройство успело получить новый.
+type SensorCredential struct {
+	// SensorID - id датчика
+	SensorID int64
+	// Secret - действующий секрет, в JSON, в том числе в журнал аудита, не попадает
+	Secret string `json:"-"`
+	// PreviousSecret - секрет до последней ротации, пустой, если ротаций не было
+	PreviousSecret string `json:"-"`
+	// PreviousExpiresAt - время, до которого принимается PreviousSecret
+	PreviousExpiresAt *time.Time
+	// RotatedAt - время выпуска действующего секрета
+	RotatedAt time.Time
+}
+
+// Secrets возвращает секреты, подписи которыми принимаются в момент now
+func (c *SensorCredential) Secrets(now time.Time) []string {
+	secrets := []string{c.Secret}
+	if c.PreviousSecret != "" && c.PreviousExpiresAt != nil && now.Before(*c.PreviousExpiresAt) {
+		secrets = append(secrets, c.PreviousSecret)
+	}
+	return secrets
+}
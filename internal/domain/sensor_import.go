@@ -0,0 +1,46 @@
+package domain
+
+// SensorImportMode - режим сохранения при массовой регистрации датчиков
+type SensorImportMode string
+
+const (
+	// SensorImportAtomic - все строки сохраняются в одной транзакции, любая ошибка отменяет импорт целиком
+	SensorImportAtomic SensorImportMode = "atomic"
+	// SensorImportBestEffort - каждая корректная строка сохраняется независимо от остальных
+	SensorImportBestEffort SensorImportMode = "best_effort"
+)
+
+type SensorImportStatus string
+
+const (
+	SensorImportStatusValid   SensorImportStatus = "valid"
+	SensorImportStatusCreated SensorImportStatus = "created"
+	SensorImportStatusExists  SensorImportStatus = "exists"
+	SensorImportStatusInvalid SensorImportStatus = "invalid"
+	SensorImportStatusFailed  SensorImportStatus = "failed"
+	SensorImportStatusSkipped SensorImportStatus = "skipped"
+)
+
+// SensorImportRow - строка массовой регистрации датчиков
+type SensorImportRow struct {
+	// Sensor - регистрируемый датчик
+	Sensor Sensor
+	// OwnerID - id пользователя, к которому надо привязать датчик, 0 - без привязки
+	OwnerID int64
+}
+
+// SensorImportResult - результат обработки строки импорта
+type SensorImportResult struct {
+	// Row - номер строки, начиная с 1
+	Row int
+	// SerialNumber - серийный номер датчика из строки
+	SerialNumber string
+	// Status - итог обработки строки
+	Status SensorImportStatus
+	// SensorID - id созданного или уже существующего датчика
+	SensorID int64
+	// Secret - секрет подписи событий, выпущенный созданному датчику, если подпись включена
+	Secret string
+	// Err - причина, по которой строка не сохранена
+	Err error
+}
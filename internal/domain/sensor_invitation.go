@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// SensorInvitation - приглашение к датчику по ссылке.
+// Принявший приглашение пользователь получает доступ к датчику с ролью Role.
+// Сам токен приглашения не хранится, только его хэш.
+type SensorInvitation struct {
+	// ID - id приглашения
+	ID int64
+	// SensorID - id датчика
+	SensorID int64
+	// Role - роль, которую получит принявший приглашение
+	Role SensorRole
+	// Hash - хэш токена приглашения в hex, в JSON, в том числе в журнал аудита, не попадает
+	Hash string `json:"-"`
+	// CreatedBy - id пользователя, создавшего приглашение, 0 - создано без аутентификации
+	CreatedBy int64
+	// CreatedAt - время создания
+	CreatedAt time.Time
+	// ExpiresAt - время, после которого приглашение нельзя принять
+	ExpiresAt time.Time
+	// MaxUses - сколько раз приглашение можно принять
+	MaxUses int
+	// Uses - сколько раз приглашение уже принято
+	Uses int
+	// RevokedAt - время отзыва, nil - приглашение не отозвано
+	RevokedAt *time.Time
+}
+
+// IsPending сообщает, можно ли принять приглашение в момент now
+func (i *SensorInvitation) IsPending(now time.Time) bool {
+	return i.RevokedAt == nil && now.Before(i.ExpiresAt) && i.Uses < i.MaxUses
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// julianUnixEpoch - юлианская дата 1970-01-01 00:00 UTC
+	julianUnixEpoch = 2440587.5
+	// julianJ2000 - юлианская дата эпохи J2000.0
+	julianJ2000 = 2451545.0
+	// sunAltitude - высота центра солнца над горизонтом в момент восхода и заката с учетом рефракции, градусы
+	sunAltitude = -0.833
+	// earthObliquity - наклон земной оси, градусы
+	earthObliquity = 23.4397
+)
+
+// SunTimes возвращает время восхода и заката в день day по календарю часового пояса day.
+// Результат в том же часовом поясе с точностью около минуты. В полярный день и полярную ночь
+// восхода и заката нет, тогда ok равно false.
+func (c Coordinates) SunTimes(day time.Time) (sunrise, sunset time.Time, ok bool) {
+	y, m, d := day.Date()
+	noon := time.Date(y, m, d, 12, 0, 0, 0, time.UTC)
+
+	// средний солнечный полдень на долготе дома в днях от J2000
+	meanNoon := float64(noon.Unix())/86400 + julianUnixEpoch - julianJ2000 + 0.0008 - c.Longitude/360
+
+	meanAnomaly := math.Mod(357.5291+0.98560028*meanNoon, 360)
+	m1 := radians(meanAnomaly)
+	center := 1.9148*math.Sin(m1) + 0.0200*math.Sin(2*m1) + 0.0003*math.Sin(3*m1)
+	eclipticLongitude := radians(math.Mod(meanAnomaly+center+180+102.9372, 360))
+	transit := julianJ2000 + meanNoon + 0.0053*math.Sin(m1) - 0.0069*math.Sin(2*eclipticLongitude)
+
+	declination := math.Asin(math.Sin(eclipticLongitude) * math.Sin(radians(earthObliquity)))
+	latitude := radians(c.Latitude)
+	cosHourAngle := (math.Sin(radians(sunAltitude)) - math.Sin(latitude)*math.Sin(declination)) /
+		(math.Cos(latitude) * math.Cos(declination))
+	if cosHourAngle < -1 || cosHourAngle > 1 {
+		return time.Time{}, time.Time{}, false
+	}
+	hourAngle := math.Acos(cosHourAngle) * 180 / math.Pi
+
+	loc := day.Location()
+	return julianToTime(transit - hourAngle/360).In(loc), julianToTime(transit + hourAngle/360).In(loc), true
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// julianToTime переводит юлианскую дату в время с точностью до секунды
+func julianToTime(julian float64) time.Time {
+	return time.Unix(int64(math.Round((julian-julianUnixEpoch)*86400)), 0).UTC()
+}
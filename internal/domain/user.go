@@ -6,6 +6,8 @@ type User struct {
 	ID int64
 	// Name - имя пользователя
 	Name string
+	// IsAdmin - администратор видит все датчики и дома независимо от привязок
+	IsAdmin bool
 }
 
 // SensorOwner - структура для связи пользователя и датчика
@@ -15,4 +17,41 @@ type SensorOwner struct {
 	UserID int64
 	// SensorID - id датчика
 	SensorID int64
+	// Role - уровень доступа пользователя к датчику
+	Role SensorRole
+}
+
+// SensorRole - уровень доступа к датчику. Каждая роль включает права младших.
+type SensorRole string
+
+const (
+	// SensorRoleOwner - владелец: может делиться датчиком и менять роли
+	SensorRoleOwner SensorRole = "owner"
+	// SensorRoleOperator - оператор: может менять настройки датчика
+	SensorRoleOperator SensorRole = "operator"
+	// SensorRoleViewer - наблюдатель: может читать датчик и его историю
+	SensorRoleViewer SensorRole = "viewer"
+)
+
+func (r SensorRole) level() int {
+	switch r {
+	case SensorRoleOwner:
+		return 3
+	case SensorRoleOperator:
+		return 2
+	case SensorRoleViewer:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsValid сообщает, является ли r известной ролью
+func (r SensorRole) IsValid() bool {
+	return r.level() > 0
+}
+
+// Includes сообщает, дает ли роль r права роли other
+func (r SensorRole) Includes(other SensorRole) bool {
+	return r.IsValid() && r.level() >= other.level()
 }
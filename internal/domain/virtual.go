@@ -0,0 +1,351 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultVirtualResolution - шаг значения вычисляемого датчика, если он не задан
+const DefaultVirtualResolution = 0.01
+
+// ErrUndefinedValue - значение выражения не определено: деление на ноль или бесконечный результат
+var ErrUndefinedValue = errors.New("expression value is undefined")
+
+// VirtualSensor - вычисляемый датчик. Значение выражения хранится в событиях как целое число шагов Resolution,
+// поэтому история, правила и подписки работают с ним как с обычным датчиком.
+type VirtualSensor struct {
+	// Expression - выражение над текущими значениями других датчиков, например "avg(s1, s2, s3)" или "s4 - s5"
+	Expression string
+	// Resolution - шаг значения
+	Resolution float64
+	// Unit - единица измерения
+	Unit string
+}
+
+// Convert переводит сохраненное в событии число шагов в значение
+func (v *VirtualSensor) Convert(payload int64) float64 {
+	return float64(payload) * v.Resolution
+}
+
+// Payload переводит значение в число шагов, сохраняемое в событии. Значение, число шагов которого
+// не помещается в событие, не определено.
+func (v *VirtualSensor) Payload(value float64) (int64, error) {
+	steps := math.Round(value / v.Resolution)
+	if !(steps > math.MinInt64 && steps < math.MaxInt64) {
+		return 0, ErrUndefinedValue
+	}
+	return int64(steps), nil
+}
+
+// SensorExpression - разобранное выражение вычисляемого датчика
+type SensorExpression struct {
+	root   expressionNode
+	inputs []int64
+}
+
+// expressionNode - узел дерева выражения
+type expressionNode interface {
+	eval(values map[int64]float64) (float64, error)
+}
+
+type (
+	numberNode    float64
+	referenceNode int64
+	negateNode    struct{ operand expressionNode }
+	binaryNode    struct {
+		op          byte
+		left, right expressionNode
+	}
+	callNode struct {
+		function expressionFunction
+		args     []expressionNode
+	}
+)
+
+// expressionFunction - функция выражения: число аргументов от minArgs, без ограничения сверху, если variadic
+type expressionFunction struct {
+	name     string
+	minArgs  int
+	variadic bool
+	apply    func(args []float64) float64
+}
+
+var expressionFunctions = map[string]expressionFunction{
+	"avg": {name: "avg", minArgs: 1, variadic: true, apply: func(args []float64) float64 {
+		sum := 0.0
+		for _, arg := range args {
+			sum += arg
+		}
+		return sum / float64(len(args))
+	}},
+	"sum": {name: "sum", minArgs: 1, variadic: true, apply: func(args []float64) float64 {
+		sum := 0.0
+		for _, arg := range args {
+			sum += arg
+		}
+		return sum
+	}},
+	"min": {name: "min", minArgs: 1, variadic: true, apply: func(args []float64) float64 { return slices.Min(args) }},
+	"max": {name: "max", minArgs: 1, variadic: true, apply: func(args []float64) float64 { return slices.Max(args) }},
+	"abs": {name: "abs", minArgs: 1, apply: func(args []float64) float64 { return math.Abs(args[0]) }},
+}
+
+// ParseSensorExpression разбирает выражение вида "avg(s1, s2, s3) - 0.5": числа, ссылки на датчики s<id>,
+// операции + - * /, скобки и функции avg, sum, min, max, abs
+func ParseSensorExpression(expr string) (*SensorExpression, error) {
+	p := &expressionParser{input: expr}
+	root, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at %d", p.input[p.pos], p.pos)
+	}
+	if len(p.inputs) == 0 {
+		return nil, errors.New("expression does not reference sensors")
+	}
+
+	slices.Sort(p.inputs)
+	return &SensorExpression{root: root, inputs: slices.Compact(p.inputs)}, nil
+}
+
+// Inputs возвращает id датчиков, на которые ссылается выражение, по возрастанию
+func (e *SensorExpression) Inputs() []int64 {
+	return e.inputs
+}
+
+// Evaluate вычисляет выражение по значениям входных датчиков
+func (e *SensorExpression) Evaluate(values map[int64]float64) (float64, error) {
+	value, err := e.root.eval(values)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, ErrUndefinedValue
+	}
+	return value, nil
+}
+
+func (n numberNode) eval(map[int64]float64) (float64, error) {
+	return float64(n), nil
+}
+
+func (n referenceNode) eval(values map[int64]float64) (float64, error) {
+	value, ok := values[int64(n)]
+	if !ok {
+		return 0, fmt.Errorf("no value of sensor %d", int64(n))
+	}
+	return value, nil
+}
+
+func (n negateNode) eval(values map[int64]float64) (float64, error) {
+	value, err := n.operand.eval(values)
+	return -value, err
+}
+
+func (n binaryNode) eval(values map[int64]float64) (float64, error) {
+	left, err := n.left.eval(values)
+	if err != nil {
+		return 0, err
+	}
+	right, err := n.right.eval(values)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	}
+	if right == 0 {
+		return 0, ErrUndefinedValue
+	}
+	return left / right, nil
+}
+
+func (n callNode) eval(values map[int64]float64) (float64, error) {
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(values)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = value
+	}
+	return n.function.apply(args), nil
+}
+
+// expressionParser - разбор выражения рекурсивным спуском
+type expressionParser struct {
+	input  string
+	pos    int
+	inputs []int64
+}
+
+func (p *expressionParser) skipSpaces() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// peek возвращает следующий значимый символ, 0 - конец выражения
+func (p *expressionParser) peek() byte {
+	p.skipSpaces()
+	if p.pos == len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+// parseSum разбирает слагаемые, соединенные + и -
+func (p *expressionParser) parseSum() (expressionNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '+' || op == '-'; op = p.peek() {
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseProduct разбирает множители, соединенные * и /
+func (p *expressionParser) parseProduct() (expressionNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '*' || op == '/'; op = p.peek() {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseUnary() (expressionNode, error) {
+	if p.peek() == '-' {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negateNode{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary разбирает число, ссылку на датчик, вызов функции или выражение в скобках
+func (p *expressionParser) parsePrimary() (expressionNode, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, errors.New("unexpected end of expression")
+	case c == '(':
+		p.pos++
+		node, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case c == '.' || isDigit(c):
+		return p.parseNumber()
+	case isLetter(c):
+		return p.parseName()
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", c, p.pos)
+}
+
+func (p *expressionParser) parseNumber() (expressionNode, error) {
+	start := p.pos
+	for p.pos < len(p.input) && (p.input[p.pos] == '.' || isDigit(p.input[p.pos])) {
+		p.pos++
+	}
+	value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", p.input[start:p.pos])
+	}
+	return numberNode(value), nil
+}
+
+// parseName разбирает ссылку на датчик s<id> или вызов функции
+func (p *expressionParser) parseName() (expressionNode, error) {
+	start := p.pos
+	for p.pos < len(p.input) && (isDigit(p.input[p.pos]) || isLetter(p.input[p.pos])) {
+		p.pos++
+	}
+	name := strings.ToLower(p.input[start:p.pos])
+
+	function, ok := expressionFunctions[name]
+	if !ok {
+		if len(name) < 2 || name[0] != 's' {
+			return nil, fmt.Errorf("unknown name %q", name)
+		}
+		id, err := strconv.ParseInt(name[1:], 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid sensor reference %q", name)
+		}
+		p.inputs = append(p.inputs, id)
+		return referenceNode(id), nil
+	}
+
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	var args []expressionNode
+	for {
+		arg, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.peek() != ',' {
+			break
+		}
+		p.pos++
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+
+	if len(args) < function.minArgs || !function.variadic && len(args) > function.minArgs {
+		return nil, fmt.Errorf("wrong number of arguments of %s: %d", function.name, len(args))
+	}
+	return callNode{function: function, args: args}, nil
+}
+
+func (p *expressionParser) expect(c byte) error {
+	if p.peek() != c {
+		return fmt.Errorf("expected %q at %d", c, p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
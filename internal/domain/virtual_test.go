@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSensorExpression(t *testing.T) {
+	values := map[int64]float64{1: 21, 2: 22.5, 3: 24, 4: 1000, 5: 400}
+
+	tests := []struct {
+		expr   string
+		inputs []int64
+		want   float64
+	}{
+		{"avg(s1, s2, s3)", []int64{1, 2, 3}, 22.5},
+		{"s4 - s5", []int64{4, 5}, 600},
+		{"S4-s5*2", []int64{4, 5}, 200},
+		{"(s4 - s5) / 2 + .5", []int64{4, 5}, 300.5},
+		{"-s1 * -2", []int64{1}, 42},
+		{"max(s1, s3) - min(s1, s3)", []int64{1, 3}, 3},
+		{"abs(s5 - s4) + sum(s1, s1)", []int64{1, 4, 5}, 642},
+		{"\tavg(s3) ", []int64{3}, 24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := ParseSensorExpression(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.inputs, expr.Inputs())
+
+			value, err := expr.Evaluate(values)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, value, 1e-9)
+		})
+	}
+}
+
+func TestParseSensorExpression_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"1 + 2",
+		"s1 +",
+		"s1 s2",
+		"(s1",
+		"avg()",
+		"abs(s1, s2)",
+		"pow(s1, 2)",
+		"s0",
+		"sx",
+		"s1 % 2",
+		"1..2 + s1",
+		"avg(s1,)",
+		"s1 + ä",
+	} {
+		_, err := ParseSensorExpression(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestSensorExpression_Evaluate(t *testing.T) {
+	expr, err := ParseSensorExpression("s1 / s2")
+	require.NoError(t, err)
+
+	_, err = expr.Evaluate(map[int64]float64{1: 1, 2: 0})
+	assert.ErrorIs(t, err, ErrUndefinedValue)
+
+	_, err = expr.Evaluate(map[int64]float64{1: 1})
+	assert.Error(t, err, "missing input")
+}
+
+func TestVirtualSensor_Convert(t *testing.T) {
+	v := &VirtualSensor{Resolution: 0.01, Unit: "°C"}
+	payload, err := v.Payload(22.567)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2257), payload)
+	_, err = v.Payload(1e300)
+	assert.ErrorIs(t, err, ErrUndefinedValue)
+	assert.InDelta(t, 22.57, v.Convert(2257), 1e-9)
+
+	event := &Event{Payload: 2257}
+	(&Sensor{Virtual: v}).ApplyCalibration(event)
+	require.NotNil(t, event.Value)
+	assert.InDelta(t, 22.57, *event.Value, 1e-9)
+	assert.Equal(t, "°C", event.Unit)
+}
@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// WebhookEventKind - вид уведомления, которое отправляется подписке
+type WebhookEventKind string
+
+const (
+	// WebhookEventSensorEvent - датчик прислал событие
+	WebhookEventSensorEvent WebhookEventKind = "sensor.event"
+	// WebhookEventAlert - оповещение правила создано, повторилось, подтверждено или закрыто
+	WebhookEventAlert WebhookEventKind = "alert"
+	// WebhookEventAutomation - уведомление, отправленное действием автоматизации или сцены конкретной подписке
+	// в обход фильтров
+	WebhookEventAutomation WebhookEventKind = "automation"
+	// WebhookEventTest - проверочное уведомление, отправляется по запросу пользователя в обход фильтров
+	WebhookEventTest WebhookEventKind = "test"
+)
+
+// IsValid сообщает, можно ли подписаться на уведомления этого вида
+func (k WebhookEventKind) IsValid() bool {
+	return k == WebhookEventSensorEvent || k == WebhookEventAlert
+}
+
+// Webhook - подписка внешней системы на уведомления. Уведомления отправляются POST-запросом с JSON
+// и подписью HMAC-SHA256 по Secret. Пустые фильтры не ограничивают уведомления.
+type Webhook struct {
+	// ID - id подписки
+	ID int64
+	// URL - адрес, на который отправляются уведомления
+	URL string
+	// Secret - секрет подписи уведомлений, в JSON, в том числе в журнал аудита, не попадает
+	Secret string `json:"-"`
+	// EventKinds - виды уведомлений
+	EventKinds []WebhookEventKind
+	// SensorIDs - датчики, уведомления о которых отправляются
+	SensorIDs []int64
+	// SensorTypes - типы датчиков, уведомления о которых отправляются
+	SensorTypes []SensorType
+	// Labels - метки датчиков, уведомления о которых отправляются: у датчика должна быть хотя бы одна из них
+	Labels []string
+	// Enabled - включена ли подписка
+	Enabled bool
+	// CreatedBy - id пользователя, создавшего подписку, 0 - создана без аутентификации
+	CreatedBy int64
+	// CreatedAt - время создания
+	CreatedAt time.Time
+}
+
+// Matches сообщает, подходит ли уведомление вида kind о датчике sensor под фильтры включенной подписки
+func (w *Webhook) Matches(kind WebhookEventKind, sensor *Sensor) bool {
+	return w.Enabled &&
+		(len(w.EventKinds) == 0 || slices.Contains(w.EventKinds, kind)) &&
+		(len(w.SensorIDs) == 0 || slices.Contains(w.SensorIDs, sensor.ID)) &&
+		(len(w.SensorTypes) == 0 || slices.Contains(w.SensorTypes, sensor.Type)) &&
+		(len(w.Labels) == 0 || slices.ContainsFunc(sensor.Labels, func(label string) bool {
+			return slices.Contains(w.Labels, label)
+		}))
+}
+
+// WebhookDeliveryStatus - состояние отправки уведомления
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending - уведомление ждет первой или повторной попытки
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliveryDelivered - получатель ответил кодом 2xx
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryDead - попытки исчерпаны, уведомление больше не отправляется
+	WebhookDeliveryDead WebhookDeliveryStatus = "dead"
+)
+
+// IsValid сообщает, известно ли состояние
+func (s WebhookDeliveryStatus) IsValid() bool {
+	switch s {
+	case WebhookDeliveryPending, WebhookDeliveryDelivered, WebhookDeliveryDead:
+		return true
+	}
+	return false
+}
+
+// WebhookDelivery - отправка уведомления подписке, журнал отправок
+type WebhookDelivery struct {
+	// ID - id отправки
+	ID int64
+	// WebhookID - id подписки
+	WebhookID int64
+	// EventKind - вид уведомления
+	EventKind WebhookEventKind
+	// Payload - тело запроса в JSON
+	Payload []byte
+	// Status - состояние отправки
+	Status WebhookDeliveryStatus
+	// Attempts - число сделанных попыток
+	Attempts int
+	// NextAttemptAt - время следующей попытки для ожидающих отправок
+	NextAttemptAt time.Time
+	// LastStatusCode - код ответа на последнюю попытку, 0 - ответа не было
+	LastStatusCode int
+	// LastError - причина неудачи последней попытки
+	LastError string
+	// CreatedAt - время создания уведомления
+	CreatedAt time.Time
+	// DeliveredAt - время успешной отправки
+	DeliveredAt *time.Time
+}
+
+// WebhookDeliveryFilter - условия выборки журнала отправок, нулевые поля не ограничивают выборку
+type WebhookDeliveryFilter struct {
+	WebhookID int64
+	Status    WebhookDeliveryStatus
+	Limit     int
+	Offset    int
+}
+
+// Matches сообщает, подходит ли отправка под условия фильтра без учета пагинации
+func (f WebhookDeliveryFilter) Matches(delivery *WebhookDelivery) bool {
+	return (f.WebhookID == 0 || f.WebhookID == delivery.WebhookID) &&
+		(f.Status == "" || f.Status == delivery.Status)
+}
+
+// WebhookRetryPolicy - повторные попытки отправки: задержка перед попыткой n+1 равна BaseDelay * 2^(n-1),
+// но не больше MaxDelay
+type WebhookRetryPolicy struct {
+	// MaxAttempts - число попыток, после которого отправка переходит в WebhookDeliveryDead
+	MaxAttempts int
+	// BaseDelay - задержка перед второй попыткой
+	BaseDelay time.Duration
+	// MaxDelay - максимальная задержка между попытками
+	MaxDelay time.Duration
+}
+
+// Delay возвращает задержку перед следующей попыткой после attempts неудачных
+func (p WebhookRetryPolicy) Delay(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
+
+// Delivered отмечает успешную попытку отправки
+func (d *WebhookDelivery) Delivered(statusCode int, now time.Time) {
+	d.Attempts++
+	d.Status = WebhookDeliveryDelivered
+	d.LastStatusCode = statusCode
+	d.LastError = ""
+	d.DeliveredAt = &now
+}
+
+// Failed отмечает неудачную попытку отправки и назначает следующую или, если попытки исчерпаны,
+// переводит отправку в WebhookDeliveryDead
+func (d *WebhookDelivery) Failed(statusCode int, reason string, now time.Time, policy WebhookRetryPolicy) {
+	d.Attempts++
+	d.LastStatusCode = statusCode
+	d.LastError = reason
+	if d.Attempts >= policy.MaxAttempts {
+		d.Status = WebhookDeliveryDead
+		return
+	}
+	d.Status = WebhookDeliveryPending
+	d.NextAttemptAt = now.Add(policy.Delay(d.Attempts))
+}
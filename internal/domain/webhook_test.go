@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook_Matches(t *testing.T) {
+	door := &Sensor{ID: 1, Type: SensorTypeContactClosure, Labels: []string{"hall", "critical"}}
+	meter := &Sensor{ID: 2, Type: SensorTypeADC}
+
+	tests := []struct {
+		name    string
+		webhook Webhook
+		kind    WebhookEventKind
+		sensor  *Sensor
+		want    bool
+	}{
+		{"empty filters", Webhook{Enabled: true}, WebhookEventSensorEvent, door, true},
+		{"disabled", Webhook{}, WebhookEventSensorEvent, door, false},
+		{"kind", Webhook{Enabled: true, EventKinds: []WebhookEventKind{WebhookEventAlert}}, WebhookEventSensorEvent, door, false},
+		{"sensor", Webhook{Enabled: true, SensorIDs: []int64{2}}, WebhookEventSensorEvent, meter, true},
+		{"other sensor", Webhook{Enabled: true, SensorIDs: []int64{2}}, WebhookEventSensorEvent, door, false},
+		{"type", Webhook{Enabled: true, SensorTypes: []SensorType{SensorTypeADC}}, WebhookEventAlert, door, false},
+		{"label", Webhook{Enabled: true, Labels: []string{"critical", "kitchen"}}, WebhookEventAlert, door, true},
+		{"other label", Webhook{Enabled: true, Labels: []string{"kitchen"}}, WebhookEventAlert, door, false},
+		{"sensor without labels", Webhook{Enabled: true, Labels: []string{"critical"}}, WebhookEventAlert, meter, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.webhook.Matches(tt.kind, tt.sensor))
+		})
+	}
+}
+
+func TestWebhookDelivery_Failed(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	policy := WebhookRetryPolicy{MaxAttempts: 4, BaseDelay: time.Minute, MaxDelay: 3 * time.Minute}
+
+	assert.Equal(t, time.Minute, policy.Delay(1))
+	assert.Equal(t, 2*time.Minute, policy.Delay(2))
+	assert.Equal(t, 3*time.Minute, policy.Delay(3), "delay is capped")
+
+	delivery := &WebhookDelivery{Status: WebhookDeliveryPending}
+	for attempt, wantNext := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute} {
+		delivery.Failed(500, "server error", now, policy)
+		assert.Equal(t, attempt+1, delivery.Attempts)
+		assert.Equal(t, WebhookDeliveryPending, delivery.Status)
+		assert.Equal(t, now.Add(wantNext), delivery.NextAttemptAt)
+	}
+
+	delivery.Failed(0, "timeout", now, policy)
+	assert.Equal(t, WebhookDeliveryDead, delivery.Status, "dead after max attempts")
+	assert.Equal(t, "timeout", delivery.LastError)
+
+	delivery = &WebhookDelivery{Status: WebhookDeliveryPending, LastError: "timeout"}
+	delivery.Delivered(204, now)
+	assert.Equal(t, WebhookDeliveryDelivered, delivery.Status)
+	assert.Empty(t, delivery.LastError)
+	assert.Equal(t, now, *delivery.DeliveredAt)
+}
@@ -0,0 +1,283 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"homework/internal/domain"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensorAccess(t *testing.T) {
+	uc, _, ur := newInmemoryUseCases(t, withAuth())
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	register := func(name string) string {
+		w := doJSON(engine, http.MethodPost, "/users", `{"name": "`+name+`"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var registered UserRegistrationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+		return registered.Token
+	}
+	owner := register("owner")
+	stranger := register("stranger")
+
+	ctx := context.Background()
+	admin := &domain.User{Name: "admin", IsAdmin: true}
+	require.NoError(t, ur.SaveUser(ctx, admin))
+	_, adminToken, err := uc.Auth.CreateToken(ctx, admin.ID, "admin", 0)
+	require.NoError(t, err)
+
+	w := doAuthJSON(engine, http.MethodPost, "/sensors",
+		`{"serial_number": "0000000001", "type": "adc", "description": "t"}`, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	listSensors := func(token string) []SensorResponse {
+		w := doAuthJSON(engine, http.MethodGet, "/sensors", "", token)
+		require.Equal(t, http.StatusOK, w.Code)
+		var sensors []SensorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensors))
+		return sensors
+	}
+
+	t.Run("owner_reads_sensor", func(t *testing.T) {
+		assert.Len(t, listSensors(owner), 1)
+
+		w := doAuthJSON(engine, http.MethodGet, "/sensors/1", "", owner)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/sensors/1/history", "", owner)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("stranger_gets_404", func(t *testing.T) {
+		assert.Empty(t, listSensors(stranger))
+
+		w := doAuthJSON(engine, http.MethodGet, "/sensors/1", "", stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/sensors/1/history", "", stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/sensors/1/events", "", stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/users/2/sensors", `{"sensor_id": 1}`, stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("stranger_cannot_claim_existing_serial", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/sensors",
+			`{"serial_number": "0000000001", "type": "adc", "description": "t"}`, stranger)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("admin_sees_everything", func(t *testing.T) {
+		assert.Len(t, listSensors(adminToken), 1)
+
+		w := doAuthJSON(engine, http.MethodGet, "/sensors/1", "", adminToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestSensorRoles(t *testing.T) {
+	uc, _, _ := newInmemoryUseCases(t, withAuth())
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	register := func(name string) string {
+		w := doJSON(engine, http.MethodPost, "/users", `{"name": "`+name+`"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var registered UserRegistrationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+		return registered.Token
+	}
+	owner := register("owner")
+	member := register("member")
+
+	w := doAuthJSON(engine, http.MethodPost, "/sensors",
+		`{"serial_number": "0000000001", "type": "adc", "description": "t"}`, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = doAuthJSON(engine, http.MethodPost, "/users/2/sensors", `{"sensor_id": 1, "role": "viewer"}`, owner)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	t.Run("viewer_reads_but_cannot_change_settings", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/sensors/1/history", "", member)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPut, "/sensors/1/calibration", `{"scale": 2}`, member)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("unknown_role_422", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPut, "/users/2/sensors/1", `{"role": "admin"}`, owner)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("operator_changes_settings_but_cannot_share", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPut, "/users/2/sensors/1", `{"role": "operator"}`, owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		var link SensorOwnerResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &link))
+		assert.Equal(t, SensorOwnerResponse{UserID: 2, SensorID: 1, Role: "operator"}, link)
+
+		w = doAuthJSON(engine, http.MethodPut, "/sensors/1/calibration", `{"scale": 2}`, member)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPut, "/users/2/sensors/1", `{"role": "owner"}`, member)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/users/1/sensors", `{"sensor_id": 1, "role": "viewer"}`, member)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("role_of_unlinked_user_404", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPut, "/users/3/sensors/1", `{"role": "viewer"}`, owner)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestHomeRoles(t *testing.T) {
+	uc, _, _ := newInmemoryUseCases(t, withAuth())
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	register := func(name string) string {
+		w := doJSON(engine, http.MethodPost, "/users", `{"name": "`+name+`"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var registered UserRegistrationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+		return registered.Token
+	}
+	owner := register("owner")
+	member := register("member")
+	register("stranger")
+
+	w := doAuthJSON(engine, http.MethodPost, "/sensors",
+		`{"serial_number": "0000000001", "type": "adc", "description": "t"}`, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = doAuthJSON(engine, http.MethodPost, "/homes", `{"name": "Дача"}`, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = doAuthJSON(engine, http.MethodPost, "/homes/1/rooms", `{"name": "Кухня"}`, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = doAuthJSON(engine, http.MethodPost, "/rooms/1/sensors", `{"sensor_id": 1}`, owner)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = doAuthJSON(engine, http.MethodPost, "/users/2/homes", `{"home_id": 1}`, owner)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	t.Run("unknown_role_422", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/users/2/homes", `{"home_id": 1, "role": "admin"}`, owner)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("member_reads_home_and_sensors", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/homes/1", "", member)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/sensors/1", "", member)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("member_cannot_manage_home", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPatch, "/homes/1", `{"name": "Моя дача"}`, member)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = doAuthJSON(engine, http.MethodDelete, "/homes/1", "", member)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/users/3/homes", `{"home_id": 1}`, member)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("member_cannot_move_sensor_to_own_home", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/homes", `{"name": "Квартира"}`, member)
+		require.Equal(t, http.StatusOK, w.Code)
+		w = doAuthJSON(engine, http.MethodPost, "/homes/2/rooms", `{"name": "Спальня"}`, member)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/rooms/2/sensors", `{"sensor_id": 1}`, member)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("owner_is_not_demoted", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/users/1/homes", `{"home_id": 1, "role": "member"}`, owner)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPatch, "/homes/1", `{"name": "Дача у озера"}`, owner)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("promoted_member_manages_home", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/users/2/homes", `{"home_id": 1, "role": "owner"}`, owner)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/users/3/homes", `{"home_id": 1}`, member)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+}
+
+func TestSensorUsers(t *testing.T) {
+	engine, sr, ur := newInmemoryTestRouter(t)
+	ctx := context.Background()
+
+	require.NoError(t, sr.SaveSensor(ctx, &domain.Sensor{SerialNumber: "0000000001", Type: domain.SensorTypeADC, Description: "t"}))
+	for _, name := range []string{"first", "second"} {
+		require.NoError(t, ur.SaveUser(ctx, &domain.User{Name: name}))
+	}
+
+	w := doJSON(engine, http.MethodPost, "/users/1/sensors", `{"sensor_id": 1}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	w = doJSON(engine, http.MethodPost, "/users/2/sensors", `{"sensor_id": 1, "role": "viewer"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	t.Run("list_sensor_users", func(t *testing.T) {
+		w := doJSON(engine, http.MethodGet, "/sensors/1/users", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var links []SensorOwnerResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &links))
+		assert.Equal(t, []SensorOwnerResponse{
+			{UserID: 1, SensorID: 1, Role: "owner"},
+			{UserID: 2, SensorID: 1, Role: "viewer"},
+		}, links)
+
+		w = doJSON(engine, http.MethodGet, "/sensors/2/users", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("last_owner_cannot_be_detached_409", func(t *testing.T) {
+		w := doJSON(engine, http.MethodDelete, "/users/1/sensors/1", "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("detach_204_then_404", func(t *testing.T) {
+		w := doJSON(engine, http.MethodDelete, "/users/2/sensors/1", "")
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = doJSON(engine, http.MethodDelete, "/users/2/sensors/1", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doJSON(engine, http.MethodGet, "/users/2/sensors", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+	})
+
+	t.Run("allow_header_on_405", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/users/1/sensors/1", "")
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "PUT,DELETE,OPTIONS", w.Header().Get("Allow"))
+
+		w = doJSON(engine, http.MethodDelete, "/sensors/1/users", "")
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET,HEAD,OPTIONS", w.Header().Get("Allow"))
+	})
+}
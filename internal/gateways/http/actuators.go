@@ -0,0 +1,391 @@
+package http
+
+import (
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseActuatorSensorID разбирает необязательный фильтр sensor_id, 0 - устройства всех датчиков
+func parseActuatorSensorID(c *gin.Context) (int64, error) {
+	sensorID, err := strconv.ParseInt(c.DefaultQuery("sensor_id", "0"), 10, 64)
+	if err != nil || sensorID < 0 {
+		return 0, usecase.ErrInvalidActuator
+	}
+	return sensorID, nil
+}
+
+// parseCommandFilter разбирает параметры истории команд устройства: status, limit и offset
+func parseCommandFilter(c *gin.Context, actuatorID int64) (domain.CommandFilter, error) {
+	filter := domain.CommandFilter{
+		ActuatorID: actuatorID,
+		Status:     domain.CommandStatus(c.Query("status")),
+	}
+	var err error
+	if filter.Limit, filter.Offset, err = parsePagination(c); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+func setupActuatorsRoutes(r *gin.Engine, uc UseCases) {
+	actuatorsGroup := r.Group("/actuators")
+	{
+		actuatorsGroup.GET("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			sensorID, err := parseActuatorSensorID(c)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			actuators, err := uc.Actuator.GetActuators(c.Request.Context(), sensorID)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, actuatorsToResponse(actuators))
+		})
+
+		actuatorsGroup.HEAD("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			sensorID, err := parseActuatorSensorID(c)
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			actuators, err := uc.Actuator.GetActuators(c.Request.Context(), sensorID)
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			setContentLength(c, actuatorsToResponse(actuators))
+			c.Status(http.StatusOK)
+		})
+
+		actuatorsGroup.POST("", func(c *gin.Context) {
+			if !checkContentTypeJSON(c) {
+				return
+			}
+
+			var actuatorReq ActuatorRequest
+			if err := c.ShouldBindJSON(&actuatorReq); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+				return
+			}
+
+			if actuatorReq.SensorID <= 0 {
+				c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Reason: "Invalid sensor ID"})
+				return
+			}
+
+			result, err := uc.Actuator.CreateActuator(c.Request.Context(), actuatorToDomain(actuatorReq))
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusCreated, actuatorToResponse(result))
+		})
+
+		actuatorsGroup.OPTIONS("", func(c *gin.Context) {
+			setAllowHeader(c, "GET,HEAD,POST,OPTIONS")
+		})
+
+		setupActuatorByIDRoutes(actuatorsGroup, uc)
+		setupActuatorCommandsRoutes(actuatorsGroup, uc)
+	}
+}
+
+func setupActuatorByIDRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:actuator_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "actuator_id", "Invalid actuator ID")
+		if !ok {
+			return
+		}
+
+		actuator, err := uc.Actuator.GetActuatorByID(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, actuatorToResponse(actuator))
+	})
+
+	rg.HEAD("/:actuator_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("actuator_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		actuator, err := uc.Actuator.GetActuatorByID(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, actuatorToResponse(actuator))
+		c.Status(http.StatusOK)
+	})
+
+	rg.PUT("/:actuator_id", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "actuator_id", "Invalid actuator ID")
+		if !ok {
+			return
+		}
+
+		var actuatorReq ActuatorRequest
+		if err := c.ShouldBindJSON(&actuatorReq); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		actuator := actuatorToDomain(actuatorReq)
+		actuator.ID = id
+		result, err := uc.Actuator.UpdateActuator(c.Request.Context(), actuator)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, actuatorToResponse(result))
+	})
+
+	rg.DELETE("/:actuator_id", func(c *gin.Context) {
+		id, ok := parseIDParam(c, "actuator_id", "Invalid actuator ID")
+		if !ok {
+			return
+		}
+
+		if err := uc.Actuator.DeleteActuator(c.Request.Context(), id); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	rg.OPTIONS("/:actuator_id", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,PUT,DELETE,OPTIONS")
+	})
+}
+
+func setupActuatorCommandsRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:actuator_id/commands", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "actuator_id", "Invalid actuator ID")
+		if !ok {
+			return
+		}
+
+		filter, err := parseCommandFilter(c, id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		commands, err := uc.Actuator.GetCommands(c.Request.Context(), filter)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, commandsToResponse(commands))
+	})
+
+	rg.HEAD("/:actuator_id/commands", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("actuator_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		filter, err := parseCommandFilter(c, id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		commands, err := uc.Actuator.GetCommands(c.Request.Context(), filter)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, commandsToResponse(commands))
+		c.Status(http.StatusOK)
+	})
+
+	rg.POST("/:actuator_id/commands", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "actuator_id", "Invalid actuator ID")
+		if !ok {
+			return
+		}
+
+		var commandReq CommandRequest
+		if err := c.ShouldBindJSON(&commandReq); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		command, timeout := commandToDomain(commandReq, id)
+		result, err := uc.Actuator.SendCommand(c.Request.Context(), command, timeout)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, commandToResponse(result))
+	})
+
+	rg.OPTIONS("/:actuator_id/commands", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,POST,OPTIONS")
+	})
+}
+
+func setupCommandsRoutes(r *gin.Engine, uc UseCases) {
+	commandsGroup := r.Group("/commands")
+	{
+		commandsGroup.GET("/:command_id", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			id, ok := parseIDParam(c, "command_id", "Invalid command ID")
+			if !ok {
+				return
+			}
+
+			command, err := uc.Actuator.GetCommandByID(c.Request.Context(), id)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, commandToResponse(command))
+		})
+
+		commandsGroup.HEAD("/:command_id", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			id, err := strconv.ParseInt(c.Param("command_id"), 10, 64)
+			if err != nil {
+				c.Status(http.StatusUnprocessableEntity)
+				return
+			}
+
+			command, err := uc.Actuator.GetCommandByID(c.Request.Context(), id)
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			setContentLength(c, commandToResponse(command))
+			c.Status(http.StatusOK)
+		})
+
+		commandsGroup.OPTIONS("/:command_id", func(c *gin.Context) {
+			setAllowHeader(c, "GET,HEAD,OPTIONS")
+		})
+	}
+}
+
+// setupDeviceCommandsRoutes - очередь команд для контроллеров. Если задан DeviceAuth, контроллер
+// подписывает запросы так же, как события, иначе запросы выполняются от имени пользователя с ролью оператора.
+func setupDeviceCommandsRoutes(r *gin.Engine, uc UseCases) {
+	devicesGroup := r.Group("/devices/:serial_number/commands")
+	{
+		var handlers []gin.HandlerFunc
+		if uc.DeviceAuth != nil {
+			handlers = append(handlers, deviceCommandsSignatureMiddleware(uc.DeviceAuth))
+		}
+
+		// команда возвращается при каждом опросе, пока контроллер не сообщит о ее выполнении
+		devicesGroup.GET("", append(handlers, func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			commands, err := uc.Actuator.FetchCommands(c.Request.Context(), c.Param("serial_number"))
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, commandsToResponse(commands))
+		})...)
+
+		devicesGroup.OPTIONS("", func(c *gin.Context) {
+			setAllowHeader(c, "GET,OPTIONS")
+		})
+
+		devicesGroup.POST("/:command_id/ack", append(handlers, func(c *gin.Context) {
+			if !checkContentTypeJSON(c) {
+				return
+			}
+
+			id, ok := parseIDParam(c, "command_id", "Invalid command ID")
+			if !ok {
+				return
+			}
+
+			var ackReq CommandAckRequest
+			if err := c.ShouldBindJSON(&ackReq); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+				return
+			}
+
+			command, err := uc.Actuator.AckCommand(c.Request.Context(), c.Param("serial_number"), id,
+				domain.CommandStatus(ackReq.Status), ackReq.Error)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, commandToResponse(command))
+		})...)
+
+		devicesGroup.OPTIONS("/:command_id/ack", func(c *gin.Context) {
+			setAllowHeader(c, "POST,OPTIONS")
+		})
+	}
+}
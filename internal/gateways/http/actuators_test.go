@@ -0,0 +1,171 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"homework/internal/usecase"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// doSignedDeviceRequest отправляет запрос контроллера, подписанный секретом датчика
+func doSignedDeviceRequest(engine *gin.Engine, method, path, body, secret, nonce string) *httptest.ResponseRecorder {
+	timestamp := time.Now().Unix()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewReader([]byte(body)))
+	if body != "" {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	req.Header.Add(headerSensorTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Add(headerSensorNonce, nonce)
+	req.Header.Add(headerSensorSignature, usecase.SignEvent(secret, timestamp, nonce, []byte(body)))
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestActuators(t *testing.T) {
+	uc, _, _ := newInmemoryUseCases(t, withDeviceAuth())
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	register := func(name string) string {
+		w := doJSON(engine, http.MethodPost, "/users", `{"name": "`+name+`"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var registered UserRegistrationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+		return registered.Token
+	}
+	owner := register("owner")
+	stranger := register("stranger")
+
+	w := doAuthJSON(engine, http.MethodPost, "/sensors",
+		`{"serial_number": "0000000001", "type": "cc", "description": "controller"}`, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+	var sensor SensorRegistrationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensor))
+
+	w = doAuthJSON(engine, http.MethodPost, "/actuators", `{"sensor_id": 1, "name": "pump", "type": "relay"}`, owner)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var actuator ActuatorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actuator))
+	assert.Equal(t, int64(0), actuator.State)
+
+	t.Run("invalid_actuator_and_command", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/actuators", `{"sensor_id": 1, "name": "door", "type": "lock"}`, owner)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		for _, body := range []string{
+			`{"kind": "set_state", "state": 2}`,
+			`{"kind": "pulse"}`,
+			`{"kind": "toggle"}`,
+			`{"kind": "set_state", "state": 1, "timeout_seconds": -1}`,
+		} {
+			w = doAuthJSON(engine, http.MethodPost, "/actuators/1/commands", body, owner)
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code, body)
+		}
+	})
+
+	t.Run("stranger_can't_see_actuator", func(t *testing.T) {
+		for _, path := range []string{"/actuators/1", "/actuators/1/commands"} {
+			w := doAuthJSON(engine, http.MethodGet, path, "", stranger)
+			assert.Equal(t, http.StatusNotFound, w.Code, path)
+		}
+		w := doAuthJSON(engine, http.MethodPost, "/actuators/1/commands", `{"kind": "set_state", "state": 1}`, stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/actuators", "", stranger)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+	})
+
+	t.Run("device_requests_must_be_signed", func(t *testing.T) {
+		w := doJSON(engine, http.MethodGet, "/devices/0000000001/commands", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = doSignedDeviceRequest(engine, http.MethodGet, "/devices/0000000002/commands", "", sensor.Secret, "nonce-0")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("command_lifecycle", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/actuators/1/commands", `{"kind": "set_state", "state": 1}`, owner)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var command CommandResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &command))
+		assert.Equal(t, "pending", command.Status)
+
+		w = doSignedDeviceRequest(engine, http.MethodGet, "/devices/0000000001/commands", "", sensor.Secret, "nonce-1")
+		require.Equal(t, http.StatusOK, w.Code)
+		var fetched []CommandResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetched))
+		require.Len(t, fetched, 1)
+		assert.Equal(t, command.ID, fetched[0].ID)
+		assert.Equal(t, "delivered", fetched[0].Status)
+
+		path := "/devices/0000000001/commands/" + strconv.FormatInt(command.ID, 10) + "/ack"
+		w = doSignedDeviceRequest(engine, http.MethodPost, path, `{"status": "acked"}`, sensor.Secret, "nonce-2")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = doSignedDeviceRequest(engine, http.MethodPost, path, `{"status": "failed"}`, sensor.Secret, "nonce-3")
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = doSignedDeviceRequest(engine, http.MethodGet, "/devices/0000000001/commands", "", sensor.Secret, "nonce-4")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+
+		w = doAuthJSON(engine, http.MethodGet, "/actuators/1", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actuator))
+		assert.Equal(t, int64(1), actuator.State)
+
+		w = doAuthJSON(engine, http.MethodGet, "/commands/"+strconv.FormatInt(command.ID, 10), "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &command))
+		assert.Equal(t, "acked", command.Status)
+		assert.NotNil(t, command.CompletedAt)
+	})
+
+	t.Run("failed_pulse_in_history", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/actuators/1/commands", `{"kind": "pulse", "duration_ms": 500}`, owner)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var command CommandResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &command))
+
+		path := "/devices/0000000001/commands/" + strconv.FormatInt(command.ID, 10) + "/ack"
+		w = doSignedDeviceRequest(engine, http.MethodPost, path, `{"status": "failed", "error": "relay stuck"}`,
+			sensor.Secret, "nonce-5")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/actuators/1/commands", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		var history []CommandResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+		require.Len(t, history, 2)
+		assert.Equal(t, "failed", history[0].Status)
+		assert.Equal(t, "relay stuck", history[0].Error)
+		assert.Equal(t, int64(500), history[0].DurationMs)
+
+		w = doAuthJSON(engine, http.MethodGet, "/actuators/1/commands?status=acked", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+		require.Len(t, history, 1)
+
+		w = doAuthJSON(engine, http.MethodGet, "/actuators/1/commands?status=done", "", owner)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("delete_removes_history", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodDelete, "/actuators/1", "", owner)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/commands/1", "", owner)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,161 @@
+package http
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseAlertFilter разбирает параметры выборки оповещений: status, sensor_id, rule_id, limit и offset
+func parseAlertFilter(c *gin.Context) (domain.AlertFilter, error) {
+	filter := domain.AlertFilter{Status: domain.AlertStatus(c.Query("status"))}
+
+	var err error
+	if filter.SensorID, err = strconv.ParseInt(c.DefaultQuery("sensor_id", "0"), 10, 64); err != nil || filter.SensorID < 0 {
+		return filter, usecase.ErrInvalidAlertFilter
+	}
+	if filter.RuleID, err = strconv.ParseInt(c.DefaultQuery("rule_id", "0"), 10, 64); err != nil || filter.RuleID < 0 {
+		return filter, usecase.ErrInvalidAlertFilter
+	}
+	if filter.Limit, filter.Offset, err = parsePagination(c); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+func setupAlertsRoutes(r *gin.Engine, uc UseCases, ws *WebSocketHandler) {
+	alertsGroup := r.Group("/alerts")
+	{
+		alertsGroup.GET("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			filter, err := parseAlertFilter(c)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			alerts, err := uc.Alert.GetAlerts(c.Request.Context(), filter)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, alertsToResponse(alerts))
+		})
+
+		alertsGroup.HEAD("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			filter, err := parseAlertFilter(c)
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			alerts, err := uc.Alert.GetAlerts(c.Request.Context(), filter)
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			setContentLength(c, alertsToResponse(alerts))
+			c.Status(http.StatusOK)
+		})
+
+		alertsGroup.OPTIONS("", func(c *gin.Context) {
+			setAllowHeader(c, "GET,HEAD,OPTIONS")
+		})
+
+		// изменения оповещений доступных пользователю датчиков по WebSocket
+		alertsGroup.GET("/stream", func(c *gin.Context) {
+			_ = ws.HandleAlerts(c)
+		})
+
+		setupAlertByIDRoutes(alertsGroup, uc)
+	}
+}
+
+func setupAlertByIDRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:alert_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "alert_id", "Invalid alert ID")
+		if !ok {
+			return
+		}
+
+		alert, err := uc.Alert.GetAlertByID(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, alertToResponse(alert))
+	})
+
+	rg.HEAD("/:alert_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("alert_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		alert, err := uc.Alert.GetAlertByID(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, alertToResponse(alert))
+		c.Status(http.StatusOK)
+	})
+
+	rg.OPTIONS("/:alert_id", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,OPTIONS")
+	})
+
+	setupAlertActionRoute(rg, "/:alert_id/ack", uc.Alert.AcknowledgeAlert)
+	setupAlertActionRoute(rg, "/:alert_id/resolve", uc.Alert.ResolveAlert)
+}
+
+// setupAlertActionRoute регистрирует POST path, меняющий состояние оповещения через action
+func setupAlertActionRoute(rg *gin.RouterGroup, path string,
+	action func(ctx context.Context, id int64) (*domain.Alert, error)) {
+	rg.POST(path, func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "alert_id", "Invalid alert ID")
+		if !ok {
+			return
+		}
+
+		alert, err := action(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, alertToResponse(alert))
+	})
+
+	rg.OPTIONS(path, func(c *gin.Context) {
+		setAllowHeader(c, "POST,OPTIONS")
+	})
+}
@@ -0,0 +1,186 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlerts(t *testing.T) {
+	uc, _, _ := newInmemoryUseCases(t, withAuth())
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	register := func(name string) string {
+		w := doJSON(engine, http.MethodPost, "/users", `{"name": "`+name+`"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var registered UserRegistrationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+		return registered.Token
+	}
+	owner := register("owner")
+	stranger := register("stranger")
+
+	w := doAuthJSON(engine, http.MethodPost, "/sensors",
+		`{"serial_number": "0000000001", "type": "cc", "description": "door"}`, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = doAuthJSON(engine, http.MethodPost, "/rules",
+		`{"sensor_id": 1, "name": "Дверь открыта", "operator": "==", "threshold": 1}`, owner)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	sendEvent := func(payload string) {
+		w := doAuthJSON(engine, http.MethodPost, "/events",
+			`{"sensor_serial_number": "0000000001", "payload": `+payload+`}`, owner)
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+	getAlert := func(path, token string) AlertResponse {
+		w := doAuthJSON(engine, http.MethodGet, path, "", token)
+		require.Equal(t, http.StatusOK, w.Code)
+		var alert AlertResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &alert))
+		return alert
+	}
+
+	srv := httptest.NewServer(engine)
+	defer srv.Close()
+	srvURL, _ := url.Parse(srv.URL)
+	srvURL.Scheme = "ws"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	dial := func(token string) *websocket.Conn {
+		conn, _, err := websocket.Dial(ctx, srvURL.String()+"/alerts/stream", &websocket.DialOptions{
+			HTTPHeader: http.Header{"Authorization": {"Bearer " + token}},
+		})
+		require.NoError(t, err)
+		return conn
+	}
+	ownerConn := dial(owner)
+	defer ownerConn.CloseNow() //nolint: errcheck // test cleanup
+	strangerConn := dial(stranger)
+	defer strangerConn.CloseNow() //nolint: errcheck // test cleanup
+
+	sendEvent("1")
+	sendEvent("1")
+
+	t.Run("stream_pushes_changes", func(t *testing.T) {
+		for _, occurrences := range []int{1, 2} {
+			_, msg, err := ownerConn.Read(ctx)
+			require.NoError(t, err)
+			var alert AlertResponse
+			require.NoError(t, json.Unmarshal(msg, &alert))
+			assert.Equal(t, "open", alert.Status)
+			assert.Equal(t, occurrences, alert.Occurrences)
+		}
+
+		readCtx, readCancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer readCancel()
+		_, _, err := strangerConn.Read(readCtx)
+		assert.Error(t, err, "alerts of inaccessible sensors are not pushed")
+	})
+
+	t.Run("GET_alerts_deduplicated", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/alerts", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		var alerts []AlertResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &alerts))
+		require.Len(t, alerts, 1)
+		assert.Equal(t, 2, alerts[0].Occurrences)
+		assert.Equal(t, float64(1), alerts[0].Value)
+	})
+
+	t.Run("stranger_gets_404", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/alerts", "", stranger)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+
+		w = doAuthJSON(engine, http.MethodGet, "/alerts/1", "", stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/alerts/1/ack", "", stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("POST_ack_200", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/alerts/1/ack", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		var alert AlertResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &alert))
+		assert.Equal(t, "acknowledged", alert.Status)
+		assert.Equal(t, int64(1), alert.AcknowledgedBy)
+		assert.NotNil(t, alert.AcknowledgedAt)
+
+		sendEvent("1")
+		alert = getAlert("/alerts/1", owner)
+		assert.Equal(t, "acknowledged", alert.Status, "repeat keeps acknowledgement")
+		assert.Equal(t, 3, alert.Occurrences)
+	})
+
+	t.Run("condition_clears_auto_resolve", func(t *testing.T) {
+		sendEvent("0")
+
+		alert := getAlert("/alerts/1", owner)
+		assert.Equal(t, "resolved", alert.Status)
+		assert.Zero(t, alert.ResolvedBy)
+		assert.NotNil(t, alert.ResolvedAt)
+
+		w := doAuthJSON(engine, http.MethodPost, "/alerts/1/resolve", "", owner)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		w = doAuthJSON(engine, http.MethodPost, "/alerts/1/ack", "", owner)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("POST_resolve_200", func(t *testing.T) {
+		sendEvent("1")
+
+		w := doAuthJSON(engine, http.MethodPost, "/alerts/2/resolve", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		var alert AlertResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &alert))
+		assert.Equal(t, "resolved", alert.Status)
+		assert.Equal(t, int64(1), alert.ResolvedBy)
+	})
+
+	t.Run("GET_alerts_filters", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/alerts?status=open", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+
+		w = doAuthJSON(engine, http.MethodGet, "/alerts?status=resolved&sensor_id=1&limit=1", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		var alerts []AlertResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &alerts))
+		require.Len(t, alerts, 1)
+		assert.Equal(t, int64(2), alerts[0].ID)
+
+		for _, query := range []string{"status=closed", "rule_id=x", "sensor_id=-1", "limit=1000"} {
+			w := doAuthJSON(engine, http.MethodGet, "/alerts?"+query, "", owner)
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code, query)
+		}
+	})
+
+	t.Run("allow_header", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPut, "/alerts/1", "", owner)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET,HEAD,OPTIONS", w.Header().Get("Allow"))
+
+		w = doAuthJSON(engine, http.MethodOptions, "/alerts/1/ack", "", owner)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "POST,OPTIONS", w.Header().Get("Allow"))
+	})
+
+	t.Run("GET_alerts_401", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/alerts", "", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
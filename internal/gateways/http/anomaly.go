@@ -0,0 +1,101 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupSensorAnomalyDetectionRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:sensor_id/anomaly-detection", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		sensor, err := uc.Sensor.GetSensorByID(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+		if sensor.AnomalyDetection == nil {
+			c.JSON(http.StatusNotFound, ErrorResponse{Reason: "anomaly detection not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, anomalyDetectionToResponse(sensor.AnomalyDetection))
+	})
+
+	rg.HEAD("/:sensor_id/anomaly-detection", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("sensor_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		sensor, err := uc.Sensor.GetSensorByID(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+		if sensor.AnomalyDetection == nil {
+			c.Status(http.StatusNotFound)
+			return
+		}
+
+		setContentLength(c, anomalyDetectionToResponse(sensor.AnomalyDetection))
+		c.Status(http.StatusOK)
+	})
+
+	rg.PUT("/:sensor_id/anomaly-detection", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		var detectionReq AnomalyDetectionRequest
+		if err := c.ShouldBindJSON(&detectionReq); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		sensor, err := uc.Sensor.SetSensorAnomalyDetection(c.Request.Context(), id, anomalyDetectionToDomain(&detectionReq))
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, sensorToResponse(sensor))
+	})
+
+	rg.DELETE("/:sensor_id/anomaly-detection", func(c *gin.Context) {
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		if _, err := uc.Sensor.SetSensorAnomalyDetection(c.Request.Context(), id, nil); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	rg.OPTIONS("/:sensor_id/anomaly-detection", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,PUT,DELETE,OPTIONS")
+	})
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"homework/internal/domain"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensorAnomalyDetectionRoutes(t *testing.T) {
+	engine, sr, _ := newInmemoryTestRouter(t)
+	ctx := context.Background()
+
+	adc := &domain.Sensor{SerialNumber: "0000000001", Type: domain.SensorTypeADC, Description: "termometer"}
+	require.NoError(t, sr.SaveSensor(ctx, adc))
+	cc := &domain.Sensor{SerialNumber: "0000000002", Type: domain.SensorTypeContactClosure, Description: "door"}
+	require.NoError(t, sr.SaveSensor(ctx, cc))
+
+	t.Run("GET_anomaly_detection_not_set_404", func(t *testing.T) {
+		w := doJSON(engine, http.MethodGet, "/sensors/1/anomaly-detection", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("PUT_anomaly_detection_cc_sensor_422", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPut, "/sensors/2/anomaly-detection", `{}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("PUT_anomaly_detection_invalid_sigma_422", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPut, "/sensors/1/anomaly-detection", `{"sigma": -1}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("PUT_anomaly_detection_defaults_200", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPut, "/sensors/1/anomaly-detection", `{"warm_up": 5}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var sensor SensorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensor))
+		require.NotNil(t, sensor.AnomalyDetection)
+		assert.Equal(t, AnomalyDetectionResponse{Alpha: domain.DefaultAnomalyAlpha, Sigma: domain.DefaultAnomalySigma, WarmUp: 5},
+			*sensor.AnomalyDetection)
+
+		w = doJSON(engine, http.MethodHead, "/sensors/1/anomaly-detection", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("POST_events_spike_is_anomalous_and_alerted", func(t *testing.T) {
+		for _, payload := range []int{100, 102, 98, 101, 99, 100, 500} {
+			w := doJSON(engine, http.MethodPost, "/events", `{"sensor_serial_number": "0000000001", "payload": `+
+				strconv.Itoa(payload)+`}`)
+			require.Equal(t, http.StatusCreated, w.Code)
+		}
+
+		w := doJSON(engine, http.MethodGet, "/sensors/1/history?end_date="+time.Now().Add(time.Minute).Format(time.RFC3339), "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var history []SensorHistoryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+		require.Len(t, history, 7)
+		for _, event := range history[:5] {
+			assert.Nil(t, event.AnomalyScore, "warm up")
+		}
+		require.NotNil(t, history[5].AnomalyScore)
+		assert.False(t, history[5].Anomalous)
+		require.NotNil(t, history[6].AnomalyScore)
+		assert.Greater(t, *history[6].AnomalyScore, float64(domain.DefaultAnomalySigma))
+		assert.True(t, history[6].Anomalous)
+
+		w = doJSON(engine, http.MethodGet, "/alerts?sensor_id=1", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var alerts []AlertResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &alerts))
+		require.Len(t, alerts, 1)
+		assert.True(t, alerts[0].Anomaly)
+		assert.Equal(t, "open", alerts[0].Status)
+		assert.Equal(t, 500.0, alerts[0].Value)
+	})
+
+	t.Run("DELETE_anomaly_detection_resolves_alert_204", func(t *testing.T) {
+		w := doJSON(engine, http.MethodDelete, "/sensors/1/anomaly-detection", "")
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = doJSON(engine, http.MethodGet, "/sensors/1/anomaly-detection", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doJSON(engine, http.MethodGet, "/alerts?sensor_id=1", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var alerts []AlertResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &alerts))
+		require.Len(t, alerts, 1)
+		assert.Equal(t, "resolved", alerts[0].Status)
+	})
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// headerRequestID - id запроса: принимается от клиента или прокси, иначе генерируется, и возвращается в ответе
+	headerRequestID = "X-Request-ID"
+	// maxRequestIDLength - максимальная длина принимаемого от клиента id запроса
+	maxRequestIDLength = 128
+)
+
+// requestInfoMiddleware кладет в контекст запроса его id и адрес клиента для журнала аудита
+func requestInfoMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(headerRequestID)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+		c.Header(headerRequestID, requestID)
+
+		info := usecase.RequestInfo{ID: requestID, SourceIP: c.ClientIP()}
+		c.Request = c.Request.WithContext(usecase.ContextWithRequestInfo(c.Request.Context(), info))
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// parseAuditFilter разбирает параметры выборки журнала: entity_type, entity_id, actor_id, limit и offset
+func parseAuditFilter(c *gin.Context) (domain.AuditFilter, error) {
+	filter := domain.AuditFilter{EntityType: domain.AuditEntityType(c.Query("entity_type"))}
+
+	var err error
+	if filter.EntityID, err = strconv.ParseInt(c.DefaultQuery("entity_id", "0"), 10, 64); err != nil || filter.EntityID < 0 {
+		return filter, usecase.ErrInvalidAuditFilter
+	}
+	if filter.ActorID, err = strconv.ParseInt(c.DefaultQuery("actor_id", "0"), 10, 64); err != nil || filter.ActorID < 0 {
+		return filter, usecase.ErrInvalidAuditFilter
+	}
+	if filter.Limit, filter.Offset, err = parsePagination(c); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+func setupAuditRoutes(r *gin.Engine, uc UseCases) {
+	auditGroup := r.Group("/audit")
+	{
+		auditGroup.GET("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			filter, err := parseAuditFilter(c)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			entries, err := uc.Audit.GetAuditEntries(c.Request.Context(), filter)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, auditEntriesToResponse(entries))
+		})
+
+		auditGroup.HEAD("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			filter, err := parseAuditFilter(c)
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			entries, err := uc.Audit.GetAuditEntries(c.Request.Context(), filter)
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			setContentLength(c, auditEntriesToResponse(entries))
+			c.Status(http.StatusOK)
+		})
+
+		auditGroup.OPTIONS("", func(c *gin.Context) {
+			setAllowHeader(c, "GET,HEAD,OPTIONS")
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudit(t *testing.T) {
+	uc, _, _ := newInmemoryUseCases(t, withAuth())
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	register := func(name string) string {
+		w := doJSON(engine, http.MethodPost, "/users", `{"name": "`+name+`"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var registered UserRegistrationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+		return registered.Token
+	}
+	owner := register("owner")
+	other := register("other")
+
+	getEntries := func(path, token string) []AuditEntryResponse {
+		w := doAuthJSON(engine, http.MethodGet, path, "", token)
+		require.Equal(t, http.StatusOK, w.Code)
+		var entries []AuditEntryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+		return entries
+	}
+
+	t.Run("request_id_is_echoed_and_recorded", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/homes", `{"name": "Дача"}`, owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, w.Header().Get(headerRequestID), 32)
+
+		w = doAuthJSON(engine, http.MethodGet, "/homes/1", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPatch, "/homes/1", strings.NewReader(`{"name": "Дом"}`))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Accept", "application/json")
+		req.Header.Add("Authorization", "Bearer "+owner)
+		req.Header.Add(headerRequestID, "rename-1")
+		engine.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "rename-1", w.Header().Get(headerRequestID))
+
+		entries := getEntries("/audit?entity_type=home&entity_id=1", owner)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "update", entries[0].Action)
+		assert.Equal(t, "rename-1", entries[0].RequestID)
+		assert.Equal(t, AuditChangeResponse{Before: "Дача", After: "Дом"}, entries[0].Changes["Name"])
+		assert.NotContains(t, entries[0].Changes, "ID")
+		assert.Equal(t, "create", entries[1].Action)
+		assert.Equal(t, int64(1), entries[1].ActorID)
+	})
+
+	t.Run("filter_by_actor", func(t *testing.T) {
+		entries := getEntries("/audit?actor_id=1&limit=1", owner)
+		require.Len(t, entries, 1)
+		assert.Equal(t, int64(1), entries[0].ActorID)
+
+		assert.Empty(t, getEntries("/audit?actor_id=1", other))
+	})
+
+	t.Run("restricted_user_sees_own_entries", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/homes", `{"name": "Квартира"}`, other)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		entries := getEntries("/audit", other)
+		require.NotEmpty(t, entries)
+		for _, entry := range entries {
+			assert.Equal(t, int64(2), entry.ActorID)
+		}
+	})
+
+	t.Run("invalid_filter_422", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/audit?entity_id=abc", "", owner)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/audit?actor_id=-1", "", owner)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/audit?limit=1000", "", owner)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("unauthenticated_401", func(t *testing.T) {
+		w := doJSON(engine, http.MethodGet, "/audit", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("OPTIONS_and_405", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodOptions, "/audit", "", owner)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "GET,HEAD,OPTIONS", w.Header().Get("Allow"))
+
+		w = doAuthJSON(engine, http.MethodPost, "/audit", "", owner)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET,HEAD,OPTIONS", w.Header().Get("Allow"))
+	})
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// anonymousUser - имя, которым в ответах подписываются запросы без аутентификации
+	anonymousUser = "anonymous"
+	// initialTokenName - название токена, выдаваемого при регистрации пользователя
+	initialTokenName = "initial"
+)
+
+// publicRoutes - маршруты, доступные без токена. OPTIONS доступен всегда.
+var publicRoutes = map[string]bool{
+	http.MethodPost + " /users": true,
+	http.MethodGet + " /ping":   true,
+}
+
+// withPublicRoute возвращает копию набора публичных маршрутов с добавленным route
+func withPublicRoute(public map[string]bool, route string) map[string]bool {
+	result := make(map[string]bool, len(public)+1)
+	for r := range public {
+		result[r] = true
+	}
+	result[route] = true
+	return result
+}
+
+// authMiddleware проверяет заголовок Authorization: Bearer и кладет пользователя в контекст запроса.
+// Запросы к несуществующим маршрутам пропускаются, чтобы на них по-прежнему отвечали 404 и 405.
+func authMiddleware(auth *usecase.Auth, public map[string]bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.FullPath() == "" || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			if public[c.Request.Method+" "+c.FullPath()] {
+				c.Next()
+				return
+			}
+			abortUnauthenticated(c, "Authorization required")
+			return
+		}
+
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			abortUnauthenticated(c, "Invalid authorization header")
+			return
+		}
+
+		user, err := auth.Authenticate(c.Request.Context(), strings.TrimSpace(token))
+		if errors.Is(err, usecase.ErrUnauthenticated) {
+			abortUnauthenticated(c, "Invalid token")
+			return
+		}
+		if err != nil {
+			handleError(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(usecase.ContextWithUser(c.Request.Context(), user))
+		c.Next()
+	}
+}
+
+func abortUnauthenticated(c *gin.Context, reason string) {
+	c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Reason: reason})
+}
+
+// currentUser возвращает аутентифицированного пользователя запроса
+func currentUser(c *gin.Context) (*domain.User, bool) {
+	return usecase.UserFromContext(c.Request.Context())
+}
+
+// callerName возвращает имя пользователя, выполнившего запрос, для метаданных ответов
+func callerName(c *gin.Context) string {
+	if user, ok := currentUser(c); ok {
+		return user.Name
+	}
+	return anonymousUser
+}
+
+func setupTokensRoutes(r *gin.Engine, uc UseCases) {
+	tokensGroup := r.Group("/tokens")
+	{
+		tokensGroup.GET("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			user, ok := currentUser(c)
+			if !ok {
+				abortUnauthenticated(c, "Authorization required")
+				return
+			}
+
+			tokens, err := uc.Auth.GetUserTokens(c.Request.Context(), user.ID)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, tokensToResponse(tokens))
+		})
+
+		tokensGroup.POST("", func(c *gin.Context) {
+			if !checkContentTypeJSON(c) {
+				return
+			}
+
+			user, ok := currentUser(c)
+			if !ok {
+				abortUnauthenticated(c, "Authorization required")
+				return
+			}
+
+			var tokenReq TokenCreateRequest
+			if err := c.ShouldBindJSON(&tokenReq); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+				return
+			}
+
+			token, plain, err := uc.Auth.CreateToken(c.Request.Context(), user.ID, tokenReq.Name,
+				time.Duration(tokenReq.TTLSeconds)*time.Second)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			response := tokenToResponse(token)
+			response.Token = plain
+			c.JSON(http.StatusCreated, response)
+		})
+
+		tokensGroup.OPTIONS("", func(c *gin.Context) {
+			setAllowHeader(c, "GET,POST,OPTIONS")
+		})
+
+		tokensGroup.DELETE("/:token_id", func(c *gin.Context) {
+			user, ok := currentUser(c)
+			if !ok {
+				abortUnauthenticated(c, "Authorization required")
+				return
+			}
+
+			id, ok := parseIDParam(c, "token_id", "Invalid token ID")
+			if !ok {
+				return
+			}
+
+			if err := uc.Auth.RevokeToken(c.Request.Context(), user.ID, id); err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.Status(http.StatusNoContent)
+		})
+
+		tokensGroup.OPTIONS("/:token_id", func(c *gin.Context) {
+			setAllowHeader(c, "DELETE,OPTIONS")
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthentication(t *testing.T) {
+	uc, _, _ := newInmemoryUseCases(t, withAuth())
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	w := doJSON(engine, http.MethodPost, "/users", `{"name": "owner"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var registered UserRegistrationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+	require.NotEmpty(t, registered.Token)
+
+	t.Run("no_token_401", func(t *testing.T) {
+		w := doJSON(engine, http.MethodGet, "/sensors", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("invalid_token_401", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/sensors", "", "sht_unknown")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("unknown_route_still_404", func(t *testing.T) {
+		w := doJSON(engine, http.MethodGet, "/unknown", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("valid_token_200", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/sensors", "", registered.Token)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("history_reports_caller", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/sensors",
+			`{"serial_number": "0000000001", "type": "adc", "description": "t"}`, registered.Token)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/events",
+			`{"sensor_serial_number": "0000000001", "payload": 1}`, registered.Token)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/sensors/1/history", "", registered.Token)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var history []SensorHistoryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+		require.Len(t, history, 1)
+		assert.Equal(t, "owner", history[0].RequestedByUser)
+	})
+
+	t.Run("create_and_revoke_token", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/tokens", `{"name": "cli", "ttl_seconds": 3600}`, registered.Token)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var created TokenResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		require.NotEmpty(t, created.Token)
+		require.NotNil(t, created.ExpiresAt)
+
+		w = doAuthJSON(engine, http.MethodGet, "/tokens", "", created.Token)
+		require.Equal(t, http.StatusOK, w.Code)
+		var tokens []TokenResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+		require.Len(t, tokens, 2)
+		for _, token := range tokens {
+			assert.Empty(t, token.Token)
+		}
+
+		w = doAuthJSON(engine, http.MethodDelete, "/tokens/2", "", registered.Token)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/tokens", "", created.Token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = doAuthJSON(engine, http.MethodDelete, "/tokens/2", "", registered.Token)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("empty_token_name_422", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/tokens", `{"name": ""}`, registered.Token)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+}
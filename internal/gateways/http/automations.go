@@ -0,0 +1,461 @@
+package http
+
+import (
+	"homework/internal/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseAutomationRunFilter разбирает параметры журнала запусков: status, limit и offset
+func parseAutomationRunFilter(c *gin.Context) (domain.AutomationRunFilter, error) {
+	filter := domain.AutomationRunFilter{Status: domain.AutomationRunStatus(c.Query("status"))}
+	var err error
+	if filter.Limit, filter.Offset, err = parsePagination(c); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+func setupAutomationsRoutes(r *gin.Engine, uc UseCases) {
+	automationsGroup := r.Group("/automations")
+	{
+		automationsGroup.GET("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			automations, err := uc.Automation.GetAutomations(c.Request.Context())
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, automationsToResponse(automations))
+		})
+
+		automationsGroup.HEAD("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			automations, err := uc.Automation.GetAutomations(c.Request.Context())
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			setContentLength(c, automationsToResponse(automations))
+			c.Status(http.StatusOK)
+		})
+
+		automationsGroup.POST("", func(c *gin.Context) {
+			if !checkContentTypeJSON(c) {
+				return
+			}
+
+			var automationReq AutomationRequest
+			if err := c.ShouldBindJSON(&automationReq); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+				return
+			}
+
+			automation, err := uc.Automation.CreateAutomation(c.Request.Context(), automationToDomain(automationReq))
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusCreated, automationToResponse(automation))
+		})
+
+		automationsGroup.OPTIONS("", func(c *gin.Context) {
+			setAllowHeader(c, "GET,HEAD,POST,OPTIONS")
+		})
+
+		setupAutomationByIDRoutes(automationsGroup, uc)
+	}
+}
+
+func setupAutomationByIDRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:automation_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "automation_id", "Invalid automation ID")
+		if !ok {
+			return
+		}
+
+		automation, err := uc.Automation.GetAutomationByID(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, automationToResponse(automation))
+	})
+
+	rg.HEAD("/:automation_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("automation_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		automation, err := uc.Automation.GetAutomationByID(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, automationToResponse(automation))
+		c.Status(http.StatusOK)
+	})
+
+	rg.PUT("/:automation_id", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "automation_id", "Invalid automation ID")
+		if !ok {
+			return
+		}
+
+		var automationReq AutomationRequest
+		if err := c.ShouldBindJSON(&automationReq); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		automation := automationToDomain(automationReq)
+		automation.ID = id
+		result, err := uc.Automation.UpdateAutomation(c.Request.Context(), automation)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, automationToResponse(result))
+	})
+
+	rg.DELETE("/:automation_id", func(c *gin.Context) {
+		id, ok := parseIDParam(c, "automation_id", "Invalid automation ID")
+		if !ok {
+			return
+		}
+
+		if err := uc.Automation.DeleteAutomation(c.Request.Context(), id); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	rg.OPTIONS("/:automation_id", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,PUT,DELETE,OPTIONS")
+	})
+
+	setupAutomationRunsRoutes(rg, uc)
+}
+
+func setupAutomationRunsRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:automation_id/runs", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "automation_id", "Invalid automation ID")
+		if !ok {
+			return
+		}
+
+		filter, err := parseAutomationRunFilter(c)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+		filter.AutomationID = id
+
+		runs, err := uc.Automation.GetAutomationRuns(c.Request.Context(), filter)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, automationRunsToResponse(runs))
+	})
+
+	rg.HEAD("/:automation_id/runs", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("automation_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		filter, err := parseAutomationRunFilter(c)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+		filter.AutomationID = id
+
+		runs, err := uc.Automation.GetAutomationRuns(c.Request.Context(), filter)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, automationRunsToResponse(runs))
+		c.Status(http.StatusOK)
+	})
+
+	rg.OPTIONS("/:automation_id/runs", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,OPTIONS")
+	})
+}
+
+func setupScenesRoutes(r *gin.Engine, uc UseCases) {
+	scenesGroup := r.Group("/scenes")
+	{
+		scenesGroup.GET("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			scenes, err := uc.Automation.GetScenes(c.Request.Context())
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, scenesToResponse(scenes))
+		})
+
+		scenesGroup.HEAD("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			scenes, err := uc.Automation.GetScenes(c.Request.Context())
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			setContentLength(c, scenesToResponse(scenes))
+			c.Status(http.StatusOK)
+		})
+
+		scenesGroup.POST("", func(c *gin.Context) {
+			if !checkContentTypeJSON(c) {
+				return
+			}
+
+			var sceneReq SceneRequest
+			if err := c.ShouldBindJSON(&sceneReq); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+				return
+			}
+
+			scene, err := uc.Automation.CreateScene(c.Request.Context(), sceneToDomain(sceneReq))
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusCreated, sceneToResponse(scene))
+		})
+
+		scenesGroup.OPTIONS("", func(c *gin.Context) {
+			setAllowHeader(c, "GET,HEAD,POST,OPTIONS")
+		})
+
+		setupSceneByIDRoutes(scenesGroup, uc)
+	}
+}
+
+func setupSceneByIDRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:scene_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "scene_id", "Invalid scene ID")
+		if !ok {
+			return
+		}
+
+		scene, err := uc.Automation.GetSceneByID(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, sceneToResponse(scene))
+	})
+
+	rg.HEAD("/:scene_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("scene_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		scene, err := uc.Automation.GetSceneByID(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, sceneToResponse(scene))
+		c.Status(http.StatusOK)
+	})
+
+	rg.PUT("/:scene_id", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "scene_id", "Invalid scene ID")
+		if !ok {
+			return
+		}
+
+		var sceneReq SceneRequest
+		if err := c.ShouldBindJSON(&sceneReq); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		scene := sceneToDomain(sceneReq)
+		scene.ID = id
+		result, err := uc.Automation.UpdateScene(c.Request.Context(), scene)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, sceneToResponse(result))
+	})
+
+	rg.DELETE("/:scene_id", func(c *gin.Context) {
+		id, ok := parseIDParam(c, "scene_id", "Invalid scene ID")
+		if !ok {
+			return
+		}
+
+		if err := uc.Automation.DeleteScene(c.Request.Context(), id); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	rg.OPTIONS("/:scene_id", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,PUT,DELETE,OPTIONS")
+	})
+
+	setupSceneRunsRoutes(rg, uc)
+}
+
+func setupSceneRunsRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:scene_id/runs", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "scene_id", "Invalid scene ID")
+		if !ok {
+			return
+		}
+
+		filter, err := parseAutomationRunFilter(c)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+		filter.SceneID = id
+
+		runs, err := uc.Automation.GetAutomationRuns(c.Request.Context(), filter)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, automationRunsToResponse(runs))
+	})
+
+	rg.HEAD("/:scene_id/runs", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("scene_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		filter, err := parseAutomationRunFilter(c)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+		filter.SceneID = id
+
+		runs, err := uc.Automation.GetAutomationRuns(c.Request.Context(), filter)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, automationRunsToResponse(runs))
+		c.Status(http.StatusOK)
+	})
+
+	rg.OPTIONS("/:scene_id/runs", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,OPTIONS")
+	})
+
+	// сцена выполняется сразу, в ответе - запись журнала с результатом
+	rg.POST("/:scene_id/run", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "scene_id", "Invalid scene ID")
+		if !ok {
+			return
+		}
+
+		run, err := uc.Automation.RunScene(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, automationRunToResponse(run))
+	})
+
+	rg.OPTIONS("/:scene_id/run", func(c *gin.Context) {
+		setAllowHeader(c, "POST,OPTIONS")
+	})
+}
@@ -0,0 +1,210 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutomations(t *testing.T) {
+	uc, _, _ := newInmemoryUseCases(t, withAuth())
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	register := func(name string) string {
+		w := doJSON(engine, http.MethodPost, "/users", `{"name": "`+name+`"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var registered UserRegistrationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+		return registered.Token
+	}
+	owner := register("owner")
+	stranger := register("stranger")
+
+	for _, body := range []string{
+		`{"serial_number": "0000000001", "type": "cc", "description": "door"}`,
+		`{"serial_number": "0000000002", "type": "cc", "description": "controller"}`,
+	} {
+		w := doAuthJSON(engine, http.MethodPost, "/sensors", body, owner)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	w := doAuthJSON(engine, http.MethodPost, "/actuators", `{"sensor_id": 2, "name": "porch light", "type": "relay"}`, owner)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	sendEvent := func(payload string) {
+		w := doAuthJSON(engine, http.MethodPost, "/events", `{"sensor_serial_number": "0000000001", "payload": `+payload+`}`, owner)
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+	getRuns := func(path string) []AutomationRunResponse {
+		w := doAuthJSON(engine, http.MethodGet, path, "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		var runs []AutomationRunResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &runs))
+		return runs
+	}
+
+	var scene SceneResponse
+	t.Run("POST_scenes_201", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/scenes",
+			`{"name": "porch on", "actions": [{"type": "command", "actuator_id": 1, "command": "set_state", "state": 1}]}`, owner)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &scene))
+		assert.Equal(t, []AutomationActionResponse{{Type: "command", ActuatorID: 1, Command: "set_state", State: 1}}, scene.Actions)
+	})
+
+	var automation AutomationResponse
+	t.Run("POST_automations_201", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/automations", `{
+			"name": "door opened",
+			"trigger": {"sensor_id": 1, "operator": "==", "value": 1},
+			"conditions": [{"type": "sensor_state", "sensor_id": 2, "operator": "==", "value": 0}],
+			"actions": [{"type": "alert"}, {"type": "scene", "scene_id": 1}]
+		}`, owner)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &automation))
+		assert.True(t, automation.Enabled)
+		assert.Len(t, automation.Conditions, 1)
+	})
+
+	t.Run("POST_automations_sun_condition_201", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/homes",
+			`{"name": "Дача", "time_zone": "Europe/Moscow", "coordinates": {"latitude": 55.7558, "longitude": 37.6173}}`, owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		w = doAuthJSON(engine, http.MethodPost, "/homes", `{"name": "Квартира"}`, owner)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/automations", `{
+			"name": "lights after sunset",
+			"enabled": false,
+			"trigger": {"sensor_id": 1, "operator": "==", "value": 1},
+			"conditions": [{"type": "sun", "home_id": 1, "sun": {"after": "sunset-30m"}}],
+			"actions": [{"type": "scene", "scene_id": 1}]
+		}`, owner)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var created AutomationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		assert.Equal(t, []AutomationConditionResponse{
+			{Type: "sun", HomeID: 1, Sun: &SunConditionResponse{After: "sunset-30m"}},
+		}, created.Conditions)
+
+		for _, condition := range []string{
+			`{"type": "sun", "home_id": 1, "sun": {"after": "dusk"}}`,
+			`{"type": "sun", "home_id": 1}`,
+			`{"type": "sun", "home_id": 2, "sun": {"after": "sunset"}}`,
+		} {
+			w = doAuthJSON(engine, http.MethodPost, "/automations", `{"name": "a", "trigger": {"sensor_id": 1, "operator": "=="},
+				"conditions": [`+condition+`], "actions": [{"type": "alert"}]}`, owner)
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code, condition)
+		}
+
+		w = doAuthJSON(engine, http.MethodDelete, "/automations/"+strconv.FormatInt(created.ID, 10), "", owner)
+		require.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("POST_automations_invalid_422", func(t *testing.T) {
+		for _, body := range []string{
+			`{"name": "a", "trigger": {"sensor_id": 1, "operator": "~"}, "actions": [{"type": "alert"}]}`,
+			`{"name": "a", "trigger": {"sensor_id": 1, "operator": "=="}, "actions": []}`,
+			`{"name": "a", "trigger": {"sensor_id": 1, "operator": "=="}, "actions": [{"type": "command", "actuator_id": 1, "command": "set_state", "state": 2}]}`,
+			`{"name": "a", "trigger": {"sensor_id": 1, "operator": "=="}, "actions": [{"type": "alert"}],
+				"conditions": [{"type": "time_window", "window": {"from": "25:00", "to": "06:00"}}]}`,
+		} {
+			w := doAuthJSON(engine, http.MethodPost, "/automations", body, owner)
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code, body)
+		}
+
+		w := doAuthJSON(engine, http.MethodPost, "/scenes", `{"name": "s", "actions": [{"type": "alert"}]}`, owner)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("stranger_404", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/automations/1", "", stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/scenes/1/run", "", stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/automations",
+			`{"name": "a", "trigger": {"sensor_id": 1, "operator": "=="}, "actions": [{"type": "alert"}]}`, stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/automations", "", stranger)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+	})
+
+	t.Run("event_runs_automation_once", func(t *testing.T) {
+		sendEvent("1")
+		sendEvent("1")
+
+		runs := getRuns("/automations/1/runs")
+		require.Len(t, runs, 1)
+		assert.Equal(t, "succeeded", runs[0].Status)
+		assert.Equal(t, int64(1), runs[0].SensorID)
+
+		w := doAuthJSON(engine, http.MethodGet, "/actuators/1/commands", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		var commands []CommandResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &commands))
+		require.Len(t, commands, 1)
+		assert.Equal(t, int64(1), commands[0].State)
+
+		w = doAuthJSON(engine, http.MethodGet, "/alerts", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		var alerts []AlertResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &alerts))
+		require.Len(t, alerts, 1)
+		assert.Equal(t, automation.ID, alerts[0].AutomationID)
+		assert.Equal(t, int64(0), alerts[0].RuleID)
+	})
+
+	t.Run("POST_scenes_run_200", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/scenes/1/run", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		var run AutomationRunResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &run))
+		assert.Equal(t, "succeeded", run.Status)
+		assert.Equal(t, scene.ID, run.SceneID)
+
+		assert.Len(t, getRuns("/scenes/1/runs"), 1)
+	})
+
+	t.Run("disabled_automation_doesnt_run", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPut, "/automations/1", `{
+			"name": "door opened",
+			"enabled": false,
+			"trigger": {"sensor_id": 1, "operator": "==", "value": 1},
+			"actions": [{"type": "alert"}]
+		}`, owner)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		sendEvent("0")
+		sendEvent("1")
+		assert.Len(t, getRuns("/automations/1/runs"), 1)
+
+		w = doAuthJSON(engine, http.MethodGet, "/automations/1/runs?status=done", "", owner)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("method_not_allowed", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPatch, "/automations/1", "", owner)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET,HEAD,PUT,DELETE,OPTIONS", w.Header().Get("Allow"))
+	})
+
+	t.Run("DELETE_204", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodDelete, "/automations/1", "", owner)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		w = doAuthJSON(engine, http.MethodDelete, "/scenes/1", "", owner)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/automations/1/runs", "", owner)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
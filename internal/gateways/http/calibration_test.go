@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"homework/internal/domain"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensorCalibrationRoutes(t *testing.T) {
+	engine, sr, _ := newInmemoryTestRouter(t)
+	ctx := context.Background()
+
+	adc := &domain.Sensor{SerialNumber: "0000000001", Type: domain.SensorTypeADC, Description: "termometer", CurrentState: 512}
+	require.NoError(t, sr.SaveSensor(ctx, adc))
+	cc := &domain.Sensor{SerialNumber: "0000000002", Type: domain.SensorTypeContactClosure, Description: "door"}
+	require.NoError(t, sr.SaveSensor(ctx, cc))
+
+	t.Run("GET_calibration_not_set_404", func(t *testing.T) {
+		w := doJSON(engine, http.MethodGet, "/sensors/1/calibration", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("PUT_calibration_cc_sensor_422", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPut, "/sensors/2/calibration", `{"scale": 2}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("PUT_calibration_200", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPut, "/sensors/1/calibration", `{"offset": -10, "scale": 0.1, "unit": "°C"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var sensor SensorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensor))
+		assert.Equal(t, int64(512), sensor.CurrentState)
+		require.NotNil(t, sensor.CurrentValue)
+		assert.InDelta(t, 41.2, *sensor.CurrentValue, 1e-9)
+		assert.Equal(t, "°C", sensor.Unit)
+	})
+
+	t.Run("POST_events_history_has_raw_and_value", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/events", `{"sensor_serial_number": "0000000001", "payload": 300}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		w = doJSON(engine, http.MethodGet, "/sensors/1/history?end_date="+time.Now().Add(time.Minute).Format(time.RFC3339), "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var history []SensorHistoryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+		require.Len(t, history, 1)
+		assert.Equal(t, int64(300), history[0].Payload)
+		require.NotNil(t, history[0].Value)
+		assert.InDelta(t, 20.0, *history[0].Value, 1e-9)
+	})
+
+	t.Run("DELETE_calibration_204", func(t *testing.T) {
+		w := doJSON(engine, http.MethodDelete, "/sensors/1/calibration", "")
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = doJSON(engine, http.MethodGet, "/sensors/1", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var sensor SensorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensor))
+		assert.Nil(t, sensor.CurrentValue)
+		assert.Nil(t, sensor.Calibration)
+	})
+}
@@ -0,0 +1,101 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupSensorDebounceRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:sensor_id/debounce", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		sensor, err := uc.Sensor.GetSensorByID(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+		if sensor.Debounce == nil {
+			c.JSON(http.StatusNotFound, ErrorResponse{Reason: "debounce not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, debounceToResponse(sensor.Debounce))
+	})
+
+	rg.HEAD("/:sensor_id/debounce", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("sensor_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		sensor, err := uc.Sensor.GetSensorByID(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+		if sensor.Debounce == nil {
+			c.Status(http.StatusNotFound)
+			return
+		}
+
+		setContentLength(c, debounceToResponse(sensor.Debounce))
+		c.Status(http.StatusOK)
+	})
+
+	rg.PUT("/:sensor_id/debounce", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		var debounceReq DebounceRequest
+		if err := c.ShouldBindJSON(&debounceReq); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		sensor, err := uc.Sensor.SetSensorDebounce(c.Request.Context(), id, debounceToDomain(&debounceReq))
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, sensorToResponse(sensor))
+	})
+
+	rg.DELETE("/:sensor_id/debounce", func(c *gin.Context) {
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		if _, err := uc.Sensor.SetSensorDebounce(c.Request.Context(), id, nil); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	rg.OPTIONS("/:sensor_id/debounce", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,PUT,DELETE,OPTIONS")
+	})
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"homework/internal/domain"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensorDebounceRoutes(t *testing.T) {
+	engine, sr, _ := newInmemoryTestRouter(t)
+	ctx := context.Background()
+
+	cc := &domain.Sensor{SerialNumber: "0000000001", Type: domain.SensorTypeContactClosure, Description: "door"}
+	require.NoError(t, sr.SaveSensor(ctx, cc))
+	adc := &domain.Sensor{SerialNumber: "0000000002", Type: domain.SensorTypeADC, Description: "termometer"}
+	require.NoError(t, sr.SaveSensor(ctx, adc))
+
+	getSensor := func(t *testing.T) SensorResponse {
+		w := doJSON(engine, http.MethodGet, "/sensors/1", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var sensor SensorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensor))
+		return sensor
+	}
+
+	t.Run("GET_debounce_not_set_404", func(t *testing.T) {
+		w := doJSON(engine, http.MethodGet, "/sensors/1/debounce", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("PUT_debounce_adc_sensor_422", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPut, "/sensors/2/debounce", `{"window_ms": 50}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("PUT_debounce_flap_window_missing_422", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPut, "/sensors/1/debounce", `{"flap_threshold": 2}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("PUT_debounce_200", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPut, "/sensors/1/debounce",
+			`{"window_ms": 60000, "flap_threshold": 2, "flap_window_ms": 60000}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var sensor SensorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensor))
+		require.NotNil(t, sensor.Debounce)
+		assert.Equal(t, DebounceResponse{WindowMs: 60000, FlapThreshold: 2, FlapWindowMs: 60000}, *sensor.Debounce)
+
+		w = doJSON(engine, http.MethodHead, "/sensors/1/debounce", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("POST_events_bounces_are_suppressed_and_sensor_flaps", func(t *testing.T) {
+		for _, payload := range []int{1, 0, 1, 0} {
+			w := doJSON(engine, http.MethodPost, "/events", `{"sensor_serial_number": "0000000001", "payload": `+
+				strconv.Itoa(payload)+`}`)
+			require.Equal(t, http.StatusCreated, w.Code)
+		}
+
+		w := doJSON(engine, http.MethodGet, "/sensors/1/history?end_date="+time.Now().Add(time.Minute).Format(time.RFC3339), "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var history []SensorHistoryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+		assert.Empty(t, history)
+
+		sensor := getSensor(t)
+		assert.True(t, sensor.Flapping)
+		assert.Zero(t, sensor.CurrentState)
+	})
+
+	t.Run("DELETE_debounce_clears_flapping_204", func(t *testing.T) {
+		w := doJSON(engine, http.MethodDelete, "/sensors/1/debounce", "")
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = doJSON(engine, http.MethodGet, "/sensors/1/debounce", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.False(t, getSensor(t).Flapping)
+
+		w = doJSON(engine, http.MethodPost, "/events", `{"sensor_serial_number": "0000000001", "payload": 1}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, int64(1), getSensor(t).CurrentState)
+	})
+}
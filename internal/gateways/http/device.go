@@ -0,0 +1,96 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupSensorInventoryRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/inventory", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		inventory, err := uc.Sensor.GetInventory(c.Request.Context())
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, inventoryToResponse(inventory))
+	})
+
+	rg.HEAD("/inventory", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		inventory, err := uc.Sensor.GetInventory(c.Request.Context())
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, inventoryToResponse(inventory))
+		c.Status(http.StatusOK)
+	})
+
+	rg.OPTIONS("/inventory", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,OPTIONS")
+	})
+}
+
+func setupSensorDeviceRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.POST("/:sensor_id/info", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		var infoReq DeviceInfoRequest
+		if err := c.ShouldBindJSON(&infoReq); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		sensor, err := uc.Sensor.ReportDeviceInfo(c.Request.Context(), id, deviceInfoToDomain(infoReq))
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, sensorToResponse(sensor))
+	})
+
+	rg.OPTIONS("/:sensor_id/info", func(c *gin.Context) {
+		setAllowHeader(c, "POST,OPTIONS")
+	})
+
+	rg.GET("/:sensor_id/firmware", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		history, err := uc.Sensor.GetFirmwareHistory(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, firmwareHistoryToResponse(history))
+	})
+
+	rg.OPTIONS("/:sensor_id/firmware", func(c *gin.Context) {
+		setAllowHeader(c, "GET,OPTIONS")
+	})
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"homework/internal/usecase"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Заголовки подписанного события, подпись считается usecase.SignEvent
+const (
+	headerSensorTimestamp = "X-Sensor-Timestamp"
+	headerSensorNonce     = "X-Sensor-Nonce"
+	headerSensorSignature = "X-Sensor-Signature"
+)
+
+// eventSignatureMiddleware проверяет подпись события до его приема, серийный номер датчика берется из тела события
+func eventSignatureMiddleware(d *usecase.DeviceAuth) gin.HandlerFunc {
+	return deviceSignatureMiddleware(d, func(c *gin.Context, body []byte) (string, bool) {
+		var eventReq SensorEventRequest
+		if err := json.Unmarshal(body, &eventReq); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return "", false
+		}
+		return eventReq.SensorSerialNumber, true
+	})
+}
+
+// deviceCommandsSignatureMiddleware проверяет подпись запроса контроллера к очереди команд,
+// серийный номер датчика берется из пути
+func deviceCommandsSignatureMiddleware(d *usecase.DeviceAuth) gin.HandlerFunc {
+	return deviceSignatureMiddleware(d, func(c *gin.Context, _ []byte) (string, bool) {
+		return c.Param("serial_number"), true
+	})
+}
+
+// deviceSignatureMiddleware проверяет подпись запроса датчика с серийным номером, который возвращает serialNumber.
+// Тело запроса читается целиком и возвращается в запрос для обработчика.
+func deviceSignatureMiddleware(d *usecase.DeviceAuth, serialNumber func(c *gin.Context, body []byte) (string, bool)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timestamp, err := strconv.ParseInt(c.GetHeader(headerSensorTimestamp), 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Reason: "Invalid " + headerSensorTimestamp + " header"})
+			return
+		}
+
+		nonce := c.GetHeader(headerSensorNonce)
+		signature := c.GetHeader(headerSensorSignature)
+		if nonce == "" || signature == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Reason: "Event signature required"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		sn, ok := serialNumber(c, body)
+		if !ok {
+			return
+		}
+
+		err = d.VerifyEvent(c.Request.Context(), sn, timestamp, nonce, body, signature)
+		switch {
+		case errors.Is(err, usecase.ErrInvalidSignature) || errors.Is(err, usecase.ErrReplayedEvent):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Reason: err.Error()})
+		case err != nil:
+			handleError(c, err)
+			c.Abort()
+		default:
+			c.Next()
+		}
+	}
+}
+
+// provisionSensorSecret выпускает секрет только что зарегистрированному датчику.
+// Для уже имевшего секрет датчика возвращает пустую строку: секрет можно получить только ротацией.
+func provisionSensorSecret(c *gin.Context, uc UseCases, sensorID int64) (string, bool) {
+	if uc.DeviceAuth == nil {
+		return "", true
+	}
+
+	secret, err := uc.DeviceAuth.ProvisionSecret(c.Request.Context(), sensorID)
+	if errors.Is(err, usecase.ErrSensorAlreadyProvisioned) {
+		return "", true
+	}
+	if err != nil {
+		handleError(c, err)
+		return "", false
+	}
+
+	return secret, true
+}
+
+func setupSensorSecretRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.POST("/:sensor_id/secret", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		secret, err := uc.DeviceAuth.RotateSecret(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, SensorSecretResponse{SensorID: id, Secret: secret})
+	})
+
+	rg.OPTIONS("/:sensor_id/secret", func(c *gin.Context) {
+		setAllowHeader(c, "POST,OPTIONS")
+	})
+}
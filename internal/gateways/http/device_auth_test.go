@@ -0,0 +1,92 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"homework/internal/usecase"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func doSignedEvent(engine *gin.Engine, body, secret, nonce string, timestamp int64) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add(headerSensorTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Add(headerSensorNonce, nonce)
+	req.Header.Add(headerSensorSignature, usecase.SignEvent(secret, timestamp, nonce, []byte(body)))
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestSignedEvents(t *testing.T) {
+	uc, _, _ := newInmemoryUseCases(t, withDeviceAuth())
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	w := doJSON(engine, http.MethodPost, "/users", `{"name": "owner"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var registered UserRegistrationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+
+	sensorBody := `{"serial_number": "0000000001", "type": "adc", "description": "t"}`
+	w = doAuthJSON(engine, http.MethodPost, "/sensors", sensorBody, registered.Token)
+	require.Equal(t, http.StatusOK, w.Code)
+	var sensor SensorRegistrationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensor))
+	require.NotEmpty(t, sensor.Secret)
+
+	eventBody := `{"sensor_serial_number": "0000000001", "payload": 1}`
+	now := time.Now().Unix()
+
+	t.Run("secret_returned_only_once", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/sensors", sensorBody, registered.Token)
+		require.Equal(t, http.StatusOK, w.Code)
+		var again SensorRegistrationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &again))
+		assert.Empty(t, again.Secret)
+	})
+
+	t.Run("unsigned_401", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/events", eventBody)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("signed_201_and_replay_401", func(t *testing.T) {
+		w := doSignedEvent(engine, eventBody, sensor.Secret, "nonce-1", now)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		w = doSignedEvent(engine, eventBody, sensor.Secret, "nonce-1", now)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("stale_timestamp_401", func(t *testing.T) {
+		w := doSignedEvent(engine, eventBody, sensor.Secret, "nonce-2", now-int64(time.Hour.Seconds()))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("rotation_keeps_old_secret_during_grace", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/sensors/1/secret", "", registered.Token)
+		require.Equal(t, http.StatusOK, w.Code)
+		var rotated SensorSecretResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+		require.NotEqual(t, sensor.Secret, rotated.Secret)
+
+		w = doSignedEvent(engine, eventBody, rotated.Secret, "nonce-3", now)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		w = doSignedEvent(engine, eventBody, sensor.Secret, "nonce-4", now)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		w = doSignedEvent(engine, eventBody, "shs_unknown", "nonce-5", now)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
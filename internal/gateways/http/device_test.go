@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"homework/internal/domain"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensorDeviceRoutes(t *testing.T) {
+	engine, sr, _ := newInmemoryTestRouter(t)
+	ctx := context.Background()
+
+	first := &domain.Sensor{SerialNumber: "0000000001", Type: domain.SensorTypeADC, Description: "termometer"}
+	require.NoError(t, sr.SaveSensor(ctx, first))
+	second := &domain.Sensor{SerialNumber: "0000000002", Type: domain.SensorTypeADC, Description: "termometer"}
+	require.NoError(t, sr.SaveSensor(ctx, second))
+
+	t.Run("POST_sensors_sensor_id_info_200", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/sensors/1/info",
+			`{"manufacturer": "Acme", "model": "T-100", "hardware_revision": "B", "firmware_version": "1.0.0"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var sensor SensorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensor))
+		assert.Equal(t, "T-100", sensor.Model)
+		assert.Equal(t, "1.0.0", sensor.FirmwareVersion)
+	})
+
+	t.Run("POST_sensors_sensor_id_info_empty_422", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/sensors/1/info", `{}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("POST_sensors_sensor_id_info_unknown_sensor_404", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/sensors/42/info", `{"firmware_version": "1.0.0"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("POST_events_with_device_updates_firmware", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/events",
+			`{"sensor_serial_number": "0000000001", "payload": 10, "device": {"firmware_version": "1.1.0"}}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		w = doJSON(engine, http.MethodGet, "/sensors/1/firmware", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var history []FirmwareChangeResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+		require.Len(t, history, 2)
+		assert.Equal(t, "", history[0].PreviousVersion)
+		assert.Equal(t, "1.0.0", history[1].PreviousVersion)
+		assert.Equal(t, "1.1.0", history[1].Version)
+	})
+
+	t.Run("GET_sensors_inventory_200", func(t *testing.T) {
+		w := doJSON(engine, http.MethodGet, "/sensors/inventory", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var inventory []InventoryGroupResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &inventory))
+		require.Len(t, inventory, 2)
+		assert.Equal(t, []int64{second.ID}, inventory[0].SensorIDs)
+		assert.Equal(t, "Acme", inventory[1].Manufacturer)
+		assert.Equal(t, "1.1.0", inventory[1].FirmwareVersion)
+		assert.Equal(t, 1, inventory[1].Count)
+	})
+
+	t.Run("DELETE_sensors_inventory_405", func(t *testing.T) {
+		w := doJSON(engine, http.MethodDelete, "/sensors/inventory", "")
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET,HEAD,OPTIONS", w.Header().Get("Allow"))
+	})
+}
@@ -0,0 +1,330 @@
+package http
+
+import (
+	"homework/internal/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupGroupsRoutes(r *gin.Engine, uc UseCases) {
+	groupsGroup := r.Group("/groups")
+	{
+		groupsGroup.GET("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			groups, err := uc.Group.GetGroups(c.Request.Context())
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, groupsToResponse(groups))
+		})
+
+		groupsGroup.HEAD("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			groups, err := uc.Group.GetGroups(c.Request.Context())
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			setContentLength(c, groupsToResponse(groups))
+			c.Status(http.StatusOK)
+		})
+
+		groupsGroup.POST("", func(c *gin.Context) {
+			if !checkContentTypeJSON(c) {
+				return
+			}
+
+			var groupReq GroupRequest
+			if err := c.ShouldBindJSON(&groupReq); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+				return
+			}
+
+			result, err := uc.Group.CreateGroup(c.Request.Context(), groupToDomain(groupReq))
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, groupToResponse(result))
+		})
+
+		groupsGroup.OPTIONS("", func(c *gin.Context) {
+			setAllowHeader(c, "GET,HEAD,POST,OPTIONS")
+		})
+
+		setupGroupByIDRoutes(groupsGroup, uc)
+		setupGroupMembersRoutes(groupsGroup, uc)
+		setupGroupSensorsRoutes(groupsGroup, uc)
+	}
+}
+
+func setupGroupByIDRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:group_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "group_id", "Invalid group ID")
+		if !ok {
+			return
+		}
+
+		group, err := uc.Group.GetGroupByID(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, groupToResponse(group))
+	})
+
+	rg.HEAD("/:group_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("group_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		group, err := uc.Group.GetGroupByID(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, groupToResponse(group))
+		c.Status(http.StatusOK)
+	})
+
+	rg.DELETE("/:group_id", func(c *gin.Context) {
+		id, ok := parseIDParam(c, "group_id", "Invalid group ID")
+		if !ok {
+			return
+		}
+
+		if err := uc.Group.DeleteGroup(c.Request.Context(), id); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	rg.OPTIONS("/:group_id", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,DELETE,OPTIONS")
+	})
+}
+
+func setupGroupMembersRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:group_id/members", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "group_id", "Invalid group ID")
+		if !ok {
+			return
+		}
+
+		members, err := uc.Group.GetGroupMembers(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, groupMembersToResponse(members))
+	})
+
+	rg.HEAD("/:group_id/members", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("group_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		members, err := uc.Group.GetGroupMembers(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, groupMembersToResponse(members))
+		c.Status(http.StatusOK)
+	})
+
+	rg.POST("/:group_id/members", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "group_id", "Invalid group ID")
+		if !ok {
+			return
+		}
+
+		var memberReq GroupMemberRequest
+		if err := c.ShouldBindJSON(&memberReq); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		if memberReq.UserID <= 0 {
+			c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Reason: "Invalid user ID"})
+			return
+		}
+
+		if err := uc.Group.AddGroupMember(c.Request.Context(), id, memberReq.UserID); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusCreated)
+	})
+
+	rg.OPTIONS("/:group_id/members", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,POST,OPTIONS")
+	})
+
+	rg.DELETE("/:group_id/members/:user_id", func(c *gin.Context) {
+		groupID, ok := parseIDParam(c, "group_id", "Invalid group ID")
+		if !ok {
+			return
+		}
+		userID, ok := parseIDParam(c, "user_id", "Invalid user ID")
+		if !ok {
+			return
+		}
+
+		if err := uc.Group.RemoveGroupMember(c.Request.Context(), groupID, userID); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	rg.OPTIONS("/:group_id/members/:user_id", func(c *gin.Context) {
+		setAllowHeader(c, "DELETE,OPTIONS")
+	})
+}
+
+func setupGroupSensorsRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:group_id/sensors", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "group_id", "Invalid group ID")
+		if !ok {
+			return
+		}
+
+		groupSensors, err := uc.Group.GetGroupSensors(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, groupSensorsToResponse(groupSensors))
+	})
+
+	rg.HEAD("/:group_id/sensors", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("group_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		groupSensors, err := uc.Group.GetGroupSensors(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, groupSensorsToResponse(groupSensors))
+		c.Status(http.StatusOK)
+	})
+
+	rg.POST("/:group_id/sensors", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "group_id", "Invalid group ID")
+		if !ok {
+			return
+		}
+
+		var binding GroupSensorRequest
+		if err := c.ShouldBindJSON(&binding); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		if binding.SensorID <= 0 {
+			c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Reason: "Invalid sensor ID"})
+			return
+		}
+
+		role := domain.SensorRoleOperator
+		if binding.Role != "" {
+			role = domain.SensorRole(binding.Role)
+		}
+
+		if err := uc.Group.AttachSensorToGroup(c.Request.Context(), id, binding.SensorID, role); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusCreated)
+	})
+
+	rg.OPTIONS("/:group_id/sensors", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,POST,OPTIONS")
+	})
+
+	rg.DELETE("/:group_id/sensors/:sensor_id", func(c *gin.Context) {
+		groupID, ok := parseIDParam(c, "group_id", "Invalid group ID")
+		if !ok {
+			return
+		}
+		sensorID, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		if err := uc.Group.DetachSensorFromGroup(c.Request.Context(), groupID, sensorID); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	rg.OPTIONS("/:group_id/sensors/:sensor_id", func(c *gin.Context) {
+		setAllowHeader(c, "DELETE,OPTIONS")
+	})
+}
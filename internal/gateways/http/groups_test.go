@@ -0,0 +1,134 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroups(t *testing.T) {
+	uc, _, _ := newInmemoryUseCases(t, withAuth())
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	register := func(name string) string {
+		w := doJSON(engine, http.MethodPost, "/users", `{"name": "`+name+`"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var registered UserRegistrationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+		return registered.Token
+	}
+	owner := register("owner")
+	member := register("member")
+	stranger := register("stranger")
+
+	w := doAuthJSON(engine, http.MethodPost, "/sensors",
+		`{"serial_number": "0000000001", "type": "adc", "description": "t"}`, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = doAuthJSON(engine, http.MethodGet, "/sensors/1", "", member)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doAuthJSON(engine, http.MethodPost, "/groups", `{"name": " Семья "}`, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id": 1, "name": "Семья"}`, w.Body.String())
+
+	w = doAuthJSON(engine, http.MethodPost, "/groups/1/members", `{"user_id": 2}`, owner)
+	require.Equal(t, http.StatusCreated, w.Code)
+	w = doAuthJSON(engine, http.MethodPost, "/groups/1/sensors", `{"sensor_id": 1, "role": "viewer"}`, owner)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	t.Run("member_sees_group_sensors", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/users/2/sensors", "", member)
+		require.Equal(t, http.StatusOK, w.Code)
+		var sensors []SensorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensors))
+		require.Len(t, sensors, 1)
+		assert.Equal(t, int64(1), sensors[0].ID)
+
+		w = doAuthJSON(engine, http.MethodGet, "/sensors/1", "", member)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPut, "/sensors/1/calibration", `{"scale": 2}`, member)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = doAuthJSON(engine, http.MethodDelete, "/groups/1/sensors/1", "", member)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("members_and_sensors_listed", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/groups/1/members", "", member)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[{"group_id": 1, "user_id": 1}, {"group_id": 1, "user_id": 2}]`, w.Body.String())
+
+		w = doAuthJSON(engine, http.MethodGet, "/groups/1/sensors", "", member)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[{"group_id": 1, "sensor_id": 1, "role": "viewer"}]`, w.Body.String())
+	})
+
+	t.Run("stranger_gets_404", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/groups", "", stranger)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+
+		w = doAuthJSON(engine, http.MethodGet, "/groups/1", "", stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/groups/1/members", `{"user_id": 3}`, stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("removal_closes_live_subscription", func(t *testing.T) {
+		srv := httptest.NewServer(engine)
+		defer srv.Close()
+
+		srvURL, _ := url.Parse(srv.URL)
+		srvURL.Scheme = "ws"
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		conn, _, err := websocket.Dial(ctx, srvURL.String()+"/sensors/1/events", &websocket.DialOptions{
+			HTTPHeader: http.Header{"Authorization": {"Bearer " + member}},
+		})
+		require.NoError(t, err)
+		defer conn.CloseNow() //nolint: errcheck // test cleanup
+
+		w := doAuthJSON(engine, http.MethodDelete, "/groups/1/members/2", "", owner)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		_, _, err = conn.Read(ctx)
+		require.Error(t, err)
+		assert.NoError(t, ctx.Err(), "subscription must be closed before the timeout")
+
+		w = doAuthJSON(engine, http.MethodGet, "/sensors/1", "", member)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("allow_header_on_405", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPatch, "/groups/1", "", owner)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET,HEAD,DELETE,OPTIONS", w.Header().Get("Allow"))
+
+		w = doAuthJSON(engine, http.MethodGet, "/groups/1/members/2", "", owner)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "DELETE,OPTIONS", w.Header().Get("Allow"))
+	})
+
+	t.Run("delete_group_204", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodDelete, "/groups/1", "", owner)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/groups/1", "", owner)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,244 @@
+package http
+
+import (
+	"bytes"
+	"homework/internal/serial"
+	"homework/internal/usecase"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	actuatorInmemory "homework/internal/repository/actuator/inmemory"
+	alertInmemory "homework/internal/repository/alert/inmemory"
+	auditInmemory "homework/internal/repository/audit/inmemory"
+	automationInmemory "homework/internal/repository/automation/inmemory"
+	eventInmemory "homework/internal/repository/event/inmemory"
+	groupInmemory "homework/internal/repository/group/inmemory"
+	homeInmemory "homework/internal/repository/home/inmemory"
+	outboxInmemory "homework/internal/repository/outbox/inmemory"
+	ruleInmemory "homework/internal/repository/rule/inmemory"
+	sensorInmemory "homework/internal/repository/sensor/inmemory"
+	transactionInmemory "homework/internal/repository/transaction/inmemory"
+	userInmemory "homework/internal/repository/user/inmemory"
+	webhookInmemory "homework/internal/repository/webhook/inmemory"
+)
+
+// inmemoryApp - usecase, собранные так же, как в cmd/server/main.go, но на репозиториях в памяти
+type inmemoryApp struct {
+	uc      UseCases
+	sensors *sensorInmemory.SensorRepository
+	users   *userInmemory.UserRepository
+	outbox  *usecase.Outbox
+}
+
+type inmemoryOptions struct {
+	auth       bool
+	deviceAuth bool
+}
+
+// withAuth включает аутентификацию пользователей по токенам
+func withAuth() func(*inmemoryOptions) {
+	return func(o *inmemoryOptions) {
+		o.auth = true
+	}
+}
+
+// withDeviceAuth включает аутентификацию пользователей и подпись запросов датчиков
+func withDeviceAuth() func(*inmemoryOptions) {
+	return func(o *inmemoryOptions) {
+		o.auth = true
+		o.deviceAuth = true
+	}
+}
+
+func newInmemoryTestRouter(t *testing.T) (*gin.Engine, *sensorInmemory.SensorRepository, *userInmemory.UserRepository) {
+	t.Helper()
+
+	uc, sr, ur := newInmemoryUseCases(t)
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	return engine, sr, ur
+}
+
+func newInmemoryUseCases(t *testing.T, options ...func(*inmemoryOptions)) (UseCases, *sensorInmemory.SensorRepository, *userInmemory.UserRepository) {
+	t.Helper()
+
+	app := newInmemoryApp(t, options...)
+	return app.uc, app.sensors, app.users
+}
+
+// newInmemoryApp собирает usecase без аутентификации, если она не включена options
+func newInmemoryApp(t *testing.T, options ...func(*inmemoryOptions)) *inmemoryApp {
+	t.Helper()
+
+	var opts inmemoryOptions
+	for _, o := range options {
+		o(&opts)
+	}
+
+	er := eventInmemory.NewEventRepository()
+	sr := sensorInmemory.NewSensorRepository()
+	fr := sensorInmemory.NewFirmwareHistoryRepository()
+	ur := userInmemory.NewUserRepository()
+	tr := userInmemory.NewAPITokenRepository()
+	sor := userInmemory.NewSensorOwnerRepository()
+	hr := homeInmemory.NewHomeRepository()
+	rr := homeInmemory.NewRoomRepository()
+	hor := homeInmemory.NewHomeOwnerRepository()
+	gr := groupInmemory.NewGroupRepository()
+	policy := usecase.NewAccessPolicy(sor, hor, rr, usecase.WithGroupAccess(gr))
+	transactor := transactionInmemory.NewTransactor()
+	audit := usecase.NewAudit(auditInmemory.NewAuditRepository(), usecase.WithAuditAccessPolicy(policy))
+	webhooks := usecase.NewWebhook(webhookInmemory.NewWebhookRepository(), sr, ur,
+		usecase.WithWebhookAccessPolicy(policy),
+		usecase.WithWebhookAudit(audit),
+		usecase.WithWebhookPrivateNetworks(),
+	)
+	outbox := usecase.NewOutbox(outboxInmemory.NewOutboxRepository(), usecase.WithOutboxPublisher(webhooks))
+	alerts := usecase.NewAlert(alertInmemory.NewAlertRepository(),
+		usecase.WithAlertAccessPolicy(policy),
+		usecase.WithAlertTransactor(transactor),
+		usecase.WithAlertAudit(audit),
+		usecase.WithAlertWebhooks(webhooks),
+		usecase.WithAlertFlappingSuppression(sr),
+	)
+	debounce := usecase.NewDebounce()
+	vsr := sensorInmemory.NewVirtualSensorRepository()
+	anomalies := usecase.NewAnomaly(sensorInmemory.NewAnomalyStateRepository(), alerts)
+	rules := usecase.NewRule(ruleInmemory.NewRuleRepository(), sr, alerts,
+		usecase.WithRuleAccessPolicy(policy),
+		usecase.WithRuleTransactor(transactor),
+		usecase.WithRuleAudit(audit),
+	)
+	ar := actuatorInmemory.NewActuatorRepository()
+	actuators := usecase.NewActuator(ar, ar, sr,
+		usecase.WithActuatorAccessPolicy(policy),
+		usecase.WithActuatorTransactor(transactor),
+		usecase.WithActuatorAudit(audit),
+	)
+	atr := automationInmemory.NewAutomationRepository()
+	automations := usecase.NewAutomation(atr, sr, ur,
+		usecase.WithAutomationAccessPolicy(policy),
+		usecase.WithAutomationHomes(hr),
+		usecase.WithAutomationActuators(actuators),
+		usecase.WithAutomationWebhooks(webhooks),
+		usecase.WithAutomationAlerts(alerts),
+		usecase.WithAutomationAudit(audit),
+	)
+	scheduler := usecase.NewScheduler(atr, hr, automations,
+		usecase.WithSchedulerAccessPolicy(policy),
+		usecase.WithSchedulerAudit(audit),
+	)
+
+	// как и в main.go без SERIAL_SCHEMES: numeric10 и номера производителя "AX" с Luhn mod 36
+	serials := serial.Default()
+	schemes, err := serial.ParseSchemes("AX=luhn36:10")
+	require.NoError(t, err)
+	for _, scheme := range schemes {
+		require.NoError(t, serials.Register(scheme))
+	}
+
+	deviceAuth := usecase.NewDeviceAuth(
+		sensorInmemory.NewSensorCredentialRepository(),
+		sensorInmemory.NewEventNonceRepository(),
+		sr,
+		usecase.WithDeviceAccessPolicy(policy),
+		usecase.WithDeviceAudit(audit),
+	)
+	sensorOptions := []func(*usecase.Sensor){
+		usecase.WithSensorSerialValidator(serials),
+		usecase.WithSensorOwners(ur, sor),
+		usecase.WithSensorTransactor(transactor),
+		usecase.WithFirmwareHistory(fr),
+		usecase.WithSensorAccessPolicy(policy),
+		usecase.WithSensorAudit(audit),
+		usecase.WithSensorAnomalies(anomalies),
+		usecase.WithSensorDebounce(debounce),
+		usecase.WithVirtualSensors(vsr),
+	}
+	if opts.deviceAuth {
+		sensorOptions = append(sensorOptions, usecase.WithSensorDeviceAuth(deviceAuth))
+	}
+
+	uc := UseCases{
+		Event: usecase.NewEvent(er, sr,
+			usecase.WithEventFirmwareHistory(fr),
+			usecase.WithEventAccessPolicy(policy),
+			usecase.WithEventDebounce(debounce),
+			usecase.WithEventVirtualSensors(vsr),
+			usecase.WithEventAnomalies(anomalies),
+			usecase.WithEventRules(rules),
+			usecase.WithEventTransactor(transactor),
+			usecase.WithEventOutbox(outbox),
+			usecase.WithEventAutomations(automations),
+		),
+		Sensor: usecase.NewSensor(sr, sensorOptions...),
+		User: usecase.NewUser(ur, sor, sr,
+			usecase.WithHomeAccess(hor, rr),
+			usecase.WithUserGroups(gr),
+			usecase.WithUserTokens(tr),
+			usecase.WithUserAccessPolicy(policy),
+			usecase.WithUserTransactor(transactor),
+			usecase.WithUserAudit(audit),
+		),
+		Home: usecase.NewHome(hr, rr, hor, sr, ur,
+			usecase.WithHomeAccessPolicy(policy),
+			usecase.WithHomeAudit(audit),
+			usecase.WithHomeTransactor(transactor),
+		),
+		Group: usecase.NewGroup(gr, ur, sr,
+			usecase.WithGroupAccessPolicy(policy),
+			usecase.WithGroupAudit(audit),
+		),
+		Invitation: usecase.NewInvitation(sensorInmemory.NewSensorInvitationRepository(), sor, sr,
+			usecase.WithInvitationAccessPolicy(policy),
+			usecase.WithInvitationTransactor(transactor),
+			usecase.WithInvitationAudit(audit),
+		),
+		Rule:       rules,
+		Alert:      alerts,
+		Webhook:    webhooks,
+		Actuator:   actuators,
+		Automation: automations,
+		Scheduler:  scheduler,
+		Audit:      audit,
+	}
+	if opts.auth {
+		uc.Auth = usecase.NewAuth(tr, ur, usecase.WithAuthAudit(audit))
+	}
+	if opts.deviceAuth {
+		uc.DeviceAuth = deviceAuth
+	}
+
+	return &inmemoryApp{uc: uc, sensors: sr, users: ur, outbox: outbox}
+}
+
+func doJSON(engine *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewReader([]byte(body)))
+	if body != "" {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	req.Header.Add("Accept", "application/json")
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func doAuthJSON(engine *gin.Engine, method, path, body, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewReader([]byte(body)))
+	if body != "" {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	req.Header.Add("Accept", "application/json")
+	if token != "" {
+		req.Header.Add("Authorization", "Bearer "+token)
+	}
+	engine.ServeHTTP(w, req)
+	return w
+}
@@ -0,0 +1,517 @@
+package http
+
+import (
+	"homework/internal/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupHomesRoutes(r *gin.Engine, uc UseCases) {
+	homesGroup := r.Group("/homes")
+	{
+		homesGroup.GET("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			homes, err := uc.Home.GetHomes(c.Request.Context())
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, homesToResponse(homes))
+		})
+
+		homesGroup.HEAD("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			homes, err := uc.Home.GetHomes(c.Request.Context())
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			setContentLength(c, homesToResponse(homes))
+			c.Status(http.StatusOK)
+		})
+
+		homesGroup.POST("", func(c *gin.Context) {
+			if !checkContentTypeJSON(c) {
+				return
+			}
+
+			var homeReq HomeRequest
+			if err := c.ShouldBindJSON(&homeReq); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+				return
+			}
+
+			result, err := uc.Home.CreateHome(c.Request.Context(), homeToDomain(homeReq))
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, homeToResponse(result))
+		})
+
+		homesGroup.OPTIONS("", func(c *gin.Context) {
+			setAllowHeader(c, "GET,HEAD,POST,OPTIONS")
+		})
+
+		setupHomeByIDRoutes(homesGroup, uc)
+	}
+}
+
+func setupHomeByIDRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:home_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "home_id", "Invalid home ID")
+		if !ok {
+			return
+		}
+
+		home, err := uc.Home.GetHomeByID(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, homeToResponse(home))
+	})
+
+	rg.HEAD("/:home_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("home_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		home, err := uc.Home.GetHomeByID(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, homeToResponse(home))
+		c.Status(http.StatusOK)
+	})
+
+	rg.PATCH("/:home_id", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "home_id", "Invalid home ID")
+		if !ok {
+			return
+		}
+
+		var homeReq HomeRequest
+		if err := c.ShouldBindJSON(&homeReq); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		home := homeToDomain(homeReq)
+		home.ID = id
+		result, err := uc.Home.UpdateHome(c.Request.Context(), home)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, homeToResponse(result))
+	})
+
+	rg.DELETE("/:home_id", func(c *gin.Context) {
+		id, ok := parseIDParam(c, "home_id", "Invalid home ID")
+		if !ok {
+			return
+		}
+
+		if err := uc.Home.DeleteHome(c.Request.Context(), id); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	rg.OPTIONS("/:home_id", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,PATCH,DELETE,OPTIONS")
+	})
+
+	rg.GET("/:home_id/rooms", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "home_id", "Invalid home ID")
+		if !ok {
+			return
+		}
+
+		rooms, err := uc.Home.GetHomeRooms(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, roomsToResponse(rooms))
+	})
+
+	rg.HEAD("/:home_id/rooms", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("home_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		rooms, err := uc.Home.GetHomeRooms(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, roomsToResponse(rooms))
+		c.Status(http.StatusOK)
+	})
+
+	rg.POST("/:home_id/rooms", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "home_id", "Invalid home ID")
+		if !ok {
+			return
+		}
+
+		var roomReq RoomRequest
+		if err := c.ShouldBindJSON(&roomReq); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		room := roomToDomain(roomReq)
+		room.HomeID = id
+		result, err := uc.Home.CreateRoom(c.Request.Context(), room)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, roomToResponse(result))
+	})
+
+	rg.OPTIONS("/:home_id/rooms", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,POST,OPTIONS")
+	})
+
+	rg.GET("/:home_id/sensors", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "home_id", "Invalid home ID")
+		if !ok {
+			return
+		}
+
+		sensors, err := uc.Home.GetHomeSensors(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, sensorsToResponse(sensors))
+	})
+
+	rg.HEAD("/:home_id/sensors", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("home_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		sensors, err := uc.Home.GetHomeSensors(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, sensorsToResponse(sensors))
+		c.Status(http.StatusOK)
+	})
+
+	rg.OPTIONS("/:home_id/sensors", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,OPTIONS")
+	})
+}
+
+func setupRoomsRoutes(r *gin.Engine, uc UseCases) {
+	roomsGroup := r.Group("/rooms")
+	{
+		roomsGroup.GET("/:room_id", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			id, ok := parseIDParam(c, "room_id", "Invalid room ID")
+			if !ok {
+				return
+			}
+
+			room, err := uc.Home.GetRoomByID(c.Request.Context(), id)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, roomToResponse(room))
+		})
+
+		roomsGroup.HEAD("/:room_id", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			id, err := strconv.ParseInt(c.Param("room_id"), 10, 64)
+			if err != nil {
+				c.Status(http.StatusUnprocessableEntity)
+				return
+			}
+
+			room, err := uc.Home.GetRoomByID(c.Request.Context(), id)
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			setContentLength(c, roomToResponse(room))
+			c.Status(http.StatusOK)
+		})
+
+		roomsGroup.PATCH("/:room_id", func(c *gin.Context) {
+			if !checkContentTypeJSON(c) {
+				return
+			}
+
+			id, ok := parseIDParam(c, "room_id", "Invalid room ID")
+			if !ok {
+				return
+			}
+
+			var roomReq RoomRequest
+			if err := c.ShouldBindJSON(&roomReq); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+				return
+			}
+
+			room := roomToDomain(roomReq)
+			room.ID = id
+			result, err := uc.Home.UpdateRoom(c.Request.Context(), room)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, roomToResponse(result))
+		})
+
+		roomsGroup.DELETE("/:room_id", func(c *gin.Context) {
+			id, ok := parseIDParam(c, "room_id", "Invalid room ID")
+			if !ok {
+				return
+			}
+
+			if err := uc.Home.DeleteRoom(c.Request.Context(), id); err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.Status(http.StatusNoContent)
+		})
+
+		roomsGroup.OPTIONS("/:room_id", func(c *gin.Context) {
+			setAllowHeader(c, "GET,HEAD,PATCH,DELETE,OPTIONS")
+		})
+
+		roomsGroup.GET("/:room_id/sensors", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			id, ok := parseIDParam(c, "room_id", "Invalid room ID")
+			if !ok {
+				return
+			}
+
+			sensors, err := uc.Home.GetRoomSensors(c.Request.Context(), id)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, sensorsToResponse(sensors))
+		})
+
+		roomsGroup.HEAD("/:room_id/sensors", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			id, err := strconv.ParseInt(c.Param("room_id"), 10, 64)
+			if err != nil {
+				c.Status(http.StatusUnprocessableEntity)
+				return
+			}
+
+			sensors, err := uc.Home.GetRoomSensors(c.Request.Context(), id)
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			setContentLength(c, sensorsToResponse(sensors))
+			c.Status(http.StatusOK)
+		})
+
+		roomsGroup.POST("/:room_id/sensors", func(c *gin.Context) {
+			if !checkContentTypeJSON(c) {
+				return
+			}
+
+			id, ok := parseIDParam(c, "room_id", "Invalid room ID")
+			if !ok {
+				return
+			}
+
+			var binding SensorBindingRequest
+			if err := c.ShouldBindJSON(&binding); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+				return
+			}
+
+			if binding.SensorID <= 0 {
+				c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Reason: "Invalid sensor ID"})
+				return
+			}
+
+			if err := uc.Home.AssignSensorToRoom(c.Request.Context(), id, binding.SensorID); err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.Status(http.StatusCreated)
+		})
+
+		roomsGroup.OPTIONS("/:room_id/sensors", func(c *gin.Context) {
+			setAllowHeader(c, "GET,HEAD,POST,OPTIONS")
+		})
+	}
+}
+
+func setupUserHomesRoutes(rg *gin.RouterGroup, uc UseCases) {
+	userHomesGroup := rg.Group("/:user_id/homes")
+	{
+		userHomesGroup.GET("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			id, ok := parseIDParam(c, "user_id", "Invalid user ID")
+			if !ok {
+				return
+			}
+
+			homes, err := uc.Home.GetUserHomes(c.Request.Context(), id)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, homesToResponse(homes))
+		})
+
+		userHomesGroup.HEAD("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			id, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+			if err != nil {
+				c.Status(http.StatusUnprocessableEntity)
+				return
+			}
+
+			homes, err := uc.Home.GetUserHomes(c.Request.Context(), id)
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			setContentLength(c, homesToResponse(homes))
+			c.Status(http.StatusOK)
+		})
+
+		userHomesGroup.POST("", func(c *gin.Context) {
+			if !checkContentTypeJSON(c) {
+				return
+			}
+
+			id, ok := parseIDParam(c, "user_id", "Invalid user ID")
+			if !ok {
+				return
+			}
+
+			var binding HomeBindingRequest
+			if err := c.ShouldBindJSON(&binding); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+				return
+			}
+
+			if binding.HomeID <= 0 {
+				c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Reason: "Invalid home ID"})
+				return
+			}
+
+			role := domain.HomeRoleMember
+			if binding.Role != "" {
+				role = domain.HomeRole(binding.Role)
+			}
+
+			if err := uc.Home.AttachHomeToUser(c.Request.Context(), id, binding.HomeID, role); err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.Status(http.StatusCreated)
+		})
+
+		userHomesGroup.OPTIONS("", func(c *gin.Context) {
+			setAllowHeader(c, "GET,HEAD,POST,OPTIONS")
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"homework/internal/domain"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHomesRoutes(t *testing.T) {
+	engine, sr, ur := newInmemoryTestRouter(t)
+	ctx := context.Background()
+
+	sensor := &domain.Sensor{SerialNumber: "0000000001", Type: domain.SensorTypeADC, Description: "termometer"}
+	require.NoError(t, sr.SaveSensor(ctx, sensor))
+	user := &domain.User{Name: "owner"}
+	require.NoError(t, ur.SaveUser(ctx, user))
+
+	t.Run("POST_homes_empty_name_422", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/homes", `{"name": "  "}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("POST_homes_invalid_location_422", func(t *testing.T) {
+		for _, body := range []string{
+			`{"name": "Дача", "time_zone": "Mars/Olympus"}`,
+			`{"name": "Дача", "coordinates": {"latitude": 91, "longitude": 0}}`,
+		} {
+			w := doJSON(engine, http.MethodPost, "/homes", body)
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code, body)
+		}
+	})
+
+	w := doJSON(engine, http.MethodPost, "/homes", `{"name": "Дача", "address": "Лесная, 1"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var home HomeResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &home))
+	assert.Equal(t, "Дача", home.Name)
+
+	w = doJSON(engine, http.MethodPost, "/homes/1/rooms", `{"name": "Кухня"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var room RoomResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &room))
+	assert.Equal(t, home.ID, room.HomeID)
+
+	t.Run("POST_rooms_room_id_sensors_201", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/rooms/1/sensors", `{"sensor_id": 1}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("POST_rooms_room_id_sensors_unknown_sensor_404", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/rooms/1/sensors", `{"sensor_id": 42}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("GET_homes_home_id_sensors_200", func(t *testing.T) {
+		w := doJSON(engine, http.MethodGet, "/homes/1/sensors", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var sensors []SensorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensors))
+		require.Len(t, sensors, 1)
+		assert.Equal(t, sensor.ID, sensors[0].ID)
+	})
+
+	t.Run("GET_rooms_room_id_sensors_unknown_room_404", func(t *testing.T) {
+		w := doJSON(engine, http.MethodGet, "/rooms/2/sensors", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("home_grants_sensors_to_user", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/users/1/homes", `{"home_id": 1}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		w = doJSON(engine, http.MethodGet, "/users/1/sensors", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var sensors []SensorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensors))
+		require.Len(t, sensors, 1)
+		assert.Equal(t, sensor.ID, sensors[0].ID)
+	})
+
+	t.Run("PATCH_rooms_room_id_200", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPatch, "/rooms/1", `{"name": "Гостиная"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Гостиная")
+	})
+
+	t.Run("PUT_homes_home_id_405", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPut, "/homes/1", `{}`)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.ElementsMatch(t,
+			[]string{"GET", "HEAD", "PATCH", "DELETE", "OPTIONS"},
+			strings.Split(w.Header().Get("Allow"), ","))
+	})
+
+	t.Run("DELETE_homes_home_id_204", func(t *testing.T) {
+		w := doJSON(engine, http.MethodDelete, "/homes/1", "")
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = doJSON(engine, http.MethodGet, "/rooms/1", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doJSON(engine, http.MethodGet, "/users/1/homes", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, "[]", w.Body.String())
+	})
+}
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"homework/internal/domain"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupSensorInvitationsRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:sensor_id/invitations", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		invitations, err := uc.Invitation.GetSensorInvitations(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, invitationsToResponse(invitations))
+	})
+
+	rg.HEAD("/:sensor_id/invitations", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("sensor_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		invitations, err := uc.Invitation.GetSensorInvitations(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, invitationsToResponse(invitations))
+		c.Status(http.StatusOK)
+	})
+
+	rg.POST("/:sensor_id/invitations", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		var invitationReq InvitationCreateRequest
+		if err := c.ShouldBindJSON(&invitationReq); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		role := domain.SensorRoleViewer
+		if invitationReq.Role != "" {
+			role = domain.SensorRole(invitationReq.Role)
+		}
+
+		invitation, plain, err := uc.Invitation.CreateInvitation(c.Request.Context(), id, role,
+			time.Duration(invitationReq.TTLSeconds)*time.Second, invitationReq.MaxUses)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		response := invitationToResponse(invitation)
+		response.Token = plain
+		c.JSON(http.StatusCreated, response)
+	})
+
+	rg.OPTIONS("/:sensor_id/invitations", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,POST,OPTIONS")
+	})
+
+	rg.DELETE("/:sensor_id/invitations/:invitation_id", func(c *gin.Context) {
+		sensorID, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+		invitationID, ok := parseIDParam(c, "invitation_id", "Invalid invitation ID")
+		if !ok {
+			return
+		}
+
+		if err := uc.Invitation.RevokeInvitation(c.Request.Context(), sensorID, invitationID); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	rg.OPTIONS("/:sensor_id/invitations/:invitation_id", func(c *gin.Context) {
+		setAllowHeader(c, "DELETE,OPTIONS")
+	})
+}
+
+func setupInvitationsRoutes(r *gin.Engine, uc UseCases) {
+	invitationsGroup := r.Group("/invitations")
+	{
+		// принять приглашение можно только от своего имени, поэтому без аутентификации маршрут не работает
+		invitationsGroup.POST("/:token/accept", func(c *gin.Context) {
+			if _, ok := currentUser(c); !ok {
+				abortUnauthenticated(c, "Authorization required")
+				return
+			}
+
+			sensorOwner, err := uc.Invitation.AcceptInvitation(c.Request.Context(), c.Param("token"))
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, sensorOwnerToResponse(*sensorOwner))
+		})
+
+		invitationsGroup.OPTIONS("/:token/accept", func(c *gin.Context) {
+			setAllowHeader(c, "POST,OPTIONS")
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitations(t *testing.T) {
+	uc, _, _ := newInmemoryUseCases(t, withAuth())
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	register := func(name string) string {
+		w := doJSON(engine, http.MethodPost, "/users", `{"name": "`+name+`"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var registered UserRegistrationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+		return registered.Token
+	}
+	owner := register("owner")
+	guest := register("guest")
+	another := register("another")
+
+	w := doAuthJSON(engine, http.MethodPost, "/sensors",
+		`{"serial_number": "0000000001", "type": "adc", "description": "t"}`, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	invite := func(body string) InvitationResponse {
+		w := doAuthJSON(engine, http.MethodPost, "/sensors/1/invitations", body, owner)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var invitation InvitationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &invitation))
+		require.NotEmpty(t, invitation.Token)
+		return invitation
+	}
+
+	t.Run("only_owner_invites", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/sensors/1/invitations", `{}`, guest)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/sensors/1/invitations", `{"max_uses": -1}`, owner)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/sensors/1/invitations", `{"role": "admin"}`, owner)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("accept_once", func(t *testing.T) {
+		invitation := invite(`{"role": "operator"}`)
+		assert.Equal(t, "operator", invitation.Role)
+		assert.Equal(t, 1, invitation.MaxUses)
+
+		w := doJSON(engine, http.MethodPost, "/invitations/"+invitation.Token+"/accept", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/invitations/"+invitation.Token+"/accept", "", guest)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"user_id": 2, "sensor_id": 1, "role": "operator"}`, w.Body.String())
+
+		w = doAuthJSON(engine, http.MethodGet, "/sensors/1", "", guest)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/invitations/"+invitation.Token+"/accept", "", another)
+		assert.Equal(t, http.StatusGone, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/invitations/shi_unknown/accept", "", another)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("list_and_revoke_pending", func(t *testing.T) {
+		invitation := invite(`{"ttl_seconds": 3600, "max_uses": 5}`)
+
+		w := doAuthJSON(engine, http.MethodGet, "/sensors/1/invitations", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		var invitations []InvitationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &invitations))
+		require.Len(t, invitations, 1)
+		assert.Equal(t, invitation.ID, invitations[0].ID)
+		assert.Equal(t, "viewer", invitations[0].Role)
+		assert.Empty(t, invitations[0].Token)
+
+		w = doAuthJSON(engine, http.MethodGet, "/sensors/1/invitations", "", guest)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = doAuthJSON(engine, http.MethodDelete, "/sensors/1/invitations/"+strconv.FormatInt(invitation.ID, 10), "", owner)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		w = doAuthJSON(engine, http.MethodDelete, "/sensors/1/invitations/"+strconv.FormatInt(invitation.ID, 10), "", owner)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/invitations/"+invitation.Token+"/accept", "", another)
+		assert.Equal(t, http.StatusGone, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/sensors/1/invitations", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+	})
+
+	t.Run("allow_header_on_405", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/invitations/x/accept", "", owner)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "POST,OPTIONS", w.Header().Get("Allow"))
+
+		w = doAuthJSON(engine, http.MethodPut, "/sensors/1/invitations", "", owner)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET,HEAD,POST,OPTIONS", w.Header().Get("Allow"))
+	})
+}
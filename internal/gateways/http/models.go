@@ -1,28 +1,294 @@
 package http
 
 import (
+	"encoding/json"
+	"fmt"
 	"homework/internal/domain"
+	"math"
 	"time"
 )
 
 type SensorEventRequest struct {
 	SensorSerialNumber string `json:"sensor_serial_number"`
 	Payload            int64  `json:"payload"`
+	// Device - необязательные сведения об устройстве
+	Device *DeviceInfoRequest `json:"device"`
+}
+
+type DeviceInfoRequest struct {
+	Manufacturer     string `json:"manufacturer"`
+	Model            string `json:"model"`
+	HardwareRevision string `json:"hardware_revision"`
+	FirmwareVersion  string `json:"firmware_version"`
 }
 
 type SensorCreateRequest struct {
-	SerialNumber string `json:"serial_number"`
-	Type         string `json:"type"`
-	Description  string `json:"description"`
-	IsActive     bool   `json:"is_active"`
+	SerialNumber string              `json:"serial_number"`
+	Type         string              `json:"type"`
+	Description  string              `json:"description"`
+	IsActive     bool                `json:"is_active"`
+	Calibration  *CalibrationRequest `json:"calibration"`
+	// Labels - метки датчика, по которым фильтруются подписки на уведомления
+	Labels []string `json:"labels"`
+}
+
+// SensorImportRequest - строка массовой регистрации датчиков
+type SensorImportRequest struct {
+	SensorCreateRequest
+	OwnerID int64 `json:"owner_id"`
+}
+
+type CalibrationRequest struct {
+	Offset float64 `json:"offset"`
+	// Scale - масштаб, если не указан, равен 1
+	Scale      *float64                  `json:"scale"`
+	Polynomial []float64                 `json:"polynomial"`
+	Table      []CalibrationPointPayload `json:"table"`
+	Unit       string                    `json:"unit"`
+}
+
+// AnomalyDetectionRequest - настройки обнаружения аномалий, не указанные поля принимают значения по умолчанию
+type AnomalyDetectionRequest struct {
+	// Alpha - вес нового значения в скользящей статистике, по умолчанию 0.05
+	Alpha *float64 `json:"alpha"`
+	// Sigma - порог оценки аномальности в стандартных отклонениях, по умолчанию 3
+	Sigma *float64 `json:"sigma"`
+	// WarmUp - сколько значений накапливается до первой оценки, по умолчанию 30
+	WarmUp *int `json:"warm_up"`
+}
+
+// DebounceRequest - настройки подавления дребезга датчика сухого контакта
+type DebounceRequest struct {
+	// WindowMs - смена состояния, продержавшаяся меньше стольких миллисекунд, подавляется, 0 - не подавляется
+	WindowMs int64 `json:"window_ms"`
+	// FlapThreshold - датчик, переключившийся больше стольких раз за FlapWindowMs, нестабилен, 0 - не отслеживается
+	FlapThreshold int `json:"flap_threshold"`
+	// FlapWindowMs - окно подсчета переключений в миллисекундах
+	FlapWindowMs int64 `json:"flap_window_ms"`
+}
+
+// VirtualSensorRequest - выражение вычисляемого датчика
+type VirtualSensorRequest struct {
+	// Expression - выражение над текущими значениями датчиков s<id>, например "avg(s1, s2, s3)"
+	Expression string `json:"expression"`
+	// Resolution - шаг значения, по умолчанию 0.01
+	Resolution *float64 `json:"resolution"`
+	// Unit - единица измерения
+	Unit string `json:"unit"`
+}
+
+type CalibrationPointPayload struct {
+	Raw   int64   `json:"raw"`
+	Value float64 `json:"value"`
 }
 
 type UserCreateRequest struct {
 	Name string `json:"name"`
 }
 
+type TokenCreateRequest struct {
+	Name string `json:"name"`
+	// TTLSeconds - время жизни токена в секундах, 0 - бессрочный
+	TTLSeconds int64 `json:"ttl_seconds"`
+}
+
 type SensorBindingRequest struct {
 	SensorID int64 `json:"sensor_id"`
+	// Role - роль пользователя, по умолчанию owner
+	Role string `json:"role,omitempty"`
+}
+
+type SensorRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type SensorOwnerResponse struct {
+	UserID   int64  `json:"user_id"`
+	SensorID int64  `json:"sensor_id"`
+	Role     string `json:"role"`
+}
+
+type HomeRequest struct {
+	Name        string              `json:"name"`
+	Address     string              `json:"address"`
+	TimeZone    string              `json:"time_zone"`
+	Coordinates *CoordinatesRequest `json:"coordinates"`
+}
+
+type CoordinatesRequest struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type RoomRequest struct {
+	Name string `json:"name"`
+}
+
+type HomeBindingRequest struct {
+	HomeID int64 `json:"home_id"`
+	// Role - роль пользователя в доме, по умолчанию member
+	Role string `json:"role,omitempty"`
+}
+
+type GroupRequest struct {
+	Name string `json:"name"`
+}
+
+type GroupMemberRequest struct {
+	UserID int64 `json:"user_id"`
+}
+
+type GroupSensorRequest struct {
+	SensorID int64 `json:"sensor_id"`
+	// Role - роль участников группы, по умолчанию operator
+	Role string `json:"role,omitempty"`
+}
+
+type InvitationCreateRequest struct {
+	// Role - роль, которую получит принявший приглашение, по умолчанию viewer
+	Role string `json:"role,omitempty"`
+	// TTLSeconds - срок действия приглашения в секундах, 0 - срок по умолчанию
+	TTLSeconds int64 `json:"ttl_seconds"`
+	// MaxUses - сколько раз приглашение можно принять, 0 - один раз
+	MaxUses int `json:"max_uses"`
+}
+
+type RuleRequest struct {
+	SensorID int64  `json:"sensor_id"`
+	Name     string `json:"name"`
+	// Operator - одно из >, >=, <, <=, ==, !=
+	Operator  string  `json:"operator"`
+	Threshold float64 `json:"threshold"`
+	// DurationSeconds - сколько секунд условие должно выполняться подряд, 0 - срабатывать сразу
+	DurationSeconds int64 `json:"duration_seconds"`
+	// Window - время суток, в которое правило действует, по умолчанию круглосуточно
+	Window *RuleWindowRequest `json:"window"`
+	// Enabled - включено ли правило, по умолчанию true
+	Enabled *bool `json:"enabled"`
+}
+
+type RuleWindowRequest struct {
+	// From и To - границы окна в формате ЧЧ:ММ, To не входит в окно
+	From string `json:"from"`
+	To   string `json:"to"`
+	// TimeZone - часовой пояс IANA, по умолчанию UTC
+	TimeZone string `json:"time_zone,omitempty"`
+}
+
+type WebhookRequest struct {
+	// URL - адрес http или https, на который отправляются уведомления
+	URL string `json:"url"`
+	// EventKinds - виды уведомлений: sensor.event, alert; пусто - все виды
+	EventKinds []string `json:"event_kinds"`
+	// SensorIDs - датчики, уведомления о которых отправляются; пусто - все доступные датчики
+	SensorIDs []int64 `json:"sensor_ids"`
+	// SensorTypes - типы датчиков, уведомления о которых отправляются; пусто - все типы
+	SensorTypes []string `json:"sensor_types"`
+	// Labels - метки датчиков, уведомления о которых отправляются; пусто - датчики с любыми метками
+	Labels []string `json:"labels"`
+	// Enabled - включена ли подписка, по умолчанию true
+	Enabled *bool `json:"enabled"`
+}
+
+type ActuatorRequest struct {
+	// SensorID - датчик-контроллер, через который устройство получает команды, при изменении не меняется
+	SensorID int64  `json:"sensor_id"`
+	Name     string `json:"name"`
+	// Type - relay или valve
+	Type string `json:"type"`
+}
+
+type CommandRequest struct {
+	// Kind - set_state или pulse
+	Kind string `json:"kind"`
+	// State - целевое состояние команды set_state: 0 или 1 для реле, от 0 до 100 для клапана
+	State int64 `json:"state"`
+	// DurationMs - длительность команды pulse в миллисекундах
+	DurationMs int64 `json:"duration_ms"`
+	// TimeoutSeconds - за сколько секунд контроллер должен выполнить команду, 0 - за минуту
+	TimeoutSeconds int64 `json:"timeout_seconds"`
+}
+
+type CommandAckRequest struct {
+	// Status - acked или failed
+	Status string `json:"status"`
+	// Error - причина, по которой команда не выполнена
+	Error string `json:"error"`
+}
+
+type AutomationRequest struct {
+	Name    string                   `json:"name"`
+	Trigger AutomationTriggerRequest `json:"trigger"`
+	// Conditions - условия, которые должны выполняться все, чтобы действия выполнились
+	Conditions []AutomationConditionRequest `json:"conditions"`
+	// Actions - действия, выполняются по порядку
+	Actions []AutomationActionRequest `json:"actions"`
+	// Enabled - включена ли автоматизация, по умолчанию true
+	Enabled *bool `json:"enabled"`
+}
+
+type AutomationTriggerRequest struct {
+	SensorID int64 `json:"sensor_id"`
+	// Operator - одно из >, >=, <, <=, ==, !=; автоматизация запускается, когда состояние датчика
+	// начинает удовлетворять условию
+	Operator string  `json:"operator"`
+	Value    float64 `json:"value"`
+}
+
+type AutomationConditionRequest struct {
+	// Type - sensor_state, time_window или sun
+	Type string `json:"type"`
+	// SensorID, Operator и Value - условие на текущее состояние датчика для sensor_state
+	SensorID int64   `json:"sensor_id"`
+	Operator string  `json:"operator"`
+	Value    float64 `json:"value"`
+	// Window - время суток для time_window
+	Window *RuleWindowRequest `json:"window"`
+	// HomeID и Sun - интервал относительно восхода и заката по координатам дома для sun
+	HomeID int64                `json:"home_id"`
+	Sun    *SunConditionRequest `json:"sun"`
+}
+
+type SunConditionRequest struct {
+	// After - начало интервала, например "sunset" или "sunrise+1h", по умолчанию противоположное Before событие
+	After string `json:"after"`
+	// Before - конец интервала, по умолчанию противоположное After событие
+	Before string `json:"before"`
+}
+
+type AutomationActionRequest struct {
+	// Type - command, webhook, alert или scene; alert и scene только для автоматизаций
+	Type string `json:"type"`
+	// ActuatorID, Command, State и DurationMs - команда устройству для command
+	ActuatorID int64  `json:"actuator_id"`
+	Command    string `json:"command"`
+	State      int64  `json:"state"`
+	DurationMs int64  `json:"duration_ms"`
+	// WebhookID - подписка, которой отправляется уведомление, для webhook
+	WebhookID int64 `json:"webhook_id"`
+	// SceneID - запускаемая сцена для scene
+	SceneID int64 `json:"scene_id"`
+}
+
+type SceneRequest struct {
+	Name string `json:"name"`
+	// Actions - действия command и webhook, выполняются по порядку
+	Actions []AutomationActionRequest `json:"actions"`
+}
+
+type ScheduleRequest struct {
+	// HomeID - дом, по часовому поясу и координатам которого считается время
+	HomeID int64  `json:"home_id"`
+	Name   string `json:"name"`
+	// Expression - cron-выражение из пяти полей, например "0 7 * * mon-fri", или восход/закат
+	// со смещением, например "sunset+30m"
+	Expression string `json:"expression"`
+	// Action - действие command или scene
+	Action AutomationActionRequest `json:"action"`
+	// MissedRunPolicy - skip или run_once, по умолчанию skip
+	MissedRunPolicy string `json:"missed_run_policy"`
+	// Enabled - включено ли расписание, по умолчанию true
+	Enabled *bool `json:"enabled"`
 }
 
 type ErrorResponse struct {
@@ -30,14 +296,58 @@ type ErrorResponse struct {
 }
 
 type SensorResponse struct {
-	ID           int64     `json:"id"`
-	SerialNumber string    `json:"serial_number"`
-	Type         string    `json:"type"`
-	CurrentState int64     `json:"current_state"`
-	Description  string    `json:"description"`
-	IsActive     bool      `json:"is_active"`
-	RegisteredAt time.Time `json:"registered_at"`
-	LastActivity time.Time `json:"last_activity"`
+	ID           int64                `json:"id"`
+	SerialNumber string               `json:"serial_number"`
+	Type         string               `json:"type"`
+	CurrentState int64                `json:"current_state"`
+	CurrentValue *float64             `json:"current_value,omitempty"`
+	Unit         string               `json:"unit,omitempty"`
+	Description  string               `json:"description"`
+	IsActive     bool                 `json:"is_active"`
+	RegisteredAt time.Time            `json:"registered_at"`
+	LastActivity time.Time            `json:"last_activity"`
+	Calibration  *CalibrationResponse `json:"calibration,omitempty"`
+	// AnomalyDetection - настройки обнаружения аномалий, если оно включено
+	AnomalyDetection *AnomalyDetectionResponse `json:"anomaly_detection,omitempty"`
+	// Debounce - настройки подавления дребезга, если оно включено
+	Debounce *DebounceResponse `json:"debounce,omitempty"`
+	// Flapping - датчик переключается слишком часто, оповещения по нему не создаются
+	Flapping bool `json:"flapping,omitempty"`
+	// Virtual - выражение вычисляемого датчика, если оно задано
+	Virtual *VirtualSensorResponse `json:"virtual,omitempty"`
+
+	Manufacturer     string `json:"manufacturer,omitempty"`
+	Model            string `json:"model,omitempty"`
+	HardwareRevision string `json:"hardware_revision,omitempty"`
+	FirmwareVersion  string `json:"firmware_version,omitempty"`
+
+	Labels []string `json:"labels"`
+}
+
+type CalibrationResponse struct {
+	Offset     float64                   `json:"offset"`
+	Scale      float64                   `json:"scale"`
+	Polynomial []float64                 `json:"polynomial,omitempty"`
+	Table      []CalibrationPointPayload `json:"table,omitempty"`
+	Unit       string                    `json:"unit"`
+}
+
+type AnomalyDetectionResponse struct {
+	Alpha  float64 `json:"alpha"`
+	Sigma  float64 `json:"sigma"`
+	WarmUp int     `json:"warm_up"`
+}
+
+type DebounceResponse struct {
+	WindowMs      int64 `json:"window_ms"`
+	FlapThreshold int   `json:"flap_threshold"`
+	FlapWindowMs  int64 `json:"flap_window_ms"`
+}
+
+type VirtualSensorResponse struct {
+	Expression string  `json:"expression"`
+	Resolution float64 `json:"resolution"`
+	Unit       string  `json:"unit"`
 }
 
 type UserResponse struct {
@@ -45,9 +355,315 @@ type UserResponse struct {
 	Name string `json:"name"`
 }
 
+// SensorRegistrationResponse - ответ на регистрацию датчика, Secret есть только у нового датчика
+// при включенной подписи событий
+type SensorRegistrationResponse struct {
+	SensorResponse
+	Secret string `json:"secret,omitempty"`
+}
+
+type SensorSecretResponse struct {
+	SensorID int64  `json:"sensor_id"`
+	Secret   string `json:"secret"`
+}
+
+// UserRegistrationResponse - ответ на регистрацию, Token есть только при включенной аутентификации
+type UserRegistrationResponse struct {
+	UserResponse
+	Token string `json:"token,omitempty"`
+}
+
+type TokenResponse struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	// Token - значение токена, возвращается только при создании
+	Token string `json:"token,omitempty"`
+}
+
+type InvitationResponse struct {
+	ID        int64     `json:"id"`
+	SensorID  int64     `json:"sensor_id"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	MaxUses   int       `json:"max_uses"`
+	Uses      int       `json:"uses"`
+	// Token - значение токена приглашения, возвращается только при создании
+	Token string `json:"token,omitempty"`
+}
+
+type AuditChangeResponse struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+type AuditEntryResponse struct {
+	ID int64 `json:"id"`
+	// ActorID - id пользователя, выполнившего изменение, 0 - запрос без аутентификации
+	ActorID    int64  `json:"actor_id"`
+	Action     string `json:"action"`
+	EntityType string `json:"entity_type"`
+	EntityID   int64  `json:"entity_id"`
+	// Changes - измененные поля со значениями до и после изменения
+	Changes   map[string]AuditChangeResponse `json:"changes"`
+	RequestID string                         `json:"request_id"`
+	SourceIP  string                         `json:"source_ip"`
+	CreatedAt time.Time                      `json:"created_at"`
+}
+
+type RuleResponse struct {
+	ID              int64               `json:"id"`
+	SensorID        int64               `json:"sensor_id"`
+	Name            string              `json:"name"`
+	Operator        string              `json:"operator"`
+	Threshold       float64             `json:"threshold"`
+	DurationSeconds int64               `json:"duration_seconds"`
+	Window          *RuleWindowResponse `json:"window,omitempty"`
+	Enabled         bool                `json:"enabled"`
+	CreatedBy       int64               `json:"created_by"`
+	CreatedAt       time.Time           `json:"created_at"`
+}
+
+type RuleWindowResponse struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	TimeZone string `json:"time_zone,omitempty"`
+}
+
+type AlertResponse struct {
+	ID     int64 `json:"id"`
+	RuleID int64 `json:"rule_id"`
+	// AutomationID - id автоматизации, создавшей оповещение, вместо правила,
+	// Anomaly - оповещение об аномальных значениях датчика вместо правила
+	AutomationID int64  `json:"automation_id,omitempty"`
+	Anomaly      bool   `json:"anomaly,omitempty"`
+	SensorID     int64  `json:"sensor_id"`
+	Status       string `json:"status"`
+	// Value - значение датчика при последнем срабатывании
+	Value float64 `json:"value"`
+	// Occurrences - число срабатываний правила, учтенных в оповещении
+	Occurrences    int        `json:"occurrences"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastOccurredAt time.Time  `json:"last_occurred_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy int64      `json:"acknowledged_by,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	// ResolvedBy - id закрывшего пользователя, 0 - закрыто автоматически
+	ResolvedBy int64 `json:"resolved_by,omitempty"`
+}
+
+// WebhookResponse - подписка, Secret есть только в ответе на создание
+type WebhookResponse struct {
+	ID          int64     `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"secret,omitempty"`
+	EventKinds  []string  `json:"event_kinds"`
+	SensorIDs   []int64   `json:"sensor_ids"`
+	SensorTypes []string  `json:"sensor_types"`
+	Labels      []string  `json:"labels"`
+	Enabled     bool      `json:"enabled"`
+	CreatedBy   int64     `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID        int64  `json:"id"`
+	WebhookID int64  `json:"webhook_id"`
+	EventKind string `json:"event_kind"`
+	// Payload - отправляемое тело запроса
+	Payload json.RawMessage `json:"payload"`
+	// Status - pending, delivered или dead
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	// LastStatusCode - код ответа на последнюю попытку, 0 - ответа не было
+	LastStatusCode int        `json:"last_status_code"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+type ActuatorResponse struct {
+	ID        int64     `json:"id"`
+	SensorID  int64     `json:"sensor_id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	State     int64     `json:"state"`
+	CreatedBy int64     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CommandResponse struct {
+	ID         int64  `json:"id"`
+	ActuatorID int64  `json:"actuator_id"`
+	SensorID   int64  `json:"sensor_id"`
+	Kind       string `json:"kind"`
+	State      int64  `json:"state"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	// Status - pending, delivered, acked, failed или expired
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	CreatedBy   int64      `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+type AutomationResponse struct {
+	ID         int64                         `json:"id"`
+	Name       string                        `json:"name"`
+	Enabled    bool                          `json:"enabled"`
+	Trigger    AutomationTriggerResponse     `json:"trigger"`
+	Conditions []AutomationConditionResponse `json:"conditions"`
+	Actions    []AutomationActionResponse    `json:"actions"`
+	CreatedBy  int64                         `json:"created_by"`
+	CreatedAt  time.Time                     `json:"created_at"`
+}
+
+type AutomationTriggerResponse struct {
+	SensorID int64   `json:"sensor_id"`
+	Operator string  `json:"operator"`
+	Value    float64 `json:"value"`
+}
+
+type AutomationConditionResponse struct {
+	Type     string                `json:"type"`
+	SensorID int64                 `json:"sensor_id,omitempty"`
+	Operator string                `json:"operator,omitempty"`
+	Value    float64               `json:"value,omitempty"`
+	Window   *RuleWindowResponse   `json:"window,omitempty"`
+	HomeID   int64                 `json:"home_id,omitempty"`
+	Sun      *SunConditionResponse `json:"sun,omitempty"`
+}
+
+type SunConditionResponse struct {
+	After  string `json:"after,omitempty"`
+	Before string `json:"before,omitempty"`
+}
+
+type AutomationActionResponse struct {
+	Type       string `json:"type"`
+	ActuatorID int64  `json:"actuator_id,omitempty"`
+	Command    string `json:"command,omitempty"`
+	State      int64  `json:"state,omitempty"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	WebhookID  int64  `json:"webhook_id,omitempty"`
+	SceneID    int64  `json:"scene_id,omitempty"`
+}
+
+type SceneResponse struct {
+	ID        int64                      `json:"id"`
+	Name      string                     `json:"name"`
+	Actions   []AutomationActionResponse `json:"actions"`
+	CreatedBy int64                      `json:"created_by"`
+	CreatedAt time.Time                  `json:"created_at"`
+}
+
+type ScheduleResponse struct {
+	ID              int64                    `json:"id"`
+	HomeID          int64                    `json:"home_id"`
+	Name            string                   `json:"name"`
+	Expression      string                   `json:"expression"`
+	Action          AutomationActionResponse `json:"action"`
+	MissedRunPolicy string                   `json:"missed_run_policy"`
+	Enabled         bool                     `json:"enabled"`
+	// NextRunAt - время следующего запуска, нет у выключенного расписания
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	CreatedBy int64      `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type AutomationRunResponse struct {
+	ID           int64 `json:"id"`
+	AutomationID int64 `json:"automation_id,omitempty"`
+	SceneID      int64 `json:"scene_id,omitempty"`
+	ScheduleID   int64 `json:"schedule_id,omitempty"`
+	// SensorID - датчик, событие которого запустило автоматизацию
+	SensorID int64 `json:"sensor_id,omitempty"`
+	// Status - succeeded, failed или skipped
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type HomeResponse struct {
+	ID          int64                `json:"id"`
+	Name        string               `json:"name"`
+	Address     string               `json:"address"`
+	TimeZone    string               `json:"time_zone"`
+	Coordinates *CoordinatesResponse `json:"coordinates,omitempty"`
+}
+
+type CoordinatesResponse struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type RoomResponse struct {
+	ID     int64  `json:"id"`
+	HomeID int64  `json:"home_id"`
+	Name   string `json:"name"`
+}
+
+type GroupResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type GroupMemberResponse struct {
+	GroupID int64 `json:"group_id"`
+	UserID  int64 `json:"user_id"`
+}
+
+type GroupSensorResponse struct {
+	GroupID  int64  `json:"group_id"`
+	SensorID int64  `json:"sensor_id"`
+	Role     string `json:"role"`
+}
+
+type SensorImportResultResponse struct {
+	Row          int    `json:"row"`
+	SerialNumber string `json:"serial_number"`
+	Status       string `json:"status"`
+	SensorID     int64  `json:"sensor_id,omitempty"`
+	Secret       string `json:"secret,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+type SensorImportResponse struct {
+	DryRun  bool                         `json:"dry_run"`
+	Mode    string                       `json:"mode"`
+	Created int                          `json:"created"`
+	Failed  int                          `json:"failed"`
+	Results []SensorImportResultResponse `json:"results"`
+}
+
+type FirmwareChangeResponse struct {
+	PreviousVersion string    `json:"previous_version"`
+	Version         string    `json:"version"`
+	ChangedAt       time.Time `json:"changed_at"`
+}
+
+type InventoryGroupResponse struct {
+	Manufacturer    string  `json:"manufacturer"`
+	Model           string  `json:"model"`
+	FirmwareVersion string  `json:"firmware_version"`
+	Count           int     `json:"count"`
+	SensorIDs       []int64 `json:"sensor_ids"`
+}
+
 type SensorHistoryResponse struct {
 	Timestamp       time.Time `json:"timestamp"`
 	Payload         int64     `json:"payload"`
+	Value           *float64  `json:"value,omitempty"`
+	Unit            string    `json:"unit,omitempty"`
+	AnomalyScore    *float64  `json:"anomaly_score,omitempty"`
+	Anomalous       bool      `json:"anomalous,omitempty"`
 	RequestTime     string    `json:"request_time"`
 	RequestedByUser string    `json:"requested_by_user"`
 }
@@ -65,11 +681,153 @@ func sensorToDomain(req SensorCreateRequest) *domain.Sensor {
 		IsActive:     req.IsActive,
 		RegisteredAt: time.Now(),
 		LastActivity: time.Now(),
+		Calibration:  calibrationToDomain(req.Calibration),
+		Labels:       req.Labels,
+	}
+}
+
+func sensorImportToDomain(requests []SensorImportRequest) []domain.SensorImportRow {
+	rows := make([]domain.SensorImportRow, len(requests))
+	for i, req := range requests {
+		rows[i] = domain.SensorImportRow{
+			Sensor:  *sensorToDomain(req.SensorCreateRequest),
+			OwnerID: req.OwnerID,
+		}
 	}
+	return rows
+}
+
+func sensorImportToResponse(results []domain.SensorImportResult, mode domain.SensorImportMode, dryRun bool) SensorImportResponse {
+	response := SensorImportResponse{
+		DryRun:  dryRun,
+		Mode:    string(mode),
+		Results: make([]SensorImportResultResponse, len(results)),
+	}
+
+	for i, r := range results {
+		response.Results[i] = SensorImportResultResponse{
+			Row:          r.Row,
+			SerialNumber: r.SerialNumber,
+			Status:       string(r.Status),
+			SensorID:     r.SensorID,
+			Secret:       r.Secret,
+		}
+		if r.Err != nil {
+			response.Results[i].Error = r.Err.Error()
+		}
+
+		switch r.Status {
+		case domain.SensorImportStatusCreated:
+			response.Created++
+		case domain.SensorImportStatusInvalid, domain.SensorImportStatusFailed:
+			response.Failed++
+		}
+	}
+	return response
+}
+
+func calibrationToDomain(req *CalibrationRequest) *domain.Calibration {
+	if req == nil {
+		return nil
+	}
+
+	c := &domain.Calibration{
+		Offset:     req.Offset,
+		Scale:      1,
+		Polynomial: req.Polynomial,
+		Unit:       req.Unit,
+	}
+	if req.Scale != nil {
+		c.Scale = *req.Scale
+	}
+	for _, p := range req.Table {
+		c.Table = append(c.Table, domain.CalibrationPoint{Raw: p.Raw, Value: p.Value})
+	}
+	return c
+}
+
+func calibrationToResponse(c *domain.Calibration) *CalibrationResponse {
+	if c == nil {
+		return nil
+	}
+
+	result := &CalibrationResponse{
+		Offset:     c.Offset,
+		Scale:      c.Scale,
+		Polynomial: c.Polynomial,
+		Unit:       c.Unit,
+	}
+	for _, p := range c.Table {
+		result.Table = append(result.Table, CalibrationPointPayload{Raw: p.Raw, Value: p.Value})
+	}
+	return result
+}
+
+func anomalyDetectionToDomain(req *AnomalyDetectionRequest) *domain.AnomalyDetection {
+	d := &domain.AnomalyDetection{
+		Alpha:  domain.DefaultAnomalyAlpha,
+		Sigma:  domain.DefaultAnomalySigma,
+		WarmUp: domain.DefaultAnomalyWarmUp,
+	}
+	if req.Alpha != nil {
+		d.Alpha = *req.Alpha
+	}
+	if req.Sigma != nil {
+		d.Sigma = *req.Sigma
+	}
+	if req.WarmUp != nil {
+		d.WarmUp = *req.WarmUp
+	}
+	return d
+}
+
+func anomalyDetectionToResponse(d *domain.AnomalyDetection) *AnomalyDetectionResponse {
+	if d == nil {
+		return nil
+	}
+	return &AnomalyDetectionResponse{Alpha: d.Alpha, Sigma: d.Sigma, WarmUp: d.WarmUp}
+}
+
+func debounceToDomain(req *DebounceRequest) *domain.Debounce {
+	return &domain.Debounce{
+		Window:        time.Duration(req.WindowMs) * time.Millisecond,
+		FlapThreshold: req.FlapThreshold,
+		FlapWindow:    time.Duration(req.FlapWindowMs) * time.Millisecond,
+	}
+}
+
+func debounceToResponse(d *domain.Debounce) *DebounceResponse {
+	if d == nil {
+		return nil
+	}
+	return &DebounceResponse{
+		WindowMs:      d.Window.Milliseconds(),
+		FlapThreshold: d.FlapThreshold,
+		FlapWindowMs:  d.FlapWindow.Milliseconds(),
+	}
+}
+
+func virtualSensorToDomain(req *VirtualSensorRequest) *domain.VirtualSensor {
+	v := &domain.VirtualSensor{
+		Expression: req.Expression,
+		Resolution: domain.DefaultVirtualResolution,
+		Unit:       req.Unit,
+	}
+	if req.Resolution != nil {
+		v.Resolution = *req.Resolution
+	}
+	return v
+}
+
+func virtualSensorToResponse(v *domain.VirtualSensor) *VirtualSensorResponse {
+	if v == nil {
+		return nil
+	}
+	return &VirtualSensorResponse{Expression: v.Expression, Resolution: v.Resolution, Unit: v.Unit}
 }
 
 func sensorToResponse(s *domain.Sensor) SensorResponse {
-	return SensorResponse{
+	result := SensorResponse{
 		ID:           s.ID,
 		SerialNumber: s.SerialNumber,
 		Type:         string(s.Type),
@@ -78,7 +836,25 @@ func sensorToResponse(s *domain.Sensor) SensorResponse {
 		IsActive:     s.IsActive,
 		RegisteredAt: s.RegisteredAt,
 		LastActivity: s.LastActivity,
+		Calibration:  calibrationToResponse(s.Calibration),
+
+		AnomalyDetection: anomalyDetectionToResponse(s.AnomalyDetection),
+		Debounce:         debounceToResponse(s.Debounce),
+		Flapping:         s.Flapping,
+		Virtual:          virtualSensorToResponse(s.Virtual),
+
+		Manufacturer:     s.Manufacturer,
+		Model:            s.Model,
+		HardwareRevision: s.HardwareRevision,
+		FirmwareVersion:  s.FirmwareVersion,
+
+		Labels: append([]string{}, s.Labels...),
 	}
+	current := domain.Event{Payload: s.CurrentState}
+	s.ApplyCalibration(&current)
+	result.CurrentValue = current.Value
+	result.Unit = current.Unit
+	return result
 }
 
 func sensorsToResponse(sensors []domain.Sensor) []SensorResponse {
@@ -95,6 +871,30 @@ func userToDomain(req UserCreateRequest) *domain.User {
 	}
 }
 
+func usersToResponse(users []domain.User) []UserResponse {
+	result := make([]UserResponse, len(users))
+	for i, u := range users {
+		result[i] = userToResponse(&u)
+	}
+	return result
+}
+
+func sensorOwnerToResponse(so domain.SensorOwner) SensorOwnerResponse {
+	return SensorOwnerResponse{
+		UserID:   so.UserID,
+		SensorID: so.SensorID,
+		Role:     string(so.Role),
+	}
+}
+
+func sensorOwnersToResponse(sensorOwners []domain.SensorOwner) []SensorOwnerResponse {
+	result := make([]SensorOwnerResponse, len(sensorOwners))
+	for i, so := range sensorOwners {
+		result[i] = sensorOwnerToResponse(so)
+	}
+	return result
+}
+
 func userToResponse(u *domain.User) UserResponse {
 	return UserResponse{
 		ID:   u.ID,
@@ -102,12 +902,112 @@ func userToResponse(u *domain.User) UserResponse {
 	}
 }
 
+func tokenToResponse(t *domain.APIToken) TokenResponse {
+	return TokenResponse{
+		ID:        t.ID,
+		Name:      t.Name,
+		CreatedAt: t.CreatedAt,
+		ExpiresAt: t.ExpiresAt,
+		RevokedAt: t.RevokedAt,
+	}
+}
+
+func tokensToResponse(tokens []domain.APIToken) []TokenResponse {
+	result := make([]TokenResponse, len(tokens))
+	for i, t := range tokens {
+		result[i] = tokenToResponse(&t)
+	}
+	return result
+}
+
+func invitationToResponse(i *domain.SensorInvitation) InvitationResponse {
+	return InvitationResponse{
+		ID:        i.ID,
+		SensorID:  i.SensorID,
+		Role:      string(i.Role),
+		CreatedAt: i.CreatedAt,
+		ExpiresAt: i.ExpiresAt,
+		MaxUses:   i.MaxUses,
+		Uses:      i.Uses,
+	}
+}
+
+func invitationsToResponse(invitations []domain.SensorInvitation) []InvitationResponse {
+	result := make([]InvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		result[i] = invitationToResponse(&invitation)
+	}
+	return result
+}
+
+func auditEntriesToResponse(entries []domain.AuditEntry) []AuditEntryResponse {
+	result := make([]AuditEntryResponse, len(entries))
+	for i, entry := range entries {
+		changes := make(map[string]AuditChangeResponse, len(entry.Changes))
+		for name, change := range entry.Changes {
+			changes[name] = AuditChangeResponse{Before: change.Before, After: change.After}
+		}
+		result[i] = AuditEntryResponse{
+			ID:         entry.ID,
+			ActorID:    entry.ActorID,
+			Action:     string(entry.Action),
+			EntityType: string(entry.EntityType),
+			EntityID:   entry.EntityID,
+			Changes:    changes,
+			RequestID:  entry.RequestID,
+			SourceIP:   entry.SourceIP,
+			CreatedAt:  entry.CreatedAt,
+		}
+	}
+	return result
+}
+
 func eventToDomain(req SensorEventRequest) *domain.Event {
-	return &domain.Event{
+	event := &domain.Event{
 		SensorSerialNumber: req.SensorSerialNumber,
 		Payload:            req.Payload,
 		Timestamp:          time.Now(),
 	}
+	if req.Device != nil {
+		info := deviceInfoToDomain(*req.Device)
+		event.Device = &info
+	}
+	return event
+}
+
+func deviceInfoToDomain(req DeviceInfoRequest) domain.DeviceInfo {
+	return domain.DeviceInfo{
+		Manufacturer:     req.Manufacturer,
+		Model:            req.Model,
+		HardwareRevision: req.HardwareRevision,
+		FirmwareVersion:  req.FirmwareVersion,
+	}
+}
+
+func firmwareHistoryToResponse(changes []domain.FirmwareChange) []FirmwareChangeResponse {
+	result := make([]FirmwareChangeResponse, len(changes))
+	for i, c := range changes {
+		result[i] = FirmwareChangeResponse{
+			PreviousVersion: c.PreviousVersion,
+			Version:         c.Version,
+			ChangedAt:       c.ChangedAt,
+		}
+	}
+	return result
+}
+
+func inventoryToResponse(groups []domain.InventoryGroup) []InventoryGroupResponse {
+	result := make([]InventoryGroupResponse, len(groups))
+	for i, g := range groups {
+		result[i] = InventoryGroupResponse{
+			Manufacturer:    g.Manufacturer,
+			Model:           g.Model,
+			FirmwareVersion: g.FirmwareVersion,
+			Count:           len(g.SensorIDs),
+			SensorIDs:       g.SensorIDs,
+		}
+	}
+	return result
 }
 
 func eventsToHistoryResponse(events []domain.Event, metadata SensorHistoryMetadata) []SensorHistoryResponse {
@@ -117,9 +1017,543 @@ func eventsToHistoryResponse(events []domain.Event, metadata SensorHistoryMetada
 		result[i] = SensorHistoryResponse{
 			Timestamp:       e.Timestamp,
 			Payload:         e.Payload,
+			Value:           e.Value,
+			Unit:            e.Unit,
+			AnomalyScore:    e.AnomalyScore,
+			Anomalous:       e.Anomalous,
 			RequestTime:     metadata.RequestTime,
 			RequestedByUser: metadata.RequestedByUser,
 		}
 	}
 	return result
 }
+
+func homeToDomain(req HomeRequest) *domain.Home {
+	home := &domain.Home{
+		Name:     req.Name,
+		Address:  req.Address,
+		TimeZone: req.TimeZone,
+	}
+	if req.Coordinates != nil {
+		home.Coordinates = &domain.Coordinates{Latitude: req.Coordinates.Latitude, Longitude: req.Coordinates.Longitude}
+	}
+	return home
+}
+
+func homeToResponse(h *domain.Home) HomeResponse {
+	resp := HomeResponse{
+		ID:       h.ID,
+		Name:     h.Name,
+		Address:  h.Address,
+		TimeZone: h.TimeZone,
+	}
+	if h.Coordinates != nil {
+		resp.Coordinates = &CoordinatesResponse{Latitude: h.Coordinates.Latitude, Longitude: h.Coordinates.Longitude}
+	}
+	return resp
+}
+
+func homesToResponse(homes []domain.Home) []HomeResponse {
+	result := make([]HomeResponse, len(homes))
+	for i, h := range homes {
+		result[i] = homeToResponse(&h)
+	}
+	return result
+}
+
+func roomToDomain(req RoomRequest) *domain.Room {
+	return &domain.Room{
+		Name: req.Name,
+	}
+}
+
+func roomToResponse(r *domain.Room) RoomResponse {
+	return RoomResponse{
+		ID:     r.ID,
+		HomeID: r.HomeID,
+		Name:   r.Name,
+	}
+}
+
+func roomsToResponse(rooms []domain.Room) []RoomResponse {
+	result := make([]RoomResponse, len(rooms))
+	for i, r := range rooms {
+		result[i] = roomToResponse(&r)
+	}
+	return result
+}
+
+func groupToDomain(req GroupRequest) *domain.Group {
+	return &domain.Group{
+		Name: req.Name,
+	}
+}
+
+func groupToResponse(g *domain.Group) GroupResponse {
+	return GroupResponse{
+		ID:   g.ID,
+		Name: g.Name,
+	}
+}
+
+func groupsToResponse(groups []domain.Group) []GroupResponse {
+	result := make([]GroupResponse, len(groups))
+	for i, g := range groups {
+		result[i] = groupToResponse(&g)
+	}
+	return result
+}
+
+func groupMembersToResponse(members []domain.GroupMember) []GroupMemberResponse {
+	result := make([]GroupMemberResponse, len(members))
+	for i, m := range members {
+		result[i] = GroupMemberResponse{GroupID: m.GroupID, UserID: m.UserID}
+	}
+	return result
+}
+
+func groupSensorsToResponse(groupSensors []domain.GroupSensor) []GroupSensorResponse {
+	result := make([]GroupSensorResponse, len(groupSensors))
+	for i, gs := range groupSensors {
+		result[i] = GroupSensorResponse{GroupID: gs.GroupID, SensorID: gs.SensorID, Role: string(gs.Role)}
+	}
+	return result
+}
+
+func ruleToDomain(req RuleRequest) *domain.Rule {
+	rule := &domain.Rule{
+		SensorID:  req.SensorID,
+		Name:      req.Name,
+		Operator:  domain.RuleOperator(req.Operator),
+		Threshold: req.Threshold,
+		Duration:  time.Duration(req.DurationSeconds) * time.Second,
+		Enabled:   req.Enabled == nil || *req.Enabled,
+	}
+	if req.DurationSeconds < 0 || req.DurationSeconds > math.MaxInt64/int64(time.Second) {
+		// не даем длительности переполниться в допустимое значение, такое правило отклонит проверка
+		rule.Duration = -1
+	}
+	if req.Window != nil {
+		rule.Window = &domain.RuleWindow{
+			From:     parseTimeOfDay(req.Window.From),
+			To:       parseTimeOfDay(req.Window.To),
+			TimeZone: req.Window.TimeZone,
+		}
+	}
+	return rule
+}
+
+// parseTimeOfDay переводит время ЧЧ:ММ в минуты от полуночи, для некорректного значения возвращает -1
+func parseTimeOfDay(s string) int {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return -1
+	}
+	return t.Hour()*60 + t.Minute()
+}
+
+func formatTimeOfDay(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func ruleToResponse(r *domain.Rule) RuleResponse {
+	response := RuleResponse{
+		ID:              r.ID,
+		SensorID:        r.SensorID,
+		Name:            r.Name,
+		Operator:        string(r.Operator),
+		Threshold:       r.Threshold,
+		DurationSeconds: int64(r.Duration / time.Second),
+		Enabled:         r.Enabled,
+		CreatedBy:       r.CreatedBy,
+		CreatedAt:       r.CreatedAt,
+	}
+	if r.Window != nil {
+		response.Window = &RuleWindowResponse{
+			From:     formatTimeOfDay(r.Window.From),
+			To:       formatTimeOfDay(r.Window.To),
+			TimeZone: r.Window.TimeZone,
+		}
+	}
+	return response
+}
+
+func rulesToResponse(rules []domain.Rule) []RuleResponse {
+	result := make([]RuleResponse, len(rules))
+	for i, r := range rules {
+		result[i] = ruleToResponse(&r)
+	}
+	return result
+}
+
+func alertToResponse(a *domain.Alert) AlertResponse {
+	return AlertResponse{
+		ID:             a.ID,
+		RuleID:         a.RuleID,
+		AutomationID:   a.AutomationID,
+		Anomaly:        a.Anomaly,
+		SensorID:       a.SensorID,
+		Status:         string(a.Status),
+		Value:          a.Value,
+		Occurrences:    a.Occurrences,
+		CreatedAt:      a.CreatedAt,
+		LastOccurredAt: a.LastOccurredAt,
+		AcknowledgedAt: a.AcknowledgedAt,
+		AcknowledgedBy: a.AcknowledgedBy,
+		ResolvedAt:     a.ResolvedAt,
+		ResolvedBy:     a.ResolvedBy,
+	}
+}
+
+func alertsToResponse(alerts []domain.Alert) []AlertResponse {
+	result := make([]AlertResponse, len(alerts))
+	for i, a := range alerts {
+		result[i] = alertToResponse(&a)
+	}
+	return result
+}
+
+func webhookToDomain(req WebhookRequest) *domain.Webhook {
+	webhook := &domain.Webhook{
+		URL:       req.URL,
+		SensorIDs: req.SensorIDs,
+		Labels:    req.Labels,
+		Enabled:   req.Enabled == nil || *req.Enabled,
+	}
+	for _, kind := range req.EventKinds {
+		webhook.EventKinds = append(webhook.EventKinds, domain.WebhookEventKind(kind))
+	}
+	for _, sensorType := range req.SensorTypes {
+		webhook.SensorTypes = append(webhook.SensorTypes, domain.SensorType(sensorType))
+	}
+	return webhook
+}
+
+func webhookToResponse(w *domain.Webhook) WebhookResponse {
+	response := WebhookResponse{
+		ID:          w.ID,
+		URL:         w.URL,
+		EventKinds:  make([]string, 0, len(w.EventKinds)),
+		SensorIDs:   make([]int64, 0, len(w.SensorIDs)),
+		SensorTypes: make([]string, 0, len(w.SensorTypes)),
+		Labels:      append([]string{}, w.Labels...),
+		Enabled:     w.Enabled,
+		CreatedBy:   w.CreatedBy,
+		CreatedAt:   w.CreatedAt,
+	}
+	for _, kind := range w.EventKinds {
+		response.EventKinds = append(response.EventKinds, string(kind))
+	}
+	response.SensorIDs = append(response.SensorIDs, w.SensorIDs...)
+	for _, sensorType := range w.SensorTypes {
+		response.SensorTypes = append(response.SensorTypes, string(sensorType))
+	}
+	return response
+}
+
+func webhooksToResponse(webhooks []domain.Webhook) []WebhookResponse {
+	result := make([]WebhookResponse, len(webhooks))
+	for i, w := range webhooks {
+		result[i] = webhookToResponse(&w)
+	}
+	return result
+}
+
+func webhookDeliveryToResponse(d *domain.WebhookDelivery) WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{
+		ID:             d.ID,
+		WebhookID:      d.WebhookID,
+		EventKind:      string(d.EventKind),
+		Payload:        json.RawMessage(d.Payload),
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+}
+
+func webhookDeliveriesToResponse(deliveries []domain.WebhookDelivery) []WebhookDeliveryResponse {
+	result := make([]WebhookDeliveryResponse, len(deliveries))
+	for i, d := range deliveries {
+		result[i] = webhookDeliveryToResponse(&d)
+	}
+	return result
+}
+
+func actuatorToDomain(req ActuatorRequest) *domain.Actuator {
+	return &domain.Actuator{
+		SensorID: req.SensorID,
+		Name:     req.Name,
+		Type:     domain.ActuatorType(req.Type),
+	}
+}
+
+func actuatorToResponse(a *domain.Actuator) ActuatorResponse {
+	return ActuatorResponse{
+		ID:        a.ID,
+		SensorID:  a.SensorID,
+		Name:      a.Name,
+		Type:      string(a.Type),
+		State:     a.State,
+		CreatedBy: a.CreatedBy,
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+func actuatorsToResponse(actuators []domain.Actuator) []ActuatorResponse {
+	result := make([]ActuatorResponse, len(actuators))
+	for i, a := range actuators {
+		result[i] = actuatorToResponse(&a)
+	}
+	return result
+}
+
+// commandToDomain возвращает команду и время на ее выполнение
+func commandToDomain(req CommandRequest, actuatorID int64) (*domain.Command, time.Duration) {
+	command := &domain.Command{
+		ActuatorID: actuatorID,
+		Kind:       domain.CommandKind(req.Kind),
+		State:      req.State,
+		Duration:   time.Duration(req.DurationMs) * time.Millisecond,
+	}
+	timeout := time.Duration(req.TimeoutSeconds) * time.Second
+	// не даем значениям переполниться в допустимые, такую команду отклонит проверка
+	if req.DurationMs < 0 || req.DurationMs > math.MaxInt64/int64(time.Millisecond) {
+		command.Duration = -1
+	}
+	if req.TimeoutSeconds < 0 || req.TimeoutSeconds > math.MaxInt64/int64(time.Second) {
+		timeout = -1
+	}
+	return command, timeout
+}
+
+func commandToResponse(c *domain.Command) CommandResponse {
+	return CommandResponse{
+		ID:          c.ID,
+		ActuatorID:  c.ActuatorID,
+		SensorID:    c.SensorID,
+		Kind:        string(c.Kind),
+		State:       c.State,
+		DurationMs:  c.Duration.Milliseconds(),
+		Status:      string(c.Status),
+		Error:       c.Error,
+		CreatedBy:   c.CreatedBy,
+		CreatedAt:   c.CreatedAt,
+		ExpiresAt:   c.ExpiresAt,
+		DeliveredAt: c.DeliveredAt,
+		CompletedAt: c.CompletedAt,
+	}
+}
+
+func commandsToResponse(commands []domain.Command) []CommandResponse {
+	result := make([]CommandResponse, len(commands))
+	for i, c := range commands {
+		result[i] = commandToResponse(&c)
+	}
+	return result
+}
+
+func automationToDomain(req AutomationRequest) *domain.Automation {
+	automation := &domain.Automation{
+		Name:    req.Name,
+		Enabled: req.Enabled == nil || *req.Enabled,
+		Trigger: domain.AutomationTrigger{
+			SensorID: req.Trigger.SensorID,
+			Operator: domain.RuleOperator(req.Trigger.Operator),
+			Value:    req.Trigger.Value,
+		},
+		Actions: actionsToDomain(req.Actions),
+	}
+	for _, c := range req.Conditions {
+		condition := domain.AutomationCondition{
+			Type:     domain.ConditionType(c.Type),
+			SensorID: c.SensorID,
+			Operator: domain.RuleOperator(c.Operator),
+			Value:    c.Value,
+			HomeID:   c.HomeID,
+		}
+		if c.Window != nil {
+			condition.Window = &domain.RuleWindow{
+				From:     parseTimeOfDay(c.Window.From),
+				To:       parseTimeOfDay(c.Window.To),
+				TimeZone: c.Window.TimeZone,
+			}
+		}
+		if c.Sun != nil {
+			condition.Sun = &domain.SunCondition{After: c.Sun.After, Before: c.Sun.Before}
+		}
+		automation.Conditions = append(automation.Conditions, condition)
+	}
+	return automation
+}
+
+func sceneToDomain(req SceneRequest) *domain.Scene {
+	return &domain.Scene{
+		Name:    req.Name,
+		Actions: actionsToDomain(req.Actions),
+	}
+}
+
+func actionsToDomain(req []AutomationActionRequest) []domain.AutomationAction {
+	var actions []domain.AutomationAction
+	for _, a := range req {
+		action := domain.AutomationAction{
+			Type:       domain.ActionType(a.Type),
+			ActuatorID: a.ActuatorID,
+			Command:    domain.CommandKind(a.Command),
+			State:      a.State,
+			Duration:   time.Duration(a.DurationMs) * time.Millisecond,
+			WebhookID:  a.WebhookID,
+			SceneID:    a.SceneID,
+		}
+		// не даем длительности переполниться в допустимое значение, такое действие отклонит проверка
+		if a.DurationMs < 0 || a.DurationMs > math.MaxInt64/int64(time.Millisecond) {
+			action.Duration = -1
+		}
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+func automationToResponse(a *domain.Automation) AutomationResponse {
+	response := AutomationResponse{
+		ID:      a.ID,
+		Name:    a.Name,
+		Enabled: a.Enabled,
+		Trigger: AutomationTriggerResponse{
+			SensorID: a.Trigger.SensorID,
+			Operator: string(a.Trigger.Operator),
+			Value:    a.Trigger.Value,
+		},
+		Conditions: make([]AutomationConditionResponse, len(a.Conditions)),
+		Actions:    actionsToResponse(a.Actions),
+		CreatedBy:  a.CreatedBy,
+		CreatedAt:  a.CreatedAt,
+	}
+	for i, c := range a.Conditions {
+		response.Conditions[i] = AutomationConditionResponse{
+			Type:     string(c.Type),
+			SensorID: c.SensorID,
+			Operator: string(c.Operator),
+			Value:    c.Value,
+			HomeID:   c.HomeID,
+		}
+		if c.Window != nil {
+			response.Conditions[i].Window = &RuleWindowResponse{
+				From:     formatTimeOfDay(c.Window.From),
+				To:       formatTimeOfDay(c.Window.To),
+				TimeZone: c.Window.TimeZone,
+			}
+		}
+		if c.Sun != nil {
+			response.Conditions[i].Sun = &SunConditionResponse{After: c.Sun.After, Before: c.Sun.Before}
+		}
+	}
+	return response
+}
+
+func automationsToResponse(automations []domain.Automation) []AutomationResponse {
+	result := make([]AutomationResponse, len(automations))
+	for i, a := range automations {
+		result[i] = automationToResponse(&a)
+	}
+	return result
+}
+
+func sceneToResponse(s *domain.Scene) SceneResponse {
+	return SceneResponse{
+		ID:        s.ID,
+		Name:      s.Name,
+		Actions:   actionsToResponse(s.Actions),
+		CreatedBy: s.CreatedBy,
+		CreatedAt: s.CreatedAt,
+	}
+}
+
+func scenesToResponse(scenes []domain.Scene) []SceneResponse {
+	result := make([]SceneResponse, len(scenes))
+	for i, s := range scenes {
+		result[i] = sceneToResponse(&s)
+	}
+	return result
+}
+
+func actionsToResponse(actions []domain.AutomationAction) []AutomationActionResponse {
+	result := make([]AutomationActionResponse, len(actions))
+	for i, a := range actions {
+		result[i] = AutomationActionResponse{
+			Type:       string(a.Type),
+			ActuatorID: a.ActuatorID,
+			Command:    string(a.Command),
+			State:      a.State,
+			DurationMs: a.Duration.Milliseconds(),
+			WebhookID:  a.WebhookID,
+			SceneID:    a.SceneID,
+		}
+	}
+	return result
+}
+
+func scheduleToDomain(req ScheduleRequest) *domain.Schedule {
+	return &domain.Schedule{
+		HomeID:          req.HomeID,
+		Name:            req.Name,
+		Expression:      req.Expression,
+		Action:          actionsToDomain([]AutomationActionRequest{req.Action})[0],
+		MissedRunPolicy: domain.MissedRunPolicy(req.MissedRunPolicy),
+		Enabled:         req.Enabled == nil || *req.Enabled,
+	}
+}
+
+func scheduleToResponse(s *domain.Schedule) ScheduleResponse {
+	resp := ScheduleResponse{
+		ID:              s.ID,
+		HomeID:          s.HomeID,
+		Name:            s.Name,
+		Expression:      s.Expression,
+		Action:          actionsToResponse([]domain.AutomationAction{s.Action})[0],
+		MissedRunPolicy: string(s.MissedRunPolicy),
+		Enabled:         s.Enabled,
+		CreatedBy:       s.CreatedBy,
+		CreatedAt:       s.CreatedAt,
+	}
+	if !s.NextRunAt.IsZero() {
+		resp.NextRunAt = &s.NextRunAt
+	}
+	if !s.LastRunAt.IsZero() {
+		resp.LastRunAt = &s.LastRunAt
+	}
+	return resp
+}
+
+func schedulesToResponse(schedules []domain.Schedule) []ScheduleResponse {
+	result := make([]ScheduleResponse, len(schedules))
+	for i, s := range schedules {
+		result[i] = scheduleToResponse(&s)
+	}
+	return result
+}
+
+func automationRunToResponse(r *domain.AutomationRun) AutomationRunResponse {
+	return AutomationRunResponse{
+		ID:           r.ID,
+		AutomationID: r.AutomationID,
+		SceneID:      r.SceneID,
+		ScheduleID:   r.ScheduleID,
+		SensorID:     r.SensorID,
+		Status:       string(r.Status),
+		Error:        r.Error,
+		CreatedAt:    r.CreatedAt,
+	}
+}
+
+func automationRunsToResponse(runs []domain.AutomationRun) []AutomationRunResponse {
+	result := make([]AutomationRunResponse, len(runs))
+	for i, r := range runs {
+		result[i] = automationRunToResponse(&r)
+	}
+	return result
+}
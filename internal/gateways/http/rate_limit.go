@@ -0,0 +1,89 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"homework/internal/usecase"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventClientRateLimitMiddleware ограничивает частоту событий с адреса клиента. Выполняется до проверки подписи
+// и ничего не читает из тела, чтобы дешево отбрасывать поток запросов.
+func eventClientRateLimitMiddleware(l *usecase.RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := l.AllowClientEvent(c.Request.Context(), c.ClientIP()); err != nil {
+			handleError(c, err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// eventRateLimitMiddleware ограничивает частоту событий по серийному номеру датчика из тела запроса.
+// Тело с ошибками пропускается дальше: на него ответит обработчик.
+func eventRateLimitMiddleware(l *usecase.RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var eventReq SensorEventRequest
+		if err := json.Unmarshal(body, &eventReq); err != nil || eventReq.SensorSerialNumber == "" {
+			c.Next()
+			return
+		}
+
+		if err := l.AllowSensorEvent(c.Request.Context(), eventReq.SensorSerialNumber); err != nil {
+			handleError(c, err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// readRateLimitMiddleware ограничивает частоту запросов GET и HEAD аутентифицированного пользователя
+func readRateLimitMiddleware(l *usecase.RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.FullPath() == "" || c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		user, ok := currentUser(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if err := l.AllowUserRead(c.Request.Context(), user.ID); err != nil {
+			if c.Request.Method == http.MethodHead {
+				handleStatusOnlyError(c, err)
+			} else {
+				handleError(c, err)
+			}
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// setRetryAfter выставляет заголовок Retry-After в целых секундах, округляя вверх
+func setRetryAfter(c *gin.Context, err error) {
+	var limitErr *usecase.RateLimitError
+	if errors.As(err, &limitErr) {
+		seconds := int64(math.Ceil(limitErr.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.FormatInt(max(seconds, 1), 10))
+	}
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"encoding/json"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	rateLimitInmemory "homework/internal/repository/ratelimit/inmemory"
+)
+
+func TestRateLimits(t *testing.T) {
+	uc, sr, _ := newInmemoryUseCases(t, withAuth())
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	uc.RateLimiter = usecase.NewRateLimiter(rateLimitInmemory.NewRateLimitStore(), sr,
+		usecase.WithSensorRateLimit(domain.RateLimit{Rate: 10, Burst: 3}),
+		usecase.WithSensorTypeRateLimit(domain.SensorTypeContactClosure, domain.RateLimit{Rate: 0.5, Burst: 1}),
+		usecase.WithUserRateLimit(domain.RateLimit{Rate: 1, Burst: 2}),
+		usecase.WithRateLimiterClock(func() time.Time { return now }),
+	)
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	register := func(name string) string {
+		w := doJSON(engine, http.MethodPost, "/users", `{"name": "`+name+`"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var registered UserRegistrationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+		return registered.Token
+	}
+	owner := register("owner")
+	other := register("other")
+
+	for _, body := range []string{
+		`{"serial_number": "0000000001", "type": "adc", "description": "t"}`,
+		`{"serial_number": "0000000002", "type": "cc", "description": "t"}`,
+	} {
+		w := doAuthJSON(engine, http.MethodPost, "/sensors", body, owner)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	t.Run("events_limited_by_sensor_type", func(t *testing.T) {
+		ccEvent := `{"sensor_serial_number": "0000000002", "payload": 1}`
+		w := doAuthJSON(engine, http.MethodPost, "/events", ccEvent, owner)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/events", ccEvent, owner)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+		adcEvent := `{"sensor_serial_number": "0000000001", "payload": 1}`
+		for i := 0; i < 3; i++ {
+			w = doAuthJSON(engine, http.MethodPost, "/events", adcEvent, owner)
+			require.Equal(t, http.StatusCreated, w.Code)
+		}
+		w = doAuthJSON(engine, http.MethodPost, "/events", adcEvent, owner)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("reads_limited_by_user", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			w := doAuthJSON(engine, http.MethodGet, "/sensors", "", other)
+			require.Equal(t, http.StatusOK, w.Code)
+		}
+
+		w := doAuthJSON(engine, http.MethodGet, "/sensors", "", other)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+		w = doAuthJSON(engine, http.MethodHead, "/sensors", "", other)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Empty(t, w.Body.String())
+
+		w = doAuthJSON(engine, http.MethodPost, "/homes", `{"name": "Дача"}`, other)
+		assert.Equal(t, http.StatusOK, w.Code, "writes are not limited")
+
+		w = doAuthJSON(engine, http.MethodGet, "/sensors", "", owner)
+		assert.Equal(t, http.StatusOK, w.Code, "users have separate buckets")
+	})
+}
+
+func TestSignedEventRateLimits(t *testing.T) {
+	uc, sr, _ := newInmemoryUseCases(t, withDeviceAuth())
+
+	now := time.Now()
+	uc.RateLimiter = usecase.NewRateLimiter(rateLimitInmemory.NewRateLimitStore(), sr,
+		usecase.WithSensorRateLimit(domain.RateLimit{Rate: 0.5, Burst: 1}),
+		usecase.WithClientRateLimit(domain.RateLimit{Rate: 0.25, Burst: 4}),
+		usecase.WithRateLimiterClock(func() time.Time { return now }),
+	)
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	w := doJSON(engine, http.MethodPost, "/users", `{"name": "owner"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var registered UserRegistrationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+
+	w = doAuthJSON(engine, http.MethodPost, "/sensors", `{"serial_number": "0000000001", "type": "adc", "description": "t"}`, registered.Token)
+	require.Equal(t, http.StatusOK, w.Code)
+	var sensor SensorRegistrationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensor))
+
+	eventBody := `{"sensor_serial_number": "0000000001", "payload": 1}`
+
+	w = doSignedEvent(engine, eventBody, "forged", "n1", now.Unix())
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	w = doSignedEvent(engine, eventBody, "forged", "n2", now.Unix())
+	require.Equal(t, http.StatusUnauthorized, w.Code, "forged requests don't use up the sensor limit")
+
+	w = doSignedEvent(engine, eventBody, sensor.Secret, "n3", now.Unix())
+	require.Equal(t, http.StatusCreated, w.Code)
+	w = doSignedEvent(engine, eventBody, sensor.Secret, "n4", now.Unix())
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "signed requests use the sensor limit")
+
+	w = doSignedEvent(engine, eventBody, sensor.Secret, "n5", now.Unix())
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "the client limit is checked before the signature")
+	assert.Equal(t, "4", w.Header().Get("Retry-After"))
+}
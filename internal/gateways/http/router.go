@@ -3,6 +3,7 @@ package http
 import (
 	"encoding/json"
 	"errors"
+	"homework/internal/domain"
 	"homework/internal/usecase"
 	"net/http"
 	"strconv"
@@ -21,44 +22,168 @@ func setupRouter(r *gin.Engine, uc UseCases, ws *WebSocketHandler) {
 	})
 
 	r.NoMethod(func(c *gin.Context) {
-		var allowedMethods string
-		path := c.Request.URL.Path
-
-		switch path {
-		case "/events":
-			allowedMethods = "POST,OPTIONS"
-		case "/sensors":
-			allowedMethods = "GET,HEAD,POST,OPTIONS"
-		case "/users":
-			allowedMethods = "POST,OPTIONS"
-		default:
-			if strings.HasPrefix(path, "/sensors/") {
-				allowedMethods = "GET,HEAD,OPTIONS"
-			} else if strings.HasPrefix(path, "/users/") && strings.HasSuffix(path, "/sensors") {
-				allowedMethods = "GET,HEAD,POST,OPTIONS"
-			}
-		}
-
-		if allowedMethods != "" {
-			c.Header("Allow", allowedMethods)
+		if methods := allowedMethods(c.Request.URL.Path); methods != "" {
+			c.Header("Allow", methods)
 		}
 
 		c.JSON(http.StatusMethodNotAllowed, ErrorResponse{Reason: "Method Not Allowed"})
 	})
 
+	r.Use(requestInfoMiddleware())
+
+	if uc.Auth != nil {
+		public := publicRoutes
+		if uc.DeviceAuth != nil {
+			// датчики подтверждают себя подписью события, а не токеном пользователя
+			public = withPublicRoute(public, http.MethodPost+" /events")
+			public = withPublicRoute(public, http.MethodGet+" /devices/:serial_number/commands")
+			public = withPublicRoute(public, http.MethodPost+" /devices/:serial_number/commands/:command_id/ack")
+		}
+		r.Use(authMiddleware(uc.Auth, public))
+		setupTokensRoutes(r, uc)
+	}
+
+	if uc.RateLimiter != nil {
+		r.Use(readRateLimitMiddleware(uc.RateLimiter))
+	}
+
 	setupEventsRoutes(r, uc)
 	setupSensorsRoutes(r, uc, ws)
 	setupUsersRoutes(r, uc)
+	setupHomesRoutes(r, uc)
+	setupRoomsRoutes(r, uc)
+	setupGroupsRoutes(r, uc)
+	setupInvitationsRoutes(r, uc)
+	setupAuditRoutes(r, uc)
+	setupRulesRoutes(r, uc)
+	setupAlertsRoutes(r, uc, ws)
+	setupWebhooksRoutes(r, uc)
+	setupActuatorsRoutes(r, uc)
+	setupCommandsRoutes(r, uc)
+	setupDeviceCommandsRoutes(r, uc)
+	setupAutomationsRoutes(r, uc)
+	setupScenesRoutes(r, uc)
+	setupSchedulesRoutes(r, uc)
 
 	r.GET("/ping", func(c *gin.Context) {
 		c.String(http.StatusOK, "pong")
 	})
 }
 
+// routeMethods - разрешенные методы для шаблонов путей, используются в заголовке Allow ответа 405.
+// Сегмент, начинающийся с ":", соответствует любому значению.
+var routeMethods = []struct {
+	pattern string
+	methods string
+}{
+	{"/events", "POST,OPTIONS"},
+	{"/sensors", "GET,HEAD,POST,OPTIONS"},
+	{"/sensors/import", "POST,OPTIONS"},
+	{"/sensors/inventory", "GET,HEAD,OPTIONS"},
+	{"/sensors/:sensor_id", "GET,HEAD,OPTIONS"},
+	{"/sensors/:sensor_id/events", "GET"},
+	{"/sensors/:sensor_id/history", "GET,OPTIONS"},
+	{"/sensors/:sensor_id/calibration", "GET,HEAD,PUT,DELETE,OPTIONS"},
+	{"/sensors/:sensor_id/anomaly-detection", "GET,HEAD,PUT,DELETE,OPTIONS"},
+	{"/sensors/:sensor_id/debounce", "GET,HEAD,PUT,DELETE,OPTIONS"},
+	{"/sensors/:sensor_id/virtual", "GET,HEAD,PUT,DELETE,OPTIONS"},
+	{"/sensors/:sensor_id/info", "POST,OPTIONS"},
+	{"/sensors/:sensor_id/firmware", "GET,OPTIONS"},
+	{"/sensors/:sensor_id/secret", "POST,OPTIONS"},
+	{"/sensors/:sensor_id/users", "GET,HEAD,OPTIONS"},
+	{"/sensors/:sensor_id/invitations", "GET,HEAD,POST,OPTIONS"},
+	{"/sensors/:sensor_id/invitations/:invitation_id", "DELETE,OPTIONS"},
+	{"/users", "GET,POST,OPTIONS"},
+	{"/users/:user_id", "GET,HEAD,PATCH,DELETE,OPTIONS"},
+	{"/tokens", "GET,POST,OPTIONS"},
+	{"/tokens/:token_id", "DELETE,OPTIONS"},
+	{"/users/:user_id/sensors", "GET,HEAD,POST,OPTIONS"},
+	{"/users/:user_id/sensors/:sensor_id", "PUT,DELETE,OPTIONS"},
+	{"/users/:user_id/homes", "GET,HEAD,POST,OPTIONS"},
+	{"/homes", "GET,HEAD,POST,OPTIONS"},
+	{"/homes/:home_id", "GET,HEAD,PATCH,DELETE,OPTIONS"},
+	{"/homes/:home_id/rooms", "GET,HEAD,POST,OPTIONS"},
+	{"/homes/:home_id/sensors", "GET,HEAD,OPTIONS"},
+	{"/rooms/:room_id", "GET,HEAD,PATCH,DELETE,OPTIONS"},
+	{"/rooms/:room_id/sensors", "GET,HEAD,POST,OPTIONS"},
+	{"/groups", "GET,HEAD,POST,OPTIONS"},
+	{"/groups/:group_id", "GET,HEAD,DELETE,OPTIONS"},
+	{"/groups/:group_id/members", "GET,HEAD,POST,OPTIONS"},
+	{"/groups/:group_id/members/:user_id", "DELETE,OPTIONS"},
+	{"/groups/:group_id/sensors", "GET,HEAD,POST,OPTIONS"},
+	{"/groups/:group_id/sensors/:sensor_id", "DELETE,OPTIONS"},
+	{"/invitations/:token/accept", "POST,OPTIONS"},
+	{"/audit", "GET,HEAD,OPTIONS"},
+	{"/rules", "GET,HEAD,POST,OPTIONS"},
+	{"/rules/:rule_id", "GET,HEAD,PUT,DELETE,OPTIONS"},
+	{"/alerts", "GET,HEAD,OPTIONS"},
+	{"/alerts/stream", "GET"},
+	{"/alerts/:alert_id", "GET,HEAD,OPTIONS"},
+	{"/alerts/:alert_id/ack", "POST,OPTIONS"},
+	{"/alerts/:alert_id/resolve", "POST,OPTIONS"},
+	{"/webhooks", "GET,HEAD,POST,OPTIONS"},
+	{"/webhooks/:webhook_id", "GET,HEAD,PUT,DELETE,OPTIONS"},
+	{"/webhooks/:webhook_id/deliveries", "GET,HEAD,OPTIONS"},
+	{"/webhooks/:webhook_id/test", "POST,OPTIONS"},
+	{"/actuators", "GET,HEAD,POST,OPTIONS"},
+	{"/actuators/:actuator_id", "GET,HEAD,PUT,DELETE,OPTIONS"},
+	{"/actuators/:actuator_id/commands", "GET,HEAD,POST,OPTIONS"},
+	{"/commands/:command_id", "GET,HEAD,OPTIONS"},
+	{"/devices/:serial_number/commands", "GET,OPTIONS"},
+	{"/devices/:serial_number/commands/:command_id/ack", "POST,OPTIONS"},
+	{"/automations", "GET,HEAD,POST,OPTIONS"},
+	{"/automations/:automation_id", "GET,HEAD,PUT,DELETE,OPTIONS"},
+	{"/automations/:automation_id/runs", "GET,HEAD,OPTIONS"},
+	{"/scenes", "GET,HEAD,POST,OPTIONS"},
+	{"/scenes/:scene_id", "GET,HEAD,PUT,DELETE,OPTIONS"},
+	{"/scenes/:scene_id/run", "POST,OPTIONS"},
+	{"/scenes/:scene_id/runs", "GET,HEAD,OPTIONS"},
+	{"/schedules", "GET,HEAD,POST,OPTIONS"},
+	{"/schedules/:schedule_id", "GET,HEAD,PUT,DELETE,OPTIONS"},
+	{"/schedules/:schedule_id/runs", "GET,HEAD,OPTIONS"},
+}
+
+func allowedMethods(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for _, route := range routeMethods {
+		patternSegments := strings.Split(strings.Trim(route.pattern, "/"), "/")
+		if len(patternSegments) != len(segments) {
+			continue
+		}
+
+		matched := true
+		for i, segment := range patternSegments {
+			if !strings.HasPrefix(segment, ":") && segment != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return route.methods
+		}
+	}
+
+	return ""
+}
+
 func setupEventsRoutes(r *gin.Engine, uc UseCases) {
 	eventsGroup := r.Group("/events")
 	{
-		eventsGroup.POST("", func(c *gin.Context) {
+		// ограничение датчика расходуется только подписанными им запросами, иначе любой, кто знает серийный номер,
+		// исчерпал бы его чужими запросами; поток неподписанных запросов отсекает ограничение по адресу
+		var handlers []gin.HandlerFunc
+		if uc.RateLimiter != nil && uc.DeviceAuth != nil {
+			handlers = append(handlers, eventClientRateLimitMiddleware(uc.RateLimiter))
+		}
+		if uc.DeviceAuth != nil {
+			handlers = append(handlers, eventSignatureMiddleware(uc.DeviceAuth))
+		}
+		if uc.RateLimiter != nil {
+			handlers = append(handlers, eventRateLimitMiddleware(uc.RateLimiter))
+		}
+
+		eventsGroup.POST("", append(handlers, func(c *gin.Context) {
 			if !checkContentTypeJSON(c) {
 				return
 			}
@@ -69,7 +194,7 @@ func setupEventsRoutes(r *gin.Engine, uc UseCases) {
 				return
 			}
 
-			if eventReq.SensorSerialNumber == "" || len(eventReq.SensorSerialNumber) != 10 || !isNumeric(eventReq.SensorSerialNumber) {
+			if err := uc.Sensor.ValidateSerialNumber(eventReq.SensorSerialNumber); err != nil {
 				c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Reason: "Invalid sensor serial number"})
 				return
 			}
@@ -82,7 +207,7 @@ func setupEventsRoutes(r *gin.Engine, uc UseCases) {
 			}
 
 			c.Status(http.StatusCreated)
-		})
+		})...)
 
 		eventsGroup.OPTIONS("", func(c *gin.Context) {
 			setAllowHeader(c, "POST,OPTIONS")
@@ -133,7 +258,7 @@ func setupSensorsRoutes(r *gin.Engine, uc UseCases, ws *WebSocketHandler) {
 				return
 			}
 
-			if !validateSensorData(c, sensorCreate) {
+			if !validateSensorData(c, uc.Sensor, sensorCreate) {
 				return
 			}
 
@@ -144,13 +269,20 @@ func setupSensorsRoutes(r *gin.Engine, uc UseCases, ws *WebSocketHandler) {
 				return
 			}
 
-			c.JSON(http.StatusOK, sensorToResponse(result))
+			secret, ok := provisionSensorSecret(c, uc, result.ID)
+			if !ok {
+				return
+			}
+
+			c.JSON(http.StatusOK, SensorRegistrationResponse{SensorResponse: sensorToResponse(result), Secret: secret})
 		})
 
 		sensorsGroup.OPTIONS("", func(c *gin.Context) {
 			setAllowHeader(c, "GET,HEAD,POST,OPTIONS")
 		})
 
+		setupSensorImportRoutes(sensorsGroup, uc)
+		setupSensorInventoryRoutes(sensorsGroup, uc)
 		setupSensorByIDRoutes(sensorsGroup, uc, ws)
 	}
 }
@@ -258,7 +390,7 @@ func setupSensorByIDRoutes(rg *gin.RouterGroup, uc UseCases, ws *WebSocketHandle
 
 		metadata := SensorHistoryMetadata{
 			RequestTime:     time.Now().Format("2006-01-02 15:04:05"),
-			RequestedByUser: "VolodyaPopov923",
+			RequestedByUser: callerName(c),
 		}
 
 		c.JSON(http.StatusOK, eventsToHistoryResponse(events, metadata))
@@ -270,6 +402,110 @@ func setupSensorByIDRoutes(rg *gin.RouterGroup, uc UseCases, ws *WebSocketHandle
 	rg.OPTIONS("/:sensor_id/history", func(c *gin.Context) {
 		setAllowHeader(c, "GET,OPTIONS")
 	})
+
+	setupSensorCalibrationRoutes(rg, uc)
+	setupSensorAnomalyDetectionRoutes(rg, uc)
+	setupSensorDebounceRoutes(rg, uc)
+	setupSensorVirtualRoutes(rg, uc)
+	setupSensorDeviceRoutes(rg, uc)
+	setupSensorUsersRoutes(rg, uc)
+	setupSensorInvitationsRoutes(rg, uc)
+	if uc.DeviceAuth != nil {
+		setupSensorSecretRoutes(rg, uc)
+	}
+}
+
+func setupSensorCalibrationRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:sensor_id/calibration", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		sensor, err := uc.Sensor.GetSensorByID(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+		if sensor.Calibration == nil {
+			c.JSON(http.StatusNotFound, ErrorResponse{Reason: "calibration not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, calibrationToResponse(sensor.Calibration))
+	})
+
+	rg.HEAD("/:sensor_id/calibration", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("sensor_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		sensor, err := uc.Sensor.GetSensorByID(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+		if sensor.Calibration == nil {
+			c.Status(http.StatusNotFound)
+			return
+		}
+
+		setContentLength(c, calibrationToResponse(sensor.Calibration))
+		c.Status(http.StatusOK)
+	})
+
+	rg.PUT("/:sensor_id/calibration", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		var calibrationReq CalibrationRequest
+		if err := c.ShouldBindJSON(&calibrationReq); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		sensor, err := uc.Sensor.SetSensorCalibration(c.Request.Context(), id, calibrationToDomain(&calibrationReq))
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, sensorToResponse(sensor))
+	})
+
+	rg.DELETE("/:sensor_id/calibration", func(c *gin.Context) {
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		if _, err := uc.Sensor.SetSensorCalibration(c.Request.Context(), id, nil); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	rg.OPTIONS("/:sensor_id/calibration", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,PUT,DELETE,OPTIONS")
+	})
 }
 
 func setupUsersRoutes(r *gin.Engine, uc UseCases) {
@@ -298,17 +534,136 @@ func setupUsersRoutes(r *gin.Engine, uc UseCases) {
 				return
 			}
 
-			c.JSON(http.StatusOK, userToResponse(result))
+			response := UserRegistrationResponse{UserResponse: userToResponse(result)}
+			if uc.Auth != nil {
+				_, token, err := uc.Auth.CreateToken(c.Request.Context(), result.ID, initialTokenName, 0)
+				if err != nil {
+					handleError(c, err)
+					return
+				}
+				response.Token = token
+			}
+
+			c.JSON(http.StatusOK, response)
+		})
+
+		usersGroup.GET("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			limit, offset, err := parsePagination(c)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			users, err := uc.User.GetUsers(c.Request.Context(), limit, offset)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, usersToResponse(users))
 		})
 
 		usersGroup.OPTIONS("", func(c *gin.Context) {
-			setAllowHeader(c, "POST,OPTIONS")
+			setAllowHeader(c, "GET,POST,OPTIONS")
 		})
 
+		setupUserByIDRoutes(usersGroup, uc)
 		setupUserSensorsRoutes(usersGroup, uc)
+		setupUserHomesRoutes(usersGroup, uc)
 	}
 }
 
+func setupUserByIDRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:user_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "user_id", "Invalid user ID")
+		if !ok {
+			return
+		}
+
+		user, err := uc.User.GetUserByID(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, userToResponse(user))
+	})
+
+	rg.HEAD("/:user_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		user, err := uc.User.GetUserByID(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, userToResponse(user))
+		c.Status(http.StatusOK)
+	})
+
+	rg.PATCH("/:user_id", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "user_id", "Invalid user ID")
+		if !ok {
+			return
+		}
+
+		var userReq UserCreateRequest
+		if err := c.ShouldBindJSON(&userReq); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		user := userToDomain(userReq)
+		user.ID = id
+		result, err := uc.User.UpdateUser(c.Request.Context(), user)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, userToResponse(result))
+	})
+
+	rg.DELETE("/:user_id", func(c *gin.Context) {
+		id, ok := parseIDParam(c, "user_id", "Invalid user ID")
+		if !ok {
+			return
+		}
+
+		if err := uc.User.DeleteUser(c.Request.Context(), id); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	rg.OPTIONS("/:user_id", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,PATCH,DELETE,OPTIONS")
+	})
+}
+
 func setupUserSensorsRoutes(rg *gin.RouterGroup, uc UseCases) {
 	userSensorsGroup := rg.Group("/:user_id/sensors")
 	{
@@ -382,7 +737,12 @@ func setupUserSensorsRoutes(rg *gin.RouterGroup, uc UseCases) {
 				return
 			}
 
-			err = uc.User.AttachSensorToUser(c.Request.Context(), id, binding.SensorID)
+			role := domain.SensorRoleOwner
+			if binding.Role != "" {
+				role = domain.SensorRole(binding.Role)
+			}
+
+			err = uc.User.ShareSensor(c.Request.Context(), id, binding.SensorID, role)
 			if err != nil {
 				if errors.Is(err, usecase.ErrUserNotFound) || strings.Contains(err.Error(), "user not found") {
 					c.JSON(http.StatusNotFound, ErrorResponse{Reason: "User not found"})
@@ -398,9 +758,106 @@ func setupUserSensorsRoutes(rg *gin.RouterGroup, uc UseCases) {
 		userSensorsGroup.OPTIONS("", func(c *gin.Context) {
 			setAllowHeader(c, "GET,HEAD,POST,OPTIONS")
 		})
+
+		userSensorsGroup.PUT("/:sensor_id", func(c *gin.Context) {
+			if !checkContentTypeJSON(c) {
+				return
+			}
+
+			userID, ok := parseIDParam(c, "user_id", "Invalid user ID")
+			if !ok {
+				return
+			}
+			sensorID, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+			if !ok {
+				return
+			}
+
+			var roleReq SensorRoleRequest
+			if err := c.ShouldBindJSON(&roleReq); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+				return
+			}
+
+			sensorOwner, err := uc.User.SetSensorRole(c.Request.Context(), userID, sensorID, domain.SensorRole(roleReq.Role))
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, sensorOwnerToResponse(*sensorOwner))
+		})
+
+		userSensorsGroup.DELETE("/:sensor_id", func(c *gin.Context) {
+			userID, ok := parseIDParam(c, "user_id", "Invalid user ID")
+			if !ok {
+				return
+			}
+			sensorID, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+			if !ok {
+				return
+			}
+
+			if err := uc.User.DetachSensorFromUser(c.Request.Context(), userID, sensorID); err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.Status(http.StatusNoContent)
+		})
+
+		userSensorsGroup.OPTIONS("/:sensor_id", func(c *gin.Context) {
+			setAllowHeader(c, "PUT,DELETE,OPTIONS")
+		})
 	}
 }
 
+func setupSensorUsersRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:sensor_id/users", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		sensorOwners, err := uc.User.GetSensorUsers(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, sensorOwnersToResponse(sensorOwners))
+	})
+
+	rg.HEAD("/:sensor_id/users", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("sensor_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		sensorOwners, err := uc.User.GetSensorUsers(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, sensorOwnersToResponse(sensorOwners))
+		c.Status(http.StatusOK)
+	})
+
+	rg.OPTIONS("/:sensor_id/users", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,OPTIONS")
+	})
+}
+
 func checkContentTypeJSON(c *gin.Context) bool {
 	contentType := c.Request.Header.Get("Content-Type")
 	if !strings.Contains(contentType, "application/json") {
@@ -419,8 +876,8 @@ func checkAcceptJSON(c *gin.Context) bool {
 	return true
 }
 
-func validateSensorData(c *gin.Context, sensor SensorCreateRequest) bool {
-	if sensor.SerialNumber == "" || len(sensor.SerialNumber) != 10 || !isNumeric(sensor.SerialNumber) {
+func validateSensorData(c *gin.Context, sensorUC *usecase.Sensor, sensor SensorCreateRequest) bool {
+	if err := sensorUC.ValidateSerialNumber(sensor.SerialNumber); err != nil {
 		c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Reason: "Invalid serial number"})
 		return false
 	}
@@ -431,6 +888,26 @@ func validateSensorData(c *gin.Context, sensor SensorCreateRequest) bool {
 	return true
 }
 
+func parseIDParam(c *gin.Context, param, reason string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Reason: reason})
+		return 0, false
+	}
+	return id, true
+}
+
+// parsePagination читает параметры limit и offset, отсутствующий параметр считается равным 0
+func parsePagination(c *gin.Context) (limit, offset int, err error) {
+	if limit, err = strconv.Atoi(c.DefaultQuery("limit", "0")); err != nil {
+		return 0, 0, usecase.ErrInvalidPagination
+	}
+	if offset, err = strconv.Atoi(c.DefaultQuery("offset", "0")); err != nil {
+		return 0, 0, usecase.ErrInvalidPagination
+	}
+	return limit, offset, nil
+}
+
 func setAllowHeader(c *gin.Context, methods string) {
 	c.Header("Allow", methods)
 	c.Status(http.StatusNoContent)
@@ -441,15 +918,6 @@ func setContentLength(c *gin.Context, data interface{}) {
 	c.Header("Content-Length", strconv.Itoa(len(jsonData)))
 }
 
-func isNumeric(s string) bool {
-	for _, c := range s {
-		if c < '0' || c > '9' {
-			return false
-		}
-	}
-	return true
-}
-
 func isAcceptableType(accept string) bool {
 	if accept == "" {
 		return true
@@ -457,15 +925,57 @@ func isAcceptableType(accept string) bool {
 	return strings.Contains(accept, "*/*") || strings.Contains(accept, "application/json")
 }
 
+func isValidationError(err error) bool {
+	return errors.Is(err, usecase.ErrWrongSensorSerialNumber) ||
+		errors.Is(err, usecase.ErrWrongSensorType) ||
+		errors.Is(err, usecase.ErrInvalidUserName) ||
+		errors.Is(err, usecase.ErrInvalidEventTimestamp) ||
+		errors.Is(err, usecase.ErrInvalidHomeName) ||
+		errors.Is(err, usecase.ErrInvalidHomeLocation) ||
+		errors.Is(err, usecase.ErrInvalidRoomName) ||
+		errors.Is(err, usecase.ErrInvalidCalibration) ||
+		errors.Is(err, usecase.ErrInvalidAnomalyDetection) ||
+		errors.Is(err, usecase.ErrInvalidDebounce) ||
+		errors.Is(err, usecase.ErrInvalidVirtualSensor) ||
+		errors.Is(err, usecase.ErrInvalidSensorLabels) ||
+		errors.Is(err, usecase.ErrVirtualSensorEvent) ||
+		errors.Is(err, usecase.ErrInvalidDeviceInfo) ||
+		errors.Is(err, usecase.ErrInvalidTokenName) ||
+		errors.Is(err, usecase.ErrInvalidTokenTTL) ||
+		errors.Is(err, usecase.ErrInvalidSensorRole) ||
+		errors.Is(err, usecase.ErrInvalidHomeRole) ||
+		errors.Is(err, usecase.ErrInvalidPagination) ||
+		errors.Is(err, usecase.ErrInvalidInvitation) ||
+		errors.Is(err, usecase.ErrInvalidAuditFilter) ||
+		errors.Is(err, usecase.ErrInvalidRule) ||
+		errors.Is(err, usecase.ErrInvalidAlertFilter) ||
+		errors.Is(err, usecase.ErrInvalidWebhook) ||
+		errors.Is(err, usecase.ErrInvalidDeliveryFilter) ||
+		errors.Is(err, usecase.ErrInvalidActuator) ||
+		errors.Is(err, usecase.ErrInvalidCommand) ||
+		errors.Is(err, usecase.ErrInvalidCommandFilter) ||
+		errors.Is(err, usecase.ErrInvalidAutomation) ||
+		errors.Is(err, usecase.ErrInvalidScene) ||
+		errors.Is(err, usecase.ErrInvalidRunFilter) ||
+		errors.Is(err, usecase.ErrInvalidSchedule)
+}
+
 func handleError(c *gin.Context, err error) {
 	switch {
 	case errors.Is(err, usecase.ErrSensorNotFound) || errors.Is(err, usecase.ErrUserNotFound) || strings.Contains(err.Error(), "not found"):
 		c.JSON(http.StatusNotFound, ErrorResponse{Reason: err.Error()})
-	case errors.Is(err, usecase.ErrWrongSensorSerialNumber) ||
-		errors.Is(err, usecase.ErrWrongSensorType) ||
-		errors.Is(err, usecase.ErrInvalidUserName) ||
-		errors.Is(err, usecase.ErrInvalidEventTimestamp):
-
+	case errors.Is(err, usecase.ErrSensorAlreadyExists) || errors.Is(err, usecase.ErrLastSensorOwner) ||
+		errors.Is(err, usecase.ErrUserNameTaken) || errors.Is(err, usecase.ErrAlertResolved) ||
+		errors.Is(err, usecase.ErrCommandCompleted):
+		c.JSON(http.StatusConflict, ErrorResponse{Reason: err.Error()})
+	case errors.Is(err, usecase.ErrInsufficientRole) || errors.Is(err, usecase.ErrInsufficientHomeRole):
+		c.JSON(http.StatusForbidden, ErrorResponse{Reason: err.Error()})
+	case errors.Is(err, usecase.ErrInvitationExpired) || errors.Is(err, usecase.ErrCommandExpired):
+		c.JSON(http.StatusGone, ErrorResponse{Reason: err.Error()})
+	case errors.Is(err, usecase.ErrRateLimited):
+		setRetryAfter(c, err)
+		c.JSON(http.StatusTooManyRequests, ErrorResponse{Reason: err.Error()})
+	case isValidationError(err):
 		c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Reason: err.Error()})
 	default:
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Reason: "Internal server error"})
@@ -476,10 +986,18 @@ func handleStatusOnlyError(c *gin.Context, err error) {
 	switch {
 	case errors.Is(err, usecase.ErrSensorNotFound) || errors.Is(err, usecase.ErrUserNotFound) || strings.Contains(err.Error(), "not found"):
 		c.Status(http.StatusNotFound)
-	case errors.Is(err, usecase.ErrWrongSensorSerialNumber) ||
-		errors.Is(err, usecase.ErrWrongSensorType) ||
-		errors.Is(err, usecase.ErrInvalidUserName) ||
-		errors.Is(err, usecase.ErrInvalidEventTimestamp):
+	case errors.Is(err, usecase.ErrSensorAlreadyExists) || errors.Is(err, usecase.ErrLastSensorOwner) ||
+		errors.Is(err, usecase.ErrUserNameTaken) || errors.Is(err, usecase.ErrAlertResolved) ||
+		errors.Is(err, usecase.ErrCommandCompleted):
+		c.Status(http.StatusConflict)
+	case errors.Is(err, usecase.ErrInsufficientRole) || errors.Is(err, usecase.ErrInsufficientHomeRole):
+		c.Status(http.StatusForbidden)
+	case errors.Is(err, usecase.ErrInvitationExpired) || errors.Is(err, usecase.ErrCommandExpired):
+		c.Status(http.StatusGone)
+	case errors.Is(err, usecase.ErrRateLimited):
+		setRetryAfter(c, err)
+		c.Status(http.StatusTooManyRequests)
+	case isValidationError(err):
 		c.Status(http.StatusUnprocessableEntity)
 	default:
 		c.Status(http.StatusInternalServerError)
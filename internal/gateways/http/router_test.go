@@ -145,7 +145,6 @@ func TestUsersRoutes(t *testing.T) {
 			input string
 			want  int
 		}{
-			{http.MethodGet, http.MethodGet, http.StatusMethodNotAllowed},
 			{http.MethodPut, http.MethodPut, http.StatusMethodNotAllowed},
 			{http.MethodPut, http.MethodPut, http.StatusMethodNotAllowed},
 			{http.MethodHead, http.MethodHead, http.StatusMethodNotAllowed},
@@ -0,0 +1,182 @@
+package http
+
+import (
+	"homework/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseRuleSensorID разбирает необязательный фильтр sensor_id, 0 - правила всех датчиков
+func parseRuleSensorID(c *gin.Context) (int64, error) {
+	sensorID, err := strconv.ParseInt(c.DefaultQuery("sensor_id", "0"), 10, 64)
+	if err != nil || sensorID < 0 {
+		return 0, usecase.ErrInvalidRule
+	}
+	return sensorID, nil
+}
+
+func setupRulesRoutes(r *gin.Engine, uc UseCases) {
+	rulesGroup := r.Group("/rules")
+	{
+		rulesGroup.GET("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			sensorID, err := parseRuleSensorID(c)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			rules, err := uc.Rule.GetRules(c.Request.Context(), sensorID)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, rulesToResponse(rules))
+		})
+
+		rulesGroup.HEAD("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			sensorID, err := parseRuleSensorID(c)
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			rules, err := uc.Rule.GetRules(c.Request.Context(), sensorID)
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			setContentLength(c, rulesToResponse(rules))
+			c.Status(http.StatusOK)
+		})
+
+		rulesGroup.POST("", func(c *gin.Context) {
+			if !checkContentTypeJSON(c) {
+				return
+			}
+
+			var ruleReq RuleRequest
+			if err := c.ShouldBindJSON(&ruleReq); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+				return
+			}
+
+			if ruleReq.SensorID <= 0 {
+				c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Reason: "Invalid sensor ID"})
+				return
+			}
+
+			result, err := uc.Rule.CreateRule(c.Request.Context(), ruleToDomain(ruleReq))
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusCreated, ruleToResponse(result))
+		})
+
+		rulesGroup.OPTIONS("", func(c *gin.Context) {
+			setAllowHeader(c, "GET,HEAD,POST,OPTIONS")
+		})
+
+		setupRuleByIDRoutes(rulesGroup, uc)
+	}
+}
+
+func setupRuleByIDRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:rule_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "rule_id", "Invalid rule ID")
+		if !ok {
+			return
+		}
+
+		rule, err := uc.Rule.GetRuleByID(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, ruleToResponse(rule))
+	})
+
+	rg.HEAD("/:rule_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("rule_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		rule, err := uc.Rule.GetRuleByID(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, ruleToResponse(rule))
+		c.Status(http.StatusOK)
+	})
+
+	rg.PUT("/:rule_id", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "rule_id", "Invalid rule ID")
+		if !ok {
+			return
+		}
+
+		var ruleReq RuleRequest
+		if err := c.ShouldBindJSON(&ruleReq); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		rule := ruleToDomain(ruleReq)
+		rule.ID = id
+		result, err := uc.Rule.UpdateRule(c.Request.Context(), rule)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, ruleToResponse(result))
+	})
+
+	rg.DELETE("/:rule_id", func(c *gin.Context) {
+		id, ok := parseIDParam(c, "rule_id", "Invalid rule ID")
+		if !ok {
+			return
+		}
+
+		if err := uc.Rule.DeleteRule(c.Request.Context(), id); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	rg.OPTIONS("/:rule_id", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,PUT,DELETE,OPTIONS")
+	})
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRules(t *testing.T) {
+	uc, _, _ := newInmemoryUseCases(t, withAuth())
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	register := func(name string) string {
+		w := doJSON(engine, http.MethodPost, "/users", `{"name": "`+name+`"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var registered UserRegistrationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+		return registered.Token
+	}
+	owner := register("owner")
+	stranger := register("stranger")
+
+	w := doAuthJSON(engine, http.MethodPost, "/sensors",
+		`{"serial_number": "0000000001", "type": "cc", "description": "door"}`, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var rule RuleResponse
+	t.Run("POST_rules_201", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/rules",
+			`{"sensor_id": 1, "name": "Дверь открыта", "operator": "==", "threshold": 1}`, owner)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rule))
+		assert.True(t, rule.Enabled)
+		assert.Nil(t, rule.Window)
+	})
+
+	t.Run("POST_rules_invalid_422", func(t *testing.T) {
+		for _, body := range []string{
+			`{"sensor_id": 0, "name": "r", "operator": "=="}`,
+			`{"sensor_id": 1, "name": "r", "operator": "~"}`,
+			`{"sensor_id": 1, "name": "r", "operator": "==", "duration_seconds": -1}`,
+			`{"sensor_id": 1, "name": "r", "operator": "==", "window": {"from": "25:00", "to": "06:00"}}`,
+			`{"sensor_id": 1, "name": "r", "operator": "==", "window": {"from": "23:00", "to": "06:00", "time_zone": "Nowhere"}}`,
+		} {
+			w := doAuthJSON(engine, http.MethodPost, "/rules", body, owner)
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code, body)
+		}
+	})
+
+	t.Run("stranger_does_not_see_rules", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/rules", `{"sensor_id": 1, "name": "r", "operator": "=="}`, stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/rules/"+strconv.FormatInt(rule.ID, 10), "", stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/rules", "", stranger)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+	})
+
+	t.Run("PUT_rules_rule_id_200", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPut, "/rules/"+strconv.FormatInt(rule.ID, 10),
+			`{"sensor_id": 42, "name": "Ночью", "operator": "==", "threshold": 1, "duration_seconds": 300,
+			"window": {"from": "23:00", "to": "06:00", "time_zone": "Europe/Moscow"}, "enabled": false}`, owner)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var updated RuleResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+		assert.Equal(t, int64(1), updated.SensorID, "sensor of a rule can't be changed")
+		assert.Equal(t, &RuleWindowResponse{From: "23:00", To: "06:00", TimeZone: "Europe/Moscow"}, updated.Window)
+		assert.Equal(t, int64(300), updated.DurationSeconds)
+		assert.False(t, updated.Enabled)
+
+		w = doAuthJSON(engine, http.MethodGet, "/rules?sensor_id=1", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		var rules []RuleResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rules))
+		require.Len(t, rules, 1)
+		assert.Equal(t, "Ночью", rules[0].Name)
+	})
+
+	t.Run("DELETE_rules_rule_id_204", func(t *testing.T) {
+		path := "/rules/" + strconv.FormatInt(rule.ID, 10)
+		w := doAuthJSON(engine, http.MethodDelete, path, "", owner)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, path, "", owner)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("allow_header", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPatch, "/rules/1", "", owner)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET,HEAD,PUT,DELETE,OPTIONS", w.Header().Get("Allow"))
+
+		w = doAuthJSON(engine, http.MethodGet, "/rules?sensor_id=x", "", owner)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+}
+
+func TestRuleAlerts(t *testing.T) {
+	uc, _, _ := newInmemoryUseCases(t)
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	w := doJSON(engine, http.MethodPost, "/sensors", `{"serial_number": "0000000001", "type": "cc", "description": "door"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = doJSON(engine, http.MethodPost, "/rules", `{"sensor_id": 1, "name": "Дверь открыта", "operator": "==", "threshold": 1}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var rule RuleResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rule))
+
+	for _, payload := range []string{"1", "1", "0", "1"} {
+		w := doJSON(engine, http.MethodPost, "/events", `{"sensor_serial_number": "0000000001", "payload": `+payload+`}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	w = doJSON(engine, http.MethodGet, "/alerts?rule_id="+strconv.FormatInt(rule.ID, 10), "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var alerts []AlertResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &alerts))
+	require.Len(t, alerts, 2, "repeats are counted in one alert until the condition clears")
+	assert.Equal(t, "open", alerts[0].Status)
+	assert.Equal(t, 1, alerts[0].Occurrences)
+	assert.Equal(t, "resolved", alerts[1].Status, "cleared condition resolves alert")
+	assert.Equal(t, 2, alerts[1].Occurrences)
+	assert.Zero(t, alerts[1].ResolvedBy)
+}
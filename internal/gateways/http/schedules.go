@@ -0,0 +1,217 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupSchedulesRoutes(r *gin.Engine, uc UseCases) {
+	schedulesGroup := r.Group("/schedules")
+	{
+		schedulesGroup.GET("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			schedules, err := uc.Scheduler.GetSchedules(c.Request.Context())
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, schedulesToResponse(schedules))
+		})
+
+		schedulesGroup.HEAD("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			schedules, err := uc.Scheduler.GetSchedules(c.Request.Context())
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			setContentLength(c, schedulesToResponse(schedules))
+			c.Status(http.StatusOK)
+		})
+
+		schedulesGroup.POST("", func(c *gin.Context) {
+			if !checkContentTypeJSON(c) {
+				return
+			}
+
+			var scheduleReq ScheduleRequest
+			if err := c.ShouldBindJSON(&scheduleReq); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+				return
+			}
+
+			schedule, err := uc.Scheduler.CreateSchedule(c.Request.Context(), scheduleToDomain(scheduleReq))
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusCreated, scheduleToResponse(schedule))
+		})
+
+		schedulesGroup.OPTIONS("", func(c *gin.Context) {
+			setAllowHeader(c, "GET,HEAD,POST,OPTIONS")
+		})
+
+		setupScheduleByIDRoutes(schedulesGroup, uc)
+	}
+}
+
+func setupScheduleByIDRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:schedule_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "schedule_id", "Invalid schedule ID")
+		if !ok {
+			return
+		}
+
+		schedule, err := uc.Scheduler.GetScheduleByID(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, scheduleToResponse(schedule))
+	})
+
+	rg.HEAD("/:schedule_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("schedule_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		schedule, err := uc.Scheduler.GetScheduleByID(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, scheduleToResponse(schedule))
+		c.Status(http.StatusOK)
+	})
+
+	rg.PUT("/:schedule_id", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "schedule_id", "Invalid schedule ID")
+		if !ok {
+			return
+		}
+
+		var scheduleReq ScheduleRequest
+		if err := c.ShouldBindJSON(&scheduleReq); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		schedule := scheduleToDomain(scheduleReq)
+		schedule.ID = id
+		result, err := uc.Scheduler.UpdateSchedule(c.Request.Context(), schedule)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, scheduleToResponse(result))
+	})
+
+	rg.DELETE("/:schedule_id", func(c *gin.Context) {
+		id, ok := parseIDParam(c, "schedule_id", "Invalid schedule ID")
+		if !ok {
+			return
+		}
+
+		if err := uc.Scheduler.DeleteSchedule(c.Request.Context(), id); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	rg.OPTIONS("/:schedule_id", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,PUT,DELETE,OPTIONS")
+	})
+
+	setupScheduleRunsRoutes(rg, uc)
+}
+
+func setupScheduleRunsRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:schedule_id/runs", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "schedule_id", "Invalid schedule ID")
+		if !ok {
+			return
+		}
+
+		filter, err := parseAutomationRunFilter(c)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+		filter.ScheduleID = id
+
+		runs, err := uc.Scheduler.GetScheduleRuns(c.Request.Context(), filter)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, automationRunsToResponse(runs))
+	})
+
+	rg.HEAD("/:schedule_id/runs", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("schedule_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		filter, err := parseAutomationRunFilter(c)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+		filter.ScheduleID = id
+
+		runs, err := uc.Scheduler.GetScheduleRuns(c.Request.Context(), filter)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, automationRunsToResponse(runs))
+		c.Status(http.StatusOK)
+	})
+
+	rg.OPTIONS("/:schedule_id/runs", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,OPTIONS")
+	})
+}
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedules(t *testing.T) {
+	uc, _, _ := newInmemoryUseCases(t, withAuth())
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	register := func(name string) string {
+		w := doJSON(engine, http.MethodPost, "/users", `{"name": "`+name+`"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var registered UserRegistrationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+		return registered.Token
+	}
+	owner := register("owner")
+	stranger := register("stranger")
+
+	w := doAuthJSON(engine, http.MethodPost, "/homes",
+		`{"name": "Дача", "time_zone": "Europe/Moscow", "coordinates": {"latitude": 55.7558, "longitude": 37.6173}}`, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+	var home HomeResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &home))
+	assert.Equal(t, "Europe/Moscow", home.TimeZone)
+	require.NotNil(t, home.Coordinates)
+
+	w = doAuthJSON(engine, http.MethodPost, "/homes", `{"name": "Квартира"}`, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = doAuthJSON(engine, http.MethodPost, "/sensors", `{"serial_number": "0000000001", "type": "cc", "description": "controller"}`, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = doAuthJSON(engine, http.MethodPost, "/actuators", `{"sensor_id": 1, "name": "porch light", "type": "relay"}`, owner)
+	require.Equal(t, http.StatusCreated, w.Code)
+	w = doAuthJSON(engine, http.MethodPost, "/scenes",
+		`{"name": "porch on", "actions": [{"type": "command", "actuator_id": 1, "command": "set_state", "state": 1}]}`, owner)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var schedule ScheduleResponse
+	t.Run("POST_schedules_201", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/schedules", `{
+			"home_id": 1,
+			"name": "weekday mornings",
+			"expression": "0 7 * * mon-fri",
+			"action": {"type": "command", "actuator_id": 1, "command": "set_state", "state": 1},
+			"missed_run_policy": "run_once"
+		}`, owner)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &schedule))
+		assert.True(t, schedule.Enabled)
+		assert.Equal(t, "run_once", schedule.MissedRunPolicy)
+		require.NotNil(t, schedule.NextRunAt)
+		assert.Nil(t, schedule.LastRunAt)
+
+		w = doAuthJSON(engine, http.MethodPost, "/schedules",
+			`{"home_id": 1, "name": "evening", "expression": "sunset+30m", "action": {"type": "scene", "scene_id": 1}}`, owner)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var solar ScheduleResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &solar))
+		assert.Equal(t, "skip", solar.MissedRunPolicy)
+		assert.NotNil(t, solar.NextRunAt)
+	})
+
+	t.Run("POST_schedules_invalid_422", func(t *testing.T) {
+		for _, body := range []string{
+			`{"home_id": 1, "name": "a", "expression": "0 25 * * *", "action": {"type": "scene", "scene_id": 1}}`,
+			`{"home_id": 2, "name": "a", "expression": "sunrise", "action": {"type": "scene", "scene_id": 1}}`,
+			`{"home_id": 1, "name": "a", "expression": "@daily", "action": {"type": "alert"}}`,
+			`{"home_id": 1, "name": "a", "expression": "@daily", "action": {"type": "scene", "scene_id": 1}, "missed_run_policy": "all"}`,
+		} {
+			w := doAuthJSON(engine, http.MethodPost, "/schedules", body, owner)
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code, body)
+		}
+	})
+
+	t.Run("stranger_404", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/schedules/1", "", stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/schedules/1/runs", "", stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/schedules",
+			`{"home_id": 1, "name": "a", "expression": "@daily", "action": {"type": "scene", "scene_id": 1}}`, stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/schedules", "", stranger)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+	})
+
+	t.Run("GET_schedules_runs_200", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/schedules/1/runs", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+
+		w = doAuthJSON(engine, http.MethodGet, "/schedules/1/runs?status=done", "", owner)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("PUT_schedules_disable", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPut, "/schedules/1", `{
+			"home_id": 1,
+			"name": "weekday mornings",
+			"expression": "0 7 * * mon-fri",
+			"action": {"type": "scene", "scene_id": 1},
+			"enabled": false
+		}`, owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		var updated ScheduleResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+		assert.False(t, updated.Enabled)
+		assert.Nil(t, updated.NextRunAt)
+		assert.Equal(t, schedule.CreatedAt, updated.CreatedAt)
+	})
+
+	t.Run("method_not_allowed", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPatch, "/schedules/1", "", owner)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET,HEAD,PUT,DELETE,OPTIONS", w.Header().Get("Allow"))
+	})
+
+	t.Run("DELETE_204", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodDelete, "/schedules/1", "", owner)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/schedules/1", "", owner)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,159 @@
+package http
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSensorImportRows - максимальное количество строк в одном запросе импорта
+const maxSensorImportRows = 1000
+
+var errTooManyImportRows = fmt.Errorf("too many rows, at most %d allowed", maxSensorImportRows)
+
+func setupSensorImportRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.POST("/import", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		mode := domain.SensorImportMode(c.DefaultQuery("mode", string(domain.SensorImportAtomic)))
+		if mode != domain.SensorImportAtomic && mode != domain.SensorImportBestEffort {
+			c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Reason: "Invalid mode, use atomic or best_effort"})
+			return
+		}
+
+		dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Reason: "Invalid dry_run"})
+			return
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+
+		var requests []SensorImportRequest
+		switch mediaType {
+		case "application/json":
+			if err := c.ShouldBindJSON(&requests); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+				return
+			}
+		case "text/csv":
+			requests, err = parseSensorImportCSV(c.Request.Body)
+			if errors.Is(err, errTooManyImportRows) {
+				c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Reason: err.Error()})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Reason: err.Error()})
+				return
+			}
+		default:
+			c.JSON(http.StatusUnsupportedMediaType, ErrorResponse{Reason: "Content-Type must be application/json or text/csv"})
+			return
+		}
+
+		if len(requests) == 0 {
+			c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Reason: "No rows to import"})
+			return
+		}
+		if len(requests) > maxSensorImportRows {
+			c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Reason: errTooManyImportRows.Error()})
+			return
+		}
+
+		results, err := uc.Sensor.ImportSensors(c.Request.Context(), sensorImportToDomain(requests), mode, dryRun)
+		switch {
+		case errors.Is(err, usecase.ErrImportRejected):
+			c.JSON(http.StatusUnprocessableEntity, sensorImportToResponse(results, mode, dryRun))
+		case err != nil:
+			handleError(c, err)
+		default:
+			c.JSON(http.StatusOK, sensorImportToResponse(results, mode, dryRun))
+		}
+	})
+
+	rg.OPTIONS("/import", func(c *gin.Context) {
+		setAllowHeader(c, "POST,OPTIONS")
+	})
+}
+
+// parseSensorImportCSV разбирает CSV с заголовком. Обязательные колонки: serial_number, type;
+// необязательные: description, is_active, owner_id, labels. Метки в колонке labels разделяются ";".
+func parseSensorImportCSV(r io.Reader) ([]SensorImportRequest, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"serial_number", "type"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv column %q is required", required)
+		}
+	}
+
+	var requests []SensorImportRequest
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+		if len(requests) == maxSensorImportRows {
+			return nil, errTooManyImportRows
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		req := SensorImportRequest{
+			SensorCreateRequest: SensorCreateRequest{
+				SerialNumber: field("serial_number"),
+				Type:         field("type"),
+				Description:  field("description"),
+			},
+		}
+
+		if v := field("is_active"); v != "" {
+			if req.IsActive, err = strconv.ParseBool(v); err != nil {
+				return nil, fmt.Errorf("line %d: invalid is_active %q", line, v)
+			}
+		}
+		if v := field("labels"); v != "" {
+			for _, label := range strings.Split(v, ";") {
+				req.Labels = append(req.Labels, strings.TrimSpace(label))
+			}
+		}
+		if v := field("owner_id"); v != "" {
+			if req.OwnerID, err = strconv.ParseInt(v, 10, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid owner_id %q", line, v)
+			}
+		}
+
+		requests = append(requests, req)
+	}
+
+	return requests, nil
+}
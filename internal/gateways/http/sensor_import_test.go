@@ -0,0 +1,117 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"homework/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensorImportRoute(t *testing.T) {
+	engine, sr, ur := newInmemoryTestRouter(t)
+	ctx := context.Background()
+
+	user := &domain.User{Name: "owner"}
+	require.NoError(t, ur.SaveUser(ctx, user))
+
+	doCSV := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", "text/csv")
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("dry_run_saves_nothing", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/sensors/import?dry_run=true",
+			`[{"serial_number": "0000000001", "type": "adc", "description": "t"}]`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response SensorImportResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.True(t, response.DryRun)
+		require.Len(t, response.Results, 1)
+		assert.Equal(t, "valid", response.Results[0].Status)
+
+		sensors, err := sr.GetSensors(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, sensors)
+	})
+
+	t.Run("dry_run_vendor_serial_scheme", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/sensors/import?dry_run=true",
+			`[{"serial_number": "AX1B2C3D4Z", "type": "adc"}, {"serial_number": "AX1B2C3D4E", "type": "adc"}]`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response SensorImportResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Results, 2)
+		assert.Equal(t, "valid", response.Results[0].Status)
+		assert.Equal(t, "invalid", response.Results[1].Status)
+	})
+
+	t.Run("atomic_rejects_invalid_rows_422", func(t *testing.T) {
+		w := doCSV("/sensors/import", "serial_number,type\n0000000001,adc\n123,adc\n")
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		var response SensorImportResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Results, 2)
+		assert.Equal(t, "skipped", response.Results[0].Status)
+		assert.Equal(t, "invalid", response.Results[1].Status)
+		assert.Equal(t, 1, response.Failed)
+	})
+
+	t.Run("csv_with_owner_200", func(t *testing.T) {
+		w := doCSV("/sensors/import", "serial_number,type,description,is_active,owner_id,labels\n0000000001,adc,t,true,1,hall; critical\n0000000002,cc,door,false,,\n")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response SensorImportResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 2, response.Created)
+
+		w = doJSON(engine, http.MethodGet, "/users/1/sensors", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var sensors []SensorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensors))
+		require.Len(t, sensors, 1)
+		assert.Equal(t, "0000000001", sensors[0].SerialNumber)
+		assert.True(t, sensors[0].IsActive)
+		assert.Equal(t, []string{"hall", "critical"}, sensors[0].Labels)
+	})
+
+	t.Run("best_effort_existing_and_invalid", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/sensors/import?mode=best_effort",
+			`[{"serial_number": "0000000002", "type": "cc"}, {"serial_number": "0000000003", "type": "adc", "owner_id": 42}, {"serial_number": "0000000004", "type": "adc"}]`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response SensorImportResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Results, 3)
+		assert.Equal(t, "exists", response.Results[0].Status)
+		assert.Equal(t, "invalid", response.Results[1].Status)
+		assert.Equal(t, "created", response.Results[2].Status)
+	})
+
+	t.Run("malformed_csv_400", func(t *testing.T) {
+		w := doCSV("/sensors/import", "serial_number\n0000000005\n")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unknown_mode_422", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/sensors/import?mode=all", `[]`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("PUT_sensors_import_405", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPut, "/sensors/import", "")
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "POST,OPTIONS", w.Header().Get("Allow"))
+	})
+}
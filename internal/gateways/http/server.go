@@ -28,6 +28,30 @@ type UseCases struct {
 	Event  *usecase.Event
 	Sensor *usecase.Sensor
 	User   *usecase.User
+	Home   *usecase.Home
+	Group  *usecase.Group
+	// Invitation - приглашения к датчикам по ссылке
+	Invitation *usecase.Invitation
+	// Rule - правила оповещений по значениям датчиков
+	Rule *usecase.Rule
+	// Alert - оповещения сработавших правил и автоматизаций
+	Alert *usecase.Alert
+	// Webhook - подписки внешних систем на уведомления
+	Webhook *usecase.Webhook
+	// Actuator - исполнительные устройства и очередь команд к ним
+	Actuator *usecase.Actuator
+	// Automation - автоматизации по событиям датчиков и сцены
+	Automation *usecase.Automation
+	// Scheduler - расписания команд устройствам и запуска сцен
+	Scheduler *usecase.Scheduler
+	// Audit - журнал аудита изменений
+	Audit *usecase.Audit
+	// Auth - аутентификация по токенам, если не задана, API доступно анонимно
+	Auth *usecase.Auth
+	// DeviceAuth - секреты датчиков, если задан, POST /events и очередь команд контроллеров принимают только подписанные запросы
+	DeviceAuth *usecase.DeviceAuth
+	// RateLimiter - ограничение частоты событий от датчиков и запросов на чтение, если не задан, частота не ограничивается
+	RateLimiter *usecase.RateLimiter
 }
 
 func NewServer(useCases UseCases, options ...func(*Server)) *Server {
@@ -0,0 +1,122 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"homework/internal/domain"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsers(t *testing.T) {
+	engine, sr, _ := newInmemoryTestRouter(t)
+	ctx := context.Background()
+
+	require.NoError(t, sr.SaveSensor(ctx, &domain.Sensor{SerialNumber: "0000000001", Type: domain.SensorTypeADC, Description: "t"}))
+	for _, name := range []string{"first", "second", "third"} {
+		w := doJSON(engine, http.MethodPost, "/users", `{"name": "`+name+`"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	t.Run("duplicate_name_409", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/users", `{"name": " FIRST "}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("list_paginated", func(t *testing.T) {
+		w := doJSON(engine, http.MethodGet, "/users?limit=2&offset=1", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var users []UserResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &users))
+		assert.Equal(t, []UserResponse{{ID: 2, Name: "second"}, {ID: 3, Name: "third"}}, users)
+
+		w = doJSON(engine, http.MethodGet, "/users?limit=1000", "")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		w = doJSON(engine, http.MethodGet, "/users?offset=x", "")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("get_and_patch", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPatch, "/users/2", `{"name": "  renamed "}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"id": 2, "name": "renamed"}`, w.Body.String())
+
+		w = doJSON(engine, http.MethodGet, "/users/2", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"id": 2, "name": "renamed"}`, w.Body.String())
+
+		w = doJSON(engine, http.MethodPatch, "/users/2", `{"name": "Third"}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = doJSON(engine, http.MethodPatch, "/users/2", `{"name": ""}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		w = doJSON(engine, http.MethodGet, "/users/42", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("delete_cascades_to_sensor_links", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/users/1/sensors", `{"sensor_id": 1}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		w = doJSON(engine, http.MethodPost, "/users/3/sensors", `{"sensor_id": 1}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		w = doJSON(engine, http.MethodDelete, "/users/3", "")
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = doJSON(engine, http.MethodGet, "/sensors/1/users", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[{"user_id": 1, "sensor_id": 1, "role": "owner"}]`, w.Body.String())
+
+		w = doJSON(engine, http.MethodDelete, "/users/3", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("allow_header_on_405", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPut, "/users/1", "")
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET,HEAD,PATCH,DELETE,OPTIONS", w.Header().Get("Allow"))
+
+		w = doJSON(engine, http.MethodHead, "/users", "")
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET,POST,OPTIONS", w.Header().Get("Allow"))
+	})
+}
+
+func TestUsersAccess(t *testing.T) {
+	uc, _, _ := newInmemoryUseCases(t, withAuth())
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	register := func(name string) string {
+		w := doJSON(engine, http.MethodPost, "/users", `{"name": "`+name+`"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var registered UserRegistrationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+		return registered.Token
+	}
+	first := register("first")
+	register("second")
+
+	w := doAuthJSON(engine, http.MethodGet, "/users", "", first)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"id": 1, "name": "first"}]`, w.Body.String())
+
+	w = doAuthJSON(engine, http.MethodGet, "/users/2", "", first)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doAuthJSON(engine, http.MethodDelete, "/users/2", "", first)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doAuthJSON(engine, http.MethodDelete, "/users/1", "", first)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	w = doAuthJSON(engine, http.MethodGet, "/users/1", "", first)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,101 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupSensorVirtualRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:sensor_id/virtual", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		sensor, err := uc.Sensor.GetSensorByID(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+		if sensor.Virtual == nil {
+			c.JSON(http.StatusNotFound, ErrorResponse{Reason: "virtual sensor expression not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, virtualSensorToResponse(sensor.Virtual))
+	})
+
+	rg.HEAD("/:sensor_id/virtual", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("sensor_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		sensor, err := uc.Sensor.GetSensorByID(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+		if sensor.Virtual == nil {
+			c.Status(http.StatusNotFound)
+			return
+		}
+
+		setContentLength(c, virtualSensorToResponse(sensor.Virtual))
+		c.Status(http.StatusOK)
+	})
+
+	rg.PUT("/:sensor_id/virtual", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		var virtualReq VirtualSensorRequest
+		if err := c.ShouldBindJSON(&virtualReq); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		sensor, err := uc.Sensor.SetSensorVirtual(c.Request.Context(), id, virtualSensorToDomain(&virtualReq))
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, sensorToResponse(sensor))
+	})
+
+	rg.DELETE("/:sensor_id/virtual", func(c *gin.Context) {
+		id, ok := parseIDParam(c, "sensor_id", "Invalid sensor ID")
+		if !ok {
+			return
+		}
+
+		if _, err := uc.Sensor.SetSensorVirtual(c.Request.Context(), id, nil); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	rg.OPTIONS("/:sensor_id/virtual", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,PUT,DELETE,OPTIONS")
+	})
+}
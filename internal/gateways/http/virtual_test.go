@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"homework/internal/domain"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensorVirtualRoutes(t *testing.T) {
+	engine, sr, _ := newInmemoryTestRouter(t)
+	ctx := context.Background()
+
+	for _, sensor := range []*domain.Sensor{
+		{SerialNumber: "0000000001", Type: domain.SensorTypeADC, Description: "bedroom"},
+		{SerialNumber: "0000000002", Type: domain.SensorTypeADC, Description: "kitchen"},
+		{SerialNumber: "0000000003", Type: domain.SensorTypeVirtual, Description: "average"},
+	} {
+		require.NoError(t, sr.SaveSensor(ctx, sensor))
+	}
+
+	history := func(t *testing.T, id string) []SensorHistoryResponse {
+		w := doJSON(engine, http.MethodGet, "/sensors/"+id+"/history?end_date="+time.Now().Add(time.Minute).Format(time.RFC3339), "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var events []SensorHistoryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &events))
+		return events
+	}
+
+	t.Run("GET_virtual_not_set_404", func(t *testing.T) {
+		w := doJSON(engine, http.MethodGet, "/sensors/3/virtual", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("PUT_virtual_adc_sensor_422", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPut, "/sensors/1/virtual", `{"expression": "s2"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("PUT_virtual_invalid_expression_422", func(t *testing.T) {
+		for _, body := range []string{
+			`{"expression": "avg(s1, "}`,
+			`{"expression": "s1 + s99"}`,
+			`{"expression": "s3 + 1"}`,
+			`{"expression": "s1", "resolution": 0}`,
+		} {
+			w := doJSON(engine, http.MethodPut, "/sensors/3/virtual", body)
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code, body)
+		}
+	})
+
+	t.Run("PUT_virtual_200", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPut, "/sensors/3/virtual", `{"expression": "avg(s1, s2)", "unit": "°C"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var sensor SensorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensor))
+		require.NotNil(t, sensor.Virtual)
+		assert.Equal(t, VirtualSensorResponse{Expression: "avg(s1, s2)", Resolution: domain.DefaultVirtualResolution, Unit: "°C"},
+			*sensor.Virtual)
+
+		w = doJSON(engine, http.MethodHead, "/sensors/3/virtual", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("POST_events_inputs_compute_virtual_sensor", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/events", `{"sensor_serial_number": "0000000001", "payload": 20}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		w = doJSON(engine, http.MethodPost, "/events", `{"sensor_serial_number": "0000000002", "payload": 25}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		events := history(t, "3")
+		require.Len(t, events, 2)
+		require.NotNil(t, events[1].Value)
+		assert.InDelta(t, 22.5, *events[1].Value, 1e-9)
+
+		w = doJSON(engine, http.MethodGet, "/sensors/3", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var sensor SensorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensor))
+		require.NotNil(t, sensor.CurrentValue)
+		assert.InDelta(t, 22.5, *sensor.CurrentValue, 1e-9)
+		assert.Equal(t, "°C", sensor.Unit)
+	})
+
+	t.Run("POST_events_virtual_sensor_422", func(t *testing.T) {
+		w := doJSON(engine, http.MethodPost, "/events", `{"sensor_serial_number": "0000000003", "payload": 1}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("DELETE_virtual_stops_computing_204", func(t *testing.T) {
+		w := doJSON(engine, http.MethodDelete, "/sensors/3/virtual", "")
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = doJSON(engine, http.MethodPost, "/events", `{"sensor_serial_number": "0000000001", "payload": 30}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		assert.Len(t, history(t, "3"), 2)
+	})
+}
@@ -0,0 +1,256 @@
+package http
+
+import (
+	"homework/internal/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseWebhookDeliveryFilter разбирает параметры журнала отправок подписки: status, limit и offset
+func parseWebhookDeliveryFilter(c *gin.Context, webhookID int64) (domain.WebhookDeliveryFilter, error) {
+	filter := domain.WebhookDeliveryFilter{
+		WebhookID: webhookID,
+		Status:    domain.WebhookDeliveryStatus(c.Query("status")),
+	}
+	var err error
+	if filter.Limit, filter.Offset, err = parsePagination(c); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+func setupWebhooksRoutes(r *gin.Engine, uc UseCases) {
+	webhooksGroup := r.Group("/webhooks")
+	{
+		webhooksGroup.GET("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			webhooks, err := uc.Webhook.GetWebhooks(c.Request.Context())
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, webhooksToResponse(webhooks))
+		})
+
+		webhooksGroup.HEAD("", func(c *gin.Context) {
+			if !checkAcceptJSON(c) {
+				return
+			}
+
+			webhooks, err := uc.Webhook.GetWebhooks(c.Request.Context())
+			if err != nil {
+				handleStatusOnlyError(c, err)
+				return
+			}
+
+			setContentLength(c, webhooksToResponse(webhooks))
+			c.Status(http.StatusOK)
+		})
+
+		// секрет подписи возвращается только в ответе на создание
+		webhooksGroup.POST("", func(c *gin.Context) {
+			if !checkContentTypeJSON(c) {
+				return
+			}
+
+			var webhookReq WebhookRequest
+			if err := c.ShouldBindJSON(&webhookReq); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+				return
+			}
+
+			webhook, secret, err := uc.Webhook.CreateWebhook(c.Request.Context(), webhookToDomain(webhookReq))
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+
+			response := webhookToResponse(webhook)
+			response.Secret = secret
+			c.JSON(http.StatusCreated, response)
+		})
+
+		webhooksGroup.OPTIONS("", func(c *gin.Context) {
+			setAllowHeader(c, "GET,HEAD,POST,OPTIONS")
+		})
+
+		setupWebhookByIDRoutes(webhooksGroup, uc)
+	}
+}
+
+func setupWebhookByIDRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:webhook_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "webhook_id", "Invalid webhook ID")
+		if !ok {
+			return
+		}
+
+		webhook, err := uc.Webhook.GetWebhookByID(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, webhookToResponse(webhook))
+	})
+
+	rg.HEAD("/:webhook_id", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("webhook_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		webhook, err := uc.Webhook.GetWebhookByID(c.Request.Context(), id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, webhookToResponse(webhook))
+		c.Status(http.StatusOK)
+	})
+
+	rg.PUT("/:webhook_id", func(c *gin.Context) {
+		if !checkContentTypeJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "webhook_id", "Invalid webhook ID")
+		if !ok {
+			return
+		}
+
+		var webhookReq WebhookRequest
+		if err := c.ShouldBindJSON(&webhookReq); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Reason: "Invalid request body"})
+			return
+		}
+
+		webhook := webhookToDomain(webhookReq)
+		webhook.ID = id
+		result, err := uc.Webhook.UpdateWebhook(c.Request.Context(), webhook)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, webhookToResponse(result))
+	})
+
+	rg.DELETE("/:webhook_id", func(c *gin.Context) {
+		id, ok := parseIDParam(c, "webhook_id", "Invalid webhook ID")
+		if !ok {
+			return
+		}
+
+		if err := uc.Webhook.DeleteWebhook(c.Request.Context(), id); err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	rg.OPTIONS("/:webhook_id", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,PUT,DELETE,OPTIONS")
+	})
+
+	setupWebhookDeliveriesRoutes(rg, uc)
+}
+
+func setupWebhookDeliveriesRoutes(rg *gin.RouterGroup, uc UseCases) {
+	rg.GET("/:webhook_id/deliveries", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "webhook_id", "Invalid webhook ID")
+		if !ok {
+			return
+		}
+
+		filter, err := parseWebhookDeliveryFilter(c, id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		deliveries, err := uc.Webhook.GetWebhookDeliveries(c.Request.Context(), filter)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, webhookDeliveriesToResponse(deliveries))
+	})
+
+	rg.HEAD("/:webhook_id/deliveries", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("webhook_id"), 10, 64)
+		if err != nil {
+			c.Status(http.StatusUnprocessableEntity)
+			return
+		}
+
+		filter, err := parseWebhookDeliveryFilter(c, id)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		deliveries, err := uc.Webhook.GetWebhookDeliveries(c.Request.Context(), filter)
+		if err != nil {
+			handleStatusOnlyError(c, err)
+			return
+		}
+
+		setContentLength(c, webhookDeliveriesToResponse(deliveries))
+		c.Status(http.StatusOK)
+	})
+
+	rg.OPTIONS("/:webhook_id/deliveries", func(c *gin.Context) {
+		setAllowHeader(c, "GET,HEAD,OPTIONS")
+	})
+
+	// проверочная отправка выполняется сразу, в ответе - запись журнала с ее результатом
+	rg.POST("/:webhook_id/test", func(c *gin.Context) {
+		if !checkAcceptJSON(c) {
+			return
+		}
+
+		id, ok := parseIDParam(c, "webhook_id", "Invalid webhook ID")
+		if !ok {
+			return
+		}
+
+		delivery, err := uc.Webhook.SendTestWebhook(c.Request.Context(), id)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, webhookDeliveryToResponse(delivery))
+	})
+
+	rg.OPTIONS("/:webhook_id/test", func(c *gin.Context) {
+		setAllowHeader(c, "POST,OPTIONS")
+	})
+}
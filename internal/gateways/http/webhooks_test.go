@@ -0,0 +1,225 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"homework/internal/usecase"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receivedWebhook - запрос, полученный тестовым получателем уведомлений
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+func TestWebhooks(t *testing.T) {
+	app := newInmemoryApp(t, withAuth())
+	uc := app.uc
+
+	engine := gin.New()
+	setupRouter(engine, uc, NewWebSocketHandler(uc))
+
+	received := make(chan receivedWebhook, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedWebhook{header: r.Header.Clone(), body: body}
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	register := func(name string) string {
+		w := doJSON(engine, http.MethodPost, "/users", `{"name": "`+name+`"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var registered UserRegistrationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+		return registered.Token
+	}
+	owner := register("owner")
+	stranger := register("stranger")
+
+	w := doAuthJSON(engine, http.MethodPost, "/sensors",
+		`{"serial_number": "0000000001", "type": "cc", "description": "door"}`, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = doAuthJSON(engine, http.MethodPost, "/webhooks",
+		`{"url": "`+receiver.URL+`", "event_kinds": ["sensor.event"], "sensor_ids": [1]}`, owner)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created WebhookResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.NotEmpty(t, created.Secret)
+
+	verify := func(t *testing.T, request receivedWebhook, kind string) map[string]any {
+		t.Helper()
+		assert.Equal(t, kind, request.header.Get(usecase.WebhookEventHeader))
+		timestamp, err := strconv.ParseInt(request.header.Get(usecase.WebhookTimestampHeader), 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, usecase.SignWebhook(created.Secret, timestamp, request.body),
+			request.header.Get(usecase.WebhookSignatureHeader))
+
+		var payload map[string]any
+		require.NoError(t, json.Unmarshal(request.body, &payload))
+		assert.Equal(t, kind, payload["kind"])
+		return payload
+	}
+
+	t.Run("secret_only_on_create", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/webhooks/1", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "secret")
+		assert.NotContains(t, w.Body.String(), created.Secret)
+	})
+
+	t.Run("invalid_webhook", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/webhooks", `{"url": "ftp://example.com"}`, owner)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/webhooks", `{"url": "http://example.com", "event_kinds": ["test"]}`, owner)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("stranger_sees_only_own_webhooks", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/webhooks", "", stranger)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+
+		for _, path := range []string{"/webhooks/1", "/webhooks/1/deliveries"} {
+			w = doAuthJSON(engine, http.MethodGet, path, "", stranger)
+			assert.Equal(t, http.StatusNotFound, w.Code, path)
+		}
+		w = doAuthJSON(engine, http.MethodPost, "/webhooks/1/test", "", stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		// нельзя подписаться на чужой датчик
+		w = doAuthJSON(engine, http.MethodPost, "/webhooks", `{"url": "`+receiver.URL+`", "sensor_ids": [1]}`, stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("send_test", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/webhooks/1/test", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		var delivery WebhookDeliveryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &delivery))
+		assert.Equal(t, "delivered", delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, http.StatusNoContent, delivery.LastStatusCode)
+
+		request := <-received
+		verify(t, request, "test")
+		assert.Equal(t, strconv.FormatInt(delivery.ID, 10), request.header.Get(usecase.WebhookDeliveryHeader))
+	})
+
+	t.Run("sensor_event_delivered", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/events",
+			`{"sensor_serial_number": "0000000001", "payload": 1}`, owner)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		require.NoError(t, app.outbox.Dispatch(context.Background()))
+		require.NoError(t, uc.Webhook.DeliverWebhooks(context.Background()))
+		payload := verify(t, <-received, "sensor.event")
+		assert.Equal(t, "0000000001", payload["sensor"].(map[string]any)["serial_number"])
+		assert.Equal(t, float64(1), payload["event"].(map[string]any)["payload"])
+	})
+
+	t.Run("delivery_log", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/webhooks/1/deliveries", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		var deliveries []WebhookDeliveryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &deliveries))
+		require.Len(t, deliveries, 2)
+		assert.Equal(t, "sensor.event", deliveries[0].EventKind)
+		assert.Equal(t, "test", deliveries[1].EventKind)
+
+		w = doAuthJSON(engine, http.MethodGet, "/webhooks/1/deliveries?status=pending", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+
+		w = doAuthJSON(engine, http.MethodGet, "/webhooks/1/deliveries?status=lost", "", owner)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("label_filter", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPost, "/sensors",
+			`{"serial_number": "0000000002", "type": "cc", "description": "safe", "labels": ["critical", "hall"]}`, owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		var sensor SensorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensor))
+		assert.Equal(t, []string{"critical", "hall"}, sensor.Labels)
+
+		w = doAuthJSON(engine, http.MethodPost, "/sensors",
+			`{"serial_number": "0000000003", "type": "cc", "description": "window"}`, owner)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/webhooks",
+			`{"url": "`+receiver.URL+`", "event_kinds": ["sensor.event"], "labels": ["critical"]}`, owner)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var labelled WebhookResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &labelled))
+		assert.Equal(t, []string{"critical"}, labelled.Labels)
+
+		for _, sn := range []string{"0000000003", "0000000002"} {
+			w = doAuthJSON(engine, http.MethodPost, "/events", `{"sensor_serial_number": "`+sn+`", "payload": 1}`, owner)
+			require.Equal(t, http.StatusCreated, w.Code)
+		}
+		require.NoError(t, app.outbox.Dispatch(context.Background()))
+		require.NoError(t, uc.Webhook.DeliverWebhooks(context.Background()))
+
+		request := <-received
+		timestamp, err := strconv.ParseInt(request.header.Get(usecase.WebhookTimestampHeader), 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, usecase.SignWebhook(labelled.Secret, timestamp, request.body),
+			request.header.Get(usecase.WebhookSignatureHeader), "signed by the labelled subscription")
+		var payload map[string]any
+		require.NoError(t, json.Unmarshal(request.body, &payload))
+		assert.Equal(t, "0000000002", payload["sensor"].(map[string]any)["serial_number"])
+		assert.Empty(t, received, "sensor without the label is filtered out")
+
+		w = doAuthJSON(engine, http.MethodPost, "/webhooks", `{"url": "http://example.com", "labels": [" "]}`, owner)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		w = doAuthJSON(engine, http.MethodDelete, "/webhooks/"+strconv.FormatInt(labelled.ID, 10), "", owner)
+		require.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("failed_test_is_dead", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodPut, "/webhooks/1", `{"url": "`+failing.URL+`"}`, owner)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = doAuthJSON(engine, http.MethodPost, "/webhooks/1/test", "", owner)
+		require.Equal(t, http.StatusOK, w.Code)
+		var delivery WebhookDeliveryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &delivery))
+		assert.Equal(t, "dead", delivery.Status)
+		assert.Equal(t, http.StatusInternalServerError, delivery.LastStatusCode)
+		assert.NotEmpty(t, delivery.LastError)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodDelete, "/webhooks/1", "", stranger)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doAuthJSON(engine, http.MethodDelete, "/webhooks/1", "", owner)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = doAuthJSON(engine, http.MethodGet, "/webhooks/1/deliveries", "", owner)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("method_not_allowed", func(t *testing.T) {
+		w := doAuthJSON(engine, http.MethodGet, "/webhooks/1/test", "", owner)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "POST,OPTIONS", w.Header().Get("Allow"))
+	})
+}
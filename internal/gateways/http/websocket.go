@@ -30,7 +30,7 @@ func NewWebSocketHandler(useCases UseCases) *WebSocketHandler {
 func (h *WebSocketHandler) Handle(c *gin.Context, id int64) error {
 	ctx := c.Request.Context()
 
-	_, err := h.useCases.Sensor.GetSensorByID(ctx, id)
+	sensor, err := h.useCases.Sensor.GetSensorByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, usecase.ErrSensorNotFound) {
 			c.Status(404)
@@ -40,6 +40,11 @@ func (h *WebSocketHandler) Handle(c *gin.Context, id int64) error {
 		return err
 	}
 
+	connCtx, cancelConn := context.WithCancel(ctx)
+	defer cancelConn()
+
+	revoked := h.useCases.Sensor.WatchSensorAccess(connCtx, id)
+
 	conn, err := websocket.Accept(c.Writer, c.Request, &websocket.AcceptOptions{
 		InsecureSkipVerify: true,
 	})
@@ -47,9 +52,6 @@ func (h *WebSocketHandler) Handle(c *gin.Context, id int64) error {
 		return err
 	}
 
-	connCtx, cancelConn := context.WithCancel(ctx)
-	defer cancelConn()
-
 	h.mu.Lock()
 	h.conns[conn] = struct{}{}
 	h.mu.Unlock()
@@ -106,7 +108,10 @@ func (h *WebSocketHandler) Handle(c *gin.Context, id int64) error {
 				return
 			}
 
-			data, err := json.Marshal(lastEvent)
+			event := *lastEvent
+			sensor.ApplyCalibration(&event)
+
+			data, err := json.Marshal(event)
 			if err != nil {
 				log.Printf("Error marshaling event data: %v", err)
 				return
@@ -129,8 +134,79 @@ func (h *WebSocketHandler) Handle(c *gin.Context, id int64) error {
 	select {
 	case <-connClosed:
 	case <-ctx.Done():
+	case <-revoked:
+		// пользователь потерял доступ к датчику, например его исключили из группы
+		cancelConn()
+		<-connClosed
+	}
+
+	return nil
+}
+
+// HandleAlerts передает по соединению изменения оповещений доступных пользователю датчиков,
+// пока клиент не закроет соединение
+func (h *WebSocketHandler) HandleAlerts(c *gin.Context) error {
+	ctx := c.Request.Context()
+
+	connCtx, cancelConn := context.WithCancel(ctx)
+	defer cancelConn()
+
+	// подписка оформляется до установки соединения, чтобы клиент не пропустил изменения сразу после нее
+	alerts := h.useCases.Alert.SubscribeAlerts(connCtx)
+
+	conn, err := websocket.Accept(c.Writer, c.Request, &websocket.AcceptOptions{
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	h.conns[conn] = struct{}{}
+	h.mu.Unlock()
+
+	connClosed := make(chan struct{})
+
+	go func() {
+		defer func() {
+			h.mu.Lock()
+			delete(h.conns, conn)
+			h.mu.Unlock()
+			cancelConn()
+			if err := conn.Close(websocket.StatusNormalClosure, "connection closed"); err != nil {
+				log.Printf("Error closing connection: %v", err)
+			}
+
+			close(connClosed)
+		}()
+
+		for {
+			_, _, err := conn.Read(connCtx)
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					log.Printf("WebSocket read error: %v", err)
+				}
+				break
+			}
+		}
+	}()
+
+	for alert := range alerts {
+		data, err := json.Marshal(alertToResponse(&alert))
+		if err != nil {
+			log.Printf("Error marshaling alert data: %v", err)
+			continue
+		}
+
+		if err := conn.Write(connCtx, websocket.MessageText, data); err != nil {
+			log.Printf("WebSocket write error: %v", err)
+			break
+		}
 	}
 
+	cancelConn()
+	<-connClosed
+
 	return nil
 }
 
@@ -0,0 +1,271 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ActuatorRepository хранит устройства по id, а команды - в порядке отправки
+type ActuatorRepository struct {
+	actuators     map[int64]domain.Actuator
+	commands      []domain.Command
+	mu            sync.RWMutex
+	lastID        int64
+	lastCommandID int64
+}
+
+func NewActuatorRepository() *ActuatorRepository {
+	return &ActuatorRepository{
+		actuators: make(map[int64]domain.Actuator),
+	}
+}
+
+func (r *ActuatorRepository) SaveActuator(ctx context.Context, actuator *domain.Actuator) error {
+	if actuator == nil {
+		return errors.New("actuator is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if actuator.ID == 0 {
+		r.lastID++
+		actuator.ID = r.lastID
+	} else if _, ok := r.actuators[actuator.ID]; !ok {
+		return usecase.ErrActuatorNotFound
+	}
+
+	r.actuators[actuator.ID] = *actuator
+
+	return nil
+}
+
+func (r *ActuatorRepository) GetActuators(ctx context.Context) ([]domain.Actuator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	actuators := make([]domain.Actuator, 0, len(r.actuators))
+	for _, actuator := range r.actuators {
+		actuators = append(actuators, actuator)
+	}
+	sort.Slice(actuators, func(i, j int) bool { return actuators[i].ID < actuators[j].ID })
+
+	return actuators, nil
+}
+
+func (r *ActuatorRepository) GetActuatorByID(ctx context.Context, id int64) (*domain.Actuator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	actuator, ok := r.actuators[id]
+	if !ok {
+		return nil, usecase.ErrActuatorNotFound
+	}
+
+	return &actuator, nil
+}
+
+func (r *ActuatorRepository) DeleteActuator(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.actuators[id]; !ok {
+		return usecase.ErrActuatorNotFound
+	}
+	delete(r.actuators, id)
+
+	r.commands = slices.DeleteFunc(r.commands, func(command domain.Command) bool {
+		return command.ActuatorID == id
+	})
+
+	return nil
+}
+
+func (r *ActuatorRepository) SetActuatorState(ctx context.Context, id int64, state int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	actuator, ok := r.actuators[id]
+	if !ok {
+		return usecase.ErrActuatorNotFound
+	}
+	actuator.State = state
+	r.actuators[id] = actuator
+
+	return nil
+}
+
+func (r *ActuatorRepository) AddCommand(ctx context.Context, command *domain.Command) error {
+	if command == nil {
+		return errors.New("command is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.actuators[command.ActuatorID]; !ok {
+		return usecase.ErrActuatorNotFound
+	}
+	r.lastCommandID++
+	command.ID = r.lastCommandID
+	r.commands = append(r.commands, copyCommand(*command))
+
+	return nil
+}
+
+func (r *ActuatorRepository) UpdateCommandStatus(ctx context.Context, command *domain.Command) error {
+	if command == nil {
+		return errors.New("command is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := r.findCommand(command.ID)
+	if stored == nil {
+		return usecase.ErrCommandNotFound
+	}
+	if !stored.Status.IsOpen() {
+		return usecase.ErrCommandCompleted
+	}
+
+	updated := copyCommand(*command)
+	stored.Status = updated.Status
+	stored.Error = updated.Error
+	stored.DeliveredAt = updated.DeliveredAt
+	stored.CompletedAt = updated.CompletedAt
+
+	return nil
+}
+
+func (r *ActuatorRepository) GetCommandByID(ctx context.Context, id int64) (*domain.Command, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	command := r.findCommand(id)
+	if command == nil {
+		return nil, usecase.ErrCommandNotFound
+	}
+
+	result := copyCommand(*command)
+	return &result, nil
+}
+
+func (r *ActuatorRepository) GetCommands(ctx context.Context, filter domain.CommandFilter) ([]domain.Command, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]domain.Command, 0)
+	skipped := 0
+	for i := len(r.commands) - 1; i >= 0 && len(result) < filter.Limit; i-- {
+		if !filter.Matches(&r.commands[i]) {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		result = append(result, copyCommand(r.commands[i]))
+	}
+
+	return result, nil
+}
+
+func (r *ActuatorRepository) GetOpenCommandsBySensorID(ctx context.Context, sensorID int64) ([]domain.Command, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]domain.Command, 0)
+	for _, command := range r.commands {
+		if command.SensorID == sensorID && command.Status.IsOpen() {
+			result = append(result, copyCommand(command))
+		}
+	}
+
+	return result, nil
+}
+
+func (r *ActuatorRepository) ExpireCommands(ctx context.Context, now time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expired := 0
+	for i := range r.commands {
+		if r.commands[i].Expire(now) {
+			expired++
+		}
+	}
+
+	return expired, nil
+}
+
+func (r *ActuatorRepository) findCommand(id int64) *domain.Command {
+	for i := range r.commands {
+		if r.commands[i].ID == id {
+			return &r.commands[i]
+		}
+	}
+	return nil
+}
+
+func copyCommand(command domain.Command) domain.Command {
+	if command.DeliveredAt != nil {
+		deliveredAt := *command.DeliveredAt
+		command.DeliveredAt = &deliveredAt
+	}
+	if command.CompletedAt != nil {
+		completedAt := *command.CompletedAt
+		command.CompletedAt = &completedAt
+	}
+	return command
+}
@@ -0,0 +1,86 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActuatorRepository(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		ar := NewActuatorRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := ar.SaveActuator(ctx, &domain.Actuator{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("fail, unknown actuator", func(t *testing.T) {
+		ar := NewActuatorRepository()
+		ctx := context.Background()
+
+		assert.ErrorIs(t, ar.SaveActuator(ctx, &domain.Actuator{ID: 5}), usecase.ErrActuatorNotFound)
+		assert.ErrorIs(t, ar.SetActuatorState(ctx, 5, 1), usecase.ErrActuatorNotFound)
+		assert.ErrorIs(t, ar.AddCommand(ctx, &domain.Command{ActuatorID: 5}), usecase.ErrActuatorNotFound)
+	})
+
+	t.Run("ok, command lifecycle", func(t *testing.T) {
+		ar := NewActuatorRepository()
+		ctx := context.Background()
+
+		actuator := &domain.Actuator{SensorID: 1, Name: "pump", Type: domain.ActuatorTypeRelay}
+		require.NoError(t, ar.SaveActuator(ctx, actuator))
+		require.NoError(t, ar.SetActuatorState(ctx, actuator.ID, 1))
+
+		stored, err := ar.GetActuatorByID(ctx, actuator.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), stored.State)
+
+		first := &domain.Command{ActuatorID: actuator.ID, SensorID: 1, Kind: domain.CommandSetState, Status: domain.CommandPending, ExpiresAt: now}
+		second := &domain.Command{ActuatorID: actuator.ID, SensorID: 1, Kind: domain.CommandPulse, Status: domain.CommandPending, ExpiresAt: now.Add(time.Hour)}
+		require.NoError(t, ar.AddCommand(ctx, first))
+		require.NoError(t, ar.AddCommand(ctx, second))
+
+		expired, err := ar.ExpireCommands(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, expired)
+
+		first.Status = domain.CommandAcked
+		assert.ErrorIs(t, ar.UpdateCommandStatus(ctx, first), usecase.ErrCommandCompleted)
+
+		open, err := ar.GetOpenCommandsBySensorID(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, open, 1)
+		assert.Equal(t, second.ID, open[0].ID)
+
+		second.Status = domain.CommandAcked
+		second.CompletedAt = &now
+		require.NoError(t, ar.UpdateCommandStatus(ctx, second))
+		now = now.Add(time.Minute)
+
+		commands, err := ar.GetCommands(ctx, domain.CommandFilter{ActuatorID: actuator.ID, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, commands, 2)
+		assert.Equal(t, second.ID, commands[0].ID)
+		assert.Equal(t, domain.CommandAcked, commands[0].Status)
+		assert.Equal(t, time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), *commands[0].CompletedAt)
+		assert.Equal(t, domain.CommandExpired, commands[1].Status)
+
+		commands, err = ar.GetCommands(ctx, domain.CommandFilter{Status: domain.CommandExpired, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, commands, 1)
+		assert.Equal(t, first.ID, commands[0].ID)
+
+		require.NoError(t, ar.DeleteActuator(ctx, actuator.ID))
+		_, err = ar.GetCommandByID(ctx, first.ID)
+		assert.ErrorIs(t, err, usecase.ErrCommandNotFound)
+	})
+}
@@ -0,0 +1,265 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	actuatorColumns = `id, sensor_id, name, type, state, created_by, created_at`
+	commandColumns  = `id, actuator_id, sensor_id, kind, state, duration_ms, status, error, created_by, created_at,
+	expires_at, delivered_at, completed_at`
+)
+
+// ActuatorRepository хранит устройства в таблице actuators, а их команды - в commands.
+// Длительность команды хранится в миллисекундах.
+type ActuatorRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewActuatorRepository(pool *pgxpool.Pool) *ActuatorRepository {
+	return &ActuatorRepository{
+		pool: pool,
+	}
+}
+
+func (r *ActuatorRepository) SaveActuator(ctx context.Context, actuator *domain.Actuator) error {
+	if actuator == nil {
+		return errors.New("actuator is nil")
+	}
+
+	conn := transaction.Conn(ctx, r.pool)
+	if actuator.ID == 0 {
+		query := `
+			INSERT INTO actuators (sensor_id, name, type, state, created_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+		`
+		err := conn.QueryRow(ctx, query, actuator.SensorID, actuator.Name, actuator.Type, actuator.State,
+			actuator.CreatedBy, actuator.CreatedAt).Scan(&actuator.ID)
+		if err != nil {
+			return fmt.Errorf("failed to save actuator: %w", err)
+		}
+		return nil
+	}
+
+	query := `
+		UPDATE actuators
+		SET sensor_id = $2, name = $3, type = $4, state = $5, created_by = $6, created_at = $7
+		WHERE id = $1
+	`
+	tag, err := conn.Exec(ctx, query, actuator.ID, actuator.SensorID, actuator.Name, actuator.Type, actuator.State,
+		actuator.CreatedBy, actuator.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update actuator: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrActuatorNotFound
+	}
+	return nil
+}
+
+func (r *ActuatorRepository) GetActuators(ctx context.Context) ([]domain.Actuator, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, `SELECT `+actuatorColumns+` FROM actuators ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query actuators: %w", err)
+	}
+	defer rows.Close()
+
+	actuators := []domain.Actuator{}
+	for rows.Next() {
+		actuator, err := scanActuator(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan actuator: %w", err)
+		}
+		actuators = append(actuators, *actuator)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through actuators: %w", err)
+	}
+	return actuators, nil
+}
+
+func (r *ActuatorRepository) GetActuatorByID(ctx context.Context, id int64) (*domain.Actuator, error) {
+	actuator, err := scanActuator(transaction.Conn(ctx, r.pool).QueryRow(ctx,
+		`SELECT `+actuatorColumns+` FROM actuators WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrActuatorNotFound
+		}
+		return nil, fmt.Errorf("failed to get actuator: %w", err)
+	}
+	return actuator, nil
+}
+
+// DeleteActuator удаляет устройство, история его команд удаляется каскадно
+func (r *ActuatorRepository) DeleteActuator(ctx context.Context, id int64) error {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM actuators WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete actuator: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrActuatorNotFound
+	}
+	return nil
+}
+
+func (r *ActuatorRepository) SetActuatorState(ctx context.Context, id int64, state int64) error {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, `UPDATE actuators SET state = $2 WHERE id = $1`, id, state)
+	if err != nil {
+		return fmt.Errorf("failed to update actuator state: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrActuatorNotFound
+	}
+	return nil
+}
+
+func (r *ActuatorRepository) AddCommand(ctx context.Context, command *domain.Command) error {
+	if command == nil {
+		return errors.New("command is nil")
+	}
+
+	query := `
+		INSERT INTO commands (actuator_id, sensor_id, kind, state, duration_ms, status, error, created_by, created_at,
+			expires_at, delivered_at, completed_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		WHERE EXISTS (SELECT 1 FROM actuators WHERE id = $1)
+		RETURNING id
+	`
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, query, command.ActuatorID, command.SensorID, command.Kind,
+		command.State, command.Duration.Milliseconds(), command.Status, command.Error, command.CreatedBy,
+		command.CreatedAt, command.ExpiresAt, command.DeliveredAt, command.CompletedAt).Scan(&command.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return usecase.ErrActuatorNotFound
+		}
+		return fmt.Errorf("failed to save command: %w", err)
+	}
+	return nil
+}
+
+// UpdateCommandStatus меняет только открытую команду, поэтому из одновременных подтверждения
+// и истечения команды выполняется первое
+func (r *ActuatorRepository) UpdateCommandStatus(ctx context.Context, command *domain.Command) error {
+	if command == nil {
+		return errors.New("command is nil")
+	}
+
+	conn := transaction.Conn(ctx, r.pool)
+	query := `
+		UPDATE commands
+		SET status = $2, error = $3, delivered_at = $4, completed_at = $5
+		WHERE id = $1 AND status IN ('pending', 'delivered')
+	`
+	tag, err := conn.Exec(ctx, query, command.ID, command.Status, command.Error, command.DeliveredAt,
+		command.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update command: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := conn.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM commands WHERE id = $1)`, command.ID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to get command: %w", err)
+	}
+	if exists {
+		return usecase.ErrCommandCompleted
+	}
+	return usecase.ErrCommandNotFound
+}
+
+func (r *ActuatorRepository) GetCommandByID(ctx context.Context, id int64) (*domain.Command, error) {
+	commands, err := r.queryCommands(ctx, `SELECT `+commandColumns+` FROM commands WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(commands) == 0 {
+		return nil, usecase.ErrCommandNotFound
+	}
+	return &commands[0], nil
+}
+
+func (r *ActuatorRepository) GetCommands(ctx context.Context, filter domain.CommandFilter) ([]domain.Command, error) {
+	query := `
+		SELECT ` + commandColumns + `
+		FROM commands
+		WHERE ($1::bigint = 0 OR actuator_id = $1)
+			AND ($2::text = '' OR status = $2)
+		ORDER BY id DESC
+		LIMIT $3 OFFSET $4
+	`
+	return r.queryCommands(ctx, query, filter.ActuatorID, string(filter.Status), filter.Limit, filter.Offset)
+}
+
+func (r *ActuatorRepository) GetOpenCommandsBySensorID(ctx context.Context, sensorID int64) ([]domain.Command, error) {
+	query := `
+		SELECT ` + commandColumns + `
+		FROM commands
+		WHERE sensor_id = $1 AND status IN ('pending', 'delivered')
+		ORDER BY id
+	`
+	return r.queryCommands(ctx, query, sensorID)
+}
+
+// ExpireCommands отмечает команды истекшими одним запросом, временем завершения становится ExpiresAt
+func (r *ActuatorRepository) ExpireCommands(ctx context.Context, now time.Time) (int, error) {
+	query := `
+		UPDATE commands
+		SET status = 'expired', completed_at = expires_at
+		WHERE status IN ('pending', 'delivered') AND expires_at <= $1
+	`
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, query, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire commands: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func (r *ActuatorRepository) queryCommands(ctx context.Context, query string, args ...any) ([]domain.Command, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commands: %w", err)
+	}
+	defer rows.Close()
+
+	commands := []domain.Command{}
+	for rows.Next() {
+		var (
+			command    domain.Command
+			durationMs int64
+		)
+		err := rows.Scan(&command.ID, &command.ActuatorID, &command.SensorID, &command.Kind, &command.State,
+			&durationMs, &command.Status, &command.Error, &command.CreatedBy, &command.CreatedAt, &command.ExpiresAt,
+			&command.DeliveredAt, &command.CompletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan command: %w", err)
+		}
+		command.Duration = time.Duration(durationMs) * time.Millisecond
+		commands = append(commands, command)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through commands: %w", err)
+	}
+	return commands, nil
+}
+
+func scanActuator(row pgx.Row) (*domain.Actuator, error) {
+	var actuator domain.Actuator
+	err := row.Scan(&actuator.ID, &actuator.SensorID, &actuator.Name, &actuator.Type, &actuator.State,
+		&actuator.CreatedBy, &actuator.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &actuator, nil
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ActuatorTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	repo *ActuatorRepository
+}
+
+func (suite *ActuatorTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.repo = NewActuatorRepository(suite.testDbInstance)
+}
+
+func (suite *ActuatorTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *ActuatorTestSuite) TestActuatorRepository() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	actuator := domain.Actuator{SensorID: 1, Name: "pump", Type: domain.ActuatorTypeRelay, CreatedBy: 2, CreatedAt: now}
+	assert.Nil(suite.T(), suite.repo.SaveActuator(ctx, &actuator))
+	assert.NotZero(suite.T(), actuator.ID)
+
+	actuator.Name = "main pump"
+	assert.Nil(suite.T(), suite.repo.SaveActuator(ctx, &actuator))
+	assert.Nil(suite.T(), suite.repo.SetActuatorState(ctx, actuator.ID, 1))
+	actuator.State = 1
+
+	actuators, err := suite.repo.GetActuators(ctx)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.Actuator{actuator}, actuators)
+
+	assert.ErrorIs(suite.T(), suite.repo.SaveActuator(ctx, &domain.Actuator{ID: 1000}), usecase.ErrActuatorNotFound)
+	assert.ErrorIs(suite.T(), suite.repo.SetActuatorState(ctx, 1000, 1), usecase.ErrActuatorNotFound)
+	_, err = suite.repo.GetActuatorByID(ctx, 1000)
+	assert.ErrorIs(suite.T(), err, usecase.ErrActuatorNotFound)
+
+	setState := domain.Command{ActuatorID: actuator.ID, SensorID: 1, Kind: domain.CommandSetState, State: 1,
+		Status: domain.CommandPending, CreatedAt: now, ExpiresAt: now}
+	pulse := domain.Command{ActuatorID: actuator.ID, SensorID: 1, Kind: domain.CommandPulse,
+		Duration: 1500 * time.Millisecond, Status: domain.CommandPending, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	for _, c := range []*domain.Command{&setState, &pulse} {
+		assert.Nil(suite.T(), suite.repo.AddCommand(ctx, c))
+		assert.NotZero(suite.T(), c.ID)
+	}
+	assert.ErrorIs(suite.T(), suite.repo.AddCommand(ctx, &domain.Command{ActuatorID: 1000}), usecase.ErrActuatorNotFound)
+
+	expired, err := suite.repo.ExpireCommands(ctx, now)
+	require.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, expired)
+
+	setState.Status = domain.CommandAcked
+	assert.ErrorIs(suite.T(), suite.repo.UpdateCommandStatus(ctx, &setState), usecase.ErrCommandCompleted)
+	assert.ErrorIs(suite.T(), suite.repo.UpdateCommandStatus(ctx, &domain.Command{ID: 1000}), usecase.ErrCommandNotFound)
+
+	open, err := suite.repo.GetOpenCommandsBySensorID(ctx, 1)
+	require.Nil(suite.T(), err)
+	require.Len(suite.T(), open, 1)
+	assert.Equal(suite.T(), pulse, open[0])
+
+	pulse.Status = domain.CommandFailed
+	pulse.Error = "relay stuck"
+	pulse.DeliveredAt = &now
+	pulse.CompletedAt = &now
+	assert.Nil(suite.T(), suite.repo.UpdateCommandStatus(ctx, &pulse))
+
+	stored, err := suite.repo.GetCommandByID(ctx, pulse.ID)
+	require.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &pulse, stored)
+
+	commands, err := suite.repo.GetCommands(ctx, domain.CommandFilter{ActuatorID: actuator.ID, Status: domain.CommandExpired, Limit: 10})
+	require.Nil(suite.T(), err)
+	require.Len(suite.T(), commands, 1)
+	assert.Equal(suite.T(), setState.ID, commands[0].ID)
+	assert.Equal(suite.T(), now, *commands[0].CompletedAt)
+
+	assert.Nil(suite.T(), suite.repo.DeleteActuator(ctx, actuator.ID))
+	assert.ErrorIs(suite.T(), suite.repo.DeleteActuator(ctx, actuator.ID), usecase.ErrActuatorNotFound)
+	_, err = suite.repo.GetCommandByID(ctx, pulse.ID)
+	assert.ErrorIs(suite.T(), err, usecase.ErrCommandNotFound)
+}
+
+func TestActuatorTestSuite(t *testing.T) {
+	suite.Run(t, new(ActuatorTestSuite))
+}
@@ -0,0 +1,184 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"sync"
+	"time"
+)
+
+// AlertRepository хранит оповещения в порядке создания
+type AlertRepository struct {
+	alerts []domain.Alert
+	mu     sync.RWMutex
+}
+
+func NewAlertRepository() *AlertRepository {
+	return &AlertRepository{}
+}
+
+func (r *AlertRepository) SaveAlert(ctx context.Context, alert *domain.Alert) error {
+	if alert == nil {
+		return errors.New("alert is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	alert.ID = int64(len(r.alerts)) + 1
+	r.alerts = append(r.alerts, copyAlert(*alert))
+
+	return nil
+}
+
+func (r *AlertRepository) AddAlertOccurrence(ctx context.Context, id int64, value float64, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.alert(id)
+	if err != nil {
+		return err
+	}
+	stored.Occurrences++
+	stored.Value = value
+	stored.LastOccurredAt = at
+
+	return nil
+}
+
+func (r *AlertRepository) UpdateAlertStatus(ctx context.Context, alert *domain.Alert) error {
+	if alert == nil {
+		return errors.New("alert is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.alert(alert.ID)
+	if err != nil {
+		return err
+	}
+	if !stored.IsActive() {
+		return usecase.ErrAlertResolved
+	}
+
+	updated := copyAlert(*alert)
+	stored.Status = updated.Status
+	stored.AcknowledgedAt = updated.AcknowledgedAt
+	stored.AcknowledgedBy = updated.AcknowledgedBy
+	stored.ResolvedAt = updated.ResolvedAt
+	stored.ResolvedBy = updated.ResolvedBy
+
+	return nil
+}
+
+func (r *AlertRepository) GetAlertByID(ctx context.Context, id int64) (*domain.Alert, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, err := r.alert(id)
+	if err != nil {
+		return nil, err
+	}
+
+	alert := copyAlert(*stored)
+	return &alert, nil
+}
+
+func (r *AlertRepository) GetActiveAlertByRuleID(ctx context.Context, ruleID int64) (*domain.Alert, error) {
+	return r.getActiveAlert(ctx, func(alert *domain.Alert) bool {
+		return alert.RuleID == ruleID && alert.AutomationID == 0
+	})
+}
+
+func (r *AlertRepository) GetActiveAlertByAutomationID(ctx context.Context, automationID int64) (*domain.Alert, error) {
+	return r.getActiveAlert(ctx, func(alert *domain.Alert) bool {
+		return alert.AutomationID == automationID && alert.RuleID == 0
+	})
+}
+
+func (r *AlertRepository) GetActiveAnomalyAlert(ctx context.Context, sensorID int64) (*domain.Alert, error) {
+	return r.getActiveAlert(ctx, func(alert *domain.Alert) bool {
+		return alert.Anomaly && alert.SensorID == sensorID
+	})
+}
+
+func (r *AlertRepository) getActiveAlert(ctx context.Context, match func(*domain.Alert) bool) (*domain.Alert, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := len(r.alerts) - 1; i >= 0; i-- {
+		if match(&r.alerts[i]) && r.alerts[i].IsActive() {
+			alert := copyAlert(r.alerts[i])
+			return &alert, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *AlertRepository) GetAlerts(ctx context.Context, filter domain.AlertFilter) ([]domain.Alert, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]domain.Alert, 0)
+	skipped := 0
+	for i := len(r.alerts) - 1; i >= 0 && len(result) < filter.Limit; i-- {
+		if !filter.Matches(&r.alerts[i]) {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		result = append(result, copyAlert(r.alerts[i]))
+	}
+
+	return result, nil
+}
+
+// alert возвращает сохраненное оповещение для изменения под блокировкой
+func (r *AlertRepository) alert(id int64) (*domain.Alert, error) {
+	if id <= 0 || id > int64(len(r.alerts)) {
+		return nil, usecase.ErrAlertNotFound
+	}
+	return &r.alerts[id-1], nil
+}
+
+func copyAlert(alert domain.Alert) domain.Alert {
+	if alert.AcknowledgedAt != nil {
+		acknowledgedAt := *alert.AcknowledgedAt
+		alert.AcknowledgedAt = &acknowledgedAt
+	}
+	if alert.ResolvedAt != nil {
+		resolvedAt := *alert.ResolvedAt
+		alert.ResolvedAt = &resolvedAt
+	}
+	return alert
+}
@@ -0,0 +1,142 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertRepository(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		ar := NewAlertRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := ar.SaveAlert(ctx, &domain.Alert{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("fail, not found", func(t *testing.T) {
+		ar := NewAlertRepository()
+		ctx := context.Background()
+
+		_, err := ar.GetAlertByID(ctx, 1)
+		assert.ErrorIs(t, err, usecase.ErrAlertNotFound)
+		assert.ErrorIs(t, ar.AddAlertOccurrence(ctx, 1, 1, time.Now()), usecase.ErrAlertNotFound)
+		assert.ErrorIs(t, ar.UpdateAlertStatus(ctx, &domain.Alert{ID: 1}), usecase.ErrAlertNotFound)
+	})
+
+	t.Run("ok, lifecycle", func(t *testing.T) {
+		ar := NewAlertRepository()
+		ctx := context.Background()
+		now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		alert := &domain.Alert{RuleID: 1, SensorID: 1, Status: domain.AlertStatusOpen, Value: 801, Occurrences: 1,
+			CreatedAt: now, LastOccurredAt: now}
+		require.NoError(t, ar.SaveAlert(ctx, alert))
+		assert.Equal(t, int64(1), alert.ID)
+
+		require.NoError(t, ar.AddAlertOccurrence(ctx, alert.ID, 900, now.Add(time.Minute)))
+
+		active, err := ar.GetActiveAlertByRuleID(ctx, 1)
+		require.NoError(t, err)
+		require.NotNil(t, active)
+		assert.Equal(t, 2, active.Occurrences)
+		assert.Equal(t, float64(900), active.Value)
+		assert.Equal(t, now.Add(time.Minute), active.LastOccurredAt)
+
+		resolvedAt := now.Add(2 * time.Minute)
+		active.Status = domain.AlertStatusResolved
+		active.ResolvedAt = &resolvedAt
+		active.ResolvedBy = 3
+		require.NoError(t, ar.UpdateAlertStatus(ctx, active))
+		assert.ErrorIs(t, ar.UpdateAlertStatus(ctx, active), usecase.ErrAlertResolved)
+
+		active, err = ar.GetActiveAlertByRuleID(ctx, 1)
+		require.NoError(t, err)
+		assert.Nil(t, active)
+
+		stored, err := ar.GetAlertByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, domain.AlertStatusResolved, stored.Status)
+		assert.Equal(t, resolvedAt, *stored.ResolvedAt)
+		assert.Equal(t, int64(3), stored.ResolvedBy)
+	})
+
+	t.Run("ok, rule and automation alerts are separate", func(t *testing.T) {
+		ar := NewAlertRepository()
+		ctx := context.Background()
+
+		require.NoError(t, ar.SaveAlert(ctx, &domain.Alert{RuleID: 1, SensorID: 1, Status: domain.AlertStatusOpen}))
+		require.NoError(t, ar.SaveAlert(ctx, &domain.Alert{AutomationID: 1, SensorID: 1, Status: domain.AlertStatusOpen}))
+
+		active, err := ar.GetActiveAlertByRuleID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), active.ID)
+
+		active, err = ar.GetActiveAlertByAutomationID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), active.ID)
+	})
+
+	t.Run("ok, anomaly alerts are kept per sensor", func(t *testing.T) {
+		ar := NewAlertRepository()
+		ctx := context.Background()
+
+		require.NoError(t, ar.SaveAlert(ctx, &domain.Alert{Anomaly: true, SensorID: 1, Status: domain.AlertStatusOpen}))
+		require.NoError(t, ar.SaveAlert(ctx, &domain.Alert{Anomaly: true, SensorID: 2, Status: domain.AlertStatusOpen}))
+		require.NoError(t, ar.SaveAlert(ctx, &domain.Alert{RuleID: 1, SensorID: 2, Status: domain.AlertStatusOpen}))
+
+		active, err := ar.GetActiveAnomalyAlert(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), active.ID)
+
+		active, err = ar.GetActiveAnomalyAlert(ctx, 3)
+		require.NoError(t, err)
+		assert.Nil(t, active)
+	})
+
+	t.Run("ok, newest first with filter and pagination", func(t *testing.T) {
+		ar := NewAlertRepository()
+		ctx := context.Background()
+
+		alerts := []domain.Alert{
+			{RuleID: 1, SensorID: 1, Status: domain.AlertStatusResolved},
+			{RuleID: 2, SensorID: 2, Status: domain.AlertStatusOpen},
+			{RuleID: 1, SensorID: 1, Status: domain.AlertStatusOpen},
+			{RuleID: 3, SensorID: 3, Status: domain.AlertStatusAcknowledged},
+		}
+		for i := range alerts {
+			require.NoError(t, ar.SaveAlert(ctx, &alerts[i]))
+		}
+
+		result, err := ar.GetAlerts(ctx, domain.AlertFilter{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{4, 3, 2, 1}, alertIDs(result))
+
+		result, err = ar.GetAlerts(ctx, domain.AlertFilter{Status: domain.AlertStatusOpen, Limit: 1, Offset: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2}, alertIDs(result))
+
+		result, err = ar.GetAlerts(ctx, domain.AlertFilter{RuleID: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{3, 1}, alertIDs(result))
+
+		result, err = ar.GetAlerts(ctx, domain.AlertFilter{SensorIDs: []int64{2, 3}, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{4, 2}, alertIDs(result))
+	})
+}
+
+func alertIDs(alerts []domain.Alert) []int64 {
+	ids := make([]int64, 0, len(alerts))
+	for _, alert := range alerts {
+		ids = append(ids, alert.ID)
+	}
+	return ids
+}
@@ -0,0 +1,174 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const alertColumns = `id, rule_id, automation_id, anomaly, sensor_id, status, value, occurrences, created_at, last_occurred_at,
+	acknowledged_at, acknowledged_by, resolved_at, resolved_by`
+
+// AlertRepository хранит оповещения в таблице alerts. Уникальные индексы по rule_id и automation_id
+// и по sensor_id оповещений об аномалиях для незакрытых оповещений не дают создать второе незакрытое
+// оповещение правила, автоматизации или об аномалиях датчика.
+type AlertRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAlertRepository(pool *pgxpool.Pool) *AlertRepository {
+	return &AlertRepository{
+		pool: pool,
+	}
+}
+
+func (r *AlertRepository) SaveAlert(ctx context.Context, alert *domain.Alert) error {
+	if alert == nil {
+		return errors.New("alert is nil")
+	}
+
+	query := `
+		INSERT INTO alerts (rule_id, automation_id, anomaly, sensor_id, status, value, occurrences, created_at,
+			last_occurred_at, acknowledged_at, acknowledged_by, resolved_at, resolved_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id
+	`
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, query, alert.RuleID, alert.AutomationID, alert.Anomaly, alert.SensorID, alert.Status, alert.Value,
+		alert.Occurrences, alert.CreatedAt, alert.LastOccurredAt, alert.AcknowledgedAt, alert.AcknowledgedBy,
+		alert.ResolvedAt, alert.ResolvedBy).Scan(&alert.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save alert: %w", err)
+	}
+	return nil
+}
+
+// AddAlertOccurrence увеличивает счетчик одним запросом, поэтому одновременные срабатывания не теряются
+func (r *AlertRepository) AddAlertOccurrence(ctx context.Context, id int64, value float64, at time.Time) error {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, `
+		UPDATE alerts SET occurrences = occurrences + 1, value = $2, last_occurred_at = $3 WHERE id = $1
+	`, id, value, at)
+	if err != nil {
+		return fmt.Errorf("failed to add alert occurrence: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrAlertNotFound
+	}
+	return nil
+}
+
+// UpdateAlertStatus меняет только незакрытое оповещение, поэтому подтверждение не откроет заново
+// оповещение, закрытое одновременно с ним
+func (r *AlertRepository) UpdateAlertStatus(ctx context.Context, alert *domain.Alert) error {
+	if alert == nil {
+		return errors.New("alert is nil")
+	}
+
+	conn := transaction.Conn(ctx, r.pool)
+	query := `
+		UPDATE alerts
+		SET status = $2, acknowledged_at = $3, acknowledged_by = $4, resolved_at = $5, resolved_by = $6
+		WHERE id = $1 AND status <> 'resolved'
+	`
+	tag, err := conn.Exec(ctx, query, alert.ID, alert.Status, alert.AcknowledgedAt, alert.AcknowledgedBy,
+		alert.ResolvedAt, alert.ResolvedBy)
+	if err != nil {
+		return fmt.Errorf("failed to update alert status: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := conn.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM alerts WHERE id = $1)`, alert.ID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check alert: %w", err)
+	}
+	if !exists {
+		return usecase.ErrAlertNotFound
+	}
+	return usecase.ErrAlertResolved
+}
+
+func (r *AlertRepository) GetAlertByID(ctx context.Context, id int64) (*domain.Alert, error) {
+	alert, err := scanAlert(transaction.Conn(ctx, r.pool).QueryRow(ctx, `SELECT `+alertColumns+` FROM alerts WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrAlertNotFound
+		}
+		return nil, fmt.Errorf("failed to get alert: %w", err)
+	}
+	return alert, nil
+}
+
+func (r *AlertRepository) GetActiveAlertByRuleID(ctx context.Context, ruleID int64) (*domain.Alert, error) {
+	return r.getActiveAlert(ctx, `rule_id = $1 AND automation_id = 0`, ruleID)
+}
+
+func (r *AlertRepository) GetActiveAlertByAutomationID(ctx context.Context, automationID int64) (*domain.Alert, error) {
+	return r.getActiveAlert(ctx, `automation_id = $1 AND rule_id = 0`, automationID)
+}
+
+func (r *AlertRepository) GetActiveAnomalyAlert(ctx context.Context, sensorID int64) (*domain.Alert, error) {
+	return r.getActiveAlert(ctx, `anomaly AND sensor_id = $1`, sensorID)
+}
+
+func (r *AlertRepository) getActiveAlert(ctx context.Context, condition string, id int64) (*domain.Alert, error) {
+	alert, err := scanAlert(transaction.Conn(ctx, r.pool).QueryRow(ctx,
+		`SELECT `+alertColumns+` FROM alerts WHERE `+condition+` AND status <> 'resolved'`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active alert: %w", err)
+	}
+	return alert, nil
+}
+
+func (r *AlertRepository) GetAlerts(ctx context.Context, filter domain.AlertFilter) ([]domain.Alert, error) {
+	query := `
+		SELECT ` + alertColumns + `
+		FROM alerts
+		WHERE ($1::bigint = 0 OR rule_id = $1)
+			AND ($2::bigint = 0 OR sensor_id = $2)
+			AND ($3::text = '' OR status = $3)
+			AND ($4::bigint[] IS NULL OR sensor_id = ANY ($4))
+		ORDER BY id DESC
+		LIMIT $5 OFFSET $6
+	`
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, filter.RuleID, filter.SensorID, string(filter.Status),
+		filter.SensorIDs, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []domain.Alert{}
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert: %w", err)
+		}
+		alerts = append(alerts, *alert)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+func scanAlert(row pgx.Row) (*domain.Alert, error) {
+	var alert domain.Alert
+	err := row.Scan(&alert.ID, &alert.RuleID, &alert.AutomationID, &alert.Anomaly, &alert.SensorID, &alert.Status, &alert.Value, &alert.Occurrences,
+		&alert.CreatedAt, &alert.LastOccurredAt, &alert.AcknowledgedAt, &alert.AcknowledgedBy, &alert.ResolvedAt,
+		&alert.ResolvedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type AlertTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	repo *AlertRepository
+}
+
+func (suite *AlertTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.repo = NewAlertRepository(suite.testDbInstance)
+}
+
+func (suite *AlertTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *AlertTestSuite) TestAlertRepository() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	first := domain.Alert{RuleID: 1, SensorID: 1, Status: domain.AlertStatusOpen, Value: 801, Occurrences: 1,
+		CreatedAt: now, LastOccurredAt: now}
+	other := domain.Alert{RuleID: 2, SensorID: 2, Status: domain.AlertStatusOpen, Value: 1, Occurrences: 1,
+		CreatedAt: now, LastOccurredAt: now}
+	for _, alert := range []*domain.Alert{&first, &other} {
+		assert.Nil(suite.T(), suite.repo.SaveAlert(ctx, alert))
+		assert.NotZero(suite.T(), alert.ID)
+	}
+
+	assert.Nil(suite.T(), suite.repo.AddAlertOccurrence(ctx, first.ID, 900, now.Add(time.Minute)))
+	assert.ErrorIs(suite.T(), suite.repo.AddAlertOccurrence(ctx, 1000, 900, now), usecase.ErrAlertNotFound)
+
+	active, err := suite.repo.GetActiveAlertByRuleID(ctx, 1)
+	assert.Nil(suite.T(), err)
+	first.Occurrences, first.Value, first.LastOccurredAt = 2, 900, now.Add(time.Minute)
+	assert.Equal(suite.T(), &first, active)
+
+	duplicate := domain.Alert{RuleID: 1, SensorID: 1, Status: domain.AlertStatusOpen, CreatedAt: now, LastOccurredAt: now}
+	assert.Error(suite.T(), suite.repo.SaveAlert(ctx, &duplicate), "only one active alert per rule")
+
+	resolvedAt := now.Add(2 * time.Minute)
+	first.Status, first.ResolvedAt, first.ResolvedBy = domain.AlertStatusResolved, &resolvedAt, 3
+	assert.Nil(suite.T(), suite.repo.UpdateAlertStatus(ctx, &first))
+	assert.ErrorIs(suite.T(), suite.repo.UpdateAlertStatus(ctx, &first), usecase.ErrAlertResolved)
+	assert.ErrorIs(suite.T(), suite.repo.UpdateAlertStatus(ctx, &domain.Alert{ID: 1000}), usecase.ErrAlertNotFound)
+
+	active, err = suite.repo.GetActiveAlertByRuleID(ctx, 1)
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), active)
+
+	stored, err := suite.repo.GetAlertByID(ctx, first.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &first, stored)
+
+	_, err = suite.repo.GetAlertByID(ctx, 1000)
+	assert.ErrorIs(suite.T(), err, usecase.ErrAlertNotFound)
+
+	alerts, err := suite.repo.GetAlerts(ctx, domain.AlertFilter{Limit: 10})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.Alert{other, first}, alerts)
+
+	alerts, err = suite.repo.GetAlerts(ctx, domain.AlertFilter{Status: domain.AlertStatusOpen, Limit: 10})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.Alert{other}, alerts)
+
+	alerts, err = suite.repo.GetAlerts(ctx, domain.AlertFilter{SensorIDs: []int64{1, 3}, Limit: 10})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.Alert{first}, alerts)
+
+	alerts, err = suite.repo.GetAlerts(ctx, domain.AlertFilter{RuleID: 2, Limit: 10, Offset: 1})
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), alerts)
+
+	// оповещение автоматизации не мешает оповещению правила с тем же id
+	automationAlert := domain.Alert{AutomationID: 2, SensorID: 2, Status: domain.AlertStatusOpen, CreatedAt: now,
+		LastOccurredAt: now}
+	assert.Nil(suite.T(), suite.repo.SaveAlert(ctx, &automationAlert))
+	active, err = suite.repo.GetActiveAlertByAutomationID(ctx, 2)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &automationAlert, active)
+	active, err = suite.repo.GetActiveAlertByRuleID(ctx, 2)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &other, active)
+
+	// незакрытые оповещения об аномалиях разных датчиков не мешают друг другу
+	for _, sensorID := range []int64{1, 2} {
+		anomalyAlert := domain.Alert{Anomaly: true, SensorID: sensorID, Status: domain.AlertStatusOpen, CreatedAt: now,
+			LastOccurredAt: now}
+		assert.Nil(suite.T(), suite.repo.SaveAlert(ctx, &anomalyAlert))
+		active, err = suite.repo.GetActiveAnomalyAlert(ctx, sensorID)
+		assert.Nil(suite.T(), err)
+		assert.Equal(suite.T(), &anomalyAlert, active)
+	}
+	assert.NotNil(suite.T(), suite.repo.SaveAlert(ctx, &domain.Alert{Anomaly: true, SensorID: 1,
+		Status: domain.AlertStatusOpen, CreatedAt: now, LastOccurredAt: now}))
+}
+
+func TestAlertTestSuite(t *testing.T) {
+	suite.Run(t, new(AlertTestSuite))
+}
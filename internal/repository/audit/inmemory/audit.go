@@ -0,0 +1,65 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"maps"
+	"sync"
+)
+
+// AuditRepository хранит журнал в порядке добавления, записи не изменяются и не удаляются
+type AuditRepository struct {
+	entries []domain.AuditEntry
+	mu      sync.RWMutex
+}
+
+func NewAuditRepository() *AuditRepository {
+	return &AuditRepository{}
+}
+
+func (r *AuditRepository) SaveAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	if entry == nil {
+		return errors.New("audit entry is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.ID = int64(len(r.entries)) + 1
+	stored := *entry
+	stored.Changes = maps.Clone(entry.Changes)
+	r.entries = append(r.entries, stored)
+
+	return nil
+}
+
+func (r *AuditRepository) GetAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]domain.AuditEntry, 0)
+	skipped := 0
+	for i := len(r.entries) - 1; i >= 0 && len(result) < filter.Limit; i-- {
+		entry := r.entries[i]
+		if !filter.Matches(&entry) {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		entry.Changes = maps.Clone(entry.Changes)
+		result = append(result, entry)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,51 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditRepository(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		ar := NewAuditRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := ar.SaveAuditEntry(ctx, &domain.AuditEntry{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, newest first with filter and pagination", func(t *testing.T) {
+		ar := NewAuditRepository()
+		ctx := context.Background()
+
+		entries := []domain.AuditEntry{
+			{ActorID: 1, Action: domain.AuditActionCreate, EntityType: domain.AuditEntitySensor, EntityID: 1},
+			{ActorID: 2, Action: domain.AuditActionCreate, EntityType: domain.AuditEntityHome, EntityID: 1},
+			{ActorID: 1, Action: domain.AuditActionUpdate, EntityType: domain.AuditEntitySensor, EntityID: 1},
+			{ActorID: 1, Action: domain.AuditActionCreate, EntityType: domain.AuditEntitySensor, EntityID: 2},
+		}
+		for i := range entries {
+			require.NoError(t, ar.SaveAuditEntry(ctx, &entries[i]))
+		}
+
+		result, err := ar.GetAuditEntries(ctx, domain.AuditFilter{EntityType: domain.AuditEntitySensor, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		assert.Equal(t, []int64{4, 3, 1}, []int64{result[0].ID, result[1].ID, result[2].ID})
+
+		result, err = ar.GetAuditEntries(ctx, domain.AuditFilter{EntityType: domain.AuditEntitySensor, EntityID: 1, Limit: 1, Offset: 1})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, int64(1), result[0].ID)
+
+		result, err = ar.GetAuditEntries(ctx, domain.AuditFilter{ActorID: 2, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, domain.AuditEntityHome, result[0].EntityType)
+	})
+}
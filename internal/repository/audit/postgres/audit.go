@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AuditRepository пишет журнал в таблицу audit_log, изменение и удаление ее записей запрещено триггером
+type AuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAuditRepository(pool *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{
+		pool: pool,
+	}
+}
+
+func (r *AuditRepository) SaveAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	if entry == nil {
+		return errors.New("audit entry is nil")
+	}
+
+	changes := entry.Changes
+	if changes == nil {
+		changes = map[string]domain.AuditChange{}
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit changes: %w", err)
+	}
+
+	query := `
+		INSERT INTO audit_log (actor_id, action, entity_type, entity_id, changes, request_id, source_ip, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+	err = transaction.Conn(ctx, r.pool).QueryRow(ctx, query, entry.ActorID, entry.Action, entry.EntityType,
+		entry.EntityID, data, entry.RequestID, entry.SourceIP, entry.CreatedAt).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save audit entry: %w", err)
+	}
+	return nil
+}
+
+func (r *AuditRepository) GetAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	query := `
+		SELECT id, actor_id, action, entity_type, entity_id, changes, request_id, source_ip, created_at
+		FROM audit_log
+		WHERE ($1::text = '' OR entity_type = $1)
+			AND ($2::bigint = 0 OR entity_id = $2)
+			AND ($3::bigint = 0 OR actor_id = $3)
+		ORDER BY id DESC
+		LIMIT $4 OFFSET $5
+	`
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, string(filter.EntityType), filter.EntityID,
+		filter.ActorID, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []domain.AuditEntry{}
+	for rows.Next() {
+		var (
+			entry domain.AuditEntry
+			data  []byte
+		)
+		if err := rows.Scan(&entry.ID, &entry.ActorID, &entry.Action, &entry.EntityType, &entry.EntityID, &data,
+			&entry.RequestID, &entry.SourceIP, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		if err := json.Unmarshal(data, &entry.Changes); err != nil {
+			return nil, fmt.Errorf("failed to decode audit changes: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through audit log: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	repo *AuditRepository
+}
+
+func (suite *AuditTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.repo = NewAuditRepository(suite.testDbInstance)
+}
+
+func (suite *AuditTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *AuditTestSuite) TestAuditRepository() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	created := domain.AuditEntry{
+		ActorID:    1,
+		Action:     domain.AuditActionCreate,
+		EntityType: domain.AuditEntityHome,
+		EntityID:   1,
+		Changes:    map[string]domain.AuditChange{"Name": {After: "Дача"}},
+		RequestID:  "req-1",
+		SourceIP:   "10.0.0.1",
+		CreatedAt:  now,
+	}
+	updated := created
+	updated.Action = domain.AuditActionUpdate
+	updated.Changes = map[string]domain.AuditChange{"Name": {Before: "Дача", After: "Дом"}}
+	other := created
+	other.ActorID = 2
+	other.EntityID = 2
+
+	for _, entry := range []*domain.AuditEntry{&created, &updated, &other} {
+		assert.Nil(suite.T(), suite.repo.SaveAuditEntry(ctx, entry))
+	}
+
+	entries, err := suite.repo.GetAuditEntries(ctx, domain.AuditFilter{EntityType: domain.AuditEntityHome, EntityID: 1, Limit: 10})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.AuditEntry{updated, created}, entries)
+
+	entries, err = suite.repo.GetAuditEntries(ctx, domain.AuditFilter{ActorID: 1, Limit: 1, Offset: 1})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.AuditEntry{created}, entries)
+
+	_, err = suite.testDbInstance.Exec(ctx, `DELETE FROM audit_log WHERE id = $1`, created.ID)
+	assert.Error(suite.T(), err)
+}
+
+func TestAuditTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditTestSuite))
+}
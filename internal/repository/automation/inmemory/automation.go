@@ -0,0 +1,262 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// AutomationRepository хранит автоматизации, сцены и расписания по id, а журнал запусков - в порядке запусков
+type AutomationRepository struct {
+	automations    map[int64]domain.Automation
+	scenes         map[int64]domain.Scene
+	schedules      map[int64]domain.Schedule
+	runs           []domain.AutomationRun
+	mu             sync.RWMutex
+	lastID         int64
+	lastSceneID    int64
+	lastScheduleID int64
+	lastRunID      int64
+}
+
+func NewAutomationRepository() *AutomationRepository {
+	return &AutomationRepository{
+		automations: make(map[int64]domain.Automation),
+		scenes:      make(map[int64]domain.Scene),
+		schedules:   make(map[int64]domain.Schedule),
+	}
+}
+
+func (r *AutomationRepository) SaveAutomation(ctx context.Context, automation *domain.Automation) error {
+	if automation == nil {
+		return errors.New("automation is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if automation.ID == 0 {
+		r.lastID++
+		automation.ID = r.lastID
+	} else if _, ok := r.automations[automation.ID]; !ok {
+		return usecase.ErrAutomationNotFound
+	}
+
+	r.automations[automation.ID] = copyAutomation(*automation)
+
+	return nil
+}
+
+func (r *AutomationRepository) GetAutomations(ctx context.Context) ([]domain.Automation, error) {
+	return r.getAutomations(ctx, func(*domain.Automation) bool { return true })
+}
+
+func (r *AutomationRepository) GetAutomationsByTriggerSensorID(ctx context.Context, sensorID int64) ([]domain.Automation, error) {
+	return r.getAutomations(ctx, func(automation *domain.Automation) bool {
+		return automation.Trigger.SensorID == sensorID
+	})
+}
+
+func (r *AutomationRepository) GetAutomationByID(ctx context.Context, id int64) (*domain.Automation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	automation, ok := r.automations[id]
+	if !ok {
+		return nil, usecase.ErrAutomationNotFound
+	}
+
+	result := copyAutomation(automation)
+	return &result, nil
+}
+
+func (r *AutomationRepository) DeleteAutomation(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.automations[id]; !ok {
+		return usecase.ErrAutomationNotFound
+	}
+	delete(r.automations, id)
+
+	r.runs = slices.DeleteFunc(r.runs, func(run domain.AutomationRun) bool {
+		return run.AutomationID == id
+	})
+
+	return nil
+}
+
+func (r *AutomationRepository) SaveScene(ctx context.Context, scene *domain.Scene) error {
+	if scene == nil {
+		return errors.New("scene is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if scene.ID == 0 {
+		r.lastSceneID++
+		scene.ID = r.lastSceneID
+	} else if _, ok := r.scenes[scene.ID]; !ok {
+		return usecase.ErrSceneNotFound
+	}
+
+	stored := *scene
+	stored.Actions = slices.Clone(scene.Actions)
+	r.scenes[scene.ID] = stored
+
+	return nil
+}
+
+func (r *AutomationRepository) GetScenes(ctx context.Context) ([]domain.Scene, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	scenes := make([]domain.Scene, 0, len(r.scenes))
+	for _, scene := range r.scenes {
+		scene.Actions = slices.Clone(scene.Actions)
+		scenes = append(scenes, scene)
+	}
+	sort.Slice(scenes, func(i, j int) bool { return scenes[i].ID < scenes[j].ID })
+
+	return scenes, nil
+}
+
+func (r *AutomationRepository) GetSceneByID(ctx context.Context, id int64) (*domain.Scene, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	scene, ok := r.scenes[id]
+	if !ok {
+		return nil, usecase.ErrSceneNotFound
+	}
+
+	scene.Actions = slices.Clone(scene.Actions)
+	return &scene, nil
+}
+
+func (r *AutomationRepository) DeleteScene(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.scenes[id]; !ok {
+		return usecase.ErrSceneNotFound
+	}
+	delete(r.scenes, id)
+
+	r.runs = slices.DeleteFunc(r.runs, func(run domain.AutomationRun) bool {
+		return run.SceneID == id
+	})
+
+	return nil
+}
+
+func (r *AutomationRepository) AddAutomationRun(ctx context.Context, run *domain.AutomationRun) error {
+	if run == nil {
+		return errors.New("automation run is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastRunID++
+	run.ID = r.lastRunID
+	r.runs = append(r.runs, *run)
+
+	return nil
+}
+
+func (r *AutomationRepository) GetAutomationRuns(ctx context.Context, filter domain.AutomationRunFilter) ([]domain.AutomationRun, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]domain.AutomationRun, 0)
+	skipped := 0
+	for i := len(r.runs) - 1; i >= 0 && len(result) < filter.Limit; i-- {
+		if !filter.Matches(&r.runs[i]) {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		result = append(result, r.runs[i])
+	}
+
+	return result, nil
+}
+
+func (r *AutomationRepository) getAutomations(ctx context.Context, match func(*domain.Automation) bool) ([]domain.Automation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	automations := make([]domain.Automation, 0)
+	for _, automation := range r.automations {
+		if match(&automation) {
+			automations = append(automations, copyAutomation(automation))
+		}
+	}
+	sort.Slice(automations, func(i, j int) bool { return automations[i].ID < automations[j].ID })
+
+	return automations, nil
+}
+
+func copyAutomation(automation domain.Automation) domain.Automation {
+	automation.Conditions = slices.Clone(automation.Conditions)
+	for i, condition := range automation.Conditions {
+		if condition.Window != nil {
+			window := *condition.Window
+			automation.Conditions[i].Window = &window
+		}
+		if condition.Sun != nil {
+			sun := *condition.Sun
+			automation.Conditions[i].Sun = &sun
+		}
+	}
+	automation.Actions = slices.Clone(automation.Actions)
+	return automation
+}
@@ -0,0 +1,102 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutomationRepository(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		ar := NewAutomationRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := ar.SaveAutomation(ctx, &domain.Automation{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("fail, unknown automation and scene", func(t *testing.T) {
+		ar := NewAutomationRepository()
+		ctx := context.Background()
+
+		assert.ErrorIs(t, ar.SaveAutomation(ctx, &domain.Automation{ID: 5}), usecase.ErrAutomationNotFound)
+		assert.ErrorIs(t, ar.DeleteAutomation(ctx, 5), usecase.ErrAutomationNotFound)
+		assert.ErrorIs(t, ar.SaveScene(ctx, &domain.Scene{ID: 5}), usecase.ErrSceneNotFound)
+		_, err := ar.GetSceneByID(ctx, 5)
+		assert.ErrorIs(t, err, usecase.ErrSceneNotFound)
+	})
+
+	t.Run("ok, stored copies are independent", func(t *testing.T) {
+		ar := NewAutomationRepository()
+		ctx := context.Background()
+
+		automation := &domain.Automation{
+			Name:    "night light",
+			Trigger: domain.AutomationTrigger{SensorID: 5, Operator: domain.RuleOperatorEqual, Value: 1},
+			Conditions: []domain.AutomationCondition{
+				{Type: domain.ConditionTimeWindow, Window: &domain.RuleWindow{From: 1200, To: 360, TimeZone: "UTC"}},
+				{Type: domain.ConditionSun, HomeID: 1, Sun: &domain.SunCondition{After: "sunset"}},
+			},
+			Actions: []domain.AutomationAction{{Type: domain.ActionAlert}},
+		}
+		require.NoError(t, ar.SaveAutomation(ctx, automation))
+		automation.Conditions[0].Window.From = 0
+		automation.Conditions[1].Sun.After = "sunrise"
+		automation.Actions[0].Type = domain.ActionWebhook
+
+		stored, err := ar.GetAutomationByID(ctx, automation.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1200, stored.Conditions[0].Window.From)
+		assert.Equal(t, "sunset", stored.Conditions[1].Sun.After)
+		assert.Equal(t, domain.ActionAlert, stored.Actions[0].Type)
+
+		automations, err := ar.GetAutomationsByTriggerSensorID(ctx, 5)
+		require.NoError(t, err)
+		assert.Len(t, automations, 1)
+
+		automations, err = ar.GetAutomationsByTriggerSensorID(ctx, 6)
+		require.NoError(t, err)
+		assert.Empty(t, automations)
+	})
+
+	t.Run("ok, runs are paged newest first and deleted with their owner", func(t *testing.T) {
+		ar := NewAutomationRepository()
+		ctx := context.Background()
+
+		automation := &domain.Automation{Name: "a"}
+		scene := &domain.Scene{Name: "s"}
+		require.NoError(t, ar.SaveAutomation(ctx, automation))
+		require.NoError(t, ar.SaveScene(ctx, scene))
+
+		for i, status := range []domain.AutomationRunStatus{domain.AutomationRunSucceeded, domain.AutomationRunSkipped, domain.AutomationRunFailed} {
+			run := &domain.AutomationRun{AutomationID: automation.ID, Status: status, CreatedAt: now.Add(time.Duration(i) * time.Minute)}
+			require.NoError(t, ar.AddAutomationRun(ctx, run))
+		}
+		require.NoError(t, ar.AddAutomationRun(ctx, &domain.AutomationRun{SceneID: scene.ID, Status: domain.AutomationRunSucceeded}))
+
+		runs, err := ar.GetAutomationRuns(ctx, domain.AutomationRunFilter{AutomationID: automation.ID, Limit: 2, Offset: 1})
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		assert.Equal(t, domain.AutomationRunSkipped, runs[0].Status)
+		assert.Equal(t, domain.AutomationRunSucceeded, runs[1].Status)
+
+		require.NoError(t, ar.DeleteAutomation(ctx, automation.ID))
+		runs, err = ar.GetAutomationRuns(ctx, domain.AutomationRunFilter{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		assert.Equal(t, scene.ID, runs[0].SceneID)
+
+		require.NoError(t, ar.DeleteScene(ctx, scene.ID))
+		runs, err = ar.GetAutomationRuns(ctx, domain.AutomationRunFilter{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, runs)
+	})
+}
@@ -0,0 +1,132 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"slices"
+	"sort"
+	"time"
+)
+
+func (r *AutomationRepository) SaveSchedule(ctx context.Context, schedule *domain.Schedule) error {
+	if schedule == nil {
+		return errors.New("schedule is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if schedule.ID == 0 {
+		r.lastScheduleID++
+		schedule.ID = r.lastScheduleID
+	} else if _, ok := r.schedules[schedule.ID]; !ok {
+		return usecase.ErrScheduleNotFound
+	}
+
+	r.schedules[schedule.ID] = *schedule
+
+	return nil
+}
+
+func (r *AutomationRepository) GetSchedules(ctx context.Context) ([]domain.Schedule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedules := make([]domain.Schedule, 0, len(r.schedules))
+	for _, schedule := range r.schedules {
+		schedules = append(schedules, schedule)
+	}
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].ID < schedules[j].ID })
+
+	return schedules, nil
+}
+
+func (r *AutomationRepository) GetScheduleByID(ctx context.Context, id int64) (*domain.Schedule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedule, ok := r.schedules[id]
+	if !ok {
+		return nil, usecase.ErrScheduleNotFound
+	}
+
+	return &schedule, nil
+}
+
+func (r *AutomationRepository) GetDueSchedules(ctx context.Context, now time.Time) ([]domain.Schedule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedules := make([]domain.Schedule, 0)
+	for _, schedule := range r.schedules {
+		if schedule.Enabled && !schedule.NextRunAt.IsZero() && !schedule.NextRunAt.After(now) {
+			schedules = append(schedules, schedule)
+		}
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		if !schedules[i].NextRunAt.Equal(schedules[j].NextRunAt) {
+			return schedules[i].NextRunAt.Before(schedules[j].NextRunAt)
+		}
+		return schedules[i].ID < schedules[j].ID
+	})
+
+	return schedules, nil
+}
+
+func (r *AutomationRepository) AdvanceSchedule(ctx context.Context, id int64, scheduled, next, last time.Time) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schedule, ok := r.schedules[id]
+	if !ok || !schedule.NextRunAt.Equal(scheduled) {
+		return false, nil
+	}
+
+	schedule.NextRunAt = next
+	schedule.LastRunAt = last
+	r.schedules[id] = schedule
+
+	return true, nil
+}
+
+func (r *AutomationRepository) DeleteSchedule(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.schedules[id]; !ok {
+		return usecase.ErrScheduleNotFound
+	}
+	delete(r.schedules, id)
+
+	r.runs = slices.DeleteFunc(r.runs, func(run domain.AutomationRun) bool {
+		return run.ScheduleID == id
+	})
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleRepository(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("fail, unknown schedule", func(t *testing.T) {
+		ar := NewAutomationRepository()
+		ctx := context.Background()
+
+		assert.ErrorIs(t, ar.SaveSchedule(ctx, &domain.Schedule{ID: 5}), usecase.ErrScheduleNotFound)
+		assert.ErrorIs(t, ar.DeleteSchedule(ctx, 5), usecase.ErrScheduleNotFound)
+		_, err := ar.GetScheduleByID(ctx, 5)
+		assert.ErrorIs(t, err, usecase.ErrScheduleNotFound)
+	})
+
+	t.Run("ok, due schedules are claimed once", func(t *testing.T) {
+		ar := NewAutomationRepository()
+		ctx := context.Background()
+
+		for _, schedule := range []*domain.Schedule{
+			{Name: "late", Enabled: true, NextRunAt: now.Add(-time.Minute)},
+			{Name: "later", Enabled: true, NextRunAt: now.Add(-time.Hour)},
+			{Name: "future", Enabled: true, NextRunAt: now.Add(time.Minute)},
+			{Name: "disabled", NextRunAt: now.Add(-time.Minute)},
+			{Name: "finished", Enabled: true},
+		} {
+			require.NoError(t, ar.SaveSchedule(ctx, schedule))
+		}
+
+		due, err := ar.GetDueSchedules(ctx, now)
+		require.NoError(t, err)
+		require.Len(t, due, 2)
+		assert.Equal(t, "later", due[0].Name)
+		assert.Equal(t, "late", due[1].Name)
+
+		next := now.Add(time.Hour)
+		claimed, err := ar.AdvanceSchedule(ctx, due[0].ID, due[0].NextRunAt, next, now)
+		require.NoError(t, err)
+		assert.True(t, claimed)
+		claimed, err = ar.AdvanceSchedule(ctx, due[0].ID, due[0].NextRunAt, next, now)
+		require.NoError(t, err)
+		assert.False(t, claimed)
+
+		stored, err := ar.GetScheduleByID(ctx, due[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, next, stored.NextRunAt)
+		assert.Equal(t, now, stored.LastRunAt)
+	})
+
+	t.Run("ok, runs are deleted with their schedule", func(t *testing.T) {
+		ar := NewAutomationRepository()
+		ctx := context.Background()
+
+		schedule := &domain.Schedule{Name: "s"}
+		require.NoError(t, ar.SaveSchedule(ctx, schedule))
+		require.NoError(t, ar.AddAutomationRun(ctx, &domain.AutomationRun{ScheduleID: schedule.ID, Status: domain.AutomationRunSkipped}))
+
+		runs, err := ar.GetAutomationRuns(ctx, domain.AutomationRunFilter{ScheduleID: schedule.ID, Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, runs, 1)
+
+		require.NoError(t, ar.DeleteSchedule(ctx, schedule.ID))
+		runs, err = ar.GetAutomationRuns(ctx, domain.AutomationRunFilter{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, runs)
+	})
+}
@@ -0,0 +1,438 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	automationColumns = `id, name, enabled, trigger, conditions, actions, created_by, created_at`
+	sceneColumns      = `id, name, actions, created_by, created_at`
+	runColumns        = `id, coalesce(automation_id, 0), coalesce(scene_id, 0), coalesce(schedule_id, 0), sensor_id, status, error,
+		created_at`
+)
+
+// AutomationRepository хранит автоматизации в таблице automations, сцены - в scenes, расписания - в schedules,
+// журнал запусков - в automation_runs. Триггер, условия и действия хранятся в jsonb, длительность команды -
+// в миллисекундах.
+type AutomationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAutomationRepository(pool *pgxpool.Pool) *AutomationRepository {
+	return &AutomationRepository{
+		pool: pool,
+	}
+}
+
+// triggerRecord - представление триггера в колонке trigger
+type triggerRecord struct {
+	SensorID int64               `json:"sensor_id"`
+	Operator domain.RuleOperator `json:"operator"`
+	Value    float64             `json:"value"`
+}
+
+// conditionRecord - представление условия в колонке conditions
+type conditionRecord struct {
+	Type     domain.ConditionType `json:"type"`
+	SensorID int64                `json:"sensor_id,omitempty"`
+	Operator domain.RuleOperator  `json:"operator,omitempty"`
+	Value    float64              `json:"value,omitempty"`
+	Window   *windowRecord        `json:"window,omitempty"`
+	HomeID   int64                `json:"home_id,omitempty"`
+	Sun      *sunRecord           `json:"sun,omitempty"`
+}
+
+type sunRecord struct {
+	After  string `json:"after,omitempty"`
+	Before string `json:"before,omitempty"`
+}
+
+type windowRecord struct {
+	From     int    `json:"from"`
+	To       int    `json:"to"`
+	TimeZone string `json:"time_zone"`
+}
+
+// actionRecord - представление действия в колонке actions
+type actionRecord struct {
+	Type       domain.ActionType  `json:"type"`
+	ActuatorID int64              `json:"actuator_id,omitempty"`
+	Command    domain.CommandKind `json:"command,omitempty"`
+	State      int64              `json:"state,omitempty"`
+	DurationMS int64              `json:"duration_ms,omitempty"`
+	WebhookID  int64              `json:"webhook_id,omitempty"`
+	SceneID    int64              `json:"scene_id,omitempty"`
+}
+
+func (r *AutomationRepository) SaveAutomation(ctx context.Context, automation *domain.Automation) error {
+	if automation == nil {
+		return errors.New("automation is nil")
+	}
+
+	trigger, err := json.Marshal(triggerRecord(automation.Trigger))
+	if err != nil {
+		return fmt.Errorf("failed to marshal trigger: %w", err)
+	}
+	conditions, err := conditionsToRecord(automation.Conditions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal conditions: %w", err)
+	}
+	actions, err := actionsToRecord(automation.Actions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal actions: %w", err)
+	}
+
+	conn := transaction.Conn(ctx, r.pool)
+	if automation.ID == 0 {
+		query := `
+			INSERT INTO automations (name, enabled, trigger_sensor_id, trigger, conditions, actions, created_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id
+		`
+		err := conn.QueryRow(ctx, query, automation.Name, automation.Enabled, automation.Trigger.SensorID, trigger,
+			conditions, actions, automation.CreatedBy, automation.CreatedAt).Scan(&automation.ID)
+		if err != nil {
+			return fmt.Errorf("failed to save automation: %w", err)
+		}
+		return nil
+	}
+
+	query := `
+		UPDATE automations
+		SET name = $2, enabled = $3, trigger_sensor_id = $4, trigger = $5, conditions = $6, actions = $7,
+			created_by = $8, created_at = $9
+		WHERE id = $1
+	`
+	tag, err := conn.Exec(ctx, query, automation.ID, automation.Name, automation.Enabled, automation.Trigger.SensorID,
+		trigger, conditions, actions, automation.CreatedBy, automation.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update automation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrAutomationNotFound
+	}
+	return nil
+}
+
+func (r *AutomationRepository) GetAutomations(ctx context.Context) ([]domain.Automation, error) {
+	return r.getAutomations(ctx, `SELECT `+automationColumns+` FROM automations ORDER BY id`)
+}
+
+func (r *AutomationRepository) GetAutomationsByTriggerSensorID(ctx context.Context, sensorID int64) ([]domain.Automation, error) {
+	return r.getAutomations(ctx, `SELECT `+automationColumns+` FROM automations WHERE trigger_sensor_id = $1 ORDER BY id`,
+		sensorID)
+}
+
+func (r *AutomationRepository) GetAutomationByID(ctx context.Context, id int64) (*domain.Automation, error) {
+	automation, err := scanAutomation(transaction.Conn(ctx, r.pool).QueryRow(ctx,
+		`SELECT `+automationColumns+` FROM automations WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrAutomationNotFound
+		}
+		return nil, fmt.Errorf("failed to get automation: %w", err)
+	}
+	return automation, nil
+}
+
+// DeleteAutomation удаляет автоматизацию, журнал ее запусков удаляется каскадно
+func (r *AutomationRepository) DeleteAutomation(ctx context.Context, id int64) error {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM automations WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete automation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrAutomationNotFound
+	}
+	return nil
+}
+
+func (r *AutomationRepository) SaveScene(ctx context.Context, scene *domain.Scene) error {
+	if scene == nil {
+		return errors.New("scene is nil")
+	}
+
+	actions, err := actionsToRecord(scene.Actions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal actions: %w", err)
+	}
+
+	conn := transaction.Conn(ctx, r.pool)
+	if scene.ID == 0 {
+		query := `
+			INSERT INTO scenes (name, actions, created_by, created_at)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`
+		err := conn.QueryRow(ctx, query, scene.Name, actions, scene.CreatedBy, scene.CreatedAt).Scan(&scene.ID)
+		if err != nil {
+			return fmt.Errorf("failed to save scene: %w", err)
+		}
+		return nil
+	}
+
+	query := `UPDATE scenes SET name = $2, actions = $3, created_by = $4, created_at = $5 WHERE id = $1`
+	tag, err := conn.Exec(ctx, query, scene.ID, scene.Name, actions, scene.CreatedBy, scene.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update scene: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrSceneNotFound
+	}
+	return nil
+}
+
+func (r *AutomationRepository) GetScenes(ctx context.Context) ([]domain.Scene, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, `SELECT `+sceneColumns+` FROM scenes ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scenes: %w", err)
+	}
+	defer rows.Close()
+
+	scenes := []domain.Scene{}
+	for rows.Next() {
+		scene, err := scanScene(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scene: %w", err)
+		}
+		scenes = append(scenes, *scene)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through scenes: %w", err)
+	}
+	return scenes, nil
+}
+
+func (r *AutomationRepository) GetSceneByID(ctx context.Context, id int64) (*domain.Scene, error) {
+	scene, err := scanScene(transaction.Conn(ctx, r.pool).QueryRow(ctx,
+		`SELECT `+sceneColumns+` FROM scenes WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrSceneNotFound
+		}
+		return nil, fmt.Errorf("failed to get scene: %w", err)
+	}
+	return scene, nil
+}
+
+// DeleteScene удаляет сцену, журнал ее запусков удаляется каскадно
+func (r *AutomationRepository) DeleteScene(ctx context.Context, id int64) error {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM scenes WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete scene: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrSceneNotFound
+	}
+	return nil
+}
+
+func (r *AutomationRepository) AddAutomationRun(ctx context.Context, run *domain.AutomationRun) error {
+	if run == nil {
+		return errors.New("automation run is nil")
+	}
+
+	query := `
+		INSERT INTO automation_runs (automation_id, scene_id, schedule_id, sensor_id, status, error, created_at)
+		VALUES (nullif($1::bigint, 0), nullif($2::bigint, 0), nullif($3::bigint, 0), $4, $5, $6, $7)
+		RETURNING id
+	`
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, query, run.AutomationID, run.SceneID, run.ScheduleID, run.SensorID,
+		run.Status, run.Error, run.CreatedAt).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save automation run: %w", err)
+	}
+	return nil
+}
+
+func (r *AutomationRepository) GetAutomationRuns(ctx context.Context, filter domain.AutomationRunFilter) ([]domain.AutomationRun, error) {
+	query := `
+		SELECT ` + runColumns + `
+		FROM automation_runs
+		WHERE ($1::bigint = 0 OR automation_id = $1)
+			AND ($2::bigint = 0 OR scene_id = $2)
+			AND ($3::bigint = 0 OR schedule_id = $3)
+			AND ($4::text = '' OR status = $4)
+		ORDER BY id DESC
+		LIMIT $5 OFFSET $6
+	`
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, filter.AutomationID, filter.SceneID, filter.ScheduleID,
+		filter.Status, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automation runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []domain.AutomationRun{}
+	for rows.Next() {
+		var run domain.AutomationRun
+		err := rows.Scan(&run.ID, &run.AutomationID, &run.SceneID, &run.ScheduleID, &run.SensorID, &run.Status, &run.Error,
+			&run.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan automation run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through automation runs: %w", err)
+	}
+	return runs, nil
+}
+
+func (r *AutomationRepository) getAutomations(ctx context.Context, query string, args ...any) ([]domain.Automation, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automations: %w", err)
+	}
+	defer rows.Close()
+
+	automations := []domain.Automation{}
+	for rows.Next() {
+		automation, err := scanAutomation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan automation: %w", err)
+		}
+		automations = append(automations, *automation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through automations: %w", err)
+	}
+	return automations, nil
+}
+
+func scanAutomation(row pgx.Row) (*domain.Automation, error) {
+	var (
+		automation                   domain.Automation
+		trigger, conditions, actions []byte
+		record                       triggerRecord
+	)
+	err := row.Scan(&automation.ID, &automation.Name, &automation.Enabled, &trigger, &conditions, &actions,
+		&automation.CreatedBy, &automation.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(trigger, &record); err != nil {
+		return nil, err
+	}
+	automation.Trigger = domain.AutomationTrigger(record)
+	if automation.Conditions, err = conditionsFromRecord(conditions); err != nil {
+		return nil, err
+	}
+	if automation.Actions, err = actionsFromRecord(actions); err != nil {
+		return nil, err
+	}
+	return &automation, nil
+}
+
+func scanScene(row pgx.Row) (*domain.Scene, error) {
+	var (
+		scene   domain.Scene
+		actions []byte
+	)
+	if err := row.Scan(&scene.ID, &scene.Name, &actions, &scene.CreatedBy, &scene.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if scene.Actions, err = actionsFromRecord(actions); err != nil {
+		return nil, err
+	}
+	return &scene, nil
+}
+
+func conditionsToRecord(conditions []domain.AutomationCondition) ([]byte, error) {
+	records := make([]conditionRecord, 0, len(conditions))
+	for _, c := range conditions {
+		record := conditionRecord{Type: c.Type, SensorID: c.SensorID, Operator: c.Operator, Value: c.Value, HomeID: c.HomeID}
+		if c.Window != nil {
+			record.Window = &windowRecord{From: c.Window.From, To: c.Window.To, TimeZone: c.Window.TimeZone}
+		}
+		if c.Sun != nil {
+			record.Sun = &sunRecord{After: c.Sun.After, Before: c.Sun.Before}
+		}
+		records = append(records, record)
+	}
+	return json.Marshal(records)
+}
+
+func conditionsFromRecord(data []byte) ([]domain.AutomationCondition, error) {
+	var records []conditionRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+
+	var conditions []domain.AutomationCondition
+	for _, record := range records {
+		c := domain.AutomationCondition{
+			Type:     record.Type,
+			SensorID: record.SensorID,
+			Operator: record.Operator,
+			Value:    record.Value,
+			HomeID:   record.HomeID,
+		}
+		if record.Window != nil {
+			c.Window = &domain.RuleWindow{From: record.Window.From, To: record.Window.To, TimeZone: record.Window.TimeZone}
+		}
+		if record.Sun != nil {
+			c.Sun = &domain.SunCondition{After: record.Sun.After, Before: record.Sun.Before}
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions, nil
+}
+
+func actionsToRecord(actions []domain.AutomationAction) ([]byte, error) {
+	records := make([]actionRecord, 0, len(actions))
+	for _, a := range actions {
+		records = append(records, actionToRecord(a))
+	}
+	return json.Marshal(records)
+}
+
+func actionsFromRecord(data []byte) ([]domain.AutomationAction, error) {
+	var records []actionRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+
+	var actions []domain.AutomationAction
+	for _, record := range records {
+		actions = append(actions, actionFromRecord(record))
+	}
+	return actions, nil
+}
+
+func actionToRecord(a domain.AutomationAction) actionRecord {
+	return actionRecord{
+		Type:       a.Type,
+		ActuatorID: a.ActuatorID,
+		Command:    a.Command,
+		State:      a.State,
+		DurationMS: a.Duration.Milliseconds(),
+		WebhookID:  a.WebhookID,
+		SceneID:    a.SceneID,
+	}
+}
+
+func actionFromRecord(record actionRecord) domain.AutomationAction {
+	return domain.AutomationAction{
+		Type:       record.Type,
+		ActuatorID: record.ActuatorID,
+		Command:    record.Command,
+		State:      record.State,
+		Duration:   time.Duration(record.DurationMS) * time.Millisecond,
+		WebhookID:  record.WebhookID,
+		SceneID:    record.SceneID,
+	}
+}
@@ -0,0 +1,182 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type AutomationTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	repo *AutomationRepository
+}
+
+func (suite *AutomationTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.repo = NewAutomationRepository(suite.testDbInstance)
+}
+
+func (suite *AutomationTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *AutomationTestSuite) TestAutomationRepository() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	scene := domain.Scene{
+		Name: "evening",
+		Actions: []domain.AutomationAction{
+			{Type: domain.ActionCommand, ActuatorID: 2, Command: domain.CommandPulse, Duration: 1500 * time.Millisecond},
+			{Type: domain.ActionWebhook, WebhookID: 3},
+		},
+		CreatedBy: 2,
+		CreatedAt: now,
+	}
+	assert.Nil(suite.T(), suite.repo.SaveScene(ctx, &scene))
+	assert.NotZero(suite.T(), scene.ID)
+
+	automation := domain.Automation{
+		Name:    "door at night",
+		Enabled: true,
+		Trigger: domain.AutomationTrigger{SensorID: 5, Operator: domain.RuleOperatorEqual, Value: 1},
+		Conditions: []domain.AutomationCondition{
+			{Type: domain.ConditionSensorState, SensorID: 6, Operator: domain.RuleOperatorLess, Value: 100},
+			{Type: domain.ConditionTimeWindow, Window: &domain.RuleWindow{From: 1200, To: 360, TimeZone: "Europe/Moscow"}},
+			{Type: domain.ConditionSun, HomeID: 1, Sun: &domain.SunCondition{After: "sunset", Before: "sunrise-1h"}},
+		},
+		Actions:   []domain.AutomationAction{{Type: domain.ActionAlert}, {Type: domain.ActionScene, SceneID: scene.ID}},
+		CreatedBy: 2,
+		CreatedAt: now,
+	}
+	assert.Nil(suite.T(), suite.repo.SaveAutomation(ctx, &automation))
+	assert.NotZero(suite.T(), automation.ID)
+
+	automation.Enabled = false
+	assert.Nil(suite.T(), suite.repo.SaveAutomation(ctx, &automation))
+
+	stored, err := suite.repo.GetAutomationByID(ctx, automation.ID)
+	require.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &automation, stored)
+
+	automations, err := suite.repo.GetAutomationsByTriggerSensorID(ctx, 5)
+	require.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.Automation{automation}, automations)
+
+	automations, err = suite.repo.GetAutomationsByTriggerSensorID(ctx, 6)
+	require.Nil(suite.T(), err)
+	assert.Empty(suite.T(), automations)
+
+	scenes, err := suite.repo.GetScenes(ctx)
+	require.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.Scene{scene}, scenes)
+
+	assert.ErrorIs(suite.T(), suite.repo.SaveAutomation(ctx, &domain.Automation{ID: 1000}), usecase.ErrAutomationNotFound)
+	assert.ErrorIs(suite.T(), suite.repo.SaveScene(ctx, &domain.Scene{ID: 1000}), usecase.ErrSceneNotFound)
+	_, err = suite.repo.GetSceneByID(ctx, 1000)
+	assert.ErrorIs(suite.T(), err, usecase.ErrSceneNotFound)
+
+	automationRun := domain.AutomationRun{AutomationID: automation.ID, SensorID: 5, Status: domain.AutomationRunFailed,
+		Error: "action 1: actuator not found", CreatedAt: now}
+	sceneRun := domain.AutomationRun{SceneID: scene.ID, Status: domain.AutomationRunSucceeded, CreatedAt: now}
+	for _, run := range []*domain.AutomationRun{&automationRun, &sceneRun} {
+		assert.Nil(suite.T(), suite.repo.AddAutomationRun(ctx, run))
+		assert.NotZero(suite.T(), run.ID)
+	}
+
+	runs, err := suite.repo.GetAutomationRuns(ctx, domain.AutomationRunFilter{AutomationID: automation.ID, Limit: 10})
+	require.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.AutomationRun{automationRun}, runs)
+
+	runs, err = suite.repo.GetAutomationRuns(ctx, domain.AutomationRunFilter{Status: domain.AutomationRunSucceeded, Limit: 10})
+	require.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.AutomationRun{sceneRun}, runs)
+
+	assert.Nil(suite.T(), suite.repo.DeleteAutomation(ctx, automation.ID))
+	assert.ErrorIs(suite.T(), suite.repo.DeleteAutomation(ctx, automation.ID), usecase.ErrAutomationNotFound)
+	assert.Nil(suite.T(), suite.repo.DeleteScene(ctx, scene.ID))
+	assert.ErrorIs(suite.T(), suite.repo.DeleteScene(ctx, scene.ID), usecase.ErrSceneNotFound)
+
+	runs, err = suite.repo.GetAutomationRuns(ctx, domain.AutomationRunFilter{Limit: 10})
+	require.Nil(suite.T(), err)
+	assert.Empty(suite.T(), runs)
+}
+
+func (suite *AutomationTestSuite) TestScheduleRepository() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var homeID int64
+	err := suite.testDbInstance.QueryRow(ctx, `INSERT INTO homes (name, address) VALUES ('home', '') RETURNING id`).Scan(&homeID)
+	require.Nil(suite.T(), err)
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.Nil(suite.T(), err)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	schedule := domain.Schedule{
+		HomeID:          homeID,
+		Name:            "morning",
+		Expression:      "0 7 * * mon-fri",
+		Action:          domain.AutomationAction{Type: domain.ActionCommand, ActuatorID: 2, Command: domain.CommandSetState, State: 1},
+		MissedRunPolicy: domain.MissedRunOnce,
+		Enabled:         true,
+		NextRunAt:       time.Date(2025, 1, 1, 14, 0, 0, 0, moscow),
+		CreatedBy:       2,
+		CreatedAt:       now,
+	}
+	assert.Nil(suite.T(), suite.repo.SaveSchedule(ctx, &schedule))
+	assert.NotZero(suite.T(), schedule.ID)
+	schedule.NextRunAt = schedule.NextRunAt.UTC()
+
+	stored, err := suite.repo.GetScheduleByID(ctx, schedule.ID)
+	require.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &schedule, stored)
+
+	due, err := suite.repo.GetDueSchedules(ctx, now)
+	require.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.Schedule{schedule}, due)
+
+	next := now.Add(time.Hour)
+	claimed, err := suite.repo.AdvanceSchedule(ctx, schedule.ID, schedule.NextRunAt, next, now)
+	require.Nil(suite.T(), err)
+	assert.True(suite.T(), claimed)
+	claimed, err = suite.repo.AdvanceSchedule(ctx, schedule.ID, schedule.NextRunAt, next, now)
+	require.Nil(suite.T(), err)
+	assert.False(suite.T(), claimed)
+
+	due, err = suite.repo.GetDueSchedules(ctx, now)
+	require.Nil(suite.T(), err)
+	assert.Empty(suite.T(), due)
+
+	run := domain.AutomationRun{ScheduleID: schedule.ID, Status: domain.AutomationRunSkipped, Error: "missed", CreatedAt: now}
+	assert.Nil(suite.T(), suite.repo.AddAutomationRun(ctx, &run))
+	runs, err := suite.repo.GetAutomationRuns(ctx, domain.AutomationRunFilter{ScheduleID: schedule.ID, Limit: 10})
+	require.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.AutomationRun{run}, runs)
+
+	assert.ErrorIs(suite.T(), suite.repo.SaveSchedule(ctx, &domain.Schedule{ID: 1000, HomeID: homeID}), usecase.ErrScheduleNotFound)
+	assert.Nil(suite.T(), suite.repo.DeleteSchedule(ctx, schedule.ID))
+	assert.ErrorIs(suite.T(), suite.repo.DeleteSchedule(ctx, schedule.ID), usecase.ErrScheduleNotFound)
+
+	runs, err = suite.repo.GetAutomationRuns(ctx, domain.AutomationRunFilter{ScheduleID: schedule.ID, Limit: 10})
+	require.Nil(suite.T(), err)
+	assert.Empty(suite.T(), runs)
+}
+
+func TestAutomationTestSuite(t *testing.T) {
+	suite.Run(t, new(AutomationTestSuite))
+}
@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const scheduleColumns = `id, home_id, name, expression, action, missed_run_policy, enabled, next_run_at, last_run_at,
+	created_by, created_at`
+
+// SaveSchedule сохраняет расписание, время запусков хранится в UTC, нулевое время - как null
+func (r *AutomationRepository) SaveSchedule(ctx context.Context, schedule *domain.Schedule) error {
+	if schedule == nil {
+		return errors.New("schedule is nil")
+	}
+
+	action, err := json.Marshal(actionToRecord(schedule.Action))
+	if err != nil {
+		return fmt.Errorf("failed to marshal action: %w", err)
+	}
+
+	conn := transaction.Conn(ctx, r.pool)
+	if schedule.ID == 0 {
+		query := `
+			INSERT INTO schedules (home_id, name, expression, action, missed_run_policy, enabled, next_run_at, last_run_at,
+				created_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id
+		`
+		err := conn.QueryRow(ctx, query, schedule.HomeID, schedule.Name, schedule.Expression, action,
+			schedule.MissedRunPolicy, schedule.Enabled, nullTime(schedule.NextRunAt), nullTime(schedule.LastRunAt),
+			schedule.CreatedBy, schedule.CreatedAt).Scan(&schedule.ID)
+		if err != nil {
+			return fmt.Errorf("failed to save schedule: %w", err)
+		}
+		return nil
+	}
+
+	query := `
+		UPDATE schedules
+		SET home_id = $2, name = $3, expression = $4, action = $5, missed_run_policy = $6, enabled = $7,
+			next_run_at = $8, last_run_at = $9, created_by = $10, created_at = $11
+		WHERE id = $1
+	`
+	tag, err := conn.Exec(ctx, query, schedule.ID, schedule.HomeID, schedule.Name, schedule.Expression, action,
+		schedule.MissedRunPolicy, schedule.Enabled, nullTime(schedule.NextRunAt), nullTime(schedule.LastRunAt),
+		schedule.CreatedBy, schedule.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrScheduleNotFound
+	}
+	return nil
+}
+
+func (r *AutomationRepository) GetSchedules(ctx context.Context) ([]domain.Schedule, error) {
+	return r.getSchedules(ctx, `SELECT `+scheduleColumns+` FROM schedules ORDER BY id`)
+}
+
+func (r *AutomationRepository) GetScheduleByID(ctx context.Context, id int64) (*domain.Schedule, error) {
+	schedule, err := scanSchedule(transaction.Conn(ctx, r.pool).QueryRow(ctx,
+		`SELECT `+scheduleColumns+` FROM schedules WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrScheduleNotFound
+		}
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+func (r *AutomationRepository) GetDueSchedules(ctx context.Context, now time.Time) ([]domain.Schedule, error) {
+	return r.getSchedules(ctx, `
+		SELECT `+scheduleColumns+`
+		FROM schedules
+		WHERE enabled AND next_run_at <= $1
+		ORDER BY next_run_at, id
+	`, now.UTC())
+}
+
+// AdvanceSchedule переносит запуск, только если next_run_at не изменился с момента выборки
+func (r *AutomationRepository) AdvanceSchedule(ctx context.Context, id int64, scheduled, next, last time.Time) (bool, error) {
+	query := `
+		UPDATE schedules SET next_run_at = $3, last_run_at = $4
+		WHERE id = $1 AND next_run_at = $2
+	`
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, query, id, scheduled.UTC(), nullTime(next), nullTime(last))
+	if err != nil {
+		return false, fmt.Errorf("failed to advance schedule: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteSchedule удаляет расписание, журнал его запусков удаляется каскадно
+func (r *AutomationRepository) DeleteSchedule(ctx context.Context, id int64) error {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM schedules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrScheduleNotFound
+	}
+	return nil
+}
+
+func (r *AutomationRepository) getSchedules(ctx context.Context, query string, args ...any) ([]domain.Schedule, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []domain.Schedule{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		schedules = append(schedules, *schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+func scanSchedule(row pgx.Row) (*domain.Schedule, error) {
+	var (
+		schedule             domain.Schedule
+		action               []byte
+		nextRunAt, lastRunAt *time.Time
+	)
+	err := row.Scan(&schedule.ID, &schedule.HomeID, &schedule.Name, &schedule.Expression, &action,
+		&schedule.MissedRunPolicy, &schedule.Enabled, &nextRunAt, &lastRunAt, &schedule.CreatedBy, &schedule.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	var record actionRecord
+	if err := json.Unmarshal(action, &record); err != nil {
+		return nil, err
+	}
+	schedule.Action = actionFromRecord(record)
+	if nextRunAt != nil {
+		schedule.NextRunAt = *nextRunAt
+	}
+	if lastRunAt != nil {
+		schedule.LastRunAt = *lastRunAt
+	}
+	return &schedule, nil
+}
+
+// nullTime переводит время в UTC, нулевое время - в null
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
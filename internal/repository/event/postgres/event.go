@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
 	"homework/internal/usecase"
 
 	"github.com/jackc/pgx/v5"
@@ -30,7 +31,7 @@ func (r *EventRepository) SaveEvent(ctx context.Context, event *domain.Event) er
 
 	if serialNumber == "" {
 		var sn string
-		err := r.pool.QueryRow(ctx, `SELECT serial_number FROM sensors WHERE id = $1`, event.SensorID).Scan(&sn)
+		err := transaction.Conn(ctx, r.pool).QueryRow(ctx, `SELECT serial_number FROM sensors WHERE id = $1`, event.SensorID).Scan(&sn)
 		if err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
 				return fmt.Errorf("sensor with id %d not found", event.SensorID)
@@ -41,10 +42,11 @@ func (r *EventRepository) SaveEvent(ctx context.Context, event *domain.Event) er
 	}
 
 	query := `
-        INSERT INTO events (timestamp, sensor_serial_number, sensor_id, payload)
-        VALUES ($1, $2, $3, $4)
+        INSERT INTO events (timestamp, sensor_serial_number, sensor_id, payload, anomaly_score, anomalous)
+        VALUES ($1, $2, $3, $4, $5, $6)
     `
-	_, err := r.pool.Exec(ctx, query, event.Timestamp, serialNumber, event.SensorID, event.Payload)
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, query, event.Timestamp, serialNumber, event.SensorID, event.Payload,
+		event.AnomalyScore, event.Anomalous)
 	if err != nil {
 		return fmt.Errorf("failed to save event: %w", err)
 	}
@@ -53,7 +55,7 @@ func (r *EventRepository) SaveEvent(ctx context.Context, event *domain.Event) er
 
 func (r *EventRepository) GetLastEventBySensorID(ctx context.Context, id int64) (*domain.Event, error) {
 	query := `
-        SELECT timestamp, sensor_serial_number, sensor_id, payload
+        SELECT timestamp, sensor_serial_number, sensor_id, payload, anomaly_score, anomalous
         FROM events
         WHERE sensor_id = $1
         ORDER BY timestamp DESC
@@ -61,11 +63,13 @@ func (r *EventRepository) GetLastEventBySensorID(ctx context.Context, id int64)
     `
 	var event domain.Event
 
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, query, id).Scan(
 		&event.Timestamp,
 		&event.SensorSerialNumber,
 		&event.SensorID,
 		&event.Payload,
+		&event.AnomalyScore,
+		&event.Anomalous,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -2,7 +2,10 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
 	"homework/pkg/pg_test"
 	"testing"
 	"time"
@@ -73,6 +76,31 @@ func (suite *EventTestSuite) TestEventRepository_GetLastEventBySensorID() {
 	assert.Equal(suite.T(), secondEvent, *event)
 }
 
+func (suite *EventTestSuite) TestEventRepository_SaveEventRollback() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rollback := errors.New("rollback")
+	err := transaction.NewTransactor(suite.testDbInstance).WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := suite.repo.SaveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now().In(time.UTC),
+			SensorSerialNumber: "1122334455",
+			SensorID:           3,
+			Payload:            1,
+		}); err != nil {
+			return err
+		}
+
+		_, err := suite.repo.GetLastEventBySensorID(ctx, 3)
+		assert.Nil(suite.T(), err, "event is visible inside the transaction")
+		return rollback
+	})
+	assert.ErrorIs(suite.T(), err, rollback)
+
+	_, err = suite.repo.GetLastEventBySensorID(ctx, 3)
+	assert.ErrorIs(suite.T(), err, usecase.ErrEventNotFound)
+}
+
 func TestEventTestSuite(t *testing.T) {
 	suite.Run(t, new(EventTestSuite))
 }
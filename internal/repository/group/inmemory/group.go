@@ -0,0 +1,220 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"sort"
+	"sync"
+)
+
+type GroupRepository struct {
+	groups map[int64]*domain.Group
+	// members - участники групп: id группы -> id пользователя
+	members map[int64]map[int64]struct{}
+	// sensors - датчики групп: id группы -> id датчика -> привязка
+	sensors map[int64]map[int64]domain.GroupSensor
+	mu      sync.RWMutex
+	lastID  int64
+}
+
+func NewGroupRepository() *GroupRepository {
+	return &GroupRepository{
+		groups:  make(map[int64]*domain.Group),
+		members: make(map[int64]map[int64]struct{}),
+		sensors: make(map[int64]map[int64]domain.GroupSensor),
+	}
+}
+
+func (r *GroupRepository) SaveGroup(ctx context.Context, group *domain.Group) error {
+	if group == nil {
+		return errors.New("group is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if group.ID == 0 {
+		r.lastID++
+		group.ID = r.lastID
+	}
+
+	stored := *group
+	r.groups[group.ID] = &stored
+
+	return nil
+}
+
+func (r *GroupRepository) GetGroups(ctx context.Context) ([]domain.Group, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	groups := make([]domain.Group, 0, len(r.groups))
+	for _, group := range r.groups {
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+
+	return groups, nil
+}
+
+func (r *GroupRepository) GetGroupByID(ctx context.Context, id int64) (*domain.Group, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	group, ok := r.groups[id]
+	if !ok {
+		return nil, usecase.ErrGroupNotFound
+	}
+
+	result := *group
+	return &result, nil
+}
+
+func (r *GroupRepository) DeleteGroup(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.groups[id]; !ok {
+		return usecase.ErrGroupNotFound
+	}
+	delete(r.groups, id)
+	delete(r.members, id)
+	delete(r.sensors, id)
+
+	return nil
+}
+
+func (r *GroupRepository) SaveGroupMember(ctx context.Context, member domain.GroupMember) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.members[member.GroupID]; !ok {
+		r.members[member.GroupID] = make(map[int64]struct{})
+	}
+	r.members[member.GroupID][member.UserID] = struct{}{}
+
+	return nil
+}
+
+func (r *GroupRepository) DeleteGroupMember(ctx context.Context, groupID, userID int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.members[groupID][userID]; !ok {
+		return usecase.ErrGroupMemberNotFound
+	}
+	delete(r.members[groupID], userID)
+
+	return nil
+}
+
+func (r *GroupRepository) GetMembersByGroupID(ctx context.Context, groupID int64) ([]domain.GroupMember, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]domain.GroupMember, 0, len(r.members[groupID]))
+	for userID := range r.members[groupID] {
+		result = append(result, domain.GroupMember{GroupID: groupID, UserID: userID})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].UserID < result[j].UserID })
+
+	return result, nil
+}
+
+func (r *GroupRepository) GetGroupsByUserID(ctx context.Context, userID int64) ([]domain.GroupMember, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]domain.GroupMember, 0)
+	for groupID, members := range r.members {
+		if _, ok := members[userID]; ok {
+			result = append(result, domain.GroupMember{GroupID: groupID, UserID: userID})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].GroupID < result[j].GroupID })
+
+	return result, nil
+}
+
+func (r *GroupRepository) SaveGroupSensor(ctx context.Context, groupSensor domain.GroupSensor) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sensors[groupSensor.GroupID]; !ok {
+		r.sensors[groupSensor.GroupID] = make(map[int64]domain.GroupSensor)
+	}
+	r.sensors[groupSensor.GroupID][groupSensor.SensorID] = groupSensor
+
+	return nil
+}
+
+func (r *GroupRepository) DeleteGroupSensor(ctx context.Context, groupID, sensorID int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sensors[groupID][sensorID]; !ok {
+		return usecase.ErrGroupSensorNotFound
+	}
+	delete(r.sensors[groupID], sensorID)
+
+	return nil
+}
+
+func (r *GroupRepository) GetSensorsByGroupID(ctx context.Context, groupID int64) ([]domain.GroupSensor, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]domain.GroupSensor, 0, len(r.sensors[groupID]))
+	for _, groupSensor := range r.sensors[groupID] {
+		result = append(result, groupSensor)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].SensorID < result[j].SensorID })
+
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupRepository_Members(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		gr := NewGroupRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := gr.GetGroupsByUserID(ctx, 1)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, add and remove members", func(t *testing.T) {
+		gr := NewGroupRepository()
+		ctx := context.Background()
+
+		require.NoError(t, gr.SaveGroupMember(ctx, domain.GroupMember{GroupID: 1, UserID: 2}))
+		require.NoError(t, gr.SaveGroupMember(ctx, domain.GroupMember{GroupID: 1, UserID: 1}))
+		require.NoError(t, gr.SaveGroupMember(ctx, domain.GroupMember{GroupID: 2, UserID: 1}))
+
+		members, err := gr.GetMembersByGroupID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.GroupMember{{GroupID: 1, UserID: 1}, {GroupID: 1, UserID: 2}}, members)
+
+		groups, err := gr.GetGroupsByUserID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.GroupMember{{GroupID: 1, UserID: 1}, {GroupID: 2, UserID: 1}}, groups)
+
+		require.NoError(t, gr.DeleteGroupMember(ctx, 1, 1))
+		assert.ErrorIs(t, gr.DeleteGroupMember(ctx, 1, 1), usecase.ErrGroupMemberNotFound)
+
+		groups, err = gr.GetGroupsByUserID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.GroupMember{{GroupID: 2, UserID: 1}}, groups)
+	})
+}
+
+func TestGroupRepository_DeleteGroup(t *testing.T) {
+	gr := NewGroupRepository()
+	ctx := context.Background()
+
+	group := domain.Group{Name: "Семья"}
+	require.NoError(t, gr.SaveGroup(ctx, &group))
+	require.NoError(t, gr.SaveGroupMember(ctx, domain.GroupMember{GroupID: group.ID, UserID: 1}))
+	require.NoError(t, gr.SaveGroupSensor(ctx, domain.GroupSensor{GroupID: group.ID, SensorID: 1, Role: domain.SensorRoleViewer}))
+	require.NoError(t, gr.SaveGroupSensor(ctx, domain.GroupSensor{GroupID: group.ID, SensorID: 1, Role: domain.SensorRoleOperator}))
+
+	sensors, err := gr.GetSensorsByGroupID(ctx, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.GroupSensor{{GroupID: group.ID, SensorID: 1, Role: domain.SensorRoleOperator}}, sensors)
+
+	require.NoError(t, gr.DeleteGroup(ctx, group.ID))
+
+	_, err = gr.GetGroupByID(ctx, group.ID)
+	assert.ErrorIs(t, err, usecase.ErrGroupNotFound)
+
+	groups, err := gr.GetGroupsByUserID(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+
+	sensors, err = gr.GetSensorsByGroupID(ctx, group.ID)
+	require.NoError(t, err)
+	assert.Empty(t, sensors)
+
+	assert.ErrorIs(t, gr.DeleteGroupSensor(ctx, group.ID, 1), usecase.ErrGroupSensorNotFound)
+}
@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type GroupRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewGroupRepository(pool *pgxpool.Pool) *GroupRepository {
+	return &GroupRepository{
+		pool: pool,
+	}
+}
+
+func (r *GroupRepository) SaveGroup(ctx context.Context, group *domain.Group) error {
+	if group == nil {
+		return errors.New("group is nil")
+	}
+
+	conn := transaction.Conn(ctx, r.pool)
+	if group.ID == 0 {
+		query := `
+			INSERT INTO groups (name)
+			VALUES ($1)
+			RETURNING id
+		`
+		if err := conn.QueryRow(ctx, query, group.Name).Scan(&group.ID); err != nil {
+			return fmt.Errorf("failed to insert group: %w", err)
+		}
+		return nil
+	}
+
+	tag, err := conn.Exec(ctx, `UPDATE groups SET name = $2 WHERE id = $1`, group.ID, group.Name)
+	if err != nil {
+		return fmt.Errorf("failed to update group: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrGroupNotFound
+	}
+	return nil
+}
+
+func (r *GroupRepository) GetGroups(ctx context.Context) ([]domain.Group, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, `SELECT id, name FROM groups ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []domain.Group{}
+	for rows.Next() {
+		var group domain.Group
+		if err := rows.Scan(&group.ID, &group.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through groups: %w", err)
+	}
+	return groups, nil
+}
+
+func (r *GroupRepository) GetGroupByID(ctx context.Context, id int64) (*domain.Group, error) {
+	var group domain.Group
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, `SELECT id, name FROM groups WHERE id = $1`, id).
+		Scan(&group.ID, &group.Name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrGroupNotFound
+		}
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+	return &group, nil
+}
+
+// DeleteGroup удаляет группу, участники и привязки датчиков удаляются каскадно
+func (r *GroupRepository) DeleteGroup(ctx context.Context, id int64) error {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM groups WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrGroupNotFound
+	}
+	return nil
+}
+
+func (r *GroupRepository) SaveGroupMember(ctx context.Context, member domain.GroupMember) error {
+	query := `
+		INSERT INTO groups_users (group_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (group_id, user_id) DO NOTHING
+	`
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, query, member.GroupID, member.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to save group member: %w", err)
+	}
+	return nil
+}
+
+func (r *GroupRepository) DeleteGroupMember(ctx context.Context, groupID, userID int64) error {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx,
+		`DELETE FROM groups_users WHERE group_id = $1 AND user_id = $2`, groupID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete group member: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrGroupMemberNotFound
+	}
+	return nil
+}
+
+func (r *GroupRepository) GetMembersByGroupID(ctx context.Context, groupID int64) ([]domain.GroupMember, error) {
+	query := `
+		SELECT group_id, user_id
+		FROM groups_users
+		WHERE group_id = $1
+		ORDER BY user_id
+	`
+	return r.queryMembers(ctx, query, groupID)
+}
+
+func (r *GroupRepository) GetGroupsByUserID(ctx context.Context, userID int64) ([]domain.GroupMember, error) {
+	query := `
+		SELECT group_id, user_id
+		FROM groups_users
+		WHERE user_id = $1
+		ORDER BY group_id
+	`
+	return r.queryMembers(ctx, query, userID)
+}
+
+func (r *GroupRepository) queryMembers(ctx context.Context, query string, id int64) ([]domain.GroupMember, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query group members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []domain.GroupMember{}
+	for rows.Next() {
+		var member domain.GroupMember
+		if err := rows.Scan(&member.GroupID, &member.UserID); err != nil {
+			return nil, fmt.Errorf("failed to scan group member: %w", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through group members: %w", err)
+	}
+	return members, nil
+}
+
+func (r *GroupRepository) SaveGroupSensor(ctx context.Context, groupSensor domain.GroupSensor) error {
+	query := `
+		INSERT INTO groups_sensors (group_id, sensor_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (group_id, sensor_id) DO UPDATE SET role = EXCLUDED.role
+	`
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, query, groupSensor.GroupID, groupSensor.SensorID, groupSensor.Role)
+	if err != nil {
+		return fmt.Errorf("failed to save group sensor: %w", err)
+	}
+	return nil
+}
+
+func (r *GroupRepository) DeleteGroupSensor(ctx context.Context, groupID, sensorID int64) error {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx,
+		`DELETE FROM groups_sensors WHERE group_id = $1 AND sensor_id = $2`, groupID, sensorID)
+	if err != nil {
+		return fmt.Errorf("failed to delete group sensor: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrGroupSensorNotFound
+	}
+	return nil
+}
+
+func (r *GroupRepository) GetSensorsByGroupID(ctx context.Context, groupID int64) ([]domain.GroupSensor, error) {
+	query := `
+		SELECT group_id, sensor_id, role
+		FROM groups_sensors
+		WHERE group_id = $1
+		ORDER BY sensor_id
+	`
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query group sensors: %w", err)
+	}
+	defer rows.Close()
+
+	groupSensors := []domain.GroupSensor{}
+	for rows.Next() {
+		var groupSensor domain.GroupSensor
+		if err := rows.Scan(&groupSensor.GroupID, &groupSensor.SensorID, &groupSensor.Role); err != nil {
+			return nil, fmt.Errorf("failed to scan group sensor: %w", err)
+		}
+		groupSensors = append(groupSensors, groupSensor)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through group sensors: %w", err)
+	}
+	return groupSensors, nil
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type GroupTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	repo *GroupRepository
+}
+
+func (suite *GroupTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.repo = NewGroupRepository(suite.testDbInstance)
+}
+
+func (suite *GroupTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *GroupTestSuite) TestGroupRepository_Members() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	group := domain.Group{Name: "Семья"}
+	assert.Nil(suite.T(), suite.repo.SaveGroup(ctx, &group))
+	assert.NotZero(suite.T(), group.ID)
+
+	assert.Nil(suite.T(), suite.repo.SaveGroupMember(ctx, domain.GroupMember{GroupID: group.ID, UserID: 2}))
+	assert.Nil(suite.T(), suite.repo.SaveGroupMember(ctx, domain.GroupMember{GroupID: group.ID, UserID: 1}))
+	assert.Nil(suite.T(), suite.repo.SaveGroupMember(ctx, domain.GroupMember{GroupID: group.ID, UserID: 1}))
+
+	members, err := suite.repo.GetMembersByGroupID(ctx, group.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.GroupMember{{GroupID: group.ID, UserID: 1}, {GroupID: group.ID, UserID: 2}}, members)
+
+	assert.Nil(suite.T(), suite.repo.DeleteGroupMember(ctx, group.ID, 2))
+	assert.ErrorIs(suite.T(), suite.repo.DeleteGroupMember(ctx, group.ID, 2), usecase.ErrGroupMemberNotFound)
+
+	groups, err := suite.repo.GetGroupsByUserID(ctx, 2)
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), groups)
+}
+
+func (suite *GroupTestSuite) TestGroupRepository_DeleteGroup() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	group := domain.Group{Name: "Дача"}
+	assert.Nil(suite.T(), suite.repo.SaveGroup(ctx, &group))
+	assert.Nil(suite.T(), suite.repo.SaveGroupMember(ctx, domain.GroupMember{GroupID: group.ID, UserID: 3}))
+	assert.Nil(suite.T(), suite.repo.SaveGroupSensor(ctx, domain.GroupSensor{GroupID: group.ID, SensorID: 1, Role: domain.SensorRoleViewer}))
+	assert.Nil(suite.T(), suite.repo.SaveGroupSensor(ctx, domain.GroupSensor{GroupID: group.ID, SensorID: 1, Role: domain.SensorRoleOperator}))
+
+	sensors, err := suite.repo.GetSensorsByGroupID(ctx, group.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.GroupSensor{{GroupID: group.ID, SensorID: 1, Role: domain.SensorRoleOperator}}, sensors)
+
+	assert.Nil(suite.T(), suite.repo.DeleteGroup(ctx, group.ID))
+	assert.ErrorIs(suite.T(), suite.repo.DeleteGroup(ctx, group.ID), usecase.ErrGroupNotFound)
+
+	groups, err := suite.repo.GetGroupsByUserID(ctx, 3)
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), groups)
+
+	sensors, err = suite.repo.GetSensorsByGroupID(ctx, group.ID)
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), sensors)
+}
+
+func TestGroupTestSuite(t *testing.T) {
+	suite.Run(t, new(GroupTestSuite))
+}
@@ -0,0 +1,103 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"sort"
+	"sync"
+)
+
+type HomeRepository struct {
+	homes  map[int64]*domain.Home
+	mu     sync.RWMutex
+	lastID int64
+}
+
+func NewHomeRepository() *HomeRepository {
+	return &HomeRepository{
+		homes: make(map[int64]*domain.Home),
+	}
+}
+
+func (r *HomeRepository) SaveHome(ctx context.Context, home *domain.Home) error {
+	if home == nil {
+		return errors.New("home is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if home.ID == 0 {
+		r.lastID++
+		home.ID = r.lastID
+	}
+
+	r.homes[home.ID] = copyHome(home)
+
+	return nil
+}
+
+func (r *HomeRepository) GetHomes(ctx context.Context) ([]domain.Home, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	homes := make([]domain.Home, 0, len(r.homes))
+	for _, home := range r.homes {
+		homes = append(homes, *copyHome(home))
+	}
+	sort.Slice(homes, func(i, j int) bool { return homes[i].ID < homes[j].ID })
+
+	return homes, nil
+}
+
+func (r *HomeRepository) GetHomeByID(ctx context.Context, id int64) (*domain.Home, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	home, ok := r.homes[id]
+	if !ok {
+		return nil, usecase.ErrHomeNotFound
+	}
+
+	return copyHome(home), nil
+}
+
+func (r *HomeRepository) DeleteHome(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.homes[id]; !ok {
+		return usecase.ErrHomeNotFound
+	}
+	delete(r.homes, id)
+
+	return nil
+}
+
+// copyHome копирует дом вместе с координатами, чтобы изменения снаружи не затрагивали хранилище
+func copyHome(home *domain.Home) *domain.Home {
+	result := *home
+	if home.Coordinates != nil {
+		coordinates := *home.Coordinates
+		result.Coordinates = &coordinates
+	}
+	return &result
+}
@@ -0,0 +1,80 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"sync"
+)
+
+type HomeOwnerRepository struct {
+	data     map[int64]map[int64]domain.HomeOwner
+	dataLock sync.RWMutex
+}
+
+func NewHomeOwnerRepository() *HomeOwnerRepository {
+	return &HomeOwnerRepository{
+		data: make(map[int64]map[int64]domain.HomeOwner),
+	}
+}
+
+func (r *HomeOwnerRepository) SaveHomeOwner(ctx context.Context, homeOwner domain.HomeOwner) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.dataLock.Lock()
+	defer r.dataLock.Unlock()
+
+	if _, exists := r.data[homeOwner.UserID]; !exists {
+		r.data[homeOwner.UserID] = make(map[int64]domain.HomeOwner)
+	}
+
+	r.data[homeOwner.UserID][homeOwner.HomeID] = homeOwner
+	return nil
+}
+
+func (r *HomeOwnerRepository) GetHomesByUserID(ctx context.Context, userID int64) ([]domain.HomeOwner, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.dataLock.RLock()
+	defer r.dataLock.RUnlock()
+
+	userHomes, exists := r.data[userID]
+	if !exists {
+		return []domain.HomeOwner{}, nil
+	}
+
+	result := make([]domain.HomeOwner, 0, len(userHomes))
+	for _, homeOwner := range userHomes {
+		result = append(result, homeOwner)
+	}
+	return result, nil
+}
+
+func (r *HomeOwnerRepository) DeleteHomeOwnersByHomeID(ctx context.Context, homeID int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.dataLock.Lock()
+	defer r.dataLock.Unlock()
+
+	for _, userHomes := range r.data {
+		delete(userHomes, homeID)
+	}
+	return nil
+}
+
+func (r *HomeOwnerRepository) DeleteHomeOwnersByUserID(ctx context.Context, userID int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.dataLock.Lock()
+	defer r.dataLock.Unlock()
+
+	delete(r.data, userID)
+	return nil
+}
@@ -0,0 +1,82 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHomeOwnerRepository_SaveHomeOwner(t *testing.T) {
+	t.Run("fail, ctx deadline exceeded", func(t *testing.T) {
+		hor := NewHomeOwnerRepository()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+
+		err := hor.SaveHomeOwner(ctx, domain.HomeOwner{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("ok, save twice and get one", func(t *testing.T) {
+		hor := NewHomeOwnerRepository()
+		ctx := context.Background()
+
+		assert.NoError(t, hor.SaveHomeOwner(ctx, domain.HomeOwner{UserID: 1, HomeID: 2}))
+		assert.NoError(t, hor.SaveHomeOwner(ctx, domain.HomeOwner{UserID: 1, HomeID: 2}))
+
+		homes, err := hor.GetHomesByUserID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.HomeOwner{{UserID: 1, HomeID: 2}}, homes)
+	})
+
+	t.Run("ok, save again changes role", func(t *testing.T) {
+		hor := NewHomeOwnerRepository()
+		ctx := context.Background()
+
+		assert.NoError(t, hor.SaveHomeOwner(ctx, domain.HomeOwner{UserID: 1, HomeID: 2, Role: domain.HomeRoleMember}))
+		assert.NoError(t, hor.SaveHomeOwner(ctx, domain.HomeOwner{UserID: 1, HomeID: 2, Role: domain.HomeRoleOwner}))
+
+		homes, err := hor.GetHomesByUserID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.HomeOwner{{UserID: 1, HomeID: 2, Role: domain.HomeRoleOwner}}, homes)
+	})
+}
+
+func TestHomeOwnerRepository_DeleteHomeOwnersByHomeID(t *testing.T) {
+	hor := NewHomeOwnerRepository()
+	ctx := context.Background()
+
+	assert.NoError(t, hor.SaveHomeOwner(ctx, domain.HomeOwner{UserID: 1, HomeID: 1}))
+	assert.NoError(t, hor.SaveHomeOwner(ctx, domain.HomeOwner{UserID: 2, HomeID: 1}))
+	assert.NoError(t, hor.SaveHomeOwner(ctx, domain.HomeOwner{UserID: 2, HomeID: 3}))
+
+	assert.NoError(t, hor.DeleteHomeOwnersByHomeID(ctx, 1))
+
+	homes, err := hor.GetHomesByUserID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, homes)
+
+	homes, err = hor.GetHomesByUserID(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.HomeOwner{{UserID: 2, HomeID: 3}}, homes)
+}
+
+func TestHomeOwnerRepository_DeleteHomeOwnersByUserID(t *testing.T) {
+	hor := NewHomeOwnerRepository()
+	ctx := context.Background()
+
+	assert.NoError(t, hor.SaveHomeOwner(ctx, domain.HomeOwner{UserID: 1, HomeID: 1}))
+	assert.NoError(t, hor.SaveHomeOwner(ctx, domain.HomeOwner{UserID: 2, HomeID: 1}))
+
+	assert.NoError(t, hor.DeleteHomeOwnersByUserID(ctx, 1))
+
+	homes, err := hor.GetHomesByUserID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, homes)
+
+	homes, err = hor.GetHomesByUserID(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.HomeOwner{{UserID: 2, HomeID: 1}}, homes)
+}
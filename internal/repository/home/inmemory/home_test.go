@@ -0,0 +1,97 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHomeRepository_SaveHome(t *testing.T) {
+	t.Run("err, home is nil", func(t *testing.T) {
+		hr := NewHomeRepository()
+		err := hr.SaveHome(context.Background(), nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		hr := NewHomeRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := hr.SaveHome(ctx, &domain.Home{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("fail, ctx deadline exceeded", func(t *testing.T) {
+		hr := NewHomeRepository()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+
+		err := hr.SaveHome(ctx, &domain.Home{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("ok, save, update and get", func(t *testing.T) {
+		hr := NewHomeRepository()
+		ctx := context.Background()
+
+		home := &domain.Home{Name: "Дача"}
+		assert.NoError(t, hr.SaveHome(ctx, home))
+		assert.Equal(t, int64(1), home.ID)
+
+		home.Name = "Квартира"
+		assert.NoError(t, hr.SaveHome(ctx, home))
+
+		actual, err := hr.GetHomeByID(ctx, home.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Квартира", actual.Name)
+
+		homes, err := hr.GetHomes(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, homes, 1)
+	})
+
+	t.Run("ok, collision test", func(t *testing.T) {
+		hr := NewHomeRepository()
+		ctx := context.Background()
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 1000; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, hr.SaveHome(ctx, &domain.Home{Name: "home"}))
+			}()
+		}
+		wg.Wait()
+
+		homes, err := hr.GetHomes(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, homes, 1000)
+	})
+}
+
+func TestHomeRepository_DeleteHome(t *testing.T) {
+	t.Run("fail, not found", func(t *testing.T) {
+		hr := NewHomeRepository()
+		err := hr.DeleteHome(context.Background(), 1)
+		assert.ErrorIs(t, err, usecase.ErrHomeNotFound)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		hr := NewHomeRepository()
+		ctx := context.Background()
+
+		home := &domain.Home{Name: "Дача"}
+		assert.NoError(t, hr.SaveHome(ctx, home))
+		assert.NoError(t, hr.DeleteHome(ctx, home.ID))
+
+		_, err := hr.GetHomeByID(ctx, home.ID)
+		assert.ErrorIs(t, err, usecase.ErrHomeNotFound)
+	})
+}
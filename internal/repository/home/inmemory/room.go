@@ -0,0 +1,137 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"sort"
+	"sync"
+)
+
+type RoomRepository struct {
+	rooms map[int64]*domain.Room
+	// sensorRooms - размещение датчиков: id датчика -> id комнаты
+	sensorRooms map[int64]int64
+	mu          sync.RWMutex
+	lastID      int64
+}
+
+func NewRoomRepository() *RoomRepository {
+	return &RoomRepository{
+		rooms:       make(map[int64]*domain.Room),
+		sensorRooms: make(map[int64]int64),
+	}
+}
+
+func (r *RoomRepository) SaveRoom(ctx context.Context, room *domain.Room) error {
+	if room == nil {
+		return errors.New("room is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if room.ID == 0 {
+		r.lastID++
+		room.ID = r.lastID
+	}
+
+	stored := *room
+	r.rooms[room.ID] = &stored
+
+	return nil
+}
+
+func (r *RoomRepository) GetRoomByID(ctx context.Context, id int64) (*domain.Room, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	room, ok := r.rooms[id]
+	if !ok {
+		return nil, usecase.ErrRoomNotFound
+	}
+
+	result := *room
+	return &result, nil
+}
+
+func (r *RoomRepository) GetRoomsByHomeID(ctx context.Context, homeID int64) ([]domain.Room, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rooms := make([]domain.Room, 0)
+	for _, room := range r.rooms {
+		if room.HomeID == homeID {
+			rooms = append(rooms, *room)
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].ID < rooms[j].ID })
+
+	return rooms, nil
+}
+
+func (r *RoomRepository) DeleteRoom(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rooms[id]; !ok {
+		return usecase.ErrRoomNotFound
+	}
+	delete(r.rooms, id)
+
+	for sensorID, roomID := range r.sensorRooms {
+		if roomID == id {
+			delete(r.sensorRooms, sensorID)
+		}
+	}
+
+	return nil
+}
+
+func (r *RoomRepository) SaveSensorRoom(ctx context.Context, sensorRoom domain.SensorRoom) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sensorRooms[sensorRoom.SensorID] = sensorRoom.RoomID
+	return nil
+}
+
+func (r *RoomRepository) GetSensorsByRoomID(ctx context.Context, roomID int64) ([]domain.SensorRoom, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]domain.SensorRoom, 0)
+	for sensorID, id := range r.sensorRooms {
+		if id == roomID {
+			result = append(result, domain.SensorRoom{SensorID: sensorID, RoomID: id})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].SensorID < result[j].SensorID })
+
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoomRepository_GetRoomsByHomeID(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		rr := NewRoomRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := rr.GetRoomsByHomeID(ctx, 1)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, rooms of one home", func(t *testing.T) {
+		rr := NewRoomRepository()
+		ctx := context.Background()
+
+		assert.NoError(t, rr.SaveRoom(ctx, &domain.Room{HomeID: 1, Name: "Кухня"}))
+		assert.NoError(t, rr.SaveRoom(ctx, &domain.Room{HomeID: 1, Name: "Спальня"}))
+		assert.NoError(t, rr.SaveRoom(ctx, &domain.Room{HomeID: 2, Name: "Гараж"}))
+
+		rooms, err := rr.GetRoomsByHomeID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Len(t, rooms, 2)
+		assert.Equal(t, "Кухня", rooms[0].Name)
+	})
+}
+
+func TestRoomRepository_SaveSensorRoom(t *testing.T) {
+	t.Run("ok, sensor is moved to another room", func(t *testing.T) {
+		rr := NewRoomRepository()
+		ctx := context.Background()
+
+		assert.NoError(t, rr.SaveSensorRoom(ctx, domain.SensorRoom{SensorID: 1, RoomID: 1}))
+		assert.NoError(t, rr.SaveSensorRoom(ctx, domain.SensorRoom{SensorID: 1, RoomID: 2}))
+
+		sensors, err := rr.GetSensorsByRoomID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Empty(t, sensors)
+
+		sensors, err = rr.GetSensorsByRoomID(ctx, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.SensorRoom{{SensorID: 1, RoomID: 2}}, sensors)
+	})
+}
+
+func TestRoomRepository_DeleteRoom(t *testing.T) {
+	t.Run("fail, not found", func(t *testing.T) {
+		rr := NewRoomRepository()
+		err := rr.DeleteRoom(context.Background(), 1)
+		assert.ErrorIs(t, err, usecase.ErrRoomNotFound)
+	})
+
+	t.Run("ok, sensor links are removed", func(t *testing.T) {
+		rr := NewRoomRepository()
+		ctx := context.Background()
+
+		room := &domain.Room{HomeID: 1, Name: "Кухня"}
+		assert.NoError(t, rr.SaveRoom(ctx, room))
+		assert.NoError(t, rr.SaveSensorRoom(ctx, domain.SensorRoom{SensorID: 1, RoomID: room.ID}))
+
+		assert.NoError(t, rr.DeleteRoom(ctx, room.ID))
+
+		sensors, err := rr.GetSensorsByRoomID(ctx, room.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, sensors)
+	})
+}
@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type HomeRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewHomeRepository(pool *pgxpool.Pool) *HomeRepository {
+	return &HomeRepository{
+		pool: pool,
+	}
+}
+
+func (r *HomeRepository) SaveHome(ctx context.Context, home *domain.Home) error {
+	if home == nil {
+		return errors.New("home is nil")
+	}
+
+	if home.ID == 0 {
+		query := `
+			INSERT INTO homes (name, address, time_zone, latitude, longitude)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`
+		latitude, longitude := coordinatesToColumns(home.Coordinates)
+		err := transaction.Conn(ctx, r.pool).QueryRow(ctx, query, home.Name, home.Address, home.TimeZone, latitude, longitude).Scan(&home.ID)
+		if err != nil {
+			return fmt.Errorf("failed to insert home: %w", err)
+		}
+		return nil
+	}
+
+	query := `
+		UPDATE homes SET name = $2, address = $3, time_zone = $4, latitude = $5, longitude = $6
+		WHERE id = $1
+	`
+	latitude, longitude := coordinatesToColumns(home.Coordinates)
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, query, home.ID, home.Name, home.Address, home.TimeZone, latitude, longitude)
+	if err != nil {
+		return fmt.Errorf("failed to update home: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrHomeNotFound
+	}
+	return nil
+}
+
+func (r *HomeRepository) GetHomes(ctx context.Context) ([]domain.Home, error) {
+	query := `
+		SELECT id, name, address, time_zone, latitude, longitude
+		FROM homes
+		ORDER BY id
+	`
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query homes: %w", err)
+	}
+	defer rows.Close()
+
+	var homes []domain.Home
+	for rows.Next() {
+		h, err := scanHome(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan home: %w", err)
+		}
+		homes = append(homes, *h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through homes: %w", err)
+	}
+	return homes, nil
+}
+
+func (r *HomeRepository) GetHomeByID(ctx context.Context, id int64) (*domain.Home, error) {
+	query := `
+		SELECT id, name, address, time_zone, latitude, longitude
+		FROM homes
+		WHERE id = $1
+	`
+	h, err := scanHome(transaction.Conn(ctx, r.pool).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrHomeNotFound
+		}
+		return nil, fmt.Errorf("failed to get home: %w", err)
+	}
+	return h, nil
+}
+
+func (r *HomeRepository) DeleteHome(ctx context.Context, id int64) error {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM homes WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete home: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrHomeNotFound
+	}
+	return nil
+}
+
+func scanHome(row pgx.Row) (*domain.Home, error) {
+	var (
+		h                   domain.Home
+		latitude, longitude *float64
+	)
+	if err := row.Scan(&h.ID, &h.Name, &h.Address, &h.TimeZone, &latitude, &longitude); err != nil {
+		return nil, err
+	}
+	if latitude != nil && longitude != nil {
+		h.Coordinates = &domain.Coordinates{Latitude: *latitude, Longitude: *longitude}
+	}
+	return &h, nil
+}
+
+// coordinatesToColumns раскладывает координаты по колонкам, отсутствующие хранятся как null
+func coordinatesToColumns(coordinates *domain.Coordinates) (*float64, *float64) {
+	if coordinates == nil {
+		return nil, nil
+	}
+	return &coordinates.Latitude, &coordinates.Longitude
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type HomeOwnerRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewHomeOwnerRepository(pool *pgxpool.Pool) *HomeOwnerRepository {
+	return &HomeOwnerRepository{
+		pool: pool,
+	}
+}
+
+func (r *HomeOwnerRepository) SaveHomeOwner(ctx context.Context, homeOwner domain.HomeOwner) error {
+	query := `
+		INSERT INTO homes_users (home_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (home_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, query, homeOwner.HomeID, homeOwner.UserID, homeOwner.Role)
+	if err != nil {
+		return fmt.Errorf("failed to save home owner: %w", err)
+	}
+	return nil
+}
+
+func (r *HomeOwnerRepository) GetHomesByUserID(ctx context.Context, userID int64) ([]domain.HomeOwner, error) {
+	query := `
+		SELECT home_id, user_id, role
+		FROM homes_users
+		WHERE user_id = $1
+	`
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query home owners: %w", err)
+	}
+	defer rows.Close()
+
+	var result []domain.HomeOwner
+	for rows.Next() {
+		var ho domain.HomeOwner
+		if err := rows.Scan(&ho.HomeID, &ho.UserID, &ho.Role); err != nil {
+			return nil, fmt.Errorf("failed to scan home owner: %w", err)
+		}
+		result = append(result, ho)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through home owners: %w", err)
+	}
+	return result, nil
+}
+
+func (r *HomeOwnerRepository) DeleteHomeOwnersByHomeID(ctx context.Context, homeID int64) error {
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM homes_users WHERE home_id = $1`, homeID)
+	if err != nil {
+		return fmt.Errorf("failed to delete home owners: %w", err)
+	}
+	return nil
+}
+
+func (r *HomeOwnerRepository) DeleteHomeOwnersByUserID(ctx context.Context, userID int64) error {
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM homes_users WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user's home owners: %w", err)
+	}
+	return nil
+}
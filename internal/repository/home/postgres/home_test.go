@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type HomeTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	homeRepo      *HomeRepository
+	roomRepo      *RoomRepository
+	homeOwnerRepo *HomeOwnerRepository
+}
+
+func (suite *HomeTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.homeRepo = NewHomeRepository(suite.testDbInstance)
+	suite.roomRepo = NewRoomRepository(suite.testDbInstance)
+	suite.homeOwnerRepo = NewHomeOwnerRepository(suite.testDbInstance)
+}
+
+func (suite *HomeTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *HomeTestSuite) TestHomeRepository_SaveHome() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	home := &domain.Home{Name: "Дача", Address: "Лесная, 1"}
+	assert.Nil(suite.T(), suite.homeRepo.SaveHome(ctx, home))
+	assert.NotZero(suite.T(), home.ID)
+
+	home.Name = "Квартира"
+	home.TimeZone = "Europe/Moscow"
+	home.Coordinates = &domain.Coordinates{Latitude: 55.7558, Longitude: 37.6173}
+	assert.Nil(suite.T(), suite.homeRepo.SaveHome(ctx, home))
+
+	actual, err := suite.homeRepo.GetHomeByID(ctx, home.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), *home, *actual)
+
+	err = suite.homeRepo.SaveHome(ctx, &domain.Home{ID: 100500, Name: "нет такого"})
+	assert.ErrorIs(suite.T(), err, usecase.ErrHomeNotFound)
+}
+
+func (suite *HomeTestSuite) TestRoomRepository_SensorRooms() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	room := &domain.Room{HomeID: 1, Name: "Кухня"}
+	assert.Nil(suite.T(), suite.roomRepo.SaveRoom(ctx, room))
+
+	assert.Nil(suite.T(), suite.roomRepo.SaveSensorRoom(ctx, domain.SensorRoom{SensorID: 1, RoomID: room.ID}))
+	assert.Nil(suite.T(), suite.roomRepo.SaveSensorRoom(ctx, domain.SensorRoom{SensorID: 2, RoomID: room.ID}))
+
+	sensors, err := suite.roomRepo.GetSensorsByRoomID(ctx, room.ID)
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), sensors, 2)
+
+	assert.Nil(suite.T(), suite.roomRepo.DeleteRoom(ctx, room.ID))
+
+	sensors, err = suite.roomRepo.GetSensorsByRoomID(ctx, room.ID)
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), sensors)
+}
+
+func (suite *HomeTestSuite) TestHomeOwnerRepository_GetHomesByUserID() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.Nil(suite.T(), suite.homeOwnerRepo.SaveHomeOwner(ctx, domain.HomeOwner{UserID: 3, HomeID: 1, Role: domain.HomeRoleMember}))
+	assert.Nil(suite.T(), suite.homeOwnerRepo.SaveHomeOwner(ctx, domain.HomeOwner{UserID: 3, HomeID: 1, Role: domain.HomeRoleOwner}))
+	assert.Nil(suite.T(), suite.homeOwnerRepo.SaveHomeOwner(ctx, domain.HomeOwner{UserID: 3, HomeID: 2, Role: domain.HomeRoleMember}))
+
+	homes, err := suite.homeOwnerRepo.GetHomesByUserID(ctx, 3)
+	assert.Nil(suite.T(), err)
+	assert.ElementsMatch(suite.T(), []domain.HomeOwner{
+		{UserID: 3, HomeID: 1, Role: domain.HomeRoleOwner},
+		{UserID: 3, HomeID: 2, Role: domain.HomeRoleMember},
+	}, homes)
+}
+
+func (suite *HomeTestSuite) TestRoomRepository_DeleteRoomRollback() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	room := &domain.Room{HomeID: 1, Name: "Спальня"}
+	assert.Nil(suite.T(), suite.roomRepo.SaveRoom(ctx, room))
+	assert.Nil(suite.T(), suite.roomRepo.SaveSensorRoom(ctx, domain.SensorRoom{SensorID: 3, RoomID: room.ID}))
+
+	rollback := errors.New("rollback")
+	err := transaction.NewTransactor(suite.testDbInstance).WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := suite.roomRepo.DeleteRoom(ctx, room.ID); err != nil {
+			return err
+		}
+
+		_, err := suite.roomRepo.GetRoomByID(ctx, room.ID)
+		assert.ErrorIs(suite.T(), err, usecase.ErrRoomNotFound, "room is deleted inside the transaction")
+		return rollback
+	})
+	assert.ErrorIs(suite.T(), err, rollback)
+
+	actual, err := suite.roomRepo.GetRoomByID(ctx, room.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), *room, *actual)
+
+	sensors, err := suite.roomRepo.GetSensorsByRoomID(ctx, room.ID)
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), sensors, 1)
+}
+
+func TestHomeTestSuite(t *testing.T) {
+	suite.Run(t, new(HomeTestSuite))
+}
@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RoomRepository struct {
+	pool       *pgxpool.Pool
+	transactor *transaction.Transactor
+}
+
+func NewRoomRepository(pool *pgxpool.Pool) *RoomRepository {
+	return &RoomRepository{
+		pool:       pool,
+		transactor: transaction.NewTransactor(pool),
+	}
+}
+
+func (r *RoomRepository) SaveRoom(ctx context.Context, room *domain.Room) error {
+	if room == nil {
+		return errors.New("room is nil")
+	}
+
+	if room.ID == 0 {
+		query := `
+			INSERT INTO rooms (home_id, name)
+			VALUES ($1, $2)
+			RETURNING id
+		`
+		if err := transaction.Conn(ctx, r.pool).QueryRow(ctx, query, room.HomeID, room.Name).Scan(&room.ID); err != nil {
+			return fmt.Errorf("failed to insert room: %w", err)
+		}
+		return nil
+	}
+
+	query := `
+		UPDATE rooms SET home_id = $2, name = $3
+		WHERE id = $1
+	`
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, query, room.ID, room.HomeID, room.Name)
+	if err != nil {
+		return fmt.Errorf("failed to update room: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrRoomNotFound
+	}
+	return nil
+}
+
+func (r *RoomRepository) GetRoomByID(ctx context.Context, id int64) (*domain.Room, error) {
+	query := `
+		SELECT id, home_id, name
+		FROM rooms
+		WHERE id = $1
+	`
+	var room domain.Room
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, query, id).Scan(&room.ID, &room.HomeID, &room.Name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrRoomNotFound
+		}
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+	return &room, nil
+}
+
+func (r *RoomRepository) GetRoomsByHomeID(ctx context.Context, homeID int64) ([]domain.Room, error) {
+	query := `
+		SELECT id, home_id, name
+		FROM rooms
+		WHERE home_id = $1
+		ORDER BY id
+	`
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, homeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rooms: %w", err)
+	}
+	defer rows.Close()
+
+	var rooms []domain.Room
+	for rows.Next() {
+		var room domain.Room
+		if err := rows.Scan(&room.ID, &room.HomeID, &room.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan room: %w", err)
+		}
+		rooms = append(rooms, room)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through rooms: %w", err)
+	}
+	return rooms, nil
+}
+
+// DeleteRoom удаляет комнату вместе с привязками датчиков, внутри открытой транзакции использует ее
+func (r *RoomRepository) DeleteRoom(ctx context.Context, id int64) error {
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		conn := transaction.Conn(ctx, r.pool)
+		if _, err := conn.Exec(ctx, `DELETE FROM rooms_sensors WHERE room_id = $1`, id); err != nil {
+			return fmt.Errorf("failed to delete room sensors: %w", err)
+		}
+
+		tag, err := conn.Exec(ctx, `DELETE FROM rooms WHERE id = $1`, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete room: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return usecase.ErrRoomNotFound
+		}
+		return nil
+	})
+}
+
+func (r *RoomRepository) SaveSensorRoom(ctx context.Context, sensorRoom domain.SensorRoom) error {
+	query := `
+		INSERT INTO rooms_sensors (sensor_id, room_id)
+		VALUES ($1, $2)
+		ON CONFLICT (sensor_id) DO UPDATE SET room_id = EXCLUDED.room_id
+	`
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, query, sensorRoom.SensorID, sensorRoom.RoomID)
+	if err != nil {
+		return fmt.Errorf("failed to save sensor room: %w", err)
+	}
+	return nil
+}
+
+func (r *RoomRepository) GetSensorsByRoomID(ctx context.Context, roomID int64) ([]domain.SensorRoom, error) {
+	query := `
+		SELECT sensor_id, room_id
+		FROM rooms_sensors
+		WHERE room_id = $1
+		ORDER BY sensor_id
+	`
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, roomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query room sensors: %w", err)
+	}
+	defer rows.Close()
+
+	var result []domain.SensorRoom
+	for rows.Next() {
+		var sr domain.SensorRoom
+		if err := rows.Scan(&sr.SensorID, &sr.RoomID); err != nil {
+			return nil, fmt.Errorf("failed to scan room sensor: %w", err)
+		}
+		result = append(result, sr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through room sensors: %w", err)
+	}
+	return result, nil
+}
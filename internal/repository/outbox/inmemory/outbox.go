@@ -0,0 +1,113 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"slices"
+	"sync"
+	"time"
+)
+
+// OutboxRepository хранит записи outbox в порядке добавления
+type OutboxRepository struct {
+	entries []domain.OutboxEntry
+	mu      sync.Mutex
+	lastID  int64
+}
+
+func NewOutboxRepository() *OutboxRepository {
+	return &OutboxRepository{}
+}
+
+func (r *OutboxRepository) AddOutboxEntry(ctx context.Context, entry *domain.OutboxEntry) error {
+	if entry == nil {
+		return errors.New("outbox entry is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	entry.ID = r.lastID
+	r.entries = append(r.entries, copyEntry(*entry))
+
+	return nil
+}
+
+func (r *OutboxRepository) ClaimOutboxEntries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.OutboxEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]domain.OutboxEntry, 0)
+	// датчики, у которых есть более ранняя запись, время попытки которой не наступило
+	blocked := make(map[int64]struct{})
+	for i := range r.entries {
+		if len(result) >= limit {
+			break
+		}
+		entry := &r.entries[i]
+		if _, ok := blocked[entry.SensorID]; ok {
+			continue
+		}
+		if entry.NextAttemptAt.After(now) {
+			blocked[entry.SensorID] = struct{}{}
+			continue
+		}
+		entry.NextAttemptAt = leaseUntil
+		result = append(result, copyEntry(*entry))
+	}
+
+	return result, nil
+}
+
+func (r *OutboxRepository) RetryOutboxEntry(ctx context.Context, entry *domain.OutboxEntry) error {
+	if entry == nil {
+		return errors.New("outbox entry is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.entries {
+		if r.entries[i].ID == entry.ID {
+			r.entries[i].Attempts = entry.Attempts
+			r.entries[i].NextAttemptAt = entry.NextAttemptAt
+			r.entries[i].LastError = entry.LastError
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *OutboxRepository) DeleteOutboxEntry(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = slices.DeleteFunc(r.entries, func(entry domain.OutboxEntry) bool {
+		return entry.ID == id
+	})
+
+	return nil
+}
+
+func copyEntry(entry domain.OutboxEntry) domain.OutboxEntry {
+	entry.Payload = slices.Clone(entry.Payload)
+	return entry
+}
@@ -0,0 +1,79 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxRepository(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		or := NewOutboxRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := or.AddOutboxEntry(ctx, &domain.OutboxEntry{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, later entries of sensor wait for earlier", func(t *testing.T) {
+		or := NewOutboxRepository()
+		ctx := context.Background()
+		now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		entries := []domain.OutboxEntry{
+			{SensorID: 1, NextAttemptAt: now.Add(time.Minute)},
+			{SensorID: 2, NextAttemptAt: now},
+			{SensorID: 1, NextAttemptAt: now},
+			{SensorID: 2, NextAttemptAt: now},
+		}
+		for i := range entries {
+			require.NoError(t, or.AddOutboxEntry(ctx, &entries[i]))
+		}
+
+		leaseUntil := now.Add(time.Minute)
+		claimed, err := or.ClaimOutboxEntries(ctx, now, leaseUntil, 10)
+		require.NoError(t, err)
+		require.Len(t, claimed, 2)
+		assert.Equal(t, int64(2), claimed[0].ID)
+		assert.Equal(t, int64(4), claimed[1].ID)
+		assert.Equal(t, leaseUntil, claimed[0].NextAttemptAt)
+
+		claimed, err = or.ClaimOutboxEntries(ctx, now, leaseUntil, 10)
+		require.NoError(t, err)
+		assert.Empty(t, claimed)
+
+		// после окончания выбора и доставки первой записи датчика 1 выбирается следующая
+		require.NoError(t, or.DeleteOutboxEntry(ctx, 1))
+		claimed, err = or.ClaimOutboxEntries(ctx, leaseUntil, leaseUntil.Add(time.Minute), 1)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		assert.Equal(t, int64(2), claimed[0].ID)
+	})
+
+	t.Run("ok, retry reschedules entry", func(t *testing.T) {
+		or := NewOutboxRepository()
+		ctx := context.Background()
+		now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		entry := &domain.OutboxEntry{SensorID: 1, NextAttemptAt: now}
+		require.NoError(t, or.AddOutboxEntry(ctx, entry))
+
+		entry.Attempts, entry.LastError, entry.NextAttemptAt = 1, "unavailable", now.Add(time.Second)
+		require.NoError(t, or.RetryOutboxEntry(ctx, entry))
+
+		claimed, err := or.ClaimOutboxEntries(ctx, now, now.Add(time.Minute), 10)
+		require.NoError(t, err)
+		assert.Empty(t, claimed)
+
+		claimed, err = or.ClaimOutboxEntries(ctx, now.Add(time.Second), now.Add(time.Minute), 10)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		assert.Equal(t, 1, claimed[0].Attempts)
+		assert.Equal(t, "unavailable", claimed[0].LastError)
+	})
+}
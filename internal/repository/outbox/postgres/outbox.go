@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	outboxColumns = `id, sensor_id, kind, payload, attempts, next_attempt_at, last_error, created_at`
+	// outboxClaimLock - ключ advisory-блокировки, которая упорядочивает выбор записей экземплярами сервиса
+	outboxClaimLock = 44
+)
+
+// OutboxRepository хранит записи outbox в таблице outbox
+type OutboxRepository struct {
+	pool       *pgxpool.Pool
+	transactor *transaction.Transactor
+}
+
+func NewOutboxRepository(pool *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{
+		pool:       pool,
+		transactor: transaction.NewTransactor(pool),
+	}
+}
+
+func (r *OutboxRepository) AddOutboxEntry(ctx context.Context, entry *domain.OutboxEntry) error {
+	if entry == nil {
+		return errors.New("outbox entry is nil")
+	}
+
+	query := `
+		INSERT INTO outbox (sensor_id, kind, payload, attempts, next_attempt_at, last_error, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, query, entry.SensorID, entry.Kind, entry.Payload, entry.Attempts,
+		entry.NextAttemptAt, entry.LastError, entry.CreatedAt).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save outbox entry: %w", err)
+	}
+	return nil
+}
+
+// ClaimOutboxEntries выбирает записи под advisory-блокировкой: без нее два экземпляра могли бы одновременно
+// выбрать разные записи одного датчика и доставить их не по порядку
+func (r *OutboxRepository) ClaimOutboxEntries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.OutboxEntry, error) {
+	var entries []domain.OutboxEntry
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		conn := transaction.Conn(ctx, r.pool)
+		if _, err := conn.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, outboxClaimLock); err != nil {
+			return fmt.Errorf("failed to lock outbox: %w", err)
+		}
+
+		query := `
+			UPDATE outbox SET next_attempt_at = $2
+			WHERE id IN (
+				SELECT o.id FROM outbox o
+				WHERE o.next_attempt_at <= $1
+					AND NOT EXISTS (
+						SELECT 1 FROM outbox p WHERE p.sensor_id = o.sensor_id AND p.id < o.id AND p.next_attempt_at > $1
+					)
+				ORDER BY o.id
+				LIMIT $3
+			)
+			RETURNING ` + outboxColumns
+		rows, err := conn.Query(ctx, query, now, leaseUntil, limit)
+		if err != nil {
+			return fmt.Errorf("failed to claim outbox entries: %w", err)
+		}
+		defer rows.Close()
+
+		entries = []domain.OutboxEntry{}
+		for rows.Next() {
+			var entry domain.OutboxEntry
+			err := rows.Scan(&entry.ID, &entry.SensorID, &entry.Kind, &entry.Payload, &entry.Attempts,
+				&entry.NextAttemptAt, &entry.LastError, &entry.CreatedAt)
+			if err != nil {
+				return fmt.Errorf("failed to scan outbox entry: %w", err)
+			}
+			entries = append(entries, entry)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating through outbox entries: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING не сохраняет порядок подзапроса
+	slices.SortFunc(entries, func(a, b domain.OutboxEntry) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return entries, nil
+}
+
+func (r *OutboxRepository) RetryOutboxEntry(ctx context.Context, entry *domain.OutboxEntry) error {
+	if entry == nil {
+		return errors.New("outbox entry is nil")
+	}
+
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, `
+		UPDATE outbox SET attempts = $2, next_attempt_at = $3, last_error = $4 WHERE id = $1
+	`, entry.ID, entry.Attempts, entry.NextAttemptAt, entry.LastError)
+	if err != nil {
+		return fmt.Errorf("failed to update outbox entry: %w", err)
+	}
+	return nil
+}
+
+func (r *OutboxRepository) DeleteOutboxEntry(ctx context.Context, id int64) error {
+	if _, err := transaction.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM outbox WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete outbox entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type OutboxTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	repo *OutboxRepository
+}
+
+func (suite *OutboxTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.repo = NewOutboxRepository(suite.testDbInstance)
+}
+
+func (suite *OutboxTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *OutboxTestSuite) TestOutboxRepository() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	entries := []domain.OutboxEntry{
+		{SensorID: 1, Kind: domain.OutboxSensorEvent, Payload: []byte(`{"Payload": 1}`), NextAttemptAt: now.Add(time.Minute), CreatedAt: now},
+		{SensorID: 2, Kind: domain.OutboxSensorEvent, Payload: []byte(`{"Payload": 2}`), NextAttemptAt: now, CreatedAt: now},
+		{SensorID: 1, Kind: domain.OutboxSensorEvent, Payload: []byte(`{"Payload": 3}`), NextAttemptAt: now, CreatedAt: now},
+		{SensorID: 2, Kind: domain.OutboxSensorEvent, Payload: []byte(`{"Payload": 4}`), NextAttemptAt: now, CreatedAt: now},
+	}
+	for i := range entries {
+		assert.Nil(suite.T(), suite.repo.AddOutboxEntry(ctx, &entries[i]))
+		assert.NotZero(suite.T(), entries[i].ID)
+	}
+
+	leaseUntil := now.Add(time.Minute)
+	claimed, err := suite.repo.ClaimOutboxEntries(ctx, now, leaseUntil, 10)
+	assert.Nil(suite.T(), err)
+	if assert.Len(suite.T(), claimed, 2) {
+		assert.Equal(suite.T(), entries[1].ID, claimed[0].ID)
+		assert.Equal(suite.T(), entries[3].ID, claimed[1].ID)
+		assert.Equal(suite.T(), leaseUntil, claimed[0].NextAttemptAt)
+		assert.JSONEq(suite.T(), `{"Payload": 2}`, string(claimed[0].Payload))
+	}
+
+	claimed, err = suite.repo.ClaimOutboxEntries(ctx, now, leaseUntil, 10)
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), claimed)
+
+	entries[0].Attempts, entries[0].LastError, entries[0].NextAttemptAt = 1, "unavailable", now
+	assert.Nil(suite.T(), suite.repo.RetryOutboxEntry(ctx, &entries[0]))
+	for _, entry := range entries[1:] {
+		assert.Nil(suite.T(), suite.repo.DeleteOutboxEntry(ctx, entry.ID))
+	}
+
+	claimed, err = suite.repo.ClaimOutboxEntries(ctx, now, leaseUntil, 10)
+	assert.Nil(suite.T(), err)
+	if assert.Len(suite.T(), claimed, 1) {
+		assert.Equal(suite.T(), entries[0].ID, claimed[0].ID)
+		assert.Equal(suite.T(), 1, claimed[0].Attempts)
+		assert.Equal(suite.T(), "unavailable", claimed[0].LastError)
+	}
+}
+
+func TestOutboxTestSuite(t *testing.T) {
+	suite.Run(t, new(OutboxTestSuite))
+}
@@ -0,0 +1,75 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"math"
+	"sync"
+	"time"
+)
+
+// maxBuckets - число корзин, после которого из хранилища удаляются полностью пополнившиеся корзины
+const maxBuckets = 10000
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   domain.RateLimit
+}
+
+// refill пополняет корзину на момент now
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+		b.updated = now
+	}
+}
+
+// RateLimitStore хранит корзины токенов в памяти процесса
+type RateLimitStore struct {
+	buckets map[string]*bucket
+	mu      sync.Mutex
+}
+
+func NewRateLimitStore() *RateLimitStore {
+	return &RateLimitStore{
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (s *RateLimitStore) TakeToken(ctx context.Context, key string, limit domain.RateLimit, now time.Time) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok || b.limit != limit {
+		if len(s.buckets) >= maxBuckets {
+			s.evictFull(now)
+		}
+		b = &bucket{tokens: float64(limit.Burst), updated: now, limit: limit}
+		s.buckets[key] = b
+	}
+	b.refill(now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, nil
+	}
+
+	wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+	return max(wait, time.Nanosecond), nil
+}
+
+// evictFull удаляет корзины, которые пополнились до емкости: новая корзина для того же ключа будет такой же
+func (s *RateLimitStore) evictFull(now time.Time) {
+	for key, b := range s.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
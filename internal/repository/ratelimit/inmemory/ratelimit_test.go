@@ -0,0 +1,65 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitStore(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limit := domain.RateLimit{Rate: 2, Burst: 3}
+
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		s := NewRateLimitStore()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := s.TakeToken(ctx, "k", limit, now)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, burst then refill", func(t *testing.T) {
+		s := NewRateLimitStore()
+		ctx := context.Background()
+
+		for i := 0; i < limit.Burst; i++ {
+			wait, err := s.TakeToken(ctx, "k", limit, now)
+			require.NoError(t, err)
+			assert.Zero(t, wait)
+		}
+
+		wait, err := s.TakeToken(ctx, "k", limit, now)
+		require.NoError(t, err)
+		assert.Equal(t, 500*time.Millisecond, wait)
+
+		wait, err = s.TakeToken(ctx, "other", limit, now)
+		require.NoError(t, err)
+		assert.Zero(t, wait, "keys have separate buckets")
+
+		wait, err = s.TakeToken(ctx, "k", limit, now.Add(250*time.Millisecond))
+		require.NoError(t, err)
+		assert.Equal(t, 250*time.Millisecond, wait)
+
+		wait, err = s.TakeToken(ctx, "k", limit, now.Add(500*time.Millisecond))
+		require.NoError(t, err)
+		assert.Zero(t, wait)
+	})
+
+	t.Run("ok, changed limit resets bucket", func(t *testing.T) {
+		s := NewRateLimitStore()
+		ctx := context.Background()
+
+		single := domain.RateLimit{Rate: 1, Burst: 1}
+		_, err := s.TakeToken(ctx, "k", single, now)
+		require.NoError(t, err)
+
+		wait, err := s.TakeToken(ctx, "k", limit, now)
+		require.NoError(t, err)
+		assert.Zero(t, wait)
+	})
+}
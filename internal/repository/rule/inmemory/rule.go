@@ -0,0 +1,188 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"sort"
+	"sync"
+)
+
+type RuleRepository struct {
+	rules  map[int64]*domain.Rule
+	states map[int64]domain.RuleState
+	mu     sync.RWMutex
+	lastID int64
+}
+
+func NewRuleRepository() *RuleRepository {
+	return &RuleRepository{
+		rules:  make(map[int64]*domain.Rule),
+		states: make(map[int64]domain.RuleState),
+	}
+}
+
+func (r *RuleRepository) SaveRule(ctx context.Context, rule *domain.Rule) error {
+	if rule == nil {
+		return errors.New("rule is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if rule.ID == 0 {
+		r.lastID++
+		rule.ID = r.lastID
+	} else if _, ok := r.rules[rule.ID]; !ok {
+		return usecase.ErrRuleNotFound
+	}
+
+	r.rules[rule.ID] = copyRule(rule)
+
+	return nil
+}
+
+func (r *RuleRepository) GetRules(ctx context.Context) ([]domain.Rule, error) {
+	return r.findRules(ctx, func(*domain.Rule) bool { return true })
+}
+
+func (r *RuleRepository) GetRuleByID(ctx context.Context, id int64) (*domain.Rule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rule, ok := r.rules[id]
+	if !ok {
+		return nil, usecase.ErrRuleNotFound
+	}
+
+	return copyRule(rule), nil
+}
+
+func (r *RuleRepository) GetRulesBySensorID(ctx context.Context, sensorID int64) ([]domain.Rule, error) {
+	return r.findRules(ctx, func(rule *domain.Rule) bool { return rule.SensorID == sensorID })
+}
+
+func (r *RuleRepository) DeleteRule(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rules[id]; !ok {
+		return usecase.ErrRuleNotFound
+	}
+	delete(r.rules, id)
+	delete(r.states, id)
+
+	return nil
+}
+
+func (r *RuleRepository) SaveRuleState(ctx context.Context, state domain.RuleState) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rules[state.RuleID]; !ok {
+		return usecase.ErrRuleNotFound
+	}
+	r.states[state.RuleID] = copyRuleState(state)
+
+	return nil
+}
+
+func (r *RuleRepository) GetRuleState(ctx context.Context, ruleID int64) (*domain.RuleState, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state, ok := r.states[ruleID]
+	if !ok {
+		return nil, nil
+	}
+
+	result := copyRuleState(state)
+	return &result, nil
+}
+
+func (r *RuleRepository) GetRuleStates(ctx context.Context) ([]domain.RuleState, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	states := make([]domain.RuleState, 0, len(r.states))
+	for _, state := range r.states {
+		states = append(states, copyRuleState(state))
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].RuleID < states[j].RuleID })
+
+	return states, nil
+}
+
+func (r *RuleRepository) DeleteRuleState(ctx context.Context, ruleID int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.states, ruleID)
+
+	return nil
+}
+
+func (r *RuleRepository) findRules(ctx context.Context, match func(*domain.Rule) bool) ([]domain.Rule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rules := make([]domain.Rule, 0)
+	for _, rule := range r.rules {
+		if match(rule) {
+			rules = append(rules, *copyRule(rule))
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	return rules, nil
+}
+
+func copyRule(rule *domain.Rule) *domain.Rule {
+	result := *rule
+	if rule.Window != nil {
+		window := *rule.Window
+		result.Window = &window
+	}
+	return &result
+}
+
+func copyRuleState(state domain.RuleState) domain.RuleState {
+	if state.PendingSince != nil {
+		pendingSince := *state.PendingSince
+		state.PendingSince = &pendingSince
+	}
+	return state
+}
@@ -0,0 +1,80 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleRepository(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		rr := NewRuleRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := rr.SaveRule(ctx, &domain.Rule{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("fail, update unknown rule", func(t *testing.T) {
+		rr := NewRuleRepository()
+		err := rr.SaveRule(context.Background(), &domain.Rule{ID: 5})
+		assert.ErrorIs(t, err, usecase.ErrRuleNotFound)
+	})
+
+	t.Run("ok, rules are copied", func(t *testing.T) {
+		rr := NewRuleRepository()
+		ctx := context.Background()
+
+		rule := &domain.Rule{SensorID: 1, Name: "night", Window: &domain.RuleWindow{From: 23 * 60, To: 6 * 60}}
+		require.NoError(t, rr.SaveRule(ctx, rule))
+		require.NoError(t, rr.SaveRule(ctx, &domain.Rule{SensorID: 2, Name: "other"}))
+		rule.Window.From = 0
+
+		stored, err := rr.GetRuleByID(ctx, rule.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 23*60, stored.Window.From)
+
+		rules, err := rr.GetRulesBySensorID(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		assert.Equal(t, "night", rules[0].Name)
+
+		rules, err = rr.GetRules(ctx)
+		require.NoError(t, err)
+		assert.Len(t, rules, 2)
+	})
+
+	t.Run("ok, state is deleted with rule", func(t *testing.T) {
+		rr := NewRuleRepository()
+		ctx := context.Background()
+
+		rule := &domain.Rule{SensorID: 1, Name: "hot"}
+		require.NoError(t, rr.SaveRule(ctx, rule))
+
+		state, err := rr.GetRuleState(ctx, rule.ID)
+		require.NoError(t, err)
+		assert.Nil(t, state)
+
+		pendingSince := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		require.NoError(t, rr.SaveRuleState(ctx, domain.RuleState{RuleID: rule.ID, Value: 900, PendingSince: &pendingSince}))
+
+		states, err := rr.GetRuleStates(ctx)
+		require.NoError(t, err)
+		require.Len(t, states, 1)
+		assert.Equal(t, pendingSince, *states[0].PendingSince)
+
+		require.NoError(t, rr.DeleteRule(ctx, rule.ID))
+		assert.ErrorIs(t, rr.DeleteRule(ctx, rule.ID), usecase.ErrRuleNotFound)
+		assert.ErrorIs(t, rr.SaveRuleState(ctx, domain.RuleState{RuleID: rule.ID}), usecase.ErrRuleNotFound)
+
+		states, err = rr.GetRuleStates(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, states)
+	})
+}
@@ -0,0 +1,210 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const ruleColumns = `id, sensor_id, name, operator, threshold, duration_seconds, window_from, window_to, window_time_zone,
+	enabled, created_by, created_at`
+
+type RuleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRuleRepository(pool *pgxpool.Pool) *RuleRepository {
+	return &RuleRepository{
+		pool: pool,
+	}
+}
+
+func (r *RuleRepository) SaveRule(ctx context.Context, rule *domain.Rule) error {
+	if rule == nil {
+		return errors.New("rule is nil")
+	}
+
+	var windowFrom, windowTo *int
+	var windowTimeZone *string
+	if rule.Window != nil {
+		windowFrom, windowTo, windowTimeZone = &rule.Window.From, &rule.Window.To, &rule.Window.TimeZone
+	}
+	durationSeconds := int64(rule.Duration / time.Second)
+
+	conn := transaction.Conn(ctx, r.pool)
+	if rule.ID == 0 {
+		query := `
+			INSERT INTO rules (sensor_id, name, operator, threshold, duration_seconds, window_from, window_to,
+				window_time_zone, enabled, created_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			RETURNING id
+		`
+		err := conn.QueryRow(ctx, query, rule.SensorID, rule.Name, rule.Operator, rule.Threshold, durationSeconds,
+			windowFrom, windowTo, windowTimeZone, rule.Enabled, rule.CreatedBy, rule.CreatedAt).Scan(&rule.ID)
+		if err != nil {
+			return fmt.Errorf("failed to insert rule: %w", err)
+		}
+		return nil
+	}
+
+	query := `
+		UPDATE rules
+		SET name = $2, operator = $3, threshold = $4, duration_seconds = $5, window_from = $6, window_to = $7,
+			window_time_zone = $8, enabled = $9
+		WHERE id = $1
+	`
+	tag, err := conn.Exec(ctx, query, rule.ID, rule.Name, rule.Operator, rule.Threshold, durationSeconds,
+		windowFrom, windowTo, windowTimeZone, rule.Enabled)
+	if err != nil {
+		return fmt.Errorf("failed to update rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrRuleNotFound
+	}
+	return nil
+}
+
+func (r *RuleRepository) GetRules(ctx context.Context) ([]domain.Rule, error) {
+	return r.queryRules(ctx, `SELECT `+ruleColumns+` FROM rules ORDER BY id`)
+}
+
+func (r *RuleRepository) GetRuleByID(ctx context.Context, id int64) (*domain.Rule, error) {
+	rule, err := scanRule(transaction.Conn(ctx, r.pool).QueryRow(ctx, `SELECT `+ruleColumns+` FROM rules WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to get rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (r *RuleRepository) GetRulesBySensorID(ctx context.Context, sensorID int64) ([]domain.Rule, error) {
+	return r.queryRules(ctx, `SELECT `+ruleColumns+` FROM rules WHERE sensor_id = $1 ORDER BY id`, sensorID)
+}
+
+func (r *RuleRepository) DeleteRule(ctx context.Context, id int64) error {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrRuleNotFound
+	}
+	return nil
+}
+
+func (r *RuleRepository) SaveRuleState(ctx context.Context, state domain.RuleState) error {
+	query := `
+		INSERT INTO rule_states (rule_id, value, pending_since, firing, updated_at)
+		SELECT $1, $2, $3, $4, $5 WHERE EXISTS (SELECT 1 FROM rules WHERE id = $1)
+		ON CONFLICT (rule_id) DO UPDATE
+		SET value = EXCLUDED.value, pending_since = EXCLUDED.pending_since, firing = EXCLUDED.firing,
+			updated_at = EXCLUDED.updated_at
+	`
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, query, state.RuleID, state.Value, state.PendingSince,
+		state.Firing, state.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save rule state: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrRuleNotFound
+	}
+	return nil
+}
+
+func (r *RuleRepository) GetRuleState(ctx context.Context, ruleID int64) (*domain.RuleState, error) {
+	var state domain.RuleState
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT rule_id, value, pending_since, firing, updated_at FROM rule_states WHERE rule_id = $1
+	`, ruleID).Scan(&state.RuleID, &state.Value, &state.PendingSince, &state.Firing, &state.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get rule state: %w", err)
+	}
+	return &state, nil
+}
+
+func (r *RuleRepository) GetRuleStates(ctx context.Context) ([]domain.RuleState, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, `
+		SELECT rule_id, value, pending_since, firing, updated_at FROM rule_states ORDER BY rule_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rule states: %w", err)
+	}
+	defer rows.Close()
+
+	states := []domain.RuleState{}
+	for rows.Next() {
+		var state domain.RuleState
+		if err := rows.Scan(&state.RuleID, &state.Value, &state.PendingSince, &state.Firing, &state.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan rule state: %w", err)
+		}
+		states = append(states, state)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through rule states: %w", err)
+	}
+	return states, nil
+}
+
+func (r *RuleRepository) DeleteRuleState(ctx context.Context, ruleID int64) error {
+	if _, err := transaction.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM rule_states WHERE rule_id = $1`, ruleID); err != nil {
+		return fmt.Errorf("failed to delete rule state: %w", err)
+	}
+	return nil
+}
+
+func (r *RuleRepository) queryRules(ctx context.Context, query string, args ...any) ([]domain.Rule, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []domain.Rule{}
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rule: %w", err)
+		}
+		rules = append(rules, *rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through rules: %w", err)
+	}
+	return rules, nil
+}
+
+func scanRule(row pgx.Row) (*domain.Rule, error) {
+	var (
+		rule            domain.Rule
+		durationSeconds int64
+		windowFrom      *int
+		windowTo        *int
+		windowTimeZone  *string
+	)
+	err := row.Scan(&rule.ID, &rule.SensorID, &rule.Name, &rule.Operator, &rule.Threshold, &durationSeconds,
+		&windowFrom, &windowTo, &windowTimeZone, &rule.Enabled, &rule.CreatedBy, &rule.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Duration = time.Duration(durationSeconds) * time.Second
+	if windowFrom != nil && windowTo != nil {
+		rule.Window = &domain.RuleWindow{From: *windowFrom, To: *windowTo}
+		if windowTimeZone != nil {
+			rule.Window.TimeZone = *windowTimeZone
+		}
+	}
+	return &rule, nil
+}
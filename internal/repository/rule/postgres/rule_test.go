@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RuleTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	repo *RuleRepository
+}
+
+func (suite *RuleTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.repo = NewRuleRepository(suite.testDbInstance)
+}
+
+func (suite *RuleTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *RuleTestSuite) TestRuleRepository_Rules() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	rule := domain.Rule{
+		SensorID:  1,
+		Name:      "Ночью открыта дверь",
+		Operator:  domain.RuleOperatorEqual,
+		Threshold: 1,
+		Duration:  5 * time.Minute,
+		Window:    &domain.RuleWindow{From: 23 * 60, To: 6 * 60, TimeZone: "Europe/Moscow"},
+		Enabled:   true,
+		CreatedBy: 2,
+		CreatedAt: now,
+	}
+	assert.Nil(suite.T(), suite.repo.SaveRule(ctx, &rule))
+	assert.NotZero(suite.T(), rule.ID)
+
+	stored, err := suite.repo.GetRuleByID(ctx, rule.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), rule, *stored)
+
+	rule.Window = nil
+	rule.Threshold = 0
+	assert.Nil(suite.T(), suite.repo.SaveRule(ctx, &rule))
+
+	rules, err := suite.repo.GetRulesBySensorID(ctx, 1)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.Rule{rule}, rules)
+
+	assert.ErrorIs(suite.T(), suite.repo.SaveRule(ctx, &domain.Rule{ID: rule.ID + 100, CreatedAt: now}), usecase.ErrRuleNotFound)
+}
+
+func (suite *RuleTestSuite) TestRuleRepository_States() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	rule := domain.Rule{SensorID: 2, Name: "Жарко", Operator: domain.RuleOperatorGreater, Threshold: 800, CreatedAt: now}
+	assert.Nil(suite.T(), suite.repo.SaveRule(ctx, &rule))
+
+	state, err := suite.repo.GetRuleState(ctx, rule.ID)
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), state)
+
+	pending := domain.RuleState{RuleID: rule.ID, Value: 900, PendingSince: &now, UpdatedAt: now}
+	assert.Nil(suite.T(), suite.repo.SaveRuleState(ctx, pending))
+	firing := domain.RuleState{RuleID: rule.ID, Value: 950, PendingSince: &now, Firing: true, UpdatedAt: now}
+	assert.Nil(suite.T(), suite.repo.SaveRuleState(ctx, firing))
+
+	state, err = suite.repo.GetRuleState(ctx, rule.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), firing, *state)
+
+	assert.Nil(suite.T(), suite.repo.DeleteRule(ctx, rule.ID))
+	assert.ErrorIs(suite.T(), suite.repo.DeleteRule(ctx, rule.ID), usecase.ErrRuleNotFound)
+	assert.ErrorIs(suite.T(), suite.repo.SaveRuleState(ctx, firing), usecase.ErrRuleNotFound)
+
+	state, err = suite.repo.GetRuleState(ctx, rule.ID)
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), state)
+}
+
+func TestRuleTestSuite(t *testing.T) {
+	suite.Run(t, new(RuleTestSuite))
+}
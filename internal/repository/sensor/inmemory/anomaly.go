@@ -0,0 +1,59 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"sync"
+)
+
+type AnomalyStateRepository struct {
+	states map[int64]domain.AnomalyState
+	mu     sync.RWMutex
+}
+
+func NewAnomalyStateRepository() *AnomalyStateRepository {
+	return &AnomalyStateRepository{
+		states: make(map[int64]domain.AnomalyState),
+	}
+}
+
+func (r *AnomalyStateRepository) SaveAnomalyStates(ctx context.Context, states []domain.AnomalyState) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, state := range states {
+		r.states[state.SensorID] = state
+	}
+	return nil
+}
+
+func (r *AnomalyStateRepository) GetAnomalyState(ctx context.Context, sensorID int64) (*domain.AnomalyState, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state, ok := r.states[sensorID]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+func (r *AnomalyStateRepository) DeleteAnomalyState(ctx context.Context, sensorID int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.states, sensorID)
+	return nil
+}
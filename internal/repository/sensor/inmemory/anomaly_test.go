@@ -0,0 +1,53 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnomalyStateRepository(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		ar := NewAnomalyStateRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.ErrorIs(t, ar.SaveAnomalyStates(ctx, []domain.AnomalyState{{SensorID: 1}}), context.Canceled)
+		_, err := ar.GetAnomalyState(ctx, 1)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorIs(t, ar.DeleteAnomalyState(ctx, 1), context.Canceled)
+	})
+
+	t.Run("ok, save replaces and delete forgets", func(t *testing.T) {
+		ar := NewAnomalyStateRepository()
+		ctx := context.Background()
+
+		state, err := ar.GetAnomalyState(ctx, 1)
+		require.NoError(t, err)
+		assert.Nil(t, state)
+
+		now := time.Now()
+		first := domain.AnomalyState{SensorID: 1, Mean: 10, Variance: 1, Count: 5, UpdatedAt: now}
+		require.NoError(t, ar.SaveAnomalyStates(ctx, []domain.AnomalyState{first, {SensorID: 2, Count: 1}}))
+		updated := first
+		updated.Mean, updated.Count = 11, 6
+		require.NoError(t, ar.SaveAnomalyStates(ctx, []domain.AnomalyState{updated}))
+
+		state, err = ar.GetAnomalyState(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, &updated, state)
+
+		require.NoError(t, ar.DeleteAnomalyState(ctx, 1))
+		state, err = ar.GetAnomalyState(ctx, 1)
+		require.NoError(t, err)
+		assert.Nil(t, state)
+
+		state, err = ar.GetAnomalyState(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), state.Count)
+	})
+}
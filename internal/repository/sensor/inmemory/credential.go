@@ -0,0 +1,138 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"sync"
+	"time"
+)
+
+type SensorCredentialRepository struct {
+	credentials map[int64]domain.SensorCredential
+	mu          sync.RWMutex
+}
+
+func NewSensorCredentialRepository() *SensorCredentialRepository {
+	return &SensorCredentialRepository{
+		credentials: make(map[int64]domain.SensorCredential),
+	}
+}
+
+func (r *SensorCredentialRepository) CreateSensorCredential(ctx context.Context, credential *domain.SensorCredential) error {
+	if credential == nil {
+		return errors.New("credential is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.credentials[credential.SensorID]; exists {
+		return usecase.ErrSensorAlreadyProvisioned
+	}
+
+	r.credentials[credential.SensorID] = copyCredential(credential)
+	return nil
+}
+
+func (r *SensorCredentialRepository) SaveSensorCredential(ctx context.Context, credential *domain.SensorCredential) error {
+	if credential == nil {
+		return errors.New("credential is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.credentials[credential.SensorID] = copyCredential(credential)
+	return nil
+}
+
+func (r *SensorCredentialRepository) GetSensorCredential(ctx context.Context, sensorID int64) (*domain.SensorCredential, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	credential, ok := r.credentials[sensorID]
+	if !ok {
+		return nil, usecase.ErrCredentialNotFound
+	}
+
+	result := copyCredential(&credential)
+	return &result, nil
+}
+
+func copyCredential(credential *domain.SensorCredential) domain.SensorCredential {
+	result := *credential
+	if credential.PreviousExpiresAt != nil {
+		expiresAt := *credential.PreviousExpiresAt
+		result.PreviousExpiresAt = &expiresAt
+	}
+	return result
+}
+
+type nonceKey struct {
+	sensorID int64
+	nonce    string
+}
+
+type EventNonceRepository struct {
+	nonces map[nonceKey]time.Time
+	now    func() time.Time
+	mu     sync.Mutex
+}
+
+func NewEventNonceRepository() *EventNonceRepository {
+	return &EventNonceRepository{
+		nonces: make(map[nonceKey]time.Time),
+		now:    time.Now,
+	}
+}
+
+// SaveEventNonce запоминает nonce. Nonce с истекшим сроком, который еще не удален, запоминается заново.
+func (r *EventNonceRepository) SaveEventNonce(ctx context.Context, sensorID int64, nonce string, expiresAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := nonceKey{sensorID: sensorID, nonce: nonce}
+	if keyExpiresAt, exists := r.nonces[key]; exists && r.now().Before(keyExpiresAt) {
+		return usecase.ErrReplayedEvent
+	}
+
+	r.nonces[key] = expiresAt
+	return nil
+}
+
+// DeleteExpiredEventNonces забывает nonce, срок которых истек к now
+func (r *EventNonceRepository) DeleteExpiredEventNonces(ctx context.Context, now time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+	for key, expiresAt := range r.nonces {
+		if !now.Before(expiresAt) {
+			delete(r.nonces, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
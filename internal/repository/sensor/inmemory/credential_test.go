@@ -0,0 +1,76 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensorCredentialRepository(t *testing.T) {
+	t.Run("fail, not found", func(t *testing.T) {
+		cr := NewSensorCredentialRepository()
+		_, err := cr.GetSensorCredential(context.Background(), 1)
+		assert.ErrorIs(t, err, usecase.ErrCredentialNotFound)
+	})
+
+	t.Run("ok, create once then save", func(t *testing.T) {
+		cr := NewSensorCredentialRepository()
+		ctx := context.Background()
+
+		require.NoError(t, cr.CreateSensorCredential(ctx, &domain.SensorCredential{SensorID: 1, Secret: "a"}))
+		err := cr.CreateSensorCredential(ctx, &domain.SensorCredential{SensorID: 1, Secret: "b"})
+		assert.ErrorIs(t, err, usecase.ErrSensorAlreadyProvisioned)
+
+		require.NoError(t, cr.SaveSensorCredential(ctx, &domain.SensorCredential{SensorID: 1, Secret: "c", PreviousSecret: "a"}))
+
+		credential, err := cr.GetSensorCredential(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "c", credential.Secret)
+		assert.Equal(t, "a", credential.PreviousSecret)
+	})
+}
+
+func TestEventNonceRepository(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		nr := NewEventNonceRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := nr.SaveEventNonce(ctx, 1, "n", time.Now().Add(time.Minute))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, replay rejected until expiry", func(t *testing.T) {
+		nr := NewEventNonceRepository()
+		ctx := context.Background()
+		now := time.Now()
+		nr.now = func() time.Time { return now }
+
+		require.NoError(t, nr.SaveEventNonce(ctx, 1, "n", now.Add(time.Minute)))
+		assert.ErrorIs(t, nr.SaveEventNonce(ctx, 1, "n", now.Add(time.Minute)), usecase.ErrReplayedEvent)
+		assert.NoError(t, nr.SaveEventNonce(ctx, 2, "n", now.Add(time.Minute)))
+
+		now = now.Add(2 * time.Minute)
+		assert.NoError(t, nr.SaveEventNonce(ctx, 1, "n", now.Add(time.Minute)))
+	})
+
+	t.Run("ok, delete expired", func(t *testing.T) {
+		nr := NewEventNonceRepository()
+		ctx := context.Background()
+		now := time.Now()
+
+		require.NoError(t, nr.SaveEventNonce(ctx, 1, "old", now.Add(-time.Second)))
+		require.NoError(t, nr.SaveEventNonce(ctx, 1, "new", now.Add(time.Minute)))
+
+		deleted, err := nr.DeleteExpiredEventNonces(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, deleted)
+		assert.Len(t, nr.nonces, 1)
+		assert.ErrorIs(t, nr.SaveEventNonce(ctx, 1, "new", now.Add(time.Minute)), usecase.ErrReplayedEvent)
+	})
+}
@@ -0,0 +1,48 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"sync"
+)
+
+type FirmwareHistoryRepository struct {
+	changes map[int64][]domain.FirmwareChange
+	mu      sync.RWMutex
+}
+
+func NewFirmwareHistoryRepository() *FirmwareHistoryRepository {
+	return &FirmwareHistoryRepository{
+		changes: make(map[int64][]domain.FirmwareChange),
+	}
+}
+
+func (r *FirmwareHistoryRepository) SaveFirmwareChange(ctx context.Context, change *domain.FirmwareChange) error {
+	if change == nil {
+		return errors.New("firmware change is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.changes[change.SensorID] = append(r.changes[change.SensorID], *change)
+	return nil
+}
+
+func (r *FirmwareHistoryRepository) GetFirmwareChangesBySensorID(ctx context.Context, sensorID int64) ([]domain.FirmwareChange, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]domain.FirmwareChange, len(r.changes[sensorID]))
+	copy(result, r.changes[sensorID])
+	return result, nil
+}
@@ -0,0 +1,51 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirmwareHistoryRepository(t *testing.T) {
+	t.Run("err, change is nil", func(t *testing.T) {
+		fr := NewFirmwareHistoryRepository()
+		err := fr.SaveFirmwareChange(context.Background(), nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		fr := NewFirmwareHistoryRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := fr.SaveFirmwareChange(ctx, &domain.FirmwareChange{SensorID: 1})
+		assert.ErrorIs(t, err, context.Canceled)
+
+		_, err = fr.GetFirmwareChangesBySensorID(ctx, 1)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, changes in order of saving", func(t *testing.T) {
+		fr := NewFirmwareHistoryRepository()
+		ctx := context.Background()
+
+		now := time.Now()
+		first := domain.FirmwareChange{SensorID: 1, Version: "1.0.0", ChangedAt: now}
+		second := domain.FirmwareChange{SensorID: 1, PreviousVersion: "1.0.0", Version: "1.1.0", ChangedAt: now.Add(time.Minute)}
+		require.NoError(t, fr.SaveFirmwareChange(ctx, &first))
+		require.NoError(t, fr.SaveFirmwareChange(ctx, &second))
+		require.NoError(t, fr.SaveFirmwareChange(ctx, &domain.FirmwareChange{SensorID: 2, Version: "2.0.0", ChangedAt: now}))
+
+		changes, err := fr.GetFirmwareChangesBySensorID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.FirmwareChange{first, second}, changes)
+
+		changes, err = fr.GetFirmwareChangesBySensorID(ctx, 3)
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+}
@@ -0,0 +1,124 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"sort"
+	"sync"
+	"time"
+)
+
+type SensorInvitationRepository struct {
+	invitations map[int64]*domain.SensorInvitation
+	mu          sync.RWMutex
+	lastID      int64
+}
+
+func NewSensorInvitationRepository() *SensorInvitationRepository {
+	return &SensorInvitationRepository{
+		invitations: make(map[int64]*domain.SensorInvitation),
+	}
+}
+
+func (r *SensorInvitationRepository) SaveSensorInvitation(ctx context.Context, invitation *domain.SensorInvitation) error {
+	if invitation == nil {
+		return errors.New("invitation is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	invitation.ID = r.lastID
+	r.invitations[invitation.ID] = copyInvitation(invitation)
+
+	return nil
+}
+
+func (r *SensorInvitationRepository) GetSensorInvitationByHash(ctx context.Context, hash string) (*domain.SensorInvitation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, invitation := range r.invitations {
+		if invitation.Hash == hash {
+			return copyInvitation(invitation), nil
+		}
+	}
+
+	return nil, usecase.ErrInvitationNotFound
+}
+
+func (r *SensorInvitationRepository) GetSensorInvitationsBySensorID(ctx context.Context, sensorID int64) ([]domain.SensorInvitation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]domain.SensorInvitation, 0)
+	for _, invitation := range r.invitations {
+		if invitation.SensorID == sensorID {
+			result = append(result, *copyInvitation(invitation))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	return result, nil
+}
+
+func (r *SensorInvitationRepository) UseSensorInvitation(ctx context.Context, id int64, now time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invitation, ok := r.invitations[id]
+	if !ok {
+		return usecase.ErrInvitationNotFound
+	}
+	if !invitation.IsPending(now) {
+		return usecase.ErrInvitationExpired
+	}
+	invitation.Uses++
+
+	return nil
+}
+
+func (r *SensorInvitationRepository) RevokeSensorInvitation(ctx context.Context, id int64, revokedAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invitation, ok := r.invitations[id]
+	if !ok {
+		return usecase.ErrInvitationNotFound
+	}
+	invitation.RevokedAt = &revokedAt
+
+	return nil
+}
+
+func copyInvitation(invitation *domain.SensorInvitation) *domain.SensorInvitation {
+	result := *invitation
+	if invitation.RevokedAt != nil {
+		revokedAt := *invitation.RevokedAt
+		result.RevokedAt = &revokedAt
+	}
+	return &result
+}
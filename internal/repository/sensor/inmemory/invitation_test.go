@@ -0,0 +1,63 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensorInvitationRepository(t *testing.T) {
+	t.Run("fail, not found", func(t *testing.T) {
+		ir := NewSensorInvitationRepository()
+		ctx := context.Background()
+
+		_, err := ir.GetSensorInvitationByHash(ctx, "h")
+		assert.ErrorIs(t, err, usecase.ErrInvitationNotFound)
+		assert.ErrorIs(t, ir.UseSensorInvitation(ctx, 1, time.Now()), usecase.ErrInvitationNotFound)
+		assert.ErrorIs(t, ir.RevokeSensorInvitation(ctx, 1, time.Now()), usecase.ErrInvitationNotFound)
+	})
+
+	t.Run("ok, uses limited", func(t *testing.T) {
+		ir := NewSensorInvitationRepository()
+		ctx := context.Background()
+		now := time.Now()
+
+		invitation := &domain.SensorInvitation{SensorID: 1, Hash: "h", ExpiresAt: now.Add(time.Hour), MaxUses: 2}
+		require.NoError(t, ir.SaveSensorInvitation(ctx, invitation))
+
+		require.NoError(t, ir.UseSensorInvitation(ctx, invitation.ID, now))
+		require.NoError(t, ir.UseSensorInvitation(ctx, invitation.ID, now))
+		assert.ErrorIs(t, ir.UseSensorInvitation(ctx, invitation.ID, now), usecase.ErrInvitationExpired)
+
+		stored, err := ir.GetSensorInvitationByHash(ctx, "h")
+		require.NoError(t, err)
+		assert.Equal(t, 2, stored.Uses)
+	})
+
+	t.Run("ok, revoked and expired can't be used", func(t *testing.T) {
+		ir := NewSensorInvitationRepository()
+		ctx := context.Background()
+		now := time.Now()
+
+		revoked := &domain.SensorInvitation{SensorID: 1, Hash: "a", ExpiresAt: now.Add(time.Hour), MaxUses: 1}
+		expired := &domain.SensorInvitation{SensorID: 1, Hash: "b", ExpiresAt: now, MaxUses: 1}
+		require.NoError(t, ir.SaveSensorInvitation(ctx, revoked))
+		require.NoError(t, ir.SaveSensorInvitation(ctx, expired))
+		require.NoError(t, ir.SaveSensorInvitation(ctx, &domain.SensorInvitation{SensorID: 2, Hash: "c"}))
+
+		require.NoError(t, ir.RevokeSensorInvitation(ctx, revoked.ID, now))
+		assert.ErrorIs(t, ir.UseSensorInvitation(ctx, revoked.ID, now), usecase.ErrInvitationExpired)
+		assert.ErrorIs(t, ir.UseSensorInvitation(ctx, expired.ID, now), usecase.ErrInvitationExpired)
+
+		invitations, err := ir.GetSensorInvitationsBySensorID(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, invitations, 2)
+		assert.Equal(t, revoked.ID, invitations[0].ID)
+		assert.NotNil(t, invitations[0].RevokedAt)
+	})
+}
@@ -55,6 +55,64 @@ func (r *SensorRepository) SaveSensor(ctx context.Context, sensor *domain.Sensor
 	return nil
 }
 
+// CreateSensor сохраняет новый датчик. Если серийный номер уже занят, возвращает ErrSensorAlreadyExists.
+func (r *SensorRepository) CreateSensor(ctx context.Context, sensor *domain.Sensor) error {
+	if sensor == nil {
+		return errors.New("sensor is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sensorsBySN[sensor.SerialNumber]; ok {
+		return usecase.ErrSensorAlreadyExists
+	}
+
+	r.lastID++
+	sensor.ID = r.lastID
+	if sensor.RegisteredAt.IsZero() {
+		sensor.RegisteredAt = time.Now()
+	}
+
+	r.sensors[sensor.ID] = sensor
+	r.sensorsBySN[sensor.SerialNumber] = sensor
+
+	return nil
+}
+
+// UpdateSensorState сохраняет только состояние датчика, которое меняют события. Если датчика нет, возвращает ErrSensorNotFound.
+func (r *SensorRepository) UpdateSensorState(ctx context.Context, sensor *domain.Sensor) error {
+	if sensor == nil {
+		return errors.New("sensor is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.sensors[sensor.ID]
+	if !ok {
+		return usecase.ErrSensorNotFound
+	}
+
+	stored.CurrentState = sensor.CurrentState
+	stored.LastActivity = sensor.LastActivity
+	stored.Flapping = sensor.Flapping
+	stored.Manufacturer = sensor.Manufacturer
+	stored.Model = sensor.Model
+	stored.HardwareRevision = sensor.HardwareRevision
+	stored.FirmwareVersion = sensor.FirmwareVersion
+
+	return nil
+}
+
 func (r *SensorRepository) GetSensors(ctx context.Context) ([]domain.Sensor, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
@@ -104,6 +104,60 @@ func TestSensorRepository_SaveSensor(t *testing.T) {
 	})
 }
 
+func TestSensorRepository_CreateSensor(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		sr := NewSensorRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := sr.CreateSensor(ctx, &domain.Sensor{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("fail, serial number taken", func(t *testing.T) {
+		sr := NewSensorRepository()
+		ctx := context.Background()
+
+		sensor := &domain.Sensor{SerialNumber: "0000000001", Description: "first"}
+		assert.NoError(t, sr.CreateSensor(ctx, sensor))
+		assert.Equal(t, int64(1), sensor.ID)
+
+		err := sr.CreateSensor(ctx, &domain.Sensor{SerialNumber: "0000000001", Description: "second"})
+		assert.ErrorIs(t, err, usecase.ErrSensorAlreadyExists)
+
+		stored, err := sr.GetSensorBySerialNumber(ctx, "0000000001")
+		assert.NoError(t, err)
+		assert.Equal(t, "first", stored.Description)
+	})
+}
+
+func TestSensorRepository_UpdateSensorState(t *testing.T) {
+	t.Run("fail, sensor not found", func(t *testing.T) {
+		sr := NewSensorRepository()
+
+		err := sr.UpdateSensorState(context.Background(), &domain.Sensor{ID: 1})
+		assert.ErrorIs(t, err, usecase.ErrSensorNotFound)
+	})
+
+	t.Run("ok, settings are kept", func(t *testing.T) {
+		sr := NewSensorRepository()
+		ctx := context.Background()
+
+		sensor := &domain.Sensor{SerialNumber: "0000000001", Description: "first"}
+		assert.NoError(t, sr.SaveSensor(ctx, sensor))
+
+		stale := domain.Sensor{ID: sensor.ID, Description: "stale", CurrentState: 5, Model: "m1", Flapping: true}
+		assert.NoError(t, sr.UpdateSensorState(ctx, &stale))
+
+		stored, err := sr.GetSensorByID(ctx, sensor.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "first", stored.Description)
+		assert.Equal(t, int64(5), stored.CurrentState)
+		assert.Equal(t, "m1", stored.Model)
+		assert.True(t, stored.Flapping)
+	})
+}
+
 func TestSensorRepository_GetSensors(t *testing.T) {
 	t.Run("fail, ctx cancelled", func(t *testing.T) {
 		sr := NewSensorRepository()
@@ -0,0 +1,53 @@
+package inmemory
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+type VirtualSensorRepository struct {
+	// inputs - входы вычисляемых датчиков по id датчика
+	inputs map[int64][]int64
+	mu     sync.RWMutex
+}
+
+func NewVirtualSensorRepository() *VirtualSensorRepository {
+	return &VirtualSensorRepository{
+		inputs: make(map[int64][]int64),
+	}
+}
+
+func (r *VirtualSensorRepository) SaveVirtualSensorInputs(ctx context.Context, sensorID int64, inputIDs []int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(inputIDs) == 0 {
+		delete(r.inputs, sensorID)
+		return nil
+	}
+	r.inputs[sensorID] = slices.Clone(inputIDs)
+	return nil
+}
+
+func (r *VirtualSensorRepository) GetVirtualSensorIDsByInputID(ctx context.Context, inputID int64) ([]int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]int64, 0)
+	for sensorID, inputs := range r.inputs {
+		if slices.Contains(inputs, inputID) {
+			ids = append(ids, sensorID)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
@@ -0,0 +1,43 @@
+package inmemory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVirtualSensorRepository(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		vr := NewVirtualSensorRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.ErrorIs(t, vr.SaveVirtualSensorInputs(ctx, 3, []int64{1, 2}), context.Canceled)
+		_, err := vr.GetVirtualSensorIDsByInputID(ctx, 1)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, inputs are replaced", func(t *testing.T) {
+		vr := NewVirtualSensorRepository()
+		ctx := context.Background()
+
+		require.NoError(t, vr.SaveVirtualSensorInputs(ctx, 4, []int64{1, 2}))
+		require.NoError(t, vr.SaveVirtualSensorInputs(ctx, 3, []int64{1}))
+
+		ids, err := vr.GetVirtualSensorIDsByInputID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{3, 4}, ids)
+
+		require.NoError(t, vr.SaveVirtualSensorInputs(ctx, 4, []int64{2}))
+		ids, err = vr.GetVirtualSensorIDsByInputID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{3}, ids)
+
+		require.NoError(t, vr.SaveVirtualSensorInputs(ctx, 3, nil))
+		ids, err = vr.GetVirtualSensorIDsByInputID(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AnomalyStateRepository хранит статистику обнаружения аномалий в таблице anomaly_states
+type AnomalyStateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAnomalyStateRepository(pool *pgxpool.Pool) *AnomalyStateRepository {
+	return &AnomalyStateRepository{
+		pool: pool,
+	}
+}
+
+// SaveAnomalyStates сохраняет статистику одним пакетом запросов вне транзакции.
+// Статистика удаленного датчика пропускается.
+func (r *AnomalyStateRepository) SaveAnomalyStates(ctx context.Context, states []domain.AnomalyState) error {
+	query := `
+		INSERT INTO anomaly_states (sensor_id, mean, variance, count, updated_at)
+		SELECT id, $2, $3, $4, $5 FROM sensors WHERE id = $1
+		ON CONFLICT (sensor_id) DO UPDATE SET
+			mean = EXCLUDED.mean,
+			variance = EXCLUDED.variance,
+			count = EXCLUDED.count,
+			updated_at = EXCLUDED.updated_at
+	`
+	batch := &pgx.Batch{}
+	for _, state := range states {
+		batch.Queue(query, state.SensorID, state.Mean, state.Variance, state.Count, state.UpdatedAt.UTC())
+	}
+
+	if err := r.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to save anomaly states: %w", err)
+	}
+	return nil
+}
+
+func (r *AnomalyStateRepository) GetAnomalyState(ctx context.Context, sensorID int64) (*domain.AnomalyState, error) {
+	query := `
+		SELECT sensor_id, mean, variance, count, updated_at
+		FROM anomaly_states
+		WHERE sensor_id = $1
+	`
+	var state domain.AnomalyState
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, query, sensorID).
+		Scan(&state.SensorID, &state.Mean, &state.Variance, &state.Count, &state.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get anomaly state: %w", err)
+	}
+	return &state, nil
+}
+
+func (r *AnomalyStateRepository) DeleteAnomalyState(ctx context.Context, sensorID int64) error {
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM anomaly_states WHERE sensor_id = $1`, sensorID)
+	if err != nil {
+		return fmt.Errorf("failed to delete anomaly state: %w", err)
+	}
+	return nil
+}
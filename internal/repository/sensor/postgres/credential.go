@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SensorCredentialRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSensorCredentialRepository(pool *pgxpool.Pool) *SensorCredentialRepository {
+	return &SensorCredentialRepository{
+		pool: pool,
+	}
+}
+
+func (r *SensorCredentialRepository) CreateSensorCredential(ctx context.Context, credential *domain.SensorCredential) error {
+	if credential == nil {
+		return errors.New("credential is nil")
+	}
+
+	query := `
+		INSERT INTO sensor_credentials (sensor_id, secret, previous_secret, previous_expires_at, rotated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (sensor_id) DO NOTHING
+	`
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, query, credential.SensorID, credential.Secret,
+		credential.PreviousSecret, credential.PreviousExpiresAt, credential.RotatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create sensor credential: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrSensorAlreadyProvisioned
+	}
+	return nil
+}
+
+func (r *SensorCredentialRepository) SaveSensorCredential(ctx context.Context, credential *domain.SensorCredential) error {
+	if credential == nil {
+		return errors.New("credential is nil")
+	}
+
+	query := `
+		INSERT INTO sensor_credentials (sensor_id, secret, previous_secret, previous_expires_at, rotated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (sensor_id) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			previous_secret = EXCLUDED.previous_secret,
+			previous_expires_at = EXCLUDED.previous_expires_at,
+			rotated_at = EXCLUDED.rotated_at
+	`
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, query, credential.SensorID, credential.Secret,
+		credential.PreviousSecret, credential.PreviousExpiresAt, credential.RotatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save sensor credential: %w", err)
+	}
+	return nil
+}
+
+func (r *SensorCredentialRepository) GetSensorCredential(ctx context.Context, sensorID int64) (*domain.SensorCredential, error) {
+	query := `
+		SELECT sensor_id, secret, previous_secret, previous_expires_at, rotated_at
+		FROM sensor_credentials
+		WHERE sensor_id = $1
+	`
+	var c domain.SensorCredential
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, query, sensorID).
+		Scan(&c.SensorID, &c.Secret, &c.PreviousSecret, &c.PreviousExpiresAt, &c.RotatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrCredentialNotFound
+		}
+		return nil, fmt.Errorf("failed to get sensor credential: %w", err)
+	}
+	return &c, nil
+}
+
+type EventNonceRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewEventNonceRepository(pool *pgxpool.Pool) *EventNonceRepository {
+	return &EventNonceRepository{
+		pool: pool,
+	}
+}
+
+// SaveEventNonce запоминает nonce. Nonce с истекшим сроком, который еще не удален, запоминается заново.
+func (r *EventNonceRepository) SaveEventNonce(ctx context.Context, sensorID int64, nonce string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO event_nonces (sensor_id, nonce, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (sensor_id, nonce) DO UPDATE SET expires_at = EXCLUDED.expires_at
+		WHERE event_nonces.expires_at <= $4
+	`
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, query, sensorID, nonce, expiresAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save event nonce: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrReplayedEvent
+	}
+	return nil
+}
+
+// DeleteExpiredEventNonces удаляет nonce, срок которых истек к now
+func (r *EventNonceRepository) DeleteExpiredEventNonces(ctx context.Context, now time.Time) (int, error) {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM event_nonces WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired event nonces: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type FirmwareHistoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewFirmwareHistoryRepository(pool *pgxpool.Pool) *FirmwareHistoryRepository {
+	return &FirmwareHistoryRepository{
+		pool: pool,
+	}
+}
+
+func (r *FirmwareHistoryRepository) SaveFirmwareChange(ctx context.Context, change *domain.FirmwareChange) error {
+	if change == nil {
+		return errors.New("firmware change is nil")
+	}
+
+	query := `
+		INSERT INTO firmware_changes (sensor_id, previous_version, version, changed_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, query,
+		change.SensorID, change.PreviousVersion, change.Version, change.ChangedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save firmware change: %w", err)
+	}
+	return nil
+}
+
+func (r *FirmwareHistoryRepository) GetFirmwareChangesBySensorID(ctx context.Context, sensorID int64) ([]domain.FirmwareChange, error) {
+	query := `
+		SELECT sensor_id, previous_version, version, changed_at
+		FROM firmware_changes
+		WHERE sensor_id = $1
+		ORDER BY changed_at, id
+	`
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, sensorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query firmware changes: %w", err)
+	}
+	defer rows.Close()
+
+	result := []domain.FirmwareChange{}
+	for rows.Next() {
+		var change domain.FirmwareChange
+		if err := rows.Scan(&change.SensorID, &change.PreviousVersion, &change.Version, &change.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan firmware change: %w", err)
+		}
+		result = append(result, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through firmware changes: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SensorInvitationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSensorInvitationRepository(pool *pgxpool.Pool) *SensorInvitationRepository {
+	return &SensorInvitationRepository{
+		pool: pool,
+	}
+}
+
+const invitationColumns = `id, sensor_id, role, hash, created_by, created_at, expires_at, max_uses, uses, revoked_at`
+
+func scanInvitation(row pgx.Row) (*domain.SensorInvitation, error) {
+	var i domain.SensorInvitation
+	if err := row.Scan(&i.ID, &i.SensorID, &i.Role, &i.Hash, &i.CreatedBy, &i.CreatedAt, &i.ExpiresAt,
+		&i.MaxUses, &i.Uses, &i.RevokedAt); err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r *SensorInvitationRepository) SaveSensorInvitation(ctx context.Context, invitation *domain.SensorInvitation) error {
+	if invitation == nil {
+		return errors.New("invitation is nil")
+	}
+
+	query := `
+		INSERT INTO sensor_invitations (sensor_id, role, hash, created_by, created_at, expires_at, max_uses, uses)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, query, invitation.SensorID, invitation.Role, invitation.Hash,
+		invitation.CreatedBy, invitation.CreatedAt, invitation.ExpiresAt, invitation.MaxUses, invitation.Uses).
+		Scan(&invitation.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save sensor invitation: %w", err)
+	}
+	return nil
+}
+
+func (r *SensorInvitationRepository) GetSensorInvitationByHash(ctx context.Context, hash string) (*domain.SensorInvitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM sensor_invitations WHERE hash = $1`
+	invitation, err := scanInvitation(transaction.Conn(ctx, r.pool).QueryRow(ctx, query, hash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrInvitationNotFound
+		}
+		return nil, fmt.Errorf("failed to get sensor invitation: %w", err)
+	}
+	return invitation, nil
+}
+
+func (r *SensorInvitationRepository) GetSensorInvitationsBySensorID(ctx context.Context, sensorID int64) ([]domain.SensorInvitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM sensor_invitations WHERE sensor_id = $1 ORDER BY id`
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, sensorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sensor invitations: %w", err)
+	}
+	defer rows.Close()
+
+	result := []domain.SensorInvitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sensor invitation: %w", err)
+		}
+		result = append(result, *invitation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through sensor invitations: %w", err)
+	}
+	return result, nil
+}
+
+// UseSensorInvitation увеличивает счетчик одним запросом, поэтому два одновременных принятия
+// последнего использования не пройдут оба
+func (r *SensorInvitationRepository) UseSensorInvitation(ctx context.Context, id int64, now time.Time) error {
+	conn := transaction.Conn(ctx, r.pool)
+	query := `
+		UPDATE sensor_invitations SET uses = uses + 1
+		WHERE id = $1 AND revoked_at IS NULL AND expires_at > $2 AND uses < max_uses
+	`
+	tag, err := conn.Exec(ctx, query, id, now)
+	if err != nil {
+		return fmt.Errorf("failed to use sensor invitation: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := conn.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM sensor_invitations WHERE id = $1)`, id).
+		Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check sensor invitation: %w", err)
+	}
+	if !exists {
+		return usecase.ErrInvitationNotFound
+	}
+	return usecase.ErrInvitationExpired
+}
+
+func (r *SensorInvitationRepository) RevokeSensorInvitation(ctx context.Context, id int64, revokedAt time.Time) error {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx,
+		`UPDATE sensor_invitations SET revoked_at = $2 WHERE id = $1`, id, revokedAt)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sensor invitation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrInvitationNotFound
+	}
+	return nil
+}
@@ -2,9 +2,11 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
 	"homework/internal/usecase"
 	"time"
 
@@ -22,7 +24,275 @@ func NewSensorRepository(pool *pgxpool.Pool) *SensorRepository {
 	}
 }
 
+// sensorColumns - список колонок, который читает scanSensor
+const sensorColumns = `id, serial_number, type, current_state, description, is_active, registered_at, last_activity, calibration,
+	anomaly_detection, debounce, flapping, virtual, manufacturer, model, hardware_revision, firmware_version, labels`
+
+// calibrationRecord - представление калибровки в колонке calibration
+type calibrationRecord struct {
+	Offset     float64                  `json:"offset"`
+	Scale      float64                  `json:"scale"`
+	Polynomial []float64                `json:"polynomial,omitempty"`
+	Table      []calibrationPointRecord `json:"table,omitempty"`
+	Unit       string                   `json:"unit"`
+}
+
+type calibrationPointRecord struct {
+	Raw   int64   `json:"raw"`
+	Value float64 `json:"value"`
+}
+
+func calibrationToRecord(c *domain.Calibration) ([]byte, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	record := calibrationRecord{
+		Offset:     c.Offset,
+		Scale:      c.Scale,
+		Polynomial: c.Polynomial,
+		Unit:       c.Unit,
+	}
+	for _, p := range c.Table {
+		record.Table = append(record.Table, calibrationPointRecord{Raw: p.Raw, Value: p.Value})
+	}
+
+	return json.Marshal(record)
+}
+
+func calibrationFromRecord(data []byte) (*domain.Calibration, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	var record calibrationRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+
+	c := &domain.Calibration{
+		Offset:     record.Offset,
+		Scale:      record.Scale,
+		Polynomial: record.Polynomial,
+		Unit:       record.Unit,
+	}
+	for _, p := range record.Table {
+		c.Table = append(c.Table, domain.CalibrationPoint{Raw: p.Raw, Value: p.Value})
+	}
+	return c, nil
+}
+
+// anomalyDetectionRecord - представление настроек обнаружения аномалий в колонке anomaly_detection
+type anomalyDetectionRecord struct {
+	Alpha  float64 `json:"alpha"`
+	Sigma  float64 `json:"sigma"`
+	WarmUp int     `json:"warm_up"`
+}
+
+func anomalyDetectionToRecord(d *domain.AnomalyDetection) ([]byte, error) {
+	if d == nil {
+		return nil, nil
+	}
+	return json.Marshal(anomalyDetectionRecord{Alpha: d.Alpha, Sigma: d.Sigma, WarmUp: d.WarmUp})
+}
+
+func anomalyDetectionFromRecord(data []byte) (*domain.AnomalyDetection, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	var record anomalyDetectionRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &domain.AnomalyDetection{Alpha: record.Alpha, Sigma: record.Sigma, WarmUp: record.WarmUp}, nil
+}
+
+// debounceRecord - представление настроек подавления дребезга в колонке debounce
+type debounceRecord struct {
+	WindowMs      int64 `json:"window_ms"`
+	FlapThreshold int   `json:"flap_threshold"`
+	FlapWindowMs  int64 `json:"flap_window_ms"`
+}
+
+func debounceToRecord(d *domain.Debounce) ([]byte, error) {
+	if d == nil {
+		return nil, nil
+	}
+	return json.Marshal(debounceRecord{
+		WindowMs:      d.Window.Milliseconds(),
+		FlapThreshold: d.FlapThreshold,
+		FlapWindowMs:  d.FlapWindow.Milliseconds(),
+	})
+}
+
+func debounceFromRecord(data []byte) (*domain.Debounce, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	var record debounceRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &domain.Debounce{
+		Window:        time.Duration(record.WindowMs) * time.Millisecond,
+		FlapThreshold: record.FlapThreshold,
+		FlapWindow:    time.Duration(record.FlapWindowMs) * time.Millisecond,
+	}, nil
+}
+
+// virtualRecord - представление вычисляемого датчика в колонке virtual
+type virtualRecord struct {
+	Expression string  `json:"expression"`
+	Resolution float64 `json:"resolution"`
+	Unit       string  `json:"unit"`
+}
+
+func virtualToRecord(v *domain.VirtualSensor) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(virtualRecord{Expression: v.Expression, Resolution: v.Resolution, Unit: v.Unit})
+}
+
+func virtualFromRecord(data []byte) (*domain.VirtualSensor, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	var record virtualRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &domain.VirtualSensor{Expression: record.Expression, Resolution: record.Resolution, Unit: record.Unit}, nil
+}
+
+func scanSensor(row pgx.Row) (*domain.Sensor, error) {
+	var s domain.Sensor
+	var calibration, anomalyDetection, debounce, virtual []byte
+	if err := row.Scan(
+		&s.ID,
+		&s.SerialNumber,
+		&s.Type,
+		&s.CurrentState,
+		&s.Description,
+		&s.IsActive,
+		&s.RegisteredAt,
+		&s.LastActivity,
+		&calibration,
+		&anomalyDetection,
+		&debounce,
+		&s.Flapping,
+		&virtual,
+		&s.Manufacturer,
+		&s.Model,
+		&s.HardwareRevision,
+		&s.FirmwareVersion,
+		&s.Labels,
+	); err != nil {
+		return nil, err
+	}
+	if len(s.Labels) == 0 {
+		s.Labels = nil
+	}
+
+	c, err := calibrationFromRecord(calibration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode calibration: %w", err)
+	}
+	s.Calibration = c
+
+	d, err := anomalyDetectionFromRecord(anomalyDetection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode anomaly detection: %w", err)
+	}
+	s.AnomalyDetection = d
+
+	s.Debounce, err = debounceFromRecord(debounce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode debounce: %w", err)
+	}
+
+	s.Virtual, err = virtualFromRecord(virtual)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode virtual sensor: %w", err)
+	}
+
+	return &s, nil
+}
+
 func (r *SensorRepository) SaveSensor(ctx context.Context, sensor *domain.Sensor) error {
+	return r.insertSensor(ctx, sensor, `
+		ON CONFLICT (serial_number) DO UPDATE SET 
+			type = EXCLUDED.type,
+			current_state = EXCLUDED.current_state,
+			description = EXCLUDED.description,
+			is_active = EXCLUDED.is_active,
+			last_activity = EXCLUDED.last_activity,
+			calibration = EXCLUDED.calibration,
+			anomaly_detection = EXCLUDED.anomaly_detection,
+			debounce = EXCLUDED.debounce,
+			flapping = EXCLUDED.flapping,
+			virtual = EXCLUDED.virtual,
+			manufacturer = EXCLUDED.manufacturer,
+			model = EXCLUDED.model,
+			hardware_revision = EXCLUDED.hardware_revision,
+			firmware_version = EXCLUDED.firmware_version,
+			labels = EXCLUDED.labels`)
+}
+
+// CreateSensor сохраняет новый датчик. Если серийный номер уже занят, датчик не меняется
+// и возвращается ErrSensorAlreadyExists.
+func (r *SensorRepository) CreateSensor(ctx context.Context, sensor *domain.Sensor) error {
+	err := r.insertSensor(ctx, sensor, `ON CONFLICT (serial_number) DO NOTHING`)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return usecase.ErrSensorAlreadyExists
+	}
+	return err
+}
+
+// UpdateSensorState сохраняет только состояние датчика, которое меняют события,
+// чтобы не затереть настройки, измененные после чтения датчика
+func (r *SensorRepository) UpdateSensorState(ctx context.Context, sensor *domain.Sensor) error {
+	if sensor == nil {
+		return errors.New("sensor is nil")
+	}
+
+	query := `
+		UPDATE sensors SET
+			current_state = $2,
+			last_activity = $3,
+			flapping = $4,
+			manufacturer = $5,
+			model = $6,
+			hardware_revision = $7,
+			firmware_version = $8
+		WHERE id = $1
+	`
+	tag, err := transaction.Conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		sensor.ID,
+		sensor.CurrentState,
+		sensor.LastActivity,
+		sensor.Flapping,
+		sensor.Manufacturer,
+		sensor.Model,
+		sensor.HardwareRevision,
+		sensor.FirmwareVersion,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update sensor state: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrSensorNotFound
+	}
+	return nil
+}
+
+// insertSensor добавляет датчик, onConflict определяет, что делать, если серийный номер занят
+func (r *SensorRepository) insertSensor(ctx context.Context, sensor *domain.Sensor, onConflict string) error {
 	if sensor == nil {
 		return errors.New("sensor is nil")
 	}
@@ -33,23 +303,35 @@ func (r *SensorRepository) SaveSensor(ctx context.Context, sensor *domain.Sensor
 
 	sensor.LastActivity = time.Now()
 
+	calibration, err := calibrationToRecord(sensor.Calibration)
+	if err != nil {
+		return fmt.Errorf("failed to encode calibration: %w", err)
+	}
+	anomalyDetection, err := anomalyDetectionToRecord(sensor.AnomalyDetection)
+	if err != nil {
+		return fmt.Errorf("failed to encode anomaly detection: %w", err)
+	}
+	debounce, err := debounceToRecord(sensor.Debounce)
+	if err != nil {
+		return fmt.Errorf("failed to encode debounce: %w", err)
+	}
+	virtual, err := virtualToRecord(sensor.Virtual)
+	if err != nil {
+		return fmt.Errorf("failed to encode virtual sensor: %w", err)
+	}
+
 	query := `
 		INSERT INTO sensors (
 			serial_number, type, current_state, description, 
-			is_active, registered_at, last_activity
+			is_active, registered_at, last_activity, calibration, anomaly_detection,
+			debounce, flapping, virtual, manufacturer, model, hardware_revision, firmware_version, labels
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7
-		)
-		ON CONFLICT (serial_number) DO UPDATE SET 
-			type = EXCLUDED.type,
-			current_state = EXCLUDED.current_state,
-			description = EXCLUDED.description,
-			is_active = EXCLUDED.is_active,
-			last_activity = EXCLUDED.last_activity
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
+		)` + onConflict + `
 		RETURNING id, registered_at, last_activity
 	`
 
-	err := r.pool.QueryRow(
+	err = transaction.Conn(ctx, r.pool).QueryRow(
 		ctx,
 		query,
 		sensor.SerialNumber,
@@ -59,20 +341,27 @@ func (r *SensorRepository) SaveSensor(ctx context.Context, sensor *domain.Sensor
 		sensor.IsActive,
 		sensor.RegisteredAt,
 		sensor.LastActivity,
+		calibration,
+		anomalyDetection,
+		debounce,
+		sensor.Flapping,
+		virtual,
+		sensor.Manufacturer,
+		sensor.Model,
+		sensor.HardwareRevision,
+		sensor.FirmwareVersion,
+		append([]string{}, sensor.Labels...),
 	).Scan(&sensor.ID, &sensor.RegisteredAt, &sensor.LastActivity)
 	if err != nil {
-		return fmt.Errorf("failed to upsert sensor: %w", err)
+		return fmt.Errorf("failed to save sensor: %w", err)
 	}
 
 	return nil
 }
 
 func (r *SensorRepository) GetSensors(ctx context.Context) ([]domain.Sensor, error) {
-	query := `
-		SELECT id, serial_number, type, current_state, description, is_active, registered_at, last_activity
-		FROM sensors
-	`
-	rows, err := r.pool.Query(ctx, query)
+	query := `SELECT ` + sensorColumns + ` FROM sensors`
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query sensors: %w", err)
 	}
@@ -80,20 +369,11 @@ func (r *SensorRepository) GetSensors(ctx context.Context) ([]domain.Sensor, err
 
 	var sensors []domain.Sensor
 	for rows.Next() {
-		var s domain.Sensor
-		if err := rows.Scan(
-			&s.ID,
-			&s.SerialNumber,
-			&s.Type,
-			&s.CurrentState,
-			&s.Description,
-			&s.IsActive,
-			&s.RegisteredAt,
-			&s.LastActivity,
-		); err != nil {
+		s, err := scanSensor(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan sensor: %w", err)
 		}
-		sensors = append(sensors, s)
+		sensors = append(sensors, *s)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating through sensors: %w", err)
@@ -102,53 +382,25 @@ func (r *SensorRepository) GetSensors(ctx context.Context) ([]domain.Sensor, err
 }
 
 func (r *SensorRepository) GetSensorByID(ctx context.Context, id int64) (*domain.Sensor, error) {
-	query := `
-		SELECT id, serial_number, type, current_state, description, is_active, registered_at, last_activity
-		FROM sensors
-		WHERE id = $1
-	`
-	var s domain.Sensor
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&s.ID,
-		&s.SerialNumber,
-		&s.Type,
-		&s.CurrentState,
-		&s.Description,
-		&s.IsActive,
-		&s.RegisteredAt,
-		&s.LastActivity,
-	)
+	query := `SELECT ` + sensorColumns + ` FROM sensors WHERE id = $1`
+	s, err := scanSensor(transaction.Conn(ctx, r.pool).QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, usecase.ErrSensorNotFound
 		}
 		return nil, fmt.Errorf("failed to get sensor: %w", err)
 	}
-	return &s, nil
+	return s, nil
 }
 
 func (r *SensorRepository) GetSensorBySerialNumber(ctx context.Context, sn string) (*domain.Sensor, error) {
-	query := `
-		SELECT id, serial_number, type, current_state, description, is_active, registered_at, last_activity
-		FROM sensors
-		WHERE serial_number = $1
-	`
-	var s domain.Sensor
-	err := r.pool.QueryRow(ctx, query, sn).Scan(
-		&s.ID,
-		&s.SerialNumber,
-		&s.Type,
-		&s.CurrentState,
-		&s.Description,
-		&s.IsActive,
-		&s.RegisteredAt,
-		&s.LastActivity,
-	)
+	query := `SELECT ` + sensorColumns + ` FROM sensors WHERE serial_number = $1`
+	s, err := scanSensor(transaction.Conn(ctx, r.pool).QueryRow(ctx, query, sn))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, usecase.ErrSensorNotFound
 		}
 		return nil, fmt.Errorf("failed to get sensor by serial number: %w", err)
 	}
-	return &s, nil
+	return s, nil
 }
@@ -2,7 +2,9 @@ package postgres
 
 import (
 	"context"
+	"fmt"
 	"homework/internal/domain"
+	"homework/internal/usecase"
 	"homework/pkg/pg_test"
 	"testing"
 	"time"
@@ -80,6 +82,46 @@ func (suite *SensorTestSuite) TestSensorRepository_SaveSensor() {
 	assert.Equal(suite.T(), updatedSensor, *sensor)
 }
 
+func (suite *SensorTestSuite) TestSensorRepository_CreateSensor() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sensor := domain.Sensor{SerialNumber: "1234500001", Type: domain.SensorTypeADC, Description: "first"}
+	assert.Nil(suite.T(), suite.repo.CreateSensor(ctx, &sensor))
+	assert.NotZero(suite.T(), sensor.ID)
+
+	duplicate := domain.Sensor{SerialNumber: "1234500001", Type: domain.SensorTypeContactClosure, Description: "second"}
+	assert.ErrorIs(suite.T(), suite.repo.CreateSensor(ctx, &duplicate), usecase.ErrSensorAlreadyExists)
+
+	stored, err := suite.repo.GetSensorBySerialNumber(ctx, "1234500001")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), sensor.ID, stored.ID)
+	assert.Equal(suite.T(), "first", stored.Description)
+}
+
+func (suite *SensorTestSuite) TestSensorRepository_UpdateSensorState() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.ErrorIs(suite.T(), suite.repo.UpdateSensorState(ctx, &domain.Sensor{ID: 1000}), usecase.ErrSensorNotFound)
+
+	sensor := domain.Sensor{SerialNumber: "1234500002", Type: domain.SensorTypeADC, Description: "first"}
+	assert.Nil(suite.T(), suite.repo.SaveSensor(ctx, &sensor))
+
+	stale := sensor
+	stale.Description = "stale"
+	stale.CurrentState = 5
+	stale.LastActivity = time.Now().Truncate(time.Microsecond)
+	stale.Model = "m1"
+	assert.Nil(suite.T(), suite.repo.UpdateSensorState(ctx, &stale))
+
+	stored, err := suite.repo.GetSensorByID(ctx, sensor.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "first", stored.Description)
+	assert.Equal(suite.T(), int64(5), stored.CurrentState)
+	assert.Equal(suite.T(), "m1", stored.Model)
+}
+
 func (suite *SensorTestSuite) TestSensorRepository_GetSensors() {
 	ctx, _ := context.WithTimeout(context.Background(), 5*time.Second) //nolint: govet // test stub
 
@@ -153,6 +195,7 @@ func (suite *SensorTestSuite) TestSensorRepository_GetSensorBySerialNumber() {
 		CurrentState: 1,
 		Description:  "test_desc_5",
 		IsActive:     true,
+		Labels:       []string{"hall", "critical"},
 		RegisteredAt: time.Now().Truncate(time.Microsecond).In(time.UTC),
 		LastActivity: time.Now().Truncate(time.Microsecond).In(time.UTC),
 	}
@@ -169,6 +212,195 @@ func (suite *SensorTestSuite) TestSensorRepository_GetSensorBySerialNumber() {
 	assert.Equal(suite.T(), newSensor, *sensor)
 }
 
+func (suite *SensorTestSuite) TestFirmwareHistoryRepository() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	newSensor := domain.Sensor{
+		SerialNumber:     "3987654321",
+		Type:             domain.SensorTypeADC,
+		Description:      "test_desc_6",
+		Manufacturer:     "Acme",
+		Model:            "T-100",
+		HardwareRevision: "B",
+		FirmwareVersion:  "1.2.0",
+	}
+	err := suite.repo.SaveSensor(ctx, &newSensor)
+	assert.Nil(suite.T(), err)
+
+	sensor, err := suite.repo.GetSensorByID(ctx, newSensor.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "T-100", sensor.Model)
+	assert.Equal(suite.T(), "1.2.0", sensor.FirmwareVersion)
+
+	fr := NewFirmwareHistoryRepository(suite.testDbInstance)
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	changes := []domain.FirmwareChange{
+		{SensorID: sensor.ID, Version: "1.1.0", ChangedAt: now},
+		{SensorID: sensor.ID, PreviousVersion: "1.1.0", Version: "1.2.0", ChangedAt: now.Add(time.Minute)},
+	}
+	for i := range changes {
+		assert.Nil(suite.T(), fr.SaveFirmwareChange(ctx, &changes[i]))
+	}
+
+	history, err := fr.GetFirmwareChangesBySensorID(ctx, sensor.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), changes, history)
+}
+
+func (suite *SensorTestSuite) TestSensorDebounce() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	newSensor := domain.Sensor{
+		SerialNumber: "5987654321",
+		Type:         domain.SensorTypeContactClosure,
+		Debounce:     &domain.Debounce{Window: 50 * time.Millisecond, FlapThreshold: 10, FlapWindow: time.Minute},
+		Flapping:     true,
+	}
+	assert.Nil(suite.T(), suite.repo.SaveSensor(ctx, &newSensor))
+
+	sensor, err := suite.repo.GetSensorByID(ctx, newSensor.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), newSensor.Debounce, sensor.Debounce)
+	assert.True(suite.T(), sensor.Flapping)
+}
+
+func (suite *SensorTestSuite) TestVirtualSensorRepository() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	input := domain.Sensor{SerialNumber: "6987654321", Type: domain.SensorTypeADC}
+	assert.Nil(suite.T(), suite.repo.SaveSensor(ctx, &input))
+	newSensor := domain.Sensor{
+		SerialNumber: "7987654321",
+		Type:         domain.SensorTypeVirtual,
+		Virtual:      &domain.VirtualSensor{Expression: fmt.Sprintf("s%d * 2", input.ID), Resolution: 0.01, Unit: "°C"},
+	}
+	assert.Nil(suite.T(), suite.repo.SaveSensor(ctx, &newSensor))
+
+	sensor, err := suite.repo.GetSensorByID(ctx, newSensor.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), newSensor.Virtual, sensor.Virtual)
+
+	vr := NewVirtualSensorRepository(suite.testDbInstance)
+	assert.Nil(suite.T(), vr.SaveVirtualSensorInputs(ctx, sensor.ID, []int64{input.ID}))
+
+	ids, err := vr.GetVirtualSensorIDsByInputID(ctx, input.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []int64{sensor.ID}, ids)
+
+	assert.Nil(suite.T(), vr.SaveVirtualSensorInputs(ctx, sensor.ID, nil))
+	ids, err = vr.GetVirtualSensorIDsByInputID(ctx, input.ID)
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), ids)
+}
+
+func (suite *SensorTestSuite) TestAnomalyStateRepository() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	newSensor := domain.Sensor{
+		SerialNumber:     "4987654321",
+		Type:             domain.SensorTypeADC,
+		AnomalyDetection: &domain.AnomalyDetection{Alpha: 0.1, Sigma: 3, WarmUp: 20},
+	}
+	assert.Nil(suite.T(), suite.repo.SaveSensor(ctx, &newSensor))
+
+	sensor, err := suite.repo.GetSensorByID(ctx, newSensor.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), newSensor.AnomalyDetection, sensor.AnomalyDetection)
+
+	ar := NewAnomalyStateRepository(suite.testDbInstance)
+	state, err := ar.GetAnomalyState(ctx, sensor.ID)
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), state)
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	saved := domain.AnomalyState{SensorID: sensor.ID, Mean: 10.5, Variance: 2.25, Count: 30, UpdatedAt: now}
+	// статистика несуществующего датчика пропускается
+	assert.Nil(suite.T(), ar.SaveAnomalyStates(ctx, []domain.AnomalyState{{SensorID: 1 << 40, UpdatedAt: now}, saved}))
+	saved.Count = 31
+	assert.Nil(suite.T(), ar.SaveAnomalyStates(ctx, []domain.AnomalyState{saved}))
+
+	state, err = ar.GetAnomalyState(ctx, sensor.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &saved, state)
+
+	assert.Nil(suite.T(), ar.DeleteAnomalyState(ctx, sensor.ID))
+	state, err = ar.GetAnomalyState(ctx, sensor.ID)
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), state)
+}
+
+func (suite *SensorTestSuite) TestSensorCredentialRepository() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cr := NewSensorCredentialRepository(suite.testDbInstance)
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+
+	_, err := cr.GetSensorCredential(ctx, 100)
+	assert.ErrorIs(suite.T(), err, usecase.ErrCredentialNotFound)
+
+	credential := domain.SensorCredential{SensorID: 100, Secret: "a", RotatedAt: now}
+	assert.Nil(suite.T(), cr.CreateSensorCredential(ctx, &credential))
+	assert.ErrorIs(suite.T(), cr.CreateSensorCredential(ctx, &credential), usecase.ErrSensorAlreadyProvisioned)
+
+	graceUntil := now.Add(time.Hour)
+	rotated := domain.SensorCredential{SensorID: 100, Secret: "b", PreviousSecret: "a", PreviousExpiresAt: &graceUntil, RotatedAt: now}
+	assert.Nil(suite.T(), cr.SaveSensorCredential(ctx, &rotated))
+
+	stored, err := cr.GetSensorCredential(ctx, 100)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), rotated, *stored)
+
+	nr := NewEventNonceRepository(suite.testDbInstance)
+	assert.Nil(suite.T(), nr.SaveEventNonce(ctx, 100, "n", time.Now().Add(time.Minute)))
+	assert.ErrorIs(suite.T(), nr.SaveEventNonce(ctx, 100, "n", time.Now().Add(time.Minute)), usecase.ErrReplayedEvent)
+
+	assert.Nil(suite.T(), nr.SaveEventNonce(ctx, 100, "expired", time.Now().Add(-time.Second)))
+	assert.Nil(suite.T(), nr.SaveEventNonce(ctx, 100, "expired", time.Now().Add(time.Minute)))
+
+	deleted, err := nr.DeleteExpiredEventNonces(ctx, time.Now().Add(2*time.Minute))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, deleted)
+}
+
+func (suite *SensorTestSuite) TestSensorInvitationRepository() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ir := NewSensorInvitationRepository(suite.testDbInstance)
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+
+	_, err := ir.GetSensorInvitationByHash(ctx, "h")
+	assert.ErrorIs(suite.T(), err, usecase.ErrInvitationNotFound)
+	assert.ErrorIs(suite.T(), ir.UseSensorInvitation(ctx, 100, now), usecase.ErrInvitationNotFound)
+
+	invitation := domain.SensorInvitation{
+		SensorID:  100,
+		Role:      domain.SensorRoleViewer,
+		Hash:      "h",
+		CreatedBy: 1,
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+		MaxUses:   1,
+	}
+	assert.Nil(suite.T(), ir.SaveSensorInvitation(ctx, &invitation))
+
+	assert.Nil(suite.T(), ir.UseSensorInvitation(ctx, invitation.ID, now))
+	assert.ErrorIs(suite.T(), ir.UseSensorInvitation(ctx, invitation.ID, now), usecase.ErrInvitationExpired)
+
+	assert.Nil(suite.T(), ir.RevokeSensorInvitation(ctx, invitation.ID, now))
+	invitation.Uses = 1
+	invitation.RevokedAt = &now
+
+	invitations, err := ir.GetSensorInvitationsBySensorID(ctx, 100)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.SensorInvitation{invitation}, invitations)
+}
+
 func TestSensorTestSuite(t *testing.T) {
 	suite.Run(t, new(SensorTestSuite))
 }
@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	transaction "homework/internal/repository/transaction/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// VirtualSensorRepository хранит входы вычисляемых датчиков в таблице virtual_sensor_inputs
+type VirtualSensorRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewVirtualSensorRepository(pool *pgxpool.Pool) *VirtualSensorRepository {
+	return &VirtualSensorRepository{
+		pool: pool,
+	}
+}
+
+func (r *VirtualSensorRepository) SaveVirtualSensorInputs(ctx context.Context, sensorID int64, inputIDs []int64) error {
+	conn := transaction.Conn(ctx, r.pool)
+	if _, err := conn.Exec(ctx, `DELETE FROM virtual_sensor_inputs WHERE sensor_id = $1`, sensorID); err != nil {
+		return fmt.Errorf("failed to delete virtual sensor inputs: %w", err)
+	}
+
+	for _, inputID := range inputIDs {
+		query := `INSERT INTO virtual_sensor_inputs (sensor_id, input_id) VALUES ($1, $2)`
+		if _, err := conn.Exec(ctx, query, sensorID, inputID); err != nil {
+			return fmt.Errorf("failed to save virtual sensor input: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *VirtualSensorRepository) GetVirtualSensorIDsByInputID(ctx context.Context, inputID int64) ([]int64, error) {
+	query := `SELECT sensor_id FROM virtual_sensor_inputs WHERE input_id = $1 ORDER BY sensor_id`
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, inputID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query virtual sensors: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan virtual sensor id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through virtual sensors: %w", err)
+	}
+	return ids, nil
+}
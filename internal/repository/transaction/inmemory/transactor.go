@@ -0,0 +1,29 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+)
+
+type txKey struct{}
+
+// Transactor сериализует транзакции in-memory хранилищ.
+// Откат изменений не поддерживается: при ошибке уже выполненные изменения сохраняются.
+type Transactor struct {
+	mu sync.Mutex
+}
+
+func NewTransactor() *Transactor {
+	return &Transactor{}
+}
+
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txKey{}) != nil {
+		return fn(ctx)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return fn(context.WithValue(ctx, txKey{}, struct{}{}))
+}
@@ -0,0 +1,119 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"sort"
+	"sync"
+	"time"
+)
+
+type APITokenRepository struct {
+	tokens map[int64]*domain.APIToken
+	byHash map[string]int64
+	lastID int64
+	mu     sync.RWMutex
+}
+
+func NewAPITokenRepository() *APITokenRepository {
+	return &APITokenRepository{
+		tokens: make(map[int64]*domain.APIToken),
+		byHash: make(map[string]int64),
+	}
+}
+
+func (r *APITokenRepository) SaveAPIToken(ctx context.Context, token *domain.APIToken) error {
+	if token == nil {
+		return errors.New("token is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.byHash[token.Hash]; exists {
+		return errors.New("token with this hash already exists")
+	}
+
+	r.lastID++
+	token.ID = r.lastID
+
+	stored := *token
+	r.tokens[token.ID] = &stored
+	r.byHash[token.Hash] = token.ID
+	return nil
+}
+
+func (r *APITokenRepository) GetAPITokenByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byHash[hash]
+	if !ok {
+		return nil, usecase.ErrTokenNotFound
+	}
+
+	token := *r.tokens[id]
+	return &token, nil
+}
+
+func (r *APITokenRepository) GetAPITokensByUserID(ctx context.Context, userID int64) ([]domain.APIToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := []domain.APIToken{}
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			result = append(result, *token)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+func (r *APITokenRepository) RevokeAPIToken(ctx context.Context, id int64, revokedAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok {
+		return usecase.ErrTokenNotFound
+	}
+
+	token.RevokedAt = &revokedAt
+	return nil
+}
+
+func (r *APITokenRepository) DeleteAPITokensByUserID(ctx context.Context, userID int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.UserID == userID {
+			delete(r.byHash, token.Hash)
+			delete(r.tokens, id)
+		}
+	}
+	return nil
+}
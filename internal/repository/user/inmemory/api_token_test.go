@@ -0,0 +1,76 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPITokenRepository(t *testing.T) {
+	t.Run("err, token is nil", func(t *testing.T) {
+		tr := NewAPITokenRepository()
+		err := tr.SaveAPIToken(context.Background(), nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		tr := NewAPITokenRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := tr.SaveAPIToken(ctx, &domain.APIToken{Hash: "h"})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, save, find and revoke", func(t *testing.T) {
+		tr := NewAPITokenRepository()
+		ctx := context.Background()
+
+		token := &domain.APIToken{UserID: 1, Name: "cli", Hash: "h1", CreatedAt: time.Now()}
+		require.NoError(t, tr.SaveAPIToken(ctx, token))
+		require.NoError(t, tr.SaveAPIToken(ctx, &domain.APIToken{UserID: 2, Name: "cli", Hash: "h2"}))
+		assert.Error(t, tr.SaveAPIToken(ctx, &domain.APIToken{UserID: 3, Hash: "h1"}))
+
+		found, err := tr.GetAPITokenByHash(ctx, "h1")
+		require.NoError(t, err)
+		assert.Equal(t, *token, *found)
+
+		_, err = tr.GetAPITokenByHash(ctx, "unknown")
+		assert.ErrorIs(t, err, usecase.ErrTokenNotFound)
+
+		revokedAt := time.Now()
+		require.NoError(t, tr.RevokeAPIToken(ctx, token.ID, revokedAt))
+		assert.ErrorIs(t, tr.RevokeAPIToken(ctx, 42, revokedAt), usecase.ErrTokenNotFound)
+
+		tokens, err := tr.GetAPITokensByUserID(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		require.NotNil(t, tokens[0].RevokedAt)
+		assert.Equal(t, revokedAt, *tokens[0].RevokedAt)
+	})
+	t.Run("ok, delete user's tokens", func(t *testing.T) {
+		tr := NewAPITokenRepository()
+		ctx := context.Background()
+
+		require.NoError(t, tr.SaveAPIToken(ctx, &domain.APIToken{UserID: 1, Name: "cli", Hash: "h1"}))
+		require.NoError(t, tr.SaveAPIToken(ctx, &domain.APIToken{UserID: 1, Name: "ci", Hash: "h2"}))
+		require.NoError(t, tr.SaveAPIToken(ctx, &domain.APIToken{UserID: 2, Name: "cli", Hash: "h3"}))
+
+		require.NoError(t, tr.DeleteAPITokensByUserID(ctx, 1))
+
+		tokens, err := tr.GetAPITokensByUserID(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+		_, err = tr.GetAPITokenByHash(ctx, "h1")
+		assert.ErrorIs(t, err, usecase.ErrTokenNotFound)
+
+		tokens, err = tr.GetAPITokensByUserID(ctx, 2)
+		require.NoError(t, err)
+		assert.Len(t, tokens, 1)
+	})
+}
@@ -3,6 +3,8 @@ package inmemory
 import (
 	"context"
 	"homework/internal/domain"
+	"homework/internal/usecase"
+	"sort"
 	"sync"
 )
 
@@ -69,3 +71,37 @@ func (r *SensorOwnerRepository) GetSensorsByUserID(ctx context.Context, userID i
 	}
 	return result, nil
 }
+
+func (r *SensorOwnerRepository) GetUsersBySensorID(ctx context.Context, sensorID int64) ([]domain.SensorOwner, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.dataLock.RLock()
+	defer r.dataLock.RUnlock()
+
+	result := []domain.SensorOwner{}
+	for _, userSensors := range r.data {
+		if sensorOwner, ok := userSensors[sensorID]; ok {
+			result = append(result, sensorOwner)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].UserID < result[j].UserID })
+	return result, nil
+}
+
+func (r *SensorOwnerRepository) DeleteSensorOwner(ctx context.Context, userID, sensorID int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.dataLock.Lock()
+	defer r.dataLock.Unlock()
+
+	if _, ok := r.data[userID][sensorID]; !ok {
+		return usecase.ErrSensorOwnerNotFound
+	}
+
+	delete(r.data[userID], sensorID)
+	return nil
+}
@@ -3,6 +3,7 @@ package inmemory
 import (
 	"context"
 	"homework/internal/domain"
+	"homework/internal/usecase"
 	"sync"
 	"testing"
 	"time"
@@ -120,3 +121,52 @@ func TestSensorOwnerRepository_GetSensorsByUserID(t *testing.T) {
 		assert.Len(t, sensors, 1)
 	})
 }
+
+func TestSensorOwnerRepository_GetUsersBySensorID(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		sor := NewSensorOwnerRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := sor.GetUsersBySensorID(ctx, 1)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, sorted by user", func(t *testing.T) {
+		sor := NewSensorOwnerRepository()
+		ctx := context.Background()
+
+		assert.NoError(t, sor.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 3, SensorID: 1, Role: domain.SensorRoleViewer}))
+		assert.NoError(t, sor.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 1, SensorID: 1, Role: domain.SensorRoleOwner}))
+		assert.NoError(t, sor.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 1, SensorID: 2, Role: domain.SensorRoleOwner}))
+
+		users, err := sor.GetUsersBySensorID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.SensorOwner{
+			{UserID: 1, SensorID: 1, Role: domain.SensorRoleOwner},
+			{UserID: 3, SensorID: 1, Role: domain.SensorRoleViewer},
+		}, users)
+	})
+}
+
+func TestSensorOwnerRepository_DeleteSensorOwner(t *testing.T) {
+	t.Run("fail, not found", func(t *testing.T) {
+		sor := NewSensorOwnerRepository()
+
+		err := sor.DeleteSensorOwner(context.Background(), 1, 1)
+		assert.ErrorIs(t, err, usecase.ErrSensorOwnerNotFound)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		sor := NewSensorOwnerRepository()
+		ctx := context.Background()
+
+		assert.NoError(t, sor.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 1, SensorID: 1}))
+		assert.NoError(t, sor.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 1, SensorID: 2}))
+		assert.NoError(t, sor.DeleteSensorOwner(ctx, 1, 1))
+
+		sensors, err := sor.GetSensorsByUserID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.SensorOwner{{UserID: 1, SensorID: 2}}, sensors)
+	})
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"homework/internal/domain"
+	"homework/internal/usecase"
+	"sort"
+	"strings"
 	"sync"
 )
 
@@ -39,6 +42,12 @@ func (r *UserRepository) SaveUser(ctx context.Context, user *domain.User) error
 	default:
 	}
 
+	for id, existing := range r.users {
+		if id != user.ID && strings.EqualFold(existing.Name, user.Name) {
+			return usecase.ErrUserNameTaken
+		}
+	}
+
 	if user.ID == 0 {
 		maxID := int64(0)
 		for id := range r.users {
@@ -75,3 +84,60 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id int64) (*domain.Use
 
 	return user, nil
 }
+
+func (r *UserRepository) GetUserByName(ctx context.Context, name string) (*domain.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.userLock.RLock()
+	defer r.userLock.RUnlock()
+
+	for _, user := range r.users {
+		if strings.EqualFold(user.Name, name) {
+			return user, nil
+		}
+	}
+	return nil, usecase.ErrUserNotFound
+}
+
+func (r *UserRepository) GetUsers(ctx context.Context, limit, offset int) ([]domain.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.userLock.RLock()
+	defer r.userLock.RUnlock()
+
+	users := make([]domain.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, *user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+
+	if offset >= len(users) {
+		return []domain.User{}, nil
+	}
+	users = users[offset:]
+	if limit < len(users) {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+func (r *UserRepository) DeleteUser(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.userLock.Lock()
+	defer r.userLock.Unlock()
+
+	if _, exists := r.users[id]; !exists {
+		return ErrUserNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"homework/internal/domain"
+	"homework/internal/usecase"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUserRepository_SaveUser(t *testing.T) {
@@ -49,6 +51,20 @@ func TestUserRepository_SaveUser(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("fail, name taken ignoring case", func(t *testing.T) {
+		sr := NewUserRepository()
+		ctx := context.Background()
+
+		user := domain.User{Name: "User Name"}
+		require.NoError(t, sr.SaveUser(ctx, &user))
+
+		err := sr.SaveUser(ctx, &domain.User{Name: "user name"})
+		assert.ErrorIs(t, err, usecase.ErrUserNameTaken)
+
+		user.Name = "USER NAME"
+		assert.NoError(t, sr.SaveUser(ctx, &user))
+	})
+
 	t.Run("ok, collision test", func(t *testing.T) {
 		sr := NewUserRepository()
 		ctx, cancel := context.WithCancel(context.Background())
@@ -69,3 +85,50 @@ func TestUserRepository_SaveUser(t *testing.T) {
 		wg.Wait()
 	})
 }
+
+func TestUserRepository_GetUsers(t *testing.T) {
+	sr := NewUserRepository()
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, sr.SaveUser(ctx, &domain.User{Name: fmt.Sprintf("user %d", i)}))
+	}
+
+	users, err := sr.GetUsers(ctx, 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.User{{ID: 2, Name: "user 2"}, {ID: 3, Name: "user 3"}}, users)
+
+	users, err = sr.GetUsers(ctx, 2, 5)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func TestUserRepository_DeleteUser(t *testing.T) {
+	sr := NewUserRepository()
+	ctx := context.Background()
+
+	user := domain.User{Name: "User Name"}
+	require.NoError(t, sr.SaveUser(ctx, &user))
+
+	require.NoError(t, sr.DeleteUser(ctx, user.ID))
+
+	_, err := sr.GetUserByID(ctx, user.ID)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	assert.ErrorIs(t, sr.DeleteUser(ctx, user.ID), ErrUserNotFound)
+}
+
+func TestUserRepository_GetUserByName(t *testing.T) {
+	sr := NewUserRepository()
+	ctx := context.Background()
+
+	user := domain.User{Name: "User Name"}
+	require.NoError(t, sr.SaveUser(ctx, &user))
+
+	found, err := sr.GetUserByName(ctx, "user name")
+	require.NoError(t, err)
+	assert.Equal(t, user, *found)
+
+	_, err = sr.GetUserByName(ctx, "someone else")
+	assert.ErrorIs(t, err, usecase.ErrUserNotFound)
+}
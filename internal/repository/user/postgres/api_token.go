@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type APITokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAPITokenRepository(pool *pgxpool.Pool) *APITokenRepository {
+	return &APITokenRepository{
+		pool: pool,
+	}
+}
+
+const apiTokenColumns = `id, user_id, name, hash, created_at, expires_at, revoked_at`
+
+func scanAPIToken(row pgx.Row) (*domain.APIToken, error) {
+	var t domain.APIToken
+	if err := row.Scan(&t.ID, &t.UserID, &t.Name, &t.Hash, &t.CreatedAt, &t.ExpiresAt, &t.RevokedAt); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *APITokenRepository) SaveAPIToken(ctx context.Context, token *domain.APIToken) error {
+	if token == nil {
+		return errors.New("token is nil")
+	}
+
+	query := `
+		INSERT INTO api_tokens (user_id, name, hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, query, token.UserID, token.Name, token.Hash, token.CreatedAt, token.ExpiresAt).
+		Scan(&token.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save api token: %w", err)
+	}
+	return nil
+}
+
+func (r *APITokenRepository) GetAPITokenByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE hash = $1`
+	token, err := scanAPIToken(transaction.Conn(ctx, r.pool).QueryRow(ctx, query, hash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get api token: %w", err)
+	}
+	return token, nil
+}
+
+func (r *APITokenRepository) GetAPITokensByUserID(ctx context.Context, userID int64) ([]domain.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE user_id = $1 ORDER BY id`
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query api tokens: %w", err)
+	}
+	defer rows.Close()
+
+	result := []domain.APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api token: %w", err)
+		}
+		result = append(result, *token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through api tokens: %w", err)
+	}
+	return result, nil
+}
+
+func (r *APITokenRepository) RevokeAPIToken(ctx context.Context, id int64, revokedAt time.Time) error {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, `UPDATE api_tokens SET revoked_at = $2 WHERE id = $1`, id, revokedAt)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api token: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrTokenNotFound
+	}
+	return nil
+}
+
+func (r *APITokenRepository) DeleteAPITokensByUserID(ctx context.Context, userID int64) error {
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM api_tokens WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user's api tokens: %w", err)
+	}
+	return nil
+}
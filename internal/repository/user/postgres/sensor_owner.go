@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -19,25 +21,12 @@ func NewSensorOwnerRepository(pool *pgxpool.Pool) *SensorOwnerRepository {
 }
 
 func (r *SensorOwnerRepository) SaveSensorOwner(ctx context.Context, sensorOwner domain.SensorOwner) error {
-	var count int
-	checkQuery := `
-        SELECT COUNT(*) FROM sensors_users 
-        WHERE sensor_id = $1 AND user_id = $2
-    `
-	err := r.pool.QueryRow(ctx, checkQuery, sensorOwner.SensorID, sensorOwner.UserID).Scan(&count)
-	if err != nil {
-		return fmt.Errorf("failed to check existing sensor owner: %w", err)
-	}
-
-	if count > 0 {
-		return nil
-	}
-
 	query := `
-        INSERT INTO sensors_users (sensor_id, user_id)
-        VALUES ($1, $2)
+        INSERT INTO sensors_users (sensor_id, user_id, role)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (sensor_id, user_id) DO UPDATE SET role = EXCLUDED.role
     `
-	_, err = r.pool.Exec(ctx, query, sensorOwner.SensorID, sensorOwner.UserID)
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, query, sensorOwner.SensorID, sensorOwner.UserID, sensorOwner.Role)
 	if err != nil {
 		return fmt.Errorf("failed to save sensor owner: %w", err)
 	}
@@ -46,11 +35,11 @@ func (r *SensorOwnerRepository) SaveSensorOwner(ctx context.Context, sensorOwner
 
 func (r *SensorOwnerRepository) GetSensorsByUserID(ctx context.Context, userID int64) ([]domain.SensorOwner, error) {
 	query := `
-        SELECT sensor_id, user_id
+        SELECT sensor_id, user_id, role
         FROM sensors_users
         WHERE user_id = $1
     `
-	rows, err := r.pool.Query(ctx, query, userID)
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query sensor owners: %w", err)
 	}
@@ -59,7 +48,7 @@ func (r *SensorOwnerRepository) GetSensorsByUserID(ctx context.Context, userID i
 	var result []domain.SensorOwner
 	for rows.Next() {
 		var so domain.SensorOwner
-		if err := rows.Scan(&so.SensorID, &so.UserID); err != nil {
+		if err := rows.Scan(&so.SensorID, &so.UserID, &so.Role); err != nil {
 			return nil, fmt.Errorf("failed to scan sensor owner: %w", err)
 		}
 		result = append(result, so)
@@ -69,3 +58,42 @@ func (r *SensorOwnerRepository) GetSensorsByUserID(ctx context.Context, userID i
 	}
 	return result, nil
 }
+
+func (r *SensorOwnerRepository) GetUsersBySensorID(ctx context.Context, sensorID int64) ([]domain.SensorOwner, error) {
+	query := `
+        SELECT sensor_id, user_id, role
+        FROM sensors_users
+        WHERE sensor_id = $1
+        ORDER BY user_id
+    `
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, sensorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sensor users: %w", err)
+	}
+	defer rows.Close()
+
+	result := []domain.SensorOwner{}
+	for rows.Next() {
+		var so domain.SensorOwner
+		if err := rows.Scan(&so.SensorID, &so.UserID, &so.Role); err != nil {
+			return nil, fmt.Errorf("failed to scan sensor owner: %w", err)
+		}
+		result = append(result, so)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through sensor users: %w", err)
+	}
+	return result, nil
+}
+
+func (r *SensorOwnerRepository) DeleteSensorOwner(ctx context.Context, userID, sensorID int64) error {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx,
+		`DELETE FROM sensors_users WHERE user_id = $1 AND sensor_id = $2`, userID, sensorID)
+	if err != nil {
+		return fmt.Errorf("failed to delete sensor owner: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrSensorOwnerNotFound
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"homework/internal/domain"
+	"homework/internal/usecase"
 	"homework/pkg/pg_test"
 	"testing"
 	"time"
@@ -69,6 +70,39 @@ func (suite *SensorOwnerTestSuite) TestSensorOwnerRepository_GetSensorsByUserID(
 	}, sensors)
 }
 
+func (suite *SensorOwnerTestSuite) TestSensorOwnerRepository_SaveSensorOwner_UpdatesRole() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := suite.repo.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 4, SensorID: 4, Role: domain.SensorRoleOwner})
+	assert.Nil(suite.T(), err)
+
+	err = suite.repo.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 4, SensorID: 4, Role: domain.SensorRoleViewer})
+	assert.Nil(suite.T(), err)
+
+	sensors, err := suite.repo.GetSensorsByUserID(ctx, 4)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.SensorOwner{{UserID: 4, SensorID: 4, Role: domain.SensorRoleViewer}}, sensors)
+}
+
+func (suite *SensorOwnerTestSuite) TestSensorOwnerRepository_GetUsersBySensorIDAndDelete() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.Nil(suite.T(), suite.repo.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 6, SensorID: 5, Role: domain.SensorRoleViewer}))
+	assert.Nil(suite.T(), suite.repo.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 5, SensorID: 5, Role: domain.SensorRoleOwner}))
+
+	users, err := suite.repo.GetUsersBySensorID(ctx, 5)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.SensorOwner{
+		{UserID: 5, SensorID: 5, Role: domain.SensorRoleOwner},
+		{UserID: 6, SensorID: 5, Role: domain.SensorRoleViewer},
+	}, users)
+
+	assert.Nil(suite.T(), suite.repo.DeleteSensorOwner(ctx, 6, 5))
+	assert.ErrorIs(suite.T(), suite.repo.DeleteSensorOwner(ctx, 6, 5), usecase.ErrSensorOwnerNotFound)
+}
+
 func TestSensorOwnerTestSuite(t *testing.T) {
 	suite.Run(t, new(SensorOwnerTestSuite))
 }
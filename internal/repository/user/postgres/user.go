@@ -5,12 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
 	"homework/internal/usecase"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// uniqueViolation - код ошибки postgres при нарушении уникального индекса
+const uniqueViolation = "23505"
+
 type UserRepository struct {
 	pool *pgxpool.Pool
 }
@@ -28,20 +33,26 @@ func (r *UserRepository) SaveUser(ctx context.Context, user *domain.User) error
 
 	query := `
 		WITH input_data AS (
-			SELECT $1::bigint AS id, $2::text AS name
+			SELECT $1::bigint AS id, $2::text AS name, $3::boolean AS is_admin
 		)
-		INSERT INTO users (id, name)
+		INSERT INTO users (id, name, is_admin)
 		SELECT 
 			CASE WHEN id.id = 0 THEN nextval('users_id_seq') ELSE id.id END,
-			id.name
+			id.name,
+			id.is_admin
 		FROM input_data id
 		ON CONFLICT (id) DO UPDATE
-		SET name = EXCLUDED.name
+		SET name = EXCLUDED.name,
+			is_admin = EXCLUDED.is_admin
 		RETURNING id
 	`
 
-	err := r.pool.QueryRow(ctx, query, user.ID, user.Name).Scan(&user.ID)
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, query, user.ID, user.Name, user.IsAdmin).Scan(&user.ID)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return usecase.ErrUserNameTaken
+		}
 		return fmt.Errorf("failed to upsert user: %w", err)
 	}
 
@@ -51,12 +62,12 @@ func (r *UserRepository) SaveUser(ctx context.Context, user *domain.User) error
 
 func (r *UserRepository) GetUserByID(ctx context.Context, id int64) (*domain.User, error) {
 	query := `
-        SELECT id, name
+        SELECT id, name, is_admin
         FROM users
         WHERE id = $1
     `
 	var user domain.User
-	err := r.pool.QueryRow(ctx, query, id).Scan(&user.ID, &user.Name)
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, query, id).Scan(&user.ID, &user.Name, &user.IsAdmin)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, usecase.ErrUserNotFound
@@ -66,3 +77,60 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id int64) (*domain.Use
 
 	return &user, nil
 }
+
+func (r *UserRepository) GetUserByName(ctx context.Context, name string) (*domain.User, error) {
+	query := `
+		SELECT id, name, is_admin
+		FROM users
+		WHERE lower(name) = lower($1)
+	`
+	var user domain.User
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, query, name).Scan(&user.ID, &user.Name, &user.IsAdmin)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user by name: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepository) GetUsers(ctx context.Context, limit, offset int) ([]domain.User, error) {
+	query := `
+		SELECT id, name, is_admin
+		FROM users
+		ORDER BY id
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []domain.User{}
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(&user.ID, &user.Name, &user.IsAdmin); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate users: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) DeleteUser(ctx context.Context, id int64) error {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrUserNotFound
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"homework/internal/domain"
+	"homework/internal/usecase"
 	"homework/pkg/pg_test"
 	"testing"
 	"time"
@@ -27,6 +28,12 @@ func (suite *UserTestSuite) SetupSuite() {
 	suite.repo = NewUserRepository(suite.testDbInstance)
 }
 
+// SetupTest очищает пользователей: имена уникальны, и тесты не должны зависеть друг от друга
+func (suite *UserTestSuite) SetupTest() {
+	_, err := suite.testDbInstance.Exec(context.Background(), "truncate users restart identity cascade")
+	suite.Require().NoError(err)
+}
+
 func (suite *UserTestSuite) TearDownSuite() {
 	suite.testDB.TearDown()
 }
@@ -43,6 +50,42 @@ func (suite *UserTestSuite) TestUserRepository_SaveUser() {
 	assert.Nil(suite.T(), err)
 }
 
+func (suite *UserTestSuite) TestUserRepository_SaveUser_NameTaken() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := suite.repo.SaveUser(ctx, &domain.User{
+		Name: "petya pupkin",
+	})
+
+	assert.Nil(suite.T(), err)
+
+	err = suite.repo.SaveUser(ctx, &domain.User{
+		Name: "Petya Pupkin",
+	})
+
+	assert.ErrorIs(suite.T(), err, usecase.ErrUserNameTaken)
+}
+
+func (suite *UserTestSuite) TestUserRepository_GetUsersDeleteUser() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	user := domain.User{Name: "kolya pupkin"}
+	err := suite.repo.SaveUser(ctx, &user)
+	assert.Nil(suite.T(), err)
+
+	users, err := suite.repo.GetUsers(ctx, 100, 0)
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), users, user)
+
+	err = suite.repo.DeleteUser(ctx, user.ID)
+	assert.Nil(suite.T(), err)
+
+	err = suite.repo.DeleteUser(ctx, user.ID)
+	assert.ErrorIs(suite.T(), err, usecase.ErrUserNotFound)
+}
+
 func (suite *UserTestSuite) TestUserRepository_GetUserByID() {
 	ctx, _ := context.WithTimeout(context.Background(), 5*time.Second) //nolint: govet // test stub
 
@@ -61,6 +104,62 @@ func (suite *UserTestSuite) TestUserRepository_GetUserByID() {
 	assert.Equal(suite.T(), name, user.Name)
 }
 
+func (suite *UserTestSuite) TestAPITokenRepository() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tr := NewAPITokenRepository(suite.testDbInstance)
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Microsecond).In(time.UTC)
+	token := domain.APIToken{
+		UserID:    10,
+		Name:      "cli",
+		Hash:      "hash_1",
+		CreatedAt: time.Now().Truncate(time.Microsecond).In(time.UTC),
+		ExpiresAt: &expiresAt,
+	}
+	err := tr.SaveAPIToken(ctx, &token)
+	assert.Nil(suite.T(), err)
+	assert.NotZero(suite.T(), token.ID)
+
+	stored, err := tr.GetAPITokenByHash(ctx, "hash_1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), token, *stored)
+
+	revokedAt := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	err = tr.RevokeAPIToken(ctx, token.ID, revokedAt)
+	assert.Nil(suite.T(), err)
+
+	tokens, err := tr.GetAPITokensByUserID(ctx, 10)
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), tokens, 1)
+	assert.Equal(suite.T(), revokedAt, *tokens[0].RevokedAt)
+
+	_, err = tr.GetAPITokenByHash(ctx, "unknown")
+	assert.ErrorIs(suite.T(), err, usecase.ErrTokenNotFound)
+
+	err = tr.DeleteAPITokensByUserID(ctx, 10)
+	assert.Nil(suite.T(), err)
+
+	_, err = tr.GetAPITokenByHash(ctx, "hash_1")
+	assert.ErrorIs(suite.T(), err, usecase.ErrTokenNotFound)
+}
+
+func (suite *UserTestSuite) TestUserRepository_GetUserByName() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	user := domain.User{Name: "Petya Ivanov"}
+	assert.Nil(suite.T(), suite.repo.SaveUser(ctx, &user))
+
+	found, err := suite.repo.GetUserByName(ctx, "PETYA IVANOV")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), user, *found)
+
+	_, err = suite.repo.GetUserByName(ctx, "nobody")
+	assert.ErrorIs(suite.T(), err, usecase.ErrUserNotFound)
+}
+
 func TestUserTestSuite(t *testing.T) {
 	suite.Run(t, new(UserTestSuite))
 }
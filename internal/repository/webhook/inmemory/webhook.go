@@ -0,0 +1,204 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WebhookRepository хранит подписки по id, а журнал отправок - в порядке создания
+type WebhookRepository struct {
+	webhooks       map[int64]domain.Webhook
+	deliveries     []domain.WebhookDelivery
+	mu             sync.RWMutex
+	lastID         int64
+	lastDeliveryID int64
+}
+
+func NewWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{
+		webhooks: make(map[int64]domain.Webhook),
+	}
+}
+
+func (r *WebhookRepository) SaveWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	if webhook == nil {
+		return errors.New("webhook is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if webhook.ID == 0 {
+		r.lastID++
+		webhook.ID = r.lastID
+	} else if _, ok := r.webhooks[webhook.ID]; !ok {
+		return usecase.ErrWebhookNotFound
+	}
+
+	r.webhooks[webhook.ID] = copyWebhook(*webhook)
+
+	return nil
+}
+
+func (r *WebhookRepository) GetWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhooks := make([]domain.Webhook, 0, len(r.webhooks))
+	for _, webhook := range r.webhooks {
+		webhooks = append(webhooks, copyWebhook(webhook))
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) GetWebhookByID(ctx context.Context, id int64) (*domain.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return nil, usecase.ErrWebhookNotFound
+	}
+
+	webhook = copyWebhook(webhook)
+	return &webhook, nil
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[id]; !ok {
+		return usecase.ErrWebhookNotFound
+	}
+	delete(r.webhooks, id)
+
+	r.deliveries = slices.DeleteFunc(r.deliveries, func(delivery domain.WebhookDelivery) bool {
+		return delivery.WebhookID == id
+	})
+
+	return nil
+}
+
+func (r *WebhookRepository) SaveWebhookDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	if delivery == nil {
+		return errors.New("webhook delivery is nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if delivery.ID == 0 {
+		if _, ok := r.webhooks[delivery.WebhookID]; !ok {
+			return usecase.ErrWebhookNotFound
+		}
+		r.lastDeliveryID++
+		delivery.ID = r.lastDeliveryID
+		r.deliveries = append(r.deliveries, copyDelivery(*delivery))
+		return nil
+	}
+
+	for i := range r.deliveries {
+		if r.deliveries[i].ID == delivery.ID {
+			r.deliveries[i] = copyDelivery(*delivery)
+			return nil
+		}
+	}
+	// отправка удалена вместе с подпиской
+	return nil
+}
+
+func (r *WebhookRepository) GetWebhookDeliveries(ctx context.Context, filter domain.WebhookDeliveryFilter) ([]domain.WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]domain.WebhookDelivery, 0)
+	skipped := 0
+	for i := len(r.deliveries) - 1; i >= 0 && len(result) < filter.Limit; i-- {
+		if !filter.Matches(&r.deliveries[i]) {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		result = append(result, copyDelivery(r.deliveries[i]))
+	}
+
+	return result, nil
+}
+
+func (r *WebhookRepository) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]domain.WebhookDelivery, 0)
+	for i := range r.deliveries {
+		if len(result) >= limit {
+			break
+		}
+		delivery := &r.deliveries[i]
+		if delivery.Status != domain.WebhookDeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		delivery.NextAttemptAt = leaseUntil
+		result = append(result, copyDelivery(*delivery))
+	}
+
+	return result, nil
+}
+
+func copyWebhook(webhook domain.Webhook) domain.Webhook {
+	webhook.EventKinds = slices.Clone(webhook.EventKinds)
+	webhook.SensorIDs = slices.Clone(webhook.SensorIDs)
+	webhook.SensorTypes = slices.Clone(webhook.SensorTypes)
+	webhook.Labels = slices.Clone(webhook.Labels)
+	return webhook
+}
+
+func copyDelivery(delivery domain.WebhookDelivery) domain.WebhookDelivery {
+	delivery.Payload = slices.Clone(delivery.Payload)
+	if delivery.DeliveredAt != nil {
+		deliveredAt := *delivery.DeliveredAt
+		delivery.DeliveredAt = &deliveredAt
+	}
+	return delivery
+}
@@ -0,0 +1,108 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		wr := NewWebhookRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := wr.SaveWebhook(ctx, &domain.Webhook{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("fail, not found", func(t *testing.T) {
+		wr := NewWebhookRepository()
+		ctx := context.Background()
+
+		_, err := wr.GetWebhookByID(ctx, 1)
+		assert.ErrorIs(t, err, usecase.ErrWebhookNotFound)
+		assert.ErrorIs(t, wr.SaveWebhook(ctx, &domain.Webhook{ID: 1}), usecase.ErrWebhookNotFound)
+		assert.ErrorIs(t, wr.DeleteWebhook(ctx, 1), usecase.ErrWebhookNotFound)
+		assert.ErrorIs(t, wr.SaveWebhookDelivery(ctx, &domain.WebhookDelivery{WebhookID: 1}), usecase.ErrWebhookNotFound)
+	})
+
+	t.Run("ok, stored webhook is a copy", func(t *testing.T) {
+		wr := NewWebhookRepository()
+		ctx := context.Background()
+
+		webhook := &domain.Webhook{URL: "http://example.com", SensorIDs: []int64{1, 2}, Labels: []string{"hall"}, Enabled: true}
+		require.NoError(t, wr.SaveWebhook(ctx, webhook))
+		assert.Equal(t, int64(1), webhook.ID)
+		webhook.SensorIDs[0] = 3
+		webhook.Labels[0] = "kitchen"
+
+		stored, err := wr.GetWebhookByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1, 2}, stored.SensorIDs)
+		assert.Equal(t, []string{"hall"}, stored.Labels)
+	})
+
+	t.Run("ok, claim due deliveries oldest first", func(t *testing.T) {
+		wr := NewWebhookRepository()
+		ctx := context.Background()
+		now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		require.NoError(t, wr.SaveWebhook(ctx, &domain.Webhook{URL: "http://example.com"}))
+		deliveries := []domain.WebhookDelivery{
+			{WebhookID: 1, Status: domain.WebhookDeliveryPending, NextAttemptAt: now},
+			{WebhookID: 1, Status: domain.WebhookDeliveryDelivered, NextAttemptAt: now},
+			{WebhookID: 1, Status: domain.WebhookDeliveryPending, NextAttemptAt: now.Add(time.Minute)},
+			{WebhookID: 1, Status: domain.WebhookDeliveryPending, NextAttemptAt: now.Add(-time.Minute)},
+		}
+		for i := range deliveries {
+			require.NoError(t, wr.SaveWebhookDelivery(ctx, &deliveries[i]))
+		}
+
+		leaseUntil := now.Add(time.Minute)
+		claimed, err := wr.ClaimWebhookDeliveries(ctx, now, leaseUntil, 10)
+		require.NoError(t, err)
+		require.Len(t, claimed, 2)
+		assert.Equal(t, int64(1), claimed[0].ID)
+		assert.Equal(t, int64(4), claimed[1].ID)
+		assert.Equal(t, leaseUntil, claimed[0].NextAttemptAt)
+
+		// взятые отправки не выдаются повторно до окончания аренды
+		claimed, err = wr.ClaimWebhookDeliveries(ctx, now, leaseUntil, 10)
+		require.NoError(t, err)
+		assert.Empty(t, claimed)
+	})
+
+	t.Run("ok, delivery log newest first and deleted with webhook", func(t *testing.T) {
+		wr := NewWebhookRepository()
+		ctx := context.Background()
+
+		require.NoError(t, wr.SaveWebhook(ctx, &domain.Webhook{URL: "http://example.com/1"}))
+		require.NoError(t, wr.SaveWebhook(ctx, &domain.Webhook{URL: "http://example.com/2"}))
+		for _, webhookID := range []int64{1, 2, 1, 1} {
+			delivery := &domain.WebhookDelivery{WebhookID: webhookID, Status: domain.WebhookDeliveryPending}
+			require.NoError(t, wr.SaveWebhookDelivery(ctx, delivery))
+		}
+
+		delivery := &domain.WebhookDelivery{ID: 3, WebhookID: 1, Status: domain.WebhookDeliveryDead, Attempts: 6}
+		require.NoError(t, wr.SaveWebhookDelivery(ctx, delivery))
+
+		log, err := wr.GetWebhookDeliveries(ctx, domain.WebhookDeliveryFilter{WebhookID: 1, Limit: 2, Offset: 1})
+		require.NoError(t, err)
+		require.Len(t, log, 2)
+		assert.Equal(t, int64(3), log[0].ID)
+		assert.Equal(t, domain.WebhookDeliveryDead, log[0].Status)
+		assert.Equal(t, int64(1), log[1].ID)
+
+		require.NoError(t, wr.DeleteWebhook(ctx, 1))
+		log, err = wr.GetWebhookDeliveries(ctx, domain.WebhookDeliveryFilter{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, log, 1)
+		assert.Equal(t, int64(2), log[0].WebhookID)
+	})
+}
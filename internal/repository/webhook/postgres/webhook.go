@@ -0,0 +1,263 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	webhookColumns  = `id, url, secret, event_kinds, sensor_ids, sensor_types, labels, enabled, created_by, created_at`
+	deliveryColumns = `id, webhook_id, event_kind, payload, status, attempts, next_attempt_at, last_status_code,
+	last_error, created_at, delivered_at`
+)
+
+// WebhookRepository хранит подписки в таблице webhooks, а журнал отправок - в webhook_deliveries.
+// Фильтры подписок хранятся массивами, пустой массив не ограничивает уведомления.
+type WebhookRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewWebhookRepository(pool *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{
+		pool: pool,
+	}
+}
+
+func (r *WebhookRepository) SaveWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	if webhook == nil {
+		return errors.New("webhook is nil")
+	}
+
+	conn := transaction.Conn(ctx, r.pool)
+	eventKinds, sensorIDs, sensorTypes := toStrings(webhook.EventKinds), nonNil(webhook.SensorIDs), toStrings(webhook.SensorTypes)
+	labels := toStrings(webhook.Labels)
+	if webhook.ID == 0 {
+		query := `
+			INSERT INTO webhooks (url, secret, event_kinds, sensor_ids, sensor_types, labels, enabled, created_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id
+		`
+		err := conn.QueryRow(ctx, query, webhook.URL, webhook.Secret, eventKinds, sensorIDs, sensorTypes, labels,
+			webhook.Enabled, webhook.CreatedBy, webhook.CreatedAt).Scan(&webhook.ID)
+		if err != nil {
+			return fmt.Errorf("failed to save webhook: %w", err)
+		}
+		return nil
+	}
+
+	query := `
+		UPDATE webhooks
+		SET url = $2, secret = $3, event_kinds = $4, sensor_ids = $5, sensor_types = $6, labels = $7, enabled = $8,
+			created_by = $9, created_at = $10
+		WHERE id = $1
+	`
+	tag, err := conn.Exec(ctx, query, webhook.ID, webhook.URL, webhook.Secret, eventKinds, sensorIDs, sensorTypes, labels,
+		webhook.Enabled, webhook.CreatedBy, webhook.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepository) GetWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []domain.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) GetWebhookByID(ctx context.Context, id int64) (*domain.Webhook, error) {
+	webhook, err := scanWebhook(transaction.Conn(ctx, r.pool).QueryRow(ctx,
+		`SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, usecase.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook удаляет подписку, журнал ее отправок удаляется каскадно
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, id int64) error {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepository) SaveWebhookDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	if delivery == nil {
+		return errors.New("webhook delivery is nil")
+	}
+
+	conn := transaction.Conn(ctx, r.pool)
+	if delivery.ID == 0 {
+		query := `
+			INSERT INTO webhook_deliveries (webhook_id, event_kind, payload, status, attempts, next_attempt_at,
+				last_status_code, last_error, created_at, delivered_at)
+			SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			WHERE EXISTS (SELECT 1 FROM webhooks WHERE id = $1)
+			RETURNING id
+		`
+		err := conn.QueryRow(ctx, query, delivery.WebhookID, delivery.EventKind, delivery.Payload, delivery.Status,
+			delivery.Attempts, delivery.NextAttemptAt, delivery.LastStatusCode, delivery.LastError, delivery.CreatedAt,
+			delivery.DeliveredAt).Scan(&delivery.ID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return usecase.ErrWebhookNotFound
+			}
+			return fmt.Errorf("failed to save webhook delivery: %w", err)
+		}
+		return nil
+	}
+
+	// отправка, удаленная вместе с подпиской, не обновляется
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_status_code = $5, last_error = $6,
+			delivered_at = $7
+		WHERE id = $1
+	`
+	_, err := conn.Exec(ctx, query, delivery.ID, delivery.Status, delivery.Attempts, delivery.NextAttemptAt,
+		delivery.LastStatusCode, delivery.LastError, delivery.DeliveredAt)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) GetWebhookDeliveries(ctx context.Context, filter domain.WebhookDeliveryFilter) ([]domain.WebhookDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE ($1::bigint = 0 OR webhook_id = $1)
+			AND ($2::text = '' OR status = $2)
+		ORDER BY id DESC
+		LIMIT $3 OFFSET $4
+	`
+	return r.queryDeliveries(ctx, query, filter.WebhookID, string(filter.Status), filter.Limit, filter.Offset)
+}
+
+// ClaimWebhookDeliveries откладывает выбранные отправки до leaseUntil одним запросом, пропуская строки,
+// заблокированные другими экземплярами сервера, поэтому одна отправка не выполняется одновременно дважды
+func (r *WebhookRepository) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+
+	deliveries, err := r.queryDeliveries(ctx, query, now, leaseUntil, limit)
+	if err != nil {
+		return nil, err
+	}
+	// RETURNING не сохраняет порядок подзапроса
+	slices.SortFunc(deliveries, func(a, b domain.WebhookDelivery) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) queryDeliveries(ctx context.Context, query string, args ...any) ([]domain.WebhookDelivery, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		var delivery domain.WebhookDelivery
+		err := rows.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventKind, &delivery.Payload, &delivery.Status,
+			&delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastStatusCode, &delivery.LastError,
+			&delivery.CreatedAt, &delivery.DeliveredAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func scanWebhook(row pgx.Row) (*domain.Webhook, error) {
+	var (
+		webhook                 domain.Webhook
+		eventKinds, sensorTypes []string
+	)
+	err := row.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, &eventKinds, &webhook.SensorIDs, &sensorTypes,
+		&webhook.Labels, &webhook.Enabled, &webhook.CreatedBy, &webhook.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, kind := range eventKinds {
+		webhook.EventKinds = append(webhook.EventKinds, domain.WebhookEventKind(kind))
+	}
+	for _, sensorType := range sensorTypes {
+		webhook.SensorTypes = append(webhook.SensorTypes, domain.SensorType(sensorType))
+	}
+	if len(webhook.SensorIDs) == 0 {
+		webhook.SensorIDs = nil
+	}
+	if len(webhook.Labels) == 0 {
+		webhook.Labels = nil
+	}
+	return &webhook, nil
+}
+
+// toStrings переводит значения фильтра в text[], пустой фильтр - в пустой массив
+func toStrings[T ~string](values []T) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, string(value))
+	}
+	return result
+}
+
+func nonNil(values []int64) []int64 {
+	if values == nil {
+		return []int64{}
+	}
+	return values
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type WebhookTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	repo *WebhookRepository
+}
+
+func (suite *WebhookTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.repo = NewWebhookRepository(suite.testDbInstance)
+}
+
+func (suite *WebhookTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *WebhookTestSuite) TestWebhookRepository() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	webhook := domain.Webhook{URL: "http://example.com/hook", Secret: "whs_secret",
+		EventKinds: []domain.WebhookEventKind{domain.WebhookEventAlert}, SensorIDs: []int64{1, 2},
+		SensorTypes: []domain.SensorType{domain.SensorTypeADC}, Labels: []string{"critical"}, Enabled: true, CreatedBy: 3,
+		CreatedAt: now}
+	unfiltered := domain.Webhook{URL: "http://example.com/all", Secret: "whs_other", Enabled: true, CreatedAt: now}
+	for _, w := range []*domain.Webhook{&webhook, &unfiltered} {
+		assert.Nil(suite.T(), suite.repo.SaveWebhook(ctx, w))
+		assert.NotZero(suite.T(), w.ID)
+	}
+
+	webhooks, err := suite.repo.GetWebhooks(ctx)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.Webhook{webhook, unfiltered}, webhooks)
+
+	webhook.Enabled = false
+	assert.Nil(suite.T(), suite.repo.SaveWebhook(ctx, &webhook))
+	stored, err := suite.repo.GetWebhookByID(ctx, webhook.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &webhook, stored)
+
+	assert.ErrorIs(suite.T(), suite.repo.SaveWebhook(ctx, &domain.Webhook{ID: 1000}), usecase.ErrWebhookNotFound)
+	_, err = suite.repo.GetWebhookByID(ctx, 1000)
+	assert.ErrorIs(suite.T(), err, usecase.ErrWebhookNotFound)
+
+	due := domain.WebhookDelivery{WebhookID: webhook.ID, EventKind: domain.WebhookEventAlert,
+		Payload: []byte(`{"kind": "alert"}`), Status: domain.WebhookDeliveryPending, NextAttemptAt: now, CreatedAt: now}
+	later := due
+	later.NextAttemptAt = now.Add(time.Hour)
+	other := due
+	other.WebhookID = unfiltered.ID
+	for _, d := range []*domain.WebhookDelivery{&due, &later, &other} {
+		assert.Nil(suite.T(), suite.repo.SaveWebhookDelivery(ctx, d))
+		assert.NotZero(suite.T(), d.ID)
+	}
+	assert.ErrorIs(suite.T(), suite.repo.SaveWebhookDelivery(ctx, &domain.WebhookDelivery{WebhookID: 1000,
+		Payload: []byte(`{}`)}), usecase.ErrWebhookNotFound)
+
+	leaseUntil := now.Add(time.Minute)
+	claimed, err := suite.repo.ClaimWebhookDeliveries(ctx, now, leaseUntil, 10)
+	require.Nil(suite.T(), err)
+	require.Len(suite.T(), claimed, 2)
+	assert.Equal(suite.T(), due.ID, claimed[0].ID)
+	assert.Equal(suite.T(), other.ID, claimed[1].ID)
+	assert.Equal(suite.T(), leaseUntil, claimed[0].NextAttemptAt)
+	assert.JSONEq(suite.T(), `{"kind": "alert"}`, string(claimed[0].Payload))
+
+	claimed, err = suite.repo.ClaimWebhookDeliveries(ctx, now, leaseUntil, 10)
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), claimed)
+
+	due.Delivered(204, now)
+	assert.Nil(suite.T(), suite.repo.SaveWebhookDelivery(ctx, &due))
+
+	deliveries, err := suite.repo.GetWebhookDeliveries(ctx, domain.WebhookDeliveryFilter{WebhookID: webhook.ID,
+		Status: domain.WebhookDeliveryDelivered, Limit: 10})
+	require.Nil(suite.T(), err)
+	require.Len(suite.T(), deliveries, 1)
+	assert.Equal(suite.T(), 1, deliveries[0].Attempts)
+	assert.Equal(suite.T(), 204, deliveries[0].LastStatusCode)
+	assert.Equal(suite.T(), now, *deliveries[0].DeliveredAt)
+
+	deliveries, err = suite.repo.GetWebhookDeliveries(ctx, domain.WebhookDeliveryFilter{WebhookID: webhook.ID, Limit: 10})
+	require.Nil(suite.T(), err)
+	require.Len(suite.T(), deliveries, 2)
+	assert.Equal(suite.T(), later.ID, deliveries[0].ID)
+
+	assert.Nil(suite.T(), suite.repo.DeleteWebhook(ctx, webhook.ID))
+	assert.ErrorIs(suite.T(), suite.repo.DeleteWebhook(ctx, webhook.ID), usecase.ErrWebhookNotFound)
+	deliveries, err = suite.repo.GetWebhookDeliveries(ctx, domain.WebhookDeliveryFilter{Limit: 10})
+	assert.Nil(suite.T(), err)
+	require.Len(suite.T(), deliveries, 1)
+	assert.Equal(suite.T(), other.ID, deliveries[0].ID)
+}
+
+func TestWebhookTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookTestSuite))
+}
@@ -0,0 +1,68 @@
+package serial
+
+import (
+	"strconv"
+	"strings"
+)
+
+const alphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// Luhn проверяет контрольную цифру по алгоритму Луна, последняя цифра номера - контрольная
+func Luhn(sn string) bool {
+	return luhnModN(sn, alphanumeric[:10])
+}
+
+// LuhnMod36 проверяет контрольный символ по алгоритму Luhn mod N для алфавита 0-9A-Z
+func LuhnMod36(sn string) bool {
+	return luhnModN(strings.ToUpper(sn), alphanumeric)
+}
+
+func luhnModN(sn, alphabet string) bool {
+	n := len(alphabet)
+	if len(sn) < 2 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(sn) - 1; i >= 0; i-- {
+		code := strings.IndexByte(alphabet, sn[i])
+		if code < 0 {
+			return false
+		}
+		if double {
+			code *= 2
+			code = code/n + code%n
+		}
+		sum += code
+		double = !double
+	}
+	return sum%n == 0
+}
+
+// CRC8 проверяет, что два последних символа номера - шестнадцатеричная запись CRC-8 (полином 0x07)
+// от остальной части номера
+func CRC8(sn string) bool {
+	if len(sn) < 3 {
+		return false
+	}
+
+	body, suffix := sn[:len(sn)-2], sn[len(sn)-2:]
+	expected, err := strconv.ParseUint(suffix, 16, 8)
+	if err != nil {
+		return false
+	}
+
+	var crc byte
+	for i := 0; i < len(body); i++ {
+		crc ^= body[i]
+		for j := 0; j < 8; j++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc == byte(expected)
+}
@@ -0,0 +1,30 @@
+package serial
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLuhn(t *testing.T) {
+	assert.True(t, Luhn("79927398713"))
+	assert.False(t, Luhn("79927398710"))
+	assert.False(t, Luhn("7992739871A"))
+	assert.False(t, Luhn("7"))
+}
+
+func TestLuhnMod36(t *testing.T) {
+	assert.True(t, LuhnMod36("AX1B2C3D4Z"))
+	assert.True(t, LuhnMod36("ax1b2c3d4z"))
+	assert.False(t, LuhnMod36("AX1B2C3D4E"))
+	assert.False(t, LuhnMod36("AX-B2C3D4Z"))
+}
+
+func TestCRC8(t *testing.T) {
+	// CRC-8 (0x07) от "123456789" равна 0xF4
+	assert.True(t, CRC8("123456789F4"))
+	assert.True(t, CRC8("123456789f4"))
+	assert.False(t, CRC8("123456789F5"))
+	assert.False(t, CRC8("123456789ZZ"))
+	assert.False(t, CRC8("F4"))
+}
@@ -0,0 +1,152 @@
+// Package serial содержит реестр схем серийных номеров датчиков.
+// Схема выбирается по самому длинному префиксу производителя или типа устройства,
+// которому соответствует серийный номер; схема с пустым префиксом используется по умолчанию.
+package serial
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrUnknownScheme   = errors.New("unknown serial number scheme")
+	ErrInvalidFormat   = errors.New("serial number does not match scheme")
+	ErrInvalidChecksum = errors.New("serial number checksum mismatch")
+)
+
+// Scheme - схема серийных номеров
+type Scheme struct {
+	// Name - название схемы
+	Name string
+	// Prefix - префикс серийного номера, по которому выбирается схема
+	Prefix string
+	// Pattern - регулярное выражение, которому должен соответствовать весь серийный номер
+	Pattern *regexp.Regexp
+	// Checksum - необязательная проверка контрольной суммы
+	Checksum func(sn string) bool
+}
+
+// Registry - реестр схем серийных номеров
+type Registry struct {
+	mu      sync.RWMutex
+	schemes map[string]Scheme
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		schemes: make(map[string]Scheme),
+	}
+}
+
+// Default возвращает реестр со схемой по умолчанию: ровно 10 цифр без контрольной суммы
+func Default() *Registry {
+	r := NewRegistry()
+	_ = r.Register(Scheme{
+		Name:    "numeric10",
+		Pattern: regexp.MustCompile(`^\d{10}$`),
+	})
+	return r
+}
+
+// checksums - проверки контрольной суммы, доступные в конфигурации схем
+var checksums = map[string]func(sn string) bool{
+	"none":   nil,
+	"luhn":   Luhn,
+	"luhn36": LuhnMod36,
+	"crc8":   CRC8,
+}
+
+// ParseSchemes разбирает список схем "prefix=checksum:length,prefix=checksum:length", например "AX=luhn36:10".
+// Номер схемы начинается с префикса, состоит из цифр и латинских букв и имеет длину length вместе с префиксом.
+// Checksum - одна из проверок none, luhn, luhn36 или crc8.
+func ParseSchemes(s string) ([]Scheme, error) {
+	var schemes []Scheme
+	for _, item := range strings.Split(s, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		prefix, value, ok := strings.Cut(item, "=")
+		prefix = strings.TrimSpace(prefix)
+		if !ok || prefix == "" {
+			return nil, errors.New("serial scheme must be in prefix=checksum:length form")
+		}
+
+		name, lengthValue, ok := strings.Cut(strings.TrimSpace(value), ":")
+		checksum, known := checksums[name]
+		if !ok || !known {
+			return nil, fmt.Errorf("invalid serial scheme %q", item)
+		}
+		length, err := strconv.Atoi(lengthValue)
+		if err != nil || length <= len(prefix) {
+			return nil, fmt.Errorf("invalid serial scheme length %q", item)
+		}
+
+		schemes = append(schemes, Scheme{
+			Name:     prefix + "-" + name,
+			Prefix:   prefix,
+			Pattern:  regexp.MustCompile(fmt.Sprintf(`^%s[0-9A-Za-z]{%d}$`, regexp.QuoteMeta(prefix), length-len(prefix))),
+			Checksum: checksum,
+		})
+	}
+	return schemes, nil
+}
+
+// Register добавляет схему в реестр. Префикс должен быть уникальным.
+func (r *Registry) Register(scheme Scheme) error {
+	if scheme.Pattern == nil {
+		return fmt.Errorf("scheme %q has no pattern", scheme.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.schemes[scheme.Prefix]; ok {
+		return fmt.Errorf("prefix %q is already used by scheme %q", scheme.Prefix, existing.Name)
+	}
+	r.schemes[scheme.Prefix] = scheme
+	return nil
+}
+
+// Lookup возвращает схему с самым длинным префиксом, с которого начинается серийный номер
+func (r *Registry) Lookup(sn string) (Scheme, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var (
+		result Scheme
+		found  bool
+	)
+	for prefix, scheme := range r.schemes {
+		if !strings.HasPrefix(sn, prefix) {
+			continue
+		}
+		if !found || len(prefix) > len(result.Prefix) {
+			result = scheme
+			found = true
+		}
+	}
+	return result, found
+}
+
+// Validate проверяет серийный номер по выбранной для него схеме
+func (r *Registry) Validate(sn string) error {
+	scheme, ok := r.Lookup(sn)
+	if !ok {
+		return ErrUnknownScheme
+	}
+
+	if !scheme.Pattern.MatchString(sn) {
+		return fmt.Errorf("%w %q", ErrInvalidFormat, scheme.Name)
+	}
+
+	if scheme.Checksum != nil && !scheme.Checksum(sn) {
+		return fmt.Errorf("%w (scheme %q)", ErrInvalidChecksum, scheme.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package serial
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Validate(t *testing.T) {
+	r := Default()
+	require.NoError(t, r.Register(Scheme{
+		Name:     "acme",
+		Prefix:   "AX",
+		Pattern:  regexp.MustCompile(`^AX[0-9A-Z]{8}$`),
+		Checksum: LuhnMod36,
+	}))
+
+	tests := []struct {
+		name string
+		sn   string
+		want error
+	}{
+		{"default scheme, ok", "0123456789", nil},
+		{"default scheme, letters", "01234S6789", ErrInvalidFormat},
+		{"default scheme, too short", "012345678", ErrInvalidFormat},
+		{"default scheme, empty", "", ErrInvalidFormat},
+		{"vendor scheme, ok", "AX1B2C3D4Z", nil},
+		{"vendor scheme, checksum mismatch", "AX1B2C3D4E", ErrInvalidChecksum},
+		{"vendor scheme, wrong length", "AX1B2C3D4", ErrInvalidFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.Validate(tt.sn)
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestRegistry_Lookup(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register(Scheme{Name: "short", Prefix: "A", Pattern: regexp.MustCompile(`.*`)}))
+	require.NoError(t, r.Register(Scheme{Name: "long", Prefix: "AB", Pattern: regexp.MustCompile(`.*`)}))
+
+	scheme, ok := r.Lookup("ABC")
+	assert.True(t, ok)
+	assert.Equal(t, "long", scheme.Name)
+
+	scheme, ok = r.Lookup("AC")
+	assert.True(t, ok)
+	assert.Equal(t, "short", scheme.Name)
+
+	_, ok = r.Lookup("0123456789")
+	assert.False(t, ok)
+	assert.ErrorIs(t, r.Validate("0123456789"), ErrUnknownScheme)
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := Default()
+
+	err := r.Register(Scheme{Name: "no pattern", Prefix: "X"})
+	assert.Error(t, err)
+
+	err = r.Register(Scheme{Name: "duplicate", Pattern: regexp.MustCompile(`.*`)})
+	assert.Error(t, err)
+}
+
+func TestParseSchemes(t *testing.T) {
+	schemes, err := ParseSchemes("AX=luhn36:10, ZT=crc8:8,")
+	require.NoError(t, err)
+	require.Len(t, schemes, 2)
+
+	r := Default()
+	for _, scheme := range schemes {
+		require.NoError(t, r.Register(scheme))
+	}
+	assert.NoError(t, r.Validate("AX1B2C3D4Z"))
+	assert.ErrorIs(t, r.Validate("AX1B2C3D4E"), ErrInvalidChecksum)
+	assert.ErrorIs(t, r.Validate("AX1B2C3D"), ErrInvalidFormat)
+	assert.NoError(t, r.Validate("ZT123442"))
+	assert.NoError(t, r.Validate("0123456789"))
+
+	schemes, err = ParseSchemes("")
+	require.NoError(t, err)
+	assert.Empty(t, schemes)
+
+	for _, s := range []string{"AX", "=luhn:10", "AX=sha:10", "AX=luhn36", "AX=luhn36:2", "AX=luhn36:x"} {
+		_, err := ParseSchemes(s)
+		assert.Error(t, err, s)
+	}
+}
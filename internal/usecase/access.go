@@ -0,0 +1,369 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"sync"
+)
+
+// AccessPolicy решает, какие датчики и дома доступны пользователю.
+// Датчик доступен, если он привязан к пользователю напрямую, привязан к группе, в которой состоит пользователь,
+// или размещен в доме, к которому у пользователя есть доступ.
+// Администратору доступно все.
+//
+// Проверки выполняются только для пользователя из контекста запроса (см. ContextWithUser).
+// Если пользователя в контексте нет, значит аутентификация выключена, и доступ не ограничивается.
+type AccessPolicy struct {
+	sensorOwnerRepo SensorOwnerRepository
+	homeOwnerRepo   HomeOwnerRepository
+	roomRepo        RoomRepository
+	groupRepo       GroupRepository
+
+	watchersLock sync.Mutex
+	watchers     map[int64]*sensorWatcher
+	lastWatcher  int64
+}
+
+// sensorWatcher - наблюдение за доступом пользователя к датчику, например на время WebSocket-подписки
+type sensorWatcher struct {
+	user     *domain.User
+	sensorID int64
+	revoked  chan struct{}
+}
+
+// NewAccessPolicy создает политику доступа. hor и rr могут быть nil, тогда доступ через дома не учитывается.
+func NewAccessPolicy(sor SensorOwnerRepository, hor HomeOwnerRepository, rr RoomRepository, options ...func(*AccessPolicy)) *AccessPolicy {
+	p := &AccessPolicy{
+		sensorOwnerRepo: sor,
+		homeOwnerRepo:   hor,
+		roomRepo:        rr,
+		watchers:        make(map[int64]*sensorWatcher),
+	}
+
+	for _, o := range options {
+		o(p)
+	}
+
+	return p
+}
+
+// WithGroupAccess дает участникам групп доступ к датчикам, привязанным к группам
+func WithGroupAccess(gr GroupRepository) func(*AccessPolicy) {
+	return func(p *AccessPolicy) {
+		p.groupRepo = gr
+	}
+}
+
+// restricted возвращает пользователя, доступ которого надо ограничить, или false,
+// если политика не задана, пользователя в контексте нет или он администратор
+func (p *AccessPolicy) restricted(ctx context.Context) (*domain.User, bool) {
+	if p == nil {
+		return nil, false
+	}
+
+	user, ok := UserFromContext(ctx)
+	if !ok || user.IsAdmin {
+		return nil, false
+	}
+	return user, true
+}
+
+// SensorRoles возвращает роли пользователя для всех доступных ему датчиков.
+// Датчики групп доступны с ролью привязки датчика к группе, датчики в комнатах домов пользователя - с ролью оператора.
+// Если датчик доступен несколькими путями, действует самая высокая роль.
+func (p *AccessPolicy) SensorRoles(ctx context.Context, userID int64) (map[int64]domain.SensorRole, error) {
+	sensorOwners, err := p.sensorOwnerRepo.GetSensorsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make(map[int64]domain.SensorRole, len(sensorOwners))
+	for _, sensorOwner := range sensorOwners {
+		roles[sensorOwner.SensorID] = sensorRole(sensorOwner)
+	}
+
+	groupSensors, err := userGroupSensors(ctx, p.groupRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, groupSensor := range groupSensors {
+		if !roles[groupSensor.SensorID].Includes(groupSensor.Role) {
+			roles[groupSensor.SensorID] = groupSensor.Role
+		}
+	}
+
+	if p.homeOwnerRepo == nil {
+		return roles, nil
+	}
+
+	homeRoles, err := p.HomeRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for homeID := range homeRoles {
+		rooms, err := p.roomRepo.GetRoomsByHomeID(ctx, homeID)
+		if err != nil {
+			return nil, err
+		}
+		for _, room := range rooms {
+			sensorRooms, err := p.roomRepo.GetSensorsByRoomID(ctx, room.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, sensorRoom := range sensorRooms {
+				if !roles[sensorRoom.SensorID].Includes(domain.SensorRoleOperator) {
+					roles[sensorRoom.SensorID] = domain.SensorRoleOperator
+				}
+			}
+		}
+	}
+
+	return roles, nil
+}
+
+// HomeRoles возвращает роли пользователя для всех доступных ему домов
+func (p *AccessPolicy) HomeRoles(ctx context.Context, userID int64) (map[int64]domain.HomeRole, error) {
+	roles := make(map[int64]domain.HomeRole)
+	if p.homeOwnerRepo == nil {
+		return roles, nil
+	}
+
+	homeOwners, err := p.homeOwnerRepo.GetHomesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, homeOwner := range homeOwners {
+		roles[homeOwner.HomeID] = homeRole(homeOwner)
+	}
+	return roles, nil
+}
+
+// CheckSensor возвращает ErrSensorNotFound, если датчик недоступен пользователю из контекста.
+// Недоступный датчик неотличим от несуществующего, чтобы не раскрывать его наличие.
+func (p *AccessPolicy) CheckSensor(ctx context.Context, sensorID int64) error {
+	return p.CheckSensorRole(ctx, sensorID, domain.SensorRoleViewer)
+}
+
+// CheckSensorRole проверяет, что у пользователя из контекста есть роль не ниже required.
+// Для недоступного датчика возвращает ErrSensorNotFound, для доступного с младшей ролью - ErrInsufficientRole.
+func (p *AccessPolicy) CheckSensorRole(ctx context.Context, sensorID int64, required domain.SensorRole) error {
+	user, ok := p.restricted(ctx)
+	if !ok {
+		return nil
+	}
+
+	roles, err := p.SensorRoles(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	role, ok := roles[sensorID]
+	if !ok {
+		return ErrSensorNotFound
+	}
+	if !role.Includes(required) {
+		return ErrInsufficientRole
+	}
+	return nil
+}
+
+// CheckHome возвращает ErrHomeNotFound, если дом недоступен пользователю из контекста
+func (p *AccessPolicy) CheckHome(ctx context.Context, homeID int64) error {
+	return p.CheckHomeRole(ctx, homeID, domain.HomeRoleMember)
+}
+
+// CheckHomeRole проверяет, что у пользователя из контекста есть роль не ниже required.
+// Для недоступного дома возвращает ErrHomeNotFound, для доступного с младшей ролью - ErrInsufficientHomeRole.
+func (p *AccessPolicy) CheckHomeRole(ctx context.Context, homeID int64, required domain.HomeRole) error {
+	user, ok := p.restricted(ctx)
+	if !ok {
+		return nil
+	}
+
+	roles, err := p.HomeRoles(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	role, ok := roles[homeID]
+	if !ok {
+		return ErrHomeNotFound
+	}
+	if !role.Includes(required) {
+		return ErrInsufficientHomeRole
+	}
+	return nil
+}
+
+// FilterSensors оставляет только датчики, доступные пользователю из контекста
+func (p *AccessPolicy) FilterSensors(ctx context.Context, sensors []domain.Sensor) ([]domain.Sensor, error) {
+	user, ok := p.restricted(ctx)
+	if !ok {
+		return sensors, nil
+	}
+
+	roles, err := p.SensorRoles(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.Sensor, 0, len(roles))
+	for _, sensor := range sensors {
+		if _, ok := roles[sensor.ID]; ok {
+			result = append(result, sensor)
+		}
+	}
+	return result, nil
+}
+
+// FilterHomes оставляет только дома, доступные пользователю из контекста
+func (p *AccessPolicy) FilterHomes(ctx context.Context, homes []domain.Home) ([]domain.Home, error) {
+	user, ok := p.restricted(ctx)
+	if !ok {
+		return homes, nil
+	}
+
+	roles, err := p.HomeRoles(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.Home, 0, len(roles))
+	for _, home := range homes {
+		if _, ok := roles[home.ID]; ok {
+			result = append(result, home)
+		}
+	}
+	return result, nil
+}
+
+// grantSensor делает пользователя из контекста владельцем созданного им датчика
+func (p *AccessPolicy) grantSensor(ctx context.Context, sensorID int64) error {
+	user, ok := p.restricted(ctx)
+	if !ok {
+		return nil
+	}
+
+	return p.sensorOwnerRepo.SaveSensorOwner(ctx, domain.SensorOwner{
+		UserID:   user.ID,
+		SensorID: sensorID,
+		Role:     domain.SensorRoleOwner,
+	})
+}
+
+// grantHome делает пользователя из контекста владельцем созданного им дома
+func (p *AccessPolicy) grantHome(ctx context.Context, homeID int64) error {
+	user, ok := p.restricted(ctx)
+	if !ok || p.homeOwnerRepo == nil {
+		return nil
+	}
+
+	return p.homeOwnerRepo.SaveHomeOwner(ctx, domain.HomeOwner{UserID: user.ID, HomeID: homeID, Role: domain.HomeRoleOwner})
+}
+
+// WatchSensor возвращает канал, который закрывается, когда пользователь из контекста теряет доступ к датчику.
+// Наблюдение заканчивается вместе с ctx. Для пользователя без ограничений возвращается nil - такой канал никогда не закроется.
+func (p *AccessPolicy) WatchSensor(ctx context.Context, sensorID int64) <-chan struct{} {
+	user, ok := p.restricted(ctx)
+	if !ok {
+		return nil
+	}
+
+	watcher := &sensorWatcher{
+		user:     user,
+		sensorID: sensorID,
+		revoked:  make(chan struct{}),
+	}
+
+	p.watchersLock.Lock()
+	p.lastWatcher++
+	id := p.lastWatcher
+	p.watchers[id] = watcher
+	p.watchersLock.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		p.unwatch(id)
+	}()
+
+	return watcher.revoked
+}
+
+// unwatch снимает наблюдение и сообщает, было ли оно еще активно
+func (p *AccessPolicy) unwatch(id int64) bool {
+	p.watchersLock.Lock()
+	defer p.watchersLock.Unlock()
+
+	if _, ok := p.watchers[id]; !ok {
+		return false
+	}
+	delete(p.watchers, id)
+	return true
+}
+
+// accessChanged перепроверяет наблюдения за датчиками пользователей, чей доступ мог сократиться,
+// и закрывает каналы тех, кто доступ потерял
+func (p *AccessPolicy) accessChanged(ctx context.Context, userIDs ...int64) {
+	if p == nil || len(userIDs) == 0 {
+		return
+	}
+
+	changed := make(map[int64]struct{}, len(userIDs))
+	for _, userID := range userIDs {
+		changed[userID] = struct{}{}
+	}
+
+	p.watchersLock.Lock()
+	affected := make(map[int64]*sensorWatcher)
+	for id, watcher := range p.watchers {
+		if _, ok := changed[watcher.user.ID]; ok {
+			affected[id] = watcher
+		}
+	}
+	p.watchersLock.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	for id, watcher := range affected {
+		err := p.CheckSensor(ContextWithUser(ctx, watcher.user), watcher.sensorID)
+		if errors.Is(err, ErrSensorNotFound) && p.unwatch(id) {
+			close(watcher.revoked)
+		}
+	}
+}
+
+// userGroupSensors возвращает привязки датчиков ко всем группам пользователя. gr может быть nil, тогда групп нет.
+func userGroupSensors(ctx context.Context, gr GroupRepository, userID int64) ([]domain.GroupSensor, error) {
+	if gr == nil {
+		return nil, nil
+	}
+
+	memberships, err := gr.GetGroupsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []domain.GroupSensor
+	for _, membership := range memberships {
+		groupSensors, err := gr.GetSensorsByGroupID(ctx, membership.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, groupSensors...)
+	}
+	return result, nil
+}
+
+// sensorRole возвращает роль привязки. Привязки, созданные до появления ролей, давали полный доступ.
+func sensorRole(sensorOwner domain.SensorOwner) domain.SensorRole {
+	if sensorOwner.Role == "" {
+		return domain.SensorRoleOwner
+	}
+	return sensorOwner.Role
+}
+
+// homeRole возвращает роль привязки к дому. Привязки без роли давали полный доступ.
+func homeRole(homeOwner domain.HomeOwner) domain.HomeRole {
+	if homeOwner.Role == "" {
+		return domain.HomeRoleOwner
+	}
+	return homeOwner.Role
+}
@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_accessPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := &domain.User{ID: 1, Name: "owner"}
+
+	t.Run("ok, no user in context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p := NewAccessPolicy(nil, nil, nil)
+
+		assert.NoError(t, p.CheckSensor(ctx, 1))
+		assert.NoError(t, p.CheckHome(ctx, 1))
+	})
+
+	t.Run("ok, nil policy", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var p *AccessPolicy
+		assert.NoError(t, p.CheckSensor(ContextWithUser(ctx, user), 1))
+	})
+
+	t.Run("ok, admin", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 2, IsAdmin: true})
+
+		p := NewAccessPolicy(nil, nil, nil)
+
+		sensors, err := p.FilterSensors(ctx, []domain.Sensor{{ID: 1}, {ID: 2}})
+		require.NoError(t, err)
+		assert.Len(t, sensors, 2)
+	})
+
+	t.Run("fail, sensor of another user", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, user)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Times(1).Return([]domain.SensorOwner{{UserID: 1, SensorID: 1}}, nil)
+
+		p := NewAccessPolicy(sor, nil, nil)
+
+		assert.ErrorIs(t, p.CheckSensor(ctx, 2), ErrSensorNotFound)
+	})
+
+	t.Run("ok, sensor in a room of user's home", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, user)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Times(1).Return(nil, nil)
+
+		hor := NewMockHomeOwnerRepository(ctrl)
+		hor.EXPECT().GetHomesByUserID(ctx, int64(1)).Times(1).Return([]domain.HomeOwner{{UserID: 1, HomeID: 3}}, nil)
+
+		rr := NewMockRoomRepository(ctrl)
+		rr.EXPECT().GetRoomsByHomeID(ctx, int64(3)).Times(1).Return([]domain.Room{{ID: 4, HomeID: 3}}, nil)
+		rr.EXPECT().GetSensorsByRoomID(ctx, int64(4)).Times(1).Return([]domain.SensorRoom{{RoomID: 4, SensorID: 2}}, nil)
+
+		p := NewAccessPolicy(sor, hor, rr)
+
+		assert.NoError(t, p.CheckSensor(ctx, 2))
+	})
+
+	t.Run("ok, filter sensors", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, user)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Times(1).Return([]domain.SensorOwner{{UserID: 1, SensorID: 2}}, nil)
+
+		p := NewAccessPolicy(sor, nil, nil)
+
+		sensors, err := p.FilterSensors(ctx, []domain.Sensor{{ID: 1}, {ID: 2}})
+		require.NoError(t, err)
+		require.Len(t, sensors, 1)
+		assert.Equal(t, int64(2), sensors[0].ID)
+	})
+}
+
+func Test_accessPolicy_CheckSensorRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := &domain.User{ID: 1, Name: "member"}
+
+	t.Run("ok, home access gives operator role", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, user)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Times(2).
+			Return([]domain.SensorOwner{{UserID: 1, SensorID: 2, Role: domain.SensorRoleViewer}}, nil)
+
+		hor := NewMockHomeOwnerRepository(ctrl)
+		hor.EXPECT().GetHomesByUserID(ctx, int64(1)).Times(2).Return([]domain.HomeOwner{{UserID: 1, HomeID: 3}}, nil)
+
+		rr := NewMockRoomRepository(ctrl)
+		rr.EXPECT().GetRoomsByHomeID(ctx, int64(3)).Times(2).Return([]domain.Room{{ID: 4, HomeID: 3}}, nil)
+		rr.EXPECT().GetSensorsByRoomID(ctx, int64(4)).Times(2).Return([]domain.SensorRoom{{RoomID: 4, SensorID: 2}}, nil)
+
+		p := NewAccessPolicy(sor, hor, rr)
+
+		assert.NoError(t, p.CheckSensorRole(ctx, 2, domain.SensorRoleOperator))
+		assert.ErrorIs(t, p.CheckSensorRole(ctx, 2, domain.SensorRoleOwner), ErrInsufficientRole)
+	})
+}
+
+func Test_accessPolicy_groups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := &domain.User{ID: 1, Name: "member"}
+
+	t.Run("ok, group link gives its role", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, user)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Times(1).
+			Return([]domain.SensorOwner{{UserID: 1, SensorID: 2, Role: domain.SensorRoleViewer}}, nil)
+
+		gr := NewMockGroupRepository(ctrl)
+		gr.EXPECT().GetGroupsByUserID(ctx, int64(1)).Times(1).Return([]domain.GroupMember{{GroupID: 5, UserID: 1}}, nil)
+		gr.EXPECT().GetSensorsByGroupID(ctx, int64(5)).Times(1).Return([]domain.GroupSensor{
+			{GroupID: 5, SensorID: 2, Role: domain.SensorRoleOperator},
+			{GroupID: 5, SensorID: 3, Role: domain.SensorRoleViewer},
+		}, nil)
+
+		p := NewAccessPolicy(sor, nil, nil, WithGroupAccess(gr))
+
+		roles, err := p.SensorRoles(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]domain.SensorRole{
+			2: domain.SensorRoleOperator,
+			3: domain.SensorRoleViewer,
+		}, roles)
+	})
+
+	t.Run("ok, watcher closed after access is lost", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, user)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(gomock.Any(), int64(1)).Times(2).Return(nil, nil)
+
+		gr := NewMockGroupRepository(ctrl)
+		gomock.InOrder(
+			gr.EXPECT().GetGroupsByUserID(gomock.Any(), int64(1)).Times(1).
+				Return([]domain.GroupMember{{GroupID: 5, UserID: 1}}, nil),
+			gr.EXPECT().GetGroupsByUserID(gomock.Any(), int64(1)).Times(1).Return(nil, nil),
+		)
+		gr.EXPECT().GetSensorsByGroupID(gomock.Any(), int64(5)).Times(1).
+			Return([]domain.GroupSensor{{GroupID: 5, SensorID: 2, Role: domain.SensorRoleViewer}}, nil)
+
+		p := NewAccessPolicy(sor, nil, nil, WithGroupAccess(gr))
+		revoked := p.WatchSensor(ctx, 2)
+
+		p.accessChanged(context.Background(), 1)
+		select {
+		case <-revoked:
+			t.Fatal("access is still granted")
+		default:
+		}
+
+		p.accessChanged(context.Background(), 1)
+		select {
+		case <-revoked:
+		default:
+			t.Fatal("watcher is not closed")
+		}
+	})
+
+	t.Run("ok, no watch without user", func(t *testing.T) {
+		p := NewAccessPolicy(nil, nil, nil)
+		assert.Nil(t, p.WatchSensor(context.Background(), 1))
+	})
+}
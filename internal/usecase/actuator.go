@@ -0,0 +1,490 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxActuatorNameLength - максимальная длина названия устройства в символах
+	maxActuatorNameLength = 100
+	// maxCommandErrorLength - сколько символов причины неудачи команды сохраняется
+	maxCommandErrorLength = 200
+
+	// DefaultCommandTimeout - время на выполнение команды, если оно не задано
+	DefaultCommandTimeout = time.Minute
+	// MaxCommandTimeout - максимальное время на выполнение команды
+	MaxCommandTimeout = time.Hour
+	// MaxPulseDuration - максимальная длительность команды pulse
+	MaxPulseDuration = 10 * time.Minute
+
+	// DefaultCommandsPageSize - размер страницы истории команд, если он не задан
+	DefaultCommandsPageSize = 50
+	// MaxCommandsPageSize - максимальный размер страницы истории команд
+	MaxCommandsPageSize = 100
+)
+
+// Actuator - исполнительные устройства и очередь команд к ним. Просматривать устройства и команды может любой,
+// кому доступен датчик-контроллер, отправлять команды - операторы, добавлять, менять и удалять устройства - владельцы.
+//
+// Контроллер периодически забирает открытые команды своих устройств (FetchCommands) и сообщает о выполнении
+// каждой (AckCommand). Команда, которую контроллер не выполнил до ExpiresAt, истекает.
+type Actuator struct {
+	actuatorRepo ActuatorRepository
+	commandRepo  CommandRepository
+	sensorRepo   SensorRepository
+	access       *AccessPolicy
+	transactor   Transactor
+	audit        *Audit
+	now          func() time.Time
+}
+
+func NewActuator(ar ActuatorRepository, cr CommandRepository, sr SensorRepository, options ...func(*Actuator)) *Actuator {
+	a := &Actuator{
+		actuatorRepo: ar,
+		commandRepo:  cr,
+		sensorRepo:   sr,
+		transactor:   noTransaction{},
+		now:          time.Now,
+	}
+
+	for _, o := range options {
+		o(a)
+	}
+
+	return a
+}
+
+// WithActuatorAccessPolicy ограничивает устройства и команды доступными пользователю из контекста датчиками
+func WithActuatorAccessPolicy(p *AccessPolicy) func(*Actuator) {
+	return func(a *Actuator) {
+		a.access = p
+	}
+}
+
+// WithActuatorTransactor задает транзакцию для подтверждения команды вместе с изменением состояния устройства
+func WithActuatorTransactor(t Transactor) func(*Actuator) {
+	return func(a *Actuator) {
+		a.transactor = t
+	}
+}
+
+// WithActuatorAudit записывает изменения устройств и отправку команд в журнал аудита
+func WithActuatorAudit(au *Audit) func(*Actuator) {
+	return func(a *Actuator) {
+		a.audit = au
+	}
+}
+
+// WithActuatorClock подменяет источник текущего времени, используется в тестах
+func WithActuatorClock(now func() time.Time) func(*Actuator) {
+	return func(a *Actuator) {
+		a.now = now
+	}
+}
+
+// CreateActuator добавляет устройство датчику-контроллеру
+func (a *Actuator) CreateActuator(ctx context.Context, actuator *domain.Actuator) (*domain.Actuator, error) {
+	if actuator == nil {
+		return nil, ErrActuatorNotFound
+	}
+	if err := validateActuator(actuator); err != nil {
+		return nil, err
+	}
+	if err := a.checkSensor(ctx, actuator.SensorID, domain.SensorRoleOwner); err != nil {
+		return nil, err
+	}
+
+	actuator.ID = 0
+	actuator.State = 0
+	actuator.CreatedAt = a.now()
+	actuator.CreatedBy = 0
+	if caller, ok := UserFromContext(ctx); ok {
+		actuator.CreatedBy = caller.ID
+	}
+
+	if err := a.actuatorRepo.SaveActuator(ctx, actuator); err != nil {
+		return nil, err
+	}
+
+	if err := a.audit.record(ctx, domain.AuditActionCreate, domain.AuditEntityActuator, actuator.ID, nil, actuator); err != nil {
+		return nil, err
+	}
+
+	return actuator, nil
+}
+
+// GetActuators возвращает устройства датчика sensorID или, если он равен 0, устройства всех доступных датчиков
+func (a *Actuator) GetActuators(ctx context.Context, sensorID int64) ([]domain.Actuator, error) {
+	if sensorID != 0 {
+		if err := a.checkSensor(ctx, sensorID, domain.SensorRoleViewer); err != nil {
+			return nil, err
+		}
+	}
+
+	actuators, err := a.actuatorRepo.GetActuators(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var roles map[int64]domain.SensorRole
+	if caller, ok := a.access.restricted(ctx); ok && sensorID == 0 {
+		if roles, err = a.access.SensorRoles(ctx, caller.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]domain.Actuator, 0, len(actuators))
+	for _, actuator := range actuators {
+		if sensorID != 0 && actuator.SensorID != sensorID {
+			continue
+		}
+		if roles != nil {
+			if _, ok := roles[actuator.SensorID]; !ok {
+				continue
+			}
+		}
+		result = append(result, actuator)
+	}
+	return result, nil
+}
+
+// GetActuatorByID возвращает устройство. Устройство недоступного датчика неотличимо от несуществующего.
+func (a *Actuator) GetActuatorByID(ctx context.Context, id int64) (*domain.Actuator, error) {
+	actuator, err := a.actuatorRepo.GetActuatorByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if actuator == nil {
+		return nil, ErrActuatorNotFound
+	}
+
+	if err := a.access.CheckSensor(ctx, actuator.SensorID); err != nil {
+		if errors.Is(err, ErrSensorNotFound) {
+			return nil, ErrActuatorNotFound
+		}
+		return nil, err
+	}
+
+	return actuator, nil
+}
+
+// UpdateActuator меняет название и тип устройства. Датчик-контроллер не меняется, состояние, недопустимое
+// для нового типа, сбрасывается в 0.
+func (a *Actuator) UpdateActuator(ctx context.Context, actuator *domain.Actuator) (*domain.Actuator, error) {
+	if actuator == nil {
+		return nil, ErrActuatorNotFound
+	}
+
+	existingActuator, err := a.GetActuatorByID(ctx, actuator.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	actuator.SensorID = existingActuator.SensorID
+	actuator.State = existingActuator.State
+	actuator.CreatedBy = existingActuator.CreatedBy
+	actuator.CreatedAt = existingActuator.CreatedAt
+	if err := validateActuator(actuator); err != nil {
+		return nil, err
+	}
+	if !actuator.Type.IsValidState(actuator.State) {
+		actuator.State = 0
+	}
+	if err := a.access.CheckSensorRole(ctx, actuator.SensorID, domain.SensorRoleOwner); err != nil {
+		return nil, err
+	}
+
+	if err := a.actuatorRepo.SaveActuator(ctx, actuator); err != nil {
+		return nil, err
+	}
+
+	if err := a.audit.record(ctx, domain.AuditActionUpdate, domain.AuditEntityActuator, actuator.ID, existingActuator, actuator); err != nil {
+		return nil, err
+	}
+
+	return actuator, nil
+}
+
+// DeleteActuator удаляет устройство вместе с историей его команд
+func (a *Actuator) DeleteActuator(ctx context.Context, id int64) error {
+	actuator, err := a.GetActuatorByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := a.access.CheckSensorRole(ctx, actuator.SensorID, domain.SensorRoleOwner); err != nil {
+		return err
+	}
+
+	if err := a.actuatorRepo.DeleteActuator(ctx, id); err != nil {
+		return err
+	}
+
+	return a.audit.record(ctx, domain.AuditActionDelete, domain.AuditEntityActuator, id, actuator, nil)
+}
+
+// SendCommand ставит команду устройству command.ActuatorID в очередь контроллера.
+// Контроллер должен выполнить команду за timeout, 0 - за DefaultCommandTimeout.
+func (a *Actuator) SendCommand(ctx context.Context, command *domain.Command, timeout time.Duration) (*domain.Command, error) {
+	if command == nil {
+		return nil, ErrCommandNotFound
+	}
+
+	if timeout == 0 {
+		timeout = DefaultCommandTimeout
+	}
+	actuator, err := a.checkCommand(ctx, command, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	now := a.now()
+	command.ID = 0
+	command.SensorID = actuator.SensorID
+	command.Status = domain.CommandPending
+	command.Error = ""
+	command.CreatedAt = now
+	command.ExpiresAt = now.Add(timeout)
+	command.DeliveredAt = nil
+	command.CompletedAt = nil
+	command.CreatedBy = 0
+	if caller, ok := UserFromContext(ctx); ok {
+		command.CreatedBy = caller.ID
+	}
+
+	if err := a.commandRepo.AddCommand(ctx, command); err != nil {
+		return nil, err
+	}
+
+	if err := a.audit.record(ctx, domain.AuditActionCreate, domain.AuditEntityCommand, command.ID, nil, command); err != nil {
+		return nil, err
+	}
+
+	return command, nil
+}
+
+// GetCommands возвращает страницу истории команд устройства filter.ActuatorID, от новых к старым
+func (a *Actuator) GetCommands(ctx context.Context, filter domain.CommandFilter) ([]domain.Command, error) {
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, ErrInvalidCommandFilter
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultCommandsPageSize
+	}
+	if filter.Limit < 0 || filter.Limit > MaxCommandsPageSize || filter.Offset < 0 {
+		return nil, ErrInvalidPagination
+	}
+
+	if _, err := a.GetActuatorByID(ctx, filter.ActuatorID); err != nil {
+		return nil, err
+	}
+
+	return a.commandRepo.GetCommands(ctx, filter)
+}
+
+// GetCommandByID возвращает команду. Команда недоступного датчика неотличима от несуществующей.
+func (a *Actuator) GetCommandByID(ctx context.Context, id int64) (*domain.Command, error) {
+	command, err := a.commandRepo.GetCommandByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if command == nil {
+		return nil, ErrCommandNotFound
+	}
+
+	if err := a.access.CheckSensor(ctx, command.SensorID); err != nil {
+		if errors.Is(err, ErrSensorNotFound) {
+			return nil, ErrCommandNotFound
+		}
+		return nil, err
+	}
+
+	return command, nil
+}
+
+// FetchCommands возвращает контроллеру с серийным номером sn открытые команды его устройств, от старых к новым,
+// и отмечает их забранными. Команда возвращается, пока контроллер не сообщит о ее выполнении,
+// поэтому контроллер должен пропускать уже выполненные команды по id.
+func (a *Actuator) FetchCommands(ctx context.Context, sn string) ([]domain.Command, error) {
+	sensor, err := a.controller(ctx, sn)
+	if err != nil {
+		return nil, err
+	}
+
+	commands, err := a.commandRepo.GetOpenCommandsBySensorID(ctx, sensor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := a.now()
+	result := make([]domain.Command, 0, len(commands))
+	for _, command := range commands {
+		switch {
+		case command.Expire(now):
+		case command.Status == domain.CommandPending:
+			command.Status = domain.CommandDelivered
+			command.DeliveredAt = &now
+		default:
+			result = append(result, command)
+			continue
+		}
+
+		err := a.commandRepo.UpdateCommandStatus(ctx, &command)
+		if errors.Is(err, ErrCommandCompleted) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if command.Status == domain.CommandDelivered {
+			result = append(result, command)
+		}
+	}
+	return result, nil
+}
+
+// AckCommand сохраняет результат выполнения команды id контроллером с серийным номером sn:
+// domain.CommandAcked или domain.CommandFailed с причиной reason. Выполненная команда set_state
+// меняет состояние устройства.
+func (a *Actuator) AckCommand(ctx context.Context, sn string, id int64, status domain.CommandStatus, reason string) (*domain.Command, error) {
+	if status != domain.CommandAcked && status != domain.CommandFailed {
+		return nil, ErrInvalidCommand
+	}
+
+	sensor, err := a.controller(ctx, sn)
+	if err != nil {
+		return nil, err
+	}
+
+	command, err := a.commandRepo.GetCommandByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if command == nil || command.SensorID != sensor.ID {
+		return nil, ErrCommandNotFound
+	}
+	if !command.Status.IsOpen() {
+		return nil, ErrCommandCompleted
+	}
+
+	now := a.now()
+	if command.Expire(now) {
+		if err := a.commandRepo.UpdateCommandStatus(ctx, command); err != nil && !errors.Is(err, ErrCommandCompleted) {
+			return nil, err
+		}
+		return nil, ErrCommandExpired
+	}
+
+	if command.DeliveredAt == nil {
+		command.DeliveredAt = &now
+	}
+	command.Status = status
+	command.CompletedAt = &now
+	command.Error = ""
+	if status == domain.CommandFailed {
+		command.Error = truncateReason(strings.TrimSpace(reason), maxCommandErrorLength)
+	}
+
+	err = a.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := a.commandRepo.UpdateCommandStatus(ctx, command); err != nil {
+			return err
+		}
+		if status == domain.CommandAcked && command.Kind == domain.CommandSetState {
+			return a.actuatorRepo.SetActuatorState(ctx, command.ActuatorID, command.State)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return command, nil
+}
+
+// ExpireCommands отмечает истекшими команды, которые контроллеры не выполнили вовремя. Вызывается периодически.
+func (a *Actuator) ExpireCommands(ctx context.Context) error {
+	_, err := a.commandRepo.ExpireCommands(ctx, a.now())
+	return err
+}
+
+// controller возвращает датчик-контроллер по серийному номеру. Пользователь, который забирает команды
+// или сообщает об их выполнении вместо контроллера, должен быть оператором датчика.
+func (a *Actuator) controller(ctx context.Context, sn string) (*domain.Sensor, error) {
+	sensor, err := a.sensorRepo.GetSensorBySerialNumber(ctx, sn)
+	if err != nil {
+		return nil, err
+	}
+	if sensor == nil {
+		return nil, ErrSensorNotFound
+	}
+
+	if err := a.access.CheckSensorRole(ctx, sensor.ID, domain.SensorRoleOperator); err != nil {
+		return nil, err
+	}
+	return sensor, nil
+}
+
+func (a *Actuator) checkSensor(ctx context.Context, sensorID int64, required domain.SensorRole) error {
+	sensor, err := a.sensorRepo.GetSensorByID(ctx, sensorID)
+	if err != nil {
+		return err
+	}
+	if sensor == nil {
+		return ErrSensorNotFound
+	}
+
+	return a.access.CheckSensorRole(ctx, sensorID, required)
+}
+
+func validateActuator(actuator *domain.Actuator) error {
+	actuator.Name = strings.TrimSpace(actuator.Name)
+	if actuator.Name == "" || utf8.RuneCountInString(actuator.Name) > maxActuatorNameLength {
+		return ErrInvalidActuator
+	}
+	if !actuator.Type.IsValid() {
+		return ErrInvalidActuator
+	}
+	return nil
+}
+
+// checkCommand проверяет, что пользователь из контекста может отправить команду command с временем
+// на выполнение timeout, и возвращает устройство команды
+func (a *Actuator) checkCommand(ctx context.Context, command *domain.Command, timeout time.Duration) (*domain.Actuator, error) {
+	actuator, err := a.GetActuatorByID(ctx, command.ActuatorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCommand(actuator, command, timeout); err != nil {
+		return nil, err
+	}
+	if err := a.access.CheckSensorRole(ctx, actuator.SensorID, domain.SensorRoleOperator); err != nil {
+		return nil, err
+	}
+	return actuator, nil
+}
+
+func validateCommand(actuator *domain.Actuator, command *domain.Command, timeout time.Duration) error {
+	if timeout < 0 || timeout > MaxCommandTimeout {
+		return ErrInvalidCommand
+	}
+
+	switch command.Kind {
+	case domain.CommandSetState:
+		if !actuator.Type.IsValidState(command.State) {
+			return ErrInvalidCommand
+		}
+		command.Duration = 0
+	case domain.CommandPulse:
+		if command.Duration <= 0 || command.Duration > MaxPulseDuration || command.Duration >= timeout {
+			return ErrInvalidCommand
+		}
+		command.State = 0
+	default:
+		return ErrInvalidCommand
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_actuator_SendCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	valve := &domain.Actuator{ID: 2, SensorID: 1, Name: "valve", Type: domain.ActuatorTypeValve}
+
+	t.Run("fail, invalid command", func(t *testing.T) {
+		ctx := context.Background()
+
+		ar := NewMockActuatorRepository(ctrl)
+		ar.EXPECT().GetActuatorByID(ctx, int64(2)).AnyTimes().Return(valve, nil)
+
+		a := NewActuator(ar, nil, nil)
+
+		invalid := []struct {
+			command *domain.Command
+			timeout time.Duration
+		}{
+			{&domain.Command{ActuatorID: 2, Kind: "toggle"}, 0},
+			{&domain.Command{ActuatorID: 2, Kind: domain.CommandSetState, State: 101}, 0},
+			{&domain.Command{ActuatorID: 2, Kind: domain.CommandSetState, State: 50}, MaxCommandTimeout + time.Second},
+			{&domain.Command{ActuatorID: 2, Kind: domain.CommandPulse}, 0},
+			{&domain.Command{ActuatorID: 2, Kind: domain.CommandPulse, Duration: MaxPulseDuration + time.Second}, time.Hour},
+			{&domain.Command{ActuatorID: 2, Kind: domain.CommandPulse, Duration: time.Minute}, time.Minute},
+		}
+		for _, c := range invalid {
+			_, err := a.SendCommand(ctx, c.command, c.timeout)
+			assert.ErrorIs(t, err, ErrInvalidCommand)
+		}
+	})
+
+	t.Run("fail, viewer can't send commands", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 2})
+
+		ar := NewMockActuatorRepository(ctrl)
+		ar.EXPECT().GetActuatorByID(ctx, int64(2)).Times(1).Return(valve, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).AnyTimes().
+			Return([]domain.SensorOwner{{UserID: 2, SensorID: 1, Role: domain.SensorRoleViewer}}, nil)
+
+		a := NewActuator(ar, nil, nil, WithActuatorAccessPolicy(NewAccessPolicy(sor, nil, nil)))
+
+		_, err := a.SendCommand(ctx, &domain.Command{ActuatorID: 2, Kind: domain.CommandSetState, State: 50}, 0)
+		assert.ErrorIs(t, err, ErrInsufficientRole)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 1, IsAdmin: true})
+
+		ar := NewMockActuatorRepository(ctrl)
+		ar.EXPECT().GetActuatorByID(ctx, int64(2)).Times(1).Return(valve, nil)
+
+		cr := NewMockCommandRepository(ctrl)
+		cr.EXPECT().AddCommand(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, command *domain.Command) {
+			command.ID = 5
+		})
+
+		a := NewActuator(ar, cr, nil, WithActuatorClock(func() time.Time { return now }))
+
+		command, err := a.SendCommand(ctx, &domain.Command{ActuatorID: 2, Kind: domain.CommandSetState, State: 50,
+			Duration: time.Second}, 0)
+		require.NoError(t, err)
+		assert.Equal(t, &domain.Command{ID: 5, ActuatorID: 2, SensorID: 1, Kind: domain.CommandSetState, State: 50,
+			Status: domain.CommandPending, CreatedBy: 1, CreatedAt: now, ExpiresAt: now.Add(DefaultCommandTimeout)}, command)
+	})
+}
+
+func Test_actuator_FetchCommands(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("fail, unknown controller", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "1234567890").Times(1).Return(nil, ErrSensorNotFound)
+
+		a := NewActuator(nil, nil, sr)
+
+		_, err := a.FetchCommands(ctx, "1234567890")
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+
+	t.Run("ok, expired commands are skipped", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "1234567890").Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		delivered := now.Add(-time.Second)
+		expired := domain.Command{ID: 1, SensorID: 1, Status: domain.CommandPending, ExpiresAt: now}
+		redelivered := domain.Command{ID: 2, SensorID: 1, Status: domain.CommandDelivered, ExpiresAt: now.Add(time.Minute),
+			DeliveredAt: &delivered}
+		pending := domain.Command{ID: 3, SensorID: 1, Status: domain.CommandPending, ExpiresAt: now.Add(time.Minute)}
+
+		cr := NewMockCommandRepository(ctrl)
+		cr.EXPECT().GetOpenCommandsBySensorID(ctx, int64(1)).Times(1).
+			Return([]domain.Command{expired, redelivered, pending}, nil)
+		cr.EXPECT().UpdateCommandStatus(ctx, gomock.Any()).Times(2).DoAndReturn(func(_ context.Context, command *domain.Command) error {
+			switch command.ID {
+			case 1:
+				assert.Equal(t, domain.CommandExpired, command.Status)
+				assert.Equal(t, now, *command.CompletedAt)
+			case 3:
+				assert.Equal(t, domain.CommandDelivered, command.Status)
+				assert.Equal(t, now, *command.DeliveredAt)
+			}
+			return nil
+		})
+
+		a := NewActuator(nil, cr, sr, WithActuatorClock(func() time.Time { return now }))
+
+		commands, err := a.FetchCommands(ctx, "1234567890")
+		require.NoError(t, err)
+		require.Len(t, commands, 2)
+		assert.Equal(t, int64(2), commands[0].ID)
+		assert.Equal(t, int64(3), commands[1].ID)
+	})
+}
+
+func Test_actuator_AckCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	controller := &domain.Sensor{ID: 1, SerialNumber: "1234567890"}
+	open := func() *domain.Command {
+		return &domain.Command{ID: 5, ActuatorID: 2, SensorID: 1, Kind: domain.CommandSetState, State: 1,
+			Status: domain.CommandDelivered, ExpiresAt: now.Add(time.Minute)}
+	}
+
+	t.Run("fail, invalid status", func(t *testing.T) {
+		a := NewActuator(nil, nil, nil)
+		_, err := a.AckCommand(context.Background(), "1234567890", 5, domain.CommandExpired, "")
+		assert.ErrorIs(t, err, ErrInvalidCommand)
+	})
+
+	t.Run("fail, command of another controller", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "1234567890").Times(1).Return(controller, nil)
+
+		command := open()
+		command.SensorID = 3
+		cr := NewMockCommandRepository(ctrl)
+		cr.EXPECT().GetCommandByID(ctx, int64(5)).Times(1).Return(command, nil)
+
+		a := NewActuator(nil, cr, sr)
+
+		_, err := a.AckCommand(ctx, "1234567890", 5, domain.CommandAcked, "")
+		assert.ErrorIs(t, err, ErrCommandNotFound)
+	})
+
+	t.Run("fail, command expired", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "1234567890").Times(1).Return(controller, nil)
+
+		command := open()
+		command.ExpiresAt = now
+		cr := NewMockCommandRepository(ctrl)
+		cr.EXPECT().GetCommandByID(ctx, int64(5)).Times(1).Return(command, nil)
+		cr.EXPECT().UpdateCommandStatus(ctx, gomock.Any()).Times(1).Return(nil)
+
+		a := NewActuator(nil, cr, sr, WithActuatorClock(func() time.Time { return now }))
+
+		_, err := a.AckCommand(ctx, "1234567890", 5, domain.CommandAcked, "")
+		assert.ErrorIs(t, err, ErrCommandExpired)
+		assert.Equal(t, domain.CommandExpired, command.Status)
+	})
+
+	t.Run("ok, acked command sets actuator state", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "1234567890").Times(1).Return(controller, nil)
+
+		cr := NewMockCommandRepository(ctrl)
+		cr.EXPECT().GetCommandByID(ctx, int64(5)).Times(1).Return(open(), nil)
+		cr.EXPECT().UpdateCommandStatus(ctx, gomock.Any()).Times(1).Return(nil)
+
+		ar := NewMockActuatorRepository(ctrl)
+		ar.EXPECT().SetActuatorState(ctx, int64(2), int64(1)).Times(1).Return(nil)
+
+		a := NewActuator(ar, cr, sr, WithActuatorClock(func() time.Time { return now }))
+
+		command, err := a.AckCommand(ctx, "1234567890", 5, domain.CommandAcked, "ignored")
+		require.NoError(t, err)
+		assert.Equal(t, domain.CommandAcked, command.Status)
+		assert.Equal(t, now, *command.CompletedAt)
+		assert.Equal(t, now, *command.DeliveredAt)
+		assert.Empty(t, command.Error)
+	})
+
+	t.Run("ok, failed command keeps actuator state", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "1234567890").Times(1).Return(controller, nil)
+
+		cr := NewMockCommandRepository(ctrl)
+		cr.EXPECT().GetCommandByID(ctx, int64(5)).Times(1).Return(open(), nil)
+		cr.EXPECT().UpdateCommandStatus(ctx, gomock.Any()).Times(1).Return(nil)
+
+		a := NewActuator(nil, cr, sr, WithActuatorClock(func() time.Time { return now }))
+
+		command, err := a.AckCommand(ctx, "1234567890", 5, domain.CommandFailed, " relay stuck ")
+		require.NoError(t, err)
+		assert.Equal(t, domain.CommandFailed, command.Status)
+		assert.Equal(t, "relay stuck", command.Error)
+	})
+}
@@ -0,0 +1,414 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAlertsPageSize - размер страницы списка оповещений, если он не задан
+	DefaultAlertsPageSize = 50
+	// MaxAlertsPageSize - максимальный размер страницы списка оповещений
+	MaxAlertsPageSize = 100
+	// alertSubscriberBuffer - сколько изменений может ждать отправки подписчику, более новые отбрасываются
+	alertSubscriberBuffer = 16
+)
+
+// Alert - оповещения правил и автоматизаций. Просматривать оповещения может любой, кому доступен датчик,
+// подтверждать и закрывать - операторы и владельцы датчика.
+//
+// Оповещения создаются и закрываются автоматически при вычислении правил (Rule): пока оповещение не закрыто,
+// повторные срабатывания правила учитываются в нем, а когда условие перестает выполняться, оповещение закрывается.
+// Оповещения автоматизаций (Automation) создаются так же, но закрываются только пользователем.
+// Оповещения об аномалиях (Anomaly) создаются по аномальным событиям датчика и закрываются первым нормальным.
+// Каждое изменение рассылается подписчикам SubscribeAlerts.
+type Alert struct {
+	alertRepo  AlertRepository
+	access     *AccessPolicy
+	transactor Transactor
+	audit      *Audit
+	webhooks   *Webhook
+	sensorRepo SensorRepository
+	now        func() time.Time
+
+	mu          sync.Mutex
+	subscribers map[chan domain.Alert]struct{}
+}
+
+func NewAlert(ar AlertRepository, options ...func(*Alert)) *Alert {
+	a := &Alert{
+		alertRepo:   ar,
+		transactor:  noTransaction{},
+		now:         time.Now,
+		subscribers: make(map[chan domain.Alert]struct{}),
+	}
+
+	for _, o := range options {
+		o(a)
+	}
+
+	return a
+}
+
+// WithAlertAccessPolicy ограничивает оповещения оповещениями доступных пользователю из контекста датчиков
+func WithAlertAccessPolicy(p *AccessPolicy) func(*Alert) {
+	return func(a *Alert) {
+		a.access = p
+	}
+}
+
+// WithAlertTransactor задает транзакцию для изменения оповещения вместе с записью в журнал аудита
+func WithAlertTransactor(t Transactor) func(*Alert) {
+	return func(a *Alert) {
+		a.transactor = t
+	}
+}
+
+// WithAlertAudit записывает подтверждение и закрытие оповещений пользователями в журнал аудита
+func WithAlertAudit(au *Audit) func(*Alert) {
+	return func(a *Alert) {
+		a.audit = au
+	}
+}
+
+// WithAlertWebhooks отправляет каждое изменение оповещения подходящим подпискам
+func WithAlertWebhooks(w *Webhook) func(*Alert) {
+	return func(a *Alert) {
+		a.webhooks = w
+	}
+}
+
+// WithAlertFlappingSuppression не создает оповещений и не учитывает срабатываний по датчикам,
+// помеченным как нестабильные
+func WithAlertFlappingSuppression(sr SensorRepository) func(*Alert) {
+	return func(a *Alert) {
+		a.sensorRepo = sr
+	}
+}
+
+// WithAlertClock подменяет источник текущего времени, используется в тестах
+func WithAlertClock(now func() time.Time) func(*Alert) {
+	return func(a *Alert) {
+		a.now = now
+	}
+}
+
+// GetAlerts возвращает страницу оповещений доступных датчиков, подходящих под фильтр, от новых к старым
+func (a *Alert) GetAlerts(ctx context.Context, filter domain.AlertFilter) ([]domain.Alert, error) {
+	if filter.Status != "" && !filter.Status.IsValid() || filter.RuleID < 0 || filter.SensorID < 0 {
+		return nil, ErrInvalidAlertFilter
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultAlertsPageSize
+	}
+	if filter.Limit < 0 || filter.Limit > MaxAlertsPageSize || filter.Offset < 0 {
+		return nil, ErrInvalidPagination
+	}
+
+	filter.SensorIDs = nil
+	if caller, ok := a.access.restricted(ctx); ok {
+		roles, err := a.access.SensorRoles(ctx, caller.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(roles) == 0 {
+			return []domain.Alert{}, nil
+		}
+		filter.SensorIDs = make([]int64, 0, len(roles))
+		for sensorID := range roles {
+			filter.SensorIDs = append(filter.SensorIDs, sensorID)
+		}
+		slices.Sort(filter.SensorIDs)
+	}
+
+	return a.alertRepo.GetAlerts(ctx, filter)
+}
+
+// GetAlertByID возвращает оповещение. Оповещение недоступного датчика неотличимо от несуществующего.
+func (a *Alert) GetAlertByID(ctx context.Context, id int64) (*domain.Alert, error) {
+	alert, err := a.alertRepo.GetAlertByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if alert == nil {
+		return nil, ErrAlertNotFound
+	}
+
+	if err := a.access.CheckSensor(ctx, alert.SensorID); err != nil {
+		if errors.Is(err, ErrSensorNotFound) {
+			return nil, ErrAlertNotFound
+		}
+		return nil, err
+	}
+
+	return alert, nil
+}
+
+// AcknowledgeAlert подтверждает оповещение. Повторное подтверждение ничего не меняет,
+// подтвердить закрытое оповещение нельзя.
+func (a *Alert) AcknowledgeAlert(ctx context.Context, id int64) (*domain.Alert, error) {
+	return a.changeStatus(ctx, id, domain.AlertStatusAcknowledged, domain.AuditActionAcknowledge,
+		func(alert *domain.Alert, callerID int64, now time.Time) {
+			alert.AcknowledgedAt = &now
+			alert.AcknowledgedBy = callerID
+		})
+}
+
+// ResolveAlert закрывает оповещение. Следующее срабатывание правила создаст новое оповещение.
+func (a *Alert) ResolveAlert(ctx context.Context, id int64) (*domain.Alert, error) {
+	return a.changeStatus(ctx, id, domain.AlertStatusResolved, domain.AuditActionResolve,
+		func(alert *domain.Alert, callerID int64, now time.Time) {
+			alert.ResolvedAt = &now
+			alert.ResolvedBy = callerID
+		})
+}
+
+// SubscribeAlerts возвращает канал изменений оповещений доступных пользователю из контекста датчиков.
+// Канал закрывается, когда завершается ctx. Если подписчик не успевает читать, часть изменений пропускается.
+func (a *Alert) SubscribeAlerts(ctx context.Context) <-chan domain.Alert {
+	in := make(chan domain.Alert, alertSubscriberBuffer)
+	out := make(chan domain.Alert)
+
+	a.mu.Lock()
+	a.subscribers[in] = struct{}{}
+	a.mu.Unlock()
+
+	go func() {
+		defer close(out)
+		defer func() {
+			a.mu.Lock()
+			delete(a.subscribers, in)
+			a.mu.Unlock()
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case alert := <-in:
+				if err := a.access.CheckSensor(ctx, alert.SensorID); err != nil {
+					continue
+				}
+				select {
+				case out <- alert:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+func (a *Alert) changeStatus(ctx context.Context, id int64, status domain.AlertStatus, action domain.AuditAction,
+	update func(alert *domain.Alert, callerID int64, now time.Time)) (*domain.Alert, error) {
+	alert, err := a.GetAlertByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.access.CheckSensorRole(ctx, alert.SensorID, domain.SensorRoleOperator); err != nil {
+		return nil, err
+	}
+	if !alert.IsActive() {
+		return nil, ErrAlertResolved
+	}
+	if alert.Status == status {
+		return alert, nil
+	}
+
+	changed := *alert
+	changed.Status = status
+	var callerID int64
+	if caller, ok := UserFromContext(ctx); ok {
+		callerID = caller.ID
+	}
+	update(&changed, callerID, a.now())
+
+	err = a.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := a.alertRepo.UpdateAlertStatus(ctx, &changed); err != nil {
+			return err
+		}
+		if err := a.webhooks.enqueueAlert(ctx, &changed); err != nil {
+			return err
+		}
+		return a.audit.record(ctx, action, domain.AuditEntityAlert, changed.ID, alert, &changed)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.publish(changed)
+	return &changed, nil
+}
+
+// raise учитывает срабатывание правила: увеличивает число срабатываний незакрытого оповещения правила
+// или, если его нет, создает новое. Вызывается в транзакции вычисления правила.
+func (a *Alert) raise(ctx context.Context, rule *domain.Rule, value float64, now time.Time) (*domain.Alert, error) {
+	if suppressed, err := a.suppressed(ctx, rule.SensorID); err != nil || suppressed {
+		return nil, err
+	}
+
+	alert, err := a.alertRepo.GetActiveAlertByRuleID(ctx, rule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.occur(ctx, alert, &domain.Alert{RuleID: rule.ID, SensorID: rule.SensorID}, value, now)
+}
+
+// raiseAutomation учитывает срабатывание действия ActionAlert автоматизации, запущенной событием датчика sensorID.
+// Оповещение автоматизации закрывает только пользователь.
+func (a *Alert) raiseAutomation(ctx context.Context, automation *domain.Automation, sensorID int64, value float64) error {
+	if suppressed, err := a.suppressed(ctx, sensorID); err != nil || suppressed {
+		return err
+	}
+
+	var alert *domain.Alert
+	err := a.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		active, err := a.alertRepo.GetActiveAlertByAutomationID(ctx, automation.ID)
+		if err != nil {
+			return err
+		}
+
+		alert, err = a.occur(ctx, active, &domain.Alert{AutomationID: automation.ID, SensorID: sensorID}, value, a.now())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	a.publish(*alert)
+	return nil
+}
+
+// suppressed сообщает, подавляются ли оповещения по датчику, потому что он нестабилен
+func (a *Alert) suppressed(ctx context.Context, sensorID int64) (bool, error) {
+	if a.sensorRepo == nil {
+		return false, nil
+	}
+
+	sensor, err := a.sensorRepo.GetSensorByID(ctx, sensorID)
+	if errors.Is(err, ErrSensorNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return sensor != nil && sensor.Flapping, nil
+}
+
+// occur учитывает срабатывание в незакрытом оповещении alert или, если его нет, создает оповещение
+// по образцу template
+func (a *Alert) occur(ctx context.Context, alert, template *domain.Alert, value float64, now time.Time) (*domain.Alert, error) {
+	if alert == nil {
+		alert = template
+		alert.Status = domain.AlertStatusOpen
+		alert.Value = value
+		alert.Occurrences = 1
+		alert.CreatedAt = now
+		alert.LastOccurredAt = now
+		if err := a.alertRepo.SaveAlert(ctx, alert); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := a.alertRepo.AddAlertOccurrence(ctx, alert.ID, value, now); err != nil {
+			return nil, err
+		}
+		alert.Occurrences++
+		alert.Value = value
+		alert.LastOccurredAt = now
+	}
+
+	if err := a.webhooks.enqueueAlert(ctx, alert); err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// raiseAnomaly учитывает аномальное значение value датчика sensorID в его оповещении об аномалиях
+func (a *Alert) raiseAnomaly(ctx context.Context, sensorID int64, value float64) error {
+	if suppressed, err := a.suppressed(ctx, sensorID); err != nil || suppressed {
+		return err
+	}
+
+	var alert *domain.Alert
+	err := a.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		active, err := a.alertRepo.GetActiveAnomalyAlert(ctx, sensorID)
+		if err != nil {
+			return err
+		}
+
+		alert, err = a.occur(ctx, active, &domain.Alert{Anomaly: true, SensorID: sensorID}, value, a.now())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	a.publish(*alert)
+	return nil
+}
+
+// resolveAnomaly закрывает незакрытое оповещение об аномалиях датчика, если оно есть
+func (a *Alert) resolveAnomaly(ctx context.Context, sensorID int64) error {
+	var alert *domain.Alert
+	err := a.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		active, err := a.alertRepo.GetActiveAnomalyAlert(ctx, sensorID)
+		if err != nil || active == nil {
+			return err
+		}
+
+		alert, err = a.resolve(ctx, active, a.now())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if alert != nil {
+		a.publish(*alert)
+	}
+	return nil
+}
+
+// autoResolve закрывает незакрытое оповещение правила, nil - такого нет
+func (a *Alert) autoResolve(ctx context.Context, ruleID int64, now time.Time) (*domain.Alert, error) {
+	alert, err := a.alertRepo.GetActiveAlertByRuleID(ctx, ruleID)
+	if err != nil || alert == nil {
+		return nil, err
+	}
+
+	return a.resolve(ctx, alert, now)
+}
+
+// resolve закрывает незакрытое оповещение без участия пользователя
+func (a *Alert) resolve(ctx context.Context, alert *domain.Alert, now time.Time) (*domain.Alert, error) {
+	alert.Status = domain.AlertStatusResolved
+	alert.ResolvedAt = &now
+	alert.ResolvedBy = 0
+	if err := a.alertRepo.UpdateAlertStatus(ctx, alert); err != nil {
+		return nil, err
+	}
+	if err := a.webhooks.enqueueAlert(ctx, alert); err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// publish рассылает изменение оповещения подписчикам, не дожидаясь их
+func (a *Alert) publish(alert domain.Alert) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for in := range a.subscribers {
+		select {
+		case in <- alert:
+		default:
+		}
+	}
+}
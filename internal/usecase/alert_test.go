@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_alert_GetAlerts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, invalid filter", func(t *testing.T) {
+		a := NewAlert(nil)
+		ctx := context.Background()
+
+		_, err := a.GetAlerts(ctx, domain.AlertFilter{Status: "closed"})
+		assert.ErrorIs(t, err, ErrInvalidAlertFilter)
+
+		_, err = a.GetAlerts(ctx, domain.AlertFilter{Limit: MaxAlertsPageSize + 1})
+		assert.ErrorIs(t, err, ErrInvalidPagination)
+	})
+
+	t.Run("ok, restricted to accessible sensors", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 2})
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Times(1).Return([]domain.SensorOwner{
+			{UserID: 2, SensorID: 5, Role: domain.SensorRoleViewer},
+			{UserID: 2, SensorID: 1, Role: domain.SensorRoleOwner},
+		}, nil)
+
+		ar := NewMockAlertRepository(ctrl)
+		ar.EXPECT().GetAlerts(ctx, domain.AlertFilter{Status: domain.AlertStatusOpen, SensorIDs: []int64{1, 5}, Limit: DefaultAlertsPageSize}).
+			Times(1).Return([]domain.Alert{{ID: 1, SensorID: 1}}, nil)
+
+		a := NewAlert(ar, WithAlertAccessPolicy(NewAccessPolicy(sor, nil, nil)))
+		alerts, err := a.GetAlerts(ctx, domain.AlertFilter{Status: domain.AlertStatusOpen, SensorIDs: []int64{9}})
+		require.NoError(t, err)
+		assert.Len(t, alerts, 1)
+	})
+}
+
+func Test_alert_AcknowledgeAlert(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("fail, viewer can't acknowledge", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 2})
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Times(2).
+			Return([]domain.SensorOwner{{UserID: 2, SensorID: 1, Role: domain.SensorRoleViewer}}, nil)
+
+		ar := NewMockAlertRepository(ctrl)
+		ar.EXPECT().GetAlertByID(ctx, int64(7)).Times(1).
+			Return(&domain.Alert{ID: 7, SensorID: 1, Status: domain.AlertStatusOpen}, nil)
+
+		a := NewAlert(ar, WithAlertAccessPolicy(NewAccessPolicy(sor, nil, nil)))
+		_, err := a.AcknowledgeAlert(ctx, 7)
+		assert.ErrorIs(t, err, ErrInsufficientRole)
+	})
+
+	t.Run("fail, alert resolved", func(t *testing.T) {
+		ctx := context.Background()
+
+		ar := NewMockAlertRepository(ctrl)
+		ar.EXPECT().GetAlertByID(ctx, int64(7)).Times(1).
+			Return(&domain.Alert{ID: 7, SensorID: 1, Status: domain.AlertStatusResolved}, nil)
+
+		_, err := NewAlert(ar).AcknowledgeAlert(ctx, 7)
+		assert.ErrorIs(t, err, ErrAlertResolved)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctx := ContextWithUser(context.Background(), &domain.User{ID: 1, IsAdmin: true})
+
+		ar := NewMockAlertRepository(ctrl)
+		ar.EXPECT().GetAlertByID(ctx, int64(7)).Times(1).
+			Return(&domain.Alert{ID: 7, SensorID: 1, Status: domain.AlertStatusOpen}, nil)
+		ar.EXPECT().UpdateAlertStatus(ctx, &domain.Alert{ID: 7, SensorID: 1, Status: domain.AlertStatusAcknowledged,
+			AcknowledgedAt: &now, AcknowledgedBy: 1}).Times(1).Return(nil)
+
+		a := NewAlert(ar, WithAlertClock(func() time.Time { return now }))
+		alert, err := a.AcknowledgeAlert(ctx, 7)
+		require.NoError(t, err)
+		assert.Equal(t, domain.AlertStatusAcknowledged, alert.Status)
+	})
+}
+
+func Test_alert_SubscribeAlerts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = ContextWithUser(ctx, &domain.User{ID: 2})
+
+	sor := NewMockSensorOwnerRepository(ctrl)
+	sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).AnyTimes().
+		Return([]domain.SensorOwner{{UserID: 2, SensorID: 1, Role: domain.SensorRoleViewer}}, nil)
+
+	a := NewAlert(nil, WithAlertAccessPolicy(NewAccessPolicy(sor, nil, nil)))
+	updates := a.SubscribeAlerts(ctx)
+
+	a.publish(domain.Alert{ID: 1, SensorID: 2})
+	a.publish(domain.Alert{ID: 2, SensorID: 1})
+	assert.Equal(t, int64(2), (<-updates).ID, "alerts of other sensors are skipped")
+
+	cancel()
+	_, ok := <-updates
+	assert.False(t, ok)
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"sync"
+	"time"
+)
+
+// Anomaly - обнаружение аномальных значений датчиков АЦП, для которых заданы настройки AnomalyDetection.
+//
+// Каждое событие такого датчика получает оценку аномальности по скользящей статистике его значений,
+// оценка сохраняется вместе с событием. Аномальное событие учитывается в оповещении об аномалиях датчика,
+// первое нормальное закрывает его.
+//
+// Статистика ведется в памяти и сохраняется в репозиторий периодически (Checkpoint), поэтому после
+// перезапуска теряются только значения, учтенные после последнего сохранения.
+type Anomaly struct {
+	stateRepo AnomalyStateRepository
+	alerts    *Alert
+	now       func() time.Time
+
+	mu     sync.Mutex
+	states map[int64]*domain.AnomalyState
+	// dirty - датчики, статистика которых изменилась после последнего сохранения
+	dirty map[int64]struct{}
+}
+
+func NewAnomaly(ar AnomalyStateRepository, alerts *Alert, options ...func(*Anomaly)) *Anomaly {
+	a := &Anomaly{
+		stateRepo: ar,
+		alerts:    alerts,
+		now:       time.Now,
+		states:    make(map[int64]*domain.AnomalyState),
+		dirty:     make(map[int64]struct{}),
+	}
+
+	for _, o := range options {
+		o(a)
+	}
+
+	return a
+}
+
+// WithAnomalyClock подменяет источник текущего времени, используется в тестах
+func WithAnomalyClock(now func() time.Time) func(*Anomaly) {
+	return func(a *Anomaly) {
+		a.now = now
+	}
+}
+
+// score оценивает событие датчика по статистике, не меняя ее. Оценка записывается в событие,
+// у датчика без настроек обнаружения событие не меняется.
+func (a *Anomaly) score(ctx context.Context, sensor *domain.Sensor, event *domain.Event) error {
+	if sensor.AnomalyDetection == nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state, ok := a.states[sensor.ID]
+	if !ok {
+		stored, err := a.stateRepo.GetAnomalyState(ctx, sensor.ID)
+		if err != nil {
+			return err
+		}
+		state = stored
+		if state == nil {
+			state = &domain.AnomalyState{SensorID: sensor.ID}
+		}
+		a.states[sensor.ID] = state
+	}
+
+	next := *state
+	score, scored := sensor.AnomalyDetection.Detect(&next, eventValue(sensor, event))
+
+	if scored {
+		event.AnomalyScore = &score
+		event.Anomalous = sensor.AnomalyDetection.IsAnomalous(score)
+	}
+	return nil
+}
+
+// observe учитывает значение сохраненного события в статистике датчика. Вызывается после фиксации транзакции,
+// чтобы событие, которое не удалось сохранить, не попало в статистику.
+func (a *Anomaly) observe(sensor *domain.Sensor, event *domain.Event) {
+	if sensor.AnomalyDetection == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// статистики нет, если обнаружение выключили, пока событие сохранялось
+	state, ok := a.states[sensor.ID]
+	if !ok {
+		return
+	}
+
+	sensor.AnomalyDetection.Detect(state, eventValue(sensor, event))
+	state.UpdatedAt = a.now()
+	a.dirty[sensor.ID] = struct{}{}
+}
+
+// evaluateEvent ведет оповещение об аномалиях по сохраненному оцененному событию
+func (a *Anomaly) evaluateEvent(ctx context.Context, sensor *domain.Sensor, event *domain.Event) error {
+	if event.AnomalyScore == nil {
+		return nil
+	}
+	if event.Anomalous {
+		return a.alerts.raiseAnomaly(ctx, sensor.ID, eventValue(sensor, event))
+	}
+	return a.alerts.resolveAnomaly(ctx, sensor.ID)
+}
+
+// disable забывает статистику датчика, у которого выключено обнаружение аномалий, и закрывает его оповещение
+func (a *Anomaly) disable(ctx context.Context, sensorID int64) error {
+	a.mu.Lock()
+	delete(a.states, sensorID)
+	delete(a.dirty, sensorID)
+	a.mu.Unlock()
+
+	if err := a.stateRepo.DeleteAnomalyState(ctx, sensorID); err != nil {
+		return err
+	}
+	return a.alerts.resolveAnomaly(ctx, sensorID)
+}
+
+// Checkpoint сохраняет статистику, изменившуюся после предыдущего сохранения. Вызывается периодически
+// и при остановке сервиса.
+func (a *Anomaly) Checkpoint(ctx context.Context) error {
+	a.mu.Lock()
+	states := make([]domain.AnomalyState, 0, len(a.dirty))
+	for sensorID := range a.dirty {
+		states = append(states, *a.states[sensorID])
+	}
+	a.dirty = make(map[int64]struct{})
+	a.mu.Unlock()
+
+	if len(states) == 0 {
+		return nil
+	}
+
+	if err := a.stateRepo.SaveAnomalyStates(ctx, states); err != nil {
+		// несохраненная статистика сохранится при следующем вызове
+		a.mu.Lock()
+		for _, state := range states {
+			if _, ok := a.states[state.SensorID]; ok {
+				a.dirty[state.SensorID] = struct{}{}
+			}
+		}
+		a.mu.Unlock()
+		return err
+	}
+	return nil
+}
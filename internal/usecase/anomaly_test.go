@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_anomaly_ReceiveEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	detection := &domain.AnomalyDetection{Alpha: 0.1, Sigma: 3, WarmUp: 10}
+	// среднее 100, стандартное отклонение 2
+	warm := domain.AnomalyState{SensorID: 1, Mean: 100, Variance: 4, Count: 10}
+
+	setup := func(ctx context.Context, state *domain.AnomalyState) (*MockEventRepository, *MockAlertRepository, *Event) {
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
+			ID: 1, Type: domain.SensorTypeADC, AnomalyDetection: detection,
+		}, nil)
+		sr.EXPECT().UpdateSensorState(ctx, gomock.Any()).Times(1).Return(nil)
+		asr := NewMockAnomalyStateRepository(ctrl)
+		asr.EXPECT().GetAnomalyState(ctx, int64(1)).Times(1).Return(state, nil)
+		er := NewMockEventRepository(ctrl)
+		ar := NewMockAlertRepository(ctrl)
+
+		a := NewAnomaly(asr, NewAlert(ar, WithAlertClock(func() time.Time { return now })),
+			WithAnomalyClock(func() time.Time { return now }))
+		return er, ar, NewEvent(er, sr, WithEventAnomalies(a))
+	}
+	receive := func(ctx context.Context, e *Event, payload int64) error {
+		return e.ReceiveEvent(ctx, &domain.Event{Timestamp: now, SensorSerialNumber: "0123456789", Payload: payload})
+	}
+
+	t.Run("ok, statistics are accumulated without score", func(t *testing.T) {
+		ctx := context.Background()
+		er, _, e := setup(ctx, nil)
+
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, event *domain.Event) {
+			assert.Nil(t, event.AnomalyScore)
+			assert.False(t, event.Anomalous)
+		})
+
+		require.NoError(t, receive(ctx, e, 100))
+	})
+
+	t.Run("ok, anomalous event raises alert", func(t *testing.T) {
+		ctx := context.Background()
+		state := warm
+		er, ar, e := setup(ctx, &state)
+
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, event *domain.Event) {
+			require.NotNil(t, event.AnomalyScore)
+			assert.Equal(t, 10.0, *event.AnomalyScore)
+			assert.True(t, event.Anomalous)
+		})
+		ar.EXPECT().GetActiveAnomalyAlert(ctx, int64(1)).Times(1).Return(nil, nil)
+		ar.EXPECT().SaveAlert(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, alert *domain.Alert) {
+			assert.True(t, alert.Anomaly)
+			assert.Equal(t, int64(1), alert.SensorID)
+			assert.Zero(t, alert.RuleID)
+			assert.Equal(t, 120.0, alert.Value)
+		})
+
+		require.NoError(t, receive(ctx, e, 120))
+	})
+
+	t.Run("ok, normal event resolves alert", func(t *testing.T) {
+		ctx := context.Background()
+		state := warm
+		er, ar, e := setup(ctx, &state)
+
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, event *domain.Event) {
+			require.NotNil(t, event.AnomalyScore)
+			assert.Equal(t, 0.5, *event.AnomalyScore)
+			assert.False(t, event.Anomalous)
+		})
+		ar.EXPECT().GetActiveAnomalyAlert(ctx, int64(1)).Times(1).
+			Return(&domain.Alert{ID: 5, Anomaly: true, SensorID: 1, Status: domain.AlertStatusOpen}, nil)
+		ar.EXPECT().UpdateAlertStatus(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, alert *domain.Alert) {
+			assert.Equal(t, domain.AlertStatusResolved, alert.Status)
+		})
+
+		require.NoError(t, receive(ctx, e, 101))
+	})
+
+	t.Run("err, state is not loaded", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
+			ID: 1, Type: domain.SensorTypeADC, AnomalyDetection: detection,
+		}, nil)
+		asr := NewMockAnomalyStateRepository(ctrl)
+		expectedError := errors.New("some error")
+		asr.EXPECT().GetAnomalyState(ctx, int64(1)).Times(1).Return(nil, expectedError)
+
+		e := NewEvent(nil, sr, WithEventAnomalies(NewAnomaly(asr, NewAlert(nil))))
+
+		assert.ErrorIs(t, receive(ctx, e, 100), expectedError)
+	})
+
+	t.Run("err, statistics are kept when sensor is not saved", func(t *testing.T) {
+		ctx := context.Background()
+		state := warm
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
+			ID: 1, Type: domain.SensorTypeADC, AnomalyDetection: detection,
+		}, nil)
+		expectedError := errors.New("some error")
+		sr.EXPECT().UpdateSensorState(ctx, gomock.Any()).Times(1).Return(expectedError)
+		asr := NewMockAnomalyStateRepository(ctrl)
+		asr.EXPECT().GetAnomalyState(ctx, int64(1)).Times(1).Return(&state, nil)
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Return(nil)
+
+		a := NewAnomaly(asr, NewAlert(nil), WithAnomalyClock(func() time.Time { return now }))
+		e := NewEvent(er, sr, WithEventAnomalies(a))
+
+		assert.ErrorIs(t, receive(ctx, e, 120), expectedError)
+		assert.Equal(t, warm, state)
+		require.NoError(t, a.Checkpoint(ctx), "nothing to save")
+	})
+}
+
+func Test_anomaly_Checkpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	sensor := &domain.Sensor{ID: 1, Type: domain.SensorTypeADC, AnomalyDetection: &domain.AnomalyDetection{Alpha: 0.5, Sigma: 3, WarmUp: 10}}
+
+	asr := NewMockAnomalyStateRepository(ctrl)
+	asr.EXPECT().GetAnomalyState(ctx, int64(1)).Times(1).Return(nil, nil)
+	a := NewAnomaly(asr, NewAlert(nil), WithAnomalyClock(func() time.Time { return now }))
+
+	require.NoError(t, a.Checkpoint(ctx), "nothing to save")
+
+	for _, payload := range []int64{10, 20} {
+		event := &domain.Event{Payload: payload}
+		require.NoError(t, a.score(ctx, sensor, event))
+		a.observe(sensor, event)
+	}
+
+	expectedError := errors.New("some error")
+	asr.EXPECT().SaveAnomalyStates(ctx, gomock.Any()).Times(1).Return(expectedError)
+	assert.ErrorIs(t, a.Checkpoint(ctx), expectedError)
+
+	asr.EXPECT().SaveAnomalyStates(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, states []domain.AnomalyState) error {
+		assert.Equal(t, []domain.AnomalyState{{SensorID: 1, Mean: 15, Variance: 25, Count: 2, UpdatedAt: now}}, states)
+		return nil
+	})
+	require.NoError(t, a.Checkpoint(ctx), "retry after failure")
+	require.NoError(t, a.Checkpoint(ctx), "saved state is not saved again")
+}
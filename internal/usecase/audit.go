@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"homework/internal/domain"
+	"time"
+)
+
+const (
+	// DefaultAuditPageSize - размер страницы журнала аудита, если он не задан
+	DefaultAuditPageSize = 50
+	// MaxAuditPageSize - максимальный размер страницы журнала аудита
+	MaxAuditPageSize = 100
+)
+
+// RequestInfo - сведения о запросе, которые попадают в журнал аудита
+type RequestInfo struct {
+	// ID - id запроса
+	ID string
+	// SourceIP - адрес, с которого пришел запрос
+	SourceIP string
+}
+
+type requestInfoKey struct{}
+
+// ContextWithRequestInfo возвращает контекст со сведениями о запросе
+func ContextWithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFromContext возвращает сведения о запросе, положенные в контекст ContextWithRequestInfo
+func RequestInfoFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info
+}
+
+// Audit - журнал аудита изменяющих операций. Сценарии пишут в него через свои опции With...Audit,
+// без журнала изменения не записываются. Поступающие от датчиков события в журнал не попадают:
+// это данные, а не изменения конфигурации.
+type Audit struct {
+	auditRepo AuditRepository
+	access    *AccessPolicy
+	now       func() time.Time
+}
+
+func NewAudit(ar AuditRepository, options ...func(*Audit)) *Audit {
+	a := &Audit{
+		auditRepo: ar,
+		now:       time.Now,
+	}
+
+	for _, o := range options {
+		o(a)
+	}
+
+	return a
+}
+
+// WithAuditAccessPolicy показывает пользователю без прав администратора только его собственные изменения
+func WithAuditAccessPolicy(p *AccessPolicy) func(*Audit) {
+	return func(a *Audit) {
+		a.access = p
+	}
+}
+
+// WithAuditClock подменяет источник текущего времени, используется в тестах
+func WithAuditClock(now func() time.Time) func(*Audit) {
+	return func(a *Audit) {
+		a.now = now
+	}
+}
+
+// GetAuditEntries возвращает страницу записей журнала, подходящих под фильтр, от новых к старым
+func (a *Audit) GetAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	if filter.Limit == 0 {
+		filter.Limit = DefaultAuditPageSize
+	}
+	if filter.Limit < 0 || filter.Limit > MaxAuditPageSize || filter.Offset < 0 {
+		return nil, ErrInvalidPagination
+	}
+
+	if caller, ok := a.access.restricted(ctx); ok {
+		if filter.ActorID != 0 && filter.ActorID != caller.ID {
+			return []domain.AuditEntry{}, nil
+		}
+		filter.ActorID = caller.ID
+	}
+
+	return a.auditRepo.GetAuditEntries(ctx, filter)
+}
+
+// record записывает изменение сущности. before и after - снимки сущности до и после изменения,
+// в запись попадают только различающиеся поля. Секреты в снимки передавать нельзя.
+func (a *Audit) record(ctx context.Context, action domain.AuditAction, entityType domain.AuditEntityType, entityID int64, before, after any) error {
+	if a == nil {
+		return nil
+	}
+
+	changes, err := domain.AuditDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	info := RequestInfoFromContext(ctx)
+	entry := &domain.AuditEntry{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Changes:    changes,
+		RequestID:  info.ID,
+		SourceIP:   info.SourceIP,
+		CreatedAt:  a.now(),
+	}
+	if caller, ok := UserFromContext(ctx); ok {
+		entry.ActorID = caller.ID
+	}
+
+	return a.auditRepo.SaveAuditEntry(ctx, entry)
+}
+
+// auditLink возвращает снимок привязки для записи на сущность, к которой привязывают: "kind:id" -> value.
+// Так в списке изменений видно, какая именно привязка появилась, пропала или сменила роль.
+func auditLink(kind string, id int64, value any) map[string]any {
+	return map[string]any{fmt.Sprintf("%s:%d", kind, id): value}
+}
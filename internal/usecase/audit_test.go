@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_audit_record(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("ok, nil audit records nothing", func(t *testing.T) {
+		var a *Audit
+		assert.NoError(t, a.record(context.Background(), domain.AuditActionCreate, domain.AuditEntityHome, 1, nil, &domain.Home{ID: 1}))
+	})
+
+	t.Run("ok, actor and request info are recorded", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 7})
+		ctx = ContextWithRequestInfo(ctx, RequestInfo{ID: "req-1", SourceIP: "10.0.0.1"})
+
+		var saved *domain.AuditEntry
+		ar := NewMockAuditRepository(ctrl)
+		ar.EXPECT().SaveAuditEntry(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, entry *domain.AuditEntry) {
+			saved = entry
+		})
+
+		a := NewAudit(ar, WithAuditClock(func() time.Time { return now }))
+
+		err := a.record(ctx, domain.AuditActionUpdate, domain.AuditEntityHome, 1,
+			&domain.Home{ID: 1, Name: "old"}, &domain.Home{ID: 1, Name: "new"})
+		require.NoError(t, err)
+		assert.Equal(t, &domain.AuditEntry{
+			ActorID:    7,
+			Action:     domain.AuditActionUpdate,
+			EntityType: domain.AuditEntityHome,
+			EntityID:   1,
+			Changes:    map[string]domain.AuditChange{"Name": {Before: "old", After: "new"}},
+			RequestID:  "req-1",
+			SourceIP:   "10.0.0.1",
+			CreatedAt:  now,
+		}, saved)
+	})
+
+	t.Run("ok, link snapshot", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var saved *domain.AuditEntry
+		ar := NewMockAuditRepository(ctrl)
+		ar.EXPECT().SaveAuditEntry(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, entry *domain.AuditEntry) {
+			saved = entry
+		})
+
+		a := NewAudit(ar)
+
+		err := a.record(ctx, domain.AuditActionDetach, domain.AuditEntitySensor, 1, auditLink("user", 2, domain.SensorRoleViewer), nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), saved.ActorID)
+		assert.Equal(t, map[string]domain.AuditChange{"user:2": {Before: "viewer"}}, saved.Changes)
+	})
+}
+
+func Test_audit_GetAuditEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, invalid pagination", func(t *testing.T) {
+		a := NewAudit(nil)
+
+		_, err := a.GetAuditEntries(context.Background(), domain.AuditFilter{Limit: MaxAuditPageSize + 1})
+		assert.ErrorIs(t, err, ErrInvalidPagination)
+
+		_, err = a.GetAuditEntries(context.Background(), domain.AuditFilter{Offset: -1})
+		assert.ErrorIs(t, err, ErrInvalidPagination)
+	})
+
+	t.Run("ok, default page size", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ar := NewMockAuditRepository(ctrl)
+		ar.EXPECT().GetAuditEntries(ctx, domain.AuditFilter{EntityType: domain.AuditEntityHome, Limit: DefaultAuditPageSize}).
+			Times(1).Return([]domain.AuditEntry{{ID: 1}}, nil)
+
+		a := NewAudit(ar)
+
+		entries, err := a.GetAuditEntries(ctx, domain.AuditFilter{EntityType: domain.AuditEntityHome})
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("ok, restricted user sees only own entries", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 2})
+
+		ar := NewMockAuditRepository(ctrl)
+		ar.EXPECT().GetAuditEntries(ctx, domain.AuditFilter{ActorID: 2, Limit: 10}).Times(1).Return([]domain.AuditEntry{}, nil)
+
+		a := NewAudit(ar, WithAuditAccessPolicy(NewAccessPolicy(nil, nil, nil)))
+
+		_, err := a.GetAuditEntries(ctx, domain.AuditFilter{Limit: 10})
+		require.NoError(t, err)
+
+		entries, err := a.GetAuditEntries(ctx, domain.AuditFilter{ActorID: 3, Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("ok, admin sees everything", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 1, IsAdmin: true})
+
+		ar := NewMockAuditRepository(ctrl)
+		ar.EXPECT().GetAuditEntries(ctx, domain.AuditFilter{ActorID: 3, Limit: 10}).Times(1).Return([]domain.AuditEntry{}, nil)
+
+		a := NewAudit(ar, WithAuditAccessPolicy(NewAccessPolicy(nil, nil, nil)))
+
+		_, err := a.GetAuditEntries(ctx, domain.AuditFilter{ActorID: 3, Limit: 10})
+		require.NoError(t, err)
+	})
+}
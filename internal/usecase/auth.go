@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"homework/internal/domain"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// apiTokenPrefix - префикс токенов, по нему токен легко найти в конфигурации и логах
+	apiTokenPrefix = "sht_"
+	// apiTokenBytes - количество случайных байт в токене
+	apiTokenBytes = 32
+	// maxTokenNameLength - максимальная длина названия токена
+	maxTokenNameLength = 64
+)
+
+type userKey struct{}
+
+// ContextWithUser возвращает контекст с аутентифицированным пользователем
+func ContextWithUser(ctx context.Context, user *domain.User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFromContext возвращает пользователя, положенного в контекст ContextWithUser
+func UserFromContext(ctx context.Context) (*domain.User, bool) {
+	user, ok := ctx.Value(userKey{}).(*domain.User)
+	return user, ok && user != nil
+}
+
+type Auth struct {
+	tokenRepo APITokenRepository
+	userRepo  UserRepository
+	audit     *Audit
+	now       func() time.Time
+}
+
+func NewAuth(tr APITokenRepository, ur UserRepository, options ...func(*Auth)) *Auth {
+	a := &Auth{
+		tokenRepo: tr,
+		userRepo:  ur,
+		now:       time.Now,
+	}
+
+	for _, o := range options {
+		o(a)
+	}
+
+	return a
+}
+
+// WithAuthClock подменяет источник текущего времени, используется в тестах
+func WithAuthClock(now func() time.Time) func(*Auth) {
+	return func(a *Auth) {
+		a.now = now
+	}
+}
+
+// WithAuthAudit записывает выпуск и отзыв токенов в журнал аудита, значения и хэши токенов туда не попадают
+func WithAuthAudit(au *Audit) func(*Auth) {
+	return func(a *Auth) {
+		a.audit = au
+	}
+}
+
+// CreateToken выпускает токен пользователю. ttl, равный 0, означает бессрочный токен.
+// Открытое значение токена возвращается только здесь и больше нигде не хранится.
+func (a *Auth) CreateToken(ctx context.Context, userID int64, name string, ttl time.Duration) (*domain.APIToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxTokenNameLength {
+		return nil, "", ErrInvalidTokenName
+	}
+	if ttl < 0 {
+		return nil, "", ErrInvalidTokenTTL
+	}
+
+	user, err := a.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if user == nil {
+		return nil, "", ErrUserNotFound
+	}
+
+	secret := make([]byte, apiTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	plain := apiTokenPrefix + hex.EncodeToString(secret)
+
+	now := a.now()
+	token := &domain.APIToken{
+		UserID:    userID,
+		Name:      name,
+		Hash:      hashAPIToken(plain),
+		CreatedAt: now,
+	}
+	if ttl > 0 {
+		expiresAt := now.Add(ttl)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := a.tokenRepo.SaveAPIToken(ctx, token); err != nil {
+		return nil, "", err
+	}
+
+	if err := a.audit.record(ctx, domain.AuditActionCreate, domain.AuditEntityAPIToken, token.ID, nil, token); err != nil {
+		return nil, "", err
+	}
+
+	return token, plain, nil
+}
+
+func (a *Auth) GetUserTokens(ctx context.Context, userID int64) ([]domain.APIToken, error) {
+	return a.tokenRepo.GetAPITokensByUserID(ctx, userID)
+}
+
+// RevokeToken отзывает токен пользователя. Чужой или уже отозванный токен считается ненайденным.
+func (a *Auth) RevokeToken(ctx context.Context, userID, tokenID int64) error {
+	tokens, err := a.tokenRepo.GetAPITokensByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		if token.ID == tokenID && token.RevokedAt == nil {
+			revoked := token
+			now := a.now()
+			revoked.RevokedAt = &now
+			if err := a.tokenRepo.RevokeAPIToken(ctx, tokenID, now); err != nil {
+				return err
+			}
+			return a.audit.record(ctx, domain.AuditActionRevoke, domain.AuditEntityAPIToken, tokenID, &token, &revoked)
+		}
+	}
+
+	return ErrTokenNotFound
+}
+
+// Authenticate возвращает владельца действующего токена
+func (a *Auth) Authenticate(ctx context.Context, plain string) (*domain.User, error) {
+	if !strings.HasPrefix(plain, apiTokenPrefix) {
+		return nil, ErrUnauthenticated
+	}
+
+	token, err := a.tokenRepo.GetAPITokenByHash(ctx, hashAPIToken(plain))
+	if errors.Is(err, ErrTokenNotFound) {
+		return nil, ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || !token.IsActive(a.now()) {
+		return nil, ErrUnauthenticated
+	}
+
+	user, err := a.userRepo.GetUserByID(ctx, token.UserID)
+	if err != nil || user == nil {
+		return nil, ErrUnauthenticated
+	}
+
+	return user, nil
+}
+
+func hashAPIToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_auth_CreateToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("fail, invalid name", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		a := NewAuth(nil, nil)
+
+		_, _, err := a.CreateToken(ctx, 1, "  ", 0)
+		assert.ErrorIs(t, err, ErrInvalidTokenName)
+
+		_, _, err = a.CreateToken(ctx, 1, strings.Repeat("x", 65), 0)
+		assert.ErrorIs(t, err, ErrInvalidTokenName)
+
+		_, _, err = a.CreateToken(ctx, 1, "cli", -time.Second)
+		assert.ErrorIs(t, err, ErrInvalidTokenTTL)
+	})
+
+	t.Run("fail, user not found", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(1)).Times(1).Return(nil, nil)
+
+		a := NewAuth(nil, ur)
+
+		_, _, err := a.CreateToken(ctx, 1, "cli", 0)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("ok, only hash is stored", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(1)).Times(1).Return(&domain.User{ID: 1}, nil)
+
+		var saved *domain.APIToken
+		tr := NewMockAPITokenRepository(ctrl)
+		tr.EXPECT().SaveAPIToken(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, token *domain.APIToken) {
+			saved = token
+		})
+
+		a := NewAuth(tr, ur, WithAuthClock(func() time.Time { return now }))
+
+		token, plain, err := a.CreateToken(ctx, 1, " cli ", time.Hour)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(plain, apiTokenPrefix))
+		assert.Equal(t, "cli", token.Name)
+		assert.Equal(t, hashAPIToken(plain), saved.Hash)
+		assert.NotContains(t, saved.Hash, plain)
+		require.NotNil(t, token.ExpiresAt)
+		assert.Equal(t, now.Add(time.Hour), *token.ExpiresAt)
+	})
+}
+
+func Test_auth_Authenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	plain := apiTokenPrefix + "abc"
+	past := now.Add(-time.Minute)
+
+	t.Run("fail, unknown format", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		a := NewAuth(nil, nil)
+
+		_, err := a.Authenticate(ctx, "abc")
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	})
+
+	t.Run("fail, token not found", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		tr := NewMockAPITokenRepository(ctrl)
+		tr.EXPECT().GetAPITokenByHash(ctx, hashAPIToken(plain)).Times(1).Return(nil, ErrTokenNotFound)
+
+		a := NewAuth(tr, nil)
+
+		_, err := a.Authenticate(ctx, plain)
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	})
+
+	t.Run("fail, token expired or revoked", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		tr := NewMockAPITokenRepository(ctrl)
+		tr.EXPECT().GetAPITokenByHash(ctx, hashAPIToken(plain)).Times(1).Return(&domain.APIToken{UserID: 1, ExpiresAt: &past}, nil)
+		tr.EXPECT().GetAPITokenByHash(ctx, hashAPIToken(plain)).Times(1).Return(&domain.APIToken{UserID: 1, RevokedAt: &past}, nil)
+
+		a := NewAuth(tr, nil, WithAuthClock(func() time.Time { return now }))
+
+		_, err := a.Authenticate(ctx, plain)
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+
+		_, err = a.Authenticate(ctx, plain)
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		tr := NewMockAPITokenRepository(ctrl)
+		tr.EXPECT().GetAPITokenByHash(ctx, hashAPIToken(plain)).Times(1).Return(&domain.APIToken{UserID: 1}, nil)
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(1)).Times(1).Return(&domain.User{ID: 1, Name: "owner"}, nil)
+
+		a := NewAuth(tr, ur)
+
+		user, err := a.Authenticate(ctx, plain)
+		require.NoError(t, err)
+		assert.Equal(t, "owner", user.Name)
+	})
+}
+
+func Test_auth_RevokeToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, token of another user", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		tr := NewMockAPITokenRepository(ctrl)
+		tr.EXPECT().GetAPITokensByUserID(ctx, int64(1)).Times(1).Return([]domain.APIToken{{ID: 1, UserID: 1}}, nil)
+		tr.EXPECT().RevokeAPIToken(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		a := NewAuth(tr, nil)
+
+		err := a.RevokeToken(ctx, 1, 2)
+		assert.ErrorIs(t, err, ErrTokenNotFound)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		tr := NewMockAPITokenRepository(ctrl)
+		tr.EXPECT().GetAPITokensByUserID(ctx, int64(1)).Times(1).Return([]domain.APIToken{{ID: 2, UserID: 1}}, nil)
+		tr.EXPECT().RevokeAPIToken(ctx, int64(2), gomock.Any()).Times(1).Return(nil)
+
+		a := NewAuth(tr, nil)
+
+		err := a.RevokeToken(ctx, 1, 2)
+		assert.NoError(t, err)
+	})
+}
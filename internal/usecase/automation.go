@@ -0,0 +1,686 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxAutomationNameLength - максимальная длина названия автоматизации или сцены в символах
+	maxAutomationNameLength = 100
+	// maxAutomationConditions - максимальное число условий автоматизации
+	maxAutomationConditions = 10
+	// maxAutomationActions - максимальное число действий автоматизации или сцены
+	maxAutomationActions = 10
+	// maxAutomationRunErrorLength - сколько символов причин неудачи запуска сохраняется
+	maxAutomationRunErrorLength = 500
+
+	// DefaultAutomationRunsPageSize - размер страницы журнала запусков, если он не задан
+	DefaultAutomationRunsPageSize = 50
+	// MaxAutomationRunsPageSize - максимальный размер страницы журнала запусков
+	MaxAutomationRunsPageSize = 100
+)
+
+// Automation - автоматизации, которые запускаются событиями датчиков, и сцены, которые запускаются по запросу.
+// Пользователь без прав администратора видит и меняет только свои автоматизации и сцены и может ссылаться в них
+// только на доступные ему датчики, устройства и подписки.
+//
+// Автоматизация и сцена выполняются с правами своего автора, поэтому действие, на которое у автора больше
+// нет прав, не выполняется. Каждый запуск записывается в журнал запусков.
+type Automation struct {
+	automationRepo AutomationRepository
+	sensorRepo     SensorRepository
+	userRepo       UserRepository
+	homeRepo       HomeRepository
+	access         *AccessPolicy
+	actuators      *Actuator
+	webhooks       *Webhook
+	alerts         *Alert
+	audit          *Audit
+	now            func() time.Time
+}
+
+func NewAutomation(ar AutomationRepository, sr SensorRepository, ur UserRepository, options ...func(*Automation)) *Automation {
+	a := &Automation{
+		automationRepo: ar,
+		sensorRepo:     sr,
+		userRepo:       ur,
+		now:            time.Now,
+	}
+
+	for _, o := range options {
+		o(a)
+	}
+
+	return a
+}
+
+// WithAutomationAccessPolicy ограничивает автоматизации и сцены пользователя его собственными,
+// а датчики в них - доступными ему
+func WithAutomationAccessPolicy(p *AccessPolicy) func(*Automation) {
+	return func(a *Automation) {
+		a.access = p
+	}
+}
+
+// WithAutomationHomes разрешает условия относительно восхода и заката по координатам домов
+func WithAutomationHomes(hr HomeRepository) func(*Automation) {
+	return func(a *Automation) {
+		a.homeRepo = hr
+	}
+}
+
+// WithAutomationActuators разрешает действия с командами исполнительным устройствам
+func WithAutomationActuators(ac *Actuator) func(*Automation) {
+	return func(a *Automation) {
+		a.actuators = ac
+	}
+}
+
+// WithAutomationWebhooks разрешает действия с уведомлениями подпискам
+func WithAutomationWebhooks(w *Webhook) func(*Automation) {
+	return func(a *Automation) {
+		a.webhooks = w
+	}
+}
+
+// WithAutomationAlerts разрешает действия с оповещениями
+func WithAutomationAlerts(al *Alert) func(*Automation) {
+	return func(a *Automation) {
+		a.alerts = al
+	}
+}
+
+// WithAutomationAudit записывает создание, изменение и удаление автоматизаций и сцен в журнал аудита
+func WithAutomationAudit(au *Audit) func(*Automation) {
+	return func(a *Automation) {
+		a.audit = au
+	}
+}
+
+// WithAutomationClock подменяет источник текущего времени, используется в тестах
+func WithAutomationClock(now func() time.Time) func(*Automation) {
+	return func(a *Automation) {
+		a.now = now
+	}
+}
+
+// CreateAutomation создает автоматизацию, автором становится пользователь из контекста
+func (a *Automation) CreateAutomation(ctx context.Context, automation *domain.Automation) (*domain.Automation, error) {
+	if automation == nil {
+		return nil, ErrAutomationNotFound
+	}
+	if err := a.validateAutomation(ctx, automation); err != nil {
+		return nil, err
+	}
+
+	automation.ID = 0
+	automation.CreatedAt = a.now()
+	automation.CreatedBy = 0
+	if caller, ok := UserFromContext(ctx); ok {
+		automation.CreatedBy = caller.ID
+	}
+
+	if err := a.automationRepo.SaveAutomation(ctx, automation); err != nil {
+		return nil, err
+	}
+
+	if err := a.audit.record(ctx, domain.AuditActionCreate, domain.AuditEntityAutomation, automation.ID, nil, automation); err != nil {
+		return nil, err
+	}
+
+	return automation, nil
+}
+
+// GetAutomations возвращает автоматизации пользователя из контекста, администратору - все автоматизации
+func (a *Automation) GetAutomations(ctx context.Context) ([]domain.Automation, error) {
+	automations, err := a.automationRepo.GetAutomations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	caller, ok := a.access.restricted(ctx)
+	if !ok {
+		return automations, nil
+	}
+
+	result := make([]domain.Automation, 0, len(automations))
+	for _, automation := range automations {
+		if automation.CreatedBy == caller.ID {
+			result = append(result, automation)
+		}
+	}
+	return result, nil
+}
+
+// GetAutomationByID возвращает автоматизацию. Чужая автоматизация неотличима от несуществующей.
+func (a *Automation) GetAutomationByID(ctx context.Context, id int64) (*domain.Automation, error) {
+	automation, err := a.automationRepo.GetAutomationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if automation == nil {
+		return nil, ErrAutomationNotFound
+	}
+
+	if caller, ok := a.access.restricted(ctx); ok && automation.CreatedBy != caller.ID {
+		return nil, ErrAutomationNotFound
+	}
+
+	return automation, nil
+}
+
+// UpdateAutomation заменяет название, включенность, триггер, условия и действия автоматизации
+func (a *Automation) UpdateAutomation(ctx context.Context, automation *domain.Automation) (*domain.Automation, error) {
+	if automation == nil {
+		return nil, ErrAutomationNotFound
+	}
+
+	existingAutomation, err := a.GetAutomationByID(ctx, automation.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.validateAutomation(ctx, automation); err != nil {
+		return nil, err
+	}
+
+	automation.CreatedBy = existingAutomation.CreatedBy
+	automation.CreatedAt = existingAutomation.CreatedAt
+	if err := a.automationRepo.SaveAutomation(ctx, automation); err != nil {
+		return nil, err
+	}
+
+	if err := a.audit.record(ctx, domain.AuditActionUpdate, domain.AuditEntityAutomation, automation.ID, existingAutomation, automation); err != nil {
+		return nil, err
+	}
+
+	return automation, nil
+}
+
+// DeleteAutomation удаляет автоматизацию вместе с журналом ее запусков
+func (a *Automation) DeleteAutomation(ctx context.Context, id int64) error {
+	automation, err := a.GetAutomationByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := a.automationRepo.DeleteAutomation(ctx, id); err != nil {
+		return err
+	}
+
+	return a.audit.record(ctx, domain.AuditActionDelete, domain.AuditEntityAutomation, id, automation, nil)
+}
+
+// CreateScene создает сцену, автором становится пользователь из контекста
+func (a *Automation) CreateScene(ctx context.Context, scene *domain.Scene) (*domain.Scene, error) {
+	if scene == nil {
+		return nil, ErrSceneNotFound
+	}
+	if err := a.validateScene(ctx, scene); err != nil {
+		return nil, err
+	}
+
+	scene.ID = 0
+	scene.CreatedAt = a.now()
+	scene.CreatedBy = 0
+	if caller, ok := UserFromContext(ctx); ok {
+		scene.CreatedBy = caller.ID
+	}
+
+	if err := a.automationRepo.SaveScene(ctx, scene); err != nil {
+		return nil, err
+	}
+
+	if err := a.audit.record(ctx, domain.AuditActionCreate, domain.AuditEntityScene, scene.ID, nil, scene); err != nil {
+		return nil, err
+	}
+
+	return scene, nil
+}
+
+// GetScenes возвращает сцены пользователя из контекста, администратору - все сцены
+func (a *Automation) GetScenes(ctx context.Context) ([]domain.Scene, error) {
+	scenes, err := a.automationRepo.GetScenes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	caller, ok := a.access.restricted(ctx)
+	if !ok {
+		return scenes, nil
+	}
+
+	result := make([]domain.Scene, 0, len(scenes))
+	for _, scene := range scenes {
+		if scene.CreatedBy == caller.ID {
+			result = append(result, scene)
+		}
+	}
+	return result, nil
+}
+
+// GetSceneByID возвращает сцену. Чужая сцена неотличима от несуществующей.
+func (a *Automation) GetSceneByID(ctx context.Context, id int64) (*domain.Scene, error) {
+	scene, err := a.automationRepo.GetSceneByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if scene == nil {
+		return nil, ErrSceneNotFound
+	}
+
+	if caller, ok := a.access.restricted(ctx); ok && scene.CreatedBy != caller.ID {
+		return nil, ErrSceneNotFound
+	}
+
+	return scene, nil
+}
+
+// UpdateScene заменяет название и действия сцены
+func (a *Automation) UpdateScene(ctx context.Context, scene *domain.Scene) (*domain.Scene, error) {
+	if scene == nil {
+		return nil, ErrSceneNotFound
+	}
+
+	existingScene, err := a.GetSceneByID(ctx, scene.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.validateScene(ctx, scene); err != nil {
+		return nil, err
+	}
+
+	scene.CreatedBy = existingScene.CreatedBy
+	scene.CreatedAt = existingScene.CreatedAt
+	if err := a.automationRepo.SaveScene(ctx, scene); err != nil {
+		return nil, err
+	}
+
+	if err := a.audit.record(ctx, domain.AuditActionUpdate, domain.AuditEntityScene, scene.ID, existingScene, scene); err != nil {
+		return nil, err
+	}
+
+	return scene, nil
+}
+
+// DeleteScene удаляет сцену вместе с журналом ее запусков. Автоматизации, которые запускают сцену,
+// не меняются, их действие со сценой перестает выполняться.
+func (a *Automation) DeleteScene(ctx context.Context, id int64) error {
+	scene, err := a.GetSceneByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := a.automationRepo.DeleteScene(ctx, id); err != nil {
+		return err
+	}
+
+	return a.audit.record(ctx, domain.AuditActionDelete, domain.AuditEntityScene, id, scene, nil)
+}
+
+// RunScene выполняет действия сцены и возвращает запись журнала с результатом.
+// Невыполненные действия не отменяют остальные, их причины записываются в журнал.
+func (a *Automation) RunScene(ctx context.Context, id int64) (*domain.AutomationRun, error) {
+	scene, err := a.GetSceneByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &domain.AutomationRun{SceneID: scene.ID, CreatedAt: a.now()}
+	source := &webhookAutomation{SceneID: scene.ID, Name: scene.Name}
+	a.execute(ctx, run, scene.CreatedBy, func(ctx context.Context) (bool, []error) {
+		return true, a.perform(ctx, scene.Actions, source, nil, nil, nil)
+	})
+
+	if err := a.automationRepo.AddAutomationRun(ctx, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// HandleEvent запускает включенные автоматизации, триггер которых срабатывает на переход датчика sensor
+// из состояния previous в состояние события event. Вызывается после сохранения события.
+func (a *Automation) HandleEvent(ctx context.Context, sensor *domain.Sensor, previous int64, event *domain.Event) error {
+	automations, err := a.automationRepo.GetAutomationsByTriggerSensorID(ctx, sensor.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range automations {
+		automation := &automations[i]
+		if !automation.Enabled || !automation.Trigger.Fires(previous, event.Payload) {
+			continue
+		}
+
+		run := &domain.AutomationRun{AutomationID: automation.ID, SensorID: sensor.ID, CreatedAt: a.now()}
+		source := &webhookAutomation{AutomationID: automation.ID, Name: automation.Name}
+		a.execute(ctx, run, automation.CreatedBy, func(ctx context.Context) (bool, []error) {
+			if err := a.access.CheckSensor(ctx, sensor.ID); err != nil {
+				return true, []error{fmt.Errorf("trigger: %w", err)}
+			}
+			ok, err := a.checkConditions(ctx, automation.Conditions, run.CreatedAt)
+			if err != nil {
+				return true, []error{fmt.Errorf("conditions: %w", err)}
+			}
+			if !ok {
+				return false, nil
+			}
+			return true, a.perform(ctx, automation.Actions, source, automation, sensor, event)
+		})
+
+		if err := a.automationRepo.AddAutomationRun(ctx, run); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAutomationRuns возвращает страницу журнала запусков автоматизации filter.AutomationID
+// или сцены filter.SceneID, от новых к старым
+func (a *Automation) GetAutomationRuns(ctx context.Context, filter domain.AutomationRunFilter) ([]domain.AutomationRun, error) {
+	if err := checkRunFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case filter.ScheduleID != 0:
+		return nil, ErrInvalidRunFilter
+	case filter.AutomationID != 0 && filter.SceneID == 0:
+		if _, err := a.GetAutomationByID(ctx, filter.AutomationID); err != nil {
+			return nil, err
+		}
+	case filter.SceneID != 0 && filter.AutomationID == 0:
+		if _, err := a.GetSceneByID(ctx, filter.SceneID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalidRunFilter
+	}
+
+	return a.automationRepo.GetAutomationRuns(ctx, filter)
+}
+
+// checkRunFilter проверяет результат и пагинацию выборки журнала запусков и задает размер страницы по умолчанию
+func checkRunFilter(filter *domain.AutomationRunFilter) error {
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return ErrInvalidRunFilter
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultAutomationRunsPageSize
+	}
+	if filter.Limit < 0 || filter.Limit > MaxAutomationRunsPageSize || filter.Offset < 0 {
+		return ErrInvalidPagination
+	}
+	return nil
+}
+
+// execute заполняет результат запуска run: выполняет run с правами автора authorID и записывает,
+// были ли выполнены действия и какие из них не удались
+func (a *Automation) execute(ctx context.Context, run *domain.AutomationRun, authorID int64,
+	perform func(ctx context.Context) (bool, []error)) {
+	var (
+		performed = true
+		errs      []error
+	)
+	authorCtx, err := a.asAuthor(ctx, authorID)
+	if err != nil {
+		errs = []error{fmt.Errorf("author: %w", err)}
+	} else {
+		performed, errs = perform(authorCtx)
+	}
+
+	switch {
+	case !performed:
+		run.Status = domain.AutomationRunSkipped
+	case len(errs) > 0:
+		run.Status = domain.AutomationRunFailed
+		reasons := make([]string, len(errs))
+		for i, err := range errs {
+			reasons[i] = err.Error()
+		}
+		run.Error = truncateReason(strings.Join(reasons, "; "), maxAutomationRunErrorLength)
+	default:
+		run.Status = domain.AutomationRunSucceeded
+	}
+}
+
+// asAuthor возвращает контекст с автором автоматизации или сцены вместо пользователя запроса
+func (a *Automation) asAuthor(ctx context.Context, authorID int64) (context.Context, error) {
+	if authorID == 0 {
+		return ContextWithUser(ctx, nil), nil
+	}
+
+	user, err := a.userRepo.GetUserByID(ctx, authorID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return ContextWithUser(ctx, user), nil
+}
+
+// checkConditions сообщает, выполняются ли все условия в момент now
+func (a *Automation) checkConditions(ctx context.Context, conditions []domain.AutomationCondition, now time.Time) (bool, error) {
+	for _, condition := range conditions {
+		switch condition.Type {
+		case domain.ConditionSensorState:
+			sensor, err := a.checkSensor(ctx, condition.SensorID)
+			if err != nil {
+				return false, err
+			}
+			if !condition.Operator.Compare(float64(sensor.CurrentState), condition.Value) {
+				return false, nil
+			}
+		case domain.ConditionTimeWindow:
+			if !condition.Window.Contains(now) {
+				return false, nil
+			}
+		case domain.ConditionSun:
+			home, err := a.checkHome(ctx, condition.HomeID)
+			if err != nil {
+				return false, err
+			}
+			if !condition.Sun.Contains(now, home.Location(), *home.Coordinates) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// perform выполняет действия по порядку и возвращает ошибки невыполненных. automation, sensor и event
+// заданы, если действия выполняет автоматизация, запущенная событием.
+func (a *Automation) perform(ctx context.Context, actions []domain.AutomationAction, source *webhookAutomation,
+	automation *domain.Automation, sensor *domain.Sensor, event *domain.Event) []error {
+	var errs []error
+	for i, action := range actions {
+		var err error
+		switch action.Type {
+		case domain.ActionCommand:
+			_, err = a.actuators.SendCommand(ctx, actionCommand(action), actionCommandTimeout(action))
+		case domain.ActionWebhook:
+			err = a.webhooks.enqueueAutomation(ctx, action.WebhookID, source, sensor, event)
+		case domain.ActionAlert:
+			err = a.alerts.raiseAutomation(ctx, automation, sensor.ID, eventValue(sensor, event))
+		case domain.ActionScene:
+			var scene *domain.Scene
+			scene, err = a.GetSceneByID(ctx, action.SceneID)
+			if err == nil {
+				err = errors.Join(a.perform(ctx, scene.Actions, source, nil, sensor, event)...)
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("action %d: %w", i+1, err))
+		}
+	}
+	return errs
+}
+
+func (a *Automation) validateAutomation(ctx context.Context, automation *domain.Automation) error {
+	automation.Name = strings.TrimSpace(automation.Name)
+	if automation.Name == "" || utf8.RuneCountInString(automation.Name) > maxAutomationNameLength {
+		return ErrInvalidAutomation
+	}
+	if !automation.Trigger.Operator.IsValid() || len(automation.Conditions) > maxAutomationConditions {
+		return ErrInvalidAutomation
+	}
+	if _, err := a.checkSensor(ctx, automation.Trigger.SensorID); err != nil {
+		return err
+	}
+
+	for i := range automation.Conditions {
+		condition := &automation.Conditions[i]
+		switch condition.Type {
+		case domain.ConditionSensorState:
+			if !condition.Operator.IsValid() {
+				return ErrInvalidAutomation
+			}
+			if _, err := a.checkSensor(ctx, condition.SensorID); err != nil {
+				return err
+			}
+			condition.Window, condition.HomeID, condition.Sun = nil, 0, nil
+		case domain.ConditionTimeWindow:
+			if condition.Window == nil || !isWindowValid(condition.Window) {
+				return ErrInvalidAutomation
+			}
+			*condition = domain.AutomationCondition{Type: condition.Type, Window: condition.Window}
+		case domain.ConditionSun:
+			if a.homeRepo == nil || condition.Sun == nil || !condition.Sun.IsValid() {
+				return ErrInvalidAutomation
+			}
+			if _, err := a.checkHome(ctx, condition.HomeID); err != nil {
+				return err
+			}
+			*condition = domain.AutomationCondition{Type: condition.Type, HomeID: condition.HomeID, Sun: condition.Sun}
+		default:
+			return ErrInvalidAutomation
+		}
+	}
+
+	return a.validateActions(ctx, automation.Actions, true, ErrInvalidAutomation)
+}
+
+func (a *Automation) validateScene(ctx context.Context, scene *domain.Scene) error {
+	scene.Name = strings.TrimSpace(scene.Name)
+	if scene.Name == "" || utf8.RuneCountInString(scene.Name) > maxAutomationNameLength {
+		return ErrInvalidScene
+	}
+
+	return a.validateActions(ctx, scene.Actions, false, ErrInvalidScene)
+}
+
+// validateActions проверяет действия и очищает поля, не относящиеся к их видам. Оповещения и запуск сцен
+// разрешены только автоматизациям (automation), для неверных действий возвращается invalid.
+func (a *Automation) validateActions(ctx context.Context, actions []domain.AutomationAction, automation bool, invalid error) error {
+	if len(actions) == 0 || len(actions) > maxAutomationActions {
+		return invalid
+	}
+
+	for i := range actions {
+		action := &actions[i]
+		switch action.Type {
+		case domain.ActionCommand:
+			if a.actuators == nil {
+				return invalid
+			}
+			command := actionCommand(*action)
+			_, err := a.actuators.checkCommand(ctx, command, actionCommandTimeout(*action))
+			if errors.Is(err, ErrInvalidCommand) {
+				return invalid
+			}
+			if err != nil {
+				return err
+			}
+			*action = domain.AutomationAction{
+				Type:       action.Type,
+				ActuatorID: command.ActuatorID,
+				Command:    command.Kind,
+				State:      command.State,
+				Duration:   command.Duration,
+			}
+		case domain.ActionWebhook:
+			if a.webhooks == nil {
+				return invalid
+			}
+			if _, err := a.webhooks.GetWebhookByID(ctx, action.WebhookID); err != nil {
+				return err
+			}
+			*action = domain.AutomationAction{Type: action.Type, WebhookID: action.WebhookID}
+		case domain.ActionAlert:
+			if !automation || a.alerts == nil {
+				return invalid
+			}
+			*action = domain.AutomationAction{Type: action.Type}
+		case domain.ActionScene:
+			if !automation {
+				return invalid
+			}
+			if _, err := a.GetSceneByID(ctx, action.SceneID); err != nil {
+				return err
+			}
+			*action = domain.AutomationAction{Type: action.Type, SceneID: action.SceneID}
+		default:
+			return invalid
+		}
+	}
+	return nil
+}
+
+// checkSensor возвращает датчик, если он доступен пользователю из контекста
+func (a *Automation) checkSensor(ctx context.Context, sensorID int64) (*domain.Sensor, error) {
+	sensor, err := a.sensorRepo.GetSensorByID(ctx, sensorID)
+	if err != nil {
+		return nil, err
+	}
+	if sensor == nil {
+		return nil, ErrSensorNotFound
+	}
+
+	if err := a.access.CheckSensor(ctx, sensorID); err != nil {
+		return nil, err
+	}
+	return sensor, nil
+}
+
+// checkHome возвращает дом, если он доступен пользователю из контекста и у него заданы координаты
+func (a *Automation) checkHome(ctx context.Context, homeID int64) (*domain.Home, error) {
+	if a.homeRepo == nil {
+		return nil, ErrHomeNotFound
+	}
+	home, err := a.homeRepo.GetHomeByID(ctx, homeID)
+	if err != nil {
+		return nil, err
+	}
+	if home == nil {
+		return nil, ErrHomeNotFound
+	}
+
+	if err := a.access.CheckHome(ctx, homeID); err != nil {
+		return nil, err
+	}
+	if home.Coordinates == nil {
+		return nil, ErrInvalidAutomation
+	}
+	return home, nil
+}
+
+// actionCommand возвращает команду действия ActionCommand
+func actionCommand(action domain.AutomationAction) *domain.Command {
+	return &domain.Command{
+		ActuatorID: action.ActuatorID,
+		Kind:       action.Command,
+		State:      action.State,
+		Duration:   action.Duration,
+	}
+}
+
+// actionCommandTimeout - время на выполнение команды действия: DefaultCommandTimeout сверх длительности pulse
+func actionCommandTimeout(action domain.AutomationAction) time.Duration {
+	return DefaultCommandTimeout + action.Duration
+}
@@ -0,0 +1,344 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_automation_CreateAutomation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	relay := &domain.Actuator{ID: 2, SensorID: 1, Name: "relay", Type: domain.ActuatorTypeRelay}
+
+	t.Run("fail, invalid automation", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(5)).AnyTimes().Return(&domain.Sensor{ID: 5}, nil)
+
+		a := NewAutomation(nil, sr, nil)
+
+		trigger := domain.AutomationTrigger{SensorID: 5, Operator: domain.RuleOperatorEqual, Value: 1}
+		alert := []domain.AutomationAction{{Type: domain.ActionAlert}}
+		invalid := []*domain.Automation{
+			{Name: " ", Trigger: trigger, Actions: alert},
+			{Name: "door", Trigger: domain.AutomationTrigger{SensorID: 5, Operator: "~"}, Actions: alert},
+			{Name: "door", Trigger: trigger},
+			{Name: "door", Trigger: trigger, Actions: []domain.AutomationAction{{Type: "email"}}},
+			{Name: "door", Trigger: trigger, Actions: []domain.AutomationAction{{Type: domain.ActionCommand, ActuatorID: 2}}},
+			{Name: "door", Trigger: trigger, Actions: []domain.AutomationAction{{Type: domain.ActionAlert}}},
+			{Name: "door", Trigger: trigger, Actions: alert, Conditions: []domain.AutomationCondition{
+				{Type: domain.ConditionTimeWindow, Window: &domain.RuleWindow{From: 60, To: 60, TimeZone: "UTC"}},
+			}},
+		}
+		for _, automation := range invalid {
+			_, err := a.CreateAutomation(ctx, automation)
+			assert.ErrorIs(t, err, ErrInvalidAutomation)
+		}
+	})
+
+	t.Run("fail, scene can't contain alerts", func(t *testing.T) {
+		ctx := context.Background()
+
+		a := NewAutomation(nil, nil, nil, WithAutomationAlerts(NewAlert(nil)))
+
+		_, err := a.CreateScene(ctx, &domain.Scene{Name: "evening", Actions: []domain.AutomationAction{{Type: domain.ActionAlert}}})
+		assert.ErrorIs(t, err, ErrInvalidScene)
+	})
+
+	t.Run("fail, viewer can't command actuators", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 2})
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		ar := NewMockActuatorRepository(ctrl)
+		ar.EXPECT().GetActuatorByID(ctx, int64(2)).Times(1).Return(relay, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).AnyTimes().
+			Return([]domain.SensorOwner{{UserID: 2, SensorID: 1, Role: domain.SensorRoleViewer}}, nil)
+
+		access := NewAccessPolicy(sor, nil, nil)
+		a := NewAutomation(nil, sr, nil, WithAutomationAccessPolicy(access),
+			WithAutomationActuators(NewActuator(ar, nil, nil, WithActuatorAccessPolicy(access))))
+
+		_, err := a.CreateAutomation(ctx, &domain.Automation{
+			Name:    "door",
+			Trigger: domain.AutomationTrigger{SensorID: 1, Operator: domain.RuleOperatorEqual, Value: 1},
+			Actions: []domain.AutomationAction{{Type: domain.ActionCommand, ActuatorID: 2, Command: domain.CommandSetState, State: 1}},
+		})
+		assert.ErrorIs(t, err, ErrInsufficientRole)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 1, IsAdmin: true})
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		ar := NewMockActuatorRepository(ctrl)
+		ar.EXPECT().GetActuatorByID(ctx, int64(2)).Times(1).Return(relay, nil)
+
+		automationRepo := NewMockAutomationRepository(ctrl)
+		automationRepo.EXPECT().SaveAutomation(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, automation *domain.Automation) {
+			automation.ID = 3
+		})
+
+		a := NewAutomation(automationRepo, sr, nil, WithAutomationActuators(NewActuator(ar, nil, nil)),
+			WithAutomationClock(func() time.Time { return now }))
+
+		automation, err := a.CreateAutomation(ctx, &domain.Automation{
+			Name:    " door ",
+			Enabled: true,
+			Trigger: domain.AutomationTrigger{SensorID: 1, Operator: domain.RuleOperatorEqual, Value: 1},
+			Conditions: []domain.AutomationCondition{
+				{Type: domain.ConditionTimeWindow, SensorID: 7, Window: &domain.RuleWindow{From: 1200, To: 360, TimeZone: "UTC"}},
+			},
+			Actions: []domain.AutomationAction{
+				{Type: domain.ActionCommand, ActuatorID: 2, Command: domain.CommandSetState, State: 1, Duration: time.Second, WebhookID: 4},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &domain.Automation{
+			ID:      3,
+			Name:    "door",
+			Enabled: true,
+			Trigger: domain.AutomationTrigger{SensorID: 1, Operator: domain.RuleOperatorEqual, Value: 1},
+			Conditions: []domain.AutomationCondition{
+				{Type: domain.ConditionTimeWindow, Window: &domain.RuleWindow{From: 1200, To: 360, TimeZone: "UTC"}},
+			},
+			Actions: []domain.AutomationAction{
+				{Type: domain.ActionCommand, ActuatorID: 2, Command: domain.CommandSetState, State: 1},
+			},
+			CreatedBy: 1,
+			CreatedAt: now,
+		}, automation)
+	})
+}
+
+func Test_automation_HandleEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 21, 0, 0, 0, time.UTC)
+	door := &domain.Sensor{ID: 5, Type: domain.SensorTypeContactClosure, CurrentState: 1}
+	automation := domain.Automation{
+		ID:      3,
+		Name:    "door at night",
+		Enabled: true,
+		Trigger: domain.AutomationTrigger{SensorID: 5, Operator: domain.RuleOperatorEqual, Value: 1},
+		Conditions: []domain.AutomationCondition{
+			{Type: domain.ConditionSensorState, SensorID: 6, Operator: domain.RuleOperatorLess, Value: 100},
+			{Type: domain.ConditionTimeWindow, Window: &domain.RuleWindow{From: 20 * 60, To: 6 * 60, TimeZone: "UTC"}},
+		},
+		Actions: []domain.AutomationAction{
+			{Type: domain.ActionCommand, ActuatorID: 2, Command: domain.CommandSetState, State: 1},
+			{Type: domain.ActionCommand, ActuatorID: 9, Command: domain.CommandSetState, State: 1},
+		},
+		CreatedBy: 1,
+	}
+
+	t.Run("ok, repeated state doesn't fire", func(t *testing.T) {
+		ctx := context.Background()
+
+		automationRepo := NewMockAutomationRepository(ctrl)
+		automationRepo.EXPECT().GetAutomationsByTriggerSensorID(ctx, int64(5)).Times(1).
+			Return([]domain.Automation{automation}, nil)
+
+		a := NewAutomation(automationRepo, nil, nil)
+
+		require.NoError(t, a.HandleEvent(ctx, door, 1, &domain.Event{SensorID: 5, Payload: 1}))
+	})
+
+	t.Run("ok, unmet condition skips run", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(gomock.Any(), int64(6)).Times(1).Return(&domain.Sensor{ID: 6, CurrentState: 500}, nil)
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(1)).Times(1).Return(&domain.User{ID: 1, IsAdmin: true}, nil)
+
+		automationRepo := NewMockAutomationRepository(ctrl)
+		automationRepo.EXPECT().GetAutomationsByTriggerSensorID(ctx, int64(5)).Times(1).
+			Return([]domain.Automation{automation}, nil)
+		automationRepo.EXPECT().AddAutomationRun(ctx, &domain.AutomationRun{
+			AutomationID: 3, SensorID: 5, Status: domain.AutomationRunSkipped, CreatedAt: now,
+		}).Times(1)
+
+		a := NewAutomation(automationRepo, sr, ur, WithAutomationClock(func() time.Time { return now }))
+
+		require.NoError(t, a.HandleEvent(ctx, door, 0, &domain.Event{SensorID: 5, Payload: 1}))
+	})
+
+	t.Run("ok, actions run as author and failures are logged", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(gomock.Any(), int64(6)).Times(1).Return(&domain.Sensor{ID: 6, CurrentState: 50}, nil)
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(1)).Times(1).Return(&domain.User{ID: 1, IsAdmin: true}, nil)
+
+		ar := NewMockActuatorRepository(ctrl)
+		ar.EXPECT().GetActuatorByID(gomock.Any(), int64(2)).Times(1).
+			Return(&domain.Actuator{ID: 2, SensorID: 1, Type: domain.ActuatorTypeRelay}, nil)
+		ar.EXPECT().GetActuatorByID(gomock.Any(), int64(9)).Times(1).Return(nil, ErrActuatorNotFound)
+
+		cr := NewMockCommandRepository(ctrl)
+		cr.EXPECT().AddCommand(gomock.Any(), gomock.Any()).Times(1).Do(func(_ context.Context, command *domain.Command) {
+			assert.Equal(t, int64(1), command.CreatedBy)
+			assert.Equal(t, int64(2), command.ActuatorID)
+		})
+
+		automationRepo := NewMockAutomationRepository(ctrl)
+		automationRepo.EXPECT().GetAutomationsByTriggerSensorID(ctx, int64(5)).Times(1).
+			Return([]domain.Automation{automation}, nil)
+		automationRepo.EXPECT().AddAutomationRun(ctx, &domain.AutomationRun{
+			AutomationID: 3, SensorID: 5, Status: domain.AutomationRunFailed, Error: "action 2: actuator not found",
+			CreatedAt: now,
+		}).Times(1)
+
+		a := NewAutomation(automationRepo, sr, ur, WithAutomationActuators(NewActuator(ar, cr, nil)),
+			WithAutomationClock(func() time.Time { return now }))
+
+		require.NoError(t, a.HandleEvent(ctx, door, 0, &domain.Event{SensorID: 5, Payload: 1}))
+	})
+}
+
+func Test_automation_SunCondition(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	moscow := &domain.Coordinates{Latitude: 55.7558, Longitude: 37.6173}
+	home := &domain.Home{ID: 4, Name: "home", TimeZone: "Europe/Moscow", Coordinates: moscow}
+	motion := &domain.Sensor{ID: 5, Type: domain.SensorTypeContactClosure, CurrentState: 1}
+	lights := domain.AutomationAction{Type: domain.ActionCommand, ActuatorID: 2, Command: domain.CommandSetState, State: 1}
+	automation := domain.Automation{
+		ID:         3,
+		Name:       "lights on motion after sunset",
+		Enabled:    true,
+		Trigger:    domain.AutomationTrigger{SensorID: 5, Operator: domain.RuleOperatorEqual, Value: 1},
+		Conditions: []domain.AutomationCondition{{Type: domain.ConditionSun, HomeID: 4, Sun: &domain.SunCondition{After: "sunset"}}},
+		Actions:    []domain.AutomationAction{lights},
+	}
+
+	t.Run("fail, invalid sun condition", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(5)).AnyTimes().Return(motion, nil)
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(4)).AnyTimes().Return(home, nil)
+		hr.EXPECT().GetHomeByID(ctx, int64(8)).AnyTimes().Return(&domain.Home{ID: 8, Name: "no coordinates"}, nil)
+		hr.EXPECT().GetHomeByID(ctx, int64(9)).AnyTimes().Return(nil, ErrHomeNotFound)
+
+		a := NewAutomation(nil, sr, nil, WithAutomationHomes(hr), WithAutomationAlerts(NewAlert(nil)))
+
+		for _, condition := range []domain.AutomationCondition{
+			{Type: domain.ConditionSun, HomeID: 4},
+			{Type: domain.ConditionSun, HomeID: 4, Sun: &domain.SunCondition{After: "dusk"}},
+			{Type: domain.ConditionSun, HomeID: 8, Sun: &domain.SunCondition{After: "sunset"}},
+		} {
+			_, err := a.CreateAutomation(ctx, &domain.Automation{
+				Name:       "lights",
+				Trigger:    automation.Trigger,
+				Conditions: []domain.AutomationCondition{condition},
+				Actions:    []domain.AutomationAction{{Type: domain.ActionAlert}},
+			})
+			assert.ErrorIs(t, err, ErrInvalidAutomation)
+		}
+
+		_, err := a.CreateAutomation(ctx, &domain.Automation{
+			Name:       "lights",
+			Trigger:    automation.Trigger,
+			Conditions: []domain.AutomationCondition{{Type: domain.ConditionSun, HomeID: 9, Sun: &domain.SunCondition{After: "sunset"}}},
+			Actions:    []domain.AutomationAction{{Type: domain.ActionAlert}},
+		})
+		assert.ErrorIs(t, err, ErrHomeNotFound)
+	})
+
+	for _, tt := range []struct {
+		name   string
+		now    time.Time
+		status domain.AutomationRunStatus
+	}{
+		// 21 декабря в Москве закат около 15:58 по местному времени
+		{"ok, motion after sunset turns on lights", time.Date(2024, 12, 21, 15, 0, 0, 0, time.UTC), domain.AutomationRunSucceeded},
+		{"ok, motion in daytime is skipped", time.Date(2024, 12, 21, 9, 0, 0, 0, time.UTC), domain.AutomationRunSkipped},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			hr := NewMockHomeRepository(ctrl)
+			hr.EXPECT().GetHomeByID(gomock.Any(), int64(4)).Times(1).Return(home, nil)
+
+			ar := NewMockActuatorRepository(ctrl)
+			ar.EXPECT().GetActuatorByID(gomock.Any(), int64(2)).AnyTimes().
+				Return(&domain.Actuator{ID: 2, SensorID: 1, Type: domain.ActuatorTypeRelay}, nil)
+
+			cr := NewMockCommandRepository(ctrl)
+			performed := 0
+			if tt.status == domain.AutomationRunSucceeded {
+				performed = 1
+			}
+			cr.EXPECT().AddCommand(gomock.Any(), gomock.Any()).Times(performed)
+
+			automationRepo := NewMockAutomationRepository(ctrl)
+			automationRepo.EXPECT().GetAutomationsByTriggerSensorID(ctx, int64(5)).Times(1).
+				Return([]domain.Automation{automation}, nil)
+			automationRepo.EXPECT().AddAutomationRun(ctx, &domain.AutomationRun{
+				AutomationID: 3, SensorID: 5, Status: tt.status, CreatedAt: tt.now,
+			}).Times(1)
+
+			a := NewAutomation(automationRepo, nil, nil, WithAutomationHomes(hr),
+				WithAutomationActuators(NewActuator(ar, cr, nil)),
+				WithAutomationClock(func() time.Time { return tt.now }))
+
+			require.NoError(t, a.HandleEvent(ctx, motion, 0, &domain.Event{SensorID: 5, Payload: 1}))
+		})
+	}
+}
+
+func Test_automation_GetAutomationRuns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, invalid filter", func(t *testing.T) {
+		a := NewAutomation(nil, nil, nil)
+
+		_, err := a.GetAutomationRuns(context.Background(), domain.AutomationRunFilter{AutomationID: 1, SceneID: 1})
+		assert.ErrorIs(t, err, ErrInvalidRunFilter)
+
+		_, err = a.GetAutomationRuns(context.Background(), domain.AutomationRunFilter{AutomationID: 1, Status: "done"})
+		assert.ErrorIs(t, err, ErrInvalidRunFilter)
+	})
+
+	t.Run("fail, someone else's automation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 2})
+
+		automationRepo := NewMockAutomationRepository(ctrl)
+		automationRepo.EXPECT().GetAutomationByID(ctx, int64(3)).Times(1).Return(&domain.Automation{ID: 3, CreatedBy: 1}, nil)
+
+		a := NewAutomation(automationRepo, nil, nil, WithAutomationAccessPolicy(NewAccessPolicy(nil, nil, nil)))
+
+		_, err := a.GetAutomationRuns(ctx, domain.AutomationRunFilter{AutomationID: 3})
+		assert.ErrorIs(t, err, ErrAutomationNotFound)
+	})
+}
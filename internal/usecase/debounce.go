@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"homework/internal/domain"
+	"sync"
+	"time"
+)
+
+// Debounce - подавление дребезга и обнаружение нестабильных датчиков сухого контакта, для которых заданы
+// настройки Debounce.
+//
+// Смена состояния такого датчика принимается, только если она продержалась окно: до этого событие
+// откладывается, а возврат в прежнее состояние подавляет и его, и отложенную смену. Датчик, переключающийся
+// чаще порога, помечается как нестабильный, пока частота переключений не опустится до порога.
+//
+// Состояние ведется в памяти, отложенные смены при перезапуске теряются.
+type Debounce struct {
+	now func() time.Time
+
+	mu       sync.Mutex
+	contacts map[int64]*debounceContact
+}
+
+type debounceContact struct {
+	settings domain.Debounce
+	state    domain.DebounceState
+	flapping bool
+}
+
+// debounced - события датчика, которые нужно принять, и его нестабильность
+type debounced struct {
+	sensorID int64
+	events   []*domain.Event
+	flapping bool
+}
+
+func NewDebounce(options ...func(*Debounce)) *Debounce {
+	d := &Debounce{
+		now:      time.Now,
+		contacts: make(map[int64]*debounceContact),
+	}
+
+	for _, o := range options {
+		o(d)
+	}
+
+	return d
+}
+
+// WithDebounceClock подменяет источник текущего времени, используется в тестах
+func WithDebounceClock(now func() time.Time) func(*Debounce) {
+	return func(d *Debounce) {
+		d.now = now
+	}
+}
+
+// receive учитывает событие датчика и возвращает события, которые нужно принять, и нестабильность датчика.
+// События датчиков без настроек принимаются как есть.
+func (d *Debounce) receive(sensor *domain.Sensor, event *domain.Event) ([]*domain.Event, bool) {
+	if sensor.Debounce == nil || sensor.Type != domain.SensorTypeContactClosure {
+		return []*domain.Event{event}, sensor.Flapping
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	contact, ok := d.contacts[sensor.ID]
+	if !ok {
+		contact = &debounceContact{
+			state:    domain.DebounceState{Accepted: sensor.CurrentState, Reported: sensor.CurrentState},
+			flapping: sensor.Flapping,
+		}
+		d.contacts[sensor.ID] = contact
+	}
+	contact.settings = *sensor.Debounce
+
+	now := d.now()
+	events := contact.settings.Receive(&contact.state, event, now)
+	contact.flapping = contact.settings.IsFlapping(&contact.state, now)
+	return events, contact.flapping
+}
+
+// expire возвращает отложенные смены состояния, продержавшиеся окно, и датчики, нестабильность которых
+// изменилась без новых событий
+func (d *Debounce) expire() []debounced {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	var result []debounced
+	for sensorID, contact := range d.contacts {
+		flapping := contact.settings.IsFlapping(&contact.state, now)
+		due := contact.settings.Due(&contact.state, now)
+		if due == nil && flapping == contact.flapping {
+			continue
+		}
+		contact.flapping = flapping
+
+		item := debounced{sensorID: sensorID, flapping: flapping}
+		if due != nil {
+			item.events = []*domain.Event{due}
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+// forget забывает состояние датчика, у которого изменились или выключены настройки
+func (d *Debounce) forget(sensorID int64) {
+	d.mu.Lock()
+	delete(d.contacts, sensorID)
+	d.mu.Unlock()
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_debounce_ReceiveEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	settings := &domain.Debounce{Window: 50 * time.Millisecond, FlapThreshold: 3, FlapWindow: time.Second}
+
+	setup := func(ctx context.Context) (*MockEventRepository, *MockSensorRepository, *Event, *time.Time) {
+		now := start
+		sensor := &domain.Sensor{ID: 1, Type: domain.SensorTypeContactClosure, Debounce: settings}
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").AnyTimes().DoAndReturn(func(context.Context, string) (*domain.Sensor, error) {
+			copied := *sensor
+			return &copied, nil
+		})
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).AnyTimes().DoAndReturn(func(context.Context, int64) (*domain.Sensor, error) {
+			copied := *sensor
+			return &copied, nil
+		})
+		sr.EXPECT().UpdateSensorState(ctx, gomock.Any()).AnyTimes().Do(func(_ context.Context, saved *domain.Sensor) {
+			*sensor = *saved
+		})
+		er := NewMockEventRepository(ctrl)
+
+		d := NewDebounce(WithDebounceClock(func() time.Time { return now }))
+		return er, sr, NewEvent(er, sr, WithEventDebounce(d)), &now
+	}
+	receive := func(ctx context.Context, e *Event, now time.Time, payload int64) {
+		require.NoError(t, e.ReceiveEvent(ctx, &domain.Event{Timestamp: now, SensorSerialNumber: "0123456789", Payload: payload}))
+	}
+
+	t.Run("ok, bounce is suppressed", func(t *testing.T) {
+		ctx := context.Background()
+		_, _, e, now := setup(ctx)
+
+		receive(ctx, e, *now, 1)
+		*now = now.Add(5 * time.Millisecond)
+		receive(ctx, e, *now, 0)
+
+		*now = now.Add(time.Minute)
+		require.NoError(t, e.FlushDebouncedEvents(ctx))
+	})
+
+	t.Run("ok, stable change is accepted after window", func(t *testing.T) {
+		ctx := context.Background()
+		er, sr, e, now := setup(ctx)
+
+		receive(ctx, e, *now, 1)
+		require.NoError(t, e.FlushDebouncedEvents(ctx), "window has not passed")
+
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, event *domain.Event) {
+			assert.Equal(t, int64(1), event.Payload)
+			assert.Equal(t, start, event.Timestamp)
+		})
+		*now = now.Add(50 * time.Millisecond)
+		require.NoError(t, e.FlushDebouncedEvents(ctx))
+
+		sensor, err := sr.GetSensorByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), sensor.CurrentState)
+	})
+
+	t.Run("ok, flapping is marked until sensor stabilizes", func(t *testing.T) {
+		ctx := context.Background()
+		_, sr, e, now := setup(ctx)
+
+		for i := 0; i < 4; i++ {
+			receive(ctx, e, *now, int64((i+1)%2))
+			*now = now.Add(10 * time.Millisecond)
+		}
+		sensor, err := sr.GetSensorByID(ctx, 1)
+		require.NoError(t, err)
+		assert.True(t, sensor.Flapping)
+		assert.Zero(t, sensor.CurrentState, "bounces are not accepted")
+
+		*now = now.Add(time.Second)
+		require.NoError(t, e.FlushDebouncedEvents(ctx))
+		sensor, err = sr.GetSensorByID(ctx, 1)
+		require.NoError(t, err)
+		assert.False(t, sensor.Flapping)
+	})
+
+	t.Run("ok, sensor without settings", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).
+			Return(&domain.Sensor{ID: 1, Type: domain.SensorTypeContactClosure}, nil)
+		sr.EXPECT().UpdateSensorState(ctx, gomock.Any()).Times(1).Return(nil)
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Return(nil)
+
+		e := NewEvent(er, sr, WithEventDebounce(NewDebounce()))
+		receive(ctx, e, start, 1)
+	})
+}
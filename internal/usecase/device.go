@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"homework/internal/domain"
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+// maxDeviceInfoFieldLength - максимальная длина каждого поля сведений об устройстве
+const maxDeviceInfoFieldLength = 64
+
+// WithFirmwareHistory включает запись истории смены прошивки при обновлении сведений об устройстве
+func WithFirmwareHistory(fr FirmwareHistoryRepository) func(*Sensor) {
+	return func(s *Sensor) {
+		s.firmwareRepo = fr
+	}
+}
+
+// WithEventFirmwareHistory включает запись истории смены прошивки по сведениям, пришедшим с событиями
+func WithEventFirmwareHistory(fr FirmwareHistoryRepository) func(*Event) {
+	return func(e *Event) {
+		e.firmwareRepo = fr
+	}
+}
+
+func isDeviceInfoValid(info domain.DeviceInfo) error {
+	fields := []string{info.Manufacturer, info.Model, info.HardwareRevision, info.FirmwareVersion}
+
+	empty := true
+	for _, field := range fields {
+		if utf8.RuneCountInString(field) > maxDeviceInfoFieldLength {
+			return ErrInvalidDeviceInfo
+		}
+		if field != "" {
+			empty = false
+		}
+	}
+	if empty {
+		return ErrInvalidDeviceInfo
+	}
+
+	return nil
+}
+
+// applyDeviceInfo обновляет сведения об устройстве у датчика и, если сменилась прошивка, пишет историю.
+// Датчик не сохраняется.
+func applyDeviceInfo(
+	ctx context.Context,
+	fr FirmwareHistoryRepository,
+	sensor *domain.Sensor,
+	info domain.DeviceInfo,
+	at time.Time,
+) error {
+	previous := sensor.FirmwareVersion
+	if !sensor.ApplyDeviceInfo(info) || fr == nil {
+		return nil
+	}
+
+	return fr.SaveFirmwareChange(ctx, &domain.FirmwareChange{
+		SensorID:        sensor.ID,
+		PreviousVersion: previous,
+		Version:         sensor.FirmwareVersion,
+		ChangedAt:       at,
+	})
+}
+
+// ReportDeviceInfo обновляет сведения об оборудовании и прошивке датчика
+func (s *Sensor) ReportDeviceInfo(ctx context.Context, id int64, info domain.DeviceInfo) (*domain.Sensor, error) {
+	if err := isDeviceInfoValid(info); err != nil {
+		return nil, err
+	}
+
+	var updated domain.Sensor
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		sensor, err := s.GetSensorByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.access.CheckSensorRole(ctx, id, domain.SensorRoleOperator); err != nil {
+			return err
+		}
+
+		updated = *sensor
+		if err := applyDeviceInfo(ctx, s.firmwareRepo, &updated, info, time.Now()); err != nil {
+			return err
+		}
+
+		if err := s.sensorRepo.SaveSensor(ctx, &updated); err != nil {
+			return err
+		}
+		return s.audit.record(ctx, domain.AuditActionUpdate, domain.AuditEntitySensor, id, sensor, &updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// GetFirmwareHistory возвращает историю смены прошивки датчика
+func (s *Sensor) GetFirmwareHistory(ctx context.Context, id int64) ([]domain.FirmwareChange, error) {
+	if _, err := s.GetSensorByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if s.firmwareRepo == nil {
+		return []domain.FirmwareChange{}, nil
+	}
+
+	return s.firmwareRepo.GetFirmwareChangesBySensorID(ctx, id)
+}
+
+// GetInventory группирует датчики по производителю, модели и версии прошивки.
+// Датчики, не сообщавшие сведений, попадают в группу с пустыми полями.
+func (s *Sensor) GetInventory(ctx context.Context) ([]domain.InventoryGroup, error) {
+	sensors, err := s.GetSensors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type groupKey struct {
+		manufacturer, model, firmware string
+	}
+
+	groups := make(map[groupKey]*domain.InventoryGroup)
+	for _, sensor := range sensors {
+		key := groupKey{sensor.Manufacturer, sensor.Model, sensor.FirmwareVersion}
+		group, ok := groups[key]
+		if !ok {
+			group = &domain.InventoryGroup{
+				Manufacturer:    sensor.Manufacturer,
+				Model:           sensor.Model,
+				FirmwareVersion: sensor.FirmwareVersion,
+			}
+			groups[key] = group
+		}
+		group.SensorIDs = append(group.SensorIDs, sensor.ID)
+	}
+
+	inventory := make([]domain.InventoryGroup, 0, len(groups))
+	for _, group := range groups {
+		slices.Sort(group.SensorIDs)
+		inventory = append(inventory, *group)
+	}
+	slices.SortFunc(inventory, func(a, b domain.InventoryGroup) int {
+		return cmp.Or(
+			cmp.Compare(a.Manufacturer, b.Manufacturer),
+			cmp.Compare(a.Model, b.Model),
+			cmp.Compare(a.FirmwareVersion, b.FirmwareVersion),
+		)
+	})
+
+	return inventory, nil
+}
@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"homework/internal/domain"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// sensorSecretPrefix - префикс секретов датчиков
+	sensorSecretPrefix = "shs_"
+	// sensorSecretBytes - количество случайных байт в секрете
+	sensorSecretBytes = 32
+	// maxNonceLength - максимальная длина nonce подписанного события
+	maxNonceLength = 64
+
+	// DefaultReplayWindow - допустимое расхождение времени подписи и времени сервера
+	DefaultReplayWindow = 5 * time.Minute
+	// DefaultSecretGracePeriod - сколько действует старый секрет после ротации
+	DefaultSecretGracePeriod = 24 * time.Hour
+)
+
+// DeviceAuth выдает датчикам секреты и проверяет подписи отправляемых ими событий
+type DeviceAuth struct {
+	credentialRepo SensorCredentialRepository
+	nonceRepo      EventNonceRepository
+	sensorRepo     SensorRepository
+	access         *AccessPolicy
+	audit          *Audit
+	now            func() time.Time
+	replayWindow   time.Duration
+	gracePeriod    time.Duration
+}
+
+func NewDeviceAuth(cr SensorCredentialRepository, nr EventNonceRepository, sr SensorRepository, options ...func(*DeviceAuth)) *DeviceAuth {
+	d := &DeviceAuth{
+		credentialRepo: cr,
+		nonceRepo:      nr,
+		sensorRepo:     sr,
+		now:            time.Now,
+		replayWindow:   DefaultReplayWindow,
+		gracePeriod:    DefaultSecretGracePeriod,
+	}
+
+	for _, o := range options {
+		o(d)
+	}
+
+	return d
+}
+
+// WithDeviceAuthClock подменяет источник текущего времени, используется в тестах
+func WithDeviceAuthClock(now func() time.Time) func(*DeviceAuth) {
+	return func(d *DeviceAuth) {
+		d.now = now
+	}
+}
+
+// WithReplayWindow задает окно, в котором принимается подпись и запоминаются nonce
+func WithReplayWindow(window time.Duration) func(*DeviceAuth) {
+	return func(d *DeviceAuth) {
+		d.replayWindow = window
+	}
+}
+
+// WithSecretGracePeriod задает, сколько старый секрет действует после ротации
+func WithSecretGracePeriod(grace time.Duration) func(*DeviceAuth) {
+	return func(d *DeviceAuth) {
+		d.gracePeriod = grace
+	}
+}
+
+// WithDeviceAccessPolicy разрешает выпускать и менять секреты только владельцам датчика
+func WithDeviceAccessPolicy(p *AccessPolicy) func(*DeviceAuth) {
+	return func(d *DeviceAuth) {
+		d.access = p
+	}
+}
+
+// WithDeviceAudit записывает выпуск и ротацию секретов датчиков в журнал аудита, сами секреты туда не попадают
+func WithDeviceAudit(a *Audit) func(*DeviceAuth) {
+	return func(d *DeviceAuth) {
+		d.audit = a
+	}
+}
+
+// ProvisionSecret выпускает первый секрет датчика. Если секрет уже выпущен, возвращает ErrSensorAlreadyProvisioned:
+// открытое значение секрета отдается только один раз.
+func (d *DeviceAuth) ProvisionSecret(ctx context.Context, sensorID int64) (string, error) {
+	if err := d.checkSensor(ctx, sensorID); err != nil {
+		return "", err
+	}
+
+	secret, err := newSensorSecret()
+	if err != nil {
+		return "", err
+	}
+
+	credential := &domain.SensorCredential{
+		SensorID:  sensorID,
+		Secret:    secret,
+		RotatedAt: d.now(),
+	}
+	if err := d.credentialRepo.CreateSensorCredential(ctx, credential); err != nil {
+		return "", err
+	}
+
+	if err := d.audit.record(ctx, domain.AuditActionCreate, domain.AuditEntitySensorCredential, sensorID, nil, credential); err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+// RotateSecret выпускает датчику новый секрет. Старый секрет принимается еще в течение периода ротации.
+// Датчику без секрета секрет выпускается впервые.
+func (d *DeviceAuth) RotateSecret(ctx context.Context, sensorID int64) (string, error) {
+	if err := d.checkSensor(ctx, sensorID); err != nil {
+		return "", err
+	}
+
+	credential, err := d.credentialRepo.GetSensorCredential(ctx, sensorID)
+	if errors.Is(err, ErrCredentialNotFound) {
+		return d.ProvisionSecret(ctx, sensorID)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := newSensorSecret()
+	if err != nil {
+		return "", err
+	}
+
+	previous := *credential
+	now := d.now()
+	previousExpiresAt := now.Add(d.gracePeriod)
+	credential.PreviousSecret = credential.Secret
+	credential.PreviousExpiresAt = &previousExpiresAt
+	credential.Secret = secret
+	credential.RotatedAt = now
+
+	if err := d.credentialRepo.SaveSensorCredential(ctx, credential); err != nil {
+		return "", err
+	}
+
+	if err := d.audit.record(ctx, domain.AuditActionRotate, domain.AuditEntitySensorCredential, sensorID, &previous, credential); err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+// VerifyEvent проверяет подпись события датчика с серийным номером sn.
+// Подпись отклоняется, если время подписи вне окна, nonce уже использован или ни один действующий секрет не подходит.
+func (d *DeviceAuth) VerifyEvent(ctx context.Context, sn string, timestamp int64, nonce string, body []byte, signature string) error {
+	if nonce == "" || utf8.RuneCountInString(nonce) > maxNonceLength {
+		return ErrInvalidSignature
+	}
+
+	now := d.now()
+	signedAt := time.Unix(timestamp, 0)
+	if signedAt.Before(now.Add(-d.replayWindow)) || signedAt.After(now.Add(d.replayWindow)) {
+		return ErrInvalidSignature
+	}
+
+	sensor, err := d.sensorRepo.GetSensorBySerialNumber(ctx, sn)
+	if errors.Is(err, ErrSensorNotFound) || (err == nil && sensor == nil) {
+		return ErrInvalidSignature
+	}
+	if err != nil {
+		return err
+	}
+
+	credential, err := d.credentialRepo.GetSensorCredential(ctx, sensor.ID)
+	if errors.Is(err, ErrCredentialNotFound) {
+		return ErrInvalidSignature
+	}
+	if err != nil {
+		return err
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	valid := false
+	for _, secret := range credential.Secrets(now) {
+		if hmac.Equal(expected, eventMAC(secret, timestamp, nonce, body)) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+
+	// nonce запоминается до конца окна: позже подпись отклонится по времени
+	return d.nonceRepo.SaveEventNonce(ctx, sensor.ID, nonce, signedAt.Add(d.replayWindow))
+}
+
+// DeleteExpiredNonces удаляет запомненные nonce, срок которых истек. Вызывается периодически.
+func (d *DeviceAuth) DeleteExpiredNonces(ctx context.Context) error {
+	_, err := d.nonceRepo.DeleteExpiredEventNonces(ctx, d.now())
+	return err
+}
+
+func (d *DeviceAuth) checkSensor(ctx context.Context, sensorID int64) error {
+	sensor, err := d.sensorRepo.GetSensorByID(ctx, sensorID)
+	if err != nil {
+		return err
+	}
+	if sensor == nil {
+		return ErrSensorNotFound
+	}
+
+	return d.access.CheckSensorRole(ctx, sensorID, domain.SensorRoleOwner)
+}
+
+// SignEvent возвращает подпись события в hex: HMAC-SHA256 по секрету датчика
+// от строки "<timestamp>\n<nonce>\n", за которой следует тело запроса
+func SignEvent(secret string, timestamp int64, nonce string, body []byte) string {
+	return hex.EncodeToString(eventMAC(secret, timestamp, nonce, body))
+}
+
+func eventMAC(secret string, timestamp int64, nonce string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "\n" + nonce + "\n"))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+func newSensorSecret() (string, error) {
+	secret := make([]byte, sensorSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return sensorSecretPrefix + hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,252 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_deviceAuth_ProvisionSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, sensor not found", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(nil, nil)
+
+		d := NewDeviceAuth(nil, nil, sr)
+
+		_, err := d.ProvisionSecret(ctx, 1)
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+
+	t.Run("fail, already provisioned", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		cr := NewMockSensorCredentialRepository(ctrl)
+		cr.EXPECT().CreateSensorCredential(ctx, gomock.Any()).Times(1).Return(ErrSensorAlreadyProvisioned)
+
+		d := NewDeviceAuth(cr, nil, sr)
+
+		_, err := d.ProvisionSecret(ctx, 1)
+		assert.ErrorIs(t, err, ErrSensorAlreadyProvisioned)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		var saved *domain.SensorCredential
+		cr := NewMockSensorCredentialRepository(ctrl)
+		cr.EXPECT().CreateSensorCredential(ctx, gomock.Any()).Times(1).DoAndReturn(
+			func(_ context.Context, credential *domain.SensorCredential) error {
+				saved = credential
+				return nil
+			})
+
+		d := NewDeviceAuth(cr, nil, sr)
+
+		secret, err := d.ProvisionSecret(ctx, 1)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(secret, sensorSecretPrefix))
+		assert.Equal(t, secret, saved.Secret)
+		assert.Empty(t, saved.PreviousSecret)
+	})
+}
+
+func Test_deviceAuth_RotateSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("ok, previous secret kept for grace period", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		var saved *domain.SensorCredential
+		cr := NewMockSensorCredentialRepository(ctrl)
+		cr.EXPECT().GetSensorCredential(ctx, int64(1)).Times(1).Return(&domain.SensorCredential{SensorID: 1, Secret: "old"}, nil)
+		cr.EXPECT().SaveSensorCredential(ctx, gomock.Any()).Times(1).DoAndReturn(
+			func(_ context.Context, credential *domain.SensorCredential) error {
+				saved = credential
+				return nil
+			})
+
+		d := NewDeviceAuth(cr, nil, sr, WithDeviceAuthClock(func() time.Time { return now }), WithSecretGracePeriod(time.Hour))
+
+		secret, err := d.RotateSecret(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, secret, saved.Secret)
+		assert.Equal(t, "old", saved.PreviousSecret)
+		require.NotNil(t, saved.PreviousExpiresAt)
+		assert.Equal(t, now.Add(time.Hour), *saved.PreviousExpiresAt)
+	})
+}
+
+func Test_deviceAuth_VerifyEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"sensor_serial_number": "0000000001", "payload": 1}`)
+	sensor := &domain.Sensor{ID: 1, SerialNumber: "0000000001"}
+	graceUntil := now.Add(time.Minute)
+	credential := &domain.SensorCredential{SensorID: 1, Secret: "new", PreviousSecret: "old", PreviousExpiresAt: &graceUntil}
+	clock := WithDeviceAuthClock(func() time.Time { return now })
+
+	t.Run("fail, timestamp out of window", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		d := NewDeviceAuth(nil, nil, nil, clock, WithReplayWindow(time.Minute))
+
+		ts := now.Add(-2 * time.Minute).Unix()
+		err := d.VerifyEvent(ctx, "0000000001", ts, "n1", body, SignEvent("new", ts, "n1", body))
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("fail, wrong secret", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000001").Times(1).Return(sensor, nil)
+
+		cr := NewMockSensorCredentialRepository(ctrl)
+		cr.EXPECT().GetSensorCredential(ctx, int64(1)).Times(1).Return(credential, nil)
+
+		d := NewDeviceAuth(cr, nil, sr, clock)
+
+		ts := now.Unix()
+		err := d.VerifyEvent(ctx, "0000000001", ts, "n1", body, SignEvent("other", ts, "n1", body))
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("fail, tampered body", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000001").Times(1).Return(sensor, nil)
+
+		cr := NewMockSensorCredentialRepository(ctrl)
+		cr.EXPECT().GetSensorCredential(ctx, int64(1)).Times(1).Return(credential, nil)
+
+		d := NewDeviceAuth(cr, nil, sr, clock)
+
+		ts := now.Unix()
+		signature := SignEvent("new", ts, "n1", body)
+		err := d.VerifyEvent(ctx, "0000000001", ts, "n1", []byte(`{"sensor_serial_number": "0000000001", "payload": 2}`), signature)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("fail, previous secret after grace period", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000001").Times(1).Return(sensor, nil)
+
+		cr := NewMockSensorCredentialRepository(ctrl)
+		cr.EXPECT().GetSensorCredential(ctx, int64(1)).Times(1).Return(credential, nil)
+
+		later := now.Add(2 * time.Minute)
+		d := NewDeviceAuth(cr, nil, sr, WithDeviceAuthClock(func() time.Time { return later }))
+
+		ts := later.Unix()
+		err := d.VerifyEvent(ctx, "0000000001", ts, "n1", body, SignEvent("old", ts, "n1", body))
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("ok, previous secret within grace period", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000001").Times(1).Return(sensor, nil)
+
+		cr := NewMockSensorCredentialRepository(ctrl)
+		cr.EXPECT().GetSensorCredential(ctx, int64(1)).Times(1).Return(credential, nil)
+
+		ts := now.Unix()
+		nr := NewMockEventNonceRepository(ctrl)
+		nr.EXPECT().SaveEventNonce(ctx, int64(1), "n1", time.Unix(ts, 0).Add(DefaultReplayWindow)).Times(1).Return(nil)
+
+		d := NewDeviceAuth(cr, nr, sr, clock)
+
+		err := d.VerifyEvent(ctx, "0000000001", ts, "n1", body, SignEvent("old", ts, "n1", body))
+		assert.NoError(t, err)
+	})
+
+	t.Run("fail, replayed nonce", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000001").Times(1).Return(sensor, nil)
+
+		cr := NewMockSensorCredentialRepository(ctrl)
+		cr.EXPECT().GetSensorCredential(ctx, int64(1)).Times(1).Return(credential, nil)
+
+		nr := NewMockEventNonceRepository(ctrl)
+		nr.EXPECT().SaveEventNonce(ctx, int64(1), "n1", gomock.Any()).Times(1).Return(ErrReplayedEvent)
+
+		d := NewDeviceAuth(cr, nr, sr, clock)
+
+		ts := now.Unix()
+		err := d.VerifyEvent(ctx, "0000000001", ts, "n1", body, SignEvent("new", ts, "n1", body))
+		assert.ErrorIs(t, err, ErrReplayedEvent)
+	})
+}
+
+func Test_deviceAuth_DeleteExpiredNonces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := WithDeviceAuthClock(func() time.Time { return now })
+
+	t.Run("ok", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		nr := NewMockEventNonceRepository(ctrl)
+		nr.EXPECT().DeleteExpiredEventNonces(ctx, now).Times(1).Return(3, nil)
+
+		d := NewDeviceAuth(nil, nr, nil, clock)
+		assert.NoError(t, d.DeleteExpiredNonces(ctx))
+	})
+
+	t.Run("fail, repository error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		expectedError := errors.New("database error")
+		nr := NewMockEventNonceRepository(ctrl)
+		nr.EXPECT().DeleteExpiredEventNonces(ctx, now).Times(1).Return(0, expectedError)
+
+		d := NewDeviceAuth(nil, nr, nil, clock)
+		assert.ErrorIs(t, d.DeleteExpiredNonces(ctx), expectedError)
+	})
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_sensor_ReportDeviceInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, invalid info", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		s := NewSensor(nil)
+
+		_, err := s.ReportDeviceInfo(ctx, 1, domain.DeviceInfo{})
+		assert.ErrorIs(t, err, ErrInvalidDeviceInfo)
+
+		_, err = s.ReportDeviceInfo(ctx, 1, domain.DeviceInfo{Model: strings.Repeat("x", 65)})
+		assert.ErrorIs(t, err, ErrInvalidDeviceInfo)
+	})
+
+	t.Run("ok, firmware change is recorded", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{
+			ID:              1,
+			Manufacturer:    "Acme",
+			Model:           "T-100",
+			FirmwareVersion: "1.0.0",
+		}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Return(nil)
+
+		fr := NewMockFirmwareHistoryRepository(ctrl)
+		fr.EXPECT().SaveFirmwareChange(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, change *domain.FirmwareChange) {
+			assert.Equal(t, int64(1), change.SensorID)
+			assert.Equal(t, "1.0.0", change.PreviousVersion)
+			assert.Equal(t, "1.1.0", change.Version)
+		})
+
+		s := NewSensor(sr, WithFirmwareHistory(fr))
+
+		sensor, err := s.ReportDeviceInfo(ctx, 1, domain.DeviceInfo{HardwareRevision: "B", FirmwareVersion: "1.1.0"})
+		require.NoError(t, err)
+		assert.Equal(t, "Acme", sensor.Manufacturer)
+		assert.Equal(t, "B", sensor.HardwareRevision)
+		assert.Equal(t, "1.1.0", sensor.FirmwareVersion)
+	})
+
+	t.Run("ok, same firmware is not recorded", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1, FirmwareVersion: "1.0.0"}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Return(nil)
+
+		fr := NewMockFirmwareHistoryRepository(ctrl)
+		fr.EXPECT().SaveFirmwareChange(gomock.Any(), gomock.Any()).Times(0)
+
+		s := NewSensor(sr, WithFirmwareHistory(fr))
+
+		_, err := s.ReportDeviceInfo(ctx, 1, domain.DeviceInfo{FirmwareVersion: "1.0.0"})
+		assert.NoError(t, err)
+	})
+}
+
+func Test_sensor_GetInventory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sr := NewMockSensorRepository(ctrl)
+	sr.EXPECT().GetSensors(ctx).Times(1).Return([]domain.Sensor{
+		{ID: 3, Manufacturer: "Acme", Model: "T-100", FirmwareVersion: "1.1.0"},
+		{ID: 1, Manufacturer: "Acme", Model: "T-100", FirmwareVersion: "1.0.0"},
+		{ID: 4},
+		{ID: 2, Manufacturer: "Acme", Model: "T-100", FirmwareVersion: "1.1.0"},
+	}, nil)
+
+	s := NewSensor(sr)
+
+	inventory, err := s.GetInventory(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.InventoryGroup{
+		{SensorIDs: []int64{4}},
+		{Manufacturer: "Acme", Model: "T-100", FirmwareVersion: "1.0.0", SensorIDs: []int64{1}},
+		{Manufacturer: "Acme", Model: "T-100", FirmwareVersion: "1.1.0", SensorIDs: []int64{2, 3}},
+	}, inventory)
+}
+
+func Test_event_ReceiveEventWithDeviceInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, invalid device info", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		e := NewEvent(nil, nil)
+
+		err := e.ReceiveEvent(ctx, &domain.Event{Timestamp: time.Now(), Device: &domain.DeviceInfo{}})
+		assert.ErrorIs(t, err, ErrInvalidDeviceInfo)
+	})
+
+	t.Run("ok, firmware reported with event", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ts := time.Now()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{ID: 1}, nil)
+		sr.EXPECT().UpdateSensorState(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, sensor *domain.Sensor) {
+			assert.Equal(t, "2.0.0", sensor.FirmwareVersion)
+			assert.Equal(t, int64(5), sensor.CurrentState)
+		})
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Return(nil)
+
+		fr := NewMockFirmwareHistoryRepository(ctrl)
+		fr.EXPECT().SaveFirmwareChange(ctx, &domain.FirmwareChange{
+			SensorID:  1,
+			Version:   "2.0.0",
+			ChangedAt: ts,
+		}).Times(1).Return(nil)
+
+		e := NewEvent(er, sr, WithEventFirmwareHistory(fr))
+
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          ts,
+			SensorSerialNumber: "0123456789",
+			Payload:            5,
+			Device:             &domain.DeviceInfo{FirmwareVersion: "2.0.0"},
+		})
+		assert.NoError(t, err)
+	})
+}
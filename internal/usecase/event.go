@@ -2,19 +2,86 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"homework/internal/domain"
+	"log"
 	"time"
 )
 
 type Event struct {
-	eventRepo  EventRepository
-	sensorRepo SensorRepository
+	eventRepo    EventRepository
+	sensorRepo   SensorRepository
+	firmwareRepo FirmwareHistoryRepository
+	access       *AccessPolicy
+	rules        *Rule
+	automations  *Automation
+	anomalies    *Anomaly
+	debounce     *Debounce
+	virtualRepo  VirtualSensorRepository
+	outbox       *Outbox
+	transactor   Transactor
 }
 
-func NewEvent(er EventRepository, sr SensorRepository) *Event {
-	return &Event{
+func NewEvent(er EventRepository, sr SensorRepository, options ...func(*Event)) *Event {
+	e := &Event{
 		eventRepo:  er,
 		sensorRepo: sr,
+		transactor: noTransaction{},
+	}
+
+	for _, o := range options {
+		o(e)
+	}
+
+	return e
+}
+
+// WithEventAccessPolicy ограничивает чтение событий датчиками пользователя из контекста
+func WithEventAccessPolicy(p *AccessPolicy) func(*Event) {
+	return func(e *Event) {
+		e.access = p
+	}
+}
+
+// WithEventRules вычисляет правила оповещений по каждому принятому событию
+func WithEventRules(r *Rule) func(*Event) {
+	return func(e *Event) {
+		e.rules = r
+	}
+}
+
+// WithEventAutomations запускает автоматизации, триггеры которых срабатывают на принятое событие
+func WithEventAutomations(a *Automation) func(*Event) {
+	return func(e *Event) {
+		e.automations = a
+	}
+}
+
+// WithEventAnomalies оценивает аномальность событий датчиков с настройками обнаружения аномалий
+func WithEventAnomalies(a *Anomaly) func(*Event) {
+	return func(e *Event) {
+		e.anomalies = a
+	}
+}
+
+// WithEventDebounce подавляет дребезг и отмечает нестабильные датчики сухого контакта с настройками Debounce
+func WithEventDebounce(d *Debounce) func(*Event) {
+	return func(e *Event) {
+		e.debounce = d
+	}
+}
+
+// WithEventOutbox сохраняет каждое принятое событие в outbox для доставки внешним получателям
+func WithEventOutbox(o *Outbox) func(*Event) {
+	return func(e *Event) {
+		e.outbox = o
+	}
+}
+
+// WithEventTransactor задает транзакцию, в которой сохраняются событие, состояние датчика и запись outbox
+func WithEventTransactor(t Transactor) func(*Event) {
+	return func(e *Event) {
+		e.transactor = t
 	}
 }
 
@@ -23,6 +90,12 @@ func (e *Event) ReceiveEvent(ctx context.Context, event *domain.Event) error {
 		return ErrInvalidEventTimestamp
 	}
 
+	if event.Device != nil {
+		if err := isDeviceInfoValid(*event.Device); err != nil {
+			return err
+		}
+	}
+
 	sensor, err := e.sensorRepo.GetSensorBySerialNumber(ctx, event.SensorSerialNumber)
 	if err != nil {
 		return err
@@ -30,19 +103,135 @@ func (e *Event) ReceiveEvent(ctx context.Context, event *domain.Event) error {
 	if sensor == nil {
 		return ErrSensorNotFound
 	}
+	if sensor.Type == domain.SensorTypeVirtual {
+		return ErrVirtualSensorEvent
+	}
 
 	event.SensorID = sensor.ID
 
-	if err := e.eventRepo.SaveEvent(ctx, event); err != nil {
+	if e.debounce == nil {
+		return e.accept(ctx, sensor, event)
+	}
+	events, flapping := e.debounce.receive(sensor, event)
+	return e.acceptDebounced(ctx, sensor, events, flapping)
+}
+
+// FlushDebouncedEvents принимает отложенные смены состояния датчиков сухого контакта, продержавшиеся окно,
+// и обновляет отметку о нестабильности датчиков, переставших или начавших часто переключаться.
+// Вызывается периодически.
+func (e *Event) FlushDebouncedEvents(ctx context.Context) error {
+	if e.debounce == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, item := range e.debounce.expire() {
+		sensor, err := e.sensorRepo.GetSensorByID(ctx, item.sensorID)
+		if errors.Is(err, ErrSensorNotFound) || err == nil && sensor == nil {
+			e.debounce.forget(item.sensorID)
+			continue
+		}
+		if err == nil {
+			err = e.acceptDebounced(ctx, sensor, item.events, item.flapping)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// acceptDebounced принимает события датчика, пропущенные подавлением дребезга, и сохраняет его нестабильность
+func (e *Event) acceptDebounced(ctx context.Context, sensor *domain.Sensor, events []*domain.Event, flapping bool) error {
+	changed := sensor.Flapping != flapping
+	sensor.Flapping = flapping
+
+	if len(events) == 0 {
+		if !changed {
+			return nil
+		}
+		return e.sensorRepo.UpdateSensorState(ctx, sensor)
+	}
+
+	for _, event := range events {
+		if err := e.accept(ctx, sensor, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// accept сохраняет событие датчика и новое состояние датчика, затем обрабатывает событие (process).
+// Датчик прочитан до транзакции, поэтому сохраняется только его состояние, а настройки, измененные
+// за это время, не затираются. Ошибка возвращается, только если событие не сохранено.
+func (e *Event) accept(ctx context.Context, sensor *domain.Sensor, event *domain.Event) error {
+	previous := sensor.CurrentState
+
+	if e.anomalies != nil {
+		if err := e.anomalies.score(ctx, sensor, event); err != nil {
+			return err
+		}
+	}
+
+	err := e.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := e.eventRepo.SaveEvent(ctx, event); err != nil {
+			return err
+		}
+		sensor.CurrentState = event.Payload
+		sensor.LastActivity = time.Now()
+
+		if event.Device != nil {
+			if err := applyDeviceInfo(ctx, e.firmwareRepo, sensor, *event.Device, event.Timestamp); err != nil {
+				return err
+			}
+		}
+
+		if err := e.sensorRepo.UpdateSensorState(ctx, sensor); err != nil {
+			return err
+		}
+
+		return e.outbox.addSensorEvent(ctx, event)
+	})
+	if err != nil {
 		return err
 	}
-	sensor.CurrentState = event.Payload
-	sensor.LastActivity = time.Now()
 
-	return e.sensorRepo.SaveSensor(ctx, sensor)
+	if e.anomalies != nil {
+		e.anomalies.observe(sensor, event)
+	}
+
+	e.process(ctx, sensor, previous, event)
+	return nil
+}
+
+// process вычисляет по сохраненному событию аномалии, правила, автоматизации и вычисляемые датчики.
+// Ошибки только пишутся в лог: событие уже принято, и повторная отправка датчиком сохранила бы его дважды.
+func (e *Event) process(ctx context.Context, sensor *domain.Sensor, previous int64, event *domain.Event) {
+	if e.anomalies != nil {
+		if err := e.anomalies.evaluateEvent(ctx, sensor, event); err != nil {
+			log.Printf("sensor %d: anomaly evaluation error: %v", sensor.ID, err)
+		}
+	}
+	if e.rules != nil {
+		if err := e.rules.EvaluateEvent(ctx, sensor, event); err != nil {
+			log.Printf("sensor %d: rule evaluation error: %v", sensor.ID, err)
+		}
+	}
+	if e.automations != nil {
+		if err := e.automations.HandleEvent(ctx, sensor, previous, event); err != nil {
+			log.Printf("sensor %d: automation error: %v", sensor.ID, err)
+		}
+	}
+	if err := e.computeVirtualSensors(ctx, sensor.ID, event.Timestamp); err != nil {
+		log.Printf("sensor %d: virtual sensors error: %v", sensor.ID, err)
+	}
 }
 
 func (e *Event) GetLastEventBySensorID(ctx context.Context, id int64) (*domain.Event, error) {
+	if err := e.access.CheckSensor(ctx, id); err != nil {
+		return nil, err
+	}
+
 	event, err := e.eventRepo.GetLastEventBySensorID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -62,10 +251,21 @@ func (e *Event) GetSensorHistory(ctx context.Context, id int64, startDate, endDa
 		return nil, ErrSensorNotFound
 	}
 
+	if err := e.access.CheckSensor(ctx, id); err != nil {
+		return nil, err
+	}
+
 	if historyRepo, ok := e.eventRepo.(interface {
 		GetEventsHistoryBySensorID(ctx context.Context, id int64, startDate, endDate time.Time) ([]domain.Event, error)
 	}); ok {
-		return historyRepo.GetEventsHistoryBySensorID(ctx, id, startDate, endDate)
+		events, err := historyRepo.GetEventsHistoryBySensorID(ctx, id, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		for i := range events {
+			sensor.ApplyCalibration(&events[i])
+		}
+		return events, nil
 	}
 
 	return []domain.Event{}, nil
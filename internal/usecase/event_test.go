@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"homework/internal/domain"
 	"testing"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_event_ReceiveEvent(t *testing.T) {
@@ -74,7 +76,7 @@ func Test_event_ReceiveEvent(t *testing.T) {
 			ID: 1,
 		}, nil)
 		expectedError := errors.New("some error")
-		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Times(1).Return(expectedError)
+		sr.EXPECT().UpdateSensorState(ctx, gomock.Any()).Times(1).Times(1).Return(expectedError)
 
 		er := NewMockEventRepository(ctrl)
 		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Return(nil)
@@ -97,7 +99,7 @@ func Test_event_ReceiveEvent(t *testing.T) {
 		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
 			ID: 1,
 		}, nil)
-		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, s *domain.Sensor) {
+		sr.EXPECT().UpdateSensorState(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, s *domain.Sensor) {
 			assert.Equal(t, int64(8), s.CurrentState)
 			assert.NotEmpty(t, s.LastActivity)
 		})
@@ -118,4 +120,79 @@ func Test_event_ReceiveEvent(t *testing.T) {
 		})
 		assert.NoError(t, err)
 	})
+	t.Run("ok, processing errors after save don't fail the event", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		expectedError := errors.New("some error")
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{ID: 1}, nil)
+		sr.EXPECT().UpdateSensorState(ctx, gomock.Any()).Times(1).Return(nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Return(nil)
+
+		rr := NewMockRuleRepository(ctrl)
+		rr.EXPECT().GetRulesBySensorID(ctx, int64(1)).Times(1).Return(nil, expectedError)
+
+		ar := NewMockAutomationRepository(ctrl)
+		ar.EXPECT().GetAutomationsByTriggerSensorID(ctx, int64(1)).Times(1).Return(nil, expectedError)
+
+		vr := NewMockVirtualSensorRepository(ctrl)
+		vr.EXPECT().GetVirtualSensorIDsByInputID(ctx, int64(1)).Times(1).Return(nil, expectedError)
+
+		e := NewEvent(er, sr,
+			WithEventRules(NewRule(rr, sr, nil)),
+			WithEventAutomations(NewAutomation(ar, sr, nil)),
+			WithEventVirtualSensors(vr),
+		)
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "0123456789",
+			Payload:            8,
+		})
+		assert.NoError(t, err, "the event is saved, a retry would duplicate it")
+	})
+	t.Run("ok, outbox entry saved in the same transaction", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		type txKey struct{}
+		txCtx := context.WithValue(ctx, txKey{}, "tx")
+		now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		tr := NewMockTransactor(ctrl)
+		tr.EXPECT().WithinTransaction(ctx, gomock.Any()).Times(1).
+			DoAndReturn(func(_ context.Context, fn func(ctx context.Context) error) error {
+				return fn(txCtx)
+			})
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{ID: 1}, nil)
+		sr.EXPECT().UpdateSensorState(txCtx, gomock.Any()).Times(1).Return(nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(txCtx, gomock.Any()).Times(1).Return(nil)
+
+		or := NewMockOutboxRepository(ctrl)
+		or.EXPECT().AddOutboxEntry(txCtx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, entry *domain.OutboxEntry) error {
+			assert.Equal(t, int64(1), entry.SensorID)
+			assert.Equal(t, domain.OutboxSensorEvent, entry.Kind)
+			assert.Equal(t, now, entry.NextAttemptAt)
+
+			var event domain.Event
+			require.NoError(t, json.Unmarshal(entry.Payload, &event))
+			assert.Equal(t, int64(8), event.Payload)
+			return nil
+		})
+
+		outbox := NewOutbox(or, WithOutboxClock(func() time.Time { return now }))
+		e := NewEvent(er, sr, WithEventTransactor(tr), WithEventOutbox(outbox))
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "0123456789",
+			Payload:            8,
+		})
+		assert.NoError(t, err)
+	})
 }
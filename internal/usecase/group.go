@@ -0,0 +1,281 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"strings"
+)
+
+// Group - группы пользователей с общими датчиками, например семья в одном доме.
+// Управлять составом группы может любой ее участник, привязывать и отвязывать датчики - только их владельцы.
+type Group struct {
+	groupRepo  GroupRepository
+	userRepo   UserRepository
+	sensorRepo SensorRepository
+	access     *AccessPolicy
+	audit      *Audit
+}
+
+func NewGroup(gr GroupRepository, ur UserRepository, sr SensorRepository, options ...func(*Group)) *Group {
+	g := &Group{
+		groupRepo:  gr,
+		userRepo:   ur,
+		sensorRepo: sr,
+	}
+
+	for _, o := range options {
+		o(g)
+	}
+
+	return g
+}
+
+// WithGroupAccessPolicy ограничивает группы группами пользователя из контекста, делает создателя группы ее участником
+// и закрывает подписки на датчики, доступ к которым пропал после изменения группы
+func WithGroupAccessPolicy(p *AccessPolicy) func(*Group) {
+	return func(g *Group) {
+		g.access = p
+	}
+}
+
+// WithGroupAudit записывает изменения групп, их состава и датчиков в журнал аудита
+func WithGroupAudit(a *Audit) func(*Group) {
+	return func(g *Group) {
+		g.audit = a
+	}
+}
+
+func (g *Group) CreateGroup(ctx context.Context, group *domain.Group) (*domain.Group, error) {
+	if group == nil {
+		return nil, ErrGroupNotFound
+	}
+
+	group.Name = strings.TrimSpace(group.Name)
+	if group.Name == "" {
+		return nil, ErrInvalidGroupName
+	}
+
+	group.ID = 0
+	if err := g.groupRepo.SaveGroup(ctx, group); err != nil {
+		return nil, err
+	}
+
+	if caller, ok := g.access.restricted(ctx); ok {
+		member := domain.GroupMember{GroupID: group.ID, UserID: caller.ID}
+		if err := g.groupRepo.SaveGroupMember(ctx, member); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := g.audit.record(ctx, domain.AuditActionCreate, domain.AuditEntityGroup, group.ID, nil, group); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (g *Group) GetGroups(ctx context.Context) ([]domain.Group, error) {
+	groups, err := g.groupRepo.GetGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	caller, ok := g.access.restricted(ctx)
+	if !ok {
+		return groups, nil
+	}
+
+	memberships, err := g.groupRepo.GetGroupsByUserID(ctx, caller.ID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[int64]struct{}, len(memberships))
+	for _, membership := range memberships {
+		ids[membership.GroupID] = struct{}{}
+	}
+
+	result := make([]domain.Group, 0, len(ids))
+	for _, group := range groups {
+		if _, ok := ids[group.ID]; ok {
+			result = append(result, group)
+		}
+	}
+	return result, nil
+}
+
+// GetGroupByID возвращает группу. Для пользователя не из группы она неотличима от несуществующей.
+func (g *Group) GetGroupByID(ctx context.Context, id int64) (*domain.Group, error) {
+	group, err := g.groupRepo.GetGroupByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, ErrGroupNotFound
+	}
+
+	if caller, ok := g.access.restricted(ctx); ok {
+		members, err := g.groupRepo.GetMembersByGroupID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !isGroupMember(members, caller.ID) {
+			return nil, ErrGroupNotFound
+		}
+	}
+
+	return group, nil
+}
+
+// DeleteGroup удаляет группу, ее участники теряют доступ к датчикам группы. Сами датчики не удаляются.
+func (g *Group) DeleteGroup(ctx context.Context, id int64) error {
+	group, err := g.GetGroupByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	members, err := g.groupRepo.GetMembersByGroupID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := g.groupRepo.DeleteGroup(ctx, id); err != nil {
+		return err
+	}
+
+	g.access.accessChanged(ctx, memberIDs(members)...)
+	return g.audit.record(ctx, domain.AuditActionDelete, domain.AuditEntityGroup, id, group, nil)
+}
+
+func (g *Group) AddGroupMember(ctx context.Context, groupID, userID int64) error {
+	if _, err := g.GetGroupByID(ctx, groupID); err != nil {
+		return err
+	}
+
+	user, err := g.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	if err := g.groupRepo.SaveGroupMember(ctx, domain.GroupMember{GroupID: groupID, UserID: userID}); err != nil {
+		return err
+	}
+
+	return g.audit.record(ctx, domain.AuditActionAttach, domain.AuditEntityGroup, groupID, nil, auditLink("user", userID, true))
+}
+
+// RemoveGroupMember исключает пользователя из группы, его подписки на датчики группы сразу закрываются
+func (g *Group) RemoveGroupMember(ctx context.Context, groupID, userID int64) error {
+	if _, err := g.GetGroupByID(ctx, groupID); err != nil {
+		return err
+	}
+
+	if err := g.groupRepo.DeleteGroupMember(ctx, groupID, userID); err != nil {
+		return err
+	}
+
+	g.access.accessChanged(ctx, userID)
+	return g.audit.record(ctx, domain.AuditActionDetach, domain.AuditEntityGroup, groupID, auditLink("user", userID, true), nil)
+}
+
+func (g *Group) GetGroupMembers(ctx context.Context, groupID int64) ([]domain.GroupMember, error) {
+	if _, err := g.GetGroupByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	return g.groupRepo.GetMembersByGroupID(ctx, groupID)
+}
+
+// AttachSensorToGroup дает участникам группы доступ к датчику с ролью role, для уже привязанного датчика меняет роль.
+// Привязывать датчик может только его владелец.
+func (g *Group) AttachSensorToGroup(ctx context.Context, groupID, sensorID int64, role domain.SensorRole) error {
+	if !role.IsValid() {
+		return ErrInvalidSensorRole
+	}
+
+	if _, err := g.GetGroupByID(ctx, groupID); err != nil {
+		return err
+	}
+
+	sensor, err := g.sensorRepo.GetSensorByID(ctx, sensorID)
+	if err != nil {
+		return err
+	}
+	if sensor == nil {
+		return ErrSensorNotFound
+	}
+	if err := g.access.CheckSensorRole(ctx, sensorID, domain.SensorRoleOwner); err != nil {
+		return err
+	}
+
+	err = g.groupRepo.SaveGroupSensor(ctx, domain.GroupSensor{
+		GroupID:  groupID,
+		SensorID: sensorID,
+		Role:     role,
+	})
+	if err != nil {
+		return err
+	}
+
+	return g.audit.record(ctx, domain.AuditActionAttach, domain.AuditEntityGroup, groupID, nil, auditLink("sensor", sensorID, role))
+}
+
+// DetachSensorFromGroup отвязывает датчик от группы, подписки участников, потерявших доступ, сразу закрываются.
+// Отвязывать датчик может только его владелец.
+func (g *Group) DetachSensorFromGroup(ctx context.Context, groupID, sensorID int64) error {
+	if _, err := g.GetGroupByID(ctx, groupID); err != nil {
+		return err
+	}
+	if err := g.access.CheckSensorRole(ctx, sensorID, domain.SensorRoleOwner); err != nil {
+		return err
+	}
+
+	groupSensors, err := g.groupRepo.GetSensorsByGroupID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	var role domain.SensorRole
+	for _, groupSensor := range groupSensors {
+		if groupSensor.SensorID == sensorID {
+			role = groupSensor.Role
+		}
+	}
+
+	if err := g.groupRepo.DeleteGroupSensor(ctx, groupID, sensorID); err != nil {
+		return err
+	}
+
+	members, err := g.groupRepo.GetMembersByGroupID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	g.access.accessChanged(ctx, memberIDs(members)...)
+	return g.audit.record(ctx, domain.AuditActionDetach, domain.AuditEntityGroup, groupID, auditLink("sensor", sensorID, role), nil)
+}
+
+func (g *Group) GetGroupSensors(ctx context.Context, groupID int64) ([]domain.GroupSensor, error) {
+	if _, err := g.GetGroupByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	return g.groupRepo.GetSensorsByGroupID(ctx, groupID)
+}
+
+func isGroupMember(members []domain.GroupMember, userID int64) bool {
+	for _, member := range members {
+		if member.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func memberIDs(members []domain.GroupMember) []int64 {
+	ids := make([]int64, len(members))
+	for i, member := range members {
+		ids[i] = member.UserID
+	}
+	return ids
+}
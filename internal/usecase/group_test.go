@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_group_CreateGroup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, empty name", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		g := NewGroup(nil, nil, nil)
+
+		_, err := g.CreateGroup(ctx, &domain.Group{Name: "  "})
+		assert.ErrorIs(t, err, ErrInvalidGroupName)
+	})
+
+	t.Run("ok, creator becomes member", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 7})
+
+		gr := NewMockGroupRepository(ctrl)
+		gr.EXPECT().SaveGroup(ctx, &domain.Group{Name: "Семья"}).Times(1).Do(func(_ context.Context, g *domain.Group) {
+			g.ID = 1
+		})
+		gr.EXPECT().SaveGroupMember(ctx, domain.GroupMember{GroupID: 1, UserID: 7}).Times(1).Return(nil)
+
+		g := NewGroup(gr, nil, nil, WithGroupAccessPolicy(NewAccessPolicy(nil, nil, nil)))
+
+		group, err := g.CreateGroup(ctx, &domain.Group{Name: " Семья "})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), group.ID)
+	})
+}
+
+func Test_group_GetGroupByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, not a member", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 3})
+
+		gr := NewMockGroupRepository(ctrl)
+		gr.EXPECT().GetGroupByID(ctx, int64(1)).Times(1).Return(&domain.Group{ID: 1, Name: "Семья"}, nil)
+		gr.EXPECT().GetMembersByGroupID(ctx, int64(1)).Times(1).Return([]domain.GroupMember{{GroupID: 1, UserID: 1}}, nil)
+
+		g := NewGroup(gr, nil, nil, WithGroupAccessPolicy(NewAccessPolicy(nil, nil, nil)))
+
+		_, err := g.GetGroupByID(ctx, 1)
+		assert.ErrorIs(t, err, ErrGroupNotFound)
+	})
+}
+
+func Test_group_AttachSensorToGroup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, invalid role", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		g := NewGroup(nil, nil, nil)
+
+		err := g.AttachSensorToGroup(ctx, 1, 1, "admin")
+		assert.ErrorIs(t, err, ErrInvalidSensorRole)
+	})
+
+	t.Run("fail, not an owner of the sensor", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 1})
+
+		gr := NewMockGroupRepository(ctrl)
+		gr.EXPECT().GetGroupByID(ctx, int64(1)).Times(1).Return(&domain.Group{ID: 1}, nil)
+		gr.EXPECT().GetMembersByGroupID(ctx, int64(1)).Times(1).Return([]domain.GroupMember{{GroupID: 1, UserID: 1}}, nil)
+		gr.EXPECT().GetGroupsByUserID(ctx, int64(1)).Times(1).Return(nil, nil)
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(2)).Times(1).Return(&domain.Sensor{ID: 2}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Times(1).
+			Return([]domain.SensorOwner{{UserID: 1, SensorID: 2, Role: domain.SensorRoleOperator}}, nil)
+
+		g := NewGroup(gr, nil, sr, WithGroupAccessPolicy(NewAccessPolicy(sor, nil, nil, WithGroupAccess(gr))))
+
+		err := g.AttachSensorToGroup(ctx, 1, 2, domain.SensorRoleViewer)
+		assert.ErrorIs(t, err, ErrInsufficientRole)
+	})
+}
@@ -0,0 +1,445 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"strings"
+	"time"
+)
+
+type Home struct {
+	homeRepo      HomeRepository
+	roomRepo      RoomRepository
+	homeOwnerRepo HomeOwnerRepository
+	sensorRepo    SensorRepository
+	userRepo      UserRepository
+	access        *AccessPolicy
+	audit         *Audit
+	transactor    Transactor
+}
+
+func NewHome(
+	hr HomeRepository,
+	rr RoomRepository,
+	hor HomeOwnerRepository,
+	sr SensorRepository,
+	ur UserRepository,
+	options ...func(*Home),
+) *Home {
+	h := &Home{
+		homeRepo:      hr,
+		roomRepo:      rr,
+		homeOwnerRepo: hor,
+		sensorRepo:    sr,
+		userRepo:      ur,
+		transactor:    noTransaction{},
+	}
+
+	for _, o := range options {
+		o(h)
+	}
+
+	return h
+}
+
+// WithHomeAccessPolicy ограничивает дома и комнаты домами пользователя из контекста
+// и дает создателю дома доступ к нему
+func WithHomeAccessPolicy(p *AccessPolicy) func(*Home) {
+	return func(h *Home) {
+		h.access = p
+	}
+}
+
+// WithHomeAudit записывает изменения домов и комнат в журнал аудита
+func WithHomeAudit(a *Audit) func(*Home) {
+	return func(h *Home) {
+		h.audit = a
+	}
+}
+
+// WithHomeTransactor задает транзакцию для удаления дома вместе с комнатами и участниками
+func WithHomeTransactor(t Transactor) func(*Home) {
+	return func(h *Home) {
+		h.transactor = t
+	}
+}
+
+func (h *Home) CreateHome(ctx context.Context, home *domain.Home) (*domain.Home, error) {
+	if home == nil {
+		return nil, ErrHomeNotFound
+	}
+
+	if err := validateHome(home); err != nil {
+		return nil, err
+	}
+
+	home.ID = 0
+	if err := h.homeRepo.SaveHome(ctx, home); err != nil {
+		return nil, err
+	}
+
+	if err := h.access.grantHome(ctx, home.ID); err != nil {
+		return nil, err
+	}
+
+	if err := h.audit.record(ctx, domain.AuditActionCreate, domain.AuditEntityHome, home.ID, nil, home); err != nil {
+		return nil, err
+	}
+
+	return home, nil
+}
+
+func (h *Home) GetHomes(ctx context.Context) ([]domain.Home, error) {
+	homes, err := h.homeRepo.GetHomes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.access.FilterHomes(ctx, homes)
+}
+
+func (h *Home) GetHomeByID(ctx context.Context, id int64) (*domain.Home, error) {
+	home, err := h.homeRepo.GetHomeByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if home == nil {
+		return nil, ErrHomeNotFound
+	}
+
+	if err := h.access.CheckHome(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return home, nil
+}
+
+func (h *Home) UpdateHome(ctx context.Context, home *domain.Home) (*domain.Home, error) {
+	if home == nil {
+		return nil, ErrHomeNotFound
+	}
+
+	existingHome, err := h.GetHomeByID(ctx, home.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.access.CheckHomeRole(ctx, home.ID, domain.HomeRoleOwner); err != nil {
+		return nil, err
+	}
+
+	if err := validateHome(home); err != nil {
+		return nil, err
+	}
+
+	if err := h.homeRepo.SaveHome(ctx, home); err != nil {
+		return nil, err
+	}
+
+	if err := h.audit.record(ctx, domain.AuditActionUpdate, domain.AuditEntityHome, home.ID, existingHome, home); err != nil {
+		return nil, err
+	}
+
+	return home, nil
+}
+
+// DeleteHome удаляет дом вместе с его комнатами, размещением датчиков и доступами пользователей.
+// Сами датчики при этом не удаляются.
+func (h *Home) DeleteHome(ctx context.Context, id int64) error {
+	home, err := h.GetHomeByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := h.access.CheckHomeRole(ctx, id, domain.HomeRoleOwner); err != nil {
+		return err
+	}
+
+	return h.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		rooms, err := h.roomRepo.GetRoomsByHomeID(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, room := range rooms {
+			if err := h.roomRepo.DeleteRoom(ctx, room.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := h.homeOwnerRepo.DeleteHomeOwnersByHomeID(ctx, id); err != nil {
+			return err
+		}
+
+		if err := h.homeRepo.DeleteHome(ctx, id); err != nil {
+			return err
+		}
+
+		return h.audit.record(ctx, domain.AuditActionDelete, domain.AuditEntityHome, id, home, nil)
+	})
+}
+
+func (h *Home) CreateRoom(ctx context.Context, room *domain.Room) (*domain.Room, error) {
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	if _, err := h.GetHomeByID(ctx, room.HomeID); err != nil {
+		return nil, err
+	}
+
+	room.Name = strings.TrimSpace(room.Name)
+	if room.Name == "" {
+		return nil, ErrInvalidRoomName
+	}
+
+	room.ID = 0
+	if err := h.roomRepo.SaveRoom(ctx, room); err != nil {
+		return nil, err
+	}
+
+	if err := h.audit.record(ctx, domain.AuditActionCreate, domain.AuditEntityRoom, room.ID, nil, room); err != nil {
+		return nil, err
+	}
+
+	return room, nil
+}
+
+func (h *Home) GetHomeRooms(ctx context.Context, homeID int64) ([]domain.Room, error) {
+	if _, err := h.GetHomeByID(ctx, homeID); err != nil {
+		return nil, err
+	}
+
+	return h.roomRepo.GetRoomsByHomeID(ctx, homeID)
+}
+
+func (h *Home) GetRoomByID(ctx context.Context, id int64) (*domain.Room, error) {
+	room, err := h.roomRepo.GetRoomByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	if err := h.access.CheckHome(ctx, room.HomeID); err != nil {
+		return nil, ErrRoomNotFound
+	}
+
+	return room, nil
+}
+
+// UpdateRoom переименовывает комнату. Перенос комнаты в другой дом не поддерживается.
+func (h *Home) UpdateRoom(ctx context.Context, room *domain.Room) (*domain.Room, error) {
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	existingRoom, err := h.GetRoomByID(ctx, room.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	room.Name = strings.TrimSpace(room.Name)
+	if room.Name == "" {
+		return nil, ErrInvalidRoomName
+	}
+	room.HomeID = existingRoom.HomeID
+
+	if err := h.roomRepo.SaveRoom(ctx, room); err != nil {
+		return nil, err
+	}
+
+	if err := h.audit.record(ctx, domain.AuditActionUpdate, domain.AuditEntityRoom, room.ID, existingRoom, room); err != nil {
+		return nil, err
+	}
+
+	return room, nil
+}
+
+func (h *Home) DeleteRoom(ctx context.Context, id int64) error {
+	room, err := h.GetRoomByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := h.roomRepo.DeleteRoom(ctx, id); err != nil {
+		return err
+	}
+
+	return h.audit.record(ctx, domain.AuditActionDelete, domain.AuditEntityRoom, id, room, nil)
+}
+
+// AssignSensorToRoom размещает датчик в комнате. Если датчик уже был в другой комнате, он переносится.
+// Размещение дает участникам дома доступ к датчику, поэтому размещать датчик может только его владелец.
+func (h *Home) AssignSensorToRoom(ctx context.Context, roomID, sensorID int64) error {
+	if _, err := h.GetRoomByID(ctx, roomID); err != nil {
+		return err
+	}
+
+	sensor, err := h.sensorRepo.GetSensorByID(ctx, sensorID)
+	if err != nil {
+		return err
+	}
+	if sensor == nil {
+		return ErrSensorNotFound
+	}
+	if err := h.access.CheckSensorRole(ctx, sensorID, domain.SensorRoleOwner); err != nil {
+		return err
+	}
+
+	err = h.roomRepo.SaveSensorRoom(ctx, domain.SensorRoom{
+		SensorID: sensorID,
+		RoomID:   roomID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.audit.record(ctx, domain.AuditActionAttach, domain.AuditEntityRoom, roomID, nil, auditLink("sensor", sensorID, true))
+}
+
+func (h *Home) GetRoomSensors(ctx context.Context, roomID int64) ([]domain.Sensor, error) {
+	if _, err := h.GetRoomByID(ctx, roomID); err != nil {
+		return nil, err
+	}
+
+	return roomSensors(ctx, h.roomRepo, h.sensorRepo, roomID)
+}
+
+func (h *Home) GetHomeSensors(ctx context.Context, homeID int64) ([]domain.Sensor, error) {
+	if _, err := h.GetHomeByID(ctx, homeID); err != nil {
+		return nil, err
+	}
+
+	return homeSensors(ctx, h.roomRepo, h.sensorRepo, homeID)
+}
+
+// AttachHomeToUser дает пользователю доступ к дому с ролью role, для уже привязанного пользователя меняет роль.
+// Давать доступ к дому может только его владелец. Владелец не понижается до участника,
+// чтобы дом не остался без владельца.
+func (h *Home) AttachHomeToUser(ctx context.Context, userID, homeID int64, role domain.HomeRole) error {
+	if !role.IsValid() {
+		return ErrInvalidHomeRole
+	}
+
+	user, err := h.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	if _, err := h.GetHomeByID(ctx, homeID); err != nil {
+		return err
+	}
+	if err := h.access.CheckHomeRole(ctx, homeID, domain.HomeRoleOwner); err != nil {
+		return err
+	}
+
+	homeOwners, err := h.homeOwnerRepo.GetHomesByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, homeOwner := range homeOwners {
+		if homeOwner.HomeID == homeID && homeRole(homeOwner) == domain.HomeRoleOwner {
+			role = domain.HomeRoleOwner
+		}
+	}
+
+	err = h.homeOwnerRepo.SaveHomeOwner(ctx, domain.HomeOwner{
+		UserID: userID,
+		HomeID: homeID,
+		Role:   role,
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.audit.record(ctx, domain.AuditActionAttach, domain.AuditEntityHome, homeID, nil, auditLink("user", userID, role))
+}
+
+func (h *Home) GetUserHomes(ctx context.Context, userID int64) ([]domain.Home, error) {
+	if caller, ok := h.access.restricted(ctx); ok && caller.ID != userID {
+		return nil, ErrUserNotFound
+	}
+
+	user, err := h.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	homeOwners, err := h.homeOwnerRepo.GetHomesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	homes := make([]domain.Home, 0, len(homeOwners))
+	for _, homeOwner := range homeOwners {
+		home, err := h.homeRepo.GetHomeByID(ctx, homeOwner.HomeID)
+		if err != nil {
+			return nil, err
+		}
+		if home != nil {
+			homes = append(homes, *home)
+		}
+	}
+	return homes, nil
+}
+
+// roomSensors возвращает все датчики, размещенные в комнате.
+func roomSensors(ctx context.Context, rr RoomRepository, sr SensorRepository, roomID int64) ([]domain.Sensor, error) {
+	sensorRooms, err := rr.GetSensorsByRoomID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	sensors := make([]domain.Sensor, 0, len(sensorRooms))
+	for _, sensorRoom := range sensorRooms {
+		sensor, err := sr.GetSensorByID(ctx, sensorRoom.SensorID)
+		if err != nil {
+			return nil, err
+		}
+		if sensor != nil {
+			sensors = append(sensors, *sensor)
+		}
+	}
+	return sensors, nil
+}
+
+// homeSensors возвращает все датчики, размещенные в комнатах дома.
+func homeSensors(ctx context.Context, rr RoomRepository, sr SensorRepository, homeID int64) ([]domain.Sensor, error) {
+	rooms, err := rr.GetRoomsByHomeID(ctx, homeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sensors []domain.Sensor
+	for _, room := range rooms {
+		rs, err := roomSensors(ctx, rr, sr, room.ID)
+		if err != nil {
+			return nil, err
+		}
+		sensors = append(sensors, rs...)
+	}
+	return sensors, nil
+}
+
+// validateHome нормализует название и часовой пояс дома и проверяет координаты
+func validateHome(home *domain.Home) error {
+	home.Name = strings.TrimSpace(home.Name)
+	if home.Name == "" {
+		return ErrInvalidHomeName
+	}
+
+	home.TimeZone = strings.TrimSpace(home.TimeZone)
+	if _, err := time.LoadLocation(home.TimeZone); err != nil {
+		return ErrInvalidHomeLocation
+	}
+	if home.Coordinates != nil && !home.Coordinates.IsValid() {
+		return ErrInvalidHomeLocation
+	}
+
+	return nil
+}
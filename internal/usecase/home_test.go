@@ -0,0 +1,309 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_home_CreateHome(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, empty name", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		h := NewHome(nil, nil, nil, nil, nil)
+
+		_, err := h.CreateHome(ctx, &domain.Home{Name: "   "})
+		assert.ErrorIs(t, err, ErrInvalidHomeName)
+	})
+
+	t.Run("fail, invalid time zone or coordinates", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		h := NewHome(nil, nil, nil, nil, nil)
+
+		for _, home := range []*domain.Home{
+			{Name: "Дача", TimeZone: "Mars/Olympus"},
+			{Name: "Дача", Coordinates: &domain.Coordinates{Latitude: -91}},
+			{Name: "Дача", Coordinates: &domain.Coordinates{Longitude: 180.5}},
+		} {
+			_, err := h.CreateHome(ctx, home)
+			assert.ErrorIs(t, err, ErrInvalidHomeLocation)
+		}
+	})
+
+	t.Run("ok, name is trimmed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().SaveHome(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, home *domain.Home) {
+			assert.Equal(t, "Дача", home.Name)
+			home.ID = 1
+		})
+
+		h := NewHome(hr, nil, nil, nil, nil)
+
+		home, err := h.CreateHome(ctx, &domain.Home{Name: " Дача "})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), home.ID)
+	})
+}
+
+func Test_home_DeleteHome(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, home not found", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(1)).Times(1).Return(nil, ErrHomeNotFound)
+
+		h := NewHome(hr, nil, nil, nil, nil)
+
+		err := h.DeleteHome(ctx, 1)
+		assert.ErrorIs(t, err, ErrHomeNotFound)
+	})
+
+	t.Run("ok, rooms and owners are removed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(1)).Times(1).Return(&domain.Home{ID: 1}, nil)
+		hr.EXPECT().DeleteHome(ctx, int64(1)).Times(1).Return(nil)
+
+		rr := NewMockRoomRepository(ctrl)
+		rr.EXPECT().GetRoomsByHomeID(ctx, int64(1)).Times(1).Return([]domain.Room{{ID: 2, HomeID: 1}, {ID: 3, HomeID: 1}}, nil)
+		rr.EXPECT().DeleteRoom(ctx, int64(2)).Times(1).Return(nil)
+		rr.EXPECT().DeleteRoom(ctx, int64(3)).Times(1).Return(nil)
+
+		hor := NewMockHomeOwnerRepository(ctrl)
+		hor.EXPECT().DeleteHomeOwnersByHomeID(ctx, int64(1)).Times(1).Return(nil)
+
+		h := NewHome(hr, rr, hor, nil, nil)
+
+		err := h.DeleteHome(ctx, 1)
+		assert.NoError(t, err)
+	})
+
+	t.Run("fail, deletes run in one transaction", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		type txKey struct{}
+		txCtx := context.WithValue(ctx, txKey{}, "tx")
+		expectedError := errors.New("database error")
+
+		tr := NewMockTransactor(ctrl)
+		tr.EXPECT().WithinTransaction(ctx, gomock.Any()).Times(1).
+			DoAndReturn(func(_ context.Context, fn func(ctx context.Context) error) error {
+				return fn(txCtx)
+			})
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(1)).Times(1).Return(&domain.Home{ID: 1}, nil)
+
+		rr := NewMockRoomRepository(ctrl)
+		rr.EXPECT().GetRoomsByHomeID(txCtx, int64(1)).Times(1).Return([]domain.Room{{ID: 2, HomeID: 1}}, nil)
+		rr.EXPECT().DeleteRoom(txCtx, int64(2)).Times(1).Return(nil)
+
+		hor := NewMockHomeOwnerRepository(ctrl)
+		hor.EXPECT().DeleteHomeOwnersByHomeID(txCtx, int64(1)).Times(1).Return(expectedError)
+
+		h := NewHome(hr, rr, hor, nil, nil, WithHomeTransactor(tr))
+
+		err := h.DeleteHome(ctx, 1)
+		assert.ErrorIs(t, err, expectedError)
+	})
+}
+
+func Test_home_CreateRoom(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, home not found", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(1)).Times(1).Return(nil, ErrHomeNotFound)
+
+		h := NewHome(hr, nil, nil, nil, nil)
+
+		_, err := h.CreateRoom(ctx, &domain.Room{HomeID: 1, Name: "Кухня"})
+		assert.ErrorIs(t, err, ErrHomeNotFound)
+	})
+
+	t.Run("fail, empty name", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(1)).Times(1).Return(&domain.Home{ID: 1}, nil)
+
+		h := NewHome(hr, nil, nil, nil, nil)
+
+		_, err := h.CreateRoom(ctx, &domain.Room{HomeID: 1})
+		assert.ErrorIs(t, err, ErrInvalidRoomName)
+	})
+}
+
+func Test_home_AssignSensorToRoom(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, sensor not found", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		rr := NewMockRoomRepository(ctrl)
+		rr.EXPECT().GetRoomByID(ctx, int64(1)).Times(1).Return(&domain.Room{ID: 1, HomeID: 1}, nil)
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(2)).Times(1).Return(nil, ErrSensorNotFound)
+
+		h := NewHome(nil, rr, nil, sr, nil)
+
+		err := h.AssignSensorToRoom(ctx, 1, 2)
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		rr := NewMockRoomRepository(ctrl)
+		rr.EXPECT().GetRoomByID(ctx, int64(1)).Times(1).Return(&domain.Room{ID: 1, HomeID: 1}, nil)
+		rr.EXPECT().SaveSensorRoom(ctx, domain.SensorRoom{SensorID: 2, RoomID: 1}).Times(1).Return(nil)
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(2)).Times(1).Return(&domain.Sensor{ID: 2}, nil)
+
+		h := NewHome(nil, rr, nil, sr, nil)
+
+		err := h.AssignSensorToRoom(ctx, 1, 2)
+		assert.NoError(t, err)
+	})
+}
+
+func Test_home_GetHomeSensors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, room repo error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(1)).Times(1).Return(&domain.Home{ID: 1}, nil)
+
+		rr := NewMockRoomRepository(ctrl)
+		expectedError := errors.New("some error")
+		rr.EXPECT().GetRoomsByHomeID(ctx, int64(1)).Times(1).Return(nil, expectedError)
+
+		h := NewHome(hr, rr, nil, nil, nil)
+
+		_, err := h.GetHomeSensors(ctx, 1)
+		assert.ErrorIs(t, err, expectedError)
+	})
+
+	t.Run("ok, sensors from all rooms", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(1)).Times(1).Return(&domain.Home{ID: 1}, nil)
+
+		rr := NewMockRoomRepository(ctrl)
+		rr.EXPECT().GetRoomsByHomeID(ctx, int64(1)).Times(1).Return([]domain.Room{{ID: 1, HomeID: 1}, {ID: 2, HomeID: 1}}, nil)
+		rr.EXPECT().GetSensorsByRoomID(ctx, int64(1)).Times(1).Return([]domain.SensorRoom{{SensorID: 10, RoomID: 1}}, nil)
+		rr.EXPECT().GetSensorsByRoomID(ctx, int64(2)).Times(1).Return([]domain.SensorRoom{{SensorID: 20, RoomID: 2}}, nil)
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(10)).Times(1).Return(&domain.Sensor{ID: 10}, nil)
+		sr.EXPECT().GetSensorByID(ctx, int64(20)).Times(1).Return(&domain.Sensor{ID: 20}, nil)
+
+		h := NewHome(hr, rr, nil, sr, nil)
+
+		sensors, err := h.GetHomeSensors(ctx, 1)
+		assert.NoError(t, err)
+		assert.Len(t, sensors, 2)
+	})
+}
+
+func Test_user_GetUserSensorsWithHomes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ur := NewMockUserRepository(ctrl)
+	ur.EXPECT().GetUserByID(ctx, int64(1)).Times(1).Return(&domain.User{ID: 1}, nil)
+
+	sor := NewMockSensorOwnerRepository(ctrl)
+	sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Times(1).Return([]domain.SensorOwner{{UserID: 1, SensorID: 10}}, nil)
+
+	hor := NewMockHomeOwnerRepository(ctrl)
+	hor.EXPECT().GetHomesByUserID(ctx, int64(1)).Times(1).Return([]domain.HomeOwner{{UserID: 1, HomeID: 5}}, nil)
+
+	rr := NewMockRoomRepository(ctrl)
+	rr.EXPECT().GetRoomsByHomeID(ctx, int64(5)).Times(1).Return([]domain.Room{{ID: 7, HomeID: 5}}, nil)
+	rr.EXPECT().GetSensorsByRoomID(ctx, int64(7)).Times(1).Return([]domain.SensorRoom{{SensorID: 10, RoomID: 7}, {SensorID: 11, RoomID: 7}}, nil)
+
+	sr := NewMockSensorRepository(ctrl)
+	sr.EXPECT().GetSensorByID(ctx, int64(10)).Times(2).Return(&domain.Sensor{ID: 10}, nil)
+	sr.EXPECT().GetSensorByID(ctx, int64(11)).Times(1).Return(&domain.Sensor{ID: 11}, nil)
+
+	u := NewUser(ur, sor, sr, WithHomeAccess(hor, rr))
+
+	sensors, err := u.GetUserSensors(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, sensors, 2)
+}
+
+func Test_home_AttachHomeToUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, invalid role", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		h := NewHome(nil, nil, nil, nil, nil)
+
+		err := h.AttachHomeToUser(ctx, 1, 1, domain.HomeRole("admin"))
+		assert.ErrorIs(t, err, ErrInvalidHomeRole)
+	})
+
+	t.Run("ok, owner keeps owner role", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(2)).Times(1).Return(&domain.User{ID: 2}, nil)
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(1)).Times(1).Return(&domain.Home{ID: 1}, nil)
+
+		hor := NewMockHomeOwnerRepository(ctrl)
+		hor.EXPECT().GetHomesByUserID(ctx, int64(2)).Times(1).
+			Return([]domain.HomeOwner{{UserID: 2, HomeID: 1, Role: domain.HomeRoleOwner}}, nil)
+		hor.EXPECT().SaveHomeOwner(ctx, domain.HomeOwner{UserID: 2, HomeID: 1, Role: domain.HomeRoleOwner}).Times(1).Return(nil)
+
+		h := NewHome(hr, nil, hor, nil, ur)
+
+		err := h.AttachHomeToUser(ctx, 2, 1, domain.HomeRoleMember)
+		assert.NoError(t, err)
+	})
+}
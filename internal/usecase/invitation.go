@@ -0,0 +1,263 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"homework/internal/domain"
+	"strings"
+	"time"
+)
+
+const (
+	// invitationTokenPrefix - префикс токенов приглашений, отличает их от токенов API
+	invitationTokenPrefix = "shi_"
+	// invitationTokenBytes - количество случайных байт в токене приглашения
+	invitationTokenBytes = 32
+	// DefaultInvitationTTL - срок действия приглашения, если он не задан
+	DefaultInvitationTTL = 7 * 24 * time.Hour
+	// MaxInvitationTTL - максимальный срок действия приглашения
+	MaxInvitationTTL = 30 * 24 * time.Hour
+	// MaxInvitationUses - максимальное число принятий одного приглашения
+	MaxInvitationUses = 100
+)
+
+// Invitation - приглашения к датчику по ссылке. Создавать, просматривать и отзывать приглашения
+// может только владелец датчика, принять приглашение может любой аутентифицированный пользователь.
+type Invitation struct {
+	invitationRepo  SensorInvitationRepository
+	sensorOwnerRepo SensorOwnerRepository
+	sensorRepo      SensorRepository
+	access          *AccessPolicy
+	transactor      Transactor
+	audit           *Audit
+	now             func() time.Time
+}
+
+func NewInvitation(ir SensorInvitationRepository, sor SensorOwnerRepository, sr SensorRepository, options ...func(*Invitation)) *Invitation {
+	i := &Invitation{
+		invitationRepo:  ir,
+		sensorOwnerRepo: sor,
+		sensorRepo:      sr,
+		transactor:      noTransaction{},
+		now:             time.Now,
+	}
+
+	for _, o := range options {
+		o(i)
+	}
+
+	return i
+}
+
+// WithInvitationAccessPolicy разрешает работу с приглашениями только владельцам датчика из контекста
+func WithInvitationAccessPolicy(p *AccessPolicy) func(*Invitation) {
+	return func(i *Invitation) {
+		i.access = p
+	}
+}
+
+// WithInvitationTransactor задает транзакцию для принятия приглашения вместе с привязкой датчика
+func WithInvitationTransactor(t Transactor) func(*Invitation) {
+	return func(i *Invitation) {
+		i.transactor = t
+	}
+}
+
+// WithInvitationAudit записывает создание, отзыв и принятие приглашений в журнал аудита
+func WithInvitationAudit(a *Audit) func(*Invitation) {
+	return func(i *Invitation) {
+		i.audit = a
+	}
+}
+
+// WithInvitationClock подменяет источник текущего времени, используется в тестах
+func WithInvitationClock(now func() time.Time) func(*Invitation) {
+	return func(i *Invitation) {
+		i.now = now
+	}
+}
+
+// CreateInvitation создает приглашение к датчику с ролью role. ttl, равный 0, означает DefaultInvitationTTL,
+// maxUses, равный 0, - одно принятие. Открытое значение токена возвращается только здесь и больше нигде не хранится.
+func (i *Invitation) CreateInvitation(ctx context.Context, sensorID int64, role domain.SensorRole, ttl time.Duration, maxUses int) (*domain.SensorInvitation, string, error) {
+	if !role.IsValid() {
+		return nil, "", ErrInvalidSensorRole
+	}
+	if ttl == 0 {
+		ttl = DefaultInvitationTTL
+	}
+	if maxUses == 0 {
+		maxUses = 1
+	}
+	if ttl < 0 || ttl > MaxInvitationTTL || maxUses < 0 || maxUses > MaxInvitationUses {
+		return nil, "", ErrInvalidInvitation
+	}
+
+	if err := i.checkOwner(ctx, sensorID); err != nil {
+		return nil, "", err
+	}
+
+	secret := make([]byte, invitationTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	plain := invitationTokenPrefix + hex.EncodeToString(secret)
+
+	now := i.now()
+	invitation := &domain.SensorInvitation{
+		SensorID:  sensorID,
+		Role:      role,
+		Hash:      hashAPIToken(plain),
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+		MaxUses:   maxUses,
+	}
+	if caller, ok := UserFromContext(ctx); ok {
+		invitation.CreatedBy = caller.ID
+	}
+
+	if err := i.invitationRepo.SaveSensorInvitation(ctx, invitation); err != nil {
+		return nil, "", err
+	}
+
+	err := i.audit.record(ctx, domain.AuditActionCreate, domain.AuditEntitySensorInvitation, invitation.ID, nil, invitation)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return invitation, plain, nil
+}
+
+// GetSensorInvitations возвращает приглашения к датчику, которые еще можно принять
+func (i *Invitation) GetSensorInvitations(ctx context.Context, sensorID int64) ([]domain.SensorInvitation, error) {
+	if err := i.checkOwner(ctx, sensorID); err != nil {
+		return nil, err
+	}
+
+	invitations, err := i.invitationRepo.GetSensorInvitationsBySensorID(ctx, sensorID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := i.now()
+	result := make([]domain.SensorInvitation, 0, len(invitations))
+	for _, invitation := range invitations {
+		if invitation.IsPending(now) {
+			result = append(result, invitation)
+		}
+	}
+	return result, nil
+}
+
+// RevokeInvitation отзывает приглашение к датчику. Чужое или уже отозванное приглашение считается ненайденным.
+// Доступ, уже полученный по приглашению, сохраняется.
+func (i *Invitation) RevokeInvitation(ctx context.Context, sensorID, invitationID int64) error {
+	if err := i.checkOwner(ctx, sensorID); err != nil {
+		return err
+	}
+
+	invitations, err := i.invitationRepo.GetSensorInvitationsBySensorID(ctx, sensorID)
+	if err != nil {
+		return err
+	}
+
+	for _, invitation := range invitations {
+		if invitation.ID == invitationID && invitation.RevokedAt == nil {
+			revoked := invitation
+			now := i.now()
+			revoked.RevokedAt = &now
+			if err := i.invitationRepo.RevokeSensorInvitation(ctx, invitationID, now); err != nil {
+				return err
+			}
+			return i.audit.record(ctx, domain.AuditActionRevoke, domain.AuditEntitySensorInvitation, invitationID, &invitation, &revoked)
+		}
+	}
+
+	return ErrInvitationNotFound
+}
+
+// AcceptInvitation привязывает датчик к пользователю из контекста с ролью из приглашения.
+// Если у пользователя уже есть привязка с не меньшей ролью, она не меняется и принятие не засчитывается.
+func (i *Invitation) AcceptInvitation(ctx context.Context, plain string) (*domain.SensorOwner, error) {
+	caller, ok := UserFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	if !strings.HasPrefix(plain, invitationTokenPrefix) {
+		return nil, ErrInvitationNotFound
+	}
+
+	invitation, err := i.invitationRepo.GetSensorInvitationByHash(ctx, hashAPIToken(plain))
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil {
+		return nil, ErrInvitationNotFound
+	}
+	if !invitation.IsPending(i.now()) {
+		return nil, ErrInvitationExpired
+	}
+
+	sensor, err := i.sensorRepo.GetSensorByID(ctx, invitation.SensorID)
+	if errors.Is(err, ErrSensorNotFound) || err == nil && sensor == nil {
+		return nil, ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result domain.SensorOwner
+	err = i.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		sensorOwners, err := i.sensorOwnerRepo.GetSensorsByUserID(ctx, caller.ID)
+		if err != nil {
+			return err
+		}
+		for _, sensorOwner := range sensorOwners {
+			if sensorOwner.SensorID == invitation.SensorID && sensorRole(sensorOwner).Includes(invitation.Role) {
+				result = sensorOwner
+				return nil
+			}
+		}
+
+		if err := i.invitationRepo.UseSensorInvitation(ctx, invitation.ID, i.now()); err != nil {
+			return err
+		}
+
+		result = domain.SensorOwner{
+			UserID:   caller.ID,
+			SensorID: invitation.SensorID,
+			Role:     invitation.Role,
+		}
+		if err := i.sensorOwnerRepo.SaveSensorOwner(ctx, result); err != nil {
+			return err
+		}
+
+		used := *invitation
+		used.Uses++
+		err = i.audit.record(ctx, domain.AuditActionAccept, domain.AuditEntitySensorInvitation, invitation.ID, invitation, &used)
+		if err != nil {
+			return err
+		}
+		return i.audit.record(ctx, domain.AuditActionAttach, domain.AuditEntitySensor, invitation.SensorID,
+			nil, auditLink("user", caller.ID, invitation.Role))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (i *Invitation) checkOwner(ctx context.Context, sensorID int64) error {
+	sensor, err := i.sensorRepo.GetSensorByID(ctx, sensorID)
+	if err != nil {
+		return err
+	}
+	if sensor == nil {
+		return ErrSensorNotFound
+	}
+
+	return i.access.CheckSensorRole(ctx, sensorID, domain.SensorRoleOwner)
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_invitation_CreateInvitation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("fail, invalid params", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		i := NewInvitation(nil, nil, nil)
+
+		_, _, err := i.CreateInvitation(ctx, 1, "admin", 0, 0)
+		assert.ErrorIs(t, err, ErrInvalidSensorRole)
+
+		_, _, err = i.CreateInvitation(ctx, 1, domain.SensorRoleViewer, -time.Second, 0)
+		assert.ErrorIs(t, err, ErrInvalidInvitation)
+
+		_, _, err = i.CreateInvitation(ctx, 1, domain.SensorRoleViewer, MaxInvitationTTL+time.Second, 0)
+		assert.ErrorIs(t, err, ErrInvalidInvitation)
+
+		_, _, err = i.CreateInvitation(ctx, 1, domain.SensorRoleViewer, 0, -1)
+		assert.ErrorIs(t, err, ErrInvalidInvitation)
+	})
+
+	t.Run("fail, caller is not an owner", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 2})
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Times(1).
+			Return([]domain.SensorOwner{{UserID: 2, SensorID: 1, Role: domain.SensorRoleOperator}}, nil)
+
+		i := NewInvitation(nil, sor, sr, WithInvitationAccessPolicy(NewAccessPolicy(sor, nil, nil)))
+
+		_, _, err := i.CreateInvitation(ctx, 1, domain.SensorRoleViewer, 0, 0)
+		assert.ErrorIs(t, err, ErrInsufficientRole)
+	})
+
+	t.Run("ok, defaults applied and only hash is stored", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 1, IsAdmin: true})
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		var saved *domain.SensorInvitation
+		ir := NewMockSensorInvitationRepository(ctrl)
+		ir.EXPECT().SaveSensorInvitation(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, invitation *domain.SensorInvitation) {
+			saved = invitation
+		})
+
+		i := NewInvitation(ir, nil, sr, WithInvitationClock(func() time.Time { return now }))
+
+		invitation, plain, err := i.CreateInvitation(ctx, 1, domain.SensorRoleOperator, 0, 0)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(plain, invitationTokenPrefix))
+		assert.Equal(t, hashAPIToken(plain), saved.Hash)
+		assert.Equal(t, int64(1), invitation.CreatedBy)
+		assert.Equal(t, now.Add(DefaultInvitationTTL), invitation.ExpiresAt)
+		assert.Equal(t, 1, invitation.MaxUses)
+	})
+}
+
+func Test_invitation_AcceptInvitation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	plain := invitationTokenPrefix + "secret"
+	pending := func() *domain.SensorInvitation {
+		return &domain.SensorInvitation{
+			ID:        5,
+			SensorID:  1,
+			Role:      domain.SensorRoleOperator,
+			Hash:      hashAPIToken(plain),
+			ExpiresAt: now.Add(time.Hour),
+			MaxUses:   1,
+		}
+	}
+
+	t.Run("fail, unauthenticated", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		i := NewInvitation(nil, nil, nil)
+
+		_, err := i.AcceptInvitation(ctx, plain)
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	})
+
+	t.Run("fail, expired or used up", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 2})
+
+		expired := pending()
+		expired.ExpiresAt = now
+		usedUp := pending()
+		usedUp.Uses = 1
+
+		ir := NewMockSensorInvitationRepository(ctrl)
+		ir.EXPECT().GetSensorInvitationByHash(ctx, hashAPIToken(plain)).Times(1).Return(expired, nil)
+		ir.EXPECT().GetSensorInvitationByHash(ctx, hashAPIToken(plain)).Times(1).Return(usedUp, nil)
+
+		i := NewInvitation(ir, nil, nil, WithInvitationClock(func() time.Time { return now }))
+
+		_, err := i.AcceptInvitation(ctx, plain)
+		assert.ErrorIs(t, err, ErrInvitationExpired)
+
+		_, err = i.AcceptInvitation(ctx, plain)
+		assert.ErrorIs(t, err, ErrInvitationExpired)
+	})
+
+	t.Run("ok, higher role kept and use not counted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 2})
+
+		ir := NewMockSensorInvitationRepository(ctrl)
+		ir.EXPECT().GetSensorInvitationByHash(ctx, hashAPIToken(plain)).Times(1).Return(pending(), nil)
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Times(1).
+			Return([]domain.SensorOwner{{UserID: 2, SensorID: 1, Role: domain.SensorRoleOwner}}, nil)
+
+		i := NewInvitation(ir, sor, sr, WithInvitationClock(func() time.Time { return now }))
+
+		sensorOwner, err := i.AcceptInvitation(ctx, plain)
+		require.NoError(t, err)
+		assert.Equal(t, domain.SensorRoleOwner, sensorOwner.Role)
+	})
+
+	t.Run("ok, link created", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 2})
+
+		ir := NewMockSensorInvitationRepository(ctrl)
+		ir.EXPECT().GetSensorInvitationByHash(ctx, hashAPIToken(plain)).Times(1).Return(pending(), nil)
+		ir.EXPECT().UseSensorInvitation(ctx, int64(5), now).Times(1).Return(nil)
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		want := domain.SensorOwner{UserID: 2, SensorID: 1, Role: domain.SensorRoleOperator}
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Times(1).
+			Return([]domain.SensorOwner{{UserID: 2, SensorID: 1, Role: domain.SensorRoleViewer}}, nil)
+		sor.EXPECT().SaveSensorOwner(ctx, want).Times(1).Return(nil)
+
+		i := NewInvitation(ir, sor, sr, WithInvitationClock(func() time.Time { return now }))
+
+		sensorOwner, err := i.AcceptInvitation(ctx, plain)
+		require.NoError(t, err)
+		assert.Equal(t, want, *sensorOwner)
+	})
+}
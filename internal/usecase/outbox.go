@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"homework/internal/domain"
+	"time"
+)
+
+const (
+	// outboxDispatchBatch - сколько записей выбирается за один вызов Dispatch
+	outboxDispatchBatch = 100
+	// outboxDispatchLease - на сколько откладывается выбранная запись, должно быть больше времени публикации
+	outboxDispatchLease = time.Minute
+	// outboxRetryBaseDelay - задержка перед второй попыткой доставки, дальше она удваивается
+	outboxRetryBaseDelay = time.Second
+	// outboxRetryMaxDelay - максимальная задержка между попытками доставки
+	outboxRetryMaxDelay = 5 * time.Minute
+	// maxOutboxErrorLength - сколько символов причины неудачи сохраняется в записи
+	maxOutboxErrorLength = 200
+)
+
+// Outbox - надежная доставка изменений внешним получателям. Запись сохраняется в одной транзакции
+// с изменением, поэтому не теряется, если сервис остановится сразу после него, а доставляется
+// периодически (Dispatch) всем публикаторам хотя бы один раз. Записи одного датчика доставляются по порядку:
+// пока запись не доставлена, следующие записи датчика ждут. Доставленные записи удаляются.
+type Outbox struct {
+	outboxRepo OutboxRepository
+	publishers []OutboxPublisher
+	now        func() time.Time
+}
+
+func NewOutbox(or OutboxRepository, options ...func(*Outbox)) *Outbox {
+	o := &Outbox{
+		outboxRepo: or,
+		now:        time.Now,
+	}
+
+	for _, opt := range options {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithOutboxPublisher добавляет получателя записей. Запись считается доставленной, когда ее принял каждый получатель.
+func WithOutboxPublisher(p OutboxPublisher) func(*Outbox) {
+	return func(o *Outbox) {
+		o.publishers = append(o.publishers, p)
+	}
+}
+
+// WithOutboxClock подменяет источник текущего времени, используется в тестах
+func WithOutboxClock(now func() time.Time) func(*Outbox) {
+	return func(o *Outbox) {
+		o.now = now
+	}
+}
+
+// addSensorEvent сохраняет запись о событии датчика. Вызывается в транзакции сохранения события.
+func (o *Outbox) addSensorEvent(ctx context.Context, event *domain.Event) error {
+	if o == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := o.now()
+	return o.outboxRepo.AddOutboxEntry(ctx, &domain.OutboxEntry{
+		SensorID:      event.SensorID,
+		Kind:          domain.OutboxSensorEvent,
+		Payload:       payload,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	})
+}
+
+// Dispatch доставляет записи, время попытки которых наступило. Вызывается периодически.
+// Если запись не принял хотя бы один получатель, она повторяется позже, в том числе для принявших.
+func (o *Outbox) Dispatch(ctx context.Context) error {
+	now := o.now()
+	entries, err := o.outboxRepo.ClaimOutboxEntries(ctx, now, now.Add(outboxDispatchLease), outboxDispatchBatch)
+	if err != nil {
+		return err
+	}
+
+	// время следующей попытки датчиков, запись которых не доставлена
+	retryAt := make(map[int64]time.Time)
+	for i := range entries {
+		entry := &entries[i]
+		if next, ok := retryAt[entry.SensorID]; ok {
+			// следующие записи датчика повторяются вместе с недоставленной, чтобы не ждать окончания выбора
+			entry.NextAttemptAt = next
+			if err := o.outboxRepo.RetryOutboxEntry(ctx, entry); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := o.publish(ctx, *entry); err != nil {
+			entry.Attempts++
+			entry.LastError = truncateReason(err.Error(), maxOutboxErrorLength)
+			entry.NextAttemptAt = o.now().Add(outboxRetryDelay(entry.Attempts))
+			retryAt[entry.SensorID] = entry.NextAttemptAt
+			if err := o.outboxRepo.RetryOutboxEntry(ctx, entry); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := o.outboxRepo.DeleteOutboxEntry(ctx, entry.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *Outbox) publish(ctx context.Context, entry domain.OutboxEntry) error {
+	for _, p := range o.publishers {
+		if err := p.Publish(ctx, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outboxRetryDelay возвращает задержку перед следующей попыткой после attempts неудачных
+func outboxRetryDelay(attempts int) time.Duration {
+	delay := outboxRetryBaseDelay
+	for i := 1; i < attempts && delay < outboxRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, outboxRetryMaxDelay)
+}
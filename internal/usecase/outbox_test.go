@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// publisherFunc - OutboxPublisher из функции
+type publisherFunc func(ctx context.Context, entry domain.OutboxEntry) error
+
+func (f publisherFunc) Publish(ctx context.Context, entry domain.OutboxEntry) error {
+	return f(ctx, entry)
+}
+
+func Test_outbox_Dispatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("ok, delivered entries deleted", func(t *testing.T) {
+		ctx := context.Background()
+
+		or := NewMockOutboxRepository(ctrl)
+		or.EXPECT().ClaimOutboxEntries(ctx, now, now.Add(outboxDispatchLease), outboxDispatchBatch).Times(1).
+			Return([]domain.OutboxEntry{{ID: 1, SensorID: 1}, {ID: 2, SensorID: 1}}, nil)
+		gomock.InOrder(
+			or.EXPECT().DeleteOutboxEntry(ctx, int64(1)).Times(1).Return(nil),
+			or.EXPECT().DeleteOutboxEntry(ctx, int64(2)).Times(1).Return(nil),
+		)
+
+		var published [][]int64
+		record := func(name int64) publisherFunc {
+			return func(_ context.Context, entry domain.OutboxEntry) error {
+				published = append(published, []int64{name, entry.ID})
+				return nil
+			}
+		}
+
+		o := NewOutbox(or,
+			WithOutboxPublisher(record(1)),
+			WithOutboxPublisher(record(2)),
+			WithOutboxClock(func() time.Time { return now }),
+		)
+		assert.NoError(t, o.Dispatch(ctx))
+		assert.Equal(t, [][]int64{{1, 1}, {2, 1}, {1, 2}, {2, 2}}, published)
+	})
+
+	t.Run("ok, failed entry holds back later entries of its sensor", func(t *testing.T) {
+		ctx := context.Background()
+
+		or := NewMockOutboxRepository(ctrl)
+		or.EXPECT().ClaimOutboxEntries(ctx, now, gomock.Any(), outboxDispatchBatch).Times(1).
+			Return([]domain.OutboxEntry{{ID: 1, SensorID: 1, Attempts: 2}, {ID: 2, SensorID: 2}, {ID: 3, SensorID: 1}}, nil)
+		gomock.InOrder(
+			or.EXPECT().RetryOutboxEntry(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, entry *domain.OutboxEntry) error {
+				assert.Equal(t, int64(1), entry.ID)
+				assert.Equal(t, 3, entry.Attempts)
+				assert.Equal(t, "broker unavailable", entry.LastError)
+				assert.Equal(t, now.Add(4*outboxRetryBaseDelay), entry.NextAttemptAt)
+				return nil
+			}),
+			or.EXPECT().DeleteOutboxEntry(ctx, int64(2)).Times(1).Return(nil),
+			or.EXPECT().RetryOutboxEntry(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, entry *domain.OutboxEntry) error {
+				assert.Equal(t, int64(3), entry.ID)
+				assert.Zero(t, entry.Attempts)
+				assert.Equal(t, now.Add(4*outboxRetryBaseDelay), entry.NextAttemptAt)
+				return nil
+			}),
+		)
+
+		o := NewOutbox(or,
+			WithOutboxPublisher(publisherFunc(func(_ context.Context, entry domain.OutboxEntry) error {
+				if entry.SensorID == 1 {
+					return errors.New("broker unavailable")
+				}
+				return nil
+			})),
+			WithOutboxClock(func() time.Time { return now }),
+		)
+		assert.NoError(t, o.Dispatch(ctx))
+	})
+
+	t.Run("ok, retry delay is capped", func(t *testing.T) {
+		assert.Equal(t, outboxRetryBaseDelay, outboxRetryDelay(1))
+		assert.Equal(t, 2*outboxRetryBaseDelay, outboxRetryDelay(2))
+		assert.Equal(t, outboxRetryMaxDelay, outboxRetryDelay(100))
+	})
+}
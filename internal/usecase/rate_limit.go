@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"strconv"
+	"time"
+)
+
+// RateLimitError - ErrRateLimited с временем, через которое запрос можно повторить
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return ErrRateLimited.Error()
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// RateLimiter ограничивает частоту приема событий от датчика и от клиента и запросов на чтение от пользователя.
+// Ограничения выбираются по типу датчика и по id пользователя, без подходящего ограничения запросы не ограничиваются.
+type RateLimiter struct {
+	store       RateLimitStore
+	sensorRepo  SensorRepository
+	client      domain.RateLimit
+	sensor      domain.RateLimit
+	sensorTypes map[domain.SensorType]domain.RateLimit
+	user        domain.RateLimit
+	users       map[int64]domain.RateLimit
+	now         func() time.Time
+}
+
+func NewRateLimiter(store RateLimitStore, sr SensorRepository, options ...func(*RateLimiter)) *RateLimiter {
+	l := &RateLimiter{
+		store:       store,
+		sensorRepo:  sr,
+		sensorTypes: make(map[domain.SensorType]domain.RateLimit),
+		users:       make(map[int64]domain.RateLimit),
+		now:         time.Now,
+	}
+
+	for _, o := range options {
+		o(l)
+	}
+
+	return l
+}
+
+// WithClientRateLimit задает ограничение событий с одного адреса независимо от датчика
+func WithClientRateLimit(limit domain.RateLimit) func(*RateLimiter) {
+	return func(l *RateLimiter) {
+		l.client = limit
+	}
+}
+
+// WithSensorRateLimit задает ограничение для датчиков, тип которых не указан в WithSensorTypeRateLimit,
+// и для неизвестных серийных номеров
+func WithSensorRateLimit(limit domain.RateLimit) func(*RateLimiter) {
+	return func(l *RateLimiter) {
+		l.sensor = limit
+	}
+}
+
+// WithSensorTypeRateLimit задает ограничение для датчиков типа sensorType
+func WithSensorTypeRateLimit(sensorType domain.SensorType, limit domain.RateLimit) func(*RateLimiter) {
+	return func(l *RateLimiter) {
+		l.sensorTypes[sensorType] = limit
+	}
+}
+
+// WithUserRateLimit задает ограничение для пользователей, не указанных в WithUserIDRateLimit
+func WithUserRateLimit(limit domain.RateLimit) func(*RateLimiter) {
+	return func(l *RateLimiter) {
+		l.user = limit
+	}
+}
+
+// WithUserIDRateLimit задает ограничение для пользователя userID
+func WithUserIDRateLimit(userID int64, limit domain.RateLimit) func(*RateLimiter) {
+	return func(l *RateLimiter) {
+		l.users[userID] = limit
+	}
+}
+
+// WithRateLimiterClock подменяет источник текущего времени, используется в тестах
+func WithRateLimiterClock(now func() time.Time) func(*RateLimiter) {
+	return func(l *RateLimiter) {
+		l.now = now
+	}
+}
+
+// AllowSensorEvent забирает токен на прием события от датчика с серийным номером sn.
+// При превышении ограничения возвращает *RateLimitError.
+func (l *RateLimiter) AllowSensorEvent(ctx context.Context, sn string) error {
+	limit := l.sensor
+	if len(l.sensorTypes) > 0 {
+		sensor, err := l.sensorRepo.GetSensorBySerialNumber(ctx, sn)
+		if err != nil && !errors.Is(err, ErrSensorNotFound) {
+			return err
+		}
+		if sensor != nil {
+			if typeLimit, ok := l.sensorTypes[sensor.Type]; ok {
+				limit = typeLimit
+			}
+		}
+	}
+
+	return l.take(ctx, "sensor:"+sn, limit)
+}
+
+// AllowClientEvent забирает токен на прием события с адреса клиента address.
+// При превышении ограничения возвращает *RateLimitError.
+func (l *RateLimiter) AllowClientEvent(ctx context.Context, address string) error {
+	return l.take(ctx, "client:"+address, l.client)
+}
+
+// AllowUserRead забирает токен на запрос пользователя userID к API чтения.
+// При превышении ограничения возвращает *RateLimitError.
+func (l *RateLimiter) AllowUserRead(ctx context.Context, userID int64) error {
+	limit, ok := l.users[userID]
+	if !ok {
+		limit = l.user
+	}
+
+	return l.take(ctx, "user:"+strconv.FormatInt(userID, 10), limit)
+}
+
+func (l *RateLimiter) take(ctx context.Context, key string, limit domain.RateLimit) error {
+	if limit.IsZero() {
+		return nil
+	}
+
+	retryAfter, err := l.store.TakeToken(ctx, key, limit, l.now())
+	if err != nil {
+		return err
+	}
+	if retryAfter > 0 {
+		return &RateLimitError{RetryAfter: retryAfter}
+	}
+	return nil
+}
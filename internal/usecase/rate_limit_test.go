@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_rateLimiter_AllowSensorEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	defaultLimit := domain.RateLimit{Rate: 10, Burst: 20}
+	ccLimit := domain.RateLimit{Rate: 1, Burst: 5}
+
+	t.Run("ok, no limits", func(t *testing.T) {
+		l := NewRateLimiter(nil, nil)
+		assert.NoError(t, l.AllowSensorEvent(context.Background(), "0000000001"))
+	})
+
+	t.Run("ok, limit by sensor type", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000001").Times(1).
+			Return(&domain.Sensor{ID: 1, Type: domain.SensorTypeContactClosure}, nil)
+
+		store := NewMockRateLimitStore(ctrl)
+		store.EXPECT().TakeToken(ctx, "sensor:0000000001", ccLimit, now).Times(1).Return(time.Duration(0), nil)
+
+		l := NewRateLimiter(store, sr,
+			WithSensorRateLimit(defaultLimit),
+			WithSensorTypeRateLimit(domain.SensorTypeContactClosure, ccLimit),
+			WithRateLimiterClock(func() time.Time { return now }),
+		)
+		assert.NoError(t, l.AllowSensorEvent(ctx, "0000000001"))
+	})
+
+	t.Run("fail, unknown sensor limited by default", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000002").Times(1).Return(nil, nil)
+
+		store := NewMockRateLimitStore(ctrl)
+		store.EXPECT().TakeToken(ctx, "sensor:0000000002", defaultLimit, now).Times(1).Return(1500*time.Millisecond, nil)
+
+		l := NewRateLimiter(store, sr,
+			WithSensorRateLimit(defaultLimit),
+			WithSensorTypeRateLimit(domain.SensorTypeContactClosure, ccLimit),
+			WithRateLimiterClock(func() time.Time { return now }),
+		)
+
+		err := l.AllowSensorEvent(ctx, "0000000002")
+		assert.ErrorIs(t, err, ErrRateLimited)
+		var limitErr *RateLimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, 1500*time.Millisecond, limitErr.RetryAfter)
+	})
+
+	t.Run("fail, repository error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		expectedError := errors.New("some error")
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000001").Times(1).Return(nil, expectedError)
+
+		l := NewRateLimiter(nil, sr, WithSensorTypeRateLimit(domain.SensorTypeADC, ccLimit))
+		assert.ErrorIs(t, l.AllowSensorEvent(ctx, "0000000001"), expectedError)
+	})
+}
+
+func Test_rateLimiter_AllowUserRead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	defaultLimit := domain.RateLimit{Rate: 5, Burst: 10}
+	adminLimit := domain.RateLimit{Rate: 50, Burst: 100}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := NewMockRateLimitStore(ctrl)
+	store.EXPECT().TakeToken(ctx, "user:1", adminLimit, now).Times(1).Return(time.Duration(0), nil)
+	store.EXPECT().TakeToken(ctx, "user:2", defaultLimit, now).Times(1).Return(time.Second, nil)
+
+	l := NewRateLimiter(store, nil,
+		WithUserRateLimit(defaultLimit),
+		WithUserIDRateLimit(1, adminLimit),
+		WithRateLimiterClock(func() time.Time { return now }),
+	)
+
+	assert.NoError(t, l.AllowUserRead(ctx, 1))
+	assert.ErrorIs(t, l.AllowUserRead(ctx, 2), ErrRateLimited)
+}
+
+func Test_rateLimiter_AllowClientEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limit := domain.RateLimit{Rate: 100, Burst: 200}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, NewRateLimiter(nil, nil).AllowClientEvent(ctx, "10.0.0.1"))
+
+	store := NewMockRateLimitStore(ctrl)
+	store.EXPECT().TakeToken(ctx, "client:10.0.0.1", limit, now).Times(1).Return(time.Second, nil)
+
+	l := NewRateLimiter(store, nil,
+		WithClientRateLimit(limit),
+		WithRateLimiterClock(func() time.Time { return now }),
+	)
+
+	assert.ErrorIs(t, l.AllowClientEvent(ctx, "10.0.0.1"), ErrRateLimited)
+}
@@ -0,0 +1,437 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxRuleNameLength - максимальная длина названия правила в символах
+	maxRuleNameLength = 100
+	// MaxRuleDuration - максимальное время, которое условие правила должно выполняться до срабатывания
+	MaxRuleDuration = 7 * 24 * time.Hour
+)
+
+// Rule - правила оповещений по значениям датчиков. Просматривать правила может любой, кому доступен датчик,
+// создавать, менять и удалять - операторы и владельцы датчика.
+//
+// Правила вычисляются при каждом событии датчика (EvaluateEvent) и периодически (EvaluateRules),
+// чтобы сработали условия "N минут подряд" и окна по времени суток, даже если датчик больше ничего не присылает.
+// Состояние вычисления хранится в репозитории, поэтому ожидание переживает перезапуск.
+// Оповещения по сработавшим правилам ведет Alert.
+type Rule struct {
+	ruleRepo   RuleRepository
+	sensorRepo SensorRepository
+	alerts     *Alert
+	access     *AccessPolicy
+	transactor Transactor
+	audit      *Audit
+	now        func() time.Time
+
+	// locks упорядочивают вычисления одного правила в процессе, чтобы событие и периодическое вычисление
+	// не перезаписали состояние друг друга; разные правила вычисляются независимо
+	locks ruleLocks
+}
+
+// ruleLocks - блокировки правил по id, блокировка удаляется, когда ее никто не держит и не ждет
+type ruleLocks struct {
+	mu    sync.Mutex
+	locks map[int64]*ruleLock
+}
+
+type ruleLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock блокирует правило id и возвращает функцию снятия блокировки
+func (l *ruleLocks) lock(id int64) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[int64]*ruleLock)
+	}
+	lock, ok := l.locks[id]
+	if !ok {
+		lock = &ruleLock{}
+		l.locks[id] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+
+		l.mu.Lock()
+		if lock.refs--; lock.refs == 0 {
+			delete(l.locks, id)
+		}
+		l.mu.Unlock()
+	}
+}
+
+func NewRule(rr RuleRepository, sr SensorRepository, alerts *Alert, options ...func(*Rule)) *Rule {
+	r := &Rule{
+		ruleRepo:   rr,
+		sensorRepo: sr,
+		alerts:     alerts,
+		transactor: noTransaction{},
+		now:        time.Now,
+	}
+
+	for _, o := range options {
+		o(r)
+	}
+
+	return r
+}
+
+// WithRuleAccessPolicy ограничивает правила правилами доступных пользователю из контекста датчиков
+func WithRuleAccessPolicy(p *AccessPolicy) func(*Rule) {
+	return func(r *Rule) {
+		r.access = p
+	}
+}
+
+// WithRuleTransactor задает транзакцию для сохранения состояния правила вместе с оповещением
+func WithRuleTransactor(t Transactor) func(*Rule) {
+	return func(r *Rule) {
+		r.transactor = t
+	}
+}
+
+// WithRuleAudit записывает создание, изменение и удаление правил в журнал аудита
+func WithRuleAudit(a *Audit) func(*Rule) {
+	return func(r *Rule) {
+		r.audit = a
+	}
+}
+
+// WithRuleClock подменяет источник текущего времени, используется в тестах
+func WithRuleClock(now func() time.Time) func(*Rule) {
+	return func(r *Rule) {
+		r.now = now
+	}
+}
+
+func (r *Rule) CreateRule(ctx context.Context, rule *domain.Rule) (*domain.Rule, error) {
+	if rule == nil {
+		return nil, ErrRuleNotFound
+	}
+	if err := r.validate(rule); err != nil {
+		return nil, err
+	}
+	if err := r.checkSensor(ctx, rule.SensorID, domain.SensorRoleOperator); err != nil {
+		return nil, err
+	}
+
+	rule.ID = 0
+	rule.CreatedAt = r.now()
+	rule.CreatedBy = 0
+	if caller, ok := UserFromContext(ctx); ok {
+		rule.CreatedBy = caller.ID
+	}
+
+	if err := r.ruleRepo.SaveRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	if err := r.audit.record(ctx, domain.AuditActionCreate, domain.AuditEntityRule, rule.ID, nil, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// GetRules возвращает правила датчика sensorID или, если он равен 0, правила всех доступных датчиков
+func (r *Rule) GetRules(ctx context.Context, sensorID int64) ([]domain.Rule, error) {
+	if sensorID != 0 {
+		if err := r.checkSensor(ctx, sensorID, domain.SensorRoleViewer); err != nil {
+			return nil, err
+		}
+		return r.ruleRepo.GetRulesBySensorID(ctx, sensorID)
+	}
+
+	rules, err := r.ruleRepo.GetRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	caller, ok := r.access.restricted(ctx)
+	if !ok {
+		return rules, nil
+	}
+
+	roles, err := r.access.SensorRoles(ctx, caller.ID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.Rule, 0, len(rules))
+	for _, rule := range rules {
+		if _, ok := roles[rule.SensorID]; ok {
+			result = append(result, rule)
+		}
+	}
+	return result, nil
+}
+
+// GetRuleByID возвращает правило. Правило недоступного датчика неотличимо от несуществующего.
+func (r *Rule) GetRuleByID(ctx context.Context, id int64) (*domain.Rule, error) {
+	rule, err := r.ruleRepo.GetRuleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, ErrRuleNotFound
+	}
+
+	if err := r.access.CheckSensor(ctx, rule.SensorID); err != nil {
+		if errors.Is(err, ErrSensorNotFound) {
+			return nil, ErrRuleNotFound
+		}
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// UpdateRule заменяет условие правила. Датчик правила не меняется, состояние вычисления сбрасывается,
+// незакрытое оповещение правила закрывается.
+func (r *Rule) UpdateRule(ctx context.Context, rule *domain.Rule) (*domain.Rule, error) {
+	if rule == nil {
+		return nil, ErrRuleNotFound
+	}
+
+	existingRule, err := r.GetRuleByID(ctx, rule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.SensorID = existingRule.SensorID
+	rule.CreatedBy = existingRule.CreatedBy
+	rule.CreatedAt = existingRule.CreatedAt
+	if err := r.validate(rule); err != nil {
+		return nil, err
+	}
+	if err := r.access.CheckSensorRole(ctx, rule.SensorID, domain.SensorRoleOperator); err != nil {
+		return nil, err
+	}
+
+	defer r.locks.lock(rule.ID)()
+
+	var resolved *domain.Alert
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.ruleRepo.SaveRule(ctx, rule); err != nil {
+			return err
+		}
+		if err := r.ruleRepo.DeleteRuleState(ctx, rule.ID); err != nil {
+			return err
+		}
+		alert, err := r.alerts.autoResolve(ctx, rule.ID, r.now())
+		if err != nil {
+			return err
+		}
+		resolved = alert
+		return r.audit.record(ctx, domain.AuditActionUpdate, domain.AuditEntityRule, rule.ID, existingRule, rule)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resolved != nil {
+		r.alerts.publish(*resolved)
+	}
+
+	return rule, nil
+}
+
+// DeleteRule удаляет правило и закрывает его незакрытое оповещение
+func (r *Rule) DeleteRule(ctx context.Context, id int64) error {
+	rule, err := r.GetRuleByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.access.CheckSensorRole(ctx, rule.SensorID, domain.SensorRoleOperator); err != nil {
+		return err
+	}
+
+	defer r.locks.lock(id)()
+
+	var resolved *domain.Alert
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.ruleRepo.DeleteRule(ctx, id); err != nil {
+			return err
+		}
+		alert, err := r.alerts.autoResolve(ctx, id, r.now())
+		if err != nil {
+			return err
+		}
+		resolved = alert
+		return r.audit.record(ctx, domain.AuditActionDelete, domain.AuditEntityRule, id, rule, nil)
+	})
+	if err != nil {
+		return err
+	}
+	if resolved != nil {
+		r.alerts.publish(*resolved)
+	}
+
+	return nil
+}
+
+// EvaluateEvent вычисляет правила датчика по новому событию. Событие, при котором условие сработавшего правила
+// продолжает выполняться, учитывается в его оповещении как повторное срабатывание.
+func (r *Rule) EvaluateEvent(ctx context.Context, sensor *domain.Sensor, event *domain.Event) error {
+	rules, err := r.ruleRepo.GetRulesBySensorID(ctx, sensor.ID)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	value := eventValue(sensor, event)
+	for i := range rules {
+		err := r.evaluateLocked(ctx, &rules[i], true, func(state *domain.RuleState) {
+			state.Value = value
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eventValue возвращает значение события датчика с учетом калибровки, для датчика без калибровки - Payload
+func eventValue(sensor *domain.Sensor, event *domain.Event) float64 {
+	calibrated := *event
+	sensor.ApplyCalibration(&calibrated)
+	if calibrated.Value != nil {
+		return *calibrated.Value
+	}
+	return float64(event.Payload)
+}
+
+// EvaluateRules вычисляет все правила, по которым уже были события, на текущий момент.
+// Вызывается периодически, чтобы срабатывали условия с длительностью и окнами без новых событий.
+func (r *Rule) EvaluateRules(ctx context.Context) error {
+	states, err := r.ruleRepo.GetRuleStates(ctx)
+	if err != nil {
+		return err
+	}
+	if len(states) == 0 {
+		return nil
+	}
+
+	rules, err := r.ruleRepo.GetRules(ctx)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int64]*domain.Rule, len(rules))
+	for i := range rules {
+		byID[rules[i].ID] = &rules[i]
+	}
+
+	for _, state := range states {
+		rule, ok := byID[state.RuleID]
+		if !ok {
+			continue
+		}
+		if err := r.evaluateLocked(ctx, rule, false, func(*domain.RuleState) {}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evaluateLocked вычисляет правило под его блокировкой. Правило, удаленное, пока ожидалась блокировка,
+// пропускается: его состояние уже не сохранить.
+func (r *Rule) evaluateLocked(ctx context.Context, rule *domain.Rule, event bool, update func(*domain.RuleState)) error {
+	defer r.locks.lock(rule.ID)()
+
+	err := r.evaluate(ctx, rule, event, update)
+	if errors.Is(err, ErrRuleNotFound) {
+		return nil
+	}
+	return err
+}
+
+// evaluate вычисляет правило по его сохраненному состоянию, измененному update, сохраняет новое состояние
+// и ведет оповещение правила: создает его при срабатывании, учитывает повторы, если event, и закрывает,
+// когда условие перестает выполняться
+func (r *Rule) evaluate(ctx context.Context, rule *domain.Rule, event bool, update func(*domain.RuleState)) error {
+	var alert *domain.Alert
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		state, err := r.ruleRepo.GetRuleState(ctx, rule.ID)
+		if err != nil {
+			return err
+		}
+		if state == nil {
+			state = &domain.RuleState{RuleID: rule.ID}
+		}
+		update(state)
+
+		now := r.now()
+		result := rule.Evaluate(state, now)
+		if err := r.ruleRepo.SaveRuleState(ctx, *state); err != nil {
+			return err
+		}
+
+		switch {
+		case result == domain.RuleFired || result == domain.RuleFiring && event:
+			alert, err = r.alerts.raise(ctx, rule, state.Value, now)
+		case result == domain.RuleCleared:
+			alert, err = r.alerts.autoResolve(ctx, rule.ID, now)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if alert != nil {
+		r.alerts.publish(*alert)
+	}
+	return nil
+}
+
+func (r *Rule) validate(rule *domain.Rule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" || utf8.RuneCountInString(rule.Name) > maxRuleNameLength {
+		return ErrInvalidRule
+	}
+	if !rule.Operator.IsValid() || rule.Duration < 0 || rule.Duration > MaxRuleDuration {
+		return ErrInvalidRule
+	}
+
+	if rule.Window != nil && !isWindowValid(rule.Window) {
+		return ErrInvalidRule
+	}
+	return nil
+}
+
+// isWindowValid сообщает, задает ли окно непустой интервал времени суток в известном часовом поясе
+func isWindowValid(window *domain.RuleWindow) bool {
+	if window.From < 0 || window.From >= domain.MinutesPerDay || window.To < 0 || window.To >= domain.MinutesPerDay ||
+		window.From == window.To {
+		return false
+	}
+	_, err := window.Location()
+	return err == nil
+}
+
+func (r *Rule) checkSensor(ctx context.Context, sensorID int64, required domain.SensorRole) error {
+	sensor, err := r.sensorRepo.GetSensorByID(ctx, sensorID)
+	if err != nil {
+		return err
+	}
+	if sensor == nil {
+		return ErrSensorNotFound
+	}
+
+	return r.access.CheckSensorRole(ctx, sensorID, required)
+}
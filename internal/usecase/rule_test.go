@@ -0,0 +1,266 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_rule_CreateRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	valid := func() *domain.Rule {
+		return &domain.Rule{SensorID: 1, Name: " Жарко ", Operator: domain.RuleOperatorGreater, Threshold: 800, Enabled: true}
+	}
+
+	t.Run("fail, invalid rule", func(t *testing.T) {
+		r := NewRule(nil, nil, nil)
+		ctx := context.Background()
+
+		invalid := []func(*domain.Rule){
+			func(rule *domain.Rule) { rule.Name = "  " },
+			func(rule *domain.Rule) { rule.Operator = "=>" },
+			func(rule *domain.Rule) { rule.Duration = -time.Second },
+			func(rule *domain.Rule) { rule.Duration = MaxRuleDuration + time.Second },
+			func(rule *domain.Rule) { rule.Window = &domain.RuleWindow{From: 60, To: 60} },
+			func(rule *domain.Rule) { rule.Window = &domain.RuleWindow{From: -1, To: 60} },
+			func(rule *domain.Rule) { rule.Window = &domain.RuleWindow{From: 0, To: domain.MinutesPerDay} },
+			func(rule *domain.Rule) { rule.Window = &domain.RuleWindow{From: 0, To: 60, TimeZone: "Mars/Olympus"} },
+		}
+		for _, modify := range invalid {
+			rule := valid()
+			modify(rule)
+			_, err := r.CreateRule(ctx, rule)
+			assert.ErrorIs(t, err, ErrInvalidRule)
+		}
+	})
+
+	t.Run("fail, viewer can't create rules", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 2})
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Times(1).
+			Return([]domain.SensorOwner{{UserID: 2, SensorID: 1, Role: domain.SensorRoleViewer}}, nil)
+
+		r := NewRule(nil, sr, nil, WithRuleAccessPolicy(NewAccessPolicy(sor, nil, nil)))
+
+		_, err := r.CreateRule(ctx, valid())
+		assert.ErrorIs(t, err, ErrInsufficientRole)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 1, IsAdmin: true})
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		rr := NewMockRuleRepository(ctrl)
+		rr.EXPECT().SaveRule(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, rule *domain.Rule) {
+			rule.ID = 3
+		})
+
+		r := NewRule(rr, sr, nil, WithRuleClock(func() time.Time { return now }))
+
+		rule, err := r.CreateRule(ctx, valid())
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), rule.ID)
+		assert.Equal(t, "Жарко", rule.Name)
+		assert.Equal(t, int64(1), rule.CreatedBy)
+		assert.Equal(t, now, rule.CreatedAt)
+	})
+}
+
+func Test_rule_EvaluateEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("ok, no rules", func(t *testing.T) {
+		ctx := context.Background()
+
+		rr := NewMockRuleRepository(ctrl)
+		rr.EXPECT().GetRulesBySensorID(ctx, int64(1)).Times(1).Return([]domain.Rule{}, nil)
+
+		r := NewRule(rr, nil, nil)
+		assert.NoError(t, r.EvaluateEvent(ctx, &domain.Sensor{ID: 1}, &domain.Event{SensorID: 1, Payload: 1}))
+	})
+
+	t.Run("ok, calibrated value fires rule", func(t *testing.T) {
+		ctx := context.Background()
+
+		rule := domain.Rule{ID: 3, SensorID: 1, Operator: domain.RuleOperatorGreater, Threshold: 80, Enabled: true}
+		rr := NewMockRuleRepository(ctrl)
+		rr.EXPECT().GetRulesBySensorID(ctx, int64(1)).Times(1).Return([]domain.Rule{rule}, nil)
+		rr.EXPECT().GetRuleState(ctx, int64(3)).Times(1).Return(nil, nil)
+		rr.EXPECT().SaveRuleState(ctx, domain.RuleState{RuleID: 3, Value: 90, PendingSince: &now, Firing: true, UpdatedAt: now}).
+			Times(1).Return(nil)
+
+		ar := NewMockAlertRepository(ctrl)
+		ar.EXPECT().GetActiveAlertByRuleID(ctx, int64(3)).Times(1).Return(nil, nil)
+		ar.EXPECT().SaveAlert(ctx, &domain.Alert{RuleID: 3, SensorID: 1, Status: domain.AlertStatusOpen, Value: 90,
+			Occurrences: 1, CreatedAt: now, LastOccurredAt: now}).Times(1).Return(nil)
+
+		r := NewRule(rr, nil, NewAlert(ar), WithRuleClock(func() time.Time { return now }))
+
+		sensor := &domain.Sensor{ID: 1, Calibration: &domain.Calibration{Scale: 0.1}}
+		event := &domain.Event{SensorID: 1, Payload: 900}
+		require.NoError(t, r.EvaluateEvent(ctx, sensor, event))
+		assert.Nil(t, event.Value, "event itself is not changed")
+	})
+
+	t.Run("ok, flapping sensor does not raise alert", func(t *testing.T) {
+		ctx := context.Background()
+
+		rule := domain.Rule{ID: 3, SensorID: 1, Operator: domain.RuleOperatorGreater, Threshold: 0, Enabled: true}
+		rr := NewMockRuleRepository(ctrl)
+		rr.EXPECT().GetRulesBySensorID(ctx, int64(1)).Times(1).Return([]domain.Rule{rule}, nil)
+		rr.EXPECT().GetRuleState(ctx, int64(3)).Times(1).Return(nil, nil)
+		rr.EXPECT().SaveRuleState(ctx, gomock.Any()).Times(1).Return(nil)
+
+		sensor := &domain.Sensor{ID: 1, Type: domain.SensorTypeContactClosure, Flapping: true}
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(sensor, nil)
+
+		r := NewRule(rr, nil, NewAlert(nil, WithAlertFlappingSuppression(sr)), WithRuleClock(func() time.Time { return now }))
+		require.NoError(t, r.EvaluateEvent(ctx, sensor, &domain.Event{SensorID: 1, Payload: 1}))
+	})
+
+	t.Run("ok, repeat is counted in active alert", func(t *testing.T) {
+		ctx := context.Background()
+
+		rule := domain.Rule{ID: 3, SensorID: 1, Operator: domain.RuleOperatorGreater, Threshold: 800, Enabled: true}
+		since := now.Add(-time.Minute)
+		rr := NewMockRuleRepository(ctrl)
+		rr.EXPECT().GetRulesBySensorID(ctx, int64(1)).Times(1).Return([]domain.Rule{rule}, nil)
+		rr.EXPECT().GetRuleState(ctx, int64(3)).Times(1).
+			Return(&domain.RuleState{RuleID: 3, Value: 850, PendingSince: &since, Firing: true, UpdatedAt: since}, nil)
+		rr.EXPECT().SaveRuleState(ctx, domain.RuleState{RuleID: 3, Value: 900, PendingSince: &since, Firing: true, UpdatedAt: now}).
+			Times(1).Return(nil)
+
+		ar := NewMockAlertRepository(ctrl)
+		ar.EXPECT().GetActiveAlertByRuleID(ctx, int64(3)).Times(1).
+			Return(&domain.Alert{ID: 7, RuleID: 3, SensorID: 1, Status: domain.AlertStatusAcknowledged, Value: 850, Occurrences: 1}, nil)
+		ar.EXPECT().AddAlertOccurrence(ctx, int64(7), float64(900), now).Times(1).Return(nil)
+
+		alerts := NewAlert(ar)
+		subscribeCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		updates := alerts.SubscribeAlerts(subscribeCtx)
+
+		r := NewRule(rr, nil, alerts, WithRuleClock(func() time.Time { return now }))
+		require.NoError(t, r.EvaluateEvent(ctx, &domain.Sensor{ID: 1}, &domain.Event{SensorID: 1, Payload: 900}))
+
+		alert := <-updates
+		assert.Equal(t, int64(7), alert.ID)
+		assert.Equal(t, 2, alert.Occurrences)
+		assert.Equal(t, float64(900), alert.Value)
+		assert.Equal(t, domain.AlertStatusAcknowledged, alert.Status)
+	})
+
+	t.Run("ok, cleared rule resolves alert", func(t *testing.T) {
+		ctx := context.Background()
+
+		rule := domain.Rule{ID: 3, SensorID: 1, Operator: domain.RuleOperatorGreater, Threshold: 800, Enabled: true}
+		since := now.Add(-time.Minute)
+		rr := NewMockRuleRepository(ctrl)
+		rr.EXPECT().GetRulesBySensorID(ctx, int64(1)).Times(1).Return([]domain.Rule{rule}, nil)
+		rr.EXPECT().GetRuleState(ctx, int64(3)).Times(1).
+			Return(&domain.RuleState{RuleID: 3, Value: 850, PendingSince: &since, Firing: true, UpdatedAt: since}, nil)
+		rr.EXPECT().SaveRuleState(ctx, domain.RuleState{RuleID: 3, Value: 700, UpdatedAt: now}).Times(1).Return(nil)
+
+		ar := NewMockAlertRepository(ctrl)
+		ar.EXPECT().GetActiveAlertByRuleID(ctx, int64(3)).Times(1).
+			Return(&domain.Alert{ID: 7, RuleID: 3, SensorID: 1, Status: domain.AlertStatusOpen, Value: 850, Occurrences: 1}, nil)
+		ar.EXPECT().UpdateAlertStatus(ctx, &domain.Alert{ID: 7, RuleID: 3, SensorID: 1, Status: domain.AlertStatusResolved,
+			Value: 850, Occurrences: 1, ResolvedAt: &now}).Times(1).Return(nil)
+
+		r := NewRule(rr, nil, NewAlert(ar), WithRuleClock(func() time.Time { return now }))
+		require.NoError(t, r.EvaluateEvent(ctx, &domain.Sensor{ID: 1}, &domain.Event{SensorID: 1, Payload: 700}))
+	})
+
+	t.Run("ok, pending rule waits for duration", func(t *testing.T) {
+		ctx := context.Background()
+
+		rule := domain.Rule{ID: 3, SensorID: 1, Operator: domain.RuleOperatorGreater, Threshold: 800, Duration: 5 * time.Minute, Enabled: true}
+		rr := NewMockRuleRepository(ctrl)
+		rr.EXPECT().GetRulesBySensorID(ctx, int64(1)).Times(1).Return([]domain.Rule{rule}, nil)
+		rr.EXPECT().GetRuleState(ctx, int64(3)).Times(1).Return(nil, nil)
+		rr.EXPECT().SaveRuleState(ctx, domain.RuleState{RuleID: 3, Value: 900, PendingSince: &now, UpdatedAt: now}).
+			Times(1).Return(nil)
+
+		r := NewRule(rr, nil, nil, WithRuleClock(func() time.Time { return now }))
+		require.NoError(t, r.EvaluateEvent(ctx, &domain.Sensor{ID: 1}, &domain.Event{SensorID: 1, Payload: 900}))
+	})
+}
+
+func Test_rule_EvaluateRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	pendingSince := now.Add(-5 * time.Minute)
+
+	ctx := context.Background()
+
+	rule := &domain.Rule{ID: 3, SensorID: 1, Operator: domain.RuleOperatorGreater, Threshold: 800, Duration: 5 * time.Minute, Enabled: true}
+	state := domain.RuleState{RuleID: 3, Value: 900, PendingSince: &pendingSince, UpdatedAt: pendingSince}
+
+	deleted := &domain.Rule{ID: 5, SensorID: 1, Operator: domain.RuleOperatorGreater, Threshold: 800, Enabled: true}
+
+	rr := NewMockRuleRepository(ctrl)
+	rr.EXPECT().GetRuleStates(ctx).Times(1).Return([]domain.RuleState{state, {RuleID: 4}, {RuleID: 5}}, nil)
+	rr.EXPECT().GetRules(ctx).Times(1).Return([]domain.Rule{*rule, *deleted}, nil)
+	rr.EXPECT().GetRuleState(ctx, int64(3)).Times(1).Return(&state, nil)
+	rr.EXPECT().SaveRuleState(ctx, domain.RuleState{RuleID: 3, Value: 900, PendingSince: &pendingSince, Firing: true, UpdatedAt: now}).
+		Times(1).Return(nil)
+	// правило удалено после загрузки списка
+	rr.EXPECT().GetRuleState(ctx, int64(5)).Times(1).Return(nil, nil)
+	rr.EXPECT().SaveRuleState(ctx, gomock.Any()).Times(1).Return(ErrRuleNotFound)
+
+	ar := NewMockAlertRepository(ctrl)
+	ar.EXPECT().GetActiveAlertByRuleID(ctx, int64(3)).Times(1).Return(nil, nil)
+	ar.EXPECT().SaveAlert(ctx, &domain.Alert{RuleID: 3, SensorID: 1, Status: domain.AlertStatusOpen, Value: 900,
+		Occurrences: 1, CreatedAt: now, LastOccurredAt: now}).Times(1).Return(nil)
+
+	r := NewRule(rr, nil, NewAlert(ar), WithRuleClock(func() time.Time { return now }))
+	require.NoError(t, r.EvaluateRules(ctx))
+}
+
+func Test_ruleLocks(t *testing.T) {
+	var locks ruleLocks
+
+	unlock := locks.lock(1)
+	// другое правило не ждет блокировки первого
+	locks.lock(2)()
+
+	locked := make(chan struct{})
+	go func() {
+		defer close(locked)
+		locks.lock(1)()
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("rule lock acquired twice")
+	case <-time.After(10 * time.Millisecond):
+	}
+	unlock()
+	<-locked
+
+	assert.Empty(t, locks.locks)
+}
@@ -0,0 +1,338 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultMissedRunGrace - на сколько запуск может опоздать, прежде чем он считается пропущенным
+const DefaultMissedRunGrace = 2 * time.Minute
+
+// Scheduler - расписания, которые отправляют команды устройствам или запускают сцены в заданное время.
+// Время расписания отсчитывается в часовом поясе его дома, восход и закат рассчитываются по координатам дома.
+// Пользователь без прав администратора видит и меняет только свои расписания в доступных ему домах.
+//
+// Расписание выполняется с правами автора, как и автоматизации, и каждый запуск записывается
+// в журнал запусков автоматизаций.
+type Scheduler struct {
+	scheduleRepo   ScheduleRepository
+	homeRepo       HomeRepository
+	automations    *Automation
+	access         *AccessPolicy
+	audit          *Audit
+	now            func() time.Time
+	missedRunGrace time.Duration
+}
+
+func NewScheduler(sr ScheduleRepository, hr HomeRepository, automations *Automation, options ...func(*Scheduler)) *Scheduler {
+	s := &Scheduler{
+		scheduleRepo:   sr,
+		homeRepo:       hr,
+		automations:    automations,
+		now:            time.Now,
+		missedRunGrace: DefaultMissedRunGrace,
+	}
+
+	for _, o := range options {
+		o(s)
+	}
+
+	return s
+}
+
+// WithSchedulerAccessPolicy ограничивает расписания пользователя его собственными, а дома - доступными ему
+func WithSchedulerAccessPolicy(p *AccessPolicy) func(*Scheduler) {
+	return func(s *Scheduler) {
+		s.access = p
+	}
+}
+
+// WithSchedulerAudit записывает создание, изменение и удаление расписаний в журнал аудита
+func WithSchedulerAudit(a *Audit) func(*Scheduler) {
+	return func(s *Scheduler) {
+		s.audit = a
+	}
+}
+
+// WithSchedulerClock подменяет источник текущего времени, используется в тестах
+func WithSchedulerClock(now func() time.Time) func(*Scheduler) {
+	return func(s *Scheduler) {
+		s.now = now
+	}
+}
+
+// WithSchedulerMissedRunGrace задает, на сколько запуск может опоздать, прежде чем к нему
+// применяется политика пропущенных запусков
+func WithSchedulerMissedRunGrace(d time.Duration) func(*Scheduler) {
+	return func(s *Scheduler) {
+		s.missedRunGrace = d
+	}
+}
+
+// CreateSchedule создает расписание, автором становится пользователь из контекста
+func (s *Scheduler) CreateSchedule(ctx context.Context, schedule *domain.Schedule) (*domain.Schedule, error) {
+	if schedule == nil {
+		return nil, ErrScheduleNotFound
+	}
+	home, err := s.validateSchedule(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.ID = 0
+	schedule.CreatedAt = s.now()
+	schedule.CreatedBy = 0
+	if caller, ok := UserFromContext(ctx); ok {
+		schedule.CreatedBy = caller.ID
+	}
+	schedule.LastRunAt = time.Time{}
+	if err := s.plan(schedule, home, schedule.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduleRepo.SaveSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	if err := s.audit.record(ctx, domain.AuditActionCreate, domain.AuditEntitySchedule, schedule.ID, nil, schedule); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// GetSchedules возвращает расписания пользователя из контекста, администратору - все расписания
+func (s *Scheduler) GetSchedules(ctx context.Context) ([]domain.Schedule, error) {
+	schedules, err := s.scheduleRepo.GetSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	caller, ok := s.access.restricted(ctx)
+	if !ok {
+		return schedules, nil
+	}
+
+	result := make([]domain.Schedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		if schedule.CreatedBy == caller.ID {
+			result = append(result, schedule)
+		}
+	}
+	return result, nil
+}
+
+// GetScheduleByID возвращает расписание. Чужое расписание неотличимо от несуществующего.
+func (s *Scheduler) GetScheduleByID(ctx context.Context, id int64) (*domain.Schedule, error) {
+	schedule, err := s.scheduleRepo.GetScheduleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, ErrScheduleNotFound
+	}
+
+	if caller, ok := s.access.restricted(ctx); ok && schedule.CreatedBy != caller.ID {
+		return nil, ErrScheduleNotFound
+	}
+
+	return schedule, nil
+}
+
+// UpdateSchedule заменяет дом, название, выражение, действие, политику пропущенных запусков
+// и включенность расписания. Следующий запуск рассчитывается заново от текущего времени.
+func (s *Scheduler) UpdateSchedule(ctx context.Context, schedule *domain.Schedule) (*domain.Schedule, error) {
+	if schedule == nil {
+		return nil, ErrScheduleNotFound
+	}
+
+	existingSchedule, err := s.GetScheduleByID(ctx, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+	home, err := s.validateSchedule(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.CreatedBy = existingSchedule.CreatedBy
+	schedule.CreatedAt = existingSchedule.CreatedAt
+	schedule.LastRunAt = existingSchedule.LastRunAt
+	if err := s.plan(schedule, home, s.now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduleRepo.SaveSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	if err := s.audit.record(ctx, domain.AuditActionUpdate, domain.AuditEntitySchedule, schedule.ID, existingSchedule, schedule); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// DeleteSchedule удаляет расписание вместе с журналом его запусков
+func (s *Scheduler) DeleteSchedule(ctx context.Context, id int64) error {
+	schedule, err := s.GetScheduleByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.scheduleRepo.DeleteSchedule(ctx, id); err != nil {
+		return err
+	}
+
+	return s.audit.record(ctx, domain.AuditActionDelete, domain.AuditEntitySchedule, id, schedule, nil)
+}
+
+// GetScheduleRuns возвращает страницу журнала запусков расписания filter.ScheduleID, от новых к старым
+func (s *Scheduler) GetScheduleRuns(ctx context.Context, filter domain.AutomationRunFilter) ([]domain.AutomationRun, error) {
+	if err := checkRunFilter(&filter); err != nil {
+		return nil, err
+	}
+	if filter.ScheduleID == 0 || filter.AutomationID != 0 || filter.SceneID != 0 {
+		return nil, ErrInvalidRunFilter
+	}
+
+	if _, err := s.GetScheduleByID(ctx, filter.ScheduleID); err != nil {
+		return nil, err
+	}
+
+	return s.automations.automationRepo.GetAutomationRuns(ctx, filter)
+}
+
+// RunDueSchedules выполняет расписания, время запуска которых наступило, и назначает им следующий запуск.
+// Запуск, опоздавший больше чем на missedRunGrace (например, после простоя сервиса), выполняется
+// один раз по политике MissedRunOnce или только записывается в журнал как пропущенный по MissedRunSkip.
+// Вызывается периодически.
+func (s *Scheduler) RunDueSchedules(ctx context.Context) error {
+	now := s.now()
+	schedules, err := s.scheduleRepo.GetDueSchedules(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range schedules {
+		if err := s.run(ctx, &schedules[i], now); err != nil {
+			errs = append(errs, fmt.Errorf("schedule %d: %w", schedules[i].ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// run забирает запуск расписания, назначая следующий, и выполняет его действие
+func (s *Scheduler) run(ctx context.Context, schedule *domain.Schedule, now time.Time) error {
+	// у удаленного дома расписание больше не запускается, о чем остается запись в журнале
+	home, homeErr := s.homeRepo.GetHomeByID(ctx, schedule.HomeID)
+	if homeErr == nil && home == nil {
+		homeErr = ErrHomeNotFound
+	}
+	if homeErr != nil && !errors.Is(homeErr, ErrHomeNotFound) {
+		return homeErr
+	}
+
+	var next time.Time
+	if homeErr == nil {
+		next = nextRun(schedule, home, now)
+	}
+	claimed, err := s.scheduleRepo.AdvanceSchedule(ctx, schedule.ID, schedule.NextRunAt, next, now)
+	if err != nil || !claimed {
+		return err
+	}
+
+	run := &domain.AutomationRun{ScheduleID: schedule.ID, CreatedAt: now}
+	if now.Sub(schedule.NextRunAt) > s.missedRunGrace && schedule.MissedRunPolicy == domain.MissedRunSkip {
+		run.Status = domain.AutomationRunSkipped
+		run.Error = "missed run scheduled at " + schedule.NextRunAt.UTC().Format(time.RFC3339)
+	} else {
+		source := &webhookAutomation{ScheduleID: schedule.ID, Name: schedule.Name}
+		s.automations.execute(ctx, run, schedule.CreatedBy, func(ctx context.Context) (bool, []error) {
+			if homeErr != nil {
+				return true, []error{fmt.Errorf("home: %w", homeErr)}
+			}
+			if err := s.access.CheckHome(ctx, schedule.HomeID); err != nil {
+				return true, []error{fmt.Errorf("home: %w", err)}
+			}
+			return true, s.automations.perform(ctx, []domain.AutomationAction{schedule.Action}, source, nil, nil, nil)
+		})
+	}
+
+	return s.automations.automationRepo.AddAutomationRun(ctx, run)
+}
+
+// validateSchedule проверяет расписание и возвращает его дом
+func (s *Scheduler) validateSchedule(ctx context.Context, schedule *domain.Schedule) (*domain.Home, error) {
+	schedule.Name = strings.TrimSpace(schedule.Name)
+	if schedule.Name == "" || utf8.RuneCountInString(schedule.Name) > maxAutomationNameLength {
+		return nil, ErrInvalidSchedule
+	}
+	if schedule.MissedRunPolicy == "" {
+		schedule.MissedRunPolicy = domain.MissedRunSkip
+	}
+	if !schedule.MissedRunPolicy.IsValid() {
+		return nil, ErrInvalidSchedule
+	}
+
+	schedule.Expression = strings.TrimSpace(schedule.Expression)
+	expr, err := domain.ParseScheduleExpression(schedule.Expression)
+	if err != nil {
+		return nil, ErrInvalidSchedule
+	}
+
+	home, err := s.homeRepo.GetHomeByID(ctx, schedule.HomeID)
+	if err != nil {
+		return nil, err
+	}
+	if home == nil {
+		return nil, ErrHomeNotFound
+	}
+	if err := s.access.CheckHome(ctx, schedule.HomeID); err != nil {
+		return nil, err
+	}
+	if expr.IsSolar() && home.Coordinates == nil {
+		return nil, ErrInvalidSchedule
+	}
+
+	if schedule.Action.Type != domain.ActionCommand && schedule.Action.Type != domain.ActionScene {
+		return nil, ErrInvalidSchedule
+	}
+	actions := []domain.AutomationAction{schedule.Action}
+	if err := s.automations.validateActions(ctx, actions, true, ErrInvalidSchedule); err != nil {
+		return nil, err
+	}
+	schedule.Action = actions[0]
+
+	return home, nil
+}
+
+// plan назначает включенному расписанию первый запуск после after, выключенное расписание не запускается
+func (s *Scheduler) plan(schedule *domain.Schedule, home *domain.Home, after time.Time) error {
+	schedule.NextRunAt = time.Time{}
+	if !schedule.Enabled {
+		return nil
+	}
+
+	schedule.NextRunAt = nextRun(schedule, home, after)
+	if schedule.NextRunAt.IsZero() {
+		return ErrInvalidSchedule
+	}
+	return nil
+}
+
+// nextRun возвращает время запуска расписания строго после after по часовому поясу и координатам дома,
+// нулевое время - запусков больше не будет
+func nextRun(schedule *domain.Schedule, home *domain.Home, after time.Time) time.Time {
+	expr, err := domain.ParseScheduleExpression(schedule.Expression)
+	if err != nil {
+		return time.Time{}
+	}
+	return expr.Next(after, home.Location(), home.Coordinates)
+}
@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_scheduler_CreateSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	home := &domain.Home{ID: 1, Name: "home", TimeZone: "Europe/Moscow"}
+	scene := domain.AutomationAction{Type: domain.ActionScene, SceneID: 3}
+
+	t.Run("fail, invalid schedule", func(t *testing.T) {
+		ctx := context.Background()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(1)).AnyTimes().Return(home, nil)
+		ar := NewMockAutomationRepository(ctrl)
+		ar.EXPECT().GetSceneByID(ctx, int64(3)).AnyTimes().Return(&domain.Scene{ID: 3}, nil)
+
+		s := NewScheduler(nil, hr, NewAutomation(ar, nil, nil, WithAutomationAlerts(NewAlert(nil))))
+
+		invalid := []*domain.Schedule{
+			{HomeID: 1, Name: " ", Expression: "@daily", Action: scene, Enabled: true},
+			{HomeID: 1, Name: "night", Expression: "every day", Action: scene, Enabled: true},
+			{HomeID: 1, Name: "night", Expression: "sunset", Action: scene, Enabled: true},
+			{HomeID: 1, Name: "night", Expression: "0 0 30 2 *", Action: scene, Enabled: true},
+			{HomeID: 1, Name: "night", Expression: "@daily", Action: domain.AutomationAction{Type: domain.ActionAlert}, Enabled: true},
+			{HomeID: 1, Name: "night", Expression: "@daily", Action: scene, MissedRunPolicy: "run_all", Enabled: true},
+		}
+		for _, schedule := range invalid {
+			_, err := s.CreateSchedule(ctx, schedule)
+			assert.ErrorIs(t, err, ErrInvalidSchedule, schedule)
+		}
+	})
+
+	t.Run("fail, someone else's home", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 2})
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(1)).Times(1).Return(home, nil)
+		hor := NewMockHomeOwnerRepository(ctrl)
+		hor.EXPECT().GetHomesByUserID(ctx, int64(2)).Times(1).Return(nil, nil)
+
+		s := NewScheduler(nil, hr, NewAutomation(nil, nil, nil),
+			WithSchedulerAccessPolicy(NewAccessPolicy(nil, hor, nil)))
+
+		_, err := s.CreateSchedule(ctx, &domain.Schedule{HomeID: 1, Name: "night", Expression: "@daily", Action: scene})
+		assert.ErrorIs(t, err, ErrHomeNotFound)
+	})
+
+	t.Run("ok, next run in home time zone", func(t *testing.T) {
+		ctx := context.Background()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(1)).Times(1).Return(home, nil)
+		ar := NewMockAutomationRepository(ctrl)
+		ar.EXPECT().GetSceneByID(ctx, int64(3)).Times(1).Return(&domain.Scene{ID: 3}, nil)
+		sr := NewMockScheduleRepository(ctrl)
+		sr.EXPECT().SaveSchedule(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, s *domain.Schedule) error {
+			s.ID = 1
+			return nil
+		})
+
+		s := NewScheduler(sr, hr, NewAutomation(ar, nil, nil), WithSchedulerClock(func() time.Time { return now }))
+
+		schedule, err := s.CreateSchedule(ctx, &domain.Schedule{
+			HomeID:     1,
+			Name:       " morning ",
+			Expression: "0 7 * * mon-fri",
+			Action:     domain.AutomationAction{Type: domain.ActionScene, SceneID: 3, WebhookID: 5},
+			Enabled:    true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "morning", schedule.Name)
+		assert.Equal(t, domain.MissedRunSkip, schedule.MissedRunPolicy)
+		assert.Equal(t, scene, schedule.Action)
+		// 12:00 UTC - 15:00 в Москве, следующий будний день - четверг
+		assert.True(t, time.Date(2025, 1, 2, 4, 0, 0, 0, time.UTC).Equal(schedule.NextRunAt), schedule.NextRunAt)
+	})
+
+	t.Run("ok, disabled schedule has no next run", func(t *testing.T) {
+		ctx := context.Background()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(1)).Times(1).Return(home, nil)
+		ar := NewMockAutomationRepository(ctrl)
+		ar.EXPECT().GetSceneByID(ctx, int64(3)).Times(1).Return(&domain.Scene{ID: 3}, nil)
+		sr := NewMockScheduleRepository(ctrl)
+		sr.EXPECT().SaveSchedule(ctx, gomock.Any()).Times(1).Return(nil)
+
+		s := NewScheduler(sr, hr, NewAutomation(ar, nil, nil), WithSchedulerClock(func() time.Time { return now }))
+
+		schedule, err := s.CreateSchedule(ctx, &domain.Schedule{HomeID: 1, Name: "night", Expression: "@daily", Action: scene})
+		require.NoError(t, err)
+		assert.True(t, schedule.NextRunAt.IsZero())
+	})
+}
+
+func Test_scheduler_RunDueSchedules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)
+	home := &domain.Home{ID: 1, Name: "home"}
+	schedule := domain.Schedule{
+		ID:              7,
+		HomeID:          1,
+		Name:            "every 15 minutes",
+		Expression:      "*/15 * * * *",
+		Action:          domain.AutomationAction{Type: domain.ActionScene, SceneID: 3},
+		MissedRunPolicy: domain.MissedRunSkip,
+		Enabled:         true,
+		NextRunAt:       time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	next := time.Date(2025, 1, 1, 12, 15, 0, 0, time.UTC)
+
+	setup := func(ctx context.Context, due domain.Schedule) (*MockScheduleRepository, *MockAutomationRepository, *Scheduler) {
+		sr := NewMockScheduleRepository(ctrl)
+		sr.EXPECT().GetDueSchedules(ctx, now).Times(1).Return([]domain.Schedule{due}, nil)
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(1)).AnyTimes().Return(home, nil)
+		ar := NewMockAutomationRepository(ctrl)
+
+		s := NewScheduler(sr, hr, NewAutomation(ar, nil, nil), WithSchedulerClock(func() time.Time { return now }))
+		return sr, ar, s
+	}
+	expectRun := func(ar *MockAutomationRepository, status domain.AutomationRunStatus) *domain.AutomationRun {
+		var stored domain.AutomationRun
+		ar.EXPECT().AddAutomationRun(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, run *domain.AutomationRun) error {
+			stored = *run
+			return nil
+		})
+		t.Cleanup(func() {
+			assert.Equal(t, status, stored.Status, stored.Error)
+			assert.Equal(t, schedule.ID, stored.ScheduleID)
+		})
+		return &stored
+	}
+
+	t.Run("ok, due schedule runs its scene and advances", func(t *testing.T) {
+		ctx := context.Background()
+		sr, ar, s := setup(ctx, schedule)
+
+		sr.EXPECT().AdvanceSchedule(ctx, schedule.ID, schedule.NextRunAt, gomock.Any(), now).Times(1).
+			DoAndReturn(func(_ context.Context, _ int64, _, got, _ time.Time) (bool, error) {
+				assert.True(t, next.Equal(got), got)
+				return true, nil
+			})
+		ar.EXPECT().GetSceneByID(gomock.Any(), int64(3)).Times(1).Return(&domain.Scene{ID: 3}, nil)
+		expectRun(ar, domain.AutomationRunSucceeded)
+
+		require.NoError(t, s.RunDueSchedules(ctx))
+	})
+
+	t.Run("ok, run claimed by another instance is not repeated", func(t *testing.T) {
+		ctx := context.Background()
+		sr, _, s := setup(ctx, schedule)
+
+		sr.EXPECT().AdvanceSchedule(ctx, schedule.ID, schedule.NextRunAt, gomock.Any(), now).Times(1).Return(false, nil)
+
+		require.NoError(t, s.RunDueSchedules(ctx))
+	})
+
+	t.Run("ok, missed run is skipped", func(t *testing.T) {
+		ctx := context.Background()
+		missed := schedule
+		missed.NextRunAt = schedule.NextRunAt.Add(-3 * time.Hour)
+		sr, ar, s := setup(ctx, missed)
+
+		sr.EXPECT().AdvanceSchedule(ctx, schedule.ID, missed.NextRunAt, gomock.Any(), now).Times(1).Return(true, nil)
+		run := expectRun(ar, domain.AutomationRunSkipped)
+
+		require.NoError(t, s.RunDueSchedules(ctx))
+		assert.Contains(t, run.Error, "missed run scheduled at 2025-01-01T09:00:00Z")
+	})
+
+	t.Run("ok, missed run runs once", func(t *testing.T) {
+		ctx := context.Background()
+		missed := schedule
+		missed.NextRunAt = schedule.NextRunAt.Add(-3 * time.Hour)
+		missed.MissedRunPolicy = domain.MissedRunOnce
+		sr, ar, s := setup(ctx, missed)
+
+		sr.EXPECT().AdvanceSchedule(ctx, schedule.ID, missed.NextRunAt, gomock.Any(), now).Times(1).Return(true, nil)
+		ar.EXPECT().GetSceneByID(gomock.Any(), int64(3)).Times(1).Return(&domain.Scene{ID: 3}, nil)
+		expectRun(ar, domain.AutomationRunSucceeded)
+
+		require.NoError(t, s.RunDueSchedules(ctx))
+	})
+
+	t.Run("ok, deleted home stops schedule", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockScheduleRepository(ctrl)
+		sr.EXPECT().GetDueSchedules(ctx, now).Times(1).Return([]domain.Schedule{schedule}, nil)
+		sr.EXPECT().AdvanceSchedule(ctx, schedule.ID, schedule.NextRunAt, time.Time{}, now).Times(1).Return(true, nil)
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(1)).Times(1).Return(nil, ErrHomeNotFound)
+		ar := NewMockAutomationRepository(ctrl)
+		run := expectRun(ar, domain.AutomationRunFailed)
+
+		s := NewScheduler(sr, hr, NewAutomation(ar, nil, nil), WithSchedulerClock(func() time.Time { return now }))
+
+		require.NoError(t, s.RunDueSchedules(ctx))
+		assert.Equal(t, "home: home not found", run.Error)
+	})
+}
@@ -3,39 +3,243 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"homework/internal/domain"
-	"regexp"
+	"homework/internal/serial"
+	"math"
+	"slices"
+	"time"
 )
 
 type Sensor struct {
-	sensorRepo SensorRepository
+	sensorRepo      SensorRepository
+	serialValidator SerialNumberValidator
+	userRepo        UserRepository
+	sensorOwnerRepo SensorOwnerRepository
+	transactor      Transactor
+	firmwareRepo    FirmwareHistoryRepository
+	access          *AccessPolicy
+	audit           *Audit
+	anomalies       *Anomaly
+	debounce        *Debounce
+	virtualRepo     VirtualSensorRepository
+	deviceAuth      *DeviceAuth
 }
 
-func NewSensor(sr SensorRepository) *Sensor {
-	return &Sensor{
-		sensorRepo: sr,
+func NewSensor(sr SensorRepository, options ...func(*Sensor)) *Sensor {
+	s := &Sensor{
+		sensorRepo:      sr,
+		serialValidator: serial.Default(),
+		transactor:      noTransaction{},
+	}
+
+	for _, o := range options {
+		o(s)
+	}
+
+	return s
+}
+
+// WithSensorSerialValidator задает схемы серийных номеров, по которым проверяются регистрируемые датчики
+func WithSensorSerialValidator(v SerialNumberValidator) func(*Sensor) {
+	return func(s *Sensor) {
+		s.serialValidator = v
+	}
+}
+
+// WithSensorOwners позволяет привязывать датчики к пользователям при массовой регистрации
+func WithSensorOwners(ur UserRepository, sor SensorOwnerRepository) func(*Sensor) {
+	return func(s *Sensor) {
+		s.userRepo = ur
+		s.sensorOwnerRepo = sor
+	}
+}
+
+// WithSensorTransactor задает транзакции для изменений, затрагивающих несколько записей
+func WithSensorTransactor(t Transactor) func(*Sensor) {
+	return func(s *Sensor) {
+		s.transactor = t
+	}
+}
+
+// WithSensorAccessPolicy ограничивает чтение датчиков датчиками пользователя из контекста
+// и привязывает новые датчики к зарегистрировавшему их пользователю
+func WithSensorAccessPolicy(p *AccessPolicy) func(*Sensor) {
+	return func(s *Sensor) {
+		s.access = p
+	}
+}
+
+// WithSensorAudit записывает изменения датчиков в журнал аудита
+func WithSensorAudit(a *Audit) func(*Sensor) {
+	return func(s *Sensor) {
+		s.audit = a
 	}
 }
 
-func isSensorValid(sensor *domain.Sensor) error {
+// WithSensorAnomalies сбрасывает статистику и закрывает оповещение об аномалиях датчика,
+// когда у него выключается обнаружение аномалий
+func WithSensorAnomalies(a *Anomaly) func(*Sensor) {
+	return func(s *Sensor) {
+		s.anomalies = a
+	}
+}
+
+// WithSensorDebounce сбрасывает отложенные смены состояния и учет переключений датчика,
+// когда у него меняются настройки подавления дребезга
+func WithSensorDebounce(d *Debounce) func(*Sensor) {
+	return func(s *Sensor) {
+		s.debounce = d
+	}
+}
+
+// WithSensorDeviceAuth выпускает секреты подписи событий датчикам, созданным импортом,
+// в той же транзакции, что и сами датчики
+func WithSensorDeviceAuth(d *DeviceAuth) func(*Sensor) {
+	return func(s *Sensor) {
+		s.deviceAuth = d
+	}
+}
+
+// ValidateSerialNumber проверяет серийный номер по зарегистрированным схемам.
+// Ошибка схемы оборачивается в ErrWrongSensorSerialNumber.
+func (s *Sensor) ValidateSerialNumber(sn string) error {
+	if err := s.serialValidator.Validate(sn); err != nil {
+		return fmt.Errorf("%w: %w", ErrWrongSensorSerialNumber, err)
+	}
+	return nil
+}
+
+func (s *Sensor) isSensorValid(sensor *domain.Sensor) error {
 	if sensor == nil {
 		return ErrWrongSensorSerialNumber
 	}
 
-	matched, _ := regexp.MatchString(`^\d{10}$`, sensor.SerialNumber)
-	if !matched {
-		return ErrWrongSensorSerialNumber
+	if err := s.ValidateSerialNumber(sensor.SerialNumber); err != nil {
+		return err
 	}
 
-	if sensor.Type != domain.SensorTypeADC && sensor.Type != domain.SensorTypeContactClosure {
+	if !sensor.Type.IsValid() {
 		return ErrWrongSensorType
 	}
 
+	if err := isCalibrationValid(sensor.Type, sensor.Calibration); err != nil {
+		return err
+	}
+	if err := isAnomalyDetectionValid(sensor.Type, sensor.AnomalyDetection); err != nil {
+		return err
+	}
+	if err := isDebounceValid(sensor.Type, sensor.Debounce); err != nil {
+		return err
+	}
+	if err := isLabelsValid(sensor.Labels); err != nil {
+		return err
+	}
+	_, err := isVirtualSensorValid(sensor.Type, sensor.Virtual)
+	return err
+}
+
+func isLabelsValid(labels []string) error {
+	if len(labels) > domain.MaxSensorLabels {
+		return ErrInvalidSensorLabels
+	}
+	for i, label := range labels {
+		if !domain.IsValidLabel(label) || slices.Contains(labels[:i], label) {
+			return ErrInvalidSensorLabels
+		}
+	}
+	return nil
+}
+
+func isCalibrationValid(sensorType domain.SensorType, calibration *domain.Calibration) error {
+	if calibration == nil {
+		return nil
+	}
+
+	if sensorType != domain.SensorTypeADC {
+		return ErrInvalidCalibration
+	}
+
+	if calibration.Scale == 0 || math.IsNaN(calibration.Scale) || math.IsInf(calibration.Scale, 0) {
+		return ErrInvalidCalibration
+	}
+
+	if len(calibration.Table) > 0 && len(calibration.Polynomial) > 0 {
+		return ErrInvalidCalibration
+	}
+
+	if len(calibration.Table) == 1 {
+		return ErrInvalidCalibration
+	}
+	for i := 1; i < len(calibration.Table); i++ {
+		if calibration.Table[i].Raw <= calibration.Table[i-1].Raw {
+			return ErrInvalidCalibration
+		}
+	}
+
+	return nil
+}
+
+// maxAnomalyWarmUp - максимальное число значений, накапливаемых до первой оценки аномальности
+const maxAnomalyWarmUp = 10000
+
+func isAnomalyDetectionValid(sensorType domain.SensorType, detection *domain.AnomalyDetection) error {
+	if detection == nil {
+		return nil
+	}
+
+	if sensorType != domain.SensorTypeADC {
+		return ErrInvalidAnomalyDetection
+	}
+
+	// сравнения ложны для NaN
+	if !(detection.Alpha > 0 && detection.Alpha < 1) || !(detection.Sigma > 0) || math.IsInf(detection.Sigma, 0) {
+		return ErrInvalidAnomalyDetection
+	}
+	if detection.WarmUp < 2 || detection.WarmUp > maxAnomalyWarmUp {
+		return ErrInvalidAnomalyDetection
+	}
+
+	return nil
+}
+
+const (
+	// maxDebounceWindow - максимальное окно подавления дребезга
+	maxDebounceWindow = time.Minute
+	// maxFlapThreshold - максимальный порог переключений нестабильного датчика
+	maxFlapThreshold = 10000
+	// maxFlapWindow - максимальное окно подсчета переключений
+	maxFlapWindow = 24 * time.Hour
+)
+
+func isDebounceValid(sensorType domain.SensorType, debounce *domain.Debounce) error {
+	if debounce == nil {
+		return nil
+	}
+
+	if sensorType != domain.SensorTypeContactClosure {
+		return ErrInvalidDebounce
+	}
+
+	if debounce.Window < 0 || debounce.Window > maxDebounceWindow {
+		return ErrInvalidDebounce
+	}
+	if debounce.FlapThreshold < 0 || debounce.FlapThreshold > maxFlapThreshold {
+		return ErrInvalidDebounce
+	}
+	if debounce.FlapThreshold == 0 && debounce.FlapWindow != 0 ||
+		debounce.FlapThreshold > 0 && (debounce.FlapWindow <= 0 || debounce.FlapWindow > maxFlapWindow) {
+		return ErrInvalidDebounce
+	}
+	if debounce.Window == 0 && debounce.FlapThreshold == 0 {
+		return ErrInvalidDebounce
+	}
+
 	return nil
 }
 
 func (s *Sensor) RegisterSensor(ctx context.Context, sensor *domain.Sensor) (*domain.Sensor, error) {
-	if err := isSensorValid(sensor); err != nil {
+	if err := s.isSensorValid(sensor); err != nil {
 		return nil, err
 	}
 
@@ -46,10 +250,22 @@ func (s *Sensor) RegisterSensor(ctx context.Context, sensor *domain.Sensor) (*do
 	}
 
 	if existingSensor != nil {
+		if err := s.access.CheckSensor(ctx, existingSensor.ID); err != nil {
+			return nil, ErrSensorAlreadyExists
+		}
 		return existingSensor, nil
 	}
 
-	if err := s.sensorRepo.SaveSensor(ctx, sensor); err != nil {
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.sensorRepo.SaveSensor(ctx, sensor); err != nil {
+			return err
+		}
+		if err := s.access.grantSensor(ctx, sensor.ID); err != nil {
+			return err
+		}
+		return s.audit.record(ctx, domain.AuditActionCreate, domain.AuditEntitySensor, sensor.ID, nil, sensor)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -62,7 +278,7 @@ func (s *Sensor) GetSensors(ctx context.Context) ([]domain.Sensor, error) {
 		return nil, err
 	}
 
-	return sensors, nil
+	return s.access.FilterSensors(ctx, sensors)
 }
 
 func (s *Sensor) GetSensorByID(ctx context.Context, id int64) (*domain.Sensor, error) {
@@ -74,5 +290,116 @@ func (s *Sensor) GetSensorByID(ctx context.Context, id int64) (*domain.Sensor, e
 		return nil, ErrSensorNotFound
 	}
 
+	if err := s.access.CheckSensor(ctx, id); err != nil {
+		return nil, err
+	}
+
 	return sensor, nil
 }
+
+// WatchSensorAccess возвращает канал, который закрывается, когда пользователь из контекста теряет доступ к датчику,
+// например после исключения из группы. Используется, чтобы закрывать подписки на события датчика.
+func (s *Sensor) WatchSensorAccess(ctx context.Context, id int64) <-chan struct{} {
+	return s.access.WatchSensor(ctx, id)
+}
+
+// SetSensorCalibration задает или, если calibration равен nil, сбрасывает калибровку датчика.
+// Сохраненные события не изменяются: инженерные значения вычисляются при чтении.
+func (s *Sensor) SetSensorCalibration(ctx context.Context, id int64, calibration *domain.Calibration) (*domain.Sensor, error) {
+	sensor, err := s.GetSensorByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.access.CheckSensorRole(ctx, id, domain.SensorRoleOperator); err != nil {
+		return nil, err
+	}
+
+	if err := isCalibrationValid(sensor.Type, calibration); err != nil {
+		return nil, err
+	}
+
+	updated := *sensor
+	updated.Calibration = calibration
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.sensorRepo.SaveSensor(ctx, &updated); err != nil {
+			return err
+		}
+		return s.audit.record(ctx, domain.AuditActionUpdate, domain.AuditEntitySensor, id, sensor, &updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// SetSensorAnomalyDetection задает или, если detection равен nil, выключает обнаружение аномалий датчика.
+// При выключении накопленная статистика сбрасывается, а оповещение об аномалиях закрывается.
+func (s *Sensor) SetSensorAnomalyDetection(ctx context.Context, id int64, detection *domain.AnomalyDetection) (*domain.Sensor, error) {
+	sensor, err := s.GetSensorByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.access.CheckSensorRole(ctx, id, domain.SensorRoleOperator); err != nil {
+		return nil, err
+	}
+
+	if err := isAnomalyDetectionValid(sensor.Type, detection); err != nil {
+		return nil, err
+	}
+
+	updated := *sensor
+	updated.AnomalyDetection = detection
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.sensorRepo.SaveSensor(ctx, &updated); err != nil {
+			return err
+		}
+		return s.audit.record(ctx, domain.AuditActionUpdate, domain.AuditEntitySensor, id, sensor, &updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if detection == nil && s.anomalies != nil {
+		if err := s.anomalies.disable(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	return &updated, nil
+}
+
+// SetSensorDebounce задает или, если debounce равен nil, выключает подавление дребезга датчика сухого контакта.
+// Отложенные смены состояния и учет переключений сбрасываются, отметка о нестабильности снимается.
+func (s *Sensor) SetSensorDebounce(ctx context.Context, id int64, debounce *domain.Debounce) (*domain.Sensor, error) {
+	sensor, err := s.GetSensorByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.access.CheckSensorRole(ctx, id, domain.SensorRoleOperator); err != nil {
+		return nil, err
+	}
+
+	if err := isDebounceValid(sensor.Type, debounce); err != nil {
+		return nil, err
+	}
+
+	updated := *sensor
+	updated.Debounce = debounce
+	updated.Flapping = false
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.sensorRepo.SaveSensor(ctx, &updated); err != nil {
+			return err
+		}
+		return s.audit.record(ctx, domain.AuditActionUpdate, domain.AuditEntitySensor, id, sensor, &updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.debounce != nil {
+		s.debounce.forget(id)
+	}
+
+	return &updated, nil
+}
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+)
+
+// ImportSensors регистрирует датчики пачкой. Каждая строка проверяется по тем же правилам, что и RegisterSensor.
+// В режиме dryRun ничего не сохраняется. В режиме domain.SensorImportAtomic при наличии хотя бы одной
+// некорректной строки или ошибке сохранения не сохраняется ничего и возвращается ErrImportRejected.
+// Результаты по строкам возвращаются всегда, в том числе вместе с ошибкой.
+func (s *Sensor) ImportSensors(
+	ctx context.Context,
+	rows []domain.SensorImportRow,
+	mode domain.SensorImportMode,
+	dryRun bool,
+) ([]domain.SensorImportResult, error) {
+	results, err := s.validateImport(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return results, nil
+	}
+
+	if mode == domain.SensorImportBestEffort {
+		for i := range rows {
+			if results[i].Status == domain.SensorImportStatusInvalid {
+				continue
+			}
+			err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+				return s.importRow(ctx, &rows[i], &results[i])
+			})
+			if err != nil {
+				results[i].Status = domain.SensorImportStatusFailed
+				results[i].Secret = ""
+				results[i].Err = err
+			}
+		}
+		return results, nil
+	}
+
+	for _, result := range results {
+		if result.Status == domain.SensorImportStatusInvalid {
+			skipPending(results)
+			return results, ErrImportRejected
+		}
+	}
+
+	var failed int
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for i := range rows {
+			if err := s.importRow(ctx, &rows[i], &results[i]); err != nil {
+				failed = i
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		results[failed].Status = domain.SensorImportStatusFailed
+		results[failed].Err = err
+		for i := range results {
+			results[i].Secret = ""
+			if i != failed {
+				results[i].Status = domain.SensorImportStatusSkipped
+				results[i].SensorID = 0
+			}
+		}
+		return results, fmt.Errorf("%w: %w", ErrImportRejected, err)
+	}
+
+	return results, nil
+}
+
+// validateImport проверяет строки импорта, ничего не сохраняя
+func (s *Sensor) validateImport(ctx context.Context, rows []domain.SensorImportRow) ([]domain.SensorImportResult, error) {
+	results := make([]domain.SensorImportResult, len(rows))
+	seen := make(map[string]int, len(rows))
+
+	for i := range rows {
+		row := &rows[i]
+		result := &results[i]
+		result.Row = i + 1
+		result.SerialNumber = row.Sensor.SerialNumber
+		result.Status = domain.SensorImportStatusValid
+
+		if err := s.isSensorValid(&row.Sensor); err != nil {
+			result.Status, result.Err = domain.SensorImportStatusInvalid, err
+			continue
+		}
+
+		if first, ok := seen[row.Sensor.SerialNumber]; ok {
+			result.Status = domain.SensorImportStatusInvalid
+			result.Err = fmt.Errorf("%w: already in row %d", ErrDuplicateSerialNumber, first)
+			continue
+		}
+		seen[row.Sensor.SerialNumber] = result.Row
+
+		if row.OwnerID != 0 {
+			if s.userRepo == nil || s.sensorOwnerRepo == nil {
+				return nil, errors.New("sensor owners are not configured")
+			}
+
+			// ошибка поиска владельца относится к строке, а не ко всему импорту
+			user, err := s.userRepo.GetUserByID(ctx, row.OwnerID)
+			if err == nil && user == nil {
+				err = ErrUserNotFound
+			}
+			if err != nil {
+				result.Status, result.Err = domain.SensorImportStatusInvalid, err
+				continue
+			}
+		}
+
+		existing, err := s.sensorRepo.GetSensorBySerialNumber(ctx, row.Sensor.SerialNumber)
+		if err != nil && !errors.Is(err, ErrSensorNotFound) {
+			return nil, err
+		}
+		if existing != nil {
+			if err := s.access.CheckSensor(ctx, existing.ID); err != nil {
+				result.Status, result.Err = domain.SensorImportStatusInvalid, ErrSensorAlreadyExists
+				continue
+			}
+			// привязка существующего датчика к пользователю - это передача доступа, она разрешена только владельцу
+			if row.OwnerID != 0 {
+				if err := s.access.CheckSensorRole(ctx, existing.ID, domain.SensorRoleOwner); err != nil {
+					result.Status, result.Err = domain.SensorImportStatusInvalid, err
+					continue
+				}
+			}
+			result.Status = domain.SensorImportStatusExists
+			result.SensorID = existing.ID
+		}
+	}
+
+	return results, nil
+}
+
+// importRow сохраняет проверенную строку: новый датчик с секретом подписи и, если указан,
+// его привязку к пользователю. Если датчик зарегистрировали после проверки, строка завершается
+// с ErrSensorAlreadyExists, а чужой датчик не меняется.
+func (s *Sensor) importRow(ctx context.Context, row *domain.SensorImportRow, result *domain.SensorImportResult) error {
+	if result.Status != domain.SensorImportStatusExists {
+		sensor := row.Sensor
+		if err := s.sensorRepo.CreateSensor(ctx, &sensor); err != nil {
+			return err
+		}
+		result.Status = domain.SensorImportStatusCreated
+		result.SensorID = sensor.ID
+
+		if err := s.access.grantSensor(ctx, sensor.ID); err != nil {
+			return err
+		}
+		if err := s.audit.record(ctx, domain.AuditActionCreate, domain.AuditEntitySensor, sensor.ID, nil, &sensor); err != nil {
+			return err
+		}
+
+		if s.deviceAuth != nil {
+			secret, err := s.deviceAuth.ProvisionSecret(ctx, sensor.ID)
+			if err != nil {
+				return err
+			}
+			result.Secret = secret
+		}
+	}
+
+	if row.OwnerID == 0 {
+		return nil
+	}
+
+	err := s.sensorOwnerRepo.SaveSensorOwner(ctx, domain.SensorOwner{
+		UserID:   row.OwnerID,
+		SensorID: result.SensorID,
+		Role:     domain.SensorRoleOwner,
+	})
+	if err != nil {
+		return err
+	}
+	return s.audit.record(ctx, domain.AuditActionAttach, domain.AuditEntitySensor, result.SensorID,
+		nil, auditLink("user", row.OwnerID, domain.SensorRoleOwner))
+}
+
+// skipPending помечает корректные строки как пропущенные, когда импорт отклонен
+func skipPending(results []domain.SensorImportResult) {
+	for i := range results {
+		if results[i].Status != domain.SensorImportStatusInvalid {
+			results[i].Status = domain.SensorImportStatusSkipped
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_sensor_ImportSensors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rows := func() []domain.SensorImportRow {
+		return []domain.SensorImportRow{
+			{Sensor: domain.Sensor{SerialNumber: "0000000001", Type: domain.SensorTypeADC}},
+			{Sensor: domain.Sensor{SerialNumber: "0000000002", Type: domain.SensorTypeContactClosure}, OwnerID: 7},
+		}
+	}
+
+	t.Run("ok, dry run saves nothing", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000001").Times(1).Return(&domain.Sensor{ID: 3}, nil)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000002").Times(1).Return(nil, ErrSensorNotFound)
+		sr.EXPECT().CreateSensor(gomock.Any(), gomock.Any()).Times(0)
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(7)).Times(1).Return(&domain.User{ID: 7}, nil)
+
+		s := NewSensor(sr, WithSensorOwners(ur, NewMockSensorOwnerRepository(ctrl)))
+
+		results, err := s.ImportSensors(ctx, rows(), domain.SensorImportAtomic, true)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, domain.SensorImportStatusExists, results[0].Status)
+		assert.Equal(t, int64(3), results[0].SensorID)
+		assert.Equal(t, domain.SensorImportStatusValid, results[1].Status)
+	})
+
+	t.Run("fail, atomic import with invalid row", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000001").Times(1).Return(nil, ErrSensorNotFound)
+		sr.EXPECT().CreateSensor(gomock.Any(), gomock.Any()).Times(0)
+
+		s := NewSensor(sr)
+
+		results, err := s.ImportSensors(ctx, []domain.SensorImportRow{
+			{Sensor: domain.Sensor{SerialNumber: "0000000001", Type: domain.SensorTypeADC}},
+			{Sensor: domain.Sensor{SerialNumber: "0000000001", Type: domain.SensorTypeADC}},
+			{Sensor: domain.Sensor{SerialNumber: "123", Type: domain.SensorTypeADC}},
+		}, domain.SensorImportAtomic, false)
+		assert.ErrorIs(t, err, ErrImportRejected)
+		require.Len(t, results, 3)
+		assert.Equal(t, domain.SensorImportStatusSkipped, results[0].Status)
+		assert.ErrorIs(t, results[1].Err, ErrDuplicateSerialNumber)
+		assert.ErrorIs(t, results[2].Err, ErrWrongSensorSerialNumber)
+	})
+
+	t.Run("fail, owner not found", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000001").Times(1).Return(nil, ErrSensorNotFound)
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(7)).Times(1).Return(nil, nil)
+
+		s := NewSensor(sr, WithSensorOwners(ur, NewMockSensorOwnerRepository(ctrl)))
+
+		results, err := s.ImportSensors(ctx, rows(), domain.SensorImportAtomic, false)
+		assert.ErrorIs(t, err, ErrImportRejected)
+		assert.ErrorIs(t, results[1].Err, ErrUserNotFound)
+	})
+
+	t.Run("fail, atomic import save error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		expectedError := errors.New("some error")
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, gomock.Any()).Times(2).Return(nil, ErrSensorNotFound)
+		sr.EXPECT().CreateSensor(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, sensor *domain.Sensor) {
+			sensor.ID = 1
+		})
+		sr.EXPECT().CreateSensor(ctx, gomock.Any()).Times(1).Return(expectedError)
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(7)).Times(1).Return(&domain.User{ID: 7}, nil)
+
+		s := NewSensor(sr, WithSensorOwners(ur, NewMockSensorOwnerRepository(ctrl)))
+
+		results, err := s.ImportSensors(ctx, rows(), domain.SensorImportAtomic, false)
+		assert.ErrorIs(t, err, ErrImportRejected)
+		assert.ErrorIs(t, err, expectedError)
+		assert.Equal(t, domain.SensorImportStatusSkipped, results[0].Status)
+		assert.Zero(t, results[0].SensorID)
+		assert.Equal(t, domain.SensorImportStatusFailed, results[1].Status)
+	})
+
+	t.Run("ok, best effort saves valid rows", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, gomock.Any()).Times(2).Return(nil, ErrSensorNotFound)
+		sr.EXPECT().CreateSensor(ctx, gomock.Any()).Times(2).DoAndReturn(func(_ context.Context, sensor *domain.Sensor) error {
+			sensor.ID = 10
+			return nil
+		})
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(7)).Times(1).Return(&domain.User{ID: 7}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().SaveSensorOwner(ctx, domain.SensorOwner{UserID: 7, SensorID: 10, Role: domain.SensorRoleOwner}).Times(1).Return(nil)
+
+		s := NewSensor(sr, WithSensorOwners(ur, sor))
+
+		importRows := append(rows(), domain.SensorImportRow{Sensor: domain.Sensor{SerialNumber: "0000000003", Type: "some"}})
+		results, err := s.ImportSensors(ctx, importRows, domain.SensorImportBestEffort, false)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.Equal(t, domain.SensorImportStatusCreated, results[0].Status)
+		assert.Equal(t, domain.SensorImportStatusCreated, results[1].Status)
+		assert.Equal(t, domain.SensorImportStatusInvalid, results[2].Status)
+		assert.ErrorIs(t, results[2].Err, ErrWrongSensorType)
+	})
+
+	t.Run("fail, sensor registered after validation is not taken over", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, gomock.Any()).Times(2).Return(nil, ErrSensorNotFound)
+		sr.EXPECT().CreateSensor(ctx, gomock.Any()).Times(1).Return(nil)
+		sr.EXPECT().CreateSensor(ctx, gomock.Any()).Times(1).Return(ErrSensorAlreadyExists)
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(7)).Times(1).Return(&domain.User{ID: 7}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().SaveSensorOwner(gomock.Any(), gomock.Any()).Times(0)
+
+		s := NewSensor(sr, WithSensorOwners(ur, sor))
+
+		results, err := s.ImportSensors(ctx, rows(), domain.SensorImportBestEffort, false)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, domain.SensorImportStatusCreated, results[0].Status)
+		assert.Equal(t, domain.SensorImportStatusFailed, results[1].Status)
+		assert.ErrorIs(t, results[1].Err, ErrSensorAlreadyExists)
+	})
+
+	t.Run("fail, secret provisioning error rejects atomic import", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		expectedError := errors.New("some error")
+
+		var lastID int64
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, gomock.Any()).Times(2).Return(nil, ErrSensorNotFound)
+		sr.EXPECT().CreateSensor(ctx, gomock.Any()).Times(2).Do(func(_ context.Context, sensor *domain.Sensor) {
+			lastID++
+			sensor.ID = lastID
+		})
+		sr.EXPECT().GetSensorByID(ctx, gomock.Any()).Times(2).DoAndReturn(func(_ context.Context, id int64) (*domain.Sensor, error) {
+			return &domain.Sensor{ID: id}, nil
+		})
+
+		cr := NewMockSensorCredentialRepository(ctrl)
+		cr.EXPECT().CreateSensorCredential(ctx, gomock.Any()).Times(1).Return(nil)
+		cr.EXPECT().CreateSensorCredential(ctx, gomock.Any()).Times(1).Return(expectedError)
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(7)).Times(1).Return(&domain.User{ID: 7}, nil)
+
+		s := NewSensor(sr, WithSensorOwners(ur, NewMockSensorOwnerRepository(ctrl)),
+			WithSensorDeviceAuth(NewDeviceAuth(cr, nil, sr)))
+
+		results, err := s.ImportSensors(ctx, rows(), domain.SensorImportAtomic, false)
+		assert.ErrorIs(t, err, ErrImportRejected)
+		assert.ErrorIs(t, err, expectedError)
+		for _, result := range results {
+			assert.Empty(t, result.Secret)
+		}
+		assert.Equal(t, domain.SensorImportStatusSkipped, results[0].Status)
+		assert.Equal(t, domain.SensorImportStatusFailed, results[1].Status)
+	})
+
+	t.Run("ok, created sensors get secrets", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, gomock.Any()).Times(1).Return(nil, ErrSensorNotFound)
+		sr.EXPECT().CreateSensor(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, sensor *domain.Sensor) {
+			sensor.ID = 10
+		})
+		sr.EXPECT().GetSensorByID(ctx, int64(10)).Times(1).Return(&domain.Sensor{ID: 10}, nil)
+
+		cr := NewMockSensorCredentialRepository(ctrl)
+		cr.EXPECT().CreateSensorCredential(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, credential *domain.SensorCredential) {
+			assert.Equal(t, int64(10), credential.SensorID)
+		})
+
+		s := NewSensor(sr, WithSensorDeviceAuth(NewDeviceAuth(cr, nil, sr)))
+
+		results, err := s.ImportSensors(ctx, rows()[:1], domain.SensorImportAtomic, false)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, domain.SensorImportStatusCreated, results[0].Status)
+		assert.NotEmpty(t, results[0].Secret)
+	})
+}
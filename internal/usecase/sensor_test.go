@@ -4,11 +4,15 @@ import (
 	"context"
 	"errors"
 	"homework/internal/domain"
+	"homework/internal/serial"
+	"math"
+	"regexp"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_sensor_RegisterSensor(t *testing.T) {
@@ -41,6 +45,15 @@ func Test_sensor_RegisterSensor(t *testing.T) {
 			SerialNumber: "123456789011", // wrong, should be 10 digits
 		})
 		assert.ErrorIs(t, err, ErrWrongSensorSerialNumber)
+
+		for _, labels := range [][]string{{""}, {" hall"}, {"hall", "hall"}, make([]string, domain.MaxSensorLabels+1)} {
+			_, err = s.RegisterSensor(ctx, &domain.Sensor{
+				Type:         domain.SensorTypeADC,
+				SerialNumber: "1234567890",
+				Labels:       labels,
+			})
+			assert.ErrorIs(t, err, ErrInvalidSensorLabels, labels)
+		}
 	})
 
 	t.Run("fail, repository return an error", func(t *testing.T) {
@@ -253,3 +266,194 @@ func Test_sensor_GetSensorByID(t *testing.T) {
 		assert.NotNil(t, sensor)
 	})
 }
+
+func Test_sensor_SetSensorCalibration(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, contact closure sensor", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1, Type: domain.SensorTypeContactClosure}, nil)
+
+		s := NewSensor(sr)
+
+		_, err := s.SetSensorCalibration(ctx, 1, &domain.Calibration{Scale: 1})
+		assert.ErrorIs(t, err, ErrInvalidCalibration)
+	})
+
+	t.Run("fail, table is not sorted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1, Type: domain.SensorTypeADC}, nil)
+
+		s := NewSensor(sr)
+
+		_, err := s.SetSensorCalibration(ctx, 1, &domain.Calibration{Scale: 1, Table: []domain.CalibrationPoint{
+			{Raw: 10, Value: 1},
+			{Raw: 5, Value: 2},
+		}})
+		assert.ErrorIs(t, err, ErrInvalidCalibration)
+	})
+
+	t.Run("fail, zero scale", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1, Type: domain.SensorTypeADC}, nil)
+
+		s := NewSensor(sr)
+
+		_, err := s.SetSensorCalibration(ctx, 1, &domain.Calibration{})
+		assert.ErrorIs(t, err, ErrInvalidCalibration)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1, Type: domain.SensorTypeADC}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, sensor *domain.Sensor) {
+			assert.Equal(t, "V", sensor.Calibration.Unit)
+		})
+
+		s := NewSensor(sr)
+
+		sensor, err := s.SetSensorCalibration(ctx, 1, &domain.Calibration{Scale: 0.01, Unit: "V"})
+		assert.NoError(t, err)
+		assert.NotNil(t, sensor.Calibration)
+	})
+}
+
+func Test_sensor_SetSensorAnomalyDetection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, invalid settings", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).AnyTimes().Return(&domain.Sensor{ID: 1, Type: domain.SensorTypeADC}, nil)
+		sr.EXPECT().GetSensorByID(ctx, int64(2)).AnyTimes().Return(&domain.Sensor{ID: 2, Type: domain.SensorTypeContactClosure}, nil)
+
+		s := NewSensor(sr)
+
+		_, err := s.SetSensorAnomalyDetection(ctx, 2, &domain.AnomalyDetection{Alpha: 0.1, Sigma: 3, WarmUp: 10})
+		assert.ErrorIs(t, err, ErrInvalidAnomalyDetection, "contact closure sensor")
+
+		for _, detection := range []*domain.AnomalyDetection{
+			{Alpha: 0, Sigma: 3, WarmUp: 10},
+			{Alpha: 1, Sigma: 3, WarmUp: 10},
+			{Alpha: math.NaN(), Sigma: 3, WarmUp: 10},
+			{Alpha: 0.1, Sigma: 0, WarmUp: 10},
+			{Alpha: 0.1, Sigma: math.Inf(1), WarmUp: 10},
+			{Alpha: 0.1, Sigma: 3, WarmUp: 1},
+		} {
+			_, err := s.SetSensorAnomalyDetection(ctx, 1, detection)
+			assert.ErrorIs(t, err, ErrInvalidAnomalyDetection, detection)
+		}
+	})
+
+	t.Run("ok, disabling forgets statistics and resolves alert", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{
+			ID: 1, Type: domain.SensorTypeADC, AnomalyDetection: &domain.AnomalyDetection{Alpha: 0.1, Sigma: 3, WarmUp: 10},
+		}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, sensor *domain.Sensor) {
+			assert.Nil(t, sensor.AnomalyDetection)
+		})
+		asr := NewMockAnomalyStateRepository(ctrl)
+		asr.EXPECT().DeleteAnomalyState(ctx, int64(1)).Times(1).Return(nil)
+		ar := NewMockAlertRepository(ctrl)
+		ar.EXPECT().GetActiveAnomalyAlert(ctx, int64(1)).Times(1).
+			Return(&domain.Alert{ID: 5, Anomaly: true, SensorID: 1, Status: domain.AlertStatusOpen}, nil)
+		ar.EXPECT().UpdateAlertStatus(ctx, gomock.Any()).Times(1).Return(nil)
+
+		s := NewSensor(sr, WithSensorAnomalies(NewAnomaly(asr, NewAlert(ar))))
+
+		sensor, err := s.SetSensorAnomalyDetection(ctx, 1, nil)
+		require.NoError(t, err)
+		assert.Nil(t, sensor.AnomalyDetection)
+	})
+}
+
+func Test_sensor_SetSensorDebounce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, invalid settings", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).AnyTimes().Return(&domain.Sensor{ID: 1, Type: domain.SensorTypeContactClosure}, nil)
+		sr.EXPECT().GetSensorByID(ctx, int64(2)).AnyTimes().Return(&domain.Sensor{ID: 2, Type: domain.SensorTypeADC}, nil)
+
+		s := NewSensor(sr)
+
+		_, err := s.SetSensorDebounce(ctx, 2, &domain.Debounce{Window: time.Millisecond})
+		assert.ErrorIs(t, err, ErrInvalidDebounce, "adc sensor")
+
+		for _, debounce := range []*domain.Debounce{
+			{},
+			{Window: -time.Millisecond},
+			{Window: 2 * time.Minute},
+			{Window: time.Millisecond, FlapWindow: time.Second},
+			{FlapThreshold: 5},
+			{FlapThreshold: -1, FlapWindow: time.Second},
+			{FlapThreshold: 5, FlapWindow: 48 * time.Hour},
+		} {
+			_, err := s.SetSensorDebounce(ctx, 1, debounce)
+			assert.ErrorIs(t, err, ErrInvalidDebounce, debounce)
+		}
+	})
+
+	t.Run("ok, settings change resets flapping", func(t *testing.T) {
+		ctx := context.Background()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{
+			ID: 1, Type: domain.SensorTypeContactClosure, Flapping: true,
+			Debounce: &domain.Debounce{FlapThreshold: 5, FlapWindow: time.Minute},
+		}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, sensor *domain.Sensor) {
+			assert.False(t, sensor.Flapping)
+			assert.Equal(t, 20*time.Millisecond, sensor.Debounce.Window)
+		})
+
+		s := NewSensor(sr, WithSensorDebounce(NewDebounce()))
+
+		sensor, err := s.SetSensorDebounce(ctx, 1, &domain.Debounce{Window: 20 * time.Millisecond, FlapThreshold: 10, FlapWindow: time.Minute})
+		require.NoError(t, err)
+		assert.False(t, sensor.Flapping)
+	})
+}
+
+func Test_sensor_ValidateSerialNumber(t *testing.T) {
+	registry := serial.Default()
+	assert.NoError(t, registry.Register(serial.Scheme{
+		Name:     "acme",
+		Prefix:   "AX",
+		Pattern:  regexp.MustCompile(`^AX[0-9A-Z]{8}$`),
+		Checksum: serial.LuhnMod36,
+	}))
+
+	s := NewSensor(nil, WithSensorSerialValidator(registry))
+
+	assert.NoError(t, s.ValidateSerialNumber("0123456789"))
+	assert.NoError(t, s.ValidateSerialNumber("AX1B2C3D4Z"))
+
+	err := s.ValidateSerialNumber("AX1B2C3D4E")
+	assert.ErrorIs(t, err, ErrWrongSensorSerialNumber)
+	assert.ErrorIs(t, err, serial.ErrInvalidChecksum)
+
+	_, err = s.RegisterSensor(context.Background(), &domain.Sensor{SerialNumber: "AX00000000", Type: domain.SensorTypeADC})
+	assert.ErrorIs(t, err, ErrWrongSensorSerialNumber)
+}
@@ -4,22 +4,109 @@ import (
 	"context"
 	"errors"
 	"homework/internal/domain"
+	"time"
 )
 
 var (
-	ErrWrongSensorSerialNumber = errors.New("wrong sensor serial number")
-	ErrWrongSensorType         = errors.New("wrong sensor type")
-	ErrInvalidEventTimestamp   = errors.New("invalid event timestamp")
-	ErrInvalidUserName         = errors.New("invalid user name")
-	ErrSensorNotFound          = errors.New("sensor not found")
-	ErrUserNotFound            = errors.New("user not found")
-	ErrEventNotFound           = errors.New("event not found")
+	ErrWrongSensorSerialNumber  = errors.New("wrong sensor serial number")
+	ErrWrongSensorType          = errors.New("wrong sensor type")
+	ErrInvalidEventTimestamp    = errors.New("invalid event timestamp")
+	ErrInvalidUserName          = errors.New("invalid user name")
+	ErrSensorNotFound           = errors.New("sensor not found")
+	ErrUserNotFound             = errors.New("user not found")
+	ErrEventNotFound            = errors.New("event not found")
+	ErrInvalidHomeName          = errors.New("invalid home name")
+	ErrInvalidHomeLocation      = errors.New("invalid home time zone or coordinates")
+	ErrInvalidRoomName          = errors.New("invalid room name")
+	ErrHomeNotFound             = errors.New("home not found")
+	ErrRoomNotFound             = errors.New("room not found")
+	ErrInvalidCalibration       = errors.New("invalid calibration")
+	ErrInvalidAnomalyDetection  = errors.New("invalid anomaly detection")
+	ErrInvalidDebounce          = errors.New("invalid debounce")
+	ErrInvalidVirtualSensor     = errors.New("invalid virtual sensor")
+	ErrInvalidSensorLabels      = errors.New("invalid sensor labels")
+	ErrVirtualSensorEvent       = errors.New("events of virtual sensor are computed")
+	ErrDuplicateSerialNumber    = errors.New("duplicate serial number")
+	ErrImportRejected           = errors.New("import rejected")
+	ErrInvalidDeviceInfo        = errors.New("invalid device info")
+	ErrInvalidTokenName         = errors.New("invalid token name")
+	ErrInvalidTokenTTL          = errors.New("invalid token ttl")
+	ErrTokenNotFound            = errors.New("token not found")
+	ErrUnauthenticated          = errors.New("unauthenticated")
+	ErrSensorAlreadyExists      = errors.New("sensor already registered")
+	ErrSensorAlreadyProvisioned = errors.New("sensor already provisioned")
+	ErrCredentialNotFound       = errors.New("sensor credential not found")
+	ErrInvalidSignature         = errors.New("invalid event signature")
+	ErrReplayedEvent            = errors.New("event nonce already used")
+	ErrInvalidSensorRole        = errors.New("invalid sensor role")
+	ErrInsufficientRole         = errors.New("insufficient sensor role")
+	ErrInvalidHomeRole          = errors.New("invalid home role")
+	ErrInsufficientHomeRole     = errors.New("insufficient home role")
+	ErrSensorOwnerNotFound      = errors.New("sensor owner not found")
+	ErrLastSensorOwner          = errors.New("sensor must keep at least one owner")
+	ErrUserNameTaken            = errors.New("user name already taken")
+	ErrInvalidPagination        = errors.New("invalid pagination")
+	ErrGroupNotFound            = errors.New("group not found")
+	ErrInvalidGroupName         = errors.New("invalid group name")
+	ErrGroupMemberNotFound      = errors.New("group member not found")
+	ErrGroupSensorNotFound      = errors.New("group sensor not found")
+	ErrInvalidInvitation        = errors.New("invalid invitation")
+	ErrInvitationNotFound       = errors.New("invitation not found")
+	ErrInvitationExpired        = errors.New("invitation expired or used up")
+	ErrInvalidAuditFilter       = errors.New("invalid audit filter")
+	ErrRateLimited              = errors.New("rate limit exceeded")
+	ErrInvalidRule              = errors.New("invalid rule")
+	ErrRuleNotFound             = errors.New("rule not found")
+	ErrAlertNotFound            = errors.New("alert not found")
+	ErrAlertResolved            = errors.New("alert already resolved")
+	ErrInvalidAlertFilter       = errors.New("invalid alert filter")
+	ErrInvalidWebhook           = errors.New("invalid webhook")
+	ErrWebhookNotFound          = errors.New("webhook not found")
+	ErrInvalidDeliveryFilter    = errors.New("invalid webhook delivery filter")
+	ErrWebhookAddressForbidden  = errors.New("webhook address is not public")
+	ErrInvalidActuator          = errors.New("invalid actuator")
+	ErrActuatorNotFound         = errors.New("actuator not found")
+	ErrInvalidCommand           = errors.New("invalid command")
+	ErrCommandNotFound          = errors.New("command not found")
+	ErrCommandCompleted         = errors.New("command already completed")
+	ErrCommandExpired           = errors.New("command expired")
+	ErrInvalidCommandFilter     = errors.New("invalid command filter")
+	ErrInvalidAutomation        = errors.New("invalid automation")
+	ErrAutomationNotFound       = errors.New("automation not found")
+	ErrInvalidScene             = errors.New("invalid scene")
+	ErrSceneNotFound            = errors.New("scene not found")
+	ErrInvalidRunFilter         = errors.New("invalid automation run filter")
+	ErrInvalidSchedule          = errors.New("invalid schedule")
+	ErrScheduleNotFound         = errors.New("schedule not found")
 )
 
+// SerialNumberValidator - проверка серийного номера датчика по схеме его производителя
+type SerialNumberValidator interface {
+	Validate(sn string) error
+}
+
+// Transactor - выполнение функции в транзакции.
+// Репозитории, вызванные с контекстом, переданным в fn, работают в рамках этой транзакции.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// noTransaction - Transactor по умолчанию, выполняет функцию без транзакции
+type noTransaction struct{}
+
+func (noTransaction) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 //go:generate mockgen -source usecase.go -package usecase -destination usecase_mock.go
 type SensorRepository interface {
 	// SaveSensor - функция сохранения датчика
 	SaveSensor(ctx context.Context, sensor *domain.Sensor) error
+	// CreateSensor - функция сохранения нового датчика, возвращает ErrSensorAlreadyExists, если серийный номер занят
+	CreateSensor(ctx context.Context, sensor *domain.Sensor) error
+	// UpdateSensorState - функция сохранения состояния датчика по событиям: значения, времени активности,
+	// нестабильности и сведений об устройстве. Настройки датчика не меняются. Если датчика нет, возвращает ErrSensorNotFound.
+	UpdateSensorState(ctx context.Context, sensor *domain.Sensor) error
 	// GetSensors - функция получения списка датчиков
 	GetSensors(ctx context.Context) ([]domain.Sensor, error)
 	// GetSensorByID - функция получения датчика по ID
@@ -36,15 +123,333 @@ type EventRepository interface {
 }
 
 type UserRepository interface {
-	// SaveUser - функция сохранения пользователя
+	// SaveUser - функция сохранения нового или изменения существующего пользователя,
+	// возвращает ErrUserNameTaken, если имя без учета регистра занято другим пользователем
 	SaveUser(ctx context.Context, user *domain.User) error
 	// GetUserByID - функция получения пользователя по id
 	GetUserByID(ctx context.Context, id int64) (*domain.User, error)
+	// GetUserByName - функция получения пользователя по имени без учета регистра, возвращает ErrUserNotFound
+	GetUserByName(ctx context.Context, name string) (*domain.User, error)
+	// GetUsers - функция получения страницы списка пользователей, упорядоченного по id
+	GetUsers(ctx context.Context, limit, offset int) ([]domain.User, error)
+	// DeleteUser - функция удаления пользователя
+	DeleteUser(ctx context.Context, id int64) error
 }
 
 type SensorOwnerRepository interface {
-	// SaveSensorOwner - функция привязки датчика к пользователю
+	// SaveSensorOwner - функция привязки датчика к пользователю, для существующей привязки меняет роль
 	SaveSensorOwner(ctx context.Context, sensorOwner domain.SensorOwner) error
 	// GetSensorsByUserID -функция, возвращающая список привязок для пользователя
 	GetSensorsByUserID(ctx context.Context, userID int64) ([]domain.SensorOwner, error)
+	// GetUsersBySensorID - функция, возвращающая список привязок пользователей к датчику
+	GetUsersBySensorID(ctx context.Context, sensorID int64) ([]domain.SensorOwner, error)
+	// DeleteSensorOwner - функция удаления привязки, возвращает ErrSensorOwnerNotFound, если привязки нет
+	DeleteSensorOwner(ctx context.Context, userID, sensorID int64) error
+}
+
+type HomeRepository interface {
+	// SaveHome - функция сохранения дома
+	SaveHome(ctx context.Context, home *domain.Home) error
+	// GetHomes - функция получения списка домов
+	GetHomes(ctx context.Context) ([]domain.Home, error)
+	// GetHomeByID - функция получения дома по id
+	GetHomeByID(ctx context.Context, id int64) (*domain.Home, error)
+	// DeleteHome - функция удаления дома
+	DeleteHome(ctx context.Context, id int64) error
+}
+
+type RoomRepository interface {
+	// SaveRoom - функция сохранения комнаты
+	SaveRoom(ctx context.Context, room *domain.Room) error
+	// GetRoomByID - функция получения комнаты по id
+	GetRoomByID(ctx context.Context, id int64) (*domain.Room, error)
+	// GetRoomsByHomeID - функция получения списка комнат дома
+	GetRoomsByHomeID(ctx context.Context, homeID int64) ([]domain.Room, error)
+	// DeleteRoom - функция удаления комнаты вместе с привязками датчиков к ней
+	DeleteRoom(ctx context.Context, id int64) error
+	// SaveSensorRoom - функция размещения датчика в комнате, предыдущее размещение датчика заменяется
+	SaveSensorRoom(ctx context.Context, sensorRoom domain.SensorRoom) error
+	// GetSensorsByRoomID - функция, возвращающая список привязок датчиков к комнате
+	GetSensorsByRoomID(ctx context.Context, roomID int64) ([]domain.SensorRoom, error)
+}
+
+type HomeOwnerRepository interface {
+	// SaveHomeOwner - функция привязки дома к пользователю
+	SaveHomeOwner(ctx context.Context, homeOwner domain.HomeOwner) error
+	// GetHomesByUserID - функция, возвращающая список привязок домов для пользователя
+	GetHomesByUserID(ctx context.Context, userID int64) ([]domain.HomeOwner, error)
+	// DeleteHomeOwnersByHomeID - функция удаления всех привязок пользователей к дому
+	DeleteHomeOwnersByHomeID(ctx context.Context, homeID int64) error
+	// DeleteHomeOwnersByUserID - функция удаления всех привязок домов к пользователю
+	DeleteHomeOwnersByUserID(ctx context.Context, userID int64) error
+}
+
+type GroupRepository interface {
+	// SaveGroup - функция сохранения новой или изменения существующей группы
+	SaveGroup(ctx context.Context, group *domain.Group) error
+	// GetGroups - функция получения списка групп
+	GetGroups(ctx context.Context) ([]domain.Group, error)
+	// GetGroupByID - функция получения группы по id
+	GetGroupByID(ctx context.Context, id int64) (*domain.Group, error)
+	// DeleteGroup - функция удаления группы вместе с ее участниками и привязками датчиков
+	DeleteGroup(ctx context.Context, id int64) error
+	// SaveGroupMember - функция добавления пользователя в группу
+	SaveGroupMember(ctx context.Context, member domain.GroupMember) error
+	// DeleteGroupMember - функция исключения пользователя из группы, возвращает ErrGroupMemberNotFound, если его там нет
+	DeleteGroupMember(ctx context.Context, groupID, userID int64) error
+	// GetMembersByGroupID - функция, возвращающая список участников группы, упорядоченный по id пользователя
+	GetMembersByGroupID(ctx context.Context, groupID int64) ([]domain.GroupMember, error)
+	// GetGroupsByUserID - функция, возвращающая список членств пользователя в группах
+	GetGroupsByUserID(ctx context.Context, userID int64) ([]domain.GroupMember, error)
+	// SaveGroupSensor - функция привязки датчика к группе, для уже привязанного датчика меняет роль
+	SaveGroupSensor(ctx context.Context, groupSensor domain.GroupSensor) error
+	// DeleteGroupSensor - функция отвязки датчика от группы, возвращает ErrGroupSensorNotFound, если привязки нет
+	DeleteGroupSensor(ctx context.Context, groupID, sensorID int64) error
+	// GetSensorsByGroupID - функция, возвращающая список привязок датчиков к группе, упорядоченный по id датчика
+	GetSensorsByGroupID(ctx context.Context, groupID int64) ([]domain.GroupSensor, error)
+}
+
+type FirmwareHistoryRepository interface {
+	// SaveFirmwareChange - функция сохранения записи о смене прошивки
+	SaveFirmwareChange(ctx context.Context, change *domain.FirmwareChange) error
+	// GetFirmwareChangesBySensorID - функция получения истории прошивок датчика от старых записей к новым
+	GetFirmwareChangesBySensorID(ctx context.Context, sensorID int64) ([]domain.FirmwareChange, error)
+}
+
+type APITokenRepository interface {
+	// SaveAPIToken - функция сохранения нового токена
+	SaveAPIToken(ctx context.Context, token *domain.APIToken) error
+	// GetAPITokenByHash - функция получения токена по хэшу
+	GetAPITokenByHash(ctx context.Context, hash string) (*domain.APIToken, error)
+	// GetAPITokensByUserID - функция получения всех токенов пользователя, включая отозванные
+	GetAPITokensByUserID(ctx context.Context, userID int64) ([]domain.APIToken, error)
+	// RevokeAPIToken - функция отзыва токена
+	RevokeAPIToken(ctx context.Context, id int64, revokedAt time.Time) error
+	// DeleteAPITokensByUserID - функция удаления всех токенов пользователя
+	DeleteAPITokensByUserID(ctx context.Context, userID int64) error
+}
+
+type SensorCredentialRepository interface {
+	// CreateSensorCredential - функция сохранения первого секрета датчика, возвращает ErrSensorAlreadyProvisioned, если секрет уже есть
+	CreateSensorCredential(ctx context.Context, credential *domain.SensorCredential) error
+	// SaveSensorCredential - функция сохранения секрета датчика после ротации
+	SaveSensorCredential(ctx context.Context, credential *domain.SensorCredential) error
+	// GetSensorCredential - функция получения секрета датчика, возвращает ErrCredentialNotFound, если секрета нет
+	GetSensorCredential(ctx context.Context, sensorID int64) (*domain.SensorCredential, error)
+}
+
+type SensorInvitationRepository interface {
+	// SaveSensorInvitation - функция сохранения нового приглашения
+	SaveSensorInvitation(ctx context.Context, invitation *domain.SensorInvitation) error
+	// GetSensorInvitationByHash - функция получения приглашения по хэшу токена, возвращает ErrInvitationNotFound, если его нет
+	GetSensorInvitationByHash(ctx context.Context, hash string) (*domain.SensorInvitation, error)
+	// GetSensorInvitationsBySensorID - функция получения всех приглашений к датчику, включая отозванные и истекшие
+	GetSensorInvitationsBySensorID(ctx context.Context, sensorID int64) ([]domain.SensorInvitation, error)
+	// UseSensorInvitation - функция, засчитывающая принятие приглашения, если в момент now его еще можно принять,
+	// иначе возвращает ErrInvitationExpired. Проверка и увеличение счетчика выполняются атомарно.
+	UseSensorInvitation(ctx context.Context, id int64, now time.Time) error
+	// RevokeSensorInvitation - функция отзыва приглашения
+	RevokeSensorInvitation(ctx context.Context, id int64, revokedAt time.Time) error
+}
+
+type RuleRepository interface {
+	// SaveRule - функция сохранения правила, правило с ID 0 добавляется
+	SaveRule(ctx context.Context, rule *domain.Rule) error
+	// GetRules - функция получения списка всех правил
+	GetRules(ctx context.Context) ([]domain.Rule, error)
+	// GetRuleByID - функция получения правила по ID
+	GetRuleByID(ctx context.Context, id int64) (*domain.Rule, error)
+	// GetRulesBySensorID - функция получения правил датчика
+	GetRulesBySensorID(ctx context.Context, sensorID int64) ([]domain.Rule, error)
+	// DeleteRule - функция удаления правила вместе с его состоянием
+	DeleteRule(ctx context.Context, id int64) error
+	// SaveRuleState - функция сохранения состояния правила, заменяет предыдущее
+	SaveRuleState(ctx context.Context, state domain.RuleState) error
+	// GetRuleState - функция получения состояния правила, nil - правило еще не вычислялось
+	GetRuleState(ctx context.Context, ruleID int64) (*domain.RuleState, error)
+	// GetRuleStates - функция получения состояний всех правил
+	GetRuleStates(ctx context.Context) ([]domain.RuleState, error)
+	// DeleteRuleState - функция сброса состояния правила
+	DeleteRuleState(ctx context.Context, ruleID int64) error
+}
+
+type AnomalyStateRepository interface {
+	// SaveAnomalyStates - функция сохранения статистики датчиков, заменяет предыдущую
+	SaveAnomalyStates(ctx context.Context, states []domain.AnomalyState) error
+	// GetAnomalyState - функция получения статистики датчика, nil - статистики еще нет
+	GetAnomalyState(ctx context.Context, sensorID int64) (*domain.AnomalyState, error)
+	// DeleteAnomalyState - функция удаления статистики датчика
+	DeleteAnomalyState(ctx context.Context, sensorID int64) error
+}
+
+type VirtualSensorRepository interface {
+	// SaveVirtualSensorInputs - функция замены входов вычисляемого датчика, пустой список удаляет их
+	SaveVirtualSensorInputs(ctx context.Context, sensorID int64, inputIDs []int64) error
+	// GetVirtualSensorIDsByInputID - функция получения id вычисляемых датчиков, зависящих от датчика, по возрастанию
+	GetVirtualSensorIDsByInputID(ctx context.Context, inputID int64) ([]int64, error)
+}
+
+type AlertRepository interface {
+	// SaveAlert - функция сохранения нового оповещения
+	SaveAlert(ctx context.Context, alert *domain.Alert) error
+	// AddAlertOccurrence - функция учета повторного срабатывания в оповещении: увеличивает число срабатываний
+	// и запоминает значение и время. Если оповещения нет, возвращает ErrAlertNotFound.
+	AddAlertOccurrence(ctx context.Context, id int64, value float64, at time.Time) error
+	// UpdateAlertStatus - функция изменения состояния оповещения вместе с временем и автором подтверждения и закрытия.
+	// Закрытое оповещение не меняется, для него возвращается ErrAlertResolved, для отсутствующего - ErrAlertNotFound.
+	UpdateAlertStatus(ctx context.Context, alert *domain.Alert) error
+	// GetAlertByID - функция получения оповещения, если его нет, возвращает ErrAlertNotFound
+	GetAlertByID(ctx context.Context, id int64) (*domain.Alert, error)
+	// GetActiveAlertByRuleID - функция получения незакрытого оповещения правила, nil - такого нет
+	GetActiveAlertByRuleID(ctx context.Context, ruleID int64) (*domain.Alert, error)
+	// GetActiveAlertByAutomationID - функция получения незакрытого оповещения автоматизации, nil - такого нет
+	GetActiveAlertByAutomationID(ctx context.Context, automationID int64) (*domain.Alert, error)
+	// GetActiveAnomalyAlert - функция получения незакрытого оповещения об аномалиях датчика, nil - такого нет
+	GetActiveAnomalyAlert(ctx context.Context, sensorID int64) (*domain.Alert, error)
+	// GetAlerts - функция получения страницы оповещений, подходящих под фильтр, от новых к старым
+	GetAlerts(ctx context.Context, filter domain.AlertFilter) ([]domain.Alert, error)
+}
+
+type WebhookRepository interface {
+	// SaveWebhook - функция сохранения подписки: с ID 0 создает новую, иначе заменяет существующую
+	// или возвращает ErrWebhookNotFound
+	SaveWebhook(ctx context.Context, webhook *domain.Webhook) error
+	// GetWebhooks - функция получения всех подписок
+	GetWebhooks(ctx context.Context) ([]domain.Webhook, error)
+	// GetWebhookByID - функция получения подписки, если ее нет, возвращает ErrWebhookNotFound
+	GetWebhookByID(ctx context.Context, id int64) (*domain.Webhook, error)
+	// DeleteWebhook - функция удаления подписки вместе с журналом ее отправок
+	DeleteWebhook(ctx context.Context, id int64) error
+	// SaveWebhookDelivery - функция сохранения отправки: с ID 0 создает новую, иначе заменяет существующую
+	SaveWebhookDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	// GetWebhookDeliveries - функция получения страницы журнала отправок, подходящих под фильтр, от новых к старым
+	GetWebhookDeliveries(ctx context.Context, filter domain.WebhookDeliveryFilter) ([]domain.WebhookDelivery, error)
+	// ClaimWebhookDeliveries - функция выбора до limit ожидающих отправок, время попытки которых наступило к now,
+	// от старых к новым. Следующая попытка выбранных отправок переносится на leaseUntil,
+	// чтобы их не выбрал одновременно другой экземпляр сервиса.
+	ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.WebhookDelivery, error)
+}
+
+type ActuatorRepository interface {
+	// SaveActuator - функция сохранения устройства: с ID 0 добавляет новое, иначе заменяет существующее
+	// или возвращает ErrActuatorNotFound
+	SaveActuator(ctx context.Context, actuator *domain.Actuator) error
+	// GetActuators - функция получения всех устройств
+	GetActuators(ctx context.Context) ([]domain.Actuator, error)
+	// GetActuatorByID - функция получения устройства, если его нет, возвращает ErrActuatorNotFound
+	GetActuatorByID(ctx context.Context, id int64) (*domain.Actuator, error)
+	// DeleteActuator - функция удаления устройства вместе с историей его команд
+	DeleteActuator(ctx context.Context, id int64) error
+	// SetActuatorState - функция сохранения подтвержденного состояния устройства
+	SetActuatorState(ctx context.Context, id int64, state int64) error
+}
+
+type CommandRepository interface {
+	// AddCommand - функция добавления команды
+	AddCommand(ctx context.Context, command *domain.Command) error
+	// UpdateCommandStatus - функция изменения состояния открытой команды: Status, Error, DeliveredAt и CompletedAt.
+	// Для завершенной команды возвращает ErrCommandCompleted, поэтому одновременные подтверждение и истечение
+	// команды не перезаписывают друг друга.
+	UpdateCommandStatus(ctx context.Context, command *domain.Command) error
+	// GetCommandByID - функция получения команды, если ее нет, возвращает ErrCommandNotFound
+	GetCommandByID(ctx context.Context, id int64) (*domain.Command, error)
+	// GetCommands - функция получения страницы истории команд, подходящих под фильтр, от новых к старым
+	GetCommands(ctx context.Context, filter domain.CommandFilter) ([]domain.Command, error)
+	// GetOpenCommandsBySensorID - функция получения открытых команд устройств датчика-контроллера, от старых к новым
+	GetOpenCommandsBySensorID(ctx context.Context, sensorID int64) ([]domain.Command, error)
+	// ExpireCommands - функция перевода открытых команд, время выполнения которых прошло к now, в CommandExpired,
+	// возвращает число таких команд
+	ExpireCommands(ctx context.Context, now time.Time) (int, error)
+}
+
+type AutomationRepository interface {
+	// SaveAutomation - функция сохранения автоматизации: с ID 0 добавляет новую, иначе заменяет существующую
+	// или возвращает ErrAutomationNotFound
+	SaveAutomation(ctx context.Context, automation *domain.Automation) error
+	// GetAutomations - функция получения всех автоматизаций
+	GetAutomations(ctx context.Context) ([]domain.Automation, error)
+	// GetAutomationsByTriggerSensorID - функция получения автоматизаций, которые запускаются событиями датчика
+	GetAutomationsByTriggerSensorID(ctx context.Context, sensorID int64) ([]domain.Automation, error)
+	// GetAutomationByID - функция получения автоматизации, если ее нет, возвращает ErrAutomationNotFound
+	GetAutomationByID(ctx context.Context, id int64) (*domain.Automation, error)
+	// DeleteAutomation - функция удаления автоматизации вместе с журналом ее запусков
+	DeleteAutomation(ctx context.Context, id int64) error
+	// SaveScene - функция сохранения сцены: с ID 0 добавляет новую, иначе заменяет существующую
+	// или возвращает ErrSceneNotFound
+	SaveScene(ctx context.Context, scene *domain.Scene) error
+	// GetScenes - функция получения всех сцен
+	GetScenes(ctx context.Context) ([]domain.Scene, error)
+	// GetSceneByID - функция получения сцены, если ее нет, возвращает ErrSceneNotFound
+	GetSceneByID(ctx context.Context, id int64) (*domain.Scene, error)
+	// DeleteScene - функция удаления сцены вместе с журналом ее запусков
+	DeleteScene(ctx context.Context, id int64) error
+	// AddAutomationRun - функция добавления записи журнала запусков
+	AddAutomationRun(ctx context.Context, run *domain.AutomationRun) error
+	// GetAutomationRuns - функция получения страницы журнала запусков, подходящих под фильтр, от новых к старым
+	GetAutomationRuns(ctx context.Context, filter domain.AutomationRunFilter) ([]domain.AutomationRun, error)
+}
+
+type ScheduleRepository interface {
+	// SaveSchedule - функция сохранения расписания: с ID 0 добавляет новое, иначе заменяет существующее
+	// или возвращает ErrScheduleNotFound
+	SaveSchedule(ctx context.Context, schedule *domain.Schedule) error
+	// GetSchedules - функция получения всех расписаний
+	GetSchedules(ctx context.Context) ([]domain.Schedule, error)
+	// GetScheduleByID - функция получения расписания, если его нет, возвращает ErrScheduleNotFound
+	GetScheduleByID(ctx context.Context, id int64) (*domain.Schedule, error)
+	// GetDueSchedules - функция получения включенных расписаний, время запуска которых наступило к now,
+	// от давно ожидающих к недавним
+	GetDueSchedules(ctx context.Context, now time.Time) ([]domain.Schedule, error)
+	// AdvanceSchedule - функция переноса запуска расписания: меняет время следующего запуска на next и время
+	// последнего на last, только если следующий запуск все еще назначен на scheduled. Возвращает false,
+	// если запуск уже перенесен, так запуск забирает только один экземпляр сервиса.
+	AdvanceSchedule(ctx context.Context, id int64, scheduled, next, last time.Time) (bool, error)
+	// DeleteSchedule - функция удаления расписания вместе с журналом его запусков
+	DeleteSchedule(ctx context.Context, id int64) error
+}
+
+// OutboxRepository - записи outbox, которые сохраняются в одной транзакции с изменением
+// и затем доставляются публикаторам (OutboxPublisher)
+type OutboxRepository interface {
+	// AddOutboxEntry - функция добавления записи, записи датчика доставляются в порядке добавления
+	AddOutboxEntry(ctx context.Context, entry *domain.OutboxEntry) error
+	// ClaimOutboxEntries - функция выбора до limit записей, время попытки которых наступило к now, от старых к новым.
+	// Запись выбирается, только если все более ранние записи ее датчика тоже выбраны, поэтому запись,
+	// которая ждет повторной попытки, задерживает следующие записи датчика. Следующая попытка выбранных записей
+	// переносится на leaseUntil, чтобы их не выбрал одновременно другой экземпляр сервиса.
+	ClaimOutboxEntries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.OutboxEntry, error)
+	// RetryOutboxEntry - функция сохранения неудачной попытки: числа попыток, времени следующей и причины неудачи
+	RetryOutboxEntry(ctx context.Context, entry *domain.OutboxEntry) error
+	// DeleteOutboxEntry - функция удаления доставленной записи
+	DeleteOutboxEntry(ctx context.Context, id int64) error
+}
+
+// OutboxPublisher - получатель записей outbox, например подписки или брокер сообщений.
+// Запись доставляется хотя бы один раз и может быть доставлена повторно, если сервис остановится
+// до ее удаления, поэтому публикация должна выдерживать повторы.
+type OutboxPublisher interface {
+	Publish(ctx context.Context, entry domain.OutboxEntry) error
+}
+
+type AuditRepository interface {
+	// SaveAuditEntry - функция добавления записи в журнал аудита, записи журнала не изменяются и не удаляются
+	SaveAuditEntry(ctx context.Context, entry *domain.AuditEntry) error
+	// GetAuditEntries - функция получения страницы записей журнала, подходящих под фильтр, от новых к старым
+	GetAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+type EventNonceRepository interface {
+	// SaveEventNonce - функция запоминания nonce подписанного события до expiresAt,
+	// возвращает ErrReplayedEvent, если такой nonce датчика уже запомнен
+	SaveEventNonce(ctx context.Context, sensorID int64, nonce string, expiresAt time.Time) error
+	// DeleteExpiredEventNonces - функция удаления nonce, срок которых истек к now, возвращает число удаленных
+	DeleteExpiredEventNonces(ctx context.Context, now time.Time) (int, error)
+}
+
+// RateLimitStore - состояние корзин токенов ограничителя частоты запросов.
+// Хранилище в памяти процесса годится для одного экземпляра сервера, несколько экземпляров должны делить общее.
+type RateLimitStore interface {
+	// TakeToken забирает токен из корзины key на момент now. Возвращает 0, если токен выдан,
+	// иначе время до появления следующего токена; в этом случае корзина не меняется.
+	TakeToken(ctx context.Context, key string, limit domain.RateLimit, now time.Time) (time.Duration, error)
 }
@@ -8,10 +8,85 @@ import (
 	context "context"
 	domain "homework/internal/domain"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
 
+// MockSerialNumberValidator is a mock of SerialNumberValidator interface.
+type MockSerialNumberValidator struct {
+	ctrl     *gomock.Controller
+	recorder *MockSerialNumberValidatorMockRecorder
+}
+
+// MockSerialNumberValidatorMockRecorder is the mock recorder for MockSerialNumberValidator.
+type MockSerialNumberValidatorMockRecorder struct {
+	mock *MockSerialNumberValidator
+}
+
+// NewMockSerialNumberValidator creates a new mock instance.
+func NewMockSerialNumberValidator(ctrl *gomock.Controller) *MockSerialNumberValidator {
+	mock := &MockSerialNumberValidator{ctrl: ctrl}
+	mock.recorder = &MockSerialNumberValidatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSerialNumberValidator) EXPECT() *MockSerialNumberValidatorMockRecorder {
+	return m.recorder
+}
+
+// Validate mocks base method.
+func (m *MockSerialNumberValidator) Validate(sn string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", sn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockSerialNumberValidatorMockRecorder) Validate(sn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockSerialNumberValidator)(nil).Validate), sn)
+}
+
+// MockTransactor is a mock of Transactor interface.
+type MockTransactor struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactorMockRecorder
+}
+
+// MockTransactorMockRecorder is the mock recorder for MockTransactor.
+type MockTransactorMockRecorder struct {
+	mock *MockTransactor
+}
+
+// NewMockTransactor creates a new mock instance.
+func NewMockTransactor(ctrl *gomock.Controller) *MockTransactor {
+	mock := &MockTransactor{ctrl: ctrl}
+	mock.recorder = &MockTransactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactor) EXPECT() *MockTransactorMockRecorder {
+	return m.recorder
+}
+
+// WithinTransaction mocks base method.
+func (m *MockTransactor) WithinTransaction(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTransaction", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTransaction indicates an expected call of WithinTransaction.
+func (mr *MockTransactorMockRecorder) WithinTransaction(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTransaction", reflect.TypeOf((*MockTransactor)(nil).WithinTransaction), ctx, fn)
+}
+
 // MockSensorRepository is a mock of SensorRepository interface.
 type MockSensorRepository struct {
 	ctrl     *gomock.Controller
@@ -35,6 +110,20 @@ func (m *MockSensorRepository) EXPECT() *MockSensorRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CreateSensor mocks base method.
+func (m *MockSensorRepository) CreateSensor(ctx context.Context, sensor *domain.Sensor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSensor", ctx, sensor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSensor indicates an expected call of CreateSensor.
+func (mr *MockSensorRepositoryMockRecorder) CreateSensor(ctx, sensor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSensor", reflect.TypeOf((*MockSensorRepository)(nil).CreateSensor), ctx, sensor)
+}
+
 // GetSensorByID mocks base method.
 func (m *MockSensorRepository) GetSensorByID(ctx context.Context, id int64) (*domain.Sensor, error) {
 	m.ctrl.T.Helper()
@@ -94,6 +183,20 @@ func (mr *MockSensorRepositoryMockRecorder) SaveSensor(ctx, sensor interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSensor", reflect.TypeOf((*MockSensorRepository)(nil).SaveSensor), ctx, sensor)
 }
 
+// UpdateSensorState mocks base method.
+func (m *MockSensorRepository) UpdateSensorState(ctx context.Context, sensor *domain.Sensor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSensorState", ctx, sensor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSensorState indicates an expected call of UpdateSensorState.
+func (mr *MockSensorRepositoryMockRecorder) UpdateSensorState(ctx, sensor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSensorState", reflect.TypeOf((*MockSensorRepository)(nil).UpdateSensorState), ctx, sensor)
+}
+
 // MockEventRepository is a mock of EventRepository interface.
 type MockEventRepository struct {
 	ctrl     *gomock.Controller
@@ -169,6 +272,20 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// DeleteUser mocks base method.
+func (m *MockUserRepository) DeleteUser(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockUserRepositoryMockRecorder) DeleteUser(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserRepository)(nil).DeleteUser), ctx, id)
+}
+
 // GetUserByID mocks base method.
 func (m *MockUserRepository) GetUserByID(ctx context.Context, id int64) (*domain.User, error) {
 	m.ctrl.T.Helper()
@@ -184,6 +301,36 @@ func (mr *MockUserRepositoryMockRecorder) GetUserByID(ctx, id interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserRepository)(nil).GetUserByID), ctx, id)
 }
 
+// GetUserByName mocks base method.
+func (m *MockUserRepository) GetUserByName(ctx context.Context, name string) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByName", ctx, name)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByName indicates an expected call of GetUserByName.
+func (mr *MockUserRepositoryMockRecorder) GetUserByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByName", reflect.TypeOf((*MockUserRepository)(nil).GetUserByName), ctx, name)
+}
+
+// GetUsers mocks base method.
+func (m *MockUserRepository) GetUsers(ctx context.Context, limit, offset int) ([]domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", ctx, limit, offset)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockUserRepositoryMockRecorder) GetUsers(ctx, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUserRepository)(nil).GetUsers), ctx, limit, offset)
+}
+
 // SaveUser mocks base method.
 func (m *MockUserRepository) SaveUser(ctx context.Context, user *domain.User) error {
 	m.ctrl.T.Helper()
@@ -221,6 +368,20 @@ func (m *MockSensorOwnerRepository) EXPECT() *MockSensorOwnerRepositoryMockRecor
 	return m.recorder
 }
 
+// DeleteSensorOwner mocks base method.
+func (m *MockSensorOwnerRepository) DeleteSensorOwner(ctx context.Context, userID, sensorID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSensorOwner", ctx, userID, sensorID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSensorOwner indicates an expected call of DeleteSensorOwner.
+func (mr *MockSensorOwnerRepositoryMockRecorder) DeleteSensorOwner(ctx, userID, sensorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSensorOwner", reflect.TypeOf((*MockSensorOwnerRepository)(nil).DeleteSensorOwner), ctx, userID, sensorID)
+}
+
 // GetSensorsByUserID mocks base method.
 func (m *MockSensorOwnerRepository) GetSensorsByUserID(ctx context.Context, userID int64) ([]domain.SensorOwner, error) {
 	m.ctrl.T.Helper()
@@ -236,6 +397,21 @@ func (mr *MockSensorOwnerRepositoryMockRecorder) GetSensorsByUserID(ctx, userID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorsByUserID", reflect.TypeOf((*MockSensorOwnerRepository)(nil).GetSensorsByUserID), ctx, userID)
 }
 
+// GetUsersBySensorID mocks base method.
+func (m *MockSensorOwnerRepository) GetUsersBySensorID(ctx context.Context, sensorID int64) ([]domain.SensorOwner, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersBySensorID", ctx, sensorID)
+	ret0, _ := ret[0].([]domain.SensorOwner)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersBySensorID indicates an expected call of GetUsersBySensorID.
+func (mr *MockSensorOwnerRepositoryMockRecorder) GetUsersBySensorID(ctx, sensorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersBySensorID", reflect.TypeOf((*MockSensorOwnerRepository)(nil).GetUsersBySensorID), ctx, sensorID)
+}
+
 // SaveSensorOwner mocks base method.
 func (m *MockSensorOwnerRepository) SaveSensorOwner(ctx context.Context, sensorOwner domain.SensorOwner) error {
 	m.ctrl.T.Helper()
@@ -249,3 +425,2060 @@ func (mr *MockSensorOwnerRepositoryMockRecorder) SaveSensorOwner(ctx, sensorOwne
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSensorOwner", reflect.TypeOf((*MockSensorOwnerRepository)(nil).SaveSensorOwner), ctx, sensorOwner)
 }
+
+// MockHomeRepository is a mock of HomeRepository interface.
+type MockHomeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHomeRepositoryMockRecorder
+}
+
+// MockHomeRepositoryMockRecorder is the mock recorder for MockHomeRepository.
+type MockHomeRepositoryMockRecorder struct {
+	mock *MockHomeRepository
+}
+
+// NewMockHomeRepository creates a new mock instance.
+func NewMockHomeRepository(ctrl *gomock.Controller) *MockHomeRepository {
+	mock := &MockHomeRepository{ctrl: ctrl}
+	mock.recorder = &MockHomeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHomeRepository) EXPECT() *MockHomeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteHome mocks base method.
+func (m *MockHomeRepository) DeleteHome(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHome", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteHome indicates an expected call of DeleteHome.
+func (mr *MockHomeRepositoryMockRecorder) DeleteHome(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHome", reflect.TypeOf((*MockHomeRepository)(nil).DeleteHome), ctx, id)
+}
+
+// GetHomeByID mocks base method.
+func (m *MockHomeRepository) GetHomeByID(ctx context.Context, id int64) (*domain.Home, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHomeByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Home)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHomeByID indicates an expected call of GetHomeByID.
+func (mr *MockHomeRepositoryMockRecorder) GetHomeByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHomeByID", reflect.TypeOf((*MockHomeRepository)(nil).GetHomeByID), ctx, id)
+}
+
+// GetHomes mocks base method.
+func (m *MockHomeRepository) GetHomes(ctx context.Context) ([]domain.Home, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHomes", ctx)
+	ret0, _ := ret[0].([]domain.Home)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHomes indicates an expected call of GetHomes.
+func (mr *MockHomeRepositoryMockRecorder) GetHomes(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHomes", reflect.TypeOf((*MockHomeRepository)(nil).GetHomes), ctx)
+}
+
+// SaveHome mocks base method.
+func (m *MockHomeRepository) SaveHome(ctx context.Context, home *domain.Home) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveHome", ctx, home)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveHome indicates an expected call of SaveHome.
+func (mr *MockHomeRepositoryMockRecorder) SaveHome(ctx, home interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveHome", reflect.TypeOf((*MockHomeRepository)(nil).SaveHome), ctx, home)
+}
+
+// MockRoomRepository is a mock of RoomRepository interface.
+type MockRoomRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoomRepositoryMockRecorder
+}
+
+// MockRoomRepositoryMockRecorder is the mock recorder for MockRoomRepository.
+type MockRoomRepositoryMockRecorder struct {
+	mock *MockRoomRepository
+}
+
+// NewMockRoomRepository creates a new mock instance.
+func NewMockRoomRepository(ctrl *gomock.Controller) *MockRoomRepository {
+	mock := &MockRoomRepository{ctrl: ctrl}
+	mock.recorder = &MockRoomRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRoomRepository) EXPECT() *MockRoomRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteRoom mocks base method.
+func (m *MockRoomRepository) DeleteRoom(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRoom", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRoom indicates an expected call of DeleteRoom.
+func (mr *MockRoomRepositoryMockRecorder) DeleteRoom(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoom", reflect.TypeOf((*MockRoomRepository)(nil).DeleteRoom), ctx, id)
+}
+
+// GetRoomByID mocks base method.
+func (m *MockRoomRepository) GetRoomByID(ctx context.Context, id int64) (*domain.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoomByID indicates an expected call of GetRoomByID.
+func (mr *MockRoomRepositoryMockRecorder) GetRoomByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomByID", reflect.TypeOf((*MockRoomRepository)(nil).GetRoomByID), ctx, id)
+}
+
+// GetRoomsByHomeID mocks base method.
+func (m *MockRoomRepository) GetRoomsByHomeID(ctx context.Context, homeID int64) ([]domain.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomsByHomeID", ctx, homeID)
+	ret0, _ := ret[0].([]domain.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoomsByHomeID indicates an expected call of GetRoomsByHomeID.
+func (mr *MockRoomRepositoryMockRecorder) GetRoomsByHomeID(ctx, homeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomsByHomeID", reflect.TypeOf((*MockRoomRepository)(nil).GetRoomsByHomeID), ctx, homeID)
+}
+
+// GetSensorsByRoomID mocks base method.
+func (m *MockRoomRepository) GetSensorsByRoomID(ctx context.Context, roomID int64) ([]domain.SensorRoom, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSensorsByRoomID", ctx, roomID)
+	ret0, _ := ret[0].([]domain.SensorRoom)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSensorsByRoomID indicates an expected call of GetSensorsByRoomID.
+func (mr *MockRoomRepositoryMockRecorder) GetSensorsByRoomID(ctx, roomID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorsByRoomID", reflect.TypeOf((*MockRoomRepository)(nil).GetSensorsByRoomID), ctx, roomID)
+}
+
+// SaveRoom mocks base method.
+func (m *MockRoomRepository) SaveRoom(ctx context.Context, room *domain.Room) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRoom", ctx, room)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRoom indicates an expected call of SaveRoom.
+func (mr *MockRoomRepositoryMockRecorder) SaveRoom(ctx, room interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRoom", reflect.TypeOf((*MockRoomRepository)(nil).SaveRoom), ctx, room)
+}
+
+// SaveSensorRoom mocks base method.
+func (m *MockRoomRepository) SaveSensorRoom(ctx context.Context, sensorRoom domain.SensorRoom) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSensorRoom", ctx, sensorRoom)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSensorRoom indicates an expected call of SaveSensorRoom.
+func (mr *MockRoomRepositoryMockRecorder) SaveSensorRoom(ctx, sensorRoom interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSensorRoom", reflect.TypeOf((*MockRoomRepository)(nil).SaveSensorRoom), ctx, sensorRoom)
+}
+
+// MockHomeOwnerRepository is a mock of HomeOwnerRepository interface.
+type MockHomeOwnerRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHomeOwnerRepositoryMockRecorder
+}
+
+// MockHomeOwnerRepositoryMockRecorder is the mock recorder for MockHomeOwnerRepository.
+type MockHomeOwnerRepositoryMockRecorder struct {
+	mock *MockHomeOwnerRepository
+}
+
+// NewMockHomeOwnerRepository creates a new mock instance.
+func NewMockHomeOwnerRepository(ctrl *gomock.Controller) *MockHomeOwnerRepository {
+	mock := &MockHomeOwnerRepository{ctrl: ctrl}
+	mock.recorder = &MockHomeOwnerRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHomeOwnerRepository) EXPECT() *MockHomeOwnerRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteHomeOwnersByHomeID mocks base method.
+func (m *MockHomeOwnerRepository) DeleteHomeOwnersByHomeID(ctx context.Context, homeID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHomeOwnersByHomeID", ctx, homeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteHomeOwnersByHomeID indicates an expected call of DeleteHomeOwnersByHomeID.
+func (mr *MockHomeOwnerRepositoryMockRecorder) DeleteHomeOwnersByHomeID(ctx, homeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHomeOwnersByHomeID", reflect.TypeOf((*MockHomeOwnerRepository)(nil).DeleteHomeOwnersByHomeID), ctx, homeID)
+}
+
+// DeleteHomeOwnersByUserID mocks base method.
+func (m *MockHomeOwnerRepository) DeleteHomeOwnersByUserID(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHomeOwnersByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteHomeOwnersByUserID indicates an expected call of DeleteHomeOwnersByUserID.
+func (mr *MockHomeOwnerRepositoryMockRecorder) DeleteHomeOwnersByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHomeOwnersByUserID", reflect.TypeOf((*MockHomeOwnerRepository)(nil).DeleteHomeOwnersByUserID), ctx, userID)
+}
+
+// GetHomesByUserID mocks base method.
+func (m *MockHomeOwnerRepository) GetHomesByUserID(ctx context.Context, userID int64) ([]domain.HomeOwner, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHomesByUserID", ctx, userID)
+	ret0, _ := ret[0].([]domain.HomeOwner)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHomesByUserID indicates an expected call of GetHomesByUserID.
+func (mr *MockHomeOwnerRepositoryMockRecorder) GetHomesByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHomesByUserID", reflect.TypeOf((*MockHomeOwnerRepository)(nil).GetHomesByUserID), ctx, userID)
+}
+
+// SaveHomeOwner mocks base method.
+func (m *MockHomeOwnerRepository) SaveHomeOwner(ctx context.Context, homeOwner domain.HomeOwner) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveHomeOwner", ctx, homeOwner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveHomeOwner indicates an expected call of SaveHomeOwner.
+func (mr *MockHomeOwnerRepositoryMockRecorder) SaveHomeOwner(ctx, homeOwner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveHomeOwner", reflect.TypeOf((*MockHomeOwnerRepository)(nil).SaveHomeOwner), ctx, homeOwner)
+}
+
+// MockGroupRepository is a mock of GroupRepository interface.
+type MockGroupRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockGroupRepositoryMockRecorder
+}
+
+// MockGroupRepositoryMockRecorder is the mock recorder for MockGroupRepository.
+type MockGroupRepositoryMockRecorder struct {
+	mock *MockGroupRepository
+}
+
+// NewMockGroupRepository creates a new mock instance.
+func NewMockGroupRepository(ctrl *gomock.Controller) *MockGroupRepository {
+	mock := &MockGroupRepository{ctrl: ctrl}
+	mock.recorder = &MockGroupRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGroupRepository) EXPECT() *MockGroupRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteGroup mocks base method.
+func (m *MockGroupRepository) DeleteGroup(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGroup", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGroup indicates an expected call of DeleteGroup.
+func (mr *MockGroupRepositoryMockRecorder) DeleteGroup(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGroup", reflect.TypeOf((*MockGroupRepository)(nil).DeleteGroup), ctx, id)
+}
+
+// DeleteGroupMember mocks base method.
+func (m *MockGroupRepository) DeleteGroupMember(ctx context.Context, groupID, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGroupMember", ctx, groupID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGroupMember indicates an expected call of DeleteGroupMember.
+func (mr *MockGroupRepositoryMockRecorder) DeleteGroupMember(ctx, groupID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGroupMember", reflect.TypeOf((*MockGroupRepository)(nil).DeleteGroupMember), ctx, groupID, userID)
+}
+
+// DeleteGroupSensor mocks base method.
+func (m *MockGroupRepository) DeleteGroupSensor(ctx context.Context, groupID, sensorID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGroupSensor", ctx, groupID, sensorID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGroupSensor indicates an expected call of DeleteGroupSensor.
+func (mr *MockGroupRepositoryMockRecorder) DeleteGroupSensor(ctx, groupID, sensorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGroupSensor", reflect.TypeOf((*MockGroupRepository)(nil).DeleteGroupSensor), ctx, groupID, sensorID)
+}
+
+// GetGroupByID mocks base method.
+func (m *MockGroupRepository) GetGroupByID(ctx context.Context, id int64) (*domain.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroupByID indicates an expected call of GetGroupByID.
+func (mr *MockGroupRepositoryMockRecorder) GetGroupByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupByID", reflect.TypeOf((*MockGroupRepository)(nil).GetGroupByID), ctx, id)
+}
+
+// GetGroups mocks base method.
+func (m *MockGroupRepository) GetGroups(ctx context.Context) ([]domain.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroups", ctx)
+	ret0, _ := ret[0].([]domain.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroups indicates an expected call of GetGroups.
+func (mr *MockGroupRepositoryMockRecorder) GetGroups(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroups", reflect.TypeOf((*MockGroupRepository)(nil).GetGroups), ctx)
+}
+
+// GetGroupsByUserID mocks base method.
+func (m *MockGroupRepository) GetGroupsByUserID(ctx context.Context, userID int64) ([]domain.GroupMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupsByUserID", ctx, userID)
+	ret0, _ := ret[0].([]domain.GroupMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroupsByUserID indicates an expected call of GetGroupsByUserID.
+func (mr *MockGroupRepositoryMockRecorder) GetGroupsByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupsByUserID", reflect.TypeOf((*MockGroupRepository)(nil).GetGroupsByUserID), ctx, userID)
+}
+
+// GetMembersByGroupID mocks base method.
+func (m *MockGroupRepository) GetMembersByGroupID(ctx context.Context, groupID int64) ([]domain.GroupMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembersByGroupID", ctx, groupID)
+	ret0, _ := ret[0].([]domain.GroupMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembersByGroupID indicates an expected call of GetMembersByGroupID.
+func (mr *MockGroupRepositoryMockRecorder) GetMembersByGroupID(ctx, groupID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembersByGroupID", reflect.TypeOf((*MockGroupRepository)(nil).GetMembersByGroupID), ctx, groupID)
+}
+
+// GetSensorsByGroupID mocks base method.
+func (m *MockGroupRepository) GetSensorsByGroupID(ctx context.Context, groupID int64) ([]domain.GroupSensor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSensorsByGroupID", ctx, groupID)
+	ret0, _ := ret[0].([]domain.GroupSensor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSensorsByGroupID indicates an expected call of GetSensorsByGroupID.
+func (mr *MockGroupRepositoryMockRecorder) GetSensorsByGroupID(ctx, groupID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorsByGroupID", reflect.TypeOf((*MockGroupRepository)(nil).GetSensorsByGroupID), ctx, groupID)
+}
+
+// SaveGroup mocks base method.
+func (m *MockGroupRepository) SaveGroup(ctx context.Context, group *domain.Group) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveGroup", ctx, group)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveGroup indicates an expected call of SaveGroup.
+func (mr *MockGroupRepositoryMockRecorder) SaveGroup(ctx, group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveGroup", reflect.TypeOf((*MockGroupRepository)(nil).SaveGroup), ctx, group)
+}
+
+// SaveGroupMember mocks base method.
+func (m *MockGroupRepository) SaveGroupMember(ctx context.Context, member domain.GroupMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveGroupMember", ctx, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveGroupMember indicates an expected call of SaveGroupMember.
+func (mr *MockGroupRepositoryMockRecorder) SaveGroupMember(ctx, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveGroupMember", reflect.TypeOf((*MockGroupRepository)(nil).SaveGroupMember), ctx, member)
+}
+
+// SaveGroupSensor mocks base method.
+func (m *MockGroupRepository) SaveGroupSensor(ctx context.Context, groupSensor domain.GroupSensor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveGroupSensor", ctx, groupSensor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveGroupSensor indicates an expected call of SaveGroupSensor.
+func (mr *MockGroupRepositoryMockRecorder) SaveGroupSensor(ctx, groupSensor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveGroupSensor", reflect.TypeOf((*MockGroupRepository)(nil).SaveGroupSensor), ctx, groupSensor)
+}
+
+// MockFirmwareHistoryRepository is a mock of FirmwareHistoryRepository interface.
+type MockFirmwareHistoryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFirmwareHistoryRepositoryMockRecorder
+}
+
+// MockFirmwareHistoryRepositoryMockRecorder is the mock recorder for MockFirmwareHistoryRepository.
+type MockFirmwareHistoryRepositoryMockRecorder struct {
+	mock *MockFirmwareHistoryRepository
+}
+
+// NewMockFirmwareHistoryRepository creates a new mock instance.
+func NewMockFirmwareHistoryRepository(ctrl *gomock.Controller) *MockFirmwareHistoryRepository {
+	mock := &MockFirmwareHistoryRepository{ctrl: ctrl}
+	mock.recorder = &MockFirmwareHistoryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFirmwareHistoryRepository) EXPECT() *MockFirmwareHistoryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetFirmwareChangesBySensorID mocks base method.
+func (m *MockFirmwareHistoryRepository) GetFirmwareChangesBySensorID(ctx context.Context, sensorID int64) ([]domain.FirmwareChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFirmwareChangesBySensorID", ctx, sensorID)
+	ret0, _ := ret[0].([]domain.FirmwareChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFirmwareChangesBySensorID indicates an expected call of GetFirmwareChangesBySensorID.
+func (mr *MockFirmwareHistoryRepositoryMockRecorder) GetFirmwareChangesBySensorID(ctx, sensorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFirmwareChangesBySensorID", reflect.TypeOf((*MockFirmwareHistoryRepository)(nil).GetFirmwareChangesBySensorID), ctx, sensorID)
+}
+
+// SaveFirmwareChange mocks base method.
+func (m *MockFirmwareHistoryRepository) SaveFirmwareChange(ctx context.Context, change *domain.FirmwareChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFirmwareChange", ctx, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveFirmwareChange indicates an expected call of SaveFirmwareChange.
+func (mr *MockFirmwareHistoryRepositoryMockRecorder) SaveFirmwareChange(ctx, change interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFirmwareChange", reflect.TypeOf((*MockFirmwareHistoryRepository)(nil).SaveFirmwareChange), ctx, change)
+}
+
+// MockAPITokenRepository is a mock of APITokenRepository interface.
+type MockAPITokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPITokenRepositoryMockRecorder
+}
+
+// MockAPITokenRepositoryMockRecorder is the mock recorder for MockAPITokenRepository.
+type MockAPITokenRepositoryMockRecorder struct {
+	mock *MockAPITokenRepository
+}
+
+// NewMockAPITokenRepository creates a new mock instance.
+func NewMockAPITokenRepository(ctrl *gomock.Controller) *MockAPITokenRepository {
+	mock := &MockAPITokenRepository{ctrl: ctrl}
+	mock.recorder = &MockAPITokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPITokenRepository) EXPECT() *MockAPITokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteAPITokensByUserID mocks base method.
+func (m *MockAPITokenRepository) DeleteAPITokensByUserID(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPITokensByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAPITokensByUserID indicates an expected call of DeleteAPITokensByUserID.
+func (mr *MockAPITokenRepositoryMockRecorder) DeleteAPITokensByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPITokensByUserID", reflect.TypeOf((*MockAPITokenRepository)(nil).DeleteAPITokensByUserID), ctx, userID)
+}
+
+// GetAPITokenByHash mocks base method.
+func (m *MockAPITokenRepository) GetAPITokenByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPITokenByHash", ctx, hash)
+	ret0, _ := ret[0].(*domain.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPITokenByHash indicates an expected call of GetAPITokenByHash.
+func (mr *MockAPITokenRepositoryMockRecorder) GetAPITokenByHash(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPITokenByHash", reflect.TypeOf((*MockAPITokenRepository)(nil).GetAPITokenByHash), ctx, hash)
+}
+
+// GetAPITokensByUserID mocks base method.
+func (m *MockAPITokenRepository) GetAPITokensByUserID(ctx context.Context, userID int64) ([]domain.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPITokensByUserID", ctx, userID)
+	ret0, _ := ret[0].([]domain.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPITokensByUserID indicates an expected call of GetAPITokensByUserID.
+func (mr *MockAPITokenRepositoryMockRecorder) GetAPITokensByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPITokensByUserID", reflect.TypeOf((*MockAPITokenRepository)(nil).GetAPITokensByUserID), ctx, userID)
+}
+
+// RevokeAPIToken mocks base method.
+func (m *MockAPITokenRepository) RevokeAPIToken(ctx context.Context, id int64, revokedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIToken", ctx, id, revokedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIToken indicates an expected call of RevokeAPIToken.
+func (mr *MockAPITokenRepositoryMockRecorder) RevokeAPIToken(ctx, id, revokedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIToken", reflect.TypeOf((*MockAPITokenRepository)(nil).RevokeAPIToken), ctx, id, revokedAt)
+}
+
+// SaveAPIToken mocks base method.
+func (m *MockAPITokenRepository) SaveAPIToken(ctx context.Context, token *domain.APIToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAPIToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAPIToken indicates an expected call of SaveAPIToken.
+func (mr *MockAPITokenRepositoryMockRecorder) SaveAPIToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAPIToken", reflect.TypeOf((*MockAPITokenRepository)(nil).SaveAPIToken), ctx, token)
+}
+
+// MockSensorCredentialRepository is a mock of SensorCredentialRepository interface.
+type MockSensorCredentialRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSensorCredentialRepositoryMockRecorder
+}
+
+// MockSensorCredentialRepositoryMockRecorder is the mock recorder for MockSensorCredentialRepository.
+type MockSensorCredentialRepositoryMockRecorder struct {
+	mock *MockSensorCredentialRepository
+}
+
+// NewMockSensorCredentialRepository creates a new mock instance.
+func NewMockSensorCredentialRepository(ctrl *gomock.Controller) *MockSensorCredentialRepository {
+	mock := &MockSensorCredentialRepository{ctrl: ctrl}
+	mock.recorder = &MockSensorCredentialRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSensorCredentialRepository) EXPECT() *MockSensorCredentialRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateSensorCredential mocks base method.
+func (m *MockSensorCredentialRepository) CreateSensorCredential(ctx context.Context, credential *domain.SensorCredential) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSensorCredential", ctx, credential)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSensorCredential indicates an expected call of CreateSensorCredential.
+func (mr *MockSensorCredentialRepositoryMockRecorder) CreateSensorCredential(ctx, credential interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSensorCredential", reflect.TypeOf((*MockSensorCredentialRepository)(nil).CreateSensorCredential), ctx, credential)
+}
+
+// GetSensorCredential mocks base method.
+func (m *MockSensorCredentialRepository) GetSensorCredential(ctx context.Context, sensorID int64) (*domain.SensorCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSensorCredential", ctx, sensorID)
+	ret0, _ := ret[0].(*domain.SensorCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSensorCredential indicates an expected call of GetSensorCredential.
+func (mr *MockSensorCredentialRepositoryMockRecorder) GetSensorCredential(ctx, sensorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorCredential", reflect.TypeOf((*MockSensorCredentialRepository)(nil).GetSensorCredential), ctx, sensorID)
+}
+
+// SaveSensorCredential mocks base method.
+func (m *MockSensorCredentialRepository) SaveSensorCredential(ctx context.Context, credential *domain.SensorCredential) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSensorCredential", ctx, credential)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSensorCredential indicates an expected call of SaveSensorCredential.
+func (mr *MockSensorCredentialRepositoryMockRecorder) SaveSensorCredential(ctx, credential interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSensorCredential", reflect.TypeOf((*MockSensorCredentialRepository)(nil).SaveSensorCredential), ctx, credential)
+}
+
+// MockSensorInvitationRepository is a mock of SensorInvitationRepository interface.
+type MockSensorInvitationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSensorInvitationRepositoryMockRecorder
+}
+
+// MockSensorInvitationRepositoryMockRecorder is the mock recorder for MockSensorInvitationRepository.
+type MockSensorInvitationRepositoryMockRecorder struct {
+	mock *MockSensorInvitationRepository
+}
+
+// NewMockSensorInvitationRepository creates a new mock instance.
+func NewMockSensorInvitationRepository(ctrl *gomock.Controller) *MockSensorInvitationRepository {
+	mock := &MockSensorInvitationRepository{ctrl: ctrl}
+	mock.recorder = &MockSensorInvitationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSensorInvitationRepository) EXPECT() *MockSensorInvitationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetSensorInvitationByHash mocks base method.
+func (m *MockSensorInvitationRepository) GetSensorInvitationByHash(ctx context.Context, hash string) (*domain.SensorInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSensorInvitationByHash", ctx, hash)
+	ret0, _ := ret[0].(*domain.SensorInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSensorInvitationByHash indicates an expected call of GetSensorInvitationByHash.
+func (mr *MockSensorInvitationRepositoryMockRecorder) GetSensorInvitationByHash(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorInvitationByHash", reflect.TypeOf((*MockSensorInvitationRepository)(nil).GetSensorInvitationByHash), ctx, hash)
+}
+
+// GetSensorInvitationsBySensorID mocks base method.
+func (m *MockSensorInvitationRepository) GetSensorInvitationsBySensorID(ctx context.Context, sensorID int64) ([]domain.SensorInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSensorInvitationsBySensorID", ctx, sensorID)
+	ret0, _ := ret[0].([]domain.SensorInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSensorInvitationsBySensorID indicates an expected call of GetSensorInvitationsBySensorID.
+func (mr *MockSensorInvitationRepositoryMockRecorder) GetSensorInvitationsBySensorID(ctx, sensorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorInvitationsBySensorID", reflect.TypeOf((*MockSensorInvitationRepository)(nil).GetSensorInvitationsBySensorID), ctx, sensorID)
+}
+
+// RevokeSensorInvitation mocks base method.
+func (m *MockSensorInvitationRepository) RevokeSensorInvitation(ctx context.Context, id int64, revokedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSensorInvitation", ctx, id, revokedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSensorInvitation indicates an expected call of RevokeSensorInvitation.
+func (mr *MockSensorInvitationRepositoryMockRecorder) RevokeSensorInvitation(ctx, id, revokedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSensorInvitation", reflect.TypeOf((*MockSensorInvitationRepository)(nil).RevokeSensorInvitation), ctx, id, revokedAt)
+}
+
+// SaveSensorInvitation mocks base method.
+func (m *MockSensorInvitationRepository) SaveSensorInvitation(ctx context.Context, invitation *domain.SensorInvitation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSensorInvitation", ctx, invitation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSensorInvitation indicates an expected call of SaveSensorInvitation.
+func (mr *MockSensorInvitationRepositoryMockRecorder) SaveSensorInvitation(ctx, invitation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSensorInvitation", reflect.TypeOf((*MockSensorInvitationRepository)(nil).SaveSensorInvitation), ctx, invitation)
+}
+
+// UseSensorInvitation mocks base method.
+func (m *MockSensorInvitationRepository) UseSensorInvitation(ctx context.Context, id int64, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseSensorInvitation", ctx, id, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseSensorInvitation indicates an expected call of UseSensorInvitation.
+func (mr *MockSensorInvitationRepositoryMockRecorder) UseSensorInvitation(ctx, id, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseSensorInvitation", reflect.TypeOf((*MockSensorInvitationRepository)(nil).UseSensorInvitation), ctx, id, now)
+}
+
+// MockRuleRepository is a mock of RuleRepository interface.
+type MockRuleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRuleRepositoryMockRecorder
+}
+
+// MockRuleRepositoryMockRecorder is the mock recorder for MockRuleRepository.
+type MockRuleRepositoryMockRecorder struct {
+	mock *MockRuleRepository
+}
+
+// NewMockRuleRepository creates a new mock instance.
+func NewMockRuleRepository(ctrl *gomock.Controller) *MockRuleRepository {
+	mock := &MockRuleRepository{ctrl: ctrl}
+	mock.recorder = &MockRuleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRuleRepository) EXPECT() *MockRuleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteRule mocks base method.
+func (m *MockRuleRepository) DeleteRule(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRule", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRule indicates an expected call of DeleteRule.
+func (mr *MockRuleRepositoryMockRecorder) DeleteRule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRule", reflect.TypeOf((*MockRuleRepository)(nil).DeleteRule), ctx, id)
+}
+
+// DeleteRuleState mocks base method.
+func (m *MockRuleRepository) DeleteRuleState(ctx context.Context, ruleID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRuleState", ctx, ruleID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRuleState indicates an expected call of DeleteRuleState.
+func (mr *MockRuleRepositoryMockRecorder) DeleteRuleState(ctx, ruleID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRuleState", reflect.TypeOf((*MockRuleRepository)(nil).DeleteRuleState), ctx, ruleID)
+}
+
+// GetRuleByID mocks base method.
+func (m *MockRuleRepository) GetRuleByID(ctx context.Context, id int64) (*domain.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRuleByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRuleByID indicates an expected call of GetRuleByID.
+func (mr *MockRuleRepositoryMockRecorder) GetRuleByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRuleByID", reflect.TypeOf((*MockRuleRepository)(nil).GetRuleByID), ctx, id)
+}
+
+// GetRuleState mocks base method.
+func (m *MockRuleRepository) GetRuleState(ctx context.Context, ruleID int64) (*domain.RuleState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRuleState", ctx, ruleID)
+	ret0, _ := ret[0].(*domain.RuleState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRuleState indicates an expected call of GetRuleState.
+func (mr *MockRuleRepositoryMockRecorder) GetRuleState(ctx, ruleID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRuleState", reflect.TypeOf((*MockRuleRepository)(nil).GetRuleState), ctx, ruleID)
+}
+
+// GetRuleStates mocks base method.
+func (m *MockRuleRepository) GetRuleStates(ctx context.Context) ([]domain.RuleState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRuleStates", ctx)
+	ret0, _ := ret[0].([]domain.RuleState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRuleStates indicates an expected call of GetRuleStates.
+func (mr *MockRuleRepositoryMockRecorder) GetRuleStates(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRuleStates", reflect.TypeOf((*MockRuleRepository)(nil).GetRuleStates), ctx)
+}
+
+// GetRules mocks base method.
+func (m *MockRuleRepository) GetRules(ctx context.Context) ([]domain.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRules", ctx)
+	ret0, _ := ret[0].([]domain.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRules indicates an expected call of GetRules.
+func (mr *MockRuleRepositoryMockRecorder) GetRules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRules", reflect.TypeOf((*MockRuleRepository)(nil).GetRules), ctx)
+}
+
+// GetRulesBySensorID mocks base method.
+func (m *MockRuleRepository) GetRulesBySensorID(ctx context.Context, sensorID int64) ([]domain.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRulesBySensorID", ctx, sensorID)
+	ret0, _ := ret[0].([]domain.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRulesBySensorID indicates an expected call of GetRulesBySensorID.
+func (mr *MockRuleRepositoryMockRecorder) GetRulesBySensorID(ctx, sensorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRulesBySensorID", reflect.TypeOf((*MockRuleRepository)(nil).GetRulesBySensorID), ctx, sensorID)
+}
+
+// SaveRule mocks base method.
+func (m *MockRuleRepository) SaveRule(ctx context.Context, rule *domain.Rule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRule", ctx, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRule indicates an expected call of SaveRule.
+func (mr *MockRuleRepositoryMockRecorder) SaveRule(ctx, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRule", reflect.TypeOf((*MockRuleRepository)(nil).SaveRule), ctx, rule)
+}
+
+// SaveRuleState mocks base method.
+func (m *MockRuleRepository) SaveRuleState(ctx context.Context, state domain.RuleState) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRuleState", ctx, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRuleState indicates an expected call of SaveRuleState.
+func (mr *MockRuleRepositoryMockRecorder) SaveRuleState(ctx, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRuleState", reflect.TypeOf((*MockRuleRepository)(nil).SaveRuleState), ctx, state)
+}
+
+// MockAnomalyStateRepository is a mock of AnomalyStateRepository interface.
+type MockAnomalyStateRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAnomalyStateRepositoryMockRecorder
+}
+
+// MockAnomalyStateRepositoryMockRecorder is the mock recorder for MockAnomalyStateRepository.
+type MockAnomalyStateRepositoryMockRecorder struct {
+	mock *MockAnomalyStateRepository
+}
+
+// NewMockAnomalyStateRepository creates a new mock instance.
+func NewMockAnomalyStateRepository(ctrl *gomock.Controller) *MockAnomalyStateRepository {
+	mock := &MockAnomalyStateRepository{ctrl: ctrl}
+	mock.recorder = &MockAnomalyStateRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAnomalyStateRepository) EXPECT() *MockAnomalyStateRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteAnomalyState mocks base method.
+func (m *MockAnomalyStateRepository) DeleteAnomalyState(ctx context.Context, sensorID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAnomalyState", ctx, sensorID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAnomalyState indicates an expected call of DeleteAnomalyState.
+func (mr *MockAnomalyStateRepositoryMockRecorder) DeleteAnomalyState(ctx, sensorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAnomalyState", reflect.TypeOf((*MockAnomalyStateRepository)(nil).DeleteAnomalyState), ctx, sensorID)
+}
+
+// GetAnomalyState mocks base method.
+func (m *MockAnomalyStateRepository) GetAnomalyState(ctx context.Context, sensorID int64) (*domain.AnomalyState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAnomalyState", ctx, sensorID)
+	ret0, _ := ret[0].(*domain.AnomalyState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAnomalyState indicates an expected call of GetAnomalyState.
+func (mr *MockAnomalyStateRepositoryMockRecorder) GetAnomalyState(ctx, sensorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAnomalyState", reflect.TypeOf((*MockAnomalyStateRepository)(nil).GetAnomalyState), ctx, sensorID)
+}
+
+// SaveAnomalyStates mocks base method.
+func (m *MockAnomalyStateRepository) SaveAnomalyStates(ctx context.Context, states []domain.AnomalyState) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAnomalyStates", ctx, states)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAnomalyStates indicates an expected call of SaveAnomalyStates.
+func (mr *MockAnomalyStateRepositoryMockRecorder) SaveAnomalyStates(ctx, states interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAnomalyStates", reflect.TypeOf((*MockAnomalyStateRepository)(nil).SaveAnomalyStates), ctx, states)
+}
+
+// MockVirtualSensorRepository is a mock of VirtualSensorRepository interface.
+type MockVirtualSensorRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockVirtualSensorRepositoryMockRecorder
+}
+
+// MockVirtualSensorRepositoryMockRecorder is the mock recorder for MockVirtualSensorRepository.
+type MockVirtualSensorRepositoryMockRecorder struct {
+	mock *MockVirtualSensorRepository
+}
+
+// NewMockVirtualSensorRepository creates a new mock instance.
+func NewMockVirtualSensorRepository(ctrl *gomock.Controller) *MockVirtualSensorRepository {
+	mock := &MockVirtualSensorRepository{ctrl: ctrl}
+	mock.recorder = &MockVirtualSensorRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVirtualSensorRepository) EXPECT() *MockVirtualSensorRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetVirtualSensorIDsByInputID mocks base method.
+func (m *MockVirtualSensorRepository) GetVirtualSensorIDsByInputID(ctx context.Context, inputID int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVirtualSensorIDsByInputID", ctx, inputID)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVirtualSensorIDsByInputID indicates an expected call of GetVirtualSensorIDsByInputID.
+func (mr *MockVirtualSensorRepositoryMockRecorder) GetVirtualSensorIDsByInputID(ctx, inputID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVirtualSensorIDsByInputID", reflect.TypeOf((*MockVirtualSensorRepository)(nil).GetVirtualSensorIDsByInputID), ctx, inputID)
+}
+
+// SaveVirtualSensorInputs mocks base method.
+func (m *MockVirtualSensorRepository) SaveVirtualSensorInputs(ctx context.Context, sensorID int64, inputIDs []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveVirtualSensorInputs", ctx, sensorID, inputIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveVirtualSensorInputs indicates an expected call of SaveVirtualSensorInputs.
+func (mr *MockVirtualSensorRepositoryMockRecorder) SaveVirtualSensorInputs(ctx, sensorID, inputIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveVirtualSensorInputs", reflect.TypeOf((*MockVirtualSensorRepository)(nil).SaveVirtualSensorInputs), ctx, sensorID, inputIDs)
+}
+
+// MockAlertRepository is a mock of AlertRepository interface.
+type MockAlertRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlertRepositoryMockRecorder
+}
+
+// MockAlertRepositoryMockRecorder is the mock recorder for MockAlertRepository.
+type MockAlertRepositoryMockRecorder struct {
+	mock *MockAlertRepository
+}
+
+// NewMockAlertRepository creates a new mock instance.
+func NewMockAlertRepository(ctrl *gomock.Controller) *MockAlertRepository {
+	mock := &MockAlertRepository{ctrl: ctrl}
+	mock.recorder = &MockAlertRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAlertRepository) EXPECT() *MockAlertRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddAlertOccurrence mocks base method.
+func (m *MockAlertRepository) AddAlertOccurrence(ctx context.Context, id int64, value float64, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAlertOccurrence", ctx, id, value, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAlertOccurrence indicates an expected call of AddAlertOccurrence.
+func (mr *MockAlertRepositoryMockRecorder) AddAlertOccurrence(ctx, id, value, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAlertOccurrence", reflect.TypeOf((*MockAlertRepository)(nil).AddAlertOccurrence), ctx, id, value, at)
+}
+
+// GetActiveAlertByAutomationID mocks base method.
+func (m *MockAlertRepository) GetActiveAlertByAutomationID(ctx context.Context, automationID int64) (*domain.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveAlertByAutomationID", ctx, automationID)
+	ret0, _ := ret[0].(*domain.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveAlertByAutomationID indicates an expected call of GetActiveAlertByAutomationID.
+func (mr *MockAlertRepositoryMockRecorder) GetActiveAlertByAutomationID(ctx, automationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveAlertByAutomationID", reflect.TypeOf((*MockAlertRepository)(nil).GetActiveAlertByAutomationID), ctx, automationID)
+}
+
+// GetActiveAlertByRuleID mocks base method.
+func (m *MockAlertRepository) GetActiveAlertByRuleID(ctx context.Context, ruleID int64) (*domain.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveAlertByRuleID", ctx, ruleID)
+	ret0, _ := ret[0].(*domain.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveAlertByRuleID indicates an expected call of GetActiveAlertByRuleID.
+func (mr *MockAlertRepositoryMockRecorder) GetActiveAlertByRuleID(ctx, ruleID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveAlertByRuleID", reflect.TypeOf((*MockAlertRepository)(nil).GetActiveAlertByRuleID), ctx, ruleID)
+}
+
+// GetActiveAnomalyAlert mocks base method.
+func (m *MockAlertRepository) GetActiveAnomalyAlert(ctx context.Context, sensorID int64) (*domain.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveAnomalyAlert", ctx, sensorID)
+	ret0, _ := ret[0].(*domain.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveAnomalyAlert indicates an expected call of GetActiveAnomalyAlert.
+func (mr *MockAlertRepositoryMockRecorder) GetActiveAnomalyAlert(ctx, sensorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveAnomalyAlert", reflect.TypeOf((*MockAlertRepository)(nil).GetActiveAnomalyAlert), ctx, sensorID)
+}
+
+// GetAlertByID mocks base method.
+func (m *MockAlertRepository) GetAlertByID(ctx context.Context, id int64) (*domain.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlertByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlertByID indicates an expected call of GetAlertByID.
+func (mr *MockAlertRepositoryMockRecorder) GetAlertByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlertByID", reflect.TypeOf((*MockAlertRepository)(nil).GetAlertByID), ctx, id)
+}
+
+// GetAlerts mocks base method.
+func (m *MockAlertRepository) GetAlerts(ctx context.Context, filter domain.AlertFilter) ([]domain.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlerts", ctx, filter)
+	ret0, _ := ret[0].([]domain.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlerts indicates an expected call of GetAlerts.
+func (mr *MockAlertRepositoryMockRecorder) GetAlerts(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlerts", reflect.TypeOf((*MockAlertRepository)(nil).GetAlerts), ctx, filter)
+}
+
+// SaveAlert mocks base method.
+func (m *MockAlertRepository) SaveAlert(ctx context.Context, alert *domain.Alert) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAlert", ctx, alert)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAlert indicates an expected call of SaveAlert.
+func (mr *MockAlertRepositoryMockRecorder) SaveAlert(ctx, alert interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAlert", reflect.TypeOf((*MockAlertRepository)(nil).SaveAlert), ctx, alert)
+}
+
+// UpdateAlertStatus mocks base method.
+func (m *MockAlertRepository) UpdateAlertStatus(ctx context.Context, alert *domain.Alert) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAlertStatus", ctx, alert)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAlertStatus indicates an expected call of UpdateAlertStatus.
+func (mr *MockAlertRepositoryMockRecorder) UpdateAlertStatus(ctx, alert interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAlertStatus", reflect.TypeOf((*MockAlertRepository)(nil).UpdateAlertStatus), ctx, alert)
+}
+
+// MockWebhookRepository is a mock of WebhookRepository interface.
+type MockWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepositoryMockRecorder
+}
+
+// MockWebhookRepositoryMockRecorder is the mock recorder for MockWebhookRepository.
+type MockWebhookRepositoryMockRecorder struct {
+	mock *MockWebhookRepository
+}
+
+// NewMockWebhookRepository creates a new mock instance.
+func NewMockWebhookRepository(ctrl *gomock.Controller) *MockWebhookRepository {
+	mock := &MockWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepository) EXPECT() *MockWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimWebhookDeliveries mocks base method.
+func (m *MockWebhookRepository) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookDeliveries", ctx, now, leaseUntil, limit)
+	ret0, _ := ret[0].([]domain.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookDeliveries indicates an expected call of ClaimWebhookDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) ClaimWebhookDeliveries(ctx, now, leaseUntil, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).ClaimWebhookDeliveries), ctx, now, leaseUntil, limit)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhookRepository) DeleteWebhook(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookRepositoryMockRecorder) DeleteWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookRepository)(nil).DeleteWebhook), ctx, id)
+}
+
+// GetWebhookByID mocks base method.
+func (m *MockWebhookRepository) GetWebhookByID(ctx context.Context, id int64) (*domain.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookByID indicates an expected call of GetWebhookByID.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhookByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookByID", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhookByID), ctx, id)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockWebhookRepository) GetWebhookDeliveries(ctx context.Context, filter domain.WebhookDeliveryFilter) ([]domain.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", ctx, filter)
+	ret0, _ := ret[0].([]domain.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhookDeliveries(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhookDeliveries), ctx, filter)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockWebhookRepository) GetWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", ctx)
+	ret0, _ := ret[0].([]domain.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhooks(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhooks), ctx)
+}
+
+// SaveWebhook mocks base method.
+func (m *MockWebhookRepository) SaveWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebhook", ctx, webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebhook indicates an expected call of SaveWebhook.
+func (mr *MockWebhookRepositoryMockRecorder) SaveWebhook(ctx, webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebhook", reflect.TypeOf((*MockWebhookRepository)(nil).SaveWebhook), ctx, webhook)
+}
+
+// SaveWebhookDelivery mocks base method.
+func (m *MockWebhookRepository) SaveWebhookDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebhookDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebhookDelivery indicates an expected call of SaveWebhookDelivery.
+func (mr *MockWebhookRepositoryMockRecorder) SaveWebhookDelivery(ctx, delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebhookDelivery", reflect.TypeOf((*MockWebhookRepository)(nil).SaveWebhookDelivery), ctx, delivery)
+}
+
+// MockActuatorRepository is a mock of ActuatorRepository interface.
+type MockActuatorRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockActuatorRepositoryMockRecorder
+}
+
+// MockActuatorRepositoryMockRecorder is the mock recorder for MockActuatorRepository.
+type MockActuatorRepositoryMockRecorder struct {
+	mock *MockActuatorRepository
+}
+
+// NewMockActuatorRepository creates a new mock instance.
+func NewMockActuatorRepository(ctrl *gomock.Controller) *MockActuatorRepository {
+	mock := &MockActuatorRepository{ctrl: ctrl}
+	mock.recorder = &MockActuatorRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockActuatorRepository) EXPECT() *MockActuatorRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteActuator mocks base method.
+func (m *MockActuatorRepository) DeleteActuator(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteActuator", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteActuator indicates an expected call of DeleteActuator.
+func (mr *MockActuatorRepositoryMockRecorder) DeleteActuator(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteActuator", reflect.TypeOf((*MockActuatorRepository)(nil).DeleteActuator), ctx, id)
+}
+
+// GetActuatorByID mocks base method.
+func (m *MockActuatorRepository) GetActuatorByID(ctx context.Context, id int64) (*domain.Actuator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActuatorByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Actuator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActuatorByID indicates an expected call of GetActuatorByID.
+func (mr *MockActuatorRepositoryMockRecorder) GetActuatorByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActuatorByID", reflect.TypeOf((*MockActuatorRepository)(nil).GetActuatorByID), ctx, id)
+}
+
+// GetActuators mocks base method.
+func (m *MockActuatorRepository) GetActuators(ctx context.Context) ([]domain.Actuator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActuators", ctx)
+	ret0, _ := ret[0].([]domain.Actuator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActuators indicates an expected call of GetActuators.
+func (mr *MockActuatorRepositoryMockRecorder) GetActuators(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActuators", reflect.TypeOf((*MockActuatorRepository)(nil).GetActuators), ctx)
+}
+
+// SaveActuator mocks base method.
+func (m *MockActuatorRepository) SaveActuator(ctx context.Context, actuator *domain.Actuator) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveActuator", ctx, actuator)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveActuator indicates an expected call of SaveActuator.
+func (mr *MockActuatorRepositoryMockRecorder) SaveActuator(ctx, actuator interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveActuator", reflect.TypeOf((*MockActuatorRepository)(nil).SaveActuator), ctx, actuator)
+}
+
+// SetActuatorState mocks base method.
+func (m *MockActuatorRepository) SetActuatorState(ctx context.Context, id, state int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetActuatorState", ctx, id, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetActuatorState indicates an expected call of SetActuatorState.
+func (mr *MockActuatorRepositoryMockRecorder) SetActuatorState(ctx, id, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetActuatorState", reflect.TypeOf((*MockActuatorRepository)(nil).SetActuatorState), ctx, id, state)
+}
+
+// MockCommandRepository is a mock of CommandRepository interface.
+type MockCommandRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommandRepositoryMockRecorder
+}
+
+// MockCommandRepositoryMockRecorder is the mock recorder for MockCommandRepository.
+type MockCommandRepositoryMockRecorder struct {
+	mock *MockCommandRepository
+}
+
+// NewMockCommandRepository creates a new mock instance.
+func NewMockCommandRepository(ctrl *gomock.Controller) *MockCommandRepository {
+	mock := &MockCommandRepository{ctrl: ctrl}
+	mock.recorder = &MockCommandRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommandRepository) EXPECT() *MockCommandRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddCommand mocks base method.
+func (m *MockCommandRepository) AddCommand(ctx context.Context, command *domain.Command) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCommand", ctx, command)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddCommand indicates an expected call of AddCommand.
+func (mr *MockCommandRepositoryMockRecorder) AddCommand(ctx, command interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCommand", reflect.TypeOf((*MockCommandRepository)(nil).AddCommand), ctx, command)
+}
+
+// ExpireCommands mocks base method.
+func (m *MockCommandRepository) ExpireCommands(ctx context.Context, now time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireCommands", ctx, now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireCommands indicates an expected call of ExpireCommands.
+func (mr *MockCommandRepositoryMockRecorder) ExpireCommands(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireCommands", reflect.TypeOf((*MockCommandRepository)(nil).ExpireCommands), ctx, now)
+}
+
+// GetCommandByID mocks base method.
+func (m *MockCommandRepository) GetCommandByID(ctx context.Context, id int64) (*domain.Command, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommandByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Command)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommandByID indicates an expected call of GetCommandByID.
+func (mr *MockCommandRepositoryMockRecorder) GetCommandByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommandByID", reflect.TypeOf((*MockCommandRepository)(nil).GetCommandByID), ctx, id)
+}
+
+// GetCommands mocks base method.
+func (m *MockCommandRepository) GetCommands(ctx context.Context, filter domain.CommandFilter) ([]domain.Command, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommands", ctx, filter)
+	ret0, _ := ret[0].([]domain.Command)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommands indicates an expected call of GetCommands.
+func (mr *MockCommandRepositoryMockRecorder) GetCommands(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommands", reflect.TypeOf((*MockCommandRepository)(nil).GetCommands), ctx, filter)
+}
+
+// GetOpenCommandsBySensorID mocks base method.
+func (m *MockCommandRepository) GetOpenCommandsBySensorID(ctx context.Context, sensorID int64) ([]domain.Command, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenCommandsBySensorID", ctx, sensorID)
+	ret0, _ := ret[0].([]domain.Command)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOpenCommandsBySensorID indicates an expected call of GetOpenCommandsBySensorID.
+func (mr *MockCommandRepositoryMockRecorder) GetOpenCommandsBySensorID(ctx, sensorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenCommandsBySensorID", reflect.TypeOf((*MockCommandRepository)(nil).GetOpenCommandsBySensorID), ctx, sensorID)
+}
+
+// UpdateCommandStatus mocks base method.
+func (m *MockCommandRepository) UpdateCommandStatus(ctx context.Context, command *domain.Command) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCommandStatus", ctx, command)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCommandStatus indicates an expected call of UpdateCommandStatus.
+func (mr *MockCommandRepositoryMockRecorder) UpdateCommandStatus(ctx, command interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCommandStatus", reflect.TypeOf((*MockCommandRepository)(nil).UpdateCommandStatus), ctx, command)
+}
+
+// MockAutomationRepository is a mock of AutomationRepository interface.
+type MockAutomationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAutomationRepositoryMockRecorder
+}
+
+// MockAutomationRepositoryMockRecorder is the mock recorder for MockAutomationRepository.
+type MockAutomationRepositoryMockRecorder struct {
+	mock *MockAutomationRepository
+}
+
+// NewMockAutomationRepository creates a new mock instance.
+func NewMockAutomationRepository(ctrl *gomock.Controller) *MockAutomationRepository {
+	mock := &MockAutomationRepository{ctrl: ctrl}
+	mock.recorder = &MockAutomationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAutomationRepository) EXPECT() *MockAutomationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddAutomationRun mocks base method.
+func (m *MockAutomationRepository) AddAutomationRun(ctx context.Context, run *domain.AutomationRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAutomationRun", ctx, run)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAutomationRun indicates an expected call of AddAutomationRun.
+func (mr *MockAutomationRepositoryMockRecorder) AddAutomationRun(ctx, run interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAutomationRun", reflect.TypeOf((*MockAutomationRepository)(nil).AddAutomationRun), ctx, run)
+}
+
+// DeleteAutomation mocks base method.
+func (m *MockAutomationRepository) DeleteAutomation(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAutomation", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAutomation indicates an expected call of DeleteAutomation.
+func (mr *MockAutomationRepositoryMockRecorder) DeleteAutomation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAutomation", reflect.TypeOf((*MockAutomationRepository)(nil).DeleteAutomation), ctx, id)
+}
+
+// DeleteScene mocks base method.
+func (m *MockAutomationRepository) DeleteScene(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteScene", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteScene indicates an expected call of DeleteScene.
+func (mr *MockAutomationRepositoryMockRecorder) DeleteScene(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteScene", reflect.TypeOf((*MockAutomationRepository)(nil).DeleteScene), ctx, id)
+}
+
+// GetAutomationByID mocks base method.
+func (m *MockAutomationRepository) GetAutomationByID(ctx context.Context, id int64) (*domain.Automation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAutomationByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Automation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAutomationByID indicates an expected call of GetAutomationByID.
+func (mr *MockAutomationRepositoryMockRecorder) GetAutomationByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAutomationByID", reflect.TypeOf((*MockAutomationRepository)(nil).GetAutomationByID), ctx, id)
+}
+
+// GetAutomationRuns mocks base method.
+func (m *MockAutomationRepository) GetAutomationRuns(ctx context.Context, filter domain.AutomationRunFilter) ([]domain.AutomationRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAutomationRuns", ctx, filter)
+	ret0, _ := ret[0].([]domain.AutomationRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAutomationRuns indicates an expected call of GetAutomationRuns.
+func (mr *MockAutomationRepositoryMockRecorder) GetAutomationRuns(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAutomationRuns", reflect.TypeOf((*MockAutomationRepository)(nil).GetAutomationRuns), ctx, filter)
+}
+
+// GetAutomations mocks base method.
+func (m *MockAutomationRepository) GetAutomations(ctx context.Context) ([]domain.Automation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAutomations", ctx)
+	ret0, _ := ret[0].([]domain.Automation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAutomations indicates an expected call of GetAutomations.
+func (mr *MockAutomationRepositoryMockRecorder) GetAutomations(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAutomations", reflect.TypeOf((*MockAutomationRepository)(nil).GetAutomations), ctx)
+}
+
+// GetAutomationsByTriggerSensorID mocks base method.
+func (m *MockAutomationRepository) GetAutomationsByTriggerSensorID(ctx context.Context, sensorID int64) ([]domain.Automation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAutomationsByTriggerSensorID", ctx, sensorID)
+	ret0, _ := ret[0].([]domain.Automation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAutomationsByTriggerSensorID indicates an expected call of GetAutomationsByTriggerSensorID.
+func (mr *MockAutomationRepositoryMockRecorder) GetAutomationsByTriggerSensorID(ctx, sensorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAutomationsByTriggerSensorID", reflect.TypeOf((*MockAutomationRepository)(nil).GetAutomationsByTriggerSensorID), ctx, sensorID)
+}
+
+// GetSceneByID mocks base method.
+func (m *MockAutomationRepository) GetSceneByID(ctx context.Context, id int64) (*domain.Scene, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSceneByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Scene)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSceneByID indicates an expected call of GetSceneByID.
+func (mr *MockAutomationRepositoryMockRecorder) GetSceneByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSceneByID", reflect.TypeOf((*MockAutomationRepository)(nil).GetSceneByID), ctx, id)
+}
+
+// GetScenes mocks base method.
+func (m *MockAutomationRepository) GetScenes(ctx context.Context) ([]domain.Scene, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScenes", ctx)
+	ret0, _ := ret[0].([]domain.Scene)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScenes indicates an expected call of GetScenes.
+func (mr *MockAutomationRepositoryMockRecorder) GetScenes(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScenes", reflect.TypeOf((*MockAutomationRepository)(nil).GetScenes), ctx)
+}
+
+// SaveAutomation mocks base method.
+func (m *MockAutomationRepository) SaveAutomation(ctx context.Context, automation *domain.Automation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAutomation", ctx, automation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAutomation indicates an expected call of SaveAutomation.
+func (mr *MockAutomationRepositoryMockRecorder) SaveAutomation(ctx, automation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAutomation", reflect.TypeOf((*MockAutomationRepository)(nil).SaveAutomation), ctx, automation)
+}
+
+// SaveScene mocks base method.
+func (m *MockAutomationRepository) SaveScene(ctx context.Context, scene *domain.Scene) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveScene", ctx, scene)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveScene indicates an expected call of SaveScene.
+func (mr *MockAutomationRepositoryMockRecorder) SaveScene(ctx, scene interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveScene", reflect.TypeOf((*MockAutomationRepository)(nil).SaveScene), ctx, scene)
+}
+
+// MockScheduleRepository is a mock of ScheduleRepository interface.
+type MockScheduleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockScheduleRepositoryMockRecorder
+}
+
+// MockScheduleRepositoryMockRecorder is the mock recorder for MockScheduleRepository.
+type MockScheduleRepositoryMockRecorder struct {
+	mock *MockScheduleRepository
+}
+
+// NewMockScheduleRepository creates a new mock instance.
+func NewMockScheduleRepository(ctrl *gomock.Controller) *MockScheduleRepository {
+	mock := &MockScheduleRepository{ctrl: ctrl}
+	mock.recorder = &MockScheduleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScheduleRepository) EXPECT() *MockScheduleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AdvanceSchedule mocks base method.
+func (m *MockScheduleRepository) AdvanceSchedule(ctx context.Context, id int64, scheduled, next, last time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvanceSchedule", ctx, id, scheduled, next, last)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdvanceSchedule indicates an expected call of AdvanceSchedule.
+func (mr *MockScheduleRepositoryMockRecorder) AdvanceSchedule(ctx, id, scheduled, next, last interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvanceSchedule", reflect.TypeOf((*MockScheduleRepository)(nil).AdvanceSchedule), ctx, id, scheduled, next, last)
+}
+
+// DeleteSchedule mocks base method.
+func (m *MockScheduleRepository) DeleteSchedule(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSchedule", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSchedule indicates an expected call of DeleteSchedule.
+func (mr *MockScheduleRepositoryMockRecorder) DeleteSchedule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSchedule", reflect.TypeOf((*MockScheduleRepository)(nil).DeleteSchedule), ctx, id)
+}
+
+// GetDueSchedules mocks base method.
+func (m *MockScheduleRepository) GetDueSchedules(ctx context.Context, now time.Time) ([]domain.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueSchedules", ctx, now)
+	ret0, _ := ret[0].([]domain.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueSchedules indicates an expected call of GetDueSchedules.
+func (mr *MockScheduleRepositoryMockRecorder) GetDueSchedules(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueSchedules", reflect.TypeOf((*MockScheduleRepository)(nil).GetDueSchedules), ctx, now)
+}
+
+// GetScheduleByID mocks base method.
+func (m *MockScheduleRepository) GetScheduleByID(ctx context.Context, id int64) (*domain.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduleByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduleByID indicates an expected call of GetScheduleByID.
+func (mr *MockScheduleRepositoryMockRecorder) GetScheduleByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduleByID", reflect.TypeOf((*MockScheduleRepository)(nil).GetScheduleByID), ctx, id)
+}
+
+// GetSchedules mocks base method.
+func (m *MockScheduleRepository) GetSchedules(ctx context.Context) ([]domain.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedules", ctx)
+	ret0, _ := ret[0].([]domain.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchedules indicates an expected call of GetSchedules.
+func (mr *MockScheduleRepositoryMockRecorder) GetSchedules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedules", reflect.TypeOf((*MockScheduleRepository)(nil).GetSchedules), ctx)
+}
+
+// SaveSchedule mocks base method.
+func (m *MockScheduleRepository) SaveSchedule(ctx context.Context, schedule *domain.Schedule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSchedule", ctx, schedule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSchedule indicates an expected call of SaveSchedule.
+func (mr *MockScheduleRepositoryMockRecorder) SaveSchedule(ctx, schedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSchedule", reflect.TypeOf((*MockScheduleRepository)(nil).SaveSchedule), ctx, schedule)
+}
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepositoryMockRecorder
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+	mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+	mock := &MockOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddOutboxEntry mocks base method.
+func (m *MockOutboxRepository) AddOutboxEntry(ctx context.Context, entry *domain.OutboxEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOutboxEntry", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddOutboxEntry indicates an expected call of AddOutboxEntry.
+func (mr *MockOutboxRepositoryMockRecorder) AddOutboxEntry(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOutboxEntry", reflect.TypeOf((*MockOutboxRepository)(nil).AddOutboxEntry), ctx, entry)
+}
+
+// ClaimOutboxEntries mocks base method.
+func (m *MockOutboxRepository) ClaimOutboxEntries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.OutboxEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimOutboxEntries", ctx, now, leaseUntil, limit)
+	ret0, _ := ret[0].([]domain.OutboxEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimOutboxEntries indicates an expected call of ClaimOutboxEntries.
+func (mr *MockOutboxRepositoryMockRecorder) ClaimOutboxEntries(ctx, now, leaseUntil, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOutboxEntries", reflect.TypeOf((*MockOutboxRepository)(nil).ClaimOutboxEntries), ctx, now, leaseUntil, limit)
+}
+
+// DeleteOutboxEntry mocks base method.
+func (m *MockOutboxRepository) DeleteOutboxEntry(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOutboxEntry", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOutboxEntry indicates an expected call of DeleteOutboxEntry.
+func (mr *MockOutboxRepositoryMockRecorder) DeleteOutboxEntry(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOutboxEntry", reflect.TypeOf((*MockOutboxRepository)(nil).DeleteOutboxEntry), ctx, id)
+}
+
+// RetryOutboxEntry mocks base method.
+func (m *MockOutboxRepository) RetryOutboxEntry(ctx context.Context, entry *domain.OutboxEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryOutboxEntry", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryOutboxEntry indicates an expected call of RetryOutboxEntry.
+func (mr *MockOutboxRepositoryMockRecorder) RetryOutboxEntry(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryOutboxEntry", reflect.TypeOf((*MockOutboxRepository)(nil).RetryOutboxEntry), ctx, entry)
+}
+
+// MockOutboxPublisher is a mock of OutboxPublisher interface.
+type MockOutboxPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxPublisherMockRecorder
+}
+
+// MockOutboxPublisherMockRecorder is the mock recorder for MockOutboxPublisher.
+type MockOutboxPublisherMockRecorder struct {
+	mock *MockOutboxPublisher
+}
+
+// NewMockOutboxPublisher creates a new mock instance.
+func NewMockOutboxPublisher(ctrl *gomock.Controller) *MockOutboxPublisher {
+	mock := &MockOutboxPublisher{ctrl: ctrl}
+	mock.recorder = &MockOutboxPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxPublisher) EXPECT() *MockOutboxPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockOutboxPublisher) Publish(ctx context.Context, entry domain.OutboxEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockOutboxPublisherMockRecorder) Publish(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockOutboxPublisher)(nil).Publish), ctx, entry)
+}
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryMockRecorder
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+	mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+	mock := &MockAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetAuditEntries mocks base method.
+func (m *MockAuditRepository) GetAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEntries", ctx, filter)
+	ret0, _ := ret[0].([]domain.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEntries indicates an expected call of GetAuditEntries.
+func (mr *MockAuditRepositoryMockRecorder) GetAuditEntries(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEntries", reflect.TypeOf((*MockAuditRepository)(nil).GetAuditEntries), ctx, filter)
+}
+
+// SaveAuditEntry mocks base method.
+func (m *MockAuditRepository) SaveAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAuditEntry", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAuditEntry indicates an expected call of SaveAuditEntry.
+func (mr *MockAuditRepositoryMockRecorder) SaveAuditEntry(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAuditEntry", reflect.TypeOf((*MockAuditRepository)(nil).SaveAuditEntry), ctx, entry)
+}
+
+// MockEventNonceRepository is a mock of EventNonceRepository interface.
+type MockEventNonceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventNonceRepositoryMockRecorder
+}
+
+// MockEventNonceRepositoryMockRecorder is the mock recorder for MockEventNonceRepository.
+type MockEventNonceRepositoryMockRecorder struct {
+	mock *MockEventNonceRepository
+}
+
+// NewMockEventNonceRepository creates a new mock instance.
+func NewMockEventNonceRepository(ctrl *gomock.Controller) *MockEventNonceRepository {
+	mock := &MockEventNonceRepository{ctrl: ctrl}
+	mock.recorder = &MockEventNonceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventNonceRepository) EXPECT() *MockEventNonceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpiredEventNonces mocks base method.
+func (m *MockEventNonceRepository) DeleteExpiredEventNonces(ctx context.Context, now time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredEventNonces", ctx, now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredEventNonces indicates an expected call of DeleteExpiredEventNonces.
+func (mr *MockEventNonceRepositoryMockRecorder) DeleteExpiredEventNonces(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredEventNonces", reflect.TypeOf((*MockEventNonceRepository)(nil).DeleteExpiredEventNonces), ctx, now)
+}
+
+// SaveEventNonce mocks base method.
+func (m *MockEventNonceRepository) SaveEventNonce(ctx context.Context, sensorID int64, nonce string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveEventNonce", ctx, sensorID, nonce, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveEventNonce indicates an expected call of SaveEventNonce.
+func (mr *MockEventNonceRepositoryMockRecorder) SaveEventNonce(ctx, sensorID, nonce, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEventNonce", reflect.TypeOf((*MockEventNonceRepository)(nil).SaveEventNonce), ctx, sensorID, nonce, expiresAt)
+}
+
+// MockRateLimitStore is a mock of RateLimitStore interface.
+type MockRateLimitStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimitStoreMockRecorder
+}
+
+// MockRateLimitStoreMockRecorder is the mock recorder for MockRateLimitStore.
+type MockRateLimitStoreMockRecorder struct {
+	mock *MockRateLimitStore
+}
+
+// NewMockRateLimitStore creates a new mock instance.
+func NewMockRateLimitStore(ctrl *gomock.Controller) *MockRateLimitStore {
+	mock := &MockRateLimitStore{ctrl: ctrl}
+	mock.recorder = &MockRateLimitStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateLimitStore) EXPECT() *MockRateLimitStoreMockRecorder {
+	return m.recorder
+}
+
+// TakeToken mocks base method.
+func (m *MockRateLimitStore) TakeToken(ctx context.Context, key string, limit domain.RateLimit, now time.Time) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeToken", ctx, key, limit, now)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeToken indicates an expected call of TakeToken.
+func (mr *MockRateLimitStoreMockRecorder) TakeToken(ctx, key, limit, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeToken", reflect.TypeOf((*MockRateLimitStore)(nil).TakeToken), ctx, key, limit, now)
+}
@@ -2,21 +2,98 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"homework/internal/domain"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxUserNameLength - максимальная длина имени пользователя
+	maxUserNameLength = 64
+	// DefaultUsersPageSize - размер страницы списка пользователей, если он не задан
+	DefaultUsersPageSize = 50
+	// MaxUsersPageSize - максимальный размер страницы списка пользователей
+	MaxUsersPageSize = 100
 )
 
 type User struct {
 	userRepo        UserRepository
 	sensorOwnerRepo SensorOwnerRepository
 	sensorRepo      SensorRepository
+	homeOwnerRepo   HomeOwnerRepository
+	roomRepo        RoomRepository
+	groupRepo       GroupRepository
+	tokenRepo       APITokenRepository
+	access          *AccessPolicy
+	transactor      Transactor
+	audit           *Audit
 }
 
-func NewUser(ur UserRepository, sor SensorOwnerRepository, sr SensorRepository) *User {
-	return &User{
+func NewUser(ur UserRepository, sor SensorOwnerRepository, sr SensorRepository, options ...func(*User)) *User {
+	u := &User{
 		userRepo:        ur,
 		sensorOwnerRepo: sor,
 		sensorRepo:      sr,
+		transactor:      noTransaction{},
+	}
+
+	for _, o := range options {
+		o(u)
+	}
+
+	return u
+}
+
+// WithHomeAccess включает в список датчиков пользователя все датчики домов, к которым у него есть доступ.
+func WithHomeAccess(hor HomeOwnerRepository, rr RoomRepository) func(*User) {
+	return func(u *User) {
+		u.homeOwnerRepo = hor
+		u.roomRepo = rr
+	}
+}
+
+// WithUserGroups включает в список датчиков пользователя датчики групп, в которых он состоит
+func WithUserGroups(gr GroupRepository) func(*User) {
+	return func(u *User) {
+		u.groupRepo = gr
+	}
+}
+
+// WithUserTokens удаляет API-токены пользователя вместе с ним
+func WithUserTokens(tr APITokenRepository) func(*User) {
+	return func(u *User) {
+		u.tokenRepo = tr
+	}
+}
+
+// WithUserAccessPolicy запрещает привязывать чужие датчики и читать датчики других пользователей
+func WithUserAccessPolicy(p *AccessPolicy) func(*User) {
+	return func(u *User) {
+		u.access = p
+	}
+}
+
+// WithUserTransactor задает транзакцию для удаления пользователя вместе с его привязками
+func WithUserTransactor(t Transactor) func(*User) {
+	return func(u *User) {
+		u.transactor = t
+	}
+}
+
+// WithUserAudit записывает изменения пользователей и их привязок к датчикам в журнал аудита
+func WithUserAudit(a *Audit) func(*User) {
+	return func(u *User) {
+		u.audit = a
+	}
+}
+
+// checkSelf возвращает ErrUserNotFound, если пользователь из контекста запрашивает данные другого пользователя
+func (u *User) checkSelf(ctx context.Context, userID int64) error {
+	if caller, ok := u.access.restricted(ctx); ok && caller.ID != userID {
+		return ErrUserNotFound
 	}
+	return nil
 }
 
 func (u *User) RegisterUser(ctx context.Context, user *domain.User) (*domain.User, error) {
@@ -24,18 +101,185 @@ func (u *User) RegisterUser(ctx context.Context, user *domain.User) (*domain.Use
 		return nil, ErrUserNotFound
 	}
 
-	if user.Name == "" {
-		return nil, ErrInvalidUserName
+	name, err := normalizeUserName(user.Name)
+	if err != nil {
+		return nil, err
+	}
+	user.Name = name
+
+	if err := u.checkUserName(ctx, user); err != nil {
+		return nil, err
 	}
 
 	if err := u.userRepo.SaveUser(ctx, user); err != nil {
 		return nil, err
 	}
 
+	if err := u.audit.record(ctx, domain.AuditActionCreate, domain.AuditEntityUser, user.ID, nil, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// checkUserName возвращает ErrUserNameTaken, если имя без учета регистра занято другим пользователем.
+// Одновременные регистрации с одним именем дополнительно отсекает уникальный индекс в репозитории.
+func (u *User) checkUserName(ctx context.Context, user *domain.User) error {
+	existing, err := u.userRepo.GetUserByName(ctx, user.Name)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != user.ID {
+		return ErrUserNameTaken
+	}
+	return nil
+}
+
+// normalizeUserName обрезает пробелы по краям имени и проверяет его длину
+func normalizeUserName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxUserNameLength {
+		return "", ErrInvalidUserName
+	}
+	return name, nil
+}
+
+// GetUsers возвращает страницу списка пользователей. Пользователь без прав администратора видит только себя.
+func (u *User) GetUsers(ctx context.Context, limit, offset int) ([]domain.User, error) {
+	if limit == 0 {
+		limit = DefaultUsersPageSize
+	}
+	if limit < 0 || limit > MaxUsersPageSize || offset < 0 {
+		return nil, ErrInvalidPagination
+	}
+
+	if caller, ok := u.access.restricted(ctx); ok {
+		if offset > 0 {
+			return []domain.User{}, nil
+		}
+		user, err := u.userRepo.GetUserByID(ctx, caller.ID)
+		if err != nil {
+			return nil, err
+		}
+		return []domain.User{*user}, nil
+	}
+
+	return u.userRepo.GetUsers(ctx, limit, offset)
+}
+
+func (u *User) GetUserByID(ctx context.Context, id int64) (*domain.User, error) {
+	if err := u.checkSelf(ctx, id); err != nil {
+		return nil, err
+	}
+
+	user, err := u.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
 	return user, nil
 }
 
+// UpdateUser меняет имя пользователя, права администратора при этом не меняются
+func (u *User) UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	existing, err := u.GetUserByID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := normalizeUserName(user.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *existing
+	updated.Name = name
+	if err := u.checkUserName(ctx, &updated); err != nil {
+		return nil, err
+	}
+	if err := u.userRepo.SaveUser(ctx, &updated); err != nil {
+		return nil, err
+	}
+
+	if err := u.audit.record(ctx, domain.AuditActionUpdate, domain.AuditEntityUser, updated.ID, existing, &updated); err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// DeleteUser удаляет пользователя вместе с его API-токенами, привязками к датчикам, домам и членством в группах.
+// Сами датчики и дома при этом не удаляются.
+func (u *User) DeleteUser(ctx context.Context, id int64) error {
+	user, err := u.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		sensorOwners, err := u.sensorOwnerRepo.GetSensorsByUserID(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, sensorOwner := range sensorOwners {
+			if err := u.sensorOwnerRepo.DeleteSensorOwner(ctx, id, sensorOwner.SensorID); err != nil {
+				return err
+			}
+		}
+
+		if u.homeOwnerRepo != nil {
+			if err := u.homeOwnerRepo.DeleteHomeOwnersByUserID(ctx, id); err != nil {
+				return err
+			}
+		}
+
+		if u.groupRepo != nil {
+			memberships, err := u.groupRepo.GetGroupsByUserID(ctx, id)
+			if err != nil {
+				return err
+			}
+			for _, membership := range memberships {
+				if err := u.groupRepo.DeleteGroupMember(ctx, membership.GroupID, id); err != nil {
+					return err
+				}
+			}
+		}
+
+		if u.tokenRepo != nil {
+			if err := u.tokenRepo.DeleteAPITokensByUserID(ctx, id); err != nil {
+				return err
+			}
+		}
+
+		if err := u.userRepo.DeleteUser(ctx, id); err != nil {
+			return err
+		}
+
+		return u.audit.record(ctx, domain.AuditActionDelete, domain.AuditEntityUser, id, user, nil)
+	})
+}
+
+// AttachSensorToUser делает пользователя владельцем датчика
 func (u *User) AttachSensorToUser(ctx context.Context, userID, sensorID int64) error {
+	return u.ShareSensor(ctx, userID, sensorID, domain.SensorRoleOwner)
+}
+
+// ShareSensor дает пользователю доступ к датчику с ролью role, для уже привязанного пользователя меняет роль.
+// Делиться датчиком может только его владелец.
+func (u *User) ShareSensor(ctx context.Context, userID, sensorID int64, role domain.SensorRole) error {
+	if !role.IsValid() {
+		return ErrInvalidSensorRole
+	}
+
 	user, err := u.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
 		return err
@@ -51,15 +295,147 @@ func (u *User) AttachSensorToUser(ctx context.Context, userID, sensorID int64) e
 	if sensor == nil {
 		return ErrSensorNotFound
 	}
+	if err := u.access.CheckSensorRole(ctx, sensorID, domain.SensorRoleOwner); err != nil {
+		return err
+	}
+
+	if role != domain.SensorRoleOwner {
+		sensorOwners, err := u.sensorOwnerRepo.GetUsersBySensorID(ctx, sensorID)
+		if err != nil {
+			return err
+		}
+		// повторная привязка с младшей ролью не должна оставить датчик без владельца
+		if _, err := findSensorOwner(sensorOwners, userID, role); err != nil && !errors.Is(err, ErrSensorOwnerNotFound) {
+			return err
+		}
+	}
+
 	sensorOwner := domain.SensorOwner{
 		UserID:   userID,
 		SensorID: sensorID,
+		Role:     role,
+	}
+	if err := u.sensorOwnerRepo.SaveSensorOwner(ctx, sensorOwner); err != nil {
+		return err
+	}
+
+	return u.audit.record(ctx, domain.AuditActionAttach, domain.AuditEntitySensor, sensorID, nil, auditLink("user", userID, role))
+}
+
+// SetSensorRole меняет роль уже привязанного к датчику пользователя. Менять роли может только владелец датчика.
+func (u *User) SetSensorRole(ctx context.Context, userID, sensorID int64, role domain.SensorRole) (*domain.SensorOwner, error) {
+	if !role.IsValid() {
+		return nil, ErrInvalidSensorRole
+	}
+
+	if err := u.access.CheckSensorRole(ctx, sensorID, domain.SensorRoleOwner); err != nil {
+		return nil, err
+	}
+
+	sensorOwners, err := u.sensorOwnerRepo.GetUsersBySensorID(ctx, sensorID)
+	if err != nil {
+		return nil, err
+	}
+	sensorOwner, err := findSensorOwner(sensorOwners, userID, role)
+	if err != nil {
+		return nil, err
 	}
 
-	return u.sensorOwnerRepo.SaveSensorOwner(ctx, sensorOwner)
+	previous := sensorRole(sensorOwner)
+	sensorOwner.Role = role
+	if err := u.sensorOwnerRepo.SaveSensorOwner(ctx, sensorOwner); err != nil {
+		return nil, err
+	}
+
+	err = u.audit.record(ctx, domain.AuditActionUpdate, domain.AuditEntitySensor, sensorID,
+		auditLink("user", userID, previous), auditLink("user", userID, role))
+	if err != nil {
+		return nil, err
+	}
+	return &sensorOwner, nil
+}
+
+// DetachSensorFromUser отвязывает датчик от пользователя. Отвязывать других может только владелец,
+// отвязаться самому может любой пользователь датчика. Последнего владельца отвязать нельзя.
+func (u *User) DetachSensorFromUser(ctx context.Context, userID, sensorID int64) error {
+	required := domain.SensorRoleOwner
+	if caller, ok := UserFromContext(ctx); ok && caller.ID == userID {
+		required = domain.SensorRoleViewer
+	}
+	if err := u.access.CheckSensorRole(ctx, sensorID, required); err != nil {
+		return err
+	}
+
+	sensorOwners, err := u.sensorOwnerRepo.GetUsersBySensorID(ctx, sensorID)
+	if err != nil {
+		return err
+	}
+	sensorOwner, err := findSensorOwner(sensorOwners, userID, "")
+	if err != nil {
+		return err
+	}
+
+	if err := u.sensorOwnerRepo.DeleteSensorOwner(ctx, userID, sensorID); err != nil {
+		return err
+	}
+
+	err = u.audit.record(ctx, domain.AuditActionDetach, domain.AuditEntitySensor, sensorID,
+		auditLink("user", userID, sensorRole(sensorOwner)), nil)
+	if err != nil {
+		return err
+	}
+
+	u.access.accessChanged(ctx, userID)
+	return nil
+}
+
+// GetSensorUsers возвращает привязки пользователей к датчику
+func (u *User) GetSensorUsers(ctx context.Context, sensorID int64) ([]domain.SensorOwner, error) {
+	sensor, err := u.sensorRepo.GetSensorByID(ctx, sensorID)
+	if err != nil {
+		return nil, err
+	}
+	if sensor == nil {
+		return nil, ErrSensorNotFound
+	}
+	if err := u.access.CheckSensor(ctx, sensorID); err != nil {
+		return nil, err
+	}
+
+	return u.sensorOwnerRepo.GetUsersBySensorID(ctx, sensorID)
+}
+
+// findSensorOwner находит привязку пользователя среди привязок датчика и проверяет,
+// что после смены ее роли на role (пустая роль - удаление) у датчика останется владелец
+func findSensorOwner(sensorOwners []domain.SensorOwner, userID int64, role domain.SensorRole) (domain.SensorOwner, error) {
+	var (
+		found  *domain.SensorOwner
+		owners int
+	)
+	for i, sensorOwner := range sensorOwners {
+		if sensorOwner.UserID == userID {
+			found = &sensorOwners[i]
+			continue
+		}
+		if sensorRole(sensorOwner) == domain.SensorRoleOwner {
+			owners++
+		}
+	}
+
+	if found == nil {
+		return domain.SensorOwner{}, ErrSensorOwnerNotFound
+	}
+	if sensorRole(*found) == domain.SensorRoleOwner && role != domain.SensorRoleOwner && owners == 0 {
+		return domain.SensorOwner{}, ErrLastSensorOwner
+	}
+	return *found, nil
 }
 
 func (u *User) GetUserSensors(ctx context.Context, userID int64) ([]domain.Sensor, error) {
+	if err := u.checkSelf(ctx, userID); err != nil {
+		return nil, err
+	}
+
 	user, err := u.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
 		return nil, err
@@ -74,6 +450,7 @@ func (u *User) GetUserSensors(ctx context.Context, userID int64) ([]domain.Senso
 	}
 
 	var sensors []domain.Sensor
+	seen := make(map[int64]struct{}, len(sensorOwners))
 	for _, sensorOwner := range sensorOwners {
 		sensor, err := u.sensorRepo.GetSensorByID(ctx, sensorOwner.SensorID)
 		if err != nil {
@@ -81,6 +458,47 @@ func (u *User) GetUserSensors(ctx context.Context, userID int64) ([]domain.Senso
 		}
 		if sensor != nil {
 			sensors = append(sensors, *sensor)
+			seen[sensor.ID] = struct{}{}
+		}
+	}
+
+	groupSensors, err := userGroupSensors(ctx, u.groupRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, groupSensor := range groupSensors {
+		if _, ok := seen[groupSensor.SensorID]; ok {
+			continue
+		}
+		sensor, err := u.sensorRepo.GetSensorByID(ctx, groupSensor.SensorID)
+		if err != nil {
+			return nil, err
+		}
+		if sensor != nil {
+			sensors = append(sensors, *sensor)
+			seen[sensor.ID] = struct{}{}
+		}
+	}
+
+	if u.homeOwnerRepo == nil {
+		return sensors, nil
+	}
+
+	homeOwners, err := u.homeOwnerRepo.GetHomesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, homeOwner := range homeOwners {
+		hs, err := homeSensors(ctx, u.roomRepo, u.sensorRepo, homeOwner.HomeID)
+		if err != nil {
+			return nil, err
+		}
+		for _, sensor := range hs {
+			if _, ok := seen[sensor.ID]; ok {
+				continue
+			}
+			sensors = append(sensors, sensor)
+			seen[sensor.ID] = struct{}{}
 		}
 	}
 	return sensors, nil
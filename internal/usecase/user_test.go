@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"homework/internal/domain"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_user_RegisterUser(t *testing.T) {
@@ -24,12 +26,26 @@ func Test_user_RegisterUser(t *testing.T) {
 		assert.ErrorIs(t, err, ErrInvalidUserName)
 	})
 
+	t.Run("fail, name too long", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		u := NewUser(nil, nil, nil)
+
+		_, err := u.RegisterUser(ctx, &domain.User{Name: strings.Repeat("я", maxUserNameLength+1)})
+		assert.ErrorIs(t, err, ErrInvalidUserName)
+
+		_, err = u.RegisterUser(ctx, &domain.User{Name: "   "})
+		assert.ErrorIs(t, err, ErrInvalidUserName)
+	})
+
 	t.Run("fail, repo fail", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
 		ur := NewMockUserRepository(ctrl)
 		expectedError := errors.New("doh")
+		ur.EXPECT().GetUserByName(ctx, "Homer Simpson").Times(1).Return(nil, ErrUserNotFound)
 		ur.EXPECT().SaveUser(ctx, gomock.Any()).Times(1).Return(expectedError)
 
 		u := NewUser(ur, nil, nil)
@@ -45,6 +61,7 @@ func Test_user_RegisterUser(t *testing.T) {
 		defer cancel()
 
 		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByName(ctx, "Homer Simpson").Times(1).Return(nil, ErrUserNotFound)
 		ur.EXPECT().SaveUser(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, u *domain.User) {
 			assert.Equal(t, "Homer Simpson", u.Name)
 			u.ID = 1
@@ -58,6 +75,37 @@ func Test_user_RegisterUser(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, int64(1), user.ID)
 	})
+
+	t.Run("ok, name trimmed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByName(ctx, "Homer Simpson").Times(1).Return(nil, ErrUserNotFound)
+		ur.EXPECT().SaveUser(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, u *domain.User) {
+			assert.Equal(t, "Homer Simpson", u.Name)
+		})
+
+		u := NewUser(ur, nil, nil)
+
+		_, err := u.RegisterUser(ctx, &domain.User{
+			Name: "  Homer Simpson\t",
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("fail, name taken", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByName(ctx, "homer simpson").Times(1).Return(&domain.User{ID: 1, Name: "Homer Simpson"}, nil)
+
+		u := NewUser(ur, nil, nil)
+
+		_, err := u.RegisterUser(ctx, &domain.User{Name: "homer simpson"})
+		assert.ErrorIs(t, err, ErrUserNameTaken)
+	})
 }
 
 func Test_user_AttachSensorToUser(t *testing.T) {
@@ -230,3 +278,319 @@ func Test_user_GetUserSensors(t *testing.T) {
 		assert.Len(t, sensors, 3)
 	})
 }
+
+func Test_user_SetSensorRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, invalid role", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		u := NewUser(nil, nil, nil)
+
+		_, err := u.SetSensorRole(ctx, 1, 1, "admin")
+		assert.ErrorIs(t, err, ErrInvalidSensorRole)
+	})
+
+	t.Run("fail, caller is not an owner", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 2})
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Times(1).
+			Return([]domain.SensorOwner{{UserID: 2, SensorID: 1, Role: domain.SensorRoleOperator}}, nil)
+		sor.EXPECT().SaveSensorOwner(gomock.Any(), gomock.Any()).Times(0)
+
+		u := NewUser(nil, sor, nil, WithUserAccessPolicy(NewAccessPolicy(sor, nil, nil)))
+
+		_, err := u.SetSensorRole(ctx, 2, 1, domain.SensorRoleOwner)
+		assert.ErrorIs(t, err, ErrInsufficientRole)
+	})
+
+	t.Run("fail, user is not linked", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetUsersBySensorID(ctx, int64(1)).Times(1).
+			Return([]domain.SensorOwner{{UserID: 1, SensorID: 1, Role: domain.SensorRoleOwner}}, nil)
+
+		u := NewUser(nil, sor, nil)
+
+		_, err := u.SetSensorRole(ctx, 3, 1, domain.SensorRoleViewer)
+		assert.ErrorIs(t, err, ErrSensorOwnerNotFound)
+	})
+
+	t.Run("fail, demoting the last owner", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetUsersBySensorID(ctx, int64(1)).Times(1).Return([]domain.SensorOwner{
+			{UserID: 1, SensorID: 1, Role: domain.SensorRoleOwner},
+			{UserID: 2, SensorID: 1, Role: domain.SensorRoleViewer},
+		}, nil)
+		sor.EXPECT().SaveSensorOwner(gomock.Any(), gomock.Any()).Times(0)
+
+		u := NewUser(nil, sor, nil)
+
+		_, err := u.SetSensorRole(ctx, 1, 1, domain.SensorRoleViewer)
+		assert.ErrorIs(t, err, ErrLastSensorOwner)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetUsersBySensorID(ctx, int64(1)).Times(1).Return([]domain.SensorOwner{
+			{UserID: 1, SensorID: 1, Role: domain.SensorRoleOwner},
+			{UserID: 2, SensorID: 1, Role: domain.SensorRoleViewer},
+		}, nil)
+		sor.EXPECT().SaveSensorOwner(ctx, domain.SensorOwner{UserID: 2, SensorID: 1, Role: domain.SensorRoleOperator}).
+			Times(1).Return(nil)
+
+		u := NewUser(nil, sor, nil)
+
+		sensorOwner, err := u.SetSensorRole(ctx, 2, 1, domain.SensorRoleOperator)
+		require.NoError(t, err)
+		assert.Equal(t, domain.SensorRoleOperator, sensorOwner.Role)
+	})
+}
+
+func Test_user_DetachSensorFromUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, not linked", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetUsersBySensorID(ctx, int64(1)).Times(1).Return([]domain.SensorOwner{}, nil)
+		sor.EXPECT().DeleteSensorOwner(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		u := NewUser(nil, sor, nil)
+
+		err := u.DetachSensorFromUser(ctx, 2, 1)
+		assert.ErrorIs(t, err, ErrSensorOwnerNotFound)
+	})
+
+	t.Run("fail, last owner", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetUsersBySensorID(ctx, int64(1)).Times(1).
+			Return([]domain.SensorOwner{{UserID: 1, SensorID: 1, Role: domain.SensorRoleOwner}}, nil)
+
+		u := NewUser(nil, sor, nil)
+
+		err := u.DetachSensorFromUser(ctx, 1, 1)
+		assert.ErrorIs(t, err, ErrLastSensorOwner)
+	})
+
+	t.Run("ok, viewer leaves on their own", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 2})
+
+		links := []domain.SensorOwner{
+			{UserID: 1, SensorID: 1, Role: domain.SensorRoleOwner},
+			{UserID: 2, SensorID: 1, Role: domain.SensorRoleViewer},
+		}
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Times(1).Return(links[1:], nil)
+		sor.EXPECT().GetUsersBySensorID(ctx, int64(1)).Times(1).Return(links, nil)
+		sor.EXPECT().DeleteSensorOwner(ctx, int64(2), int64(1)).Times(1).Return(nil)
+
+		u := NewUser(nil, sor, nil, WithUserAccessPolicy(NewAccessPolicy(sor, nil, nil)))
+
+		err := u.DetachSensorFromUser(ctx, 2, 1)
+		assert.NoError(t, err)
+	})
+}
+
+func Test_user_GetUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, invalid pagination", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		u := NewUser(nil, nil, nil)
+
+		_, err := u.GetUsers(ctx, MaxUsersPageSize+1, 0)
+		assert.ErrorIs(t, err, ErrInvalidPagination)
+
+		_, err = u.GetUsers(ctx, 10, -1)
+		assert.ErrorIs(t, err, ErrInvalidPagination)
+	})
+
+	t.Run("ok, default page size", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUsers(ctx, DefaultUsersPageSize, 0).Times(1).Return([]domain.User{{ID: 1}, {ID: 2}}, nil)
+
+		u := NewUser(ur, nil, nil)
+
+		users, err := u.GetUsers(ctx, 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, users, 2)
+	})
+
+	t.Run("ok, user sees only themselves", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 2})
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(2)).Times(1).Return(&domain.User{ID: 2, Name: "Marge"}, nil)
+
+		u := NewUser(ur, nil, nil, WithUserAccessPolicy(NewAccessPolicy(nil, nil, nil)))
+
+		users, err := u.GetUsers(ctx, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.User{{ID: 2, Name: "Marge"}}, users)
+	})
+}
+
+func Test_user_UpdateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, another user", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = ContextWithUser(ctx, &domain.User{ID: 2})
+
+		u := NewUser(nil, nil, nil, WithUserAccessPolicy(NewAccessPolicy(nil, nil, nil)))
+
+		_, err := u.UpdateUser(ctx, &domain.User{ID: 1, Name: "Bart"})
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("ok, admin flag kept", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(1)).Times(1).Return(&domain.User{ID: 1, Name: "Homer", IsAdmin: true}, nil)
+		ur.EXPECT().GetUserByName(ctx, "Homer Simpson").Times(1).Return(nil, ErrUserNotFound)
+		ur.EXPECT().SaveUser(ctx, &domain.User{ID: 1, Name: "Homer Simpson", IsAdmin: true}).Times(1).Return(nil)
+
+		u := NewUser(ur, nil, nil)
+
+		user, err := u.UpdateUser(ctx, &domain.User{ID: 1, Name: " Homer Simpson "})
+		require.NoError(t, err)
+		assert.Equal(t, "Homer Simpson", user.Name)
+	})
+
+	t.Run("ok, own name in another case", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(1)).Times(1).Return(&domain.User{ID: 1, Name: "Homer"}, nil)
+		ur.EXPECT().GetUserByName(ctx, "HOMER").Times(1).Return(&domain.User{ID: 1, Name: "Homer"}, nil)
+		ur.EXPECT().SaveUser(ctx, &domain.User{ID: 1, Name: "HOMER"}).Times(1).Return(nil)
+
+		u := NewUser(ur, nil, nil)
+
+		_, err := u.UpdateUser(ctx, &domain.User{ID: 1, Name: "HOMER"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("fail, name taken", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(1)).Times(1).Return(&domain.User{ID: 1, Name: "Homer"}, nil)
+		ur.EXPECT().GetUserByName(ctx, "Bart").Times(1).Return(&domain.User{ID: 2, Name: "bart"}, nil)
+
+		u := NewUser(ur, nil, nil)
+
+		_, err := u.UpdateUser(ctx, &domain.User{ID: 1, Name: "Bart"})
+		assert.ErrorIs(t, err, ErrUserNameTaken)
+	})
+}
+
+func Test_user_DeleteUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, user not found", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(1)).Times(1).Return(nil, ErrUserNotFound)
+
+		u := NewUser(ur, nil, nil)
+
+		assert.ErrorIs(t, u.DeleteUser(ctx, 1), ErrUserNotFound)
+	})
+
+	t.Run("ok, links deleted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(1)).Times(1).Return(&domain.User{ID: 1}, nil)
+		ur.EXPECT().DeleteUser(ctx, int64(1)).Times(1).Return(nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Times(1).
+			Return([]domain.SensorOwner{{UserID: 1, SensorID: 3}, {UserID: 1, SensorID: 4}}, nil)
+		sor.EXPECT().DeleteSensorOwner(ctx, int64(1), int64(3)).Times(1).Return(nil)
+		sor.EXPECT().DeleteSensorOwner(ctx, int64(1), int64(4)).Times(1).Return(nil)
+
+		hor := NewMockHomeOwnerRepository(ctrl)
+		hor.EXPECT().DeleteHomeOwnersByUserID(ctx, int64(1)).Times(1).Return(nil)
+
+		tr := NewMockAPITokenRepository(ctrl)
+		tr.EXPECT().DeleteAPITokensByUserID(ctx, int64(1)).Times(1).Return(nil)
+
+		u := NewUser(ur, sor, nil, WithHomeAccess(hor, nil), WithUserTokens(tr))
+
+		assert.NoError(t, u.DeleteUser(ctx, 1))
+	})
+}
+
+func Test_user_GetUserSensors_groups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ur := NewMockUserRepository(ctrl)
+	ur.EXPECT().GetUserByID(ctx, int64(1)).Times(1).Return(&domain.User{ID: 1}, nil)
+
+	sor := NewMockSensorOwnerRepository(ctrl)
+	sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Times(1).Return([]domain.SensorOwner{{UserID: 1, SensorID: 1}}, nil)
+
+	gr := NewMockGroupRepository(ctrl)
+	gr.EXPECT().GetGroupsByUserID(ctx, int64(1)).Times(1).Return([]domain.GroupMember{{GroupID: 5, UserID: 1}}, nil)
+	gr.EXPECT().GetSensorsByGroupID(ctx, int64(5)).Times(1).Return([]domain.GroupSensor{
+		{GroupID: 5, SensorID: 1, Role: domain.SensorRoleViewer},
+		{GroupID: 5, SensorID: 2, Role: domain.SensorRoleViewer},
+	}, nil)
+
+	sr := NewMockSensorRepository(ctrl)
+	sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+	sr.EXPECT().GetSensorByID(ctx, int64(2)).Times(1).Return(&domain.Sensor{ID: 2}, nil)
+
+	u := NewUser(ur, sor, sr, WithUserGroups(gr))
+
+	sensors, err := u.GetUserSensors(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Sensor{{ID: 1}, {ID: 2}}, sensors)
+}
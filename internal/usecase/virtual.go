@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"math"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxVirtualExpressionLength - максимальная длина выражения вычисляемого датчика в символах
+	maxVirtualExpressionLength = 1000
+	// maxVirtualInputs - максимальное число датчиков, на которые ссылается выражение
+	maxVirtualInputs = 50
+	// maxVirtualDepth - максимальная длина цепочки вычисляемых датчиков, зависящих друг от друга
+	maxVirtualDepth = 8
+)
+
+// virtualDepthKey - ключ контекста с длиной цепочки вычисляемых датчиков, вычисляемых по событию
+type virtualDepthKey struct{}
+
+// WithVirtualSensors сохраняет входы вычисляемых датчиков при изменении их выражений
+func WithVirtualSensors(vr VirtualSensorRepository) func(*Sensor) {
+	return func(s *Sensor) {
+		s.virtualRepo = vr
+	}
+}
+
+// WithEventVirtualSensors пересчитывает вычисляемые датчики по каждому принятому событию их входов
+func WithEventVirtualSensors(vr VirtualSensorRepository) func(*Event) {
+	return func(e *Event) {
+		e.virtualRepo = vr
+	}
+}
+
+// isVirtualSensorValid проверяет определение вычисляемого датчика и возвращает его разобранное выражение,
+// nil - датчик не вычисляется
+func isVirtualSensorValid(sensorType domain.SensorType, virtual *domain.VirtualSensor) (*domain.SensorExpression, error) {
+	if virtual == nil {
+		return nil, nil
+	}
+
+	if sensorType != domain.SensorTypeVirtual {
+		return nil, ErrInvalidVirtualSensor
+	}
+
+	// сравнение ложно для NaN
+	if !(virtual.Resolution > 0) || math.IsInf(virtual.Resolution, 0) {
+		return nil, ErrInvalidVirtualSensor
+	}
+	if utf8.RuneCountInString(virtual.Expression) > maxVirtualExpressionLength {
+		return nil, ErrInvalidVirtualSensor
+	}
+
+	expr, err := domain.ParseSensorExpression(virtual.Expression)
+	if err != nil || len(expr.Inputs()) > maxVirtualInputs {
+		return nil, ErrInvalidVirtualSensor
+	}
+	return expr, nil
+}
+
+// SetSensorVirtual задает или, если virtual равен nil, сбрасывает выражение вычисляемого датчика.
+// Входы выражения должны быть доступны пользователю и не могут зависеть от самого датчика.
+// Новое значение вычисляется по следующему событию любого из входов.
+func (s *Sensor) SetSensorVirtual(ctx context.Context, id int64, virtual *domain.VirtualSensor) (*domain.Sensor, error) {
+	sensor, err := s.GetSensorByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.access.CheckSensorRole(ctx, id, domain.SensorRoleOperator); err != nil {
+		return nil, err
+	}
+
+	if sensor.Type != domain.SensorTypeVirtual {
+		return nil, ErrInvalidVirtualSensor
+	}
+	expr, err := isVirtualSensorValid(sensor.Type, virtual)
+	if err != nil {
+		return nil, err
+	}
+
+	var inputs []int64
+	if expr != nil {
+		inputs = expr.Inputs()
+		if err := s.checkVirtualInputs(ctx, id, inputs); err != nil {
+			return nil, err
+		}
+	}
+
+	updated := *sensor
+	updated.Virtual = virtual
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.sensorRepo.SaveSensor(ctx, &updated); err != nil {
+			return err
+		}
+		if s.virtualRepo != nil {
+			if err := s.virtualRepo.SaveVirtualSensorInputs(ctx, id, inputs); err != nil {
+				return err
+			}
+		}
+		return s.audit.record(ctx, domain.AuditActionUpdate, domain.AuditEntitySensor, id, sensor, &updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// checkVirtualInputs проверяет, что входы вычисляемого датчика id доступны пользователю
+// и ни один из них не вычисляется, прямо или через другие датчики, по самому датчику
+func (s *Sensor) checkVirtualInputs(ctx context.Context, id int64, inputs []int64) error {
+	for _, inputID := range inputs {
+		_, err := s.GetSensorByID(ctx, inputID)
+		if errors.Is(err, ErrSensorNotFound) {
+			return ErrInvalidVirtualSensor
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	visited := make(map[int64]struct{})
+	pending := append([]int64(nil), inputs...)
+	for len(pending) > 0 {
+		inputID := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if inputID == id {
+			return ErrInvalidVirtualSensor
+		}
+		if _, ok := visited[inputID]; ok {
+			continue
+		}
+		visited[inputID] = struct{}{}
+
+		input, err := s.sensorRepo.GetSensorByID(ctx, inputID)
+		if errors.Is(err, ErrSensorNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if input == nil || input.Virtual == nil {
+			continue
+		}
+		expr, err := domain.ParseSensorExpression(input.Virtual.Expression)
+		if err != nil {
+			continue
+		}
+		pending = append(pending, expr.Inputs()...)
+	}
+	return nil
+}
+
+// computeVirtualSensors вычисляет датчики, зависящие от датчика inputID, и принимает их значения как события
+// с моментом timestamp. Датчик, значение которого не определено, например из-за деления на ноль, пропускается.
+func (e *Event) computeVirtualSensors(ctx context.Context, inputID int64, timestamp time.Time) error {
+	if e.virtualRepo == nil {
+		return nil
+	}
+	depth, _ := ctx.Value(virtualDepthKey{}).(int)
+	if depth >= maxVirtualDepth {
+		return nil
+	}
+
+	ids, err := e.virtualRepo.GetVirtualSensorIDsByInputID(ctx, inputID)
+	if err != nil {
+		return err
+	}
+
+	ctx = context.WithValue(ctx, virtualDepthKey{}, depth+1)
+	var errs []error
+	for _, id := range ids {
+		if err := e.computeVirtualSensor(ctx, id, timestamp); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (e *Event) computeVirtualSensor(ctx context.Context, id int64, timestamp time.Time) error {
+	sensor, err := e.sensorRepo.GetSensorByID(ctx, id)
+	if errors.Is(err, ErrSensorNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if sensor == nil || sensor.Virtual == nil {
+		return nil
+	}
+
+	expr, err := domain.ParseSensorExpression(sensor.Virtual.Expression)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[int64]float64, len(expr.Inputs()))
+	for _, inputID := range expr.Inputs() {
+		input, err := e.sensorRepo.GetSensorByID(ctx, inputID)
+		if errors.Is(err, ErrSensorNotFound) || err == nil && input == nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		values[inputID] = eventValue(input, &domain.Event{Payload: input.CurrentState})
+	}
+
+	value, err := expr.Evaluate(values)
+	if errors.Is(err, domain.ErrUndefinedValue) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	payload, err := sensor.Virtual.Payload(value)
+	if err != nil {
+		// число шагов не помещается в событие - значение не определено
+		return nil
+	}
+
+	return e.accept(ctx, sensor, &domain.Event{
+		Timestamp:          timestamp,
+		SensorSerialNumber: sensor.SerialNumber,
+		SensorID:           sensor.ID,
+		Payload:            payload,
+	})
+}
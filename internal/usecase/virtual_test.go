@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_virtual_SetSensorVirtual(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sensors := map[int64]*domain.Sensor{
+		1: {ID: 1, Type: domain.SensorTypeADC},
+		2: {ID: 2, Type: domain.SensorTypeADC},
+		3: {ID: 3, Type: domain.SensorTypeVirtual},
+		4: {ID: 4, Type: domain.SensorTypeVirtual, Virtual: &domain.VirtualSensor{Expression: "s3 + s1", Resolution: 0.01}},
+	}
+	newRepo := func(ctx context.Context) *MockSensorRepository {
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, id int64) (*domain.Sensor, error) {
+			sensor, ok := sensors[id]
+			if !ok {
+				return nil, ErrSensorNotFound
+			}
+			return sensor, nil
+		})
+		return sr
+	}
+
+	t.Run("fail, invalid settings", func(t *testing.T) {
+		ctx := context.Background()
+		s := NewSensor(newRepo(ctx))
+
+		_, err := s.SetSensorVirtual(ctx, 1, &domain.VirtualSensor{Expression: "s2", Resolution: 0.01})
+		assert.ErrorIs(t, err, ErrInvalidVirtualSensor, "adc sensor")
+
+		for _, virtual := range []*domain.VirtualSensor{
+			{Expression: "s1 +", Resolution: 0.01},
+			{Expression: "s1", Resolution: 0},
+			{Expression: "s5", Resolution: 0.01},
+			{Expression: "s3", Resolution: 0.01},
+			{Expression: "s4 - s2", Resolution: 0.01},
+		} {
+			_, err := s.SetSensorVirtual(ctx, 3, virtual)
+			assert.ErrorIs(t, err, ErrInvalidVirtualSensor, virtual.Expression)
+		}
+	})
+
+	t.Run("ok, inputs are saved", func(t *testing.T) {
+		ctx := context.Background()
+		sr := newRepo(ctx)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, sensor *domain.Sensor) {
+			assert.Equal(t, "avg(s1, s2)", sensor.Virtual.Expression)
+		})
+		vr := NewMockVirtualSensorRepository(ctrl)
+		vr.EXPECT().SaveVirtualSensorInputs(ctx, int64(3), []int64{1, 2}).Times(1).Return(nil)
+
+		s := NewSensor(sr, WithVirtualSensors(vr))
+
+		sensor, err := s.SetSensorVirtual(ctx, 3, &domain.VirtualSensor{Expression: "avg(s1, s2)", Resolution: 0.01})
+		require.NoError(t, err)
+		assert.NotNil(t, sensor.Virtual)
+	})
+}
+
+func Test_virtual_ReceiveEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	setup := func(ctx context.Context, expression string) (*MockEventRepository, *Event) {
+		sensors := map[int64]*domain.Sensor{
+			1: {ID: 1, SerialNumber: "0000000001", Type: domain.SensorTypeADC, CurrentState: 200,
+				Calibration: &domain.Calibration{Scale: 0.1}},
+			2: {ID: 2, SerialNumber: "0000000002", Type: domain.SensorTypeADC},
+			3: {ID: 3, SerialNumber: "0000000003", Type: domain.SensorTypeVirtual,
+				Virtual: &domain.VirtualSensor{Expression: expression, Resolution: 0.01, Unit: "°C"}},
+		}
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, sn string) (*domain.Sensor, error) {
+			for _, sensor := range sensors {
+				if sensor.SerialNumber == sn {
+					copied := *sensor
+					return &copied, nil
+				}
+			}
+			return nil, ErrSensorNotFound
+		})
+		sr.EXPECT().GetSensorByID(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, id int64) (*domain.Sensor, error) {
+			copied := *sensors[id]
+			return &copied, nil
+		})
+		sr.EXPECT().UpdateSensorState(gomock.Any(), gomock.Any()).AnyTimes().Do(func(_ context.Context, sensor *domain.Sensor) {
+			saved := *sensor
+			sensors[sensor.ID] = &saved
+		})
+		vr := NewMockVirtualSensorRepository(ctrl)
+		vr.EXPECT().GetVirtualSensorIDsByInputID(ctx, int64(2)).AnyTimes().Return([]int64{3}, nil)
+		vr.EXPECT().GetVirtualSensorIDsByInputID(gomock.Any(), int64(3)).AnyTimes().Return([]int64{}, nil)
+		er := NewMockEventRepository(ctrl)
+
+		return er, NewEvent(er, sr, WithEventVirtualSensors(vr))
+	}
+
+	t.Run("ok, input event computes virtual sensor", func(t *testing.T) {
+		ctx := context.Background()
+		er, e := setup(ctx, "avg(s1, s2)")
+
+		gomock.InOrder(
+			er.EXPECT().SaveEvent(gomock.Any(), gomock.Any()).Times(1).Do(func(_ context.Context, event *domain.Event) {
+				assert.Equal(t, int64(2), event.SensorID)
+			}),
+			er.EXPECT().SaveEvent(gomock.Any(), gomock.Any()).Times(1).Do(func(_ context.Context, event *domain.Event) {
+				assert.Equal(t, domain.Event{Timestamp: now, SensorSerialNumber: "0000000003", SensorID: 3, Payload: 1550}, *event)
+			}),
+		)
+
+		require.NoError(t, e.ReceiveEvent(ctx, &domain.Event{Timestamp: now, SensorSerialNumber: "0000000002", Payload: 11}))
+	})
+
+	t.Run("ok, undefined value is skipped", func(t *testing.T) {
+		ctx := context.Background()
+		er, e := setup(ctx, "s1 / s2")
+
+		er.EXPECT().SaveEvent(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+
+		require.NoError(t, e.ReceiveEvent(ctx, &domain.Event{Timestamp: now, SensorSerialNumber: "0000000002", Payload: 0}))
+	})
+
+	t.Run("fail, event of virtual sensor", func(t *testing.T) {
+		ctx := context.Background()
+		_, e := setup(ctx, "s2")
+
+		err := e.ReceiveEvent(ctx, &domain.Event{Timestamp: now, SensorSerialNumber: "0000000003", Payload: 1})
+		assert.ErrorIs(t, err, ErrVirtualSensorEvent)
+	})
+}
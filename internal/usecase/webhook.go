@@ -0,0 +1,759 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"homework/internal/domain"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// WebhookSignatureHeader - заголовок с подписью уведомления, см. SignWebhook
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	// WebhookTimestampHeader - заголовок с временем отправки в секундах Unix, входит в подпись
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	// WebhookEventHeader - заголовок с видом уведомления
+	WebhookEventHeader = "X-Webhook-Event"
+	// WebhookDeliveryHeader - заголовок с id отправки, одинаковый во всех попытках
+	WebhookDeliveryHeader = "X-Webhook-Delivery"
+
+	// webhookSecretPrefix - префикс секретов подписок
+	webhookSecretPrefix = "whs_"
+	// webhookSecretBytes - количество случайных байт в секрете подписки
+	webhookSecretBytes = 32
+	// maxWebhookURLLength - максимальная длина адреса подписки
+	maxWebhookURLLength = 2048
+	// maxWebhookErrorLength - сколько символов причины неудачи сохраняется в журнале отправок
+	maxWebhookErrorLength = 200
+	// maxWebhookResponseBytes - сколько байт ответа получателя читается, чтобы переиспользовать соединение
+	maxWebhookResponseBytes = 64 << 10
+	// defaultWebhookTimeout - время ожидания ответа получателя
+	defaultWebhookTimeout = 10 * time.Second
+	// webhookDeliveryBatch - сколько отправок выбирается за один вызов DeliverWebhooks
+	webhookDeliveryBatch = 50
+	// webhookDeliveryLease - на сколько откладывается выбранная отправка, должно быть больше времени ожидания ответа
+	webhookDeliveryLease = time.Minute
+	// webhookDeliveryWorkers - скольким получателям уведомления отправляются одновременно
+	webhookDeliveryWorkers = 8
+
+	// DefaultWebhookDeliveriesPageSize - размер страницы журнала отправок, если он не задан
+	DefaultWebhookDeliveriesPageSize = 50
+	// MaxWebhookDeliveriesPageSize - максимальный размер страницы журнала отправок
+	MaxWebhookDeliveriesPageSize = 100
+)
+
+// DefaultWebhookRetryPolicy - повторные попытки отправки по умолчанию: 6 попыток в течение примерно получаса
+var DefaultWebhookRetryPolicy = domain.WebhookRetryPolicy{
+	MaxAttempts: 6,
+	BaseDelay:   30 * time.Second,
+	MaxDelay:    time.Hour,
+}
+
+// Webhook - подписки внешних систем на события датчиков и оповещения. Пользователь без прав администратора
+// видит и меняет только свои подписки и получает уведомления только о доступных ему датчиках.
+//
+// Уведомления о событиях приходят через outbox (Publish), об оповещениях - в транзакции изменения оповещения.
+// Уведомления сначала сохраняются в журнал отправок, а отправляются периодически (DeliverWebhooks)
+// с повторными попытками. Отправка, для которой попытки исчерпаны, остается в журнале в состоянии dead.
+//
+// Уведомления отправляются только на публичные адреса: адреса локальной и частных сетей запрещены и при
+// проверке подписки, и при подключении к получателю, после разрешения имени.
+type Webhook struct {
+	webhookRepo WebhookRepository
+	sensorRepo  SensorRepository
+	userRepo    UserRepository
+	access      *AccessPolicy
+	audit       *Audit
+	client      *http.Client
+	retry       domain.WebhookRetryPolicy
+	now         func() time.Time
+
+	privateNetworks bool
+}
+
+func NewWebhook(wr WebhookRepository, sr SensorRepository, ur UserRepository, options ...func(*Webhook)) *Webhook {
+	w := &Webhook{
+		webhookRepo: wr,
+		sensorRepo:  sr,
+		userRepo:    ur,
+		retry:       DefaultWebhookRetryPolicy,
+		now:         time.Now,
+	}
+
+	for _, o := range options {
+		o(w)
+	}
+
+	if w.client == nil {
+		w.client = newWebhookClient(w.privateNetworks)
+	}
+
+	return w
+}
+
+// WithWebhookAccessPolicy ограничивает подписки пользователя его собственными, а уведомления - доступными ему датчиками
+func WithWebhookAccessPolicy(p *AccessPolicy) func(*Webhook) {
+	return func(w *Webhook) {
+		w.access = p
+	}
+}
+
+// WithWebhookAudit записывает создание, изменение и удаление подписок в журнал аудита
+func WithWebhookAudit(a *Audit) func(*Webhook) {
+	return func(w *Webhook) {
+		w.audit = a
+	}
+}
+
+// WithWebhookHTTPClient задает клиент для отправки уведомлений. Проверять адрес подключения должен сам клиент.
+func WithWebhookHTTPClient(client *http.Client) func(*Webhook) {
+	return func(w *Webhook) {
+		w.client = client
+	}
+}
+
+// WithWebhookRetryPolicy задает число попыток и задержки между ними
+func WithWebhookRetryPolicy(policy domain.WebhookRetryPolicy) func(*Webhook) {
+	return func(w *Webhook) {
+		w.retry = policy
+	}
+}
+
+// WithWebhookPrivateNetworks разрешает адреса локальной и частных сетей, используется в тестах
+func WithWebhookPrivateNetworks() func(*Webhook) {
+	return func(w *Webhook) {
+		w.privateNetworks = true
+	}
+}
+
+// WithWebhookClock подменяет источник текущего времени, используется в тестах
+func WithWebhookClock(now func() time.Time) func(*Webhook) {
+	return func(w *Webhook) {
+		w.now = now
+	}
+}
+
+// CreateWebhook создает подписку и выпускает ее секрет. Открытое значение секрета возвращается только здесь.
+func (w *Webhook) CreateWebhook(ctx context.Context, webhook *domain.Webhook) (*domain.Webhook, string, error) {
+	if webhook == nil {
+		return nil, "", ErrWebhookNotFound
+	}
+	if err := w.validate(ctx, webhook); err != nil {
+		return nil, "", err
+	}
+
+	secret := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+
+	webhook.ID = 0
+	webhook.Secret = webhookSecretPrefix + hex.EncodeToString(secret)
+	webhook.CreatedAt = w.now()
+	webhook.CreatedBy = 0
+	if caller, ok := UserFromContext(ctx); ok {
+		webhook.CreatedBy = caller.ID
+	}
+
+	if err := w.webhookRepo.SaveWebhook(ctx, webhook); err != nil {
+		return nil, "", err
+	}
+
+	if err := w.audit.record(ctx, domain.AuditActionCreate, domain.AuditEntityWebhook, webhook.ID, nil, webhook); err != nil {
+		return nil, "", err
+	}
+
+	return webhook, webhook.Secret, nil
+}
+
+// GetWebhooks возвращает подписки пользователя из контекста, администратору - все подписки
+func (w *Webhook) GetWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	webhooks, err := w.webhookRepo.GetWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	caller, ok := w.access.restricted(ctx)
+	if !ok {
+		return webhooks, nil
+	}
+
+	result := make([]domain.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		if webhook.CreatedBy == caller.ID {
+			result = append(result, webhook)
+		}
+	}
+	return result, nil
+}
+
+// GetWebhookByID возвращает подписку. Чужая подписка неотличима от несуществующей.
+func (w *Webhook) GetWebhookByID(ctx context.Context, id int64) (*domain.Webhook, error) {
+	webhook, err := w.webhookRepo.GetWebhookByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	if caller, ok := w.access.restricted(ctx); ok && webhook.CreatedBy != caller.ID {
+		return nil, ErrWebhookNotFound
+	}
+
+	return webhook, nil
+}
+
+// UpdateWebhook заменяет адрес, фильтры и включенность подписки. Секрет не меняется.
+func (w *Webhook) UpdateWebhook(ctx context.Context, webhook *domain.Webhook) (*domain.Webhook, error) {
+	if webhook == nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	existingWebhook, err := w.GetWebhookByID(ctx, webhook.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.validate(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	webhook.Secret = existingWebhook.Secret
+	webhook.CreatedBy = existingWebhook.CreatedBy
+	webhook.CreatedAt = existingWebhook.CreatedAt
+	if err := w.webhookRepo.SaveWebhook(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	if err := w.audit.record(ctx, domain.AuditActionUpdate, domain.AuditEntityWebhook, webhook.ID, existingWebhook, webhook); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook удаляет подписку вместе с журналом ее отправок
+func (w *Webhook) DeleteWebhook(ctx context.Context, id int64) error {
+	webhook, err := w.GetWebhookByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := w.webhookRepo.DeleteWebhook(ctx, id); err != nil {
+		return err
+	}
+
+	return w.audit.record(ctx, domain.AuditActionDelete, domain.AuditEntityWebhook, id, webhook, nil)
+}
+
+// GetWebhookDeliveries возвращает страницу журнала отправок подписки, от новых к старым
+func (w *Webhook) GetWebhookDeliveries(ctx context.Context, filter domain.WebhookDeliveryFilter) ([]domain.WebhookDelivery, error) {
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, ErrInvalidDeliveryFilter
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultWebhookDeliveriesPageSize
+	}
+	if filter.Limit < 0 || filter.Limit > MaxWebhookDeliveriesPageSize || filter.Offset < 0 {
+		return nil, ErrInvalidPagination
+	}
+
+	if _, err := w.GetWebhookByID(ctx, filter.WebhookID); err != nil {
+		return nil, err
+	}
+
+	return w.webhookRepo.GetWebhookDeliveries(ctx, filter)
+}
+
+// SendTestWebhook сразу отправляет подписке проверочное уведомление, даже если она выключена, и возвращает
+// запись журнала с результатом. Неудачная проверочная отправка не повторяется.
+func (w *Webhook) SendTestWebhook(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	webhook, err := w.GetWebhookByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := w.now()
+	delivery, err := w.newDelivery(webhook, webhookPayload{Kind: domain.WebhookEventTest, CreatedAt: now}, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.webhookRepo.SaveWebhookDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	w.attempt(ctx, webhook, delivery, domain.WebhookRetryPolicy{MaxAttempts: 1})
+	if err := w.webhookRepo.SaveWebhookDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// Publish сохраняет уведомления для подходящих подписок по записи outbox, Webhook - получатель записей outbox
+func (w *Webhook) Publish(ctx context.Context, entry domain.OutboxEntry) error {
+	if entry.Kind != domain.OutboxSensorEvent {
+		return nil
+	}
+
+	var event domain.Event
+	if err := json.Unmarshal(entry.Payload, &event); err != nil {
+		return err
+	}
+
+	sensor, err := w.sensorRepo.GetSensorByID(ctx, entry.SensorID)
+	if errors.Is(err, ErrSensorNotFound) || err == nil && sensor == nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return w.enqueueSensorEvent(ctx, sensor, &event)
+}
+
+// enqueueSensorEvent сохраняет уведомления о событии датчика для подходящих подписок
+func (w *Webhook) enqueueSensorEvent(ctx context.Context, sensor *domain.Sensor, event *domain.Event) error {
+	return w.enqueue(ctx, sensor, webhookPayload{
+		Kind:   domain.WebhookEventSensorEvent,
+		Sensor: newWebhookSensor(sensor),
+		Event:  newWebhookEvent(sensor, event),
+	})
+}
+
+// enqueueAlert сохраняет уведомления об изменении оповещения для подходящих подписок
+func (w *Webhook) enqueueAlert(ctx context.Context, alert *domain.Alert) error {
+	if w == nil {
+		return nil
+	}
+
+	sensor, err := w.sensorRepo.GetSensorByID(ctx, alert.SensorID)
+	if errors.Is(err, ErrSensorNotFound) || err == nil && sensor == nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return w.enqueue(ctx, sensor, webhookPayload{
+		Kind:   domain.WebhookEventAlert,
+		Sensor: newWebhookSensor(sensor),
+		Alert: &webhookAlert{
+			ID:             alert.ID,
+			RuleID:         alert.RuleID,
+			AutomationID:   alert.AutomationID,
+			Anomaly:        alert.Anomaly,
+			Status:         alert.Status,
+			Value:          alert.Value,
+			Occurrences:    alert.Occurrences,
+			CreatedAt:      alert.CreatedAt,
+			LastOccurredAt: alert.LastOccurredAt,
+			AcknowledgedAt: alert.AcknowledgedAt,
+			ResolvedAt:     alert.ResolvedAt,
+		},
+	})
+}
+
+// enqueueAutomation сохраняет уведомление действия автоматизации или сцены, запущенной событием event
+// датчика sensor (nil - запуск не событием), для подписки id пользователя из контекста. Выключенной подписке
+// уведомление не отправляется.
+func (w *Webhook) enqueueAutomation(ctx context.Context, id int64, source *webhookAutomation, sensor *domain.Sensor, event *domain.Event) error {
+	webhook, err := w.GetWebhookByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !webhook.Enabled {
+		return nil
+	}
+
+	now := w.now()
+	payload := webhookPayload{Kind: domain.WebhookEventAutomation, CreatedAt: now, Automation: source}
+	if sensor != nil && event != nil {
+		payload.Sensor = newWebhookSensor(sensor)
+		payload.Event = newWebhookEvent(sensor, event)
+	}
+
+	delivery, err := w.newDelivery(webhook, payload, now)
+	if err != nil {
+		return err
+	}
+	return w.webhookRepo.SaveWebhookDelivery(ctx, delivery)
+}
+
+// DeliverWebhooks отправляет уведомления, время попытки которых наступило. Вызывается периодически.
+// Разным получателям уведомления отправляются одновременно, одному - по порядку. Если получатель отвечает
+// медленно, оставшиеся его уведомления не отправляются в этот раз и выбираются снова после аренды.
+func (w *Webhook) DeliverWebhooks(ctx context.Context) error {
+	now := w.now()
+	deliveries, err := w.webhookRepo.ClaimWebhookDeliveries(ctx, now, now.Add(webhookDeliveryLease), webhookDeliveryBatch)
+	if err != nil {
+		return err
+	}
+
+	var order []int64
+	byWebhook := make(map[int64][]*domain.WebhookDelivery)
+	for i := range deliveries {
+		id := deliveries[i].WebhookID
+		if _, ok := byWebhook[id]; !ok {
+			order = append(order, id)
+		}
+		byWebhook[id] = append(byWebhook[id], &deliveries[i])
+	}
+
+	// новые попытки начинаются, только пока ответ успеет прийти до окончания аренды
+	deadline := time.Now().Add(webhookDeliveryLease - defaultWebhookTimeout)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		errs    []error
+		workers = make(chan struct{}, webhookDeliveryWorkers)
+	)
+	for _, id := range order {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(id int64) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			if err := w.deliverTo(ctx, id, byWebhook[id], deadline); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// deliverTo по порядку отправляет уведомления подписки id, пока не наступит deadline
+func (w *Webhook) deliverTo(ctx context.Context, id int64, deliveries []*domain.WebhookDelivery, deadline time.Time) error {
+	webhook, err := w.webhookRepo.GetWebhookByID(ctx, id)
+	if errors.Is(err, ErrWebhookNotFound) || err == nil && webhook == nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		if webhook.Enabled {
+			if time.Now().After(deadline) {
+				return nil
+			}
+			w.attempt(ctx, webhook, delivery, w.retry)
+		} else {
+			delivery.Status = domain.WebhookDeliveryDead
+			delivery.LastError = "webhook disabled"
+		}
+		if err := w.webhookRepo.SaveWebhookDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Webhook) enqueue(ctx context.Context, sensor *domain.Sensor, payload webhookPayload) error {
+	webhooks, err := w.webhookRepo.GetWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := w.now()
+	payload.CreatedAt = now
+	for i := range webhooks {
+		webhook := &webhooks[i]
+		if !webhook.Matches(payload.Kind, sensor) {
+			continue
+		}
+		ok, err := w.canReceive(ctx, webhook, sensor.ID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		delivery, err := w.newDelivery(webhook, payload, now)
+		if err != nil {
+			return err
+		}
+		if err := w.webhookRepo.SaveWebhookDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// canReceive сообщает, доступен ли датчик автору подписки
+func (w *Webhook) canReceive(ctx context.Context, webhook *domain.Webhook, sensorID int64) (bool, error) {
+	if w.access == nil || webhook.CreatedBy == 0 {
+		return true, nil
+	}
+
+	user, err := w.userRepo.GetUserByID(ctx, webhook.CreatedBy)
+	if errors.Is(err, ErrUserNotFound) || err == nil && user == nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if user.IsAdmin {
+		return true, nil
+	}
+
+	roles, err := w.access.SensorRoles(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	_, ok := roles[sensorID]
+	return ok, nil
+}
+
+func (w *Webhook) newDelivery(webhook *domain.Webhook, payload webhookPayload, now time.Time) (*domain.WebhookDelivery, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventKind:     payload.Kind,
+		Payload:       data,
+		Status:        domain.WebhookDeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}, nil
+}
+
+// attempt делает одну попытку отправки и записывает ее результат в delivery
+func (w *Webhook) attempt(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery, policy domain.WebhookRetryPolicy) {
+	timestamp := w.now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		delivery.Failed(0, truncateReason(err.Error(), maxWebhookErrorLength), w.now(), policy)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, string(delivery.EventKind))
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		delivery.Failed(0, truncateReason(err.Error(), maxWebhookErrorLength), w.now(), policy)
+		return
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebhookResponseBytes))
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		delivery.Delivered(resp.StatusCode, w.now())
+		return
+	}
+	delivery.Failed(resp.StatusCode, truncateReason(resp.Status, maxWebhookErrorLength), w.now(), policy)
+}
+
+func (w *Webhook) validate(ctx context.Context, webhook *domain.Webhook) error {
+	if len(webhook.URL) > maxWebhookURLLength {
+		return ErrInvalidWebhook
+	}
+	target, err := url.Parse(webhook.URL)
+	if err != nil || target.Scheme != "http" && target.Scheme != "https" || target.Host == "" {
+		return ErrInvalidWebhook
+	}
+	if !w.privateNetworks && !isPublicHost(target.Hostname()) {
+		return ErrInvalidWebhook
+	}
+
+	for _, kind := range webhook.EventKinds {
+		if !kind.IsValid() {
+			return ErrInvalidWebhook
+		}
+	}
+	for _, sensorType := range webhook.SensorTypes {
+		if !sensorType.IsValid() {
+			return ErrInvalidWebhook
+		}
+	}
+	for _, label := range webhook.Labels {
+		if !domain.IsValidLabel(label) {
+			return ErrInvalidWebhook
+		}
+	}
+	webhook.EventKinds = compactSorted(webhook.EventKinds)
+	webhook.SensorTypes = compactSorted(webhook.SensorTypes)
+	webhook.SensorIDs = compactSorted(webhook.SensorIDs)
+	webhook.Labels = compactSorted(webhook.Labels)
+
+	for _, sensorID := range webhook.SensorIDs {
+		if err := w.access.CheckSensor(ctx, sensorID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newWebhookClient возвращает клиент для отправки уведомлений. Если privateNetworks не задан, клиент
+// не подключается к адресам локальной и частных сетей и не использует прокси, чтобы адрес проверялся
+// после разрешения имени, в том числе при переадресации.
+func newWebhookClient(privateNetworks bool) *http.Client {
+	if privateNetworks {
+		return &http.Client{Timeout: defaultWebhookTimeout}
+	}
+
+	dialer := &net.Dialer{
+		Timeout: defaultWebhookTimeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !isPublicAddr(addrPort.Addr()) {
+				return ErrWebhookAddressForbidden
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: defaultWebhookTimeout, Transport: transport}
+}
+
+// isPublicHost сообщает, может ли узел адреса подписки быть публичным. Имена, кроме localhost, проверяются
+// при подключении.
+func isPublicHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return true
+	}
+	return isPublicAddr(addr)
+}
+
+// isPublicAddr сообщает, что адрес не относится к локальной, частным и служебным сетям
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() && !addr.IsUnspecified() && !addr.IsLoopback() && !addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() && !addr.IsLinkLocalMulticast() && !addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast()
+}
+
+// SignWebhook возвращает подпись уведомления в hex: HMAC-SHA256 по секрету подписки
+// от строки "<timestamp>\n", за которой следует тело запроса
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// truncateReason обрезает причину неудачи до n символов
+func truncateReason(reason string, n int) string {
+	runes := []rune(reason)
+	if len(runes) > n {
+		return string(runes[:n])
+	}
+	return reason
+}
+
+// compactSorted возвращает отсортированные значения без повторов, nil для пустого среза
+func compactSorted[T int64 | string | domain.WebhookEventKind | domain.SensorType](values []T) []T {
+	if len(values) == 0 {
+		return nil
+	}
+	values = slices.Clone(values)
+	slices.Sort(values)
+	return slices.Compact(values)
+}
+
+// webhookPayload - тело уведомления
+type webhookPayload struct {
+	Kind       domain.WebhookEventKind `json:"kind"`
+	CreatedAt  time.Time               `json:"created_at"`
+	Sensor     *webhookSensor          `json:"sensor,omitempty"`
+	Event      *webhookEvent           `json:"event,omitempty"`
+	Alert      *webhookAlert           `json:"alert,omitempty"`
+	Automation *webhookAutomation      `json:"automation,omitempty"`
+}
+
+type webhookSensor struct {
+	ID           int64             `json:"id"`
+	SerialNumber string            `json:"serial_number"`
+	Type         domain.SensorType `json:"type"`
+	Description  string            `json:"description"`
+}
+
+func newWebhookSensor(sensor *domain.Sensor) *webhookSensor {
+	return &webhookSensor{
+		ID:           sensor.ID,
+		SerialNumber: sensor.SerialNumber,
+		Type:         sensor.Type,
+		Description:  sensor.Description,
+	}
+}
+
+type webhookEvent struct {
+	Payload   int64     `json:"payload"`
+	Value     *float64  `json:"value,omitempty"`
+	Unit      string    `json:"unit,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// newWebhookEvent возвращает событие датчика sensor с учетом калибровки
+func newWebhookEvent(sensor *domain.Sensor, event *domain.Event) *webhookEvent {
+	calibrated := *event
+	sensor.ApplyCalibration(&calibrated)
+
+	return &webhookEvent{
+		Payload:   calibrated.Payload,
+		Value:     calibrated.Value,
+		Unit:      calibrated.Unit,
+		Timestamp: calibrated.Timestamp,
+	}
+}
+
+type webhookAlert struct {
+	ID             int64              `json:"id"`
+	RuleID         int64              `json:"rule_id"`
+	AutomationID   int64              `json:"automation_id,omitempty"`
+	Anomaly        bool               `json:"anomaly,omitempty"`
+	Status         domain.AlertStatus `json:"status"`
+	Value          float64            `json:"value"`
+	Occurrences    int                `json:"occurrences"`
+	CreatedAt      time.Time          `json:"created_at"`
+	LastOccurredAt time.Time          `json:"last_occurred_at"`
+	AcknowledgedAt *time.Time         `json:"acknowledged_at,omitempty"`
+	ResolvedAt     *time.Time         `json:"resolved_at,omitempty"`
+}
+
+type webhookAutomation struct {
+	AutomationID int64  `json:"automation_id,omitempty"`
+	SceneID      int64  `json:"scene_id,omitempty"`
+	ScheduleID   int64  `json:"schedule_id,omitempty"`
+	Name         string `json:"name"`
+}
@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"homework/internal/domain"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_webhook_CreateWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, invalid webhook", func(t *testing.T) {
+		w := NewWebhook(nil, nil, nil)
+		ctx := context.Background()
+
+		for _, webhook := range []domain.Webhook{
+			{URL: "ftp://example.com"},
+			{URL: "http://"},
+			{URL: "http://example.com", EventKinds: []domain.WebhookEventKind{domain.WebhookEventTest}},
+			{URL: "http://example.com", SensorTypes: []domain.SensorType{"thermometer"}},
+			{URL: "http://example.com", Labels: []string{""}},
+			{URL: "http://localhost:8080/hook"},
+			{URL: "http://127.0.0.1/hook"},
+			{URL: "http://10.0.0.1/hook"},
+			{URL: "http://192.168.1.1/hook"},
+			{URL: "http://169.254.169.254/latest/meta-data"},
+			{URL: "http://[::1]/hook"},
+			{URL: "http://[::ffff:127.0.0.1]/hook"},
+			{URL: "http://0.0.0.0/hook"},
+		} {
+			_, _, err := w.CreateWebhook(ctx, &webhook)
+			assert.ErrorIs(t, err, ErrInvalidWebhook, webhook)
+		}
+	})
+
+	t.Run("ok, secret issued and filters normalized", func(t *testing.T) {
+		ctx := ContextWithUser(context.Background(), &domain.User{ID: 2, IsAdmin: true})
+		now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		wr := NewMockWebhookRepository(ctrl)
+		wr.EXPECT().SaveWebhook(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, webhook *domain.Webhook) error {
+			webhook.ID = 1
+			return nil
+		})
+
+		w := NewWebhook(wr, nil, nil, WithWebhookClock(func() time.Time { return now }))
+		webhook, secret, err := w.CreateWebhook(ctx, &domain.Webhook{URL: "https://example.com/hook",
+			SensorIDs: []int64{3, 1, 3}, EventKinds: []domain.WebhookEventKind{}, Labels: []string{"hall", "critical", "hall"}})
+		require.NoError(t, err)
+		assert.Regexp(t, "^whs_[0-9a-f]{64}$", secret)
+		assert.Equal(t, secret, webhook.Secret)
+		assert.Equal(t, []int64{1, 3}, webhook.SensorIDs)
+		assert.Equal(t, []string{"critical", "hall"}, webhook.Labels)
+		assert.Nil(t, webhook.EventKinds)
+		assert.Equal(t, int64(2), webhook.CreatedBy)
+		assert.Equal(t, now, webhook.CreatedAt)
+	})
+}
+
+func Test_webhook_DeliverWebhooks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	policy := domain.WebhookRetryPolicy{MaxAttempts: 2, BaseDelay: time.Minute, MaxDelay: time.Hour}
+
+	t.Run("ok, signed request delivered", func(t *testing.T) {
+		ctx := context.Background()
+		payload := []byte(`{"kind":"sensor.event"}`)
+
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			timestamp, err := strconv.ParseInt(r.Header.Get(WebhookTimestampHeader), 10, 64)
+			assert.NoError(t, err)
+			assert.Equal(t, SignWebhook("whs_secret", timestamp, body), r.Header.Get(WebhookSignatureHeader))
+			assert.Equal(t, "sensor.event", r.Header.Get(WebhookEventHeader))
+			assert.Equal(t, "5", r.Header.Get(WebhookDeliveryHeader))
+			assert.Equal(t, payload, body)
+			rw.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		delivery := domain.WebhookDelivery{ID: 5, WebhookID: 1, EventKind: domain.WebhookEventSensorEvent,
+			Payload: payload, Status: domain.WebhookDeliveryPending, NextAttemptAt: now}
+
+		wr := NewMockWebhookRepository(ctrl)
+		wr.EXPECT().ClaimWebhookDeliveries(ctx, now, now.Add(webhookDeliveryLease), webhookDeliveryBatch).Times(1).
+			Return([]domain.WebhookDelivery{delivery}, nil)
+		wr.EXPECT().GetWebhookByID(ctx, int64(1)).Times(1).
+			Return(&domain.Webhook{ID: 1, URL: server.URL, Secret: "whs_secret", Enabled: true}, nil)
+		wr.EXPECT().SaveWebhookDelivery(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, d *domain.WebhookDelivery) error {
+			assert.Equal(t, domain.WebhookDeliveryDelivered, d.Status)
+			assert.Equal(t, 1, d.Attempts)
+			assert.Equal(t, http.StatusNoContent, d.LastStatusCode)
+			assert.Equal(t, now, *d.DeliveredAt)
+			return nil
+		})
+
+		w := NewWebhook(wr, nil, nil, WithWebhookPrivateNetworks(), WithWebhookClock(func() time.Time { return now }))
+		assert.NoError(t, w.DeliverWebhooks(ctx))
+	})
+
+	t.Run("ok, failed attempts retried with backoff and then dead", func(t *testing.T) {
+		ctx := context.Background()
+
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		delivery := domain.WebhookDelivery{ID: 5, WebhookID: 1, EventKind: domain.WebhookEventAlert,
+			Payload: []byte(`{}`), Status: domain.WebhookDeliveryPending, NextAttemptAt: now}
+		webhook := &domain.Webhook{ID: 1, URL: server.URL, Secret: "whs_secret", Enabled: true}
+
+		wr := NewMockWebhookRepository(ctrl)
+		wr.EXPECT().GetWebhookByID(ctx, int64(1)).Times(2).Return(webhook, nil)
+		gomock.InOrder(
+			wr.EXPECT().ClaimWebhookDeliveries(ctx, now, gomock.Any(), webhookDeliveryBatch).Times(1).
+				Return([]domain.WebhookDelivery{delivery}, nil),
+			wr.EXPECT().SaveWebhookDelivery(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, d *domain.WebhookDelivery) error {
+				assert.Equal(t, domain.WebhookDeliveryPending, d.Status)
+				assert.Equal(t, 1, d.Attempts)
+				assert.Equal(t, http.StatusServiceUnavailable, d.LastStatusCode)
+				assert.Equal(t, "503 Service Unavailable", d.LastError)
+				assert.Equal(t, now.Add(time.Minute), d.NextAttemptAt)
+				delivery = *d
+				return nil
+			}),
+			wr.EXPECT().ClaimWebhookDeliveries(ctx, now.Add(time.Minute), gomock.Any(), webhookDeliveryBatch).Times(1).
+				DoAndReturn(func(context.Context, time.Time, time.Time, int) ([]domain.WebhookDelivery, error) {
+					return []domain.WebhookDelivery{delivery}, nil
+				}),
+			wr.EXPECT().SaveWebhookDelivery(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, d *domain.WebhookDelivery) error {
+				assert.Equal(t, domain.WebhookDeliveryDead, d.Status)
+				assert.Equal(t, 2, d.Attempts)
+				return nil
+			}),
+		)
+
+		clock := now
+		w := NewWebhook(wr, nil, nil, WithWebhookPrivateNetworks(), WithWebhookRetryPolicy(policy),
+			WithWebhookClock(func() time.Time { return clock }))
+		require.NoError(t, w.DeliverWebhooks(ctx))
+		clock = now.Add(time.Minute)
+		require.NoError(t, w.DeliverWebhooks(ctx))
+	})
+
+	t.Run("ok, private address refused on connect", func(t *testing.T) {
+		ctx := context.Background()
+
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			t.Error("request must not be sent")
+		}))
+		defer server.Close()
+
+		wr := NewMockWebhookRepository(ctrl)
+		wr.EXPECT().ClaimWebhookDeliveries(ctx, now, gomock.Any(), webhookDeliveryBatch).Times(1).
+			Return([]domain.WebhookDelivery{{ID: 5, WebhookID: 1, Status: domain.WebhookDeliveryPending}}, nil)
+		wr.EXPECT().GetWebhookByID(ctx, int64(1)).Times(1).
+			Return(&domain.Webhook{ID: 1, URL: server.URL, Secret: "whs_secret", Enabled: true}, nil)
+		wr.EXPECT().SaveWebhookDelivery(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, d *domain.WebhookDelivery) error {
+			assert.Equal(t, domain.WebhookDeliveryPending, d.Status)
+			assert.Equal(t, 1, d.Attempts)
+			assert.Contains(t, d.LastError, ErrWebhookAddressForbidden.Error())
+			return nil
+		})
+
+		w := NewWebhook(wr, nil, nil, WithWebhookClock(func() time.Time { return now }))
+		assert.NoError(t, w.DeliverWebhooks(ctx))
+	})
+
+	t.Run("ok, slow endpoint does not delay others", func(t *testing.T) {
+		ctx := context.Background()
+
+		release := make(chan struct{})
+		slow := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			<-release
+		}))
+		defer slow.Close()
+		fast := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.WriteHeader(http.StatusNoContent)
+		}))
+		defer fast.Close()
+
+		wr := NewMockWebhookRepository(ctrl)
+		wr.EXPECT().ClaimWebhookDeliveries(ctx, now, gomock.Any(), webhookDeliveryBatch).Times(1).
+			Return([]domain.WebhookDelivery{
+				{ID: 5, WebhookID: 1, Status: domain.WebhookDeliveryPending},
+				{ID: 6, WebhookID: 2, Status: domain.WebhookDeliveryPending},
+			}, nil)
+		wr.EXPECT().GetWebhookByID(ctx, int64(1)).Times(1).
+			Return(&domain.Webhook{ID: 1, URL: slow.URL, Secret: "whs_secret", Enabled: true}, nil)
+		wr.EXPECT().GetWebhookByID(ctx, int64(2)).Times(1).
+			Return(&domain.Webhook{ID: 2, URL: fast.URL, Secret: "whs_secret", Enabled: true}, nil)
+		wr.EXPECT().SaveWebhookDelivery(ctx, gomock.Any()).Times(2).DoAndReturn(func(_ context.Context, d *domain.WebhookDelivery) error {
+			if d.WebhookID == 2 {
+				assert.Equal(t, domain.WebhookDeliveryDelivered, d.Status)
+				close(release)
+			}
+			return nil
+		})
+
+		w := NewWebhook(wr, nil, nil, WithWebhookPrivateNetworks(), WithWebhookClock(func() time.Time { return now }))
+		assert.NoError(t, w.DeliverWebhooks(ctx))
+	})
+
+	t.Run("ok, disabled webhook deliveries are dead", func(t *testing.T) {
+		ctx := context.Background()
+
+		wr := NewMockWebhookRepository(ctrl)
+		wr.EXPECT().ClaimWebhookDeliveries(ctx, now, gomock.Any(), webhookDeliveryBatch).Times(1).
+			Return([]domain.WebhookDelivery{{ID: 5, WebhookID: 1, Status: domain.WebhookDeliveryPending}}, nil)
+		wr.EXPECT().GetWebhookByID(ctx, int64(1)).Times(1).Return(&domain.Webhook{ID: 1, URL: "http://example.com"}, nil)
+		wr.EXPECT().SaveWebhookDelivery(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, d *domain.WebhookDelivery) error {
+			assert.Equal(t, domain.WebhookDeliveryDead, d.Status)
+			assert.Zero(t, d.Attempts)
+			return nil
+		})
+
+		w := NewWebhook(wr, nil, nil, WithWebhookClock(func() time.Time { return now }))
+		assert.NoError(t, w.DeliverWebhooks(ctx))
+	})
+}
+
+func Test_webhook_Publish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("ok, only matching webhooks of users with access", func(t *testing.T) {
+		ctx := context.Background()
+		sensor := &domain.Sensor{ID: 1, SerialNumber: "0123456789", Type: domain.SensorTypeADC}
+		payload, err := json.Marshal(domain.Event{SensorID: 1, Payload: 10, Timestamp: now})
+		require.NoError(t, err)
+		entry := domain.OutboxEntry{ID: 1, SensorID: 1, Kind: domain.OutboxSensorEvent, Payload: payload}
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(sensor, nil)
+
+		wr := NewMockWebhookRepository(ctrl)
+		wr.EXPECT().GetWebhooks(ctx).Times(1).Return([]domain.Webhook{
+			{ID: 1, Enabled: true},
+			{ID: 2, Enabled: true, SensorTypes: []domain.SensorType{domain.SensorTypeContactClosure}},
+			{ID: 3, Enabled: false},
+			{ID: 4, Enabled: true, EventKinds: []domain.WebhookEventKind{domain.WebhookEventSensorEvent}, CreatedBy: 2},
+			{ID: 5, Enabled: true, CreatedBy: 3},
+		}, nil)
+		wr.EXPECT().SaveWebhookDelivery(ctx, gomock.Any()).Times(2).DoAndReturn(func(_ context.Context, d *domain.WebhookDelivery) error {
+			assert.Contains(t, []int64{1, 4}, d.WebhookID)
+			assert.Equal(t, domain.WebhookDeliveryPending, d.Status)
+			assert.Equal(t, now, d.NextAttemptAt)
+
+			var payload map[string]any
+			require.NoError(t, json.Unmarshal(d.Payload, &payload))
+			assert.Equal(t, "sensor.event", payload["kind"])
+			assert.Equal(t, "0123456789", payload["sensor"].(map[string]any)["serial_number"])
+			assert.Equal(t, float64(10), payload["event"].(map[string]any)["payload"])
+			return nil
+		})
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(2)).Times(1).Return(&domain.User{ID: 2}, nil)
+		ur.EXPECT().GetUserByID(ctx, int64(3)).Times(1).Return(&domain.User{ID: 3}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Times(1).
+			Return([]domain.SensorOwner{{UserID: 2, SensorID: 1, Role: domain.SensorRoleViewer}}, nil)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(3)).Times(1).Return(nil, nil)
+
+		w := NewWebhook(wr, sr, ur,
+			WithWebhookAccessPolicy(NewAccessPolicy(sor, nil, nil)),
+			WithWebhookClock(func() time.Time { return now }),
+		)
+		assert.NoError(t, w.Publish(ctx, entry))
+	})
+}
@@ -0,0 +1,6 @@
+create table homes
+(
+    id      bigserial   primary key,
+    name    text        not null,
+    address text        not null default ''
+);
@@ -0,0 +1 @@
+drop table rooms;
\ No newline at end of file
@@ -0,0 +1,8 @@
+create table rooms
+(
+    id      bigserial   primary key,
+    home_id bigint      not null,
+    name    text        not null
+);
+
+create index rooms_home_id_idx on rooms (home_id);
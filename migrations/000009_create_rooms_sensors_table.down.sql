@@ -0,0 +1 @@
+drop table rooms_sensors;
\ No newline at end of file
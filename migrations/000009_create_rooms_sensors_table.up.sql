@@ -0,0 +1,7 @@
+create table rooms_sensors
+(
+    sensor_id   bigint  primary key,
+    room_id     bigint  not null
+);
+
+create index rooms_sensors_room_id_idx on rooms_sensors (room_id);
@@ -0,0 +1,7 @@
+create table homes_users
+(
+    id          bigserial   not null,
+    home_id     bigint      not null,
+    user_id     bigint      not null,
+    unique (home_id, user_id)
+);
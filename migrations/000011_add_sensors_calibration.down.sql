@@ -0,0 +1 @@
+alter table sensors drop column calibration;
\ No newline at end of file
@@ -0,0 +1 @@
+alter table sensors add column calibration jsonb;
@@ -0,0 +1,5 @@
+alter table sensors
+    drop column manufacturer,
+    drop column model,
+    drop column hardware_revision,
+    drop column firmware_version;
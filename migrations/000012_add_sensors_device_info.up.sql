@@ -0,0 +1,5 @@
+alter table sensors
+    add column manufacturer      text not null default '',
+    add column model             text not null default '',
+    add column hardware_revision text not null default '',
+    add column firmware_version  text not null default '';
@@ -0,0 +1 @@
+drop table firmware_changes;
\ No newline at end of file
@@ -0,0 +1,10 @@
+create table firmware_changes
+(
+    id                  bigserial   primary key,
+    sensor_id           bigint      not null,
+    previous_version    text        not null,
+    version             text        not null,
+    changed_at          timestamp   not null
+);
+
+create index firmware_changes_sensor_id_idx on firmware_changes (sensor_id, changed_at);
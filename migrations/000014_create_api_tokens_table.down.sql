@@ -0,0 +1 @@
+drop table api_tokens;
\ No newline at end of file
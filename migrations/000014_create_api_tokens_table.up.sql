@@ -0,0 +1,12 @@
+create table api_tokens
+(
+    id          bigserial   primary key,
+    user_id     bigint      not null,
+    name        text        not null,
+    hash        text        not null unique,
+    created_at  timestamp   not null,
+    expires_at  timestamp,
+    revoked_at  timestamp
+);
+
+create index api_tokens_user_id_idx on api_tokens (user_id);
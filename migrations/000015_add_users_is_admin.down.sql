@@ -0,0 +1 @@
+alter table users drop column is_admin;
\ No newline at end of file
@@ -0,0 +1 @@
+alter table users add column is_admin boolean not null default false;
\ No newline at end of file
@@ -0,0 +1 @@
+drop table sensor_credentials;
\ No newline at end of file
@@ -0,0 +1,8 @@
+create table sensor_credentials
+(
+    sensor_id           bigint      primary key,
+    secret              text        not null,
+    previous_secret     text        not null default '',
+    previous_expires_at timestamp,
+    rotated_at          timestamp   not null
+);
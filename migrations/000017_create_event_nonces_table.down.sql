@@ -0,0 +1 @@
+drop table event_nonces;
\ No newline at end of file
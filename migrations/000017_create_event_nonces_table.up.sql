@@ -0,0 +1,9 @@
+create table event_nonces
+(
+    sensor_id   bigint      not null,
+    nonce       text        not null,
+    expires_at  timestamp   not null,
+    primary key (sensor_id, nonce)
+);
+
+create index event_nonces_expires_at_idx on event_nonces (expires_at);
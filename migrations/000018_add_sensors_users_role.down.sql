@@ -0,0 +1,3 @@
+alter table sensors_users
+    drop constraint sensors_users_sensor_id_user_id_key,
+    drop column role;
@@ -0,0 +1,7 @@
+delete from sensors_users a
+    using sensors_users b
+    where a.sensor_id = b.sensor_id and a.user_id = b.user_id and a.id > b.id;
+
+alter table sensors_users
+    add column role text not null default 'owner',
+    add constraint sensors_users_sensor_id_user_id_key unique (sensor_id, user_id);
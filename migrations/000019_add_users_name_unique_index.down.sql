@@ -0,0 +1 @@
+drop index if exists users_name_lower_key;
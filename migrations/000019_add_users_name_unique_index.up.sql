@@ -0,0 +1,12 @@
+-- данные не исправляются автоматически: одинаковые без учета регистра имена нужно переименовать до миграции,
+-- найти их можно запросом: select lower(name), array_agg(id) from users group by lower(name) having count(*) > 1
+do
+$$
+begin
+    if exists(select 1 from users group by lower(name) having count(*) > 1) then
+        raise exception 'users contain names that differ only in case, rename them before migrating';
+    end if;
+end;
+$$;
+
+create unique index users_name_lower_key on users (lower(name));
@@ -0,0 +1,3 @@
+drop table groups_sensors;
+drop table groups_users;
+drop table groups;
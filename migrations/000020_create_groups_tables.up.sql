@@ -0,0 +1,22 @@
+create table groups
+(
+    id      bigserial   primary key,
+    name    text        not null
+);
+
+create table groups_users
+(
+    group_id    bigint  not null references groups (id) on delete cascade,
+    user_id     bigint  not null,
+    primary key (group_id, user_id)
+);
+
+create index groups_users_user_id_idx on groups_users (user_id);
+
+create table groups_sensors
+(
+    group_id    bigint  not null references groups (id) on delete cascade,
+    sensor_id   bigint  not null,
+    role        text    not null,
+    primary key (group_id, sensor_id)
+);
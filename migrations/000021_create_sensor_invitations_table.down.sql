@@ -0,0 +1 @@
+drop table sensor_invitations;
@@ -0,0 +1,15 @@
+create table sensor_invitations
+(
+    id          bigserial   primary key,
+    sensor_id   bigint      not null,
+    role        text        not null,
+    hash        text        not null unique,
+    created_by  bigint      not null,
+    created_at  timestamp   not null,
+    expires_at  timestamp   not null,
+    max_uses    integer     not null,
+    uses        integer     not null default 0,
+    revoked_at  timestamp
+);
+
+create index sensor_invitations_sensor_id_idx on sensor_invitations (sensor_id);
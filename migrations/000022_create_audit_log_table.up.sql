@@ -0,0 +1,27 @@
+create table audit_log
+(
+    id          bigserial   primary key,
+    actor_id    bigint      not null,
+    action      text        not null,
+    entity_type text        not null,
+    entity_id   bigint      not null,
+    changes     jsonb       not null,
+    request_id  text        not null,
+    source_ip   text        not null,
+    created_at  timestamp   not null
+);
+
+create index audit_log_entity_idx on audit_log (entity_type, entity_id);
+create index audit_log_actor_id_idx on audit_log (actor_id);
+
+-- журнал только пополняется: изменение и удаление записей запрещены
+create function audit_log_append_only() returns trigger as
+$$
+begin
+    raise exception 'audit_log is append-only';
+end;
+$$ language plpgsql;
+
+create trigger audit_log_append_only
+    before update or delete on audit_log
+    for each row execute function audit_log_append_only();
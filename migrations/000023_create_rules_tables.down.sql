@@ -0,0 +1,3 @@
+drop table alerts;
+drop table rule_states;
+drop table rules;
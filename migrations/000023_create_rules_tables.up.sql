@@ -0,0 +1,37 @@
+create table rules
+(
+    id                bigserial        primary key,
+    sensor_id         bigint           not null,
+    name              text             not null,
+    operator          text             not null,
+    threshold         double precision not null,
+    duration_seconds  bigint           not null default 0,
+    window_from       integer,
+    window_to         integer,
+    window_time_zone  text,
+    enabled           boolean          not null default true,
+    created_by        bigint           not null default 0,
+    created_at        timestamp        not null
+);
+
+create index rules_sensor_id_idx on rules (sensor_id);
+
+create table rule_states
+(
+    rule_id        bigint           primary key references rules (id) on delete cascade,
+    value          double precision not null,
+    pending_since  timestamp,
+    firing         boolean          not null default false,
+    updated_at     timestamp        not null
+);
+
+create table alerts
+(
+    id          bigserial        primary key,
+    rule_id     bigint           not null,
+    sensor_id   bigint           not null,
+    value       double precision not null,
+    created_at  timestamp        not null
+);
+
+create index alerts_rule_id_idx on alerts (rule_id);
@@ -0,0 +1,11 @@
+drop index if exists alerts_sensor_id_idx;
+drop index if exists alerts_active_rule_id_idx;
+
+alter table alerts
+    drop column status,
+    drop column occurrences,
+    drop column last_occurred_at,
+    drop column acknowledged_at,
+    drop column acknowledged_by,
+    drop column resolved_at,
+    drop column resolved_by;
@@ -0,0 +1,21 @@
+alter table alerts
+    add column status           text      not null default 'open',
+    add column occurrences      integer   not null default 1,
+    add column last_occurred_at timestamp,
+    add column acknowledged_at  timestamp,
+    add column acknowledged_by  bigint    not null default 0,
+    add column resolved_at      timestamp,
+    add column resolved_by      bigint    not null default 0;
+
+update alerts set last_occurred_at = created_at;
+
+alter table alerts alter column last_occurred_at set not null;
+
+-- открытым остается только последнее оповещение правила, которое сейчас срабатывает:
+-- повторные срабатывания учитываются в незакрытом оповещении, поэтому оно может быть только одно
+update alerts set status = 'resolved', resolved_at = created_at
+where id not in (select max(id) from alerts group by rule_id)
+   or not exists (select 1 from rule_states where rule_states.rule_id = alerts.rule_id and rule_states.firing);
+
+create unique index alerts_active_rule_id_idx on alerts (rule_id) where status <> 'resolved';
+create index alerts_sensor_id_idx on alerts (sensor_id);
@@ -0,0 +1,31 @@
+create table webhooks
+(
+    id            bigserial  primary key,
+    url           text       not null,
+    secret        text       not null,
+    event_kinds   text[]     not null default '{}',
+    sensor_ids    bigint[]   not null default '{}',
+    sensor_types  text[]     not null default '{}',
+    enabled       boolean    not null default true,
+    created_by    bigint     not null default 0,
+    created_at    timestamp  not null
+);
+
+create table webhook_deliveries
+(
+    id                bigserial  primary key,
+    webhook_id        bigint     not null references webhooks (id) on delete cascade,
+    event_kind        text       not null,
+    payload           jsonb      not null,
+    status            text       not null,
+    attempts          integer    not null default 0,
+    next_attempt_at   timestamp  not null,
+    last_status_code  integer    not null default 0,
+    last_error        text       not null default '',
+    created_at        timestamp  not null,
+    delivered_at      timestamp
+);
+
+create index webhook_deliveries_webhook_id_idx on webhook_deliveries (webhook_id);
+-- выборка отправок, время попытки которых наступило
+create index webhook_deliveries_pending_idx on webhook_deliveries (next_attempt_at) where status = 'pending';
@@ -0,0 +1,15 @@
+create table outbox
+(
+    id               bigserial  primary key,
+    sensor_id        bigint     not null,
+    kind             text       not null,
+    payload          jsonb      not null,
+    attempts         integer    not null default 0,
+    next_attempt_at  timestamp  not null,
+    last_error       text       not null default '',
+    created_at       timestamp  not null
+);
+
+-- более ранние записи датчика, которые задерживают следующие
+create index outbox_sensor_id_idx on outbox (sensor_id, id);
+create index outbox_next_attempt_at_idx on outbox (next_attempt_at);
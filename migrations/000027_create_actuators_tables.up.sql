@@ -0,0 +1,33 @@
+create table actuators
+(
+    id          bigserial  primary key,
+    sensor_id   bigint     not null,
+    name        text       not null,
+    type        text       not null,
+    state       bigint     not null default 0,
+    created_by  bigint     not null default 0,
+    created_at  timestamp  not null
+);
+
+create index actuators_sensor_id_idx on actuators (sensor_id);
+
+create table commands
+(
+    id            bigserial  primary key,
+    actuator_id   bigint     not null references actuators (id) on delete cascade,
+    sensor_id     bigint     not null,
+    kind          text       not null,
+    state         bigint     not null default 0,
+    duration_ms   bigint     not null default 0,
+    status        text       not null,
+    error         text       not null default '',
+    created_by    bigint     not null default 0,
+    created_at    timestamp  not null,
+    expires_at    timestamp  not null,
+    delivered_at  timestamp,
+    completed_at  timestamp
+);
+
+create index commands_actuator_id_idx on commands (actuator_id);
+-- открытые команды контроллера и истечение команд
+create index commands_open_idx on commands (sensor_id, id) where status in ('pending', 'delivered');
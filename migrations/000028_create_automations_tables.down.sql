@@ -0,0 +1,8 @@
+drop index if exists alerts_active_source_idx;
+delete from alerts where automation_id <> 0;
+create unique index alerts_active_rule_id_idx on alerts (rule_id) where status <> 'resolved';
+alter table alerts drop column automation_id;
+
+drop table if exists automation_runs;
+drop table if exists scenes;
+drop table if exists automations;
@@ -0,0 +1,46 @@
+-- триггер, условия и действия хранятся в jsonb, датчик триггера - отдельно для выборки по событию
+create table automations
+(
+    id                 bigserial  primary key,
+    name               text       not null,
+    enabled            boolean    not null default true,
+    trigger_sensor_id  bigint     not null,
+    trigger            jsonb      not null,
+    conditions         jsonb      not null default '[]',
+    actions            jsonb      not null,
+    created_by         bigint     not null default 0,
+    created_at         timestamp  not null
+);
+
+create index automations_trigger_sensor_id_idx on automations (trigger_sensor_id);
+
+create table scenes
+(
+    id          bigserial  primary key,
+    name        text       not null,
+    actions     jsonb      not null,
+    created_by  bigint     not null default 0,
+    created_at  timestamp  not null
+);
+
+-- запуск принадлежит либо автоматизации, либо сцене
+create table automation_runs
+(
+    id             bigserial  primary key,
+    automation_id  bigint     references automations (id) on delete cascade,
+    scene_id       bigint     references scenes (id) on delete cascade,
+    sensor_id      bigint     not null default 0,
+    status         text       not null,
+    error          text       not null default '',
+    created_at     timestamp  not null,
+    check ((automation_id is null) <> (scene_id is null))
+);
+
+create index automation_runs_automation_id_idx on automation_runs (automation_id, id) where automation_id is not null;
+create index automation_runs_scene_id_idx on automation_runs (scene_id, id) where scene_id is not null;
+
+-- оповещение создает либо правило, либо автоматизация, незакрытым может быть одно оповещение каждого из них
+alter table alerts add column automation_id bigint not null default 0;
+
+drop index if exists alerts_active_rule_id_idx;
+create unique index alerts_active_source_idx on alerts (rule_id, automation_id) where status <> 'resolved';
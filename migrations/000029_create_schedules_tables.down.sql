@@ -0,0 +1,11 @@
+delete from automation_runs where schedule_id is not null;
+drop index if exists automation_runs_schedule_id_idx;
+alter table automation_runs drop constraint automation_runs_source_check;
+alter table automation_runs drop column schedule_id;
+alter table automation_runs add constraint automation_runs_check check ((automation_id is null) <> (scene_id is null));
+
+drop table if exists schedules;
+
+alter table homes drop column longitude;
+alter table homes drop column latitude;
+alter table homes drop column time_zone;
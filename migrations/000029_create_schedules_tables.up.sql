@@ -0,0 +1,30 @@
+-- часовой пояс и координаты дома нужны для расписаний и расчета восхода и заката
+alter table homes add column time_zone text not null default '';
+alter table homes add column latitude double precision;
+alter table homes add column longitude double precision;
+
+-- действие хранится в jsonb как действие автоматизации, пустое время запуска хранится как null
+create table schedules
+(
+    id                 bigserial  primary key,
+    home_id            bigint     not null references homes (id) on delete cascade,
+    name               text       not null,
+    expression         text       not null,
+    action             jsonb      not null,
+    missed_run_policy  text       not null,
+    enabled            boolean    not null default true,
+    next_run_at        timestamp,
+    last_run_at        timestamp,
+    created_by         bigint     not null default 0,
+    created_at         timestamp  not null
+);
+
+create index schedules_next_run_at_idx on schedules (next_run_at) where enabled;
+
+-- запуск принадлежит ровно одному из: автоматизации, сцене или расписанию
+alter table automation_runs add column schedule_id bigint references schedules (id) on delete cascade;
+alter table automation_runs drop constraint automation_runs_check;
+alter table automation_runs add constraint automation_runs_source_check
+    check (num_nonnulls(automation_id, scene_id, schedule_id) = 1);
+
+create index automation_runs_schedule_id_idx on automation_runs (schedule_id, id) where schedule_id is not null;
@@ -0,0 +1,13 @@
+drop index if exists alerts_active_anomaly_idx;
+drop index if exists alerts_active_source_idx;
+delete from alerts where anomaly;
+create unique index alerts_active_source_idx on alerts (rule_id, automation_id) where status <> 'resolved';
+alter table alerts drop column anomaly;
+
+drop table anomaly_states;
+
+alter table events
+    drop column anomaly_score,
+    drop column anomalous;
+
+alter table sensors drop column anomaly_detection;
@@ -0,0 +1,21 @@
+alter table sensors add column anomaly_detection jsonb;
+
+alter table events
+    add column anomaly_score double precision,
+    add column anomalous     boolean not null default false;
+
+create table anomaly_states
+(
+    sensor_id   bigint           primary key references sensors (id) on delete cascade,
+    mean        double precision not null,
+    variance    double precision not null,
+    count       bigint           not null,
+    updated_at  timestamp        not null
+);
+
+-- оповещение об аномалиях создается без правила и автоматизации, незакрытым может быть одно такое оповещение датчика
+alter table alerts add column anomaly boolean not null default false;
+
+drop index if exists alerts_active_source_idx;
+create unique index alerts_active_source_idx on alerts (rule_id, automation_id) where status <> 'resolved' and not anomaly;
+create unique index alerts_active_anomaly_idx on alerts (sensor_id) where status <> 'resolved' and anomaly;
@@ -0,0 +1,3 @@
+alter table sensors
+    drop column debounce,
+    drop column flapping;
@@ -0,0 +1,3 @@
+alter table sensors
+    add column debounce jsonb,
+    add column flapping boolean not null default false;
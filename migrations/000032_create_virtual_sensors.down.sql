@@ -0,0 +1,10 @@
+drop table virtual_sensor_inputs;
+
+alter table sensors drop column virtual;
+
+-- значение перечисления нельзя удалить, тип пересоздается без него
+delete from sensors where type = 'virtual';
+alter type sensor_type rename to sensor_type_old;
+create type sensor_type as enum ('cc', 'adc');
+alter table sensors alter column type type sensor_type using type::text::sensor_type;
+drop type sensor_type_old;
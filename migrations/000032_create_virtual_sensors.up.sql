@@ -0,0 +1,12 @@
+alter type sensor_type add value 'virtual';
+
+alter table sensors add column virtual jsonb;
+
+create table virtual_sensor_inputs
+(
+    sensor_id bigint not null references sensors (id) on delete cascade,
+    input_id  bigint not null references sensors (id) on delete cascade,
+    primary key (sensor_id, input_id)
+);
+
+create index virtual_sensor_inputs_input_id_idx on virtual_sensor_inputs (input_id);
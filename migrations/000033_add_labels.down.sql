@@ -0,0 +1,5 @@
+alter table webhooks
+    drop column labels;
+
+alter table sensors
+    drop column labels;
@@ -0,0 +1,5 @@
+alter table sensors
+    add column labels text[] not null default '{}';
+
+alter table webhooks
+    add column labels text[] not null default '{}';
@@ -0,0 +1,2 @@
+alter table homes_users
+    drop column role;
@@ -0,0 +1,3 @@
+-- привязки, созданные до появления ролей, давали полный доступ к дому
+alter table homes_users
+    add column role text not null default 'owner';